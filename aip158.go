@@ -0,0 +1,135 @@
+package pagetoken
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/pixlcrashr/go-pagetoken/checksum"
+)
+
+// PageSizeField is the checksum field WithAIP158 leaves out of the
+// checksum. It matches the page_size query parameter of HTTPRequest.
+const PageSizeField = DefaultPageSizeParam
+
+// PageSizer is implemented by requests carrying a page size. RequestReader
+// resolves it with PageSize when reading the request and records the result
+// on the returned token, see KeysetToken.PageSize.
+type PageSizer interface {
+	// GetPageSize returns the requested page size. Zero means the default
+	// page size.
+	GetPageSize() int
+}
+
+// WithAIP158 makes the reader follow AIP-158 (https://google.aip.dev/158):
+//
+//   - Clients may change the page size between pages, so the page_size
+//     checksum field is left out of the checksum.
+//   - A negative page size is rejected with ErrInvalidPageSize, which
+//     HTTPStatus maps to 400 Bad Request like all other token errors. Read
+//     rejects it with any reader.
+//   - Invalid tokens are rejected with ErrInvalidToken, and tokens issued
+//     for other parameters with ErrChecksumMismatch, as without it.
+//
+// Use PageSize to apply the default and the maximum page size.
+func WithAIP158() RequestReaderOpt {
+	return func(rr *RequestReader) {
+		rr.aip158 = true
+	}
+}
+
+// WithPageSizeLimits sets the page size PageSize returns for requests without
+// one and the maximum it coerces larger page sizes to. The defaults are
+// DefaultPageSize and DefaultMaxPageSize.
+func WithPageSizeLimits(defaultSize, maxSize int) RequestReaderOpt {
+	return func(rr *RequestReader) {
+		rr.defaultPageSize = defaultSize
+		rr.maxPageSize = maxSize
+	}
+}
+
+// WithPageSizeBounds sets the smallest and the largest page size PageSize
+// returns; requested page sizes outside the bounds are clamped to them, and
+// so is the default page size. The defaults are DefaultMinPageSize and
+// DefaultMaxPageSize, e.g.
+//
+//	pagetoken.NewRequestReader(pagetoken.WithPageSizeBounds(1, 100))
+func WithPageSizeBounds(minSize, maxSize int) RequestReaderOpt {
+	return func(rr *RequestReader) {
+		rr.minPageSize = minSize
+		rr.maxPageSize = maxSize
+	}
+}
+
+// WithPageSizeChecksum sets whether the page size of PageSizer requests is
+// part of the checksum. If include is true, the page size PageSize resolves
+// is added as the PageSizeField checksum field, replacing one returned by
+// GetChecksumFields, so a token is only accepted with the page size it was
+// issued for; page sizes clamped to the same bound are equal. If include is
+// false, the PageSizeField checksum field is left out, as AIP-158 requires
+// and WithAIP158 does.
+//
+// Without the option, the checksum fields of requests are used as they are,
+// unless WithAIP158 is given.
+func WithPageSizeChecksum(include bool) RequestReaderOpt {
+	return func(rr *RequestReader) {
+		rr.pageSizeChecksum = pageSizeChecksumExclude
+		if include {
+			rr.pageSizeChecksum = pageSizeChecksumInclude
+		}
+	}
+}
+
+type pageSizeChecksum uint8
+
+const (
+	pageSizeChecksumFields pageSizeChecksum = iota
+	pageSizeChecksumExclude
+	pageSizeChecksumInclude
+)
+
+// PageSize returns the page size to serve req with: the default page size
+// if req does not implement PageSizer or asks for zero, and the page size it
+// asks for clamped to the bounds of WithPageSizeBounds otherwise, so larger
+// page sizes are coerced to the maximum as AIP-158 prescribes. A negative
+// page size is reported with an error wrapping ErrInvalidPageSize.
+func (r *RequestReader) PageSize(req Request) (int, error) {
+	ps, ok := req.(PageSizer)
+	if !ok {
+		return r.clampPageSize(r.defaultPageSize), nil
+	}
+
+	switch n := ps.GetPageSize(); {
+	case n < 0:
+		return 0, fmt.Errorf("%w: %d is negative", ErrInvalidPageSize, n)
+	case n == 0:
+		return r.clampPageSize(r.defaultPageSize), nil
+	default:
+		return r.clampPageSize(n), nil
+	}
+}
+
+func (r *RequestReader) clampPageSize(n int) int {
+	return max(r.minPageSize, min(n, r.maxPageSize))
+}
+
+// pageSizeChecksumField applies the page size policy of r to the checksum
+// fields of req in cb.
+func (r *RequestReader) pageSizeChecksumField(cb *checksum.Builder, req Request) error {
+	switch {
+	case r.pageSizeChecksum == pageSizeChecksumInclude:
+		if _, ok := req.(PageSizer); !ok {
+			return nil
+		}
+
+		n, err := r.PageSize(req)
+		if err != nil {
+			return err
+		}
+		checksum.Without(PageSizeField)(cb)
+		checksum.Field(PageSizeField, strconv.Itoa(n))(cb)
+	case r.pageSizeChecksum == pageSizeChecksumExclude, r.aip158:
+		checksum.Without(PageSizeField)(cb)
+	}
+
+	return nil
+}
@@ -0,0 +1,241 @@
+package pagetoken_test
+
+import (
+	"net/http"
+	"strconv"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/checksum"
+	"github.com/pixlcrashr/go-pagetoken/encryption"
+)
+
+// listRequest is an AIP-158 list request with a filter and a page size.
+type listRequest struct {
+	filter string
+	size   int
+	token  string
+}
+
+func (r listRequest) GetChecksumFields() []checksum.BuilderOpt {
+	return []checksum.BuilderOpt{
+		checksum.Field("filter", r.filter),
+		checksum.Field(pagetoken.PageSizeField, strconv.Itoa(r.size)),
+	}
+}
+
+func (r listRequest) GetPageToken() string { return r.token }
+func (r listRequest) GetPageSize() int     { return r.size }
+
+// sizedRequest carries a page size but leaves it out of its checksum fields.
+type sizedRequest struct {
+	size  int
+	token string
+}
+
+func (sizedRequest) GetChecksumFields() []checksum.BuilderOpt {
+	return []checksum.BuilderOpt{checksum.Field("filter", "odd")}
+}
+
+func (r sizedRequest) GetPageToken() string { return r.token }
+func (r sizedRequest) GetPageSize() int     { return r.size }
+
+var _ = Describe("AIP-158", func() {
+	var (
+		e  encryption.Crypter
+		rr *pagetoken.RequestReader
+	)
+
+	BeforeEach(func() {
+		key, err := encryption.Rand32ByteKey()
+		Expect(err).NotTo(HaveOccurred())
+		e, err = encryption.NewAEADEncryptor(key)
+		Expect(err).NotTo(HaveOccurred())
+		rr = pagetoken.NewRequestReader(pagetoken.WithEncryptor(e), pagetoken.WithAIP158())
+	})
+
+	issue := func(r listRequest) string {
+		t, err := rr.Read(r)
+		Expect(err).NotTo(HaveOccurred())
+		s, err := t.Next().String()
+		Expect(err).NotTo(HaveOccurred())
+		return s
+	}
+
+	// "The page_size field may change between pages" (AIP-158, Changing
+	// parameters).
+	It("accepts a token when only the page size changed", func() {
+		s := issue(listRequest{filter: "odd", size: 10})
+		_, err := rr.Read(listRequest{filter: "odd", size: 50, token: s})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("still locks the page size without AIP-158 mode", func() {
+		s := issue(listRequest{filter: "odd", size: 10})
+		_, err := pagetoken.NewRequestReader(pagetoken.WithEncryptor(e)).Read(listRequest{filter: "odd", size: 50, token: s})
+		Expect(err).To(MatchError(pagetoken.ErrChecksumMismatch))
+	})
+
+	// "Other parameters [...] must be the same between pages; the API should
+	// error with INVALID_ARGUMENT if they differ."
+	It("rejects a token when another parameter changed", func() {
+		s := issue(listRequest{filter: "odd", size: 10})
+		_, err := rr.Read(listRequest{filter: "even", size: 10, token: s})
+		Expect(err).To(MatchError(pagetoken.ErrChecksumMismatch))
+		Expect(pagetoken.HTTPStatus(err)).To(Equal(http.StatusBadRequest))
+	})
+
+	// "If a negative value is sent, the API must send an INVALID_ARGUMENT
+	// error."
+	It("rejects a negative page size", func() {
+		_, err := rr.Read(listRequest{size: -1})
+		Expect(err).To(MatchError(pagetoken.ErrInvalidPageSize))
+		Expect(pagetoken.HTTPStatus(err)).To(Equal(http.StatusBadRequest))
+
+		_, err = rr.Read(listRequest{size: -1, token: issue(listRequest{size: 10})})
+		Expect(err).To(MatchError(pagetoken.ErrInvalidPageSize))
+	})
+
+	// "If page_size is 0, the API may choose an appropriate default [...]. If
+	// page_size is greater than the maximum, the API should coerce it down to
+	// the maximum."
+	DescribeTable("resolves the page size",
+		func(opts []pagetoken.RequestReaderOpt, size, want int) {
+			n, err := pagetoken.NewRequestReader(opts...).PageSize(listRequest{size: size})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(n).To(Equal(want))
+		},
+		Entry("zero to the default", nil, 0, pagetoken.DefaultPageSize),
+		Entry("within bounds unchanged", nil, 42, 42),
+		Entry("above the maximum to the maximum", nil, 1000, pagetoken.DefaultMaxPageSize),
+		Entry("with custom limits",
+			[]pagetoken.RequestReaderOpt{pagetoken.WithPageSizeLimits(5, 10)}, 0, 5),
+		Entry("above custom limits",
+			[]pagetoken.RequestReaderOpt{pagetoken.WithPageSizeLimits(5, 10)}, 11, 10),
+	)
+
+	It("uses the default page size for requests without one", func() {
+		n, err := rr.PageSize(filterRequest{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(Equal(pagetoken.DefaultPageSize))
+	})
+
+	// "Page tokens must be opaque to users."
+	It("issues opaque tokens", func() {
+		s := issue(listRequest{filter: "odd", size: 10})
+		Expect(s).NotTo(ContainSubstring("odd"))
+		Expect(s).To(MatchRegexp(`^[A-Za-z0-9_=-]+$`))
+	})
+})
+
+var _ = Describe("Page size", func() {
+	var e encryption.Crypter
+
+	BeforeEach(func() {
+		key, err := encryption.Rand32ByteKey()
+		Expect(err).NotTo(HaveOccurred())
+		e, err = encryption.NewAEADEncryptor(key)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	DescribeTable("is clamped to the bounds",
+		func(size, want int) {
+			rr := pagetoken.NewRequestReader(pagetoken.WithPageSizeBounds(5, 50))
+			n, err := rr.PageSize(listRequest{size: size})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(n).To(Equal(want))
+
+			t, err := rr.Read(listRequest{size: size})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(t.PageSize()).To(Equal(want))
+		},
+		Entry("below the minimum", 1, 5),
+		Entry("at the minimum", 5, 5),
+		Entry("within the bounds", 42, 42),
+		Entry("at the maximum", 50, 50),
+		Entry("above the maximum", 51, 50),
+		Entry("zero to the default", 0, pagetoken.DefaultPageSize),
+	)
+
+	It("clamps the default page size to the bounds", func() {
+		rr := pagetoken.NewRequestReader(pagetoken.WithPageSizeLimits(200, 500), pagetoken.WithPageSizeBounds(1, 100))
+		n, err := rr.PageSize(listRequest{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(Equal(100))
+	})
+
+	It("rejects a negative page size without AIP-158 mode", func() {
+		_, err := pagetoken.NewRequestReader().Read(listRequest{size: -1})
+		Expect(err).To(MatchError(pagetoken.ErrInvalidPageSize))
+	})
+
+	It("is recorded on tokens read from a page token", func() {
+		rr := pagetoken.NewRequestReader(pagetoken.WithEncryptor(e), pagetoken.WithAIP158())
+		first, err := rr.Read(listRequest{size: 10})
+		Expect(err).NotTo(HaveOccurred())
+		s, err := first.Next().String()
+		Expect(err).NotTo(HaveOccurred())
+
+		t, err := rr.Read(listRequest{size: 1000, token: s})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(t.PageSize()).To(Equal(pagetoken.DefaultMaxPageSize))
+	})
+
+	It("is the default for requests without one", func() {
+		t, err := pagetoken.NewRequestReader().Read(filterRequest{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(t.PageSize()).To(Equal(pagetoken.DefaultPageSize))
+	})
+
+	Context("in the checksum", func() {
+		issue := func(rr *pagetoken.RequestReader, r pagetoken.Request) string {
+			t, err := rr.Read(r)
+			Expect(err).NotTo(HaveOccurred())
+			s, err := t.Next().String()
+			Expect(err).NotTo(HaveOccurred())
+			return s
+		}
+
+		It("binds tokens to the resolved page size if included", func() {
+			rr := pagetoken.NewRequestReader(pagetoken.WithEncryptor(e), pagetoken.WithPageSizeChecksum(true))
+			s := issue(rr, sizedRequest{size: 10})
+
+			_, err := rr.Read(sizedRequest{size: 10, token: s})
+			Expect(err).NotTo(HaveOccurred())
+			_, err = rr.Read(sizedRequest{size: 50, token: s})
+			Expect(err).To(MatchError(pagetoken.ErrChecksumMismatch))
+
+			s = issue(rr, sizedRequest{size: 500})
+			_, err = rr.Read(sizedRequest{size: 1000, token: s})
+			Expect(err).NotTo(HaveOccurred(), "both are clamped to the maximum")
+		})
+
+		It("replaces the page size field of the request if included", func() {
+			rr := pagetoken.NewRequestReader(pagetoken.WithEncryptor(e), pagetoken.WithPageSizeChecksum(true))
+			s := issue(rr, listRequest{size: 0})
+
+			_, err := rr.Read(listRequest{size: pagetoken.DefaultPageSize, token: s})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("lets the page size change between pages if excluded", func() {
+			rr := pagetoken.NewRequestReader(pagetoken.WithEncryptor(e), pagetoken.WithPageSizeChecksum(false))
+			s := issue(rr, listRequest{filter: "odd", size: 10})
+
+			_, err := rr.Read(listRequest{filter: "odd", size: 50, token: s})
+			Expect(err).NotTo(HaveOccurred())
+			_, err = rr.Read(listRequest{filter: "even", size: 10, token: s})
+			Expect(err).To(MatchError(pagetoken.ErrChecksumMismatch))
+		})
+
+		It("overrides AIP-158 mode", func() {
+			rr := pagetoken.NewRequestReader(pagetoken.WithEncryptor(e), pagetoken.WithAIP158(), pagetoken.WithPageSizeChecksum(true))
+			s := issue(rr, sizedRequest{size: 10})
+
+			_, err := rr.Read(sizedRequest{size: 50, token: s})
+			Expect(err).To(MatchError(pagetoken.ErrChecksumMismatch))
+		})
+	})
+})
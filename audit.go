@@ -0,0 +1,189 @@
+package pagetoken
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/pixlcrashr/go-pagetoken/order"
+)
+
+// AuditSink receives an AuditRecord for every page token a RequestReader
+// issues or consumes, see WithAuditSink. Its methods are called
+// synchronously by the reader, so they must be safe for concurrent use and
+// should not block.
+type AuditSink interface {
+	// Issued is called for every token string returned by NextToken and
+	// NextTokenFor.
+	Issued(ctx context.Context, rec AuditRecord)
+	// Consumed is called for every page token Read and ReadContext accept.
+	// Tokens replaced by the first page, see WithSoftChecksum and
+	// WithFallbackToFirstPage, are not consumed.
+	Consumed(ctx context.Context, rec AuditRecord)
+}
+
+// AuditRecord describes a page token for an audit trail. It never contains
+// the token itself.
+type AuditRecord struct {
+	// Fingerprint identifies the token string, see TokenFingerprint.
+	Fingerprint string `json:"fingerprint"`
+	// Checksum is the request checksum the token was issued for.
+	Checksum uint32 `json:"checksum"`
+	// Scope and Subject are returned by the AuditIdentityFn of the reader,
+	// e.g. the endpoint and the authenticated user.
+	Scope   string `json:"scope,omitempty"`
+	Subject string `json:"subject,omitempty"`
+	// PageIndex is the zero-based index of the page the token points to.
+	PageIndex int `json:"page_index"`
+	// Fields are the keyset fields of the token.
+	Fields []AuditField `json:"fields"`
+	// IssuedAt is the issue time recorded in the token, with second
+	// precision. It is zero for tokens issued before tokens were stamped.
+	IssuedAt time.Time `json:"issued_at,omitzero"`
+	// Time is the time the token was issued or consumed at.
+	Time time.Time `json:"time"`
+}
+
+// AuditField is a keyset field of an AuditRecord. The values of sensitive
+// fields are replaced by RedactSensitive.
+type AuditField struct {
+	Path  string      `json:"path"`
+	Order order.Order `json:"order"`
+	Value string      `json:"value"`
+}
+
+// AuditIdentityFn returns the scope and the subject of the request ctx
+// belongs to for an AuditRecord.
+type AuditIdentityFn func(ctx context.Context) (scope, subject string)
+
+// WithAuditSink makes the reader report the tokens it issues and consumes
+// to sink. identity, which may be nil, fills in the scope and the subject
+// of the records. NextToken, NextTokenFor and Read report to it with
+// context.Background(), so the identity is only known to NextTokenContext
+// and ReadContext.
+func WithAuditSink(sink AuditSink, identity AuditIdentityFn) RequestReaderOpt {
+	return func(rr *RequestReader) {
+		rr.auditSink = sink
+		rr.auditIdentity = identity
+	}
+}
+
+// TokenFingerprint returns the fingerprint of the token string in audit
+// records: "sha256:" followed by the first 32 hex digits of the SHA-256
+// hash of token, which identifies the token without revealing it.
+func TokenFingerprint(token string) string {
+	h := sha256.Sum256([]byte(token))
+	return "sha256:" + hex.EncodeToString(h[:16])
+}
+
+// auditRecord returns the record of the token string s of t.
+func (r *RequestReader) auditRecord(ctx context.Context, s string, t *KeysetToken, now time.Time) AuditRecord {
+	rec := AuditRecord{
+		Fingerprint: TokenFingerprint(s),
+		Checksum:    t.checksum,
+		PageIndex:   t.pageIndex,
+		Fields:      []AuditField{},
+		IssuedAt:    t.issuedAt,
+		Time:        now,
+	}
+	if r.auditIdentity != nil {
+		rec.Scope, rec.Subject = r.auditIdentity(ctx)
+	}
+
+	if t.payload == nil {
+		return rec
+	}
+	for _, v := range t.payload.Values() {
+		value := v.Value
+		if v.Sensitive {
+			value = RedactSensitive(value)
+		}
+		rec.Fields = append(rec.Fields, AuditField{Path: v.Path, Order: v.Order, Value: value})
+	}
+
+	return rec
+}
+
+// consumed reports the page token s of the request to the audit sink if
+// the reader accepted it as c.
+func (r *RequestReader) consumed(ctx context.Context, s string, c *KeysetToken) {
+	if r.auditSink == nil || c.recovered != nil || c.checksumMismatched {
+		return
+	}
+
+	r.auditSink.Consumed(ctx, r.auditRecord(ctx, s, c, time.Now()))
+}
+
+// issue serializes t and reports it to the audit sink.
+func (r *RequestReader) issue(ctx context.Context, t *KeysetToken) (string, error) {
+	if r.auditSink == nil {
+		return t.String()
+	}
+
+	// pin the issue time so that the record matches the token
+	now := t.issueTime()
+	t.now = func() time.Time { return now }
+
+	s, err := t.String()
+	if err != nil {
+		return "", err
+	}
+
+	t.issuedAt = time.Unix(now.Unix(), 0).UTC()
+	r.auditSink.Issued(ctx, r.auditRecord(ctx, s, t, now))
+
+	return s, nil
+}
+
+// JSONLinesAuditSink is an AuditSink writing every record as a line of
+// JSON, e.g. to a file shipped to a log pipeline:
+//
+//	{"event":"issued","fingerprint":"sha256:…","checksum":…,…}
+//
+// It is safe for concurrent use.
+type JSONLinesAuditSink struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+	err error
+}
+
+// NewJSONLinesAuditSink returns a sink writing to w.
+func NewJSONLinesAuditSink(w io.Writer) *JSONLinesAuditSink {
+	return &JSONLinesAuditSink{enc: json.NewEncoder(w)}
+}
+
+type auditLine struct {
+	Event string `json:"event"`
+	AuditRecord
+}
+
+func (s *JSONLinesAuditSink) Issued(_ context.Context, rec AuditRecord) {
+	s.write("issued", rec)
+}
+
+func (s *JSONLinesAuditSink) Consumed(_ context.Context, rec AuditRecord) {
+	s.write("consumed", rec)
+}
+
+func (s *JSONLinesAuditSink) write(event string, rec AuditRecord) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.enc.Encode(auditLine{Event: event, AuditRecord: rec}); err != nil && s.err == nil {
+		s.err = err
+	}
+}
+
+// Err returns the first error writing a record failed with. Records
+// failing to be written are dropped, so compliance setups should check it,
+// e.g. in health checks.
+func (s *JSONLinesAuditSink) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.err
+}
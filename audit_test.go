@@ -0,0 +1,197 @@
+package pagetoken_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/encryption"
+	"github.com/pixlcrashr/go-pagetoken/order"
+)
+
+type auditEvent struct {
+	event string
+	rec   pagetoken.AuditRecord
+}
+
+type recordingSink struct {
+	mu     sync.Mutex
+	events []auditEvent
+}
+
+func (s *recordingSink) Issued(_ context.Context, rec pagetoken.AuditRecord) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, auditEvent{"issued", rec})
+}
+
+func (s *recordingSink) Consumed(_ context.Context, rec pagetoken.AuditRecord) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, auditEvent{"consumed", rec})
+}
+
+type subjectKey struct{}
+
+var _ = Describe("Audit records", func() {
+	var (
+		e    encryption.Crypter
+		sink *recordingSink
+		rr   *pagetoken.RequestReader
+		ctx  context.Context
+	)
+
+	identity := func(ctx context.Context) (string, string) {
+		subject, _ := ctx.Value(subjectKey{}).(string)
+		return "GET /books", subject
+	}
+
+	payload := pagetoken.NewKeysetPayloadBuilder().
+		AddString("email", "jane@example.com", order.Asc).Sensitive().
+		AddInt("id", 42, order.Asc).
+		Build()
+
+	BeforeEach(func() {
+		key, err := encryption.Rand32ByteKey()
+		Expect(err).NotTo(HaveOccurred())
+		e, err = encryption.NewAEADEncryptor(key)
+		Expect(err).NotTo(HaveOccurred())
+
+		sink = &recordingSink{}
+		rr = pagetoken.NewRequestReader(pagetoken.WithEncryptor(e), pagetoken.WithAuditSink(sink, identity))
+		ctx = context.WithValue(context.Background(), subjectKey{}, "user-1")
+	})
+
+	issue := func() string {
+		t, err := rr.ReadContext(ctx, filterRequest{status: "active"})
+		Expect(err).NotTo(HaveOccurred())
+
+		s, err := rr.NextTokenContext(ctx, t, payload)
+		Expect(err).NotTo(HaveOccurred())
+		return s
+	}
+
+	It("records issued tokens", func() {
+		s := issue()
+
+		Expect(sink.events).To(HaveLen(1))
+		Expect(sink.events[0].event).To(Equal("issued"))
+
+		rec := sink.events[0].rec
+		Expect(rec.Fingerprint).To(Equal(pagetoken.TokenFingerprint(s)))
+		Expect(rec.Scope).To(Equal("GET /books"))
+		Expect(rec.Subject).To(Equal("user-1"))
+		Expect(rec.PageIndex).To(Equal(1))
+		Expect(rec.Fields).To(Equal([]pagetoken.AuditField{
+			{Path: "email", Order: order.Asc, Value: pagetoken.RedactSensitive("jane@example.com")},
+			{Path: "id", Order: order.Asc, Value: "42"},
+		}))
+		Expect(rec.Time).NotTo(BeZero())
+
+		next, err := rr.Read(filterRequest{status: "active", token: s})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(next.Checksum()).To(Equal(rec.Checksum))
+		issuedAt, _ := next.IssuedAt()
+		Expect(rec.IssuedAt).To(Equal(issuedAt))
+	})
+
+	It("records consumed tokens", func() {
+		s := issue()
+		issued := sink.events[0].rec
+
+		_, err := rr.ReadContext(ctx, filterRequest{status: "active", token: s})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(sink.events).To(HaveLen(2))
+		Expect(sink.events[1].event).To(Equal("consumed"))
+
+		rec := sink.events[1].rec
+		Expect(rec.Fingerprint).To(Equal(issued.Fingerprint))
+		Expect(rec.Checksum).To(Equal(issued.Checksum))
+		Expect(rec.Subject).To(Equal("user-1"))
+		Expect(rec.PageIndex).To(Equal(1))
+		Expect(rec.Fields).To(Equal(issued.Fields))
+		Expect(rec.IssuedAt).To(Equal(issued.IssuedAt))
+	})
+
+	It("does not record requests without or with rejected tokens", func() {
+		s := issue()
+		sink.events = nil
+
+		_, err := rr.Read(filterRequest{status: "archived", token: s})
+		Expect(err).To(MatchError(pagetoken.ErrChecksumMismatch))
+
+		_, err = rr.Read(filterRequest{status: "active"})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(sink.events).To(BeEmpty())
+	})
+
+	It("does not record tokens replaced by the first page", func() {
+		rr = pagetoken.NewRequestReader(pagetoken.WithEncryptor(e), pagetoken.WithAuditSink(sink, identity), pagetoken.WithSoftChecksum())
+		s := issue()
+		sink.events = nil
+
+		t, err := rr.Read(filterRequest{status: "archived", token: s})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(t.ChecksumMismatched()).To(BeTrue())
+
+		Expect(sink.events).To(BeEmpty())
+	})
+
+	It("never records the token itself", func() {
+		var buf bytes.Buffer
+		rr = pagetoken.NewRequestReader(pagetoken.WithEncryptor(e), pagetoken.WithAuditSink(pagetoken.NewJSONLinesAuditSink(&buf), identity))
+
+		s := issue()
+		_, err := rr.ReadContext(ctx, filterRequest{status: "active", token: s})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(buf.String()).NotTo(ContainSubstring(s))
+		Expect(buf.String()).NotTo(ContainSubstring("jane@example.com"))
+	})
+
+	Describe("JSONLinesAuditSink", func() {
+		It("writes a line of JSON per record", func() {
+			var buf bytes.Buffer
+			jsonl := pagetoken.NewJSONLinesAuditSink(&buf)
+			rr = pagetoken.NewRequestReader(pagetoken.WithEncryptor(e), pagetoken.WithAuditSink(jsonl, identity))
+
+			s := issue()
+			_, err := rr.ReadContext(ctx, filterRequest{status: "active", token: s})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(jsonl.Err()).NotTo(HaveOccurred())
+
+			lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+			Expect(lines).To(HaveLen(2))
+
+			events := []string{}
+			for _, line := range lines {
+				var got map[string]any
+				Expect(json.Unmarshal([]byte(line), &got)).To(Succeed())
+				Expect(got).To(HaveKeyWithValue("fingerprint", pagetoken.TokenFingerprint(s)))
+				Expect(got).To(HaveKeyWithValue("subject", "user-1"))
+				Expect(got).To(HaveKeyWithValue("fields", ContainElement(HaveKeyWithValue("order", "asc"))))
+				events = append(events, got["event"].(string))
+			}
+			Expect(events).To(Equal([]string{"issued", "consumed"}))
+		})
+
+		It("reports write errors", func() {
+			jsonl := pagetoken.NewJSONLinesAuditSink(failingWriter{})
+			jsonl.Issued(context.Background(), pagetoken.AuditRecord{})
+
+			Expect(jsonl.Err()).To(MatchError(errWrongKeySize))
+		})
+	})
+})
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errWrongKeySize }
@@ -0,0 +1,48 @@
+package pagetoken
+
+// BoundaryTokens returns a page token for every boundary payload, e.g.
+// the keysets of every n-th row sampled periodically for the numbered page
+// links of a UI. boundaries[i] is the keyset of the last row of page i+1,
+// so its token points to the page with index i+1, see
+// KeysetToken.PageIndex, and jumping to it lands on the same rows as
+// walking i+1 pages from the first one, as long as the rows did not change
+// since the boundaries were sampled.
+//
+// The tokens are bound to the checksum fields and scope of req like the
+// tokens reader issues for req; its page token is not read. The checksum is
+// computed once and the serialization buffer is shared by all tokens, but
+// every token still costs one call to the reader's crypter.
+func BoundaryTokens(reader *RequestReader, req Request, boundaries []*KeysetPayload) ([]string, error) {
+	reader.assertFrozen()
+
+	crc, err := reader.checksum(req)
+	if err != nil {
+		return nil, err
+	}
+
+	e, err := reader.crypter(req)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &KeysetToken{checksum: crc, scope: scopeOf(req), now: reader.now}
+	w := &itemCursorWriter{
+		e:      e,
+		prefix: reader.prefix,
+		body:   t.v1Body(),
+		ids:    reader.tokenIDs,
+	}
+
+	ts := make([]string, len(boundaries))
+	for i, b := range boundaries {
+		w.body.PageIndex = i + 1
+
+		s, err := w.cursor(b)
+		if err != nil {
+			return nil, err
+		}
+		ts[i] = s
+	}
+
+	return ts, nil
+}
@@ -0,0 +1,88 @@
+package pagetoken
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/pixlcrashr/go-pagetoken/checksum"
+)
+
+// CacheKey returns a deterministic key of the page requested by req at the
+// cursor position of token, e.g. for caching list responses in a CDN.
+//
+// Encrypted token strings differ on every serialization, as every encryption
+// uses a fresh nonce, so they cannot serve as a key themselves. CacheKey
+// instead hashes the canonical encoding of the request's checksum fields
+// together with the keyset, composite payload, named keysets, snapshot time
+// and upstream token of token. Requests with equal checksum fields at the
+// same cursor position get the same key; changing any filter or cursor value
+// changes it. A nil token stands for the first page.
+//
+// The key is the hex-encoded SHA-256 of the above and reveals neither the
+// filters nor the cursor.
+func CacheKey(req Request, token *KeysetToken) (string, error) {
+	fields, err := checksum.NewBuilder(req.GetChecksumFields()...).Canonical()
+	if err != nil {
+		return "", err
+	}
+
+	keyset := []string{}
+	var (
+		snapshot  int64
+		upstream  []byte
+		composite *compositeWire
+	)
+	if token != nil {
+		keyset = encodeKeysetValues(token.payload.vs)
+		composite = encodeComposite(token.composite)
+		if !token.snapshot.IsZero() {
+			snapshot = token.snapshot.UnixNano()
+		}
+		if token.hasUpstream {
+			upstream = append([]byte{1}, token.upstream...)
+		}
+	}
+	cursor, err := json.Marshal(keyset)
+	if err != nil {
+		return "", err
+	}
+	parts, err := json.Marshal(composite)
+	if err != nil {
+		return "", err
+	}
+
+	hashed := [][]byte{fields, cursor, parts, upstream}
+	// named keysets are only hashed if present, which keeps the keys of
+	// single-list tokens unchanged
+	if token != nil && token.groups != nil {
+		groups, err := json.Marshal(encodeGroups(token.groups))
+		if err != nil {
+			return "", err
+		}
+		hashed = append(hashed, groups)
+	}
+
+	h := sha256.New()
+	// prefix all parts with their length so that they cannot run into each
+	// other
+	for _, part := range hashed {
+		_ = binary.Write(h, binary.BigEndian, uint64(len(part)))
+		h.Write(part)
+	}
+	_ = binary.Write(h, binary.BigEndian, snapshot)
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// ETag returns a strong ETag of the page requested by req at the cursor
+// position of token, the quoted CacheKey.
+func ETag(req Request, token *KeysetToken) (string, error) {
+	k, err := CacheKey(req, token)
+	if err != nil {
+		return "", err
+	}
+
+	return `"` + k + `"`, nil
+}
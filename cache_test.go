@@ -0,0 +1,104 @@
+package pagetoken_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/encryption"
+	"github.com/pixlcrashr/go-pagetoken/order"
+)
+
+var _ = Describe("CacheKey", func() {
+	var rr *pagetoken.RequestReader
+
+	BeforeEach(func() {
+		key, err := encryption.Rand32ByteKey()
+		Expect(err).NotTo(HaveOccurred())
+		e, err := encryption.NewAEADEncryptor(key)
+		Expect(err).NotTo(HaveOccurred())
+		rr = pagetoken.NewRequestReader(pagetoken.WithEncryptor(e))
+	})
+
+	// cursor returns a serialized token positioned after the given id.
+	cursor := func(status string, id int) string {
+		t, err := rr.Read(filterRequest{status: status})
+		Expect(err).NotTo(HaveOccurred())
+		s, err := t.Next(pagetoken.WithKeysetPayload(
+			pagetoken.NewKeysetPayloadBuilder().AddInt("id", id, order.Asc).Build(),
+		)).String()
+		Expect(err).NotTo(HaveOccurred())
+		return s
+	}
+
+	keyOf := func(req filterRequest) string {
+		t, err := rr.Read(req)
+		Expect(err).NotTo(HaveOccurred())
+		k, err := pagetoken.CacheKey(req, t)
+		Expect(err).NotTo(HaveOccurred())
+		return k
+	}
+
+	It("is equal for different encryptions of the same cursor", func() {
+		s1, s2 := cursor("active", 42), cursor("active", 42)
+		Expect(s1).NotTo(Equal(s2))
+
+		Expect(keyOf(filterRequest{status: "active", token: s1})).
+			To(Equal(keyOf(filterRequest{status: "active", token: s2})))
+	})
+
+	It("changes with the filter", func() {
+		Expect(keyOf(filterRequest{status: "active"})).
+			NotTo(Equal(keyOf(filterRequest{status: "inactive"})))
+	})
+
+	It("changes with the cursor position", func() {
+		Expect(keyOf(filterRequest{status: "active", token: cursor("active", 42)})).
+			NotTo(Equal(keyOf(filterRequest{status: "active", token: cursor("active", 43)})))
+		Expect(keyOf(filterRequest{status: "active", token: cursor("active", 42)})).
+			NotTo(Equal(keyOf(filterRequest{status: "active"})))
+	})
+
+	It("changes with the snapshot time", func() {
+		req := filterRequest{status: "active"}
+		t, err := rr.Read(req)
+		Expect(err).NotTo(HaveOccurred())
+
+		k1, err := pagetoken.CacheKey(req, t)
+		Expect(err).NotTo(HaveOccurred())
+		k2, err := pagetoken.CacheKey(req, t.Next(pagetoken.WithSnapshotTime(time.Unix(1, 0))))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(k1).NotTo(Equal(k2))
+	})
+
+	It("changes with the upstream token", func() {
+		req := filterRequest{status: "active"}
+		t, err := rr.Read(req)
+		Expect(err).NotTo(HaveOccurred())
+
+		k1, err := pagetoken.CacheKey(req, t.Next(pagetoken.WithUpstreamToken("a")))
+		Expect(err).NotTo(HaveOccurred())
+		k2, err := pagetoken.CacheKey(req, t.Next(pagetoken.WithUpstreamToken("b")))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(k1).NotTo(Equal(k2))
+	})
+
+	It("treats a nil token as the first page", func() {
+		req := filterRequest{status: "active"}
+		k, err := pagetoken.CacheKey(req, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(k).To(Equal(keyOf(req)))
+	})
+
+	Describe("ETag", func() {
+		It("is the quoted cache key", func() {
+			req := filterRequest{status: "active"}
+			etag, err := pagetoken.ETag(req, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(etag).To(Equal(`"` + keyOf(req) + `"`))
+			Expect(etag).To(MatchRegexp(`^"[0-9a-f]{64}"$`))
+		})
+	})
+})
@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"encoding/json"
 	"hash/crc32"
+	"slices"
+	"strings"
 )
 
 const DefaultChecksumMask = 0x58AEF322
@@ -15,6 +17,9 @@ func checksum(data []byte, mask uint32) uint32 {
 type Builder struct {
 	mask   uint32
 	fields []string
+	// defaulted holds for every field whether it was added by
+	// FieldWithDefault with its default value.
+	defaulted []bool
 }
 
 func NewBuilder(opts ...BuilderOpt) *Builder {
@@ -31,12 +36,58 @@ func NewBuilder(opts ...BuilderOpt) *Builder {
 }
 
 func (b *Builder) Build() (uint32, error) {
-	bs := bytes.NewBuffer(nil)
-	if err := json.NewEncoder(bs).Encode(b.fields); err != nil {
+	bs, err := b.Canonical()
+	if err != nil {
 		return 0, err
 	}
 
-	return checksum(bs.Bytes(), b.mask), nil
+	return checksum(bs, b.mask), nil
+}
+
+// Len returns the number of fields added to b, including those added by
+// FieldWithDefault with their default value.
+func (b *Builder) Len() int {
+	return len(b.fields) / 2
+}
+
+// BuildWithDefaults is like Build, but includes the fields added by
+// FieldWithDefault with their default value, as if they had been added by
+// Field. It is the checksum of tokens issued while such a field was still
+// added by Field.
+func (b *Builder) BuildWithDefaults() (uint32, error) {
+	bs, err := b.canonical(true)
+	if err != nil {
+		return 0, err
+	}
+
+	return checksum(bs, b.mask), nil
+}
+
+// Canonical returns the encoding of the fields the checksum is computed
+// from. Equal fields, added in the same order, always encode to the same
+// bytes; the mask is not part of it.
+func (b *Builder) Canonical() ([]byte, error) {
+	return b.canonical(false)
+}
+
+func (b *Builder) canonical(withDefaults bool) ([]byte, error) {
+	fields := b.fields
+	if !withDefaults && slices.Contains(b.defaulted, true) {
+		fields = make([]string, 0, len(b.fields))
+		for i := 0; i < len(b.fields); i += 2 {
+			if b.defaulted[i/2] {
+				continue
+			}
+			fields = append(fields, b.fields[i], b.fields[i+1])
+		}
+	}
+
+	bs := bytes.NewBuffer(nil)
+	if err := json.NewEncoder(bs).Encode(fields); err != nil {
+		return nil, err
+	}
+
+	return bs.Bytes(), nil
 }
 
 type BuilderOpt func(*Builder)
@@ -50,5 +101,79 @@ func Mask(mask uint32) BuilderOpt {
 func Field(key, value string) BuilderOpt {
 	return func(b *Builder) {
 		b.fields = append(b.fields, key, value)
+		b.defaulted = append(b.defaulted, false)
 	}
 }
+
+// FieldWithDefault is like Field, but leaves the field out of the checksum
+// while value equals defaultValue. A field added to the checksum fields of a
+// request this way keeps the tokens issued before it existed valid, as long
+// as the client sends the default value; tokens are still rejected once it
+// sends another one.
+func FieldWithDefault(key, value, defaultValue string) BuilderOpt {
+	return func(b *Builder) {
+		b.fields = append(b.fields, key, value)
+		b.defaulted = append(b.defaulted, value == defaultValue)
+	}
+}
+
+// Without removes all fields with the given keys added by the options
+// applied before it. It lets a caller drop fields by convention, e.g. a page
+// size that may change between pages, without knowing how they were added.
+func Without(keys ...string) BuilderOpt {
+	return func(b *Builder) {
+		fields, defaulted := b.fields[:0], b.defaulted[:0]
+		for i := 0; i < len(b.fields); i += 2 {
+			if slices.Contains(keys, b.fields[i]) {
+				continue
+			}
+			fields = append(fields, b.fields[i], b.fields[i+1])
+			defaulted = append(defaulted, b.defaulted[i/2])
+		}
+		b.fields, b.defaulted = fields, defaulted
+	}
+}
+
+// FieldMask adds a field mask, e.g. the paths of a fields= projection, as
+// the field key. The paths are normalized first, so that masks selecting the
+// same fields yield the same checksum: surrounding whitespace and duplicates
+// are dropped, a path whose parent is included is collapsed into the parent
+// ("author.name" into "author"), a "*" selects everything, and the rest is
+// sorted. An empty mask is encoded as the empty string.
+func FieldMask(key string, paths []string) BuilderOpt {
+	return Field(key, strings.Join(NormalizeFieldMask(paths), ","))
+}
+
+// NormalizeFieldMask returns the normalized form of paths FieldMask adds to
+// the checksum. paths is not modified.
+func NormalizeFieldMask(paths []string) []string {
+	ps := make([]string, 0, len(paths))
+	for _, p := range paths {
+		p = strings.TrimSpace(p)
+		if p == "*" {
+			return []string{"*"}
+		}
+		if p != "" {
+			ps = append(ps, p)
+		}
+	}
+
+	// comparing by segments sorts a parent right before its children, e.g.
+	// "a", "a.b", "a.b.c", "ab"
+	slices.SortFunc(ps, func(a, b string) int {
+		return slices.Compare(strings.Split(a, "."), strings.Split(b, "."))
+	})
+
+	out := ps[:0]
+	for _, p := range ps {
+		if len(out) > 0 {
+			last := out[len(out)-1]
+			if p == last || strings.HasPrefix(p, last+".") {
+				continue
+			}
+		}
+		out = append(out, p)
+	}
+
+	return out
+}
@@ -84,4 +84,83 @@ var _ = Describe("Checksum", func() {
 
 		Expect(crc1).To(Equal(crc2))
 	})
+
+	It("should drop fields removed by Without", func() {
+		cb1 := checksum.NewBuilder()
+		checksum.Field("key1", "value1")(cb1)
+		checksum.Field("page_size", "10")(cb1)
+		checksum.Field("key2", "value2")(cb1)
+		checksum.Without("page_size")(cb1)
+		crc1, err := cb1.Build()
+		Expect(err).ToNot(HaveOccurred())
+
+		cb2 := checksum.NewBuilder()
+		checksum.Field("key1", "value1")(cb2)
+		checksum.Field("key2", "value2")(cb2)
+		crc2, err := cb2.Build()
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(crc1).To(Equal(crc2))
+	})
+
+	It("should encode the fields independently of the mask", func() {
+		bs1, err := checksum.NewBuilder(checksum.Field("key1", "value1")).Canonical()
+		Expect(err).ToNot(HaveOccurred())
+
+		bs2, err := checksum.NewBuilder(checksum.Mask(0x1), checksum.Field("key1", "value1")).Canonical()
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(bs1).To(Equal(bs2))
+		Expect(string(bs1)).To(Equal("[\"key1\",\"value1\"]\n"))
+	})
+
+	It("should leave out fields with their default value", func() {
+		crc1, err := checksum.NewBuilder(
+			checksum.Field("key1", "value1"),
+			checksum.FieldWithDefault("region", "eu", "eu"),
+		).Build()
+		Expect(err).ToNot(HaveOccurred())
+
+		crc2, err := checksum.NewBuilder(checksum.Field("key1", "value1")).Build()
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(crc1).To(Equal(crc2))
+	})
+
+	It("should include fields with another value than their default", func() {
+		crc1, err := checksum.NewBuilder(checksum.FieldWithDefault("region", "us", "eu")).Build()
+		Expect(err).ToNot(HaveOccurred())
+
+		crc2, err := checksum.NewBuilder(checksum.Field("region", "us")).Build()
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(crc1).To(Equal(crc2))
+	})
+
+	It("should include fields with their default value in BuildWithDefaults", func() {
+		cb := checksum.NewBuilder(
+			checksum.FieldWithDefault("region", "eu", "eu"),
+			checksum.Field("key1", "value1"),
+		)
+		crc1, err := cb.BuildWithDefaults()
+		Expect(err).ToNot(HaveOccurred())
+
+		crc2, err := checksum.NewBuilder(checksum.Field("region", "eu"), checksum.Field("key1", "value1")).Build()
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(crc1).To(Equal(crc2))
+	})
+
+	It("should keep defaults of fields not removed by Without", func() {
+		cb := checksum.NewBuilder(
+			checksum.Field("page_size", "10"),
+			checksum.FieldWithDefault("region", "eu", "eu"),
+			checksum.Field("key1", "value1"),
+		)
+		checksum.Without("page_size")(cb)
+		bs, err := cb.Canonical()
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(string(bs)).To(Equal("[\"key1\",\"value1\"]\n"))
+	})
 })
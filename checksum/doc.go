@@ -196,6 +196,29 @@
 //	    // Always use consistent field ordering in your application
 //	}
 //
+// # Adding Fields
+//
+// Adding a field to the checksum fields of a request changes the checksum
+// of every request, so all tokens issued before fail to validate. A new
+// optional parameter with a server-side default is therefore added with
+// FieldWithDefault, which leaves it out of the checksum while it holds its
+// default:
+//
+//	checksum.FieldWithDefault("region", r.Region, "eu")
+//
+// Tokens issued before the field existed stay valid for clients sending the
+// default, and are rejected once a client sends another value.
+//
+// # Field Masks and Views
+//
+// Parameters selecting a projection, such as a fields= mask or a view, can
+// change which rows are visible and belong in the checksum. Field masks are
+// added with FieldMask, which normalizes the paths so that equivalent masks
+// in a different order yield the same checksum:
+//
+//	checksum.FieldMask("fields", strings.Split(r.Fields, ",")),
+//	checksum.Field("view", r.View),
+//
 // # Default Mask
 //
 // The default checksum mask is 0x58AEF322. This mask is XORed with the CRC32
@@ -0,0 +1,49 @@
+package checksum_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken/checksum"
+)
+
+func fieldMaskChecksum(paths ...string) uint32 {
+	crc, err := checksum.NewBuilder(checksum.FieldMask("fields", paths)).Build()
+	Expect(err).NotTo(HaveOccurred())
+	return crc
+}
+
+var _ = Describe("FieldMask", func() {
+	DescribeTable("normalizes paths",
+		func(paths, normalized []string) {
+			Expect(checksum.NormalizeFieldMask(paths)).To(Equal(normalized))
+		},
+		Entry("empty", nil, []string{}),
+		Entry("sorted", []string{"title", "author"}, []string{"author", "title"}),
+		Entry("deduplicated", []string{"title", "title"}, []string{"title"}),
+		Entry("trimmed", []string{" title ", ""}, []string{"title"}),
+		Entry("children collapsed into parents", []string{"author.name", "author", "author.address.city"}, []string{"author"}),
+		Entry("siblings sharing a prefix kept", []string{"authors", "author.name", "author"}, []string{"author", "authors"}),
+		Entry("children of different parents kept", []string{"b.x", "a.y"}, []string{"a.y", "b.x"}),
+		Entry("wildcard", []string{"title", "*"}, []string{"*"}),
+	)
+
+	It("leaves its input unmodified", func() {
+		paths := []string{"title", "author.name", "author"}
+		checksum.NormalizeFieldMask(paths)
+		Expect(paths).To(Equal([]string{"title", "author.name", "author"}))
+	})
+
+	It("yields equal checksums for equivalent masks", func() {
+		Expect(fieldMaskChecksum("title", "author")).To(Equal(fieldMaskChecksum("author", "title")))
+		Expect(fieldMaskChecksum("author", "author.name")).To(Equal(fieldMaskChecksum("author")))
+		Expect(fieldMaskChecksum("title", " title")).To(Equal(fieldMaskChecksum("title")))
+	})
+
+	It("yields different checksums for different masks", func() {
+		Expect(fieldMaskChecksum("title")).NotTo(Equal(fieldMaskChecksum("author")))
+		Expect(fieldMaskChecksum("author.name")).NotTo(Equal(fieldMaskChecksum("author")))
+		Expect(fieldMaskChecksum("title")).NotTo(Equal(fieldMaskChecksum("title", "author")))
+		Expect(fieldMaskChecksum()).NotTo(Equal(fieldMaskChecksum("*")))
+	})
+})
@@ -0,0 +1,88 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+)
+
+// DefaultMaxPages is the page limit of All if none is given.
+const DefaultMaxPages = 1000
+
+// ErrMaxPagesExceeded is returned by All if the listing has more pages than
+// allowed.
+var ErrMaxPagesExceeded = errors.New("maximum number of pages exceeded")
+
+// FetchFunc fetches the page with the given page token, which is empty for
+// the first page. It returns the items of the page and the token of the
+// next page, which is empty on the last page.
+type FetchFunc[T any] func(ctx context.Context, pageToken string) (items []T, next string, err error)
+
+// Iterate returns an iterator over the items of all pages returned by
+// fetch, starting with the first page. Pages are fetched lazily as the
+// iteration proceeds and it ends after the page without a next page token.
+//
+// If fetch fails or ctx is done before a page is fetched, the error is
+// yielded with the zero value of T as the last element.
+func Iterate[T any](ctx context.Context, fetch FetchFunc[T]) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		err := walk(ctx, fetch, 0, func(items []T) bool {
+			for _, item := range items {
+				if !yield(item, nil) {
+					return false
+				}
+			}
+			return true
+		})
+		if err != nil {
+			var zero T
+			yield(zero, err)
+		}
+	}
+}
+
+// All returns the items of all pages returned by fetch. It fetches at most
+// maxPages pages, or DefaultMaxPages if maxPages is not positive, and fails
+// with ErrMaxPagesExceeded if there are more, guarding against APIs that
+// never return an empty next page token. On error, the items fetched so far
+// are returned along with it.
+func All[T any](ctx context.Context, fetch FetchFunc[T], maxPages int) ([]T, error) {
+	if maxPages <= 0 {
+		maxPages = DefaultMaxPages
+	}
+
+	all := []T{}
+	err := walk(ctx, fetch, maxPages, func(items []T) bool {
+		all = append(all, items...)
+		return true
+	})
+
+	return all, err
+}
+
+// walk passes the pages returned by fetch to page until the last page, or
+// until page returns false. If maxPages is positive, fetching more pages
+// fails with ErrMaxPagesExceeded.
+func walk[T any](ctx context.Context, fetch FetchFunc[T], maxPages int, page func([]T) bool) error {
+	token := ""
+	for n := 0; ; n++ {
+		if maxPages > 0 && n == maxPages {
+			return fmt.Errorf("%w: more than %d pages", ErrMaxPagesExceeded, maxPages)
+		}
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		items, next, err := fetch(ctx, token)
+		if err != nil {
+			return err
+		}
+
+		if !page(items) || next == "" {
+			return nil
+		}
+		token = next
+	}
+}
@@ -0,0 +1,150 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken/client"
+)
+
+// server serves the numbers 1 to total in pages of size items. The page
+// token is the last number of the previous page. If failAt is positive,
+// fetching the page starting after failAt fails.
+type server struct {
+	total, size, failAt int
+	calls               int
+	tokens              []string
+}
+
+var errUnavailable = errors.New("service unavailable")
+
+func (s *server) fetch(_ context.Context, pageToken string) ([]int, string, error) {
+	s.calls++
+	s.tokens = append(s.tokens, pageToken)
+
+	after := 0
+	if pageToken != "" {
+		var err error
+		if after, err = strconv.Atoi(pageToken); err != nil {
+			return nil, "", err
+		}
+	}
+	if s.failAt > 0 && after == s.failAt {
+		return nil, "", errUnavailable
+	}
+
+	items := []int{}
+	for n := after + 1; n <= s.total && len(items) < s.size; n++ {
+		items = append(items, n)
+	}
+
+	next := ""
+	if len(items) > 0 && items[len(items)-1] < s.total {
+		next = strconv.Itoa(items[len(items)-1])
+	}
+	return items, next, nil
+}
+
+var _ = Describe("Iterate", func() {
+	collect := func(s *server, ctx context.Context) ([]int, error) {
+		items := []int{}
+		for item, err := range client.Iterate(ctx, s.fetch) {
+			if err != nil {
+				return items, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	}
+
+	It("walks all pages until the next page token is empty", func() {
+		s := &server{total: 7, size: 3}
+		items, err := collect(s, context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(items).To(Equal([]int{1, 2, 3, 4, 5, 6, 7}))
+		Expect(s.tokens).To(Equal([]string{"", "3", "6"}))
+	})
+
+	It("handles an empty listing", func() {
+		s := &server{total: 0, size: 3}
+		items, err := collect(s, context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(items).To(BeEmpty())
+		Expect(s.calls).To(Equal(1))
+	})
+
+	It("yields the items before an error in the middle of the stream", func() {
+		s := &server{total: 10, size: 3, failAt: 6}
+		items, err := collect(s, context.Background())
+		Expect(err).To(MatchError(errUnavailable))
+		Expect(items).To(Equal([]int{1, 2, 3, 4, 5, 6}))
+	})
+
+	It("fetches no further pages once the consumer stops", func() {
+		s := &server{total: 10, size: 3}
+		for item, err := range client.Iterate(context.Background(), s.fetch) {
+			Expect(err).NotTo(HaveOccurred())
+			if item == 4 {
+				break
+			}
+		}
+		Expect(s.calls).To(Equal(2))
+	})
+
+	It("stops with the context error once the context is canceled", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		DeferCleanup(cancel)
+		s := &server{total: 10, size: 3}
+
+		items := []int{}
+		var iterErr error
+		for item, err := range client.Iterate(ctx, s.fetch) {
+			if err != nil {
+				iterErr = err
+				break
+			}
+			items = append(items, item)
+			if item == 3 {
+				cancel()
+			}
+		}
+
+		Expect(iterErr).To(MatchError(context.Canceled))
+		Expect(items).To(Equal([]int{1, 2, 3}))
+		Expect(s.calls).To(Equal(1))
+	})
+})
+
+var _ = Describe("All", func() {
+	It("collects the items of all pages", func() {
+		s := &server{total: 5, size: 2}
+		items, err := client.All(context.Background(), s.fetch, 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(items).To(Equal([]int{1, 2, 3, 4, 5}))
+	})
+
+	It("fails once the listing has more pages than allowed", func() {
+		s := &server{total: 10, size: 2}
+		items, err := client.All(context.Background(), s.fetch, 3)
+		Expect(err).To(MatchError(client.ErrMaxPagesExceeded))
+		Expect(items).To(Equal([]int{1, 2, 3, 4, 5, 6}))
+		Expect(s.calls).To(Equal(3))
+	})
+
+	It("accepts a listing of exactly the allowed number of pages", func() {
+		s := &server{total: 6, size: 2}
+		_, err := client.All(context.Background(), s.fetch, 3)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("returns the items fetched before an error", func() {
+		s := &server{total: 10, size: 2, failAt: 4}
+		items, err := client.All(context.Background(), s.fetch, 0)
+		Expect(err).To(MatchError(errUnavailable))
+		Expect(items).To(Equal([]int{1, 2, 3, 4}))
+	})
+})
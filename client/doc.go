@@ -0,0 +1,29 @@
+// Package client iterates over paginated APIs that follow the page token
+// conventions of the pagetoken package.
+//
+// Clients never look into page tokens: they pass the token returned with one
+// page to the request of the next and stop once the returned token is empty.
+// The package therefore needs no crypter and works with any API returning
+// opaque page tokens, over HTTP, gRPC or anything else.
+//
+// # Example
+//
+//	fetch := func(ctx context.Context, pageToken string) ([]*User, string, error) {
+//	    resp, err := api.ListUsers(ctx, &ListUsersRequest{PageToken: pageToken})
+//	    if err != nil {
+//	        return nil, "", err
+//	    }
+//	    return resp.Users, resp.NextPageToken, nil
+//	}
+//
+//	for u, err := range client.Iterate(ctx, fetch) {
+//	    if err != nil {
+//	        return err
+//	    }
+//	    fmt.Println(u.Name)
+//	}
+//
+// All collects all items at once, up to a maximum number of pages:
+//
+//	users, err := client.All(ctx, fetch, 100)
+package client
@@ -0,0 +1,149 @@
+package pagetoken
+
+import (
+	"maps"
+	"slices"
+)
+
+// CompositePayload holds a keyset per named part of a fanned-out listing,
+// e.g. one cursor per database shard whose sorted results are merged into
+// one page. It is stored on a KeysetToken next to its keyset payload, see
+// WithCompositePayload, and protected by the same checksum.
+//
+// A part without a keyset starts at its first row. A part marked done via
+// SetDone has no rows left and need not be queried again.
+type CompositePayload struct {
+	parts map[string]*KeysetPayload
+	done  map[string]bool
+}
+
+// NewCompositePayload returns an empty composite payload, in which every
+// part starts at its first row.
+func NewCompositePayload() *CompositePayload {
+	return &CompositePayload{
+		parts: map[string]*KeysetPayload{},
+		done:  map[string]bool{},
+	}
+}
+
+// Set sets the keyset of the named part. A nil keyset makes the part start
+// at its first row.
+func (c *CompositePayload) Set(name string, p *KeysetPayload) {
+	delete(c.done, name)
+	if p == nil {
+		delete(c.parts, name)
+		return
+	}
+	c.parts[name] = p
+}
+
+// Get returns the keyset of the named part, or nil if it starts at its
+// first row or is done.
+func (c *CompositePayload) Get(name string) *KeysetPayload {
+	return c.parts[name]
+}
+
+// SetDone marks the named part as having no rows left.
+func (c *CompositePayload) SetDone(name string) {
+	delete(c.parts, name)
+	c.done[name] = true
+}
+
+// Done reports whether the named part has no rows left.
+func (c *CompositePayload) Done(name string) bool {
+	return c.done[name]
+}
+
+// Names returns the names of all parts with a keyset or marked done, in
+// sorted order.
+func (c *CompositePayload) Names() []string {
+	names := slices.Collect(maps.Keys(c.parts))
+	for name := range c.done {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// clone returns a copy of c whose parts can be set without affecting c, or
+// nil if c is nil. The keysets of the parts are shared, as they are
+// immutable.
+func (c *CompositePayload) clone() *CompositePayload {
+	if c == nil {
+		return nil
+	}
+
+	return &CompositePayload{
+		parts: maps.Clone(c.parts),
+		done:  maps.Clone(c.done),
+	}
+}
+
+// CompositePayload returns the per-part keysets stored via
+// WithCompositePayload, or nil if there are none.
+func (c *KeysetToken) CompositePayload() *CompositePayload {
+	return c.composite
+}
+
+// WithCompositePayload stores the per-part keysets of a fanned-out listing
+// on the token. It is carried over by Next like the keyset payload. Every
+// part lengthens the token, which may need a higher WithMaxTokenLength in
+// Middleware, see DefaultMaxTokenLength.
+func WithCompositePayload(p *CompositePayload) KeysetTokenOpt {
+	return func(c *KeysetToken) {
+		c.composite = p
+	}
+}
+
+// compositeWire is the plaintext encoding of a CompositePayload. Both maps
+// and the sorted done list encode deterministically.
+type compositeWire struct {
+	Parts map[string][]string `json:"p,omitempty"`
+	Done  []string            `json:"d,omitempty"`
+	// Sensitive holds the positions of the sensitive values of the parts
+	// that have any.
+	Sensitive map[string][]int `json:"r,omitempty"`
+}
+
+func encodeComposite(c *CompositePayload) *compositeWire {
+	if c == nil {
+		return nil
+	}
+
+	w := &compositeWire{Parts: map[string][]string{}}
+	for name, p := range c.parts {
+		w.Parts[name] = encodeKeysetValues(p.Values())
+		if is := sensitivePositions(p.Values()); is != nil {
+			if w.Sensitive == nil {
+				w.Sensitive = map[string][]int{}
+			}
+			w.Sensitive[name] = is
+		}
+	}
+	w.Done = slices.Sorted(maps.Keys(c.done))
+
+	return w
+}
+
+func decodeComposite(w *compositeWire) (*CompositePayload, error) {
+	if w == nil {
+		return nil, nil
+	}
+
+	c := NewCompositePayload()
+	for name, ps := range w.Parts {
+		vs, err := decodeKeysetValues(ps)
+		if err != nil {
+			return nil, err
+		}
+		if err := markSensitive(vs, w.Sensitive[name]); err != nil {
+			return nil, err
+		}
+		c.parts[name] = &KeysetPayload{vs: vs}
+	}
+	for _, name := range w.Done {
+		c.done[name] = true
+	}
+
+	return c, nil
+}
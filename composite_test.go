@@ -0,0 +1,67 @@
+package pagetoken_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/encryption"
+	"github.com/pixlcrashr/go-pagetoken/order"
+)
+
+var _ = Describe("CompositePayload", func() {
+	var rr *pagetoken.RequestReader
+
+	BeforeEach(func() {
+		key, err := encryption.Rand32ByteKey()
+		Expect(err).NotTo(HaveOccurred())
+		e, err := encryption.NewAEADEncryptor(key)
+		Expect(err).NotTo(HaveOccurred())
+		rr = pagetoken.NewRequestReader(pagetoken.WithEncryptor(e))
+	})
+
+	It("round-trips the parts inside one token with the request checksum", func() {
+		c := pagetoken.NewCompositePayload()
+		c.Set("shard-1", pagetoken.NewKeysetPayloadBuilder().AddInt("id", 7, order.Asc).Build())
+		c.Set("shard-2", pagetoken.NewKeysetPayloadBuilder().AddString("name", "b", order.Desc).Build())
+		c.SetDone("shard-3")
+
+		t, err := rr.Read(filterRequest{status: "active"})
+		Expect(err).NotTo(HaveOccurred())
+		s, err := t.Next(pagetoken.WithCompositePayload(c)).String()
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = rr.Read(filterRequest{status: "inactive", token: s})
+		Expect(err).To(MatchError(pagetoken.ErrChecksumMismatch))
+
+		t, err = rr.Read(filterRequest{status: "active", token: s})
+		Expect(err).NotTo(HaveOccurred())
+		got := t.Next().CompositePayload()
+		Expect(got.Names()).To(Equal([]string{"shard-1", "shard-2", "shard-3"}))
+
+		id, _, err := got.Get("shard-1").Int("id")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(id).To(Equal(7))
+		Expect(got.Get("shard-2").Values()).To(Equal([]pagetoken.KeysetValue{
+			{Path: "name", Order: order.Desc, Value: "b"},
+		}))
+		Expect(got.Get("shard-3")).To(BeNil())
+		Expect(got.Done("shard-3")).To(BeTrue())
+		Expect(got.Get("shard-4")).To(BeNil())
+		Expect(got.Done("shard-4")).To(BeFalse())
+	})
+
+	It("has no composite payload unless one is set", func() {
+		t, err := rr.Read(filterRequest{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(t.CompositePayload()).To(BeNil())
+	})
+
+	It("starts a part over once its keyset is set to nil", func() {
+		c := pagetoken.NewCompositePayload()
+		c.SetDone("a")
+		c.Set("a", nil)
+		Expect(c.Done("a")).To(BeFalse())
+		Expect(c.Names()).To(BeEmpty())
+	})
+})
@@ -0,0 +1,121 @@
+package pagetoken_test
+
+import (
+	"context"
+	"strconv"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/encryption"
+	"github.com/pixlcrashr/go-pagetoken/order"
+)
+
+// The specs below are meant to be run with the race detector, which reports
+// unsynchronized access to the state shared between the goroutines:
+//
+//	go test -race -tags pagetokendebug ./...
+var _ = Describe("Concurrent use", func() {
+	const goroutines, iterations = 16, 50
+
+	var (
+		e      *encryption.AEADEncryptor
+		rr     *pagetoken.RequestReader
+		parser *pagetoken.KeysetTokenParser
+	)
+
+	BeforeEach(func() {
+		key, err := encryption.Rand32ByteKey()
+		Expect(err).NotTo(HaveOccurred())
+		e, err = encryption.NewAEADEncryptor(key)
+		Expect(err).NotTo(HaveOccurred())
+
+		rr = pagetoken.NewRequestReader(
+			pagetoken.WithEncryptor(e),
+			pagetoken.WithRevocationCheck(func(context.Context, pagetoken.TokenInfo) error { return nil }),
+		)
+		parser = pagetoken.NewKeysetTokenParser(pagetoken.WithKeysetTokenEncryptor(e))
+	})
+
+	hammer := func(fn func(g, i int)) {
+		var wg sync.WaitGroup
+		for g := range goroutines {
+			wg.Go(func() {
+				defer GinkgoRecover()
+
+				for i := range iterations {
+					fn(g, i)
+				}
+			})
+		}
+		wg.Wait()
+	}
+
+	It("shares a RequestReader between goroutines", func() {
+		hammer(func(g, i int) {
+			status := "status-" + strconv.Itoa(g)
+
+			first, err := rr.Read(filterRequest{status: status})
+			Expect(err).NotTo(HaveOccurred())
+
+			s, err := first.Next(pagetoken.WithKeysetPayload(
+				pagetoken.NewKeysetPayloadBuilder().AddInt("id", i, order.Asc).Build(),
+			)).String()
+			Expect(err).NotTo(HaveOccurred())
+
+			t, err := rr.Read(filterRequest{status: status, token: s})
+			Expect(err).NotTo(HaveOccurred())
+			id, _, err := t.Payload().Int("id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(id).To(Equal(i))
+
+			t, err = rr.ReadContext(pagetoken.NewContext(context.Background(), t), filterRequest{status: status})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rr.Verify(t, filterRequest{status: status + "!"})).To(MatchError(pagetoken.ErrChecksumMismatch))
+		})
+	})
+
+	It("shares a KeysetTokenParser and a token between goroutines", func() {
+		shared := pagetoken.NewKeysetToken(e,
+			pagetoken.WithKeysetPayload(pagetoken.NewKeysetPayloadBuilder().AddString("name", "shared", order.Desc).Build()),
+			pagetoken.WithTotalCount(42),
+		)
+
+		hammer(func(int, int) {
+			s, err := shared.String()
+			Expect(err).NotTo(HaveOccurred())
+
+			t, err := parser.Parse(s)
+			Expect(err).NotTo(HaveOccurred())
+			name, _, err := t.Payload().String("name")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(name).To(Equal("shared"))
+
+			Expect(shared.Next().PageIndex()).To(Equal(1))
+		})
+	})
+
+	It("shares a large KeysetPayload between goroutines looking up its fields", func() {
+		s, err := pagetoken.NewKeysetToken(e, pagetoken.WithKeysetPayload(benchmarkPayload(32))).String()
+		Expect(err).NotTo(HaveOccurred())
+
+		// parsed, so that the lookup index is built under contention
+		t, err := parser.Parse(s)
+		Expect(err).NotTo(HaveOccurred())
+		p := t.Payload()
+
+		hammer(func(g, i int) {
+			j := (g + i) % 32
+			if j%3 == 2 {
+				id, _, err := p.Int64("id_" + strconv.Itoa(j))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(id).To(Equal(int64(j) * 7919))
+			}
+
+			_, _, err := p.String("missing")
+			Expect(err).To(MatchError(pagetoken.ErrFieldNotFound))
+		})
+	})
+})
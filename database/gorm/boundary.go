@@ -0,0 +1,57 @@
+package gorm
+
+import (
+	"errors"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/database/sqlraw"
+	"gorm.io/gorm"
+)
+
+// boundaryRowColumn is the column SampleBoundaries numbers the rows in.
+const boundaryRowColumn = "pagetoken_row"
+
+// SampleBoundaries loads every every-th row of db in the order of spec, i.e.
+// the last row of every full page of every rows, using the row_number()
+// window function. The keysets of the rows, built from spec like the
+// keysets of the pages, are the boundaries of pagetoken.BoundaryTokens:
+//
+//	rows, err := ptgorm.SampleBoundaries[Book](db.Model(&Book{}), spec, 50)
+//	...
+//	boundaries := make([]*pagetoken.KeysetPayload, len(rows))
+//	for i, row := range rows {
+//		boundaries[i], err = bookKeyset(row, spec)
+//		...
+//	}
+//	links, err := pagetoken.BoundaryTokens(reader, req, boundaries)
+//
+// spec must end in a unique column, e.g. via pagetoken.EnsureTiebreak, for
+// the rows to be those the pages end with. Sampling visits all rows of db,
+// so it is meant to run periodically rather than per request.
+func SampleBoundaries[T any](db *gorm.DB, spec pagetoken.SortSpec, every int) ([]T, error) {
+	if every <= 0 {
+		return nil, errors.New("boundary interval must be positive")
+	}
+	if len(spec) == 0 {
+		return nil, errors.New("boundary sort spec must not be empty")
+	}
+
+	bOpts := []sqlraw.BuilderOpt{}
+	if fn := dialectColumnFn(db); fn != nil {
+		bOpts = append(bOpts, sqlraw.WithColumnFn(fn))
+	}
+
+	rows := db.Select("*, row_number() OVER (ORDER BY " + sqlraw.NewBuilder(bOpts...).OrderBy(spec) + ") AS " + boundaryRowColumn)
+
+	items := []T{}
+	err := db.Session(&gorm.Session{NewDB: true}).
+		Table("(?) AS pagetoken_boundaries", rows).
+		Where(boundaryRowColumn+" % ? = 0", every).
+		Order(boundaryRowColumn).
+		Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
@@ -0,0 +1,111 @@
+package gorm_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gorm.io/gorm"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	ptgorm "github.com/pixlcrashr/go-pagetoken/database/gorm"
+	"github.com/pixlcrashr/go-pagetoken/encryption"
+	"github.com/pixlcrashr/go-pagetoken/order"
+)
+
+var _ = Describe("SampleBoundaries", func() {
+	var (
+		db *gorm.DB
+		rr *pagetoken.RequestReader
+	)
+
+	// bySortDesc has ties on sort, which the id tiebreak breaks
+	bySortDesc := pagetoken.SortSpec{{Path: "sort", Order: order.Desc}, {Path: "id", Order: order.Asc}}
+
+	cfg := ptgorm.ListConfig[item]{
+		Spec:     bySortDesc,
+		ValueFn:  itemValue,
+		KeysetFn: itemKeysetFor,
+	}
+
+	BeforeEach(func() {
+		db = openDB(false)
+		seedItems(db, 20)
+
+		key, err := encryption.Rand32ByteKey()
+		Expect(err).NotTo(HaveOccurred())
+		e, err := encryption.NewAEADEncryptor(key)
+		Expect(err).NotTo(HaveOccurred())
+		rr = pagetoken.NewRequestReader(pagetoken.WithEncryptor(e))
+	})
+
+	boundaryTokens := func() []string {
+		rows, err := ptgorm.SampleBoundaries[item](db.Model(&item{}), bySortDesc, 4)
+		Expect(err).NotTo(HaveOccurred())
+
+		boundaries := make([]*pagetoken.KeysetPayload, len(rows))
+		for i, row := range rows {
+			boundaries[i], err = itemKeysetFor(row, bySortDesc)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		ts, err := pagetoken.BoundaryTokens(rr, listRequest{}, boundaries)
+		Expect(err).NotTo(HaveOccurred())
+		return ts
+	}
+
+	It("samples the last row of every full page", func() {
+		rows, err := ptgorm.SampleBoundaries[item](db.Model(&item{}), bySortDesc, 4)
+		Expect(err).NotTo(HaveOccurred())
+
+		// the order is 19, 20, 16, 17 | 18, 13, 14, 15 | 10, 11, 12, 7 |
+		// 8, 9, 4, 5 | 6, 1, 2, 3
+		got := []int{}
+		for _, row := range rows {
+			got = append(got, row.ID)
+		}
+		Expect(got).To(Equal([]int{17, 15, 7, 5, 3}))
+	})
+
+	It("jumps to the same rows as walking the pages", func() {
+		walked := [][]int{}
+		var keyset *pagetoken.KeysetPayload
+		for range 4 {
+			page, next, err := ptgorm.ListKeyset(db, keyset, 4, cfg)
+			Expect(err).NotTo(HaveOccurred())
+
+			pageIDs := []int{}
+			for _, it := range page {
+				pageIDs = append(pageIDs, it.ID)
+			}
+			walked = append(walked, pageIDs)
+			keyset = next
+		}
+
+		ts := boundaryTokens()
+		Expect(ts).To(HaveLen(5))
+
+		t, err := rr.Read(listRequest{token: ts[2]})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(t.PageIndex()).To(Equal(3))
+
+		page, _, err := ptgorm.ListKeyset(db, t.Payload(), 4, cfg)
+		Expect(err).NotTo(HaveOccurred())
+
+		jumped := []int{}
+		for _, it := range page {
+			jumped = append(jumped, it.ID)
+		}
+		Expect(jumped).To(Equal(walked[3]))
+	})
+
+	It("binds the tokens to the request", func() {
+		ts := boundaryTokens()
+
+		_, err := rr.Read(listRequest{token: ts[0]})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("rejects a non-positive interval", func() {
+		_, err := ptgorm.SampleBoundaries[item](db.Model(&item{}), bySortDesc, 0)
+		Expect(err).To(HaveOccurred())
+	})
+})
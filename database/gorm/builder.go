@@ -1,79 +1,210 @@
 package gorm
 
 import (
-	"fmt"
-	"strings"
+	"errors"
 
 	"github.com/pixlcrashr/go-pagetoken"
-	"github.com/pixlcrashr/go-pagetoken/order"
+	"github.com/pixlcrashr/go-pagetoken/database/sqlraw"
 	"gorm.io/gorm"
 )
 
+// ErrMissingTiebreak is returned in strict mode when the sort spec or the
+// keyset lacks the column required via WithRequiredTiebreak.
+var ErrMissingTiebreak = errors.New("keyset is missing the required tiebreak column")
+
 type KeysetWhereOrderLimitValueFn func(column string, payload *pagetoken.KeysetPayload) (any, error)
 
-func orderToSQL(o order.Order) string {
-	if o == order.Asc {
-		return "ASC"
+type keysetWhereOrderLimitConfig struct {
+	inclusiveTiebreak string
+	requiredTiebreak  string
+	strictTiebreak    bool
+	spec              pagetoken.SortSpec
+	columnFn          sqlraw.ColumnFn
+	upperBound        *pagetoken.KeysetPayload
+}
+
+type KeysetWhereOrderLimitOpt func(*keysetWhereOrderLimitConfig)
+
+// WithInclusiveBoundary compares the leading sort column inclusively and
+// excludes the already returned rows via tiebreakColumn, which must be the
+// last keyset column. See sqlraw.WithInclusiveBoundary for the generated
+// predicate and its trade-offs.
+func WithInclusiveBoundary(tiebreakColumn string) KeysetWhereOrderLimitOpt {
+	return func(c *keysetWhereOrderLimitConfig) {
+		c.inclusiveTiebreak = tiebreakColumn
+	}
+}
+
+// WithSortSpec sets the order of the first page, which has no keyset to
+// derive it from. Later pages are ordered by their keyset.
+func WithSortSpec(spec pagetoken.SortSpec) KeysetWhereOrderLimitOpt {
+	return func(c *keysetWhereOrderLimitConfig) {
+		c.spec = spec
+	}
+}
+
+// WithRequiredTiebreak appends column to the ORDER BY if the sort spec does
+// not contain it, see pagetoken.EnsureTiebreak. Keysets should then be built
+// from the same extended spec so that they carry the tiebreak value.
+//
+// A keyset without the tiebreak (e.g. one issued before the option was
+// enabled) is continued by its own columns, which may skip rows tied on all
+// of them at that single boundary. Use WithStrictTiebreak to reject such
+// keysets and sort specs instead.
+func WithRequiredTiebreak(column string) KeysetWhereOrderLimitOpt {
+	return func(c *keysetWhereOrderLimitConfig) {
+		c.requiredTiebreak = column
+	}
+}
+
+// WithStrictTiebreak makes a sort spec or keyset lacking the column required
+// via WithRequiredTiebreak fail with ErrMissingTiebreak instead of being
+// extended.
+func WithStrictTiebreak() KeysetWhereOrderLimitOpt {
+	return func(c *keysetWhereOrderLimitConfig) {
+		c.strictTiebreak = true
+	}
+}
+
+// WithColumnMapping renders keyset paths through fn instead of using them as
+// column names. Paths are then validated with pagetoken.ValidatePath, so a
+// token or sort spec carrying anything but (nested) identifiers fails with
+// an error wrapping pagetoken.ErrInvalidPath.
+func WithColumnMapping(fn sqlraw.ColumnFn) KeysetWhereOrderLimitOpt {
+	return func(c *keysetWhereOrderLimitConfig) {
+		c.columnFn = fn
+	}
+}
+
+// WithJSONPaths maps nested keyset paths such as "author.name" into the JSON
+// document stored in column, using fn to render the accessor expression for
+// db's dialect, e.g. sqlraw.PostgresJSONPath for data->'author'->>'name'.
+// Paths without a separator stay regular columns. See sqlraw.JSONColumns.
+func WithJSONPaths(column string, fn sqlraw.JSONPathFn) KeysetWhereOrderLimitOpt {
+	return WithColumnMapping(sqlraw.JSONColumns(column, fn))
+}
+
+// WithUpperBound excludes the rows after bound, the upper bound of a shard
+// token returned by pagetoken.SplitToken, see sqlraw.Builder.UpperBoundWhere:
+//
+//	q, err := ptgorm.KeysetWhereOrderLimit(db, t.Payload(), valueFn,
+//		ptgorm.WithUpperBound(t.UpperBound()),
+//	)
+//
+// The bound is converted by the value hook like the keyset. A nil bound,
+// that of unbounded tokens, has no effect.
+func WithUpperBound(bound *pagetoken.KeysetPayload) KeysetWhereOrderLimitOpt {
+	return func(c *keysetWhereOrderLimitConfig) {
+		c.upperBound = bound
+	}
+}
+
+// dialectColumnFn returns the column mapping db's dialect needs for keyset
+// paths, or nil if it takes them as they are. SQL Server reserves common
+// column names such as key and order, so columns are quoted in brackets
+// there; gorm binds the "?" placeholders for it.
+func dialectColumnFn(db *gorm.DB) sqlraw.ColumnFn {
+	if db.Dialector == nil || db.Dialector.Name() != "sqlserver" {
+		return nil
+	}
+
+	return func(path string) string {
+		return sqlraw.QuoteIdentifier(sqlraw.DialectSQLServer, path)
+	}
+}
+
+func (c *keysetWhereOrderLimitConfig) validatePaths(spec pagetoken.SortSpec) error {
+	if c.columnFn == nil {
+		return nil
+	}
+
+	for _, f := range spec {
+		if err := pagetoken.ValidatePath(f.Path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *keysetWhereOrderLimitConfig) ensureTiebreak(spec pagetoken.SortSpec) (pagetoken.SortSpec, error) {
+	if c.requiredTiebreak == "" {
+		return spec, nil
+	}
+
+	if c.strictTiebreak && !pagetoken.HasSortColumn(spec, c.requiredTiebreak) {
+		return nil, ErrMissingTiebreak
 	}
 
-	return "DESC"
+	return pagetoken.EnsureTiebreak(spec, c.requiredTiebreak), nil
 }
 
 func KeysetWhereOrderLimit(
 	db *gorm.DB,
 	keyset *pagetoken.KeysetPayload,
 	valueFn KeysetWhereOrderLimitValueFn,
+	opts ...KeysetWhereOrderLimitOpt,
 ) (*gorm.DB, error) {
-	if keyset == nil {
-		return db, nil
+	cfg := &keysetWhereOrderLimitConfig{}
+	for _, opt := range opts {
+		opt(cfg)
 	}
 
-	vs := keyset.Values()
-	if len(vs) == 0 {
-		return db, nil
+	bOpts := []sqlraw.BuilderOpt{}
+	if cfg.inclusiveTiebreak != "" {
+		bOpts = append(bOpts, sqlraw.WithInclusiveBoundary(cfg.inclusiveTiebreak))
+	}
+	if cfg.columnFn == nil {
+		cfg.columnFn = dialectColumnFn(db)
+	}
+	if cfg.columnFn != nil {
+		bOpts = append(bOpts, sqlraw.WithColumnFn(cfg.columnFn))
+	}
+	b := sqlraw.NewBuilder(bOpts...)
+
+	bound, boundArgs, err := b.UpperBoundWhere(cfg.upperBound, sqlraw.KeysetValueFn(valueFn))
+	if err != nil {
+		return nil, err
+	}
+	if bound != "" {
+		db = db.Where(bound, boundArgs...)
 	}
 
-	args := []any{}
-	orExprs := []string{}
-	orderExprs := []string{}
+	if keyset == nil || len(keyset.Values()) == 0 {
+		if len(cfg.spec) == 0 {
+			return db, nil
+		}
 
-	for i := 0; i < len(vs); i++ {
-		andExprs := []string{}
-		for j := 0; j < i; j++ {
-			v := vs[j]
-			andExprs = append(andExprs, fmt.Sprintf("%s = ?", v.Path))
-			aV, err := valueFn(v.Path, keyset)
-			if err != nil {
-				return nil, err
-			}
-			args = append(args, aV)
+		spec, err := cfg.ensureTiebreak(cfg.spec)
+		if err != nil {
+			return nil, err
 		}
 
-		v := vs[i]
-		if v.Order == order.Desc {
-			andExprs = append(andExprs, fmt.Sprintf("%s < ?", v.Path))
-			aV, err := valueFn(v.Path, keyset)
-			if err != nil {
-				return nil, err
-			}
-			args = append(args, aV)
-		} else {
-			andExprs = append(andExprs, fmt.Sprintf("%s > ?", v.Path))
-			aV, err := valueFn(v.Path, keyset)
-			if err != nil {
-				return nil, err
-			}
-			args = append(args, aV)
+		if err := cfg.validatePaths(spec); err != nil {
+			return nil, err
 		}
 
-		orExprs = append(orExprs, "("+strings.Join(andExprs, " AND ")+")")
-		orderExprs = append(orderExprs, fmt.Sprintf("%s %s", v.Path, orderToSQL(v.Order)))
+		return db.Order(b.OrderBy(spec)), nil
+	}
+
+	spec, err := cfg.ensureTiebreak(keyset.SortSpec())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cfg.validatePaths(spec); err != nil {
+		return nil, err
+	}
+
+	where, args, err := b.KeysetWhere(keyset, sqlraw.KeysetValueFn(valueFn))
+	if err != nil {
+		return nil, err
 	}
 
 	return db.Where(
-		"("+strings.Join(orExprs, " OR ")+")",
+		where,
 		args...,
 	).Order(
-		strings.Join(orderExprs, ", "),
+		b.OrderBy(spec),
 	), nil
 }
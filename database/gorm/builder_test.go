@@ -0,0 +1,215 @@
+package gorm_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	ptgorm "github.com/pixlcrashr/go-pagetoken/database/gorm"
+	"github.com/pixlcrashr/go-pagetoken/order"
+)
+
+type item struct {
+	ID   int `gorm:"primaryKey"`
+	Sort int
+}
+
+func itemValue(column string, payload *pagetoken.KeysetPayload) (any, error) {
+	v, _, err := payload.Int(column)
+	return v, err
+}
+
+func itemKeyset(it item) *pagetoken.KeysetPayload {
+	return pagetoken.NewKeysetPayloadBuilder().
+		AddInt("sort", it.Sort, order.Asc).
+		AddInt("id", it.ID, order.Asc).
+		Build()
+}
+
+func openDB(dryRun bool) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		DryRun: dryRun,
+		Logger: logger.Discard,
+	})
+	Expect(err).NotTo(HaveOccurred())
+
+	// every connection to ":memory:" opens a separate database
+	sqlDB, err := db.DB()
+	Expect(err).NotTo(HaveOccurred())
+	sqlDB.SetMaxOpenConns(1)
+
+	return db
+}
+
+// seedItems inserts n items whose sort values repeat in groups of three so
+// that ties span page boundaries.
+func seedItems(db *gorm.DB, n int) {
+	Expect(db.AutoMigrate(&item{})).To(Succeed())
+
+	items := make([]item, n)
+	for i := range items {
+		items[i] = item{ID: i + 1, Sort: i / 3}
+	}
+	Expect(db.Create(&items).Error).To(Succeed())
+}
+
+// walk pages through all items, calling between after every page with the
+// boundary item, and returns the visited ids in order.
+func walk(db *gorm.DB, pageSize int, between func(boundary item), opts ...ptgorm.KeysetWhereOrderLimitOpt) []int {
+	var keyset *pagetoken.KeysetPayload
+	seen := []int{}
+
+	for {
+		q := db.Model(&item{})
+		if keyset == nil {
+			q = q.Order("sort ASC, id ASC")
+		}
+
+		q, err := ptgorm.KeysetWhereOrderLimit(q, keyset, itemValue, opts...)
+		Expect(err).NotTo(HaveOccurred())
+
+		page := []item{}
+		Expect(q.Limit(pageSize).Find(&page).Error).To(Succeed())
+
+		for _, it := range page {
+			seen = append(seen, it.ID)
+		}
+
+		if len(page) < pageSize {
+			return seen
+		}
+
+		boundary := page[len(page)-1]
+		keyset = itemKeyset(boundary)
+		if between != nil {
+			between(boundary)
+		}
+	}
+}
+
+func ids(from, to int) []int {
+	vs := []int{}
+	for i := from; i <= to; i++ {
+		vs = append(vs, i)
+	}
+	return vs
+}
+
+var _ = Describe("KeysetWhereOrderLimit", func() {
+	It("returns the query unchanged for an empty keyset", func() {
+		db := openDB(true)
+
+		q, err := ptgorm.KeysetWhereOrderLimit(db.Model(&item{}), nil, itemValue)
+		Expect(err).NotTo(HaveOccurred())
+
+		stmt := q.Find(&[]item{}).Statement
+		Expect(stmt.SQL.String()).To(Equal("SELECT * FROM `items`"))
+	})
+
+	It("renders the strict keyset predicate", func() {
+		db := openDB(true)
+
+		q, err := ptgorm.KeysetWhereOrderLimit(db.Model(&item{}), itemKeyset(item{ID: 7, Sort: 2}), itemValue)
+		Expect(err).NotTo(HaveOccurred())
+
+		stmt := q.Find(&[]item{}).Statement
+		Expect(stmt.SQL.String()).To(Equal(
+			"SELECT * FROM `items` WHERE ((sort > ?) OR (sort = ? AND id > ?)) ORDER BY sort ASC, id ASC",
+		))
+		Expect(stmt.Vars).To(Equal([]any{2, 2, 7}))
+	})
+
+	It("renders the inclusive keyset predicate", func() {
+		db := openDB(true)
+
+		q, err := ptgorm.KeysetWhereOrderLimit(
+			db.Model(&item{}),
+			itemKeyset(item{ID: 7, Sort: 2}),
+			itemValue,
+			ptgorm.WithInclusiveBoundary("id"),
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		stmt := q.Find(&[]item{}).Statement
+		Expect(stmt.SQL.String()).To(Equal(
+			"SELECT * FROM `items` WHERE (sort >= ? AND NOT (sort = ? AND id <= ?)) ORDER BY sort ASC, id ASC",
+		))
+		Expect(stmt.Vars).To(Equal([]any{2, 2, 7}))
+	})
+
+	It("rejects an inclusive boundary whose tiebreak is not the last column", func() {
+		db := openDB(true)
+
+		_, err := ptgorm.KeysetWhereOrderLimit(
+			db.Model(&item{}),
+			itemKeyset(item{ID: 7, Sort: 2}),
+			itemValue,
+			ptgorm.WithInclusiveBoundary("sort"),
+		)
+		Expect(err).To(HaveOccurred())
+	})
+
+	for _, mode := range []struct {
+		name string
+		opts []ptgorm.KeysetWhereOrderLimitOpt
+	}{
+		{name: "strict"},
+		{name: "inclusive", opts: []ptgorm.KeysetWhereOrderLimitOpt{ptgorm.WithInclusiveBoundary("id")}},
+	} {
+		Describe("with "+mode.name+" boundaries", func() {
+			var db *gorm.DB
+
+			BeforeEach(func() {
+				db = openDB(false)
+				seedItems(db, 20)
+			})
+
+			It("visits every row exactly once", func() {
+				Expect(walk(db, 4, nil, mode.opts...)).To(Equal(ids(1, 20)))
+			})
+
+			It("loses no rows when boundary rows are deleted between pages", func() {
+				seen := walk(db, 4, func(boundary item) {
+					Expect(db.Delete(&item{}, boundary.ID).Error).To(Succeed())
+				}, mode.opts...)
+
+				Expect(seen).To(Equal(ids(1, 20)))
+			})
+
+			It("loses no rows when boundary rows move before the boundary", func() {
+				seen := walk(db, 4, func(boundary item) {
+					Expect(db.Model(&item{}).Where("id = ?", boundary.ID).Update("sort", -1).Error).To(Succeed())
+				}, mode.opts...)
+
+				Expect(seen).To(Equal(ids(1, 20)))
+			})
+		})
+	}
+})
+
+// sqlServerDialector is a SQLite dialector reporting itself as SQL Server,
+// so that dry runs show the SQL emitted for SQL Server.
+type sqlServerDialector struct {
+	gorm.Dialector
+}
+
+func (sqlServerDialector) Name() string { return "sqlserver" }
+
+var _ = Describe("KeysetWhereOrderLimit on SQL Server", func() {
+	It("quotes the keyset columns", func() {
+		db, err := gorm.Open(sqlServerDialector{sqlite.Open(":memory:")}, &gorm.Config{
+			DryRun: true,
+			Logger: logger.Discard,
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		q, err := ptgorm.KeysetWhereOrderLimit(db.Model(&item{}), itemKeyset(item{ID: 4, Sort: 1}), itemValue)
+		Expect(err).NotTo(HaveOccurred())
+
+		stmt := q.Find(&[]item{}).Statement
+		Expect(stmt.SQL.String()).To(Equal("SELECT * FROM `items` WHERE (([sort] > ?) OR ([sort] = ? AND [id] > ?)) ORDER BY [sort] ASC, [id] ASC"))
+	})
+})
@@ -0,0 +1,29 @@
+package gorm
+
+import (
+	"context"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"gorm.io/gorm"
+)
+
+// CountOnce returns the total number of rows matched by db. The count is only
+// queried if token carries no total yet, i.e. on the first page; the result is
+// then stored on the token so that every token derived from it via Next
+// returns the cached value without touching the database again.
+//
+// db must not contain the keyset condition or a limit, otherwise the count
+// only covers the remaining rows.
+func CountOnce(ctx context.Context, db *gorm.DB, token *pagetoken.KeysetToken) (int64, error) {
+	if n, ok := token.TotalCount(); ok {
+		return n, nil
+	}
+
+	var n int64
+	if err := db.WithContext(ctx).Count(&n).Error; err != nil {
+		return 0, err
+	}
+
+	token.SetTotalCount(n)
+	return n, nil
+}
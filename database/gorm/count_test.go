@@ -0,0 +1,96 @@
+package gorm_test
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/checksum"
+	ptgorm "github.com/pixlcrashr/go-pagetoken/database/gorm"
+	"github.com/pixlcrashr/go-pagetoken/encryption"
+)
+
+// sqlRecorder is a gorm logger that records every executed statement.
+type sqlRecorder struct {
+	statements []string
+}
+
+func (r *sqlRecorder) LogMode(logger.LogLevel) logger.Interface      { return r }
+func (r *sqlRecorder) Info(context.Context, string, ...interface{})  {}
+func (r *sqlRecorder) Warn(context.Context, string, ...interface{})  {}
+func (r *sqlRecorder) Error(context.Context, string, ...interface{}) {}
+
+func (r *sqlRecorder) Trace(_ context.Context, _ time.Time, fc func() (string, int64), _ error) {
+	sql, _ := fc()
+	r.statements = append(r.statements, sql)
+}
+
+func (r *sqlRecorder) count(substr string) int {
+	n := 0
+	for _, s := range r.statements {
+		if strings.Contains(s, substr) {
+			n++
+		}
+	}
+	return n
+}
+
+type listRequest struct {
+	token string
+}
+
+func (r listRequest) GetChecksumFields() []checksum.BuilderOpt {
+	return []checksum.BuilderOpt{checksum.Field("resource", "items")}
+}
+
+func (r listRequest) GetPageToken() string {
+	return r.token
+}
+
+var _ = Describe("CountOnce", func() {
+	It("counts only on the first page", func() {
+		db := openDB(false)
+		seedItems(db, 12)
+
+		rec := &sqlRecorder{}
+		db = db.Session(&gorm.Session{Logger: rec})
+
+		key, err := encryption.Rand32ByteKey()
+		Expect(err).NotTo(HaveOccurred())
+		e, err := encryption.NewAEADEncryptor(key)
+		Expect(err).NotTo(HaveOccurred())
+		rr := pagetoken.NewRequestReader(pagetoken.WithEncryptor(e))
+
+		req := listRequest{}
+		for range 3 {
+			token, err := rr.Read(req)
+			Expect(err).NotTo(HaveOccurred())
+
+			total, err := ptgorm.CountOnce(context.Background(), db.Model(&item{}), token)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(total).To(Equal(int64(12)))
+
+			q := db.Model(&item{})
+			if len(token.Payload().Values()) == 0 {
+				q = q.Order("sort ASC, id ASC")
+			}
+			q, err = ptgorm.KeysetWhereOrderLimit(q, token.Payload(), itemValue)
+			Expect(err).NotTo(HaveOccurred())
+
+			page := []item{}
+			Expect(q.Limit(4).Find(&page).Error).To(Succeed())
+			Expect(page).To(HaveLen(4))
+
+			req.token, err = token.Next(pagetoken.WithKeysetPayload(itemKeyset(page[len(page)-1]))).String()
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		Expect(rec.count("SELECT count(*)")).To(Equal(1))
+	})
+})
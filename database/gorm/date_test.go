@@ -0,0 +1,103 @@
+package gorm_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gorm.io/gorm"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	ptgorm "github.com/pixlcrashr/go-pagetoken/database/gorm"
+	"github.com/pixlcrashr/go-pagetoken/order"
+)
+
+type report struct {
+	ID         int    `gorm:"primaryKey"`
+	ReportDate string `gorm:"type:date"`
+}
+
+func reportValue(column string, p *pagetoken.KeysetPayload) (any, error) {
+	if column == "report_date" {
+		return ptgorm.DateValue(column, p)
+	}
+
+	v, _, err := p.Int(column)
+	return v, err
+}
+
+var _ = Describe("DateValue", func() {
+	var db *gorm.DB
+
+	BeforeEach(func() {
+		db = openDB(false)
+		Expect(db.AutoMigrate(&report{})).To(Succeed())
+
+		reports := []report{}
+		for i := range 9 {
+			reports = append(reports, report{ID: i + 1, ReportDate: time.Date(2024, time.March, 1+i/3, 0, 0, 0, 0, time.UTC).Format(time.DateOnly)})
+		}
+		Expect(db.Create(&reports).Error).To(Succeed())
+	})
+
+	after := func(keyset *pagetoken.KeysetPayload) []int {
+		q, err := ptgorm.KeysetWhereOrderLimit(db.Model(&report{}), keyset, reportValue)
+		Expect(err).NotTo(HaveOccurred())
+
+		page := []report{}
+		Expect(q.Find(&page).Error).To(Succeed())
+
+		ids := []int{}
+		for _, r := range page {
+			ids = append(ids, r.ID)
+		}
+		return ids
+	}
+
+	It("binds the date as a string", func() {
+		keyset := pagetoken.NewKeysetPayloadBuilder().AddDate("report_date", 2024, time.March, 2, order.Asc).Build()
+
+		v, err := ptgorm.DateValue("report_date", keyset)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(v).To(Equal("2024-03-02"))
+	})
+
+	It("walks reports by date", func() {
+		keyset := pagetoken.NewKeysetPayloadBuilder().
+			AddDate("report_date", 2024, time.March, 2, order.Asc).
+			AddInt("id", 5, order.Asc).
+			Build()
+
+		Expect(after(keyset)).To(Equal([]int{6, 7, 8, 9}))
+	})
+
+	// regression: a DATE scanned as the midnight of its day in UTC and shown
+	// in a location west of UTC lies on the previous day, so a keyset of that
+	// timestamp repeats the rows of the boundary's day
+	It("compares dates, not timestamps shifted to another location", func() {
+		pst := time.FixedZone("PST", -8*60*60)
+		midnight := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC).In(pst)
+
+		byTime := pagetoken.NewKeysetPayloadBuilder().
+			AddTime("report_date", midnight, order.Asc).
+			AddInt("id", 3, order.Asc).
+			Build()
+		q, err := ptgorm.KeysetWhereOrderLimit(db.Model(&report{}), byTime, func(column string, p *pagetoken.KeysetPayload) (any, error) {
+			if column == "report_date" {
+				v, _, err := p.Time(column)
+				return v, err
+			}
+			return reportValue(column, p)
+		})
+		Expect(err).NotTo(HaveOccurred())
+		var n int64
+		Expect(q.Count(&n).Error).To(Succeed())
+		Expect(n).To(Equal(int64(9)), "the boundary's day is repeated")
+
+		byDate := pagetoken.NewKeysetPayloadBuilder().
+			AddDate("report_date", 2024, time.March, 1, order.Asc).
+			AddInt("id", 3, order.Asc).
+			Build()
+		Expect(after(byDate)).To(Equal([]int{4, 5, 6, 7, 8, 9}))
+	})
+})
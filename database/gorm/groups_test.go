@@ -0,0 +1,87 @@
+package gorm_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	ptgorm "github.com/pixlcrashr/go-pagetoken/database/gorm"
+	"github.com/pixlcrashr/go-pagetoken/pagetokentest"
+)
+
+var _ = Describe("Named keysets", func() {
+	It("page two lists of different sizes to exhaustion with one token", func() {
+		db := openDB(false)
+
+		sizes := map[string]int{"recent": 10, "recommended": 3}
+		for table, n := range sizes {
+			Expect(db.Table(table).AutoMigrate(&item{})).To(Succeed())
+
+			items := make([]item, n)
+			for i := range items {
+				items[i] = item{ID: i + 1, Sort: i / 3}
+			}
+			Expect(db.Table(table).Create(&items).Error).To(Succeed())
+		}
+
+		const pageSize = 4
+		parser := pagetoken.NewKeysetTokenParser(pagetoken.WithKeysetTokenEncryptor(pagetokentest.StaticCrypter{}))
+
+		var (
+			token = pagetoken.NewKeysetToken(pagetokentest.StaticCrypter{})
+			seen  = map[string][][]int{}
+		)
+
+		for pages := 0; ; pages++ {
+			Expect(pages).To(BeNumerically("<", 10), "pagination does not terminate")
+
+			next := map[string]*pagetoken.KeysetPayload{}
+			for table := range sizes {
+				keyset, ok := token.PayloadFor(table)
+				if !ok {
+					continue
+				}
+
+				q := db.Table(table)
+				if len(keyset.Values()) == 0 {
+					q = q.Order("sort ASC, id ASC")
+				}
+
+				q, err := ptgorm.KeysetWhereOrderLimit(q, keyset, itemValue)
+				Expect(err).NotTo(HaveOccurred())
+
+				page := []item{}
+				Expect(q.Limit(pageSize + 1).Find(&page).Error).To(Succeed())
+
+				more := len(page) > pageSize
+				if more {
+					page = page[:pageSize]
+				}
+
+				ids := []int{}
+				for _, it := range page {
+					ids = append(ids, it.ID)
+				}
+				seen[table] = append(seen[table], ids)
+
+				if more {
+					next[table] = itemKeyset(page[len(page)-1])
+				}
+			}
+
+			if len(next) == 0 {
+				break
+			}
+
+			s, err := token.NextWithPayloads(next).String()
+			Expect(err).NotTo(HaveOccurred())
+			token, err = parser.Parse(s)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		Expect(seen).To(Equal(map[string][][]int{
+			"recent":      {{1, 2, 3, 4}, {5, 6, 7, 8}, {9, 10}},
+			"recommended": {{1, 2, 3}},
+		}))
+	})
+})
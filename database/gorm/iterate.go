@@ -0,0 +1,53 @@
+package gorm
+
+import (
+	"context"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"gorm.io/gorm"
+)
+
+// Iterate walks all rows of db in batches of batchSize, using the same
+// keyset logic as ListKeyset but without issuing page tokens. This is meant
+// for exports and background jobs that need to visit an entire table without
+// holding a long-running cursor open. If batchSize is not positive,
+// pagetoken.DefaultPageSize is used.
+//
+// yield is called once per non-empty batch. Iteration stops at the first
+// error returned by yield, which Iterate then returns. ctx is checked between
+// batches and also bound to every query.
+func Iterate[T any](
+	ctx context.Context,
+	db *gorm.DB,
+	batchSize int,
+	cfg ListConfig[T],
+	yield func(items []T) error,
+) error {
+	if batchSize <= 0 {
+		batchSize = pagetoken.DefaultPageSize
+	}
+
+	var keyset *pagetoken.KeysetPayload
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		items, next, err := ListKeyset(db.WithContext(ctx), keyset, batchSize, cfg)
+		if err != nil {
+			return err
+		}
+
+		if len(items) > 0 {
+			if err := yield(items); err != nil {
+				return err
+			}
+		}
+
+		if next == nil {
+			return nil
+		}
+		keyset = next
+	}
+}
@@ -0,0 +1,86 @@
+package gorm_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gorm.io/gorm"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	ptgorm "github.com/pixlcrashr/go-pagetoken/database/gorm"
+)
+
+var _ = Describe("Iterate", func() {
+	var db *gorm.DB
+
+	BeforeEach(func() {
+		db = openDB(false)
+		seedItems(db, 20)
+	})
+
+	for _, batchSize := range []int{1, 3, 7, 20, 25} {
+		It("visits every row exactly once with a batch size that does not divide the row count", func() {
+			seen := []int{}
+			batches := 0
+
+			Expect(ptgorm.Iterate(context.Background(), db, batchSize, itemListConfig(), func(items []item) error {
+				Expect(len(items)).To(BeNumerically("<=", batchSize))
+				batches++
+				for _, it := range items {
+					seen = append(seen, it.ID)
+				}
+				return nil
+			})).To(Succeed())
+
+			Expect(seen).To(Equal(ids(1, 20)))
+			Expect(batches).To(Equal((20 + batchSize - 1) / batchSize))
+		})
+	}
+
+	It("uses batches of DefaultPageSize rows for a non-positive batch size", func() {
+		Expect(db.Create(&item{ID: 21, Sort: 7}).Error).To(Succeed())
+
+		for _, batchSize := range []int{0, -1} {
+			sizes := []int{}
+			Expect(ptgorm.Iterate(context.Background(), db, batchSize, itemListConfig(), func(items []item) error {
+				sizes = append(sizes, len(items))
+				return nil
+			})).To(Succeed())
+
+			Expect(sizes).To(Equal([]int{pagetoken.DefaultPageSize, 1}))
+		}
+	})
+
+	It("stops at the first error returned by yield", func() {
+		errStop := errors.New("stop")
+		batches := 0
+
+		err := ptgorm.Iterate(context.Background(), db, 3, itemListConfig(), func(items []item) error {
+			batches++
+			if batches == 2 {
+				return errStop
+			}
+			return nil
+		})
+
+		Expect(err).To(MatchError(errStop))
+		Expect(batches).To(Equal(2))
+	})
+
+	It("stops when the context is canceled between batches", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		batches := 0
+		err := ptgorm.Iterate(ctx, db, 3, itemListConfig(), func(items []item) error {
+			batches++
+			cancel()
+			return nil
+		})
+
+		Expect(err).To(MatchError(context.Canceled))
+		Expect(batches).To(Equal(1))
+	})
+})
@@ -0,0 +1,141 @@
+package gorm_test
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	ptgorm "github.com/pixlcrashr/go-pagetoken/database/gorm"
+	"github.com/pixlcrashr/go-pagetoken/database/sqlraw"
+	"github.com/pixlcrashr/go-pagetoken/order"
+)
+
+type document struct {
+	ID   int `gorm:"primaryKey"`
+	Data string
+}
+
+type documentRow struct {
+	ID     int
+	Author string
+}
+
+func documentValue(column string, payload *pagetoken.KeysetPayload) (any, error) {
+	if column == "id" {
+		v, _, err := payload.Int(column)
+		return v, err
+	}
+
+	v, _, err := payload.String(column)
+	return v, err
+}
+
+func documentKeyset(row documentRow) *pagetoken.KeysetPayload {
+	return pagetoken.NewKeysetPayloadBuilder().
+		AddString("author.name", row.Author, order.Asc).
+		AddInt("id", row.ID, order.Asc).
+		Build()
+}
+
+var _ = Describe("WithJSONPaths", func() {
+	spec := pagetoken.SortSpec{
+		{Path: "author.name", Order: order.Asc},
+		{Path: "id", Order: order.Asc},
+	}
+
+	It("renders nested paths as JSON accessors", func() {
+		db := openDB(true)
+
+		q, err := ptgorm.KeysetWhereOrderLimit(
+			db.Model(&document{}),
+			documentKeyset(documentRow{ID: 7, Author: "b"}),
+			documentValue,
+			ptgorm.WithJSONPaths("data", sqlraw.SQLiteJSONPath),
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		stmt := q.Find(&[]document{}).Statement
+		Expect(stmt.SQL.String()).To(Equal(
+			"SELECT * FROM `documents` WHERE ((json_extract(data, '$.author.name') > ?) OR " +
+				"(json_extract(data, '$.author.name') = ? AND id > ?)) " +
+				"ORDER BY json_extract(data, '$.author.name') ASC, id ASC",
+		))
+		Expect(stmt.Vars).To(Equal([]any{"b", "b", 7}))
+	})
+
+	It("rejects keysets with paths that are not identifiers", func() {
+		db := openDB(true)
+
+		keyset := pagetoken.NewKeysetPayloadBuilder().
+			AddString("author.name') OR 1=1 --", "b", order.Asc).
+			Build()
+
+		_, err := ptgorm.KeysetWhereOrderLimit(
+			db.Model(&document{}),
+			keyset,
+			documentValue,
+			ptgorm.WithJSONPaths("data", sqlraw.SQLiteJSONPath),
+		)
+		Expect(err).To(MatchError(pagetoken.ErrInvalidPath))
+	})
+
+	It("rejects sort specs with paths that are not identifiers", func() {
+		db := openDB(true)
+
+		_, err := ptgorm.KeysetWhereOrderLimit(
+			db.Model(&document{}),
+			nil,
+			documentValue,
+			ptgorm.WithJSONPaths("data", sqlraw.SQLiteJSONPath),
+			ptgorm.WithSortSpec(pagetoken.SortSpec{{Path: "author..name", Order: order.Asc}}),
+		)
+		Expect(err).To(MatchError(pagetoken.ErrInvalidPath))
+	})
+
+	It("visits every document exactly once across a nested sort key", func() {
+		db := openDB(false)
+		Expect(db.AutoMigrate(&document{})).To(Succeed())
+
+		// authors repeat so that ties span page boundaries
+		docs := make([]document, 20)
+		for i := range docs {
+			docs[i] = document{
+				ID:   i + 1,
+				Data: fmt.Sprintf(`{"author":{"name":"author-%02d"}}`, (19-i)/3),
+			}
+		}
+		Expect(db.Create(&docs).Error).To(Succeed())
+
+		var keyset *pagetoken.KeysetPayload
+		seen := []documentRow{}
+
+		for {
+			q, err := ptgorm.KeysetWhereOrderLimit(
+				db.Model(&document{}).Select("id, json_extract(data, '$.author.name') AS author"),
+				keyset,
+				documentValue,
+				ptgorm.WithJSONPaths("data", sqlraw.SQLiteJSONPath),
+				ptgorm.WithSortSpec(spec),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			page := []documentRow{}
+			Expect(q.Limit(4).Scan(&page).Error).To(Succeed())
+			seen = append(seen, page...)
+
+			if len(page) < 4 {
+				break
+			}
+
+			keyset = documentKeyset(page[len(page)-1])
+		}
+
+		Expect(seen).To(HaveLen(20))
+		for i := 1; i < len(seen); i++ {
+			prev, cur := seen[i-1], seen[i]
+			Expect(prev.Author < cur.Author || prev.Author == cur.Author && prev.ID < cur.ID).To(BeTrue(), "%v before %v", prev, cur)
+		}
+	})
+})
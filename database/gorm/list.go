@@ -0,0 +1,105 @@
+package gorm
+
+import (
+	"github.com/pixlcrashr/go-pagetoken"
+	"gorm.io/gorm"
+)
+
+// DefaultTiebreak is the column ListKeyset appends to the sort spec if
+// ListConfig.Tiebreak is empty.
+const DefaultTiebreak = "id"
+
+// ListKeysetFn builds the keyset of item for spec, i.e. one value per column
+// of spec in the same order. spec always includes the tiebreak column.
+type ListKeysetFn[T any] func(item T, spec pagetoken.SortSpec) (*pagetoken.KeysetPayload, error)
+
+// ListTiebreakValueFn returns the encoded tiebreak value of item, see
+// pagetoken.EnsurePayloadTiebreak.
+type ListTiebreakValueFn[T any] func(item T) string
+
+// ListConfig describes how ListKeyset pages through a model.
+type ListConfig[T any] struct {
+	// Spec is the sort order of the listing.
+	Spec pagetoken.SortSpec
+	// Tiebreak is a unique column that is appended to Spec if missing.
+	// Defaults to DefaultTiebreak.
+	Tiebreak string
+	// ValueFn converts keyset values to the values bound to the query.
+	ValueFn KeysetWhereOrderLimitValueFn
+	// KeysetFn builds the keyset of the last item of a page.
+	KeysetFn ListKeysetFn[T]
+	// TiebreakValueFn, if set, makes ListKeyset append the tiebreak to the
+	// keysets of KeysetFn lacking it, so KeysetFn may build them from Spec
+	// alone, e.g. with AddAuto.
+	TiebreakValueFn ListTiebreakValueFn[T]
+	// Opts are passed on to KeysetWhereOrderLimit.
+	Opts []KeysetWhereOrderLimitOpt
+}
+
+func (c ListConfig[T]) tiebreak() string {
+	if c.Tiebreak == "" {
+		return DefaultTiebreak
+	}
+	return c.Tiebreak
+}
+
+// ListKeyset loads up to limit items of db following keyset, or the first
+// page if keyset is nil, and returns them together with the keyset of the
+// next page. The next keyset is nil once the last page has been loaded. If
+// limit is not positive, pagetoken.DefaultPageSize is used.
+//
+// The tiebreak column of cfg is enforced via WithRequiredTiebreak, so the
+// listing is stable even if Spec alone is not unique. With a TiebreakValueFn,
+// next keysets carrying the tiebreak in another order than the sort fail
+// with pagetoken.ErrTiebreakConflict.
+func ListKeyset[T any](
+	db *gorm.DB,
+	keyset *pagetoken.KeysetPayload,
+	limit int,
+	cfg ListConfig[T],
+) ([]T, *pagetoken.KeysetPayload, error) {
+	if limit <= 0 {
+		limit = pagetoken.DefaultPageSize
+	}
+
+	opts := append([]KeysetWhereOrderLimitOpt{
+		WithSortSpec(cfg.Spec),
+		WithRequiredTiebreak(cfg.tiebreak()),
+	}, cfg.Opts...)
+
+	q, err := KeysetWhereOrderLimit(db, keyset, cfg.ValueFn, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	items := []T{}
+	if err := q.Limit(limit + 1).Find(&items).Error; err != nil {
+		return nil, nil, err
+	}
+
+	if len(items) <= limit {
+		return items, nil, nil
+	}
+	items = items[:limit]
+
+	last := items[limit-1]
+	spec := pagetoken.EnsureTiebreak(cfg.Spec, cfg.tiebreak())
+
+	next, err := cfg.KeysetFn(last, spec)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if cfg.TiebreakValueFn != nil {
+		for _, f := range spec {
+			if f.Path != cfg.tiebreak() {
+				continue
+			}
+			if next, err = pagetoken.EnsurePayloadTiebreakStrict(next, f.Path, f.Order, cfg.TiebreakValueFn(last)); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	return items, next, nil
+}
@@ -0,0 +1,212 @@
+package gorm_test
+
+import (
+	"fmt"
+	"strconv"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gorm.io/gorm"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	ptgorm "github.com/pixlcrashr/go-pagetoken/database/gorm"
+	"github.com/pixlcrashr/go-pagetoken/order"
+)
+
+func itemKeysetFor(it item, spec pagetoken.SortSpec) (*pagetoken.KeysetPayload, error) {
+	b := pagetoken.NewKeysetPayloadBuilder()
+	for _, f := range spec {
+		switch f.Path {
+		case "sort":
+			b.AddInt(f.Path, it.Sort, f.Order)
+		case "id":
+			b.AddInt(f.Path, it.ID, f.Order)
+		default:
+			return nil, fmt.Errorf("unknown column %q", f.Path)
+		}
+	}
+	return b.Build(), nil
+}
+
+var bySort = pagetoken.SortSpec{{Path: "sort", Order: order.Asc}}
+
+func itemListConfig() ptgorm.ListConfig[item] {
+	return ptgorm.ListConfig[item]{
+		Spec:     bySort,
+		ValueFn:  itemValue,
+		KeysetFn: itemKeysetFor,
+	}
+}
+
+func listAll(db *gorm.DB, limit int, cfg ptgorm.ListConfig[item]) []int {
+	var keyset *pagetoken.KeysetPayload
+	seen := []int{}
+
+	for {
+		page, next, err := ptgorm.ListKeyset(db, keyset, limit, cfg)
+		Expect(err).NotTo(HaveOccurred())
+
+		for _, it := range page {
+			seen = append(seen, it.ID)
+		}
+
+		if next == nil {
+			return seen
+		}
+		keyset = next
+	}
+}
+
+var _ = Describe("ListKeyset", func() {
+	var db *gorm.DB
+
+	BeforeEach(func() {
+		db = openDB(false)
+		// sort values repeat in groups of three, so ties span the boundaries
+		// of pages of four
+		seedItems(db, 20)
+	})
+
+	It("visits every row exactly once although the sort column has duplicates", func() {
+		Expect(listAll(db, 4, itemListConfig())).To(Equal(ids(1, 20)))
+	})
+
+	It("loses rows without a tiebreak", func() {
+		var keyset *pagetoken.KeysetPayload
+		seen := []int{}
+
+		for {
+			q, err := ptgorm.KeysetWhereOrderLimit(db.Model(&item{}), keyset, itemValue, ptgorm.WithSortSpec(bySort))
+			Expect(err).NotTo(HaveOccurred())
+
+			page := []item{}
+			Expect(q.Limit(4).Find(&page).Error).To(Succeed())
+			for _, it := range page {
+				seen = append(seen, it.ID)
+			}
+			if len(page) < 4 {
+				break
+			}
+
+			keyset, err = itemKeysetFor(page[len(page)-1], bySort)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		Expect(len(seen)).To(BeNumerically("<", 20))
+	})
+
+	It("returns no next keyset when the last page is full", func() {
+		page, next, err := ptgorm.ListKeyset(db, nil, 20, itemListConfig())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(page).To(HaveLen(20))
+		Expect(next).To(BeNil())
+	})
+
+	for _, limit := range []int{0, -1} {
+		It(fmt.Sprintf("loads DefaultPageSize items for a limit of %d", limit), func() {
+			Expect(db.Create(&item{ID: 21, Sort: 7}).Error).To(Succeed())
+
+			page, next, err := ptgorm.ListKeyset(db, nil, limit, itemListConfig())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(page).To(HaveLen(pagetoken.DefaultPageSize))
+			Expect(next).NotTo(BeNil())
+		})
+	}
+
+	It("passes the tiebreak on to the keyset", func() {
+		_, next, err := ptgorm.ListKeyset(db, nil, 4, itemListConfig())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(next.SortSpec()).To(Equal(pagetoken.SortSpec{
+			{Path: "sort", Order: order.Asc},
+			{Path: "id", Order: order.Asc},
+		}))
+	})
+
+	It("rejects a keyset without tiebreak in strict mode", func() {
+		cfg := itemListConfig()
+		cfg.Opts = []ptgorm.KeysetWhereOrderLimitOpt{ptgorm.WithStrictTiebreak()}
+
+		keyset, err := itemKeysetFor(item{ID: 4, Sort: 1}, bySort)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, _, err = ptgorm.ListKeyset(db, keyset, 4, cfg)
+		Expect(err).To(MatchError(ptgorm.ErrMissingTiebreak))
+	})
+})
+
+var _ = Describe("WithRequiredTiebreak", func() {
+	It("appends the tiebreak to the first page's order", func() {
+		db := openDB(true)
+
+		q, err := ptgorm.KeysetWhereOrderLimit(db.Model(&item{}), nil, itemValue,
+			ptgorm.WithSortSpec(pagetoken.SortSpec{{Path: "sort", Order: order.Desc}}),
+			ptgorm.WithRequiredTiebreak("id"),
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		stmt := q.Find(&[]item{}).Statement
+		Expect(stmt.SQL.String()).To(Equal("SELECT * FROM `items` ORDER BY sort DESC, id DESC"))
+	})
+
+	It("appends the tiebreak to the order of a keyset lacking it", func() {
+		db := openDB(true)
+
+		keyset := pagetoken.NewKeysetPayloadBuilder().AddInt("sort", 2, order.Asc).Build()
+		q, err := ptgorm.KeysetWhereOrderLimit(db.Model(&item{}), keyset, itemValue, ptgorm.WithRequiredTiebreak("id"))
+		Expect(err).NotTo(HaveOccurred())
+
+		stmt := q.Find(&[]item{}).Statement
+		Expect(stmt.SQL.String()).To(Equal("SELECT * FROM `items` WHERE ((sort > ?)) ORDER BY sort ASC, id ASC"))
+	})
+
+	It("rejects a sort spec without the tiebreak in strict mode", func() {
+		db := openDB(true)
+
+		_, err := ptgorm.KeysetWhereOrderLimit(db.Model(&item{}), nil, itemValue,
+			ptgorm.WithSortSpec(bySort),
+			ptgorm.WithRequiredTiebreak("id"),
+			ptgorm.WithStrictTiebreak(),
+		)
+		Expect(err).To(MatchError(ptgorm.ErrMissingTiebreak))
+	})
+})
+
+var _ = Describe("ListKeyset with a TiebreakValueFn", func() {
+	var db *gorm.DB
+
+	BeforeEach(func() {
+		db = openDB(false)
+		seedItems(db, 20)
+	})
+
+	tiebreakConfig := func(keysetFn ptgorm.ListKeysetFn[item]) ptgorm.ListConfig[item] {
+		cfg := itemListConfig()
+		cfg.KeysetFn = keysetFn
+		cfg.TiebreakValueFn = func(it item) string { return strconv.Itoa(it.ID) }
+		return cfg
+	}
+
+	It("appends the tiebreak to keysets lacking it", func() {
+		cfg := tiebreakConfig(func(it item, _ pagetoken.SortSpec) (*pagetoken.KeysetPayload, error) {
+			return itemKeysetFor(it, bySort)
+		})
+
+		_, next, err := ptgorm.ListKeyset(db, nil, 4, cfg)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(next.SortSpec()).To(Equal(pagetoken.EnsureTiebreak(bySort, "id")))
+
+		Expect(listAll(db, 4, cfg)).To(Equal(ids(1, 20)))
+	})
+
+	It("rejects keysets with a conflicting tiebreak order", func() {
+		cfg := tiebreakConfig(func(it item, _ pagetoken.SortSpec) (*pagetoken.KeysetPayload, error) {
+			return pagetoken.NewKeysetPayloadBuilder().
+				AddInt("sort", it.Sort, order.Asc).
+				AddInt("id", it.ID, order.Desc).
+				Build(), nil
+		})
+
+		_, _, err := ptgorm.ListKeyset(db, nil, 4, cfg)
+		Expect(err).To(MatchError(pagetoken.ErrTiebreakConflict))
+	})
+})
@@ -0,0 +1,43 @@
+package gorm
+
+import (
+	"github.com/pixlcrashr/go-pagetoken"
+	"gorm.io/gorm"
+)
+
+// NetIPValue returns a value hook for columns holding IP addresses stored in
+// the payload via AddNetIP. The address is converted to the column's storage
+// form for db's dialect:
+//
+//   - postgres: the address string, which binds to inet columns; IPv4-mapped
+//     IPv6 addresses keep their IPv6 form
+//   - all other dialects: the 16-byte form, for BINARY(16) or BLOB columns,
+//     which holds IPv4 addresses in their IPv4-mapped form
+//
+// Example:
+//
+//	ipValue := ptgorm.NetIPValue(db)
+//	q, err := ptgorm.KeysetWhereOrderLimit(db, keyset, func(column string, p *pagetoken.KeysetPayload) (any, error) {
+//		if column == "client_ip" {
+//			return ipValue(column, p)
+//		}
+//		v, _, err := p.Int64(column)
+//		return v, err
+//	})
+func NetIPValue(db *gorm.DB) KeysetWhereOrderLimitValueFn {
+	native := db.Dialector.Name() == "postgres"
+
+	return func(column string, payload *pagetoken.KeysetPayload) (any, error) {
+		v, _, err := payload.NetIP(column)
+		if err != nil {
+			return nil, err
+		}
+
+		if native {
+			return v.String(), nil
+		}
+
+		b := v.As16()
+		return b[:], nil
+	}
+}
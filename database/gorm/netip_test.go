@@ -0,0 +1,109 @@
+package gorm_test
+
+import (
+	"math/rand"
+	"net/netip"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	ptgorm "github.com/pixlcrashr/go-pagetoken/database/gorm"
+	"github.com/pixlcrashr/go-pagetoken/order"
+)
+
+type auditEntry struct {
+	ID       int    `gorm:"primaryKey"`
+	ClientIP []byte `gorm:"type:blob"`
+}
+
+var _ = Describe("NetIPValue", func() {
+	// in the order of their 16-byte form, which is how blob columns sort;
+	// 10.0.0.2 and ::ffff:10.0.0.2 share that form and are told apart by id
+	addrs := []string{
+		"::1",
+		"10.0.0.2",
+		"::ffff:10.0.0.2",
+		"10.0.0.10",
+		"192.168.0.1",
+		"2001:db8::1",
+		"2001:db8::10",
+	}
+
+	It("walks addresses stored as sqlite blobs in the database's order", func() {
+		db := openDialect("", false)
+		Expect(db.AutoMigrate(&auditEntry{})).To(Succeed())
+
+		// ids ascend along addrs, the insertion order is shuffled
+		for _, i := range rand.New(rand.NewSource(3)).Perm(len(addrs)) {
+			ip := netip.MustParseAddr(addrs[i]).As16()
+			Expect(db.Create(&auditEntry{ID: i + 1, ClientIP: ip[:]}).Error).To(Succeed())
+		}
+
+		ipValue := ptgorm.NetIPValue(db)
+		valueFn := func(column string, p *pagetoken.KeysetPayload) (any, error) {
+			if column == "client_ip" {
+				return ipValue(column, p)
+			}
+			v, _, err := p.Int(column)
+			return v, err
+		}
+
+		var keyset *pagetoken.KeysetPayload
+		seen := []int{}
+		for {
+			q, err := ptgorm.KeysetWhereOrderLimit(db.Model(&auditEntry{}), keyset, valueFn, ptgorm.WithSortSpec(pagetoken.SortSpec{
+				{Path: "client_ip", Order: order.Asc},
+				{Path: "id", Order: order.Asc},
+			}))
+			Expect(err).NotTo(HaveOccurred())
+
+			page := []auditEntry{}
+			Expect(q.Limit(2).Find(&page).Error).To(Succeed())
+			Expect(len(seen)).To(BeNumerically("<=", len(addrs)), "pagination does not terminate")
+
+			for _, e := range page {
+				seen = append(seen, e.ID)
+			}
+
+			if len(page) < 2 {
+				break
+			}
+
+			last := page[len(page)-1]
+			keyset = pagetoken.NewKeysetPayloadBuilder().
+				AddNetIP("client_ip", netip.AddrFrom16([16]byte(last.ClientIP)), order.Asc).
+				AddInt("id", last.ID, order.Asc).
+				Build()
+		}
+
+		Expect(seen).To(Equal([]int{1, 2, 3, 4, 5, 6, 7}))
+	})
+
+	DescribeTable("binds the address string on postgres",
+		func(s string) {
+			db := openDialect("postgres", true)
+
+			keyset := pagetoken.NewKeysetPayloadBuilder().AddNetIP("client_ip", netip.MustParseAddr(s), order.Asc).Build()
+			q, err := ptgorm.KeysetWhereOrderLimit(db.Model(&auditEntry{}), keyset, ptgorm.NetIPValue(db))
+			Expect(err).NotTo(HaveOccurred())
+
+			stmt := q.Find(&[]auditEntry{}).Statement
+			Expect(stmt.Vars).To(Equal([]any{s}))
+		},
+		Entry("IPv4", "10.0.0.2"),
+		Entry("IPv6", "2001:db8::1"),
+		Entry("IPv4-mapped IPv6", "::ffff:10.0.0.2"),
+	)
+
+	It("binds the 16-byte form on other dialects", func() {
+		db := openDialect("mysql", true)
+
+		keyset := pagetoken.NewKeysetPayloadBuilder().AddNetIP("client_ip", netip.MustParseAddr("10.0.0.2"), order.Asc).Build()
+		q, err := ptgorm.KeysetWhereOrderLimit(db.Model(&auditEntry{}), keyset, ptgorm.NetIPValue(db))
+		Expect(err).NotTo(HaveOccurred())
+
+		stmt := q.Find(&[]auditEntry{}).Statement
+		Expect(stmt.Vars).To(Equal([]any{[]byte{10: 0xff, 11: 0xff, 12: 10, 15: 2}}))
+	})
+})
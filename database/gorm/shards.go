@@ -0,0 +1,151 @@
+package gorm
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"gorm.io/gorm"
+)
+
+// ListShards loads a page of up to limit items merged from several shards
+// of the same listing, e.g. one table sharded across databases. Each shard
+// is queried concurrently with its keyset in composite, or from its first
+// row if composite is nil or has none for it, and shards marked done are
+// skipped. The results are merged with MergeShards, which also returns the
+// composite of the next page.
+//
+// cmp must order items like cfg.Spec including the tiebreak, see
+// slices.SortFunc; items comparing equal are taken from the shard with the
+// lowest name first.
+func ListShards[T any](
+	shards map[string]*gorm.DB,
+	composite *pagetoken.CompositePayload,
+	limit int,
+	cfg ListConfig[T],
+	cmp func(a, b T) int,
+) ([]T, *pagetoken.CompositePayload, error) {
+	if composite == nil {
+		composite = pagetoken.NewCompositePayload()
+	}
+
+	opts := append([]KeysetWhereOrderLimitOpt{
+		WithSortSpec(cfg.Spec),
+		WithRequiredTiebreak(cfg.tiebreak()),
+	}, cfg.Opts...)
+
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		rows = map[string][]T{}
+		errs []error
+	)
+	for name, db := range shards {
+		if composite.Done(name) {
+			continue
+		}
+
+		wg.Go(func() {
+			items := []T{}
+			q, err := KeysetWhereOrderLimit(db, composite.Get(name), cfg.ValueFn, opts...)
+			if err == nil {
+				err = q.Limit(limit + 1).Find(&items).Error
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, err)
+				return
+			}
+			rows[name] = items
+		})
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, nil, err
+	}
+
+	spec := pagetoken.EnsureTiebreak(cfg.Spec, cfg.tiebreak())
+	return MergeShards(rows, limit, cmp, func(item T) (*pagetoken.KeysetPayload, error) {
+		return cfg.KeysetFn(item, spec)
+	}, composite)
+}
+
+// MergeShards merges the sorted rows loaded from each shard into a page of
+// up to limit items and returns it together with the composite of the next
+// page, which is nil once all shards are exhausted.
+//
+// rows must hold up to limit+1 rows per queried shard, continuing from its
+// keyset in prev. In the next composite, a shard that contributed to the
+// page continues after its last contributed row, whose keyset is built by
+// keysetFn; a shard all of whose rows were taken is marked done, as it
+// returned no more than limit rows; all other shards keep their keyset of
+// prev. cmp orders the rows as in ListShards.
+func MergeShards[T any](
+	rows map[string][]T,
+	limit int,
+	cmp func(a, b T) int,
+	keysetFn func(item T) (*pagetoken.KeysetPayload, error),
+	prev *pagetoken.CompositePayload,
+) ([]T, *pagetoken.CompositePayload, error) {
+	names := slices.Sorted(maps.Keys(rows))
+	taken := make(map[string]int, len(names))
+
+	items := []T{}
+	for len(items) < limit {
+		next := ""
+		for _, name := range names {
+			i := taken[name]
+			if i == len(rows[name]) {
+				continue
+			}
+			if next == "" || cmp(rows[name][i], rows[next][taken[next]]) < 0 {
+				next = name
+			}
+		}
+		if next == "" {
+			break
+		}
+
+		items = append(items, rows[next][taken[next]])
+		taken[next]++
+	}
+
+	composite := pagetoken.NewCompositePayload()
+	if prev != nil {
+		for _, name := range prev.Names() {
+			if prev.Done(name) {
+				composite.SetDone(name)
+			} else {
+				composite.Set(name, prev.Get(name))
+			}
+		}
+	}
+
+	more := false
+	for _, name := range names {
+		n := taken[name]
+		switch {
+		case n == len(rows[name]):
+			composite.SetDone(name)
+		case n > 0:
+			more = true
+			keyset, err := keysetFn(rows[name][n-1])
+			if err != nil {
+				return nil, nil, err
+			}
+			composite.Set(name, keyset)
+		default:
+			more = true
+		}
+	}
+
+	if !more {
+		return items, nil, nil
+	}
+	return items, composite, nil
+}
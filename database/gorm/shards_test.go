@@ -0,0 +1,131 @@
+package gorm_test
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gorm.io/gorm"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	ptgorm "github.com/pixlcrashr/go-pagetoken/database/gorm"
+	"github.com/pixlcrashr/go-pagetoken/encryption"
+)
+
+func compareItems(a, b item) int {
+	return cmp.Or(cmp.Compare(a.Sort, b.Sort), cmp.Compare(a.ID, b.ID))
+}
+
+var _ = Describe("ListShards", func() {
+	var (
+		shards map[string]*gorm.DB
+		all    []item
+		e      *encryption.AEADEncryptor
+	)
+
+	BeforeEach(func() {
+		key, err := encryption.Rand32ByteKey()
+		Expect(err).NotTo(HaveOccurred())
+		e, err = encryption.NewAEADEncryptor(key)
+		Expect(err).NotTo(HaveOccurred())
+
+		// 25 items spread round-robin over three shards, with sort values
+		// repeating across shards so that the streams interleave and tie
+		shards = map[string]*gorm.DB{}
+		all = []item{}
+		for s := range 3 {
+			db := openDB(false)
+			Expect(db.AutoMigrate(&item{})).To(Succeed())
+			shards[fmt.Sprintf("shard-%d", s+1)] = db
+		}
+		for id := 1; id <= 25; id++ {
+			it := item{ID: id, Sort: (id * 7) % 5}
+			all = append(all, it)
+			Expect(shards[fmt.Sprintf("shard-%d", id%3+1)].Create(&it).Error).To(Succeed())
+		}
+		slices.SortFunc(all, compareItems)
+	})
+
+	// listAllShards pages through all shards, passing the composite through
+	// a serialized token between pages, and returns the visited items.
+	listAllShards := func(limit int) ([]item, int) {
+		parser := pagetoken.NewKeysetTokenParser(pagetoken.WithKeysetTokenEncryptor(e))
+		seen := []item{}
+		pages := 0
+
+		token := ""
+		for {
+			var composite *pagetoken.CompositePayload
+			if token != "" {
+				t, err := parser.Parse(token)
+				Expect(err).NotTo(HaveOccurred())
+				composite = t.CompositePayload()
+			}
+
+			page, next, err := ptgorm.ListShards(shards, composite, limit, itemListConfig(), compareItems)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(len(page)).To(BeNumerically("<=", limit))
+			seen = append(seen, page...)
+			pages++
+
+			if next == nil {
+				return seen, pages
+			}
+			token, err = pagetoken.NewKeysetToken(e, pagetoken.WithCompositePayload(next)).String()
+			Expect(err).NotTo(HaveOccurred())
+		}
+	}
+
+	It("merges the shards in global order without duplicates across pages", func() {
+		seen, pages := listAllShards(4)
+		Expect(seen).To(Equal(all))
+		Expect(pages).To(Equal(7))
+	})
+
+	It("returns everything on one page if the limit allows", func() {
+		seen, pages := listAllShards(25)
+		Expect(seen).To(Equal(all))
+		Expect(pages).To(Equal(1))
+	})
+
+	It("marks exhausted shards as done and stops querying them", func() {
+		Expect(shards["shard-1"].Where("1 = 1").Delete(&item{}).Error).To(Succeed())
+
+		page, next, err := ptgorm.ListShards(shards, nil, 2, itemListConfig(), compareItems)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(page).To(HaveLen(2))
+		Expect(next.Done("shard-1")).To(BeTrue())
+		Expect(next.Done("shard-2")).To(BeFalse())
+
+		// a done shard is skipped even if rows show up again
+		Expect(shards["shard-1"].Create(&item{ID: 100, Sort: -1}).Error).To(Succeed())
+		page, _, err = ptgorm.ListShards(shards, next, 2, itemListConfig(), compareItems)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(page).NotTo(ContainElement(item{ID: 100, Sort: -1}))
+	})
+})
+
+var _ = Describe("MergeShards", func() {
+	It("keeps the keyset of shards that did not contribute to the page", func() {
+		prev := pagetoken.NewCompositePayload()
+		prev.Set("b", itemKeyset(item{ID: 9, Sort: 9}))
+
+		page, next, err := ptgorm.MergeShards(map[string][]item{
+			"a": {{ID: 1, Sort: 1}, {ID: 2, Sort: 2}, {ID: 3, Sort: 3}},
+			"b": {{ID: 10, Sort: 10}},
+		}, 2, compareItems, func(it item) (*pagetoken.KeysetPayload, error) {
+			return itemKeyset(it), nil
+		}, prev)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(page).To(Equal([]item{{ID: 1, Sort: 1}, {ID: 2, Sort: 2}}))
+
+		id, _, err := next.Get("a").Int("id")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(id).To(Equal(2))
+		id, _, err = next.Get("b").Int("id")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(id).To(Equal(9))
+	})
+})
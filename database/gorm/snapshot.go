@@ -0,0 +1,64 @@
+package gorm
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/database/sqlraw"
+	"gorm.io/gorm"
+)
+
+// CockroachSnapshotTx runs fn in a transaction that reads at the snapshot
+// time of token via SET TRANSACTION AS OF SYSTEM TIME, so that every page of
+// the listing sees the same data regardless of concurrent writes. If token
+// carries no snapshot, fn runs in a plain transaction; set one on the first
+// page via token.SetSnapshotTime to pin the following pages.
+//
+// Snapshots older than the maximum age are rejected with
+// sqlraw.ErrSnapshotTooOld before the transaction is started.
+func CockroachSnapshotTx(
+	ctx context.Context,
+	db *gorm.DB,
+	token *pagetoken.KeysetToken,
+	fn func(tx *gorm.DB) error,
+	opts ...sqlraw.SnapshotOpt,
+) error {
+	clause, err := sqlraw.SnapshotClause(token, opts...)
+	if err != nil {
+		return err
+	}
+
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if clause != "" {
+			if err := tx.Exec("SET TRANSACTION " + clause).Error; err != nil {
+				return err
+			}
+		}
+
+		return fn(tx)
+	})
+}
+
+// PostgresSnapshotTx runs fn in a read-only REPEATABLE READ transaction, so
+// that all statements of a page, such as the page query and CountOnce, see
+// the same snapshot. Postgres cannot read at a past point in time, so pages
+// of the same listing still observe writes committed in between; the token's
+// snapshot time is only checked against the maximum age, which bounds how
+// long a listing may drift.
+func PostgresSnapshotTx(
+	ctx context.Context,
+	db *gorm.DB,
+	token *pagetoken.KeysetToken,
+	fn func(tx *gorm.DB) error,
+	opts ...sqlraw.SnapshotOpt,
+) error {
+	if _, _, err := sqlraw.Snapshot(token, opts...); err != nil {
+		return err
+	}
+
+	return db.WithContext(ctx).Transaction(fn, &sql.TxOptions{
+		Isolation: sql.LevelRepeatableRead,
+		ReadOnly:  true,
+	})
+}
@@ -0,0 +1,70 @@
+package gorm_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gorm.io/gorm"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	ptgorm "github.com/pixlcrashr/go-pagetoken/database/gorm"
+	"github.com/pixlcrashr/go-pagetoken/database/sqlraw"
+)
+
+var _ = Describe("CockroachSnapshotTx", func() {
+	ts := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	It("reads the transaction at the token's snapshot time", func() {
+		rec := &sqlRecorder{}
+		db := openDB(true).Session(&gorm.Session{Logger: rec})
+		token := (&pagetoken.KeysetToken{}).Next(pagetoken.WithSnapshotTime(ts))
+
+		Expect(ptgorm.CockroachSnapshotTx(context.Background(), db, token, func(tx *gorm.DB) error {
+			return tx.Find(&[]item{}).Error
+		})).To(Succeed())
+
+		Expect(rec.statements).To(Equal([]string{
+			"SET TRANSACTION AS OF SYSTEM TIME '2024-01-01 12:00:00'",
+			"SELECT * FROM `items`",
+		}))
+	})
+
+	It("runs without snapshot on the first page", func() {
+		rec := &sqlRecorder{}
+		db := openDB(true).Session(&gorm.Session{Logger: rec})
+
+		Expect(ptgorm.CockroachSnapshotTx(context.Background(), db, &pagetoken.KeysetToken{}, func(tx *gorm.DB) error {
+			return tx.Find(&[]item{}).Error
+		})).To(Succeed())
+
+		Expect(rec.statements).To(Equal([]string{"SELECT * FROM `items`"}))
+	})
+
+	It("refuses a snapshot older than the maximum age", func() {
+		db := openDB(true)
+		token := (&pagetoken.KeysetToken{}).Next(pagetoken.WithSnapshotTime(ts))
+
+		called := false
+		err := ptgorm.CockroachSnapshotTx(context.Background(), db, token, func(tx *gorm.DB) error {
+			called = true
+			return nil
+		}, sqlraw.WithMaxSnapshotAge(time.Hour))
+
+		Expect(err).To(MatchError(sqlraw.ErrSnapshotTooOld))
+		Expect(called).To(BeFalse())
+	})
+})
+
+var _ = Describe("PostgresSnapshotTx", func() {
+	It("refuses a snapshot older than the maximum age", func() {
+		db := openDB(true)
+		token := (&pagetoken.KeysetToken{}).Next(pagetoken.WithSnapshotTime(time.Now().Add(-2 * time.Hour)))
+
+		err := ptgorm.PostgresSnapshotTx(context.Background(), db, token, func(tx *gorm.DB) error {
+			return nil
+		}, sqlraw.WithMaxSnapshotAge(time.Hour))
+		Expect(err).To(MatchError(sqlraw.ErrSnapshotTooOld))
+	})
+})
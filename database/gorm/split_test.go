@@ -0,0 +1,106 @@
+package gorm_test
+
+import (
+	"slices"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gorm.io/gorm"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	ptgorm "github.com/pixlcrashr/go-pagetoken/database/gorm"
+	"github.com/pixlcrashr/go-pagetoken/encryption"
+	"github.com/pixlcrashr/go-pagetoken/order"
+)
+
+var _ = Describe("Split tokens", func() {
+	var (
+		db *gorm.DB
+		rr *pagetoken.RequestReader
+	)
+
+	byID := pagetoken.SortSpec{{Path: "id", Order: order.Asc}}
+
+	BeforeEach(func() {
+		db = openDB(false)
+		seedItems(db, 50)
+
+		key, err := encryption.Rand32ByteKey()
+		Expect(err).NotTo(HaveOccurred())
+		e, err := encryption.NewAEADEncryptor(key)
+		Expect(err).NotTo(HaveOccurred())
+		rr = pagetoken.NewRequestReader(pagetoken.WithEncryptor(e))
+	})
+
+	// walkToken pages through the items from token on, honoring the upper
+	// bound of the tokens, and returns the visited ids
+	walkToken := func(token string, pageSize int) []int {
+		seen := []int{}
+		for {
+			t, err := rr.Read(listRequest{token: token})
+			Expect(err).NotTo(HaveOccurred())
+
+			cfg := ptgorm.ListConfig[item]{
+				Spec:     byID,
+				ValueFn:  itemValue,
+				KeysetFn: itemKeysetFor,
+				Opts:     []ptgorm.KeysetWhereOrderLimitOpt{ptgorm.WithUpperBound(t.UpperBound())},
+			}
+			page, next, err := ptgorm.ListKeyset(db.Model(&item{}), t.Payload(), pageSize, cfg)
+			Expect(err).NotTo(HaveOccurred())
+			for _, it := range page {
+				seen = append(seen, it.ID)
+			}
+
+			if token, err = rr.NextToken(t, next); err != nil || token == "" {
+				Expect(err).NotTo(HaveOccurred())
+				return seen
+			}
+		}
+	}
+
+	It("fetches the rows of a sequential walk exactly once across the shards", func() {
+		sequential := walkToken("", 7)
+		Expect(sequential).To(Equal(ids(1, 50)))
+
+		// the token after the first page of a sequential walk
+		first, err := rr.Read(listRequest{})
+		Expect(err).NotTo(HaveOccurred())
+		s, err := rr.NextToken(first, pagetoken.NewKeysetPayloadBuilder().AddInt("id", 7, order.Asc).Build())
+		Expect(err).NotTo(HaveOccurred())
+		t, err := rr.Read(listRequest{token: s})
+		Expect(err).NotTo(HaveOccurred())
+
+		shards, err := pagetoken.SplitToken(t, 3, pagetoken.IntRange("id", 1, 50))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(shards).To(HaveLen(3))
+
+		union := []int{}
+		for _, shard := range shards {
+			s, err := shard.String()
+			Expect(err).NotTo(HaveOccurred())
+
+			rows := walkToken(s, 5)
+			Expect(rows).NotTo(BeEmpty())
+			union = append(union, rows...)
+		}
+
+		Expect(union).To(HaveLen(len(sequential)-7), "the shards must not overlap")
+		slices.Sort(union)
+		Expect(union).To(Equal(sequential[7:]))
+	})
+
+	It("renders the upper bound", func() {
+		bound := pagetoken.NewKeysetPayloadBuilder().AddInt("id", 20, order.Asc).Build()
+
+		q, err := ptgorm.KeysetWhereOrderLimit(openDB(true).Model(&item{}), nil, itemValue,
+			ptgorm.WithSortSpec(byID),
+			ptgorm.WithUpperBound(bound),
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		stmt := q.Find(&[]item{}).Statement
+		Expect(stmt.SQL.String()).To(Equal("SELECT * FROM `items` WHERE (id <= ?) ORDER BY id ASC"))
+		Expect(stmt.Vars).To(Equal([]any{20}))
+	})
+})
@@ -0,0 +1,60 @@
+package gorm
+
+import (
+	"encoding/hex"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"gorm.io/gorm"
+)
+
+// UUIDValue returns a value hook for columns holding 128-bit identifiers
+// (UUIDv7, ULID, ...) stored in the payload via AddUUID. The identifier is
+// converted to the column's storage form for db's dialect, so the keyset
+// predicate compares it by the same order the database sorts it by:
+//
+//   - postgres: the dashed UUID string, for columns of type uuid
+//   - all other dialects: the raw 16 bytes, for BINARY(16) or BLOB columns
+//
+// Identifiers stored as CHAR(32) hex need no hook; payload.String returns
+// them in their storage form already.
+//
+// Example:
+//
+//	idValue := ptgorm.UUIDValue(db)
+//	q, err := ptgorm.KeysetWhereOrderLimit(db, keyset, func(column string, p *pagetoken.KeysetPayload) (any, error) {
+//		if column == "id" {
+//			return idValue(column, p)
+//		}
+//		v, _, err := p.Time(column)
+//		return v, err
+//	})
+func UUIDValue(db *gorm.DB) KeysetWhereOrderLimitValueFn {
+	native := db.Dialector.Name() == "postgres"
+
+	return func(column string, payload *pagetoken.KeysetPayload) (any, error) {
+		v, _, err := payload.UUID(column)
+		if err != nil {
+			return nil, err
+		}
+
+		if native {
+			return formatUUID(v), nil
+		}
+
+		return v[:], nil
+	}
+}
+
+func formatUUID(v [16]byte) string {
+	buf := make([]byte, 36)
+	hex.Encode(buf[0:8], v[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], v[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], v[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], v[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], v[10:])
+	return string(buf)
+}
@@ -0,0 +1,191 @@
+package gorm_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+	"slices"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	ptgorm "github.com/pixlcrashr/go-pagetoken/database/gorm"
+	"github.com/pixlcrashr/go-pagetoken/order"
+)
+
+type blobEvent struct {
+	ID []byte `gorm:"primaryKey;type:blob"`
+}
+
+type binaryEvent struct {
+	ID []byte `gorm:"primaryKey;type:binary(16)"`
+}
+
+// renamedDialector reports a different dialect name while keeping sqlite's
+// behavior, which is enough to exercise dialect-dependent value hooks.
+type renamedDialector struct {
+	gorm.Dialector
+	name string
+}
+
+func (d renamedDialector) Name() string {
+	return d.name
+}
+
+func openDialect(name string, dryRun bool) *gorm.DB {
+	var d gorm.Dialector = sqlite.Open(":memory:")
+	if name != "" {
+		d = renamedDialector{Dialector: d, name: name}
+	}
+
+	db, err := gorm.Open(d, &gorm.Config{
+		DryRun: dryRun,
+		Logger: logger.Discard,
+	})
+	Expect(err).NotTo(HaveOccurred())
+
+	sqlDB, err := db.DB()
+	Expect(err).NotTo(HaveOccurred())
+	sqlDB.SetMaxOpenConns(1)
+
+	return db
+}
+
+// uuidV7s returns n UUIDv7 values in ascending order. Several values share a
+// millisecond, so their order is decided by the random bits.
+func uuidV7s(n int) [][16]byte {
+	r := rand.New(rand.NewSource(7))
+	vs := make([][16]byte, n)
+	for i := range vs {
+		var v [16]byte
+		binary.BigEndian.PutUint64(v[0:8], uint64(1_700_000_000_000+i/4)<<16)
+		binary.BigEndian.PutUint64(v[8:16], r.Uint64())
+		v[6] = 0x70 | v[6]&0x0f
+		v[8] = 0x80 | v[8]&0x3f
+		vs[i] = v
+	}
+
+	slices.SortFunc(vs, func(a, b [16]byte) int {
+		return bytes.Compare(a[:], b[:])
+	})
+	return vs
+}
+
+func walkUUIDs(db *gorm.DB, model any, pageSize int) [][16]byte {
+	idValue := ptgorm.UUIDValue(db)
+	var keyset *pagetoken.KeysetPayload
+	seen := [][16]byte{}
+
+	for {
+		q, err := ptgorm.KeysetWhereOrderLimit(db.Model(model), keyset, idValue)
+		Expect(err).NotTo(HaveOccurred())
+		if keyset == nil {
+			q = q.Order("id ASC")
+		}
+
+		page := []struct{ ID []byte }{}
+		Expect(q.Limit(pageSize).Find(&page).Error).To(Succeed())
+		Expect(len(seen)).To(BeNumerically("<=", 100), "pagination does not terminate")
+
+		for _, row := range page {
+			seen = append(seen, [16]byte(row.ID))
+		}
+
+		if len(page) < pageSize {
+			return seen
+		}
+
+		keyset = pagetoken.NewKeysetPayloadBuilder().
+			AddUUID("id", [16]byte(page[len(page)-1].ID), order.Asc).
+			Build()
+	}
+}
+
+var _ = Describe("UUIDValue", func() {
+	ids := uuidV7s(22)
+
+	seed := func(db *gorm.DB, model any, mk func(id []byte) any) {
+		Expect(db.AutoMigrate(model)).To(Succeed())
+
+		shuffled := slices.Clone(ids)
+		rand.New(rand.NewSource(1)).Shuffle(len(shuffled), func(i, j int) {
+			shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+		})
+		for _, id := range shuffled {
+			Expect(db.Create(mk(slices.Clone(id[:]))).Error).To(Succeed())
+		}
+	}
+
+	It("walks UUIDv7 keys stored as sqlite blobs", func() {
+		db := openDialect("", false)
+		seed(db, &blobEvent{}, func(id []byte) any { return &blobEvent{ID: id} })
+
+		Expect(walkUUIDs(db, &blobEvent{}, 5)).To(Equal(ids))
+	})
+
+	It("walks UUIDv7 keys stored in a mysql BINARY(16) column", func() {
+		db := openDialect("mysql", false)
+		seed(db, &binaryEvent{}, func(id []byte) any { return &binaryEvent{ID: id} })
+
+		Expect(walkUUIDs(db, &binaryEvent{}, 5)).To(Equal(ids))
+	})
+
+	Describe("binding the dashed string form to a binary column", func() {
+		// dashed binds what UUIDValue produces for postgres, the mistake of
+		// reusing a postgres value hook against a blob column.
+		dashed := ptgorm.UUIDValue(openDialect("postgres", true))
+
+		query := func(direction order.Order) [][16]byte {
+			db := openDialect("", false)
+			seed(db, &blobEvent{}, func(id []byte) any { return &blobEvent{ID: id} })
+
+			keyset := pagetoken.NewKeysetPayloadBuilder().AddUUID("id", ids[10], direction).Build()
+			q, err := ptgorm.KeysetWhereOrderLimit(db.Model(&blobEvent{}), keyset, dashed)
+			Expect(err).NotTo(HaveOccurred())
+
+			page := []struct{ ID []byte }{}
+			Expect(q.Find(&page).Error).To(Succeed())
+
+			seen := [][16]byte{}
+			for _, row := range page {
+				seen = append(seen, [16]byte(row.ID))
+			}
+			return seen
+		}
+
+		It("binds the dashed string", func() {
+			id := ids[10]
+			v, err := dashed("id", pagetoken.NewKeysetPayloadBuilder().AddUUID("id", id, order.Asc).Build())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(v).To(Equal(fmt.Sprintf("%x-%x-%x-%x-%x", id[0:4], id[4:6], id[6:8], id[8:10], id[10:])))
+		})
+
+		It("returns every row instead of the rows after the boundary in ascending order", func() {
+			seen := query(order.Asc)
+			Expect(seen).To(Equal(ids), "text never compares above a blob, so every row matches")
+		})
+
+		It("returns no rows instead of the rows before the boundary in descending order", func() {
+			seen := query(order.Desc)
+			Expect(seen).To(BeEmpty(), "a blob never compares below text, so no row matches")
+		})
+	})
+
+	It("binds the dashed string form on postgres", func() {
+		db := openDialect("postgres", true)
+
+		id := [16]byte{0x01, 0x8f, 0x3a, 0x7c, 0xde, 0xad, 0x7b, 0xee, 0xaf, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xff}
+		keyset := pagetoken.NewKeysetPayloadBuilder().AddUUID("id", id, order.Asc).Build()
+
+		q, err := ptgorm.KeysetWhereOrderLimit(db.Model(&blobEvent{}), keyset, ptgorm.UUIDValue(db))
+		Expect(err).NotTo(HaveOccurred())
+
+		stmt := q.Find(&[]blobEvent{}).Statement
+		Expect(stmt.Vars).To(Equal([]any{"018f3a7c-dead-7bee-af00-0000000000ff"}))
+	})
+})
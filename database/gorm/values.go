@@ -0,0 +1,29 @@
+package gorm
+
+import (
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/database/sqlraw"
+)
+
+// CollatedValue binds the original string of a value stored via
+// pagetoken.KeysetPayloadBuilder.AddCollated, see sqlraw.CollatedValue.
+func CollatedValue(column string, payload *pagetoken.KeysetPayload) (any, error) {
+	v, _, err := payload.CollatedString(column)
+	return v, err
+}
+
+// DateValue binds a value stored via pagetoken.KeysetPayloadBuilder.AddDate
+// as its "2006-01-02" string, see sqlraw.DateValue.
+//
+// Example:
+//
+//	q, err := ptgorm.KeysetWhereOrderLimit(db, keyset, func(column string, p *pagetoken.KeysetPayload) (any, error) {
+//		if column == "report_date" {
+//			return ptgorm.DateValue(column, p)
+//		}
+//		v, _, err := p.Int64(column)
+//		return v, err
+//	})
+func DateValue(column string, payload *pagetoken.KeysetPayload) (any, error) {
+	return sqlraw.DateValue(column, payload)
+}
@@ -0,0 +1,202 @@
+package gorm_test
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gorm.io/gorm"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	ptgorm "github.com/pixlcrashr/go-pagetoken/database/gorm"
+	"github.com/pixlcrashr/go-pagetoken/order"
+	"github.com/pixlcrashr/go-pagetoken/pagetokentest"
+)
+
+type event struct {
+	ID     int `gorm:"primaryKey"`
+	At     time.Time
+	Rating *int
+	Name   string `gorm:"type:text COLLATE NOCASE"`
+}
+
+// ratingOrNull sorts NULL ratings before all others.
+const ratingOrNull = "COALESCE(rating, -1)"
+
+func eventValue(column string, payload *pagetoken.KeysetPayload) (any, error) {
+	switch column {
+	case "at":
+		v, _, err := payload.Time(column)
+		return v, err
+	case "name":
+		v, _, err := payload.String(column)
+		return v, err
+	default:
+		v, _, err := payload.Int(column)
+		return v, err
+	}
+}
+
+func eventKeyset(ev event, spec pagetoken.SortSpec) (*pagetoken.KeysetPayload, error) {
+	b := pagetoken.NewKeysetPayloadBuilder()
+	for _, f := range spec {
+		switch f.Path {
+		case "at":
+			b.AddTime(f.Path, ev.At, f.Order)
+		case "name":
+			b.AddString(f.Path, ev.Name, f.Order)
+		case "id":
+			b.AddInt(f.Path, ev.ID, f.Order)
+		case "rating", ratingOrNull:
+			r := -1
+			if ev.Rating != nil {
+				r = *ev.Rating
+			}
+			b.AddInt(f.Path, r, f.Order)
+		default:
+			return nil, fmt.Errorf("unknown column %q", f.Path)
+		}
+	}
+	return b.Build(), nil
+}
+
+// seedEvents inserts n events whose timestamps repeat in groups of four,
+// every third of which has no rating, and whose names differ in case only.
+func seedEvents(db *gorm.DB, n int) []event {
+	Expect(db.AutoMigrate(&event{})).To(Succeed())
+
+	at := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	names := []string{"alice", "Bob", "ALICE", "bob", "Carol", "carol"}
+
+	evs := make([]event, n)
+	for i := range evs {
+		evs[i] = event{
+			ID:   i + 1,
+			At:   at.Add(time.Duration(i/4) * time.Minute),
+			Name: names[i%len(names)],
+		}
+		if i%3 != 0 {
+			r := i % 5
+			evs[i].Rating = &r
+		}
+	}
+	Expect(db.Create(&evs).Error).To(Succeed())
+
+	return evs
+}
+
+// fetchEvents returns a WalkAllPages fetcher listing events via ListKeyset
+// with tokens minted by pagetokentest.
+func fetchEvents(db *gorm.DB, pageSize int, spec pagetoken.SortSpec) func(string) ([]event, string, error) {
+	cfg := ptgorm.ListConfig[event]{
+		Spec:     spec,
+		ValueFn:  eventValue,
+		KeysetFn: eventKeyset,
+	}
+
+	return func(token string) ([]event, string, error) {
+		var keyset *pagetoken.KeysetPayload
+		if token != "" {
+			keyset = pagetokentest.ParseForTest(GinkgoT(), token).Payload()
+		}
+
+		evs, next, err := ptgorm.ListKeyset(db.Model(&event{}), keyset, pageSize, cfg)
+		if err != nil || next == nil {
+			return evs, "", err
+		}
+
+		return evs, pagetokentest.MustToken(GinkgoT(), next.Values()...), nil
+	}
+}
+
+func eventID(ev event) string {
+	return strconv.Itoa(ev.ID)
+}
+
+// eventIDs returns the ids of evs sorted by compare and then by id.
+func eventIDs(evs []event, compare func(a, b event) int) []string {
+	evs = slices.Clone(evs)
+	slices.SortFunc(evs, func(a, b event) int {
+		return cmp.Or(compare(a, b), a.ID-b.ID)
+	})
+
+	ids := make([]string, len(evs))
+	for i, ev := range evs {
+		ids[i] = eventID(ev)
+	}
+	return ids
+}
+
+func rating(ev event) int {
+	if ev.Rating == nil {
+		return -1
+	}
+	return *ev.Rating
+}
+
+var _ = Describe("ListKeyset pages", func() {
+	var (
+		db  *gorm.DB
+		evs []event
+	)
+
+	BeforeEach(func() {
+		db = openDB(false)
+		evs = seedEvents(db, 23)
+	})
+
+	DescribeTable("visit every row exactly once and in order",
+		func(spec pagetoken.SortSpec, compare func(a, b event) int) {
+			for _, pageSize := range []int{1, 3, 4, 5, 23} {
+				By("pages of " + strconv.Itoa(pageSize))
+				pagetokentest.WalkAllPages(GinkgoT(), fetchEvents(db, pageSize, spec), eventID, eventIDs(evs, compare))
+			}
+		},
+		Entry("by timestamps with duplicates",
+			pagetoken.SortSpec{{Path: "at", Order: order.Asc}},
+			func(a, b event) int { return a.At.Compare(b.At) },
+		),
+		Entry("by timestamps with duplicates descending",
+			pagetoken.SortSpec{{Path: "at", Order: order.Desc}},
+			func(a, b event) int { return cmp.Or(b.At.Compare(a.At), b.ID-a.ID) },
+		),
+		Entry("by a nullable column coalesced in the keyset",
+			pagetoken.SortSpec{{Path: ratingOrNull, Order: order.Asc}},
+			func(a, b event) int { return rating(a) - rating(b) },
+		),
+		Entry("by a case-insensitive collation",
+			pagetoken.SortSpec{{Path: "name", Order: order.Asc}},
+			func(a, b event) int { return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name)) },
+		),
+		Entry("by a case-insensitive collation and timestamps",
+			pagetoken.SortSpec{{Path: "name", Order: order.Desc}, {Path: "at", Order: order.Asc}},
+			func(a, b event) int {
+				return cmp.Or(strings.Compare(strings.ToLower(b.Name), strings.ToLower(a.Name)), a.At.Compare(b.At))
+			},
+		),
+	)
+
+	It("skip rows of a nullable column compared as it is", func() {
+		t := &recordingT{}
+		pagetokentest.WalkAllPages(t, fetchEvents(db, 4, pagetoken.SortSpec{{Path: "rating", Order: order.Asc}}), eventID,
+			eventIDs(evs, func(a, b event) int { return rating(a) - rating(b) }))
+
+		Expect(t.failures).To(ConsistOf(ContainSubstring("missing")))
+	})
+})
+
+// recordingT records failures instead of stopping the spec.
+type recordingT struct {
+	failures []string
+}
+
+func (*recordingT) Helper() {}
+
+func (r *recordingT) Fatalf(format string, args ...any) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,337 @@
+package sqlraw
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/order"
+)
+
+// Dialect selects the placeholder syntax, the identifier quoting and the
+// row limit clause of the generated SQL. The predicates are the same for
+// every dialect: they consist of plain comparisons joined by AND, OR and
+// NOT, without boolean literals or row value comparisons such as
+// (a, b) > (?, ?), which SQL Server and Oracle do not support.
+type Dialect string
+
+const (
+	// DialectSQLite uses "?" placeholders.
+	DialectSQLite Dialect = "sqlite"
+	// DialectMySQL uses "?" placeholders.
+	DialectMySQL Dialect = "mysql"
+	// DialectPostgres uses "$1", "$2", ... placeholders.
+	DialectPostgres Dialect = "postgres"
+	// DialectSQLServer uses "@p1", "@p2", ... placeholders and quotes
+	// columns in brackets, e.g. [created_at].
+	DialectSQLServer Dialect = "sqlserver"
+	// DialectOracle uses ":1", ":2", ... placeholders and quotes columns in
+	// double quotes, e.g. "created_at". Quoted identifiers are case
+	// sensitive in Oracle, so paths must match the case the columns are
+	// stored in, which is upper case for columns created unquoted.
+	DialectOracle Dialect = "oracle"
+)
+
+// ErrInvalidTiebreak is returned when an inclusive boundary is requested but
+// the keyset does not end with the configured tiebreak column.
+var ErrInvalidTiebreak = errors.New("inclusive boundary: tiebreak column must be the last keyset column")
+
+// KeysetValueFn resolves the bind argument for a keyset column.
+type KeysetValueFn func(column string, payload *pagetoken.KeysetPayload) (any, error)
+
+// Builder renders keyset payloads as raw SQL fragments.
+type Builder struct {
+	dialect           Dialect
+	argOffset         int
+	inclusiveTiebreak string
+	columnFn          ColumnFn
+}
+
+type BuilderOpt func(*Builder)
+
+// WithDialect sets the SQL dialect. The default is DialectSQLite.
+func WithDialect(d Dialect) BuilderOpt {
+	return func(b *Builder) {
+		b.dialect = d
+	}
+}
+
+// WithArgOffset shifts numbered placeholders by n, for queries that already
+// bind n arguments before the keyset fragment. It has no effect on dialects
+// using "?" placeholders.
+func WithArgOffset(n int) BuilderOpt {
+	return func(b *Builder) {
+		b.argOffset = n
+	}
+}
+
+// WithInclusiveBoundary makes KeysetWhere compare the leading sort column
+// inclusively and exclude the rows already returned via the tiebreak column
+// instead of expanding the keyset into strict comparisons:
+//
+//	created_at >= ? AND NOT (created_at = ? AND id <= ?)
+//
+// instead of
+//
+//	(created_at > ?) OR (created_at = ? AND id > ?)
+//
+// Both forms select exactly the same rows, so the option changes the shape
+// of the query, not the result, and tokens stay valid when it is toggled.
+// The inclusive form leads with a single range predicate on the primary sort
+// column, which some planners turn into an index range scan more reliably
+// than an OR chain. In exchange it binds the leading value twice and
+// compares the boundary's sort value again for every row of the range, so
+// it only pays off where EXPLAIN shows the strict form falling back to a
+// full scan. tiebreakColumn must be unique and must be the last column of
+// the keyset, otherwise KeysetWhere fails with ErrInvalidTiebreak; keysets
+// of a single column always use the strict form. Like every keyset scheme,
+// neither form revisits a row whose sort value is updated to a position
+// before the boundary.
+func WithInclusiveBoundary(tiebreakColumn string) BuilderOpt {
+	return func(b *Builder) {
+		b.inclusiveTiebreak = tiebreakColumn
+	}
+}
+
+// WithColumnFn renders every keyset path through fn instead of using it as
+// the column name, e.g. to map nested paths into a JSON document via
+// JSONColumns. The expressions of fn are used verbatim, without the
+// identifier quoting of the dialect, see QuoteIdentifier. KeysetWhere then
+// rejects paths that are not valid per pagetoken.ValidatePath; sort specs
+// passed to OrderBy must be validated by the caller.
+func WithColumnFn(fn ColumnFn) BuilderOpt {
+	return func(b *Builder) {
+		b.columnFn = fn
+	}
+}
+
+func NewBuilder(opts ...BuilderOpt) *Builder {
+	b := &Builder{
+		dialect: DialectSQLite,
+	}
+
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	return b
+}
+
+// args collects bind arguments and renders the matching placeholders.
+type args struct {
+	b  *Builder
+	vs []any
+}
+
+func (a *args) add(v any) string {
+	a.vs = append(a.vs, v)
+
+	n := strconv.Itoa(a.b.argOffset + len(a.vs))
+	switch a.b.dialect {
+	case DialectPostgres:
+		return "$" + n
+	case DialectSQLServer:
+		return "@p" + n
+	case DialectOracle:
+		return ":" + n
+	default:
+		return "?"
+	}
+}
+
+func compareOp(o order.Order, inclusive bool) string {
+	op := ">"
+	if o == order.Desc {
+		op = "<"
+	}
+
+	if inclusive {
+		op += "="
+	}
+
+	return op
+}
+
+// KeysetWhere builds a boolean SQL expression selecting the rows after the
+// boundary described by keyset, together with its bind arguments. An empty
+// (or nil) keyset yields an empty expression. The expression is rendered in
+// the inclusive form if the builder was created with WithInclusiveBoundary.
+func (b *Builder) KeysetWhere(
+	keyset *pagetoken.KeysetPayload,
+	valueFn KeysetValueFn,
+) (string, []any, error) {
+	if keyset == nil {
+		return "", nil, nil
+	}
+
+	vs := keyset.Values()
+	if len(vs) == 0 {
+		return "", nil, nil
+	}
+
+	values, err := b.values(keyset, valueFn)
+	if err != nil {
+		return "", nil, err
+	}
+
+	a := &args{b: b}
+
+	if b.inclusiveTiebreak != "" {
+		if vs[len(vs)-1].Path != b.inclusiveTiebreak {
+			return "", nil, fmt.Errorf("%w: %s", ErrInvalidTiebreak, b.inclusiveTiebreak)
+		}
+
+		if len(vs) > 1 {
+			return b.inclusiveWhere(a, vs, values), a.vs, nil
+		}
+	}
+
+	return "(" + b.strictWhere(a, vs, values) + ")", a.vs, nil
+}
+
+// UpperBoundWhere builds a boolean SQL expression selecting the rows at or
+// before bound, the upper bound of a shard token returned by
+// pagetoken.SplitToken, see KeysetToken.UpperBound, together with its bind
+// arguments:
+//
+//	(id <= ?)
+//
+// A nil bound yields an empty expression. Queries combining it with
+// KeysetWhere on numbered placeholders need a second builder whose
+// WithArgOffset skips the arguments of the keyset.
+func (b *Builder) UpperBoundWhere(
+	bound *pagetoken.KeysetPayload,
+	valueFn KeysetValueFn,
+) (string, []any, error) {
+	if bound == nil || len(bound.Values()) == 0 {
+		return "", nil, nil
+	}
+
+	values, err := b.values(bound, valueFn)
+	if err != nil {
+		return "", nil, err
+	}
+
+	a := &args{b: b}
+	where := b.seenWhere(a, bound.Values(), values)
+	if len(values) == 1 {
+		where = "(" + where + ")"
+	}
+
+	return where, a.vs, nil
+}
+
+// values validates the paths of keyset and resolves its bind arguments,
+// once up front so each column is decoded only once even though it is
+// bound several times.
+func (b *Builder) values(keyset *pagetoken.KeysetPayload, valueFn KeysetValueFn) ([]any, error) {
+	vs := keyset.Values()
+
+	if b.columnFn != nil {
+		for _, v := range vs {
+			if err := pagetoken.ValidatePath(v.Path); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	values := make([]any, len(vs))
+	for i, v := range vs {
+		aV, err := valueFn(v.Path, keyset)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = aV
+	}
+
+	return values, nil
+}
+
+// strictWhere renders the expanded form
+// (c0 > ?) OR (c0 = ? AND c1 > ?) OR ...
+func (b *Builder) strictWhere(a *args, vs []pagetoken.KeysetValue, values []any) string {
+	orExprs := []string{}
+
+	for i := 0; i < len(vs); i++ {
+		andExprs := []string{}
+		for j := 0; j < i; j++ {
+			andExprs = append(andExprs, fmt.Sprintf("%s = %s", b.column(vs[j].Path), a.add(values[j])))
+		}
+
+		andExprs = append(andExprs, fmt.Sprintf("%s %s %s", b.column(vs[i].Path), compareOp(vs[i].Order, false), a.add(values[i])))
+
+		orExprs = append(orExprs, "("+strings.Join(andExprs, " AND ")+")")
+	}
+
+	return strings.Join(orExprs, " OR ")
+}
+
+// inclusiveWhere renders
+// (c0 >= ? AND NOT (c0 = ? AND <rest at or before the boundary>))
+func (b *Builder) inclusiveWhere(a *args, vs []pagetoken.KeysetValue, values []any) string {
+	lead := fmt.Sprintf("%s %s %s", b.column(vs[0].Path), compareOp(vs[0].Order, true), a.add(values[0]))
+	eq := fmt.Sprintf("%s = %s", b.column(vs[0].Path), a.add(values[0]))
+
+	return "(" + lead + " AND NOT (" + eq + " AND " + b.seenWhere(a, vs[1:], values[1:]) + "))"
+}
+
+// seenWhere renders the predicate matching the rows that sort at or before
+// the boundary on the given columns, i.e. the rows that were already returned.
+func (b *Builder) seenWhere(a *args, vs []pagetoken.KeysetValue, values []any) string {
+	if len(vs) == 1 {
+		return fmt.Sprintf("%s %s %s", b.column(vs[0].Path), compareOp(reverse(vs[0].Order), true), a.add(values[0]))
+	}
+
+	orExprs := []string{}
+	for i := 0; i < len(vs); i++ {
+		andExprs := []string{}
+		for j := 0; j < i; j++ {
+			andExprs = append(andExprs, fmt.Sprintf("%s = %s", b.column(vs[j].Path), a.add(values[j])))
+		}
+
+		last := i == len(vs)-1
+		andExprs = append(andExprs, fmt.Sprintf("%s %s %s", b.column(vs[i].Path), compareOp(reverse(vs[i].Order), last), a.add(values[i])))
+
+		orExprs = append(orExprs, "("+strings.Join(andExprs, " AND ")+")")
+	}
+
+	return "(" + strings.Join(orExprs, " OR ") + ")"
+}
+
+// OrderBy renders spec as the body of an ORDER BY clause, e.g.
+// "created_at DESC, id ASC".
+func (b *Builder) OrderBy(spec pagetoken.SortSpec) string {
+	exprs := make([]string, 0, len(spec))
+	for _, f := range spec {
+		exprs = append(exprs, fmt.Sprintf("%s %s", b.column(f.Path), orderToSQL(f.Order)))
+	}
+
+	return strings.Join(exprs, ", ")
+}
+
+// column renders the SQL expression of a keyset path.
+func (b *Builder) column(path string) string {
+	if b.columnFn == nil {
+		return QuoteIdentifier(b.dialect, path)
+	}
+
+	return b.columnFn(path)
+}
+
+func reverse(o order.Order) order.Order {
+	if o == order.Asc {
+		return order.Desc
+	}
+
+	return order.Asc
+}
+
+func orderToSQL(o order.Order) string {
+	if o == order.Asc {
+		return "ASC"
+	}
+
+	return "DESC"
+}
@@ -0,0 +1,199 @@
+package sqlraw_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/database/sqlraw"
+	"github.com/pixlcrashr/go-pagetoken/order"
+	"golang.org/x/text/language"
+)
+
+func stringValue(column string, payload *pagetoken.KeysetPayload) (any, error) {
+	v, _, err := payload.String(column)
+	return v, err
+}
+
+var _ = Describe("Builder", func() {
+	Describe("KeysetWhere", func() {
+		It("returns an empty expression for a nil keyset", func() {
+			where, args, err := sqlraw.NewBuilder().KeysetWhere(nil, stringValue)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(where).To(BeEmpty())
+			Expect(args).To(BeEmpty())
+		})
+
+		It("expands the keyset into strict comparisons", func() {
+			p := pagetoken.NewKeysetPayloadBuilder().
+				AddString("created_at", "t", order.Desc).
+				AddString("id", "x", order.Asc).
+				Build()
+
+			where, args, err := sqlraw.NewBuilder().KeysetWhere(p, stringValue)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(where).To(Equal("((created_at < ?) OR (created_at = ? AND id > ?))"))
+			Expect(args).To(Equal([]any{"t", "t", "x"}))
+		})
+
+		It("numbers postgres placeholders after the offset", func() {
+			p := pagetoken.NewKeysetPayloadBuilder().
+				AddString("created_at", "t", order.Desc).
+				AddString("id", "x", order.Asc).
+				Build()
+
+			where, _, err := sqlraw.NewBuilder(
+				sqlraw.WithDialect(sqlraw.DialectPostgres),
+				sqlraw.WithArgOffset(2),
+			).KeysetWhere(p, stringValue)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(where).To(Equal("((created_at < $3) OR (created_at = $4 AND id > $5))"))
+		})
+
+		Describe("WithInclusiveBoundary", func() {
+			It("renders the two-column form", func() {
+				p := pagetoken.NewKeysetPayloadBuilder().
+					AddString("sort", "s", order.Asc).
+					AddString("id", "x", order.Asc).
+					Build()
+
+				where, args, err := sqlraw.NewBuilder(sqlraw.WithInclusiveBoundary("id")).KeysetWhere(p, stringValue)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(where).To(Equal("(sort >= ? AND NOT (sort = ? AND id <= ?))"))
+				Expect(args).To(Equal([]any{"s", "s", "x"}))
+			})
+
+			It("honors descending columns", func() {
+				p := pagetoken.NewKeysetPayloadBuilder().
+					AddString("sort", "s", order.Desc).
+					AddString("id", "x", order.Desc).
+					Build()
+
+				where, _, err := sqlraw.NewBuilder(sqlraw.WithInclusiveBoundary("id")).KeysetWhere(p, stringValue)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(where).To(Equal("(sort <= ? AND NOT (sort = ? AND id >= ?))"))
+			})
+
+			It("renders intermediate columns lexicographically", func() {
+				p := pagetoken.NewKeysetPayloadBuilder().
+					AddString("a", "1", order.Desc).
+					AddString("b", "2", order.Asc).
+					AddString("id", "3", order.Asc).
+					Build()
+
+				where, args, err := sqlraw.NewBuilder(sqlraw.WithInclusiveBoundary("id")).KeysetWhere(p, stringValue)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(where).To(Equal("(a <= ? AND NOT (a = ? AND ((b < ?) OR (b = ? AND id <= ?))))"))
+				Expect(args).To(Equal([]any{"1", "1", "2", "2", "3"}))
+			})
+
+			It("falls back to the strict form for a tiebreak-only keyset", func() {
+				p := pagetoken.NewKeysetPayloadBuilder().
+					AddString("id", "x", order.Asc).
+					Build()
+
+				where, _, err := sqlraw.NewBuilder(sqlraw.WithInclusiveBoundary("id")).KeysetWhere(p, stringValue)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(where).To(Equal("((id > ?))"))
+			})
+
+			It("fails when the tiebreak is not the last column", func() {
+				p := pagetoken.NewKeysetPayloadBuilder().
+					AddString("id", "x", order.Asc).
+					AddString("sort", "s", order.Asc).
+					Build()
+
+				_, _, err := sqlraw.NewBuilder(sqlraw.WithInclusiveBoundary("id")).KeysetWhere(p, stringValue)
+				Expect(err).To(MatchError(sqlraw.ErrInvalidTiebreak))
+			})
+		})
+
+		It("binds the original string of collated values", func() {
+			p := pagetoken.NewKeysetPayloadBuilder().
+				AddCollated("name", "Äpfel", language.German, order.Asc).
+				Build()
+
+			_, args, err := sqlraw.NewBuilder().KeysetWhere(p, sqlraw.CollatedValue)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(args).To(Equal([]any{"Äpfel"}))
+		})
+
+		Describe("WithColumnFn", func() {
+			It("renders nested paths as postgres JSONB accessors", func() {
+				p := pagetoken.NewKeysetPayloadBuilder().
+					AddString("author.name", "n", order.Asc).
+					AddString("id", "x", order.Asc).
+					Build()
+
+				where, _, err := sqlraw.NewBuilder(
+					sqlraw.WithDialect(sqlraw.DialectPostgres),
+					sqlraw.WithColumnFn(sqlraw.JSONColumns("data", sqlraw.PostgresJSONPath)),
+				).KeysetWhere(p, stringValue)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(where).To(Equal("((data->'author'->>'name' > $1) OR (data->'author'->>'name' = $2 AND id > $3))"))
+			})
+
+			It("rejects paths that are not identifiers", func() {
+				p := pagetoken.NewKeysetPayloadBuilder().
+					AddString("name'--", "n", order.Asc).
+					Build()
+
+				_, _, err := sqlraw.NewBuilder(
+					sqlraw.WithColumnFn(sqlraw.JSONColumns("data", sqlraw.PostgresJSONPath)),
+				).KeysetWhere(p, stringValue)
+				Expect(err).To(MatchError(pagetoken.ErrInvalidPath))
+			})
+		})
+
+		It("propagates value errors", func() {
+			p := pagetoken.NewKeysetPayloadBuilder().
+				AddString("n", "not-a-number", order.Asc).
+				Build()
+
+			_, _, err := sqlraw.NewBuilder().KeysetWhere(p, func(column string, payload *pagetoken.KeysetPayload) (any, error) {
+				v, _, err := payload.Int(column)
+				return v, err
+			})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("UpperBoundWhere", func() {
+		It("returns an empty expression for a nil bound", func() {
+			where, args, err := sqlraw.NewBuilder().UpperBoundWhere(nil, stringValue)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(where).To(BeEmpty())
+			Expect(args).To(BeEmpty())
+		})
+
+		It("selects the rows at or before the bound", func() {
+			p := pagetoken.NewKeysetPayloadBuilder().AddString("id", "x", order.Asc).Build()
+
+			where, args, err := sqlraw.NewBuilder(
+				sqlraw.WithDialect(sqlraw.DialectPostgres),
+				sqlraw.WithArgOffset(1),
+			).UpperBoundWhere(p, stringValue)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(where).To(Equal("(id <= $2)"))
+			Expect(args).To(Equal([]any{"x"}))
+		})
+	})
+
+	Describe("OrderBy", func() {
+		It("renders the spec in order", func() {
+			Expect(sqlraw.NewBuilder().OrderBy(pagetoken.SortSpec{
+				{Path: "created_at", Order: order.Desc},
+				{Path: "id", Order: order.Asc},
+			})).To(Equal("created_at DESC, id ASC"))
+		})
+
+		It("renders mapped columns", func() {
+			Expect(sqlraw.NewBuilder(
+				sqlraw.WithColumnFn(sqlraw.JSONColumns("data", sqlraw.PostgresJSONPath)),
+			).OrderBy(pagetoken.SortSpec{
+				{Path: "meta.author.name", Order: order.Desc},
+				{Path: "id", Order: order.Asc},
+			})).To(Equal("data->'meta'->'author'->>'name' DESC, id ASC"))
+		})
+	})
+})
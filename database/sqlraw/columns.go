@@ -0,0 +1,64 @@
+package sqlraw
+
+import (
+	"strings"
+
+	"github.com/pixlcrashr/go-pagetoken"
+)
+
+// ColumnFn renders the SQL expression a keyset path is compared and ordered
+// by, see WithColumnFn.
+type ColumnFn func(path string) string
+
+// JSONPathFn renders the expression reading the nested field segments of the
+// JSON document stored in column, e.g. PostgresJSONPath or SQLiteJSONPath.
+type JSONPathFn func(column string, segments []string) string
+
+// JSONColumns returns a ColumnFn that maps nested paths such as
+// "author.name" into the JSON document stored in column via fn. Paths
+// without pagetoken.PathSeparator are regular columns and are rendered
+// unchanged, so tiebreaks like "id" keep working.
+//
+// Example:
+//
+//	b := sqlraw.NewBuilder(
+//		sqlraw.WithDialect(sqlraw.DialectPostgres),
+//		sqlraw.WithColumnFn(sqlraw.JSONColumns("data", sqlraw.PostgresJSONPath)),
+//	)
+func JSONColumns(column string, fn JSONPathFn) ColumnFn {
+	return func(path string) string {
+		segments := pagetoken.SplitPath(path)
+		if len(segments) == 1 {
+			return path
+		}
+
+		return fn(column, segments)
+	}
+}
+
+// PostgresJSONPath renders a JSONB accessor chain whose last step yields
+// text, e.g. data->'author'->>'name' for the segments "author" and "name".
+// Sorting by a numeric field therefore needs a cast in an index expression
+// or a custom JSONPathFn.
+func PostgresJSONPath(column string, segments []string) string {
+	var sb strings.Builder
+	sb.WriteString(column)
+
+	for i, s := range segments {
+		if i == len(segments)-1 {
+			sb.WriteString("->>'")
+		} else {
+			sb.WriteString("->'")
+		}
+		sb.WriteString(s)
+		sb.WriteString("'")
+	}
+
+	return sb.String()
+}
+
+// SQLiteJSONPath renders a json_extract call, e.g.
+// json_extract(data, '$.author.name').
+func SQLiteJSONPath(column string, segments []string) string {
+	return "json_extract(" + column + ", '$." + strings.Join(segments, ".") + "')"
+}
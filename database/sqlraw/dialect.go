@@ -0,0 +1,52 @@
+package sqlraw
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pixlcrashr/go-pagetoken"
+)
+
+// QuoteIdentifier quotes the column path for dialect d: every segment of a
+// dotted path such as "books.id" is quoted on its own, as [books].[id] for
+// DialectSQLServer and "books"."id" for DialectOracle, with the quote
+// characters inside escaped by doubling them. The other dialects leave
+// paths unquoted, as the builder always has.
+func QuoteIdentifier(d Dialect, path string) string {
+	var lq, rq string
+	switch d {
+	case DialectSQLServer:
+		lq, rq = "[", "]"
+	case DialectOracle:
+		lq, rq = `"`, `"`
+	default:
+		return path
+	}
+
+	segments := pagetoken.SplitPath(path)
+	for i, s := range segments {
+		segments[i] = lq + strings.ReplaceAll(s, rq, rq+rq) + rq
+	}
+
+	return strings.Join(segments, ".")
+}
+
+// Limit renders the clause restricting a query to its first n rows, to
+// follow the ORDER BY clause:
+//
+//	LIMIT 20
+//
+// SQL Server and Oracle have no LIMIT, their clause is
+//
+//	OFFSET 0 ROWS FETCH NEXT 20 ROWS ONLY
+//
+// which SQL Server only accepts after an ORDER BY clause. Keyset queries
+// always have one.
+func (b *Builder) Limit(n int) string {
+	switch b.dialect {
+	case DialectSQLServer, DialectOracle:
+		return "OFFSET 0 ROWS FETCH NEXT " + strconv.Itoa(n) + " ROWS ONLY"
+	default:
+		return "LIMIT " + strconv.Itoa(n)
+	}
+}
@@ -0,0 +1,106 @@
+package sqlraw_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/database/sqlraw"
+	"github.com/pixlcrashr/go-pagetoken/order"
+)
+
+var _ = Describe("Dialects", func() {
+	keyset := pagetoken.NewKeysetPayloadBuilder().
+		AddString("created_at", "t", order.Desc).
+		AddString("id", "x", order.Asc).
+		Build()
+
+	spec := pagetoken.SortSpec{
+		{Path: "created_at", Order: order.Desc},
+		{Path: "id", Order: order.Asc},
+	}
+
+	type golden struct {
+		where     string
+		inclusive string
+		orderBy   string
+		limit     string
+	}
+
+	DescribeTable("render the clauses of a keyset query",
+		func(d sqlraw.Dialect, want golden) {
+			b := sqlraw.NewBuilder(sqlraw.WithDialect(d))
+
+			where, args, err := b.KeysetWhere(keyset, stringValue)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(where).To(Equal(want.where))
+			Expect(args).To(Equal([]any{"t", "t", "x"}))
+
+			where, _, err = sqlraw.NewBuilder(sqlraw.WithDialect(d), sqlraw.WithInclusiveBoundary("id")).KeysetWhere(keyset, stringValue)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(where).To(Equal(want.inclusive))
+
+			Expect(b.OrderBy(spec)).To(Equal(want.orderBy))
+			Expect(b.Limit(20)).To(Equal(want.limit))
+		},
+		Entry("SQLite", sqlraw.DialectSQLite, golden{
+			where:     "((created_at < ?) OR (created_at = ? AND id > ?))",
+			inclusive: "(created_at <= ? AND NOT (created_at = ? AND id <= ?))",
+			orderBy:   "created_at DESC, id ASC",
+			limit:     "LIMIT 20",
+		}),
+		Entry("MySQL", sqlraw.DialectMySQL, golden{
+			where:     "((created_at < ?) OR (created_at = ? AND id > ?))",
+			inclusive: "(created_at <= ? AND NOT (created_at = ? AND id <= ?))",
+			orderBy:   "created_at DESC, id ASC",
+			limit:     "LIMIT 20",
+		}),
+		Entry("Postgres", sqlraw.DialectPostgres, golden{
+			where:     "((created_at < $1) OR (created_at = $2 AND id > $3))",
+			inclusive: "(created_at <= $1 AND NOT (created_at = $2 AND id <= $3))",
+			orderBy:   "created_at DESC, id ASC",
+			limit:     "LIMIT 20",
+		}),
+		Entry("SQL Server", sqlraw.DialectSQLServer, golden{
+			where:     "(([created_at] < @p1) OR ([created_at] = @p2 AND [id] > @p3))",
+			inclusive: "([created_at] <= @p1 AND NOT ([created_at] = @p2 AND [id] <= @p3))",
+			orderBy:   "[created_at] DESC, [id] ASC",
+			limit:     "OFFSET 0 ROWS FETCH NEXT 20 ROWS ONLY",
+		}),
+		Entry("Oracle", sqlraw.DialectOracle, golden{
+			where:     `(("created_at" < :1) OR ("created_at" = :2 AND "id" > :3))`,
+			inclusive: `("created_at" <= :1 AND NOT ("created_at" = :2 AND "id" <= :3))`,
+			orderBy:   `"created_at" DESC, "id" ASC`,
+			limit:     "OFFSET 0 ROWS FETCH NEXT 20 ROWS ONLY",
+		}),
+	)
+
+	It("numbers SQL Server placeholders after the offset", func() {
+		where, _, err := sqlraw.NewBuilder(
+			sqlraw.WithDialect(sqlraw.DialectSQLServer),
+			sqlraw.WithArgOffset(2),
+		).UpperBoundWhere(pagetoken.NewKeysetPayloadBuilder().AddString("id", "x", order.Asc).Build(), stringValue)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(where).To(Equal("([id] <= @p3)"))
+	})
+
+	DescribeTable("QuoteIdentifier",
+		func(d sqlraw.Dialect, path, want string) {
+			Expect(sqlraw.QuoteIdentifier(d, path)).To(Equal(want))
+		},
+		Entry("SQL Server", sqlraw.DialectSQLServer, "books.id", "[books].[id]"),
+		Entry("SQL Server escapes brackets", sqlraw.DialectSQLServer, "a]b", "[a]]b]"),
+		Entry("Oracle", sqlraw.DialectOracle, "books.id", `"books"."id"`),
+		Entry("Oracle escapes quotes", sqlraw.DialectOracle, `a"b`, `"a""b"`),
+		Entry("Postgres", sqlraw.DialectPostgres, "books.id", "books.id"),
+	)
+
+	It("does not quote the expressions of a column mapping", func() {
+		b := sqlraw.NewBuilder(
+			sqlraw.WithDialect(sqlraw.DialectSQLServer),
+			sqlraw.WithColumnFn(func(path string) string { return "JSON_VALUE(data, '$." + path + "')" }),
+		)
+
+		Expect(b.OrderBy(pagetoken.SortSpec{{Path: "name", Order: order.Asc}})).To(Equal("JSON_VALUE(data, '$.name') ASC"))
+	})
+})
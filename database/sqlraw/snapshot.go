@@ -0,0 +1,79 @@
+package sqlraw
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pixlcrashr/go-pagetoken"
+)
+
+// ErrSnapshotTooOld is returned when a token's snapshot time is older than
+// the configured maximum age. It wraps pagetoken.ErrTokenExpired, as the
+// token cannot be continued.
+var ErrSnapshotTooOld = fmt.Errorf("%w: snapshot is older than the maximum age", pagetoken.ErrTokenExpired)
+
+type snapshotConfig struct {
+	maxAge time.Duration
+	now    func() time.Time
+}
+
+type SnapshotOpt func(*snapshotConfig)
+
+// WithMaxSnapshotAge rejects snapshots older than d with ErrSnapshotTooOld.
+// It should match the lifetime of the page tokens and must stay below the
+// database's garbage collection window (gc.ttlseconds on CockroachDB). A
+// non-positive d disables the check, which is the default.
+func WithMaxSnapshotAge(d time.Duration) SnapshotOpt {
+	return func(c *snapshotConfig) {
+		c.maxAge = d
+	}
+}
+
+// WithSnapshotClock replaces time.Now as the reference for the maximum age.
+func WithSnapshotClock(now func() time.Time) SnapshotOpt {
+	return func(c *snapshotConfig) {
+		c.now = now
+	}
+}
+
+// Snapshot returns the snapshot time of token, if it carries one that is
+// within the maximum age.
+func Snapshot(token *pagetoken.KeysetToken, opts ...SnapshotOpt) (time.Time, bool, error) {
+	c := &snapshotConfig{now: time.Now}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	t, ok := token.SnapshotTime()
+	if !ok {
+		return time.Time{}, false, nil
+	}
+
+	if c.maxAge > 0 && c.now().Sub(t) > c.maxAge {
+		return time.Time{}, false, ErrSnapshotTooOld
+	}
+
+	return t, true, nil
+}
+
+// AsOfSystemTime renders the CockroachDB clause reading at t:
+//
+//	AS OF SYSTEM TIME '2024-01-01 12:00:00.123456'
+//
+// It goes directly after a table in the FROM clause, or after BEGIN or SET
+// TRANSACTION to apply to a whole read-only transaction.
+func AsOfSystemTime(t time.Time) string {
+	return "AS OF SYSTEM TIME '" + t.UTC().Format("2006-01-02 15:04:05.999999") + "'"
+}
+
+// SnapshotClause renders AsOfSystemTime for the snapshot time of token. It
+// returns an empty string if the token carries no snapshot, e.g. on the
+// first page, and ErrSnapshotTooOld if the snapshot exceeds the maximum age.
+func SnapshotClause(token *pagetoken.KeysetToken, opts ...SnapshotOpt) (string, error) {
+	t, ok, err := Snapshot(token, opts...)
+	if err != nil || !ok {
+		return "", err
+	}
+
+	return AsOfSystemTime(t), nil
+}
@@ -0,0 +1,42 @@
+package sqlraw_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/database/sqlraw"
+)
+
+var _ = Describe("Snapshot", func() {
+	ts := time.Date(2024, 1, 1, 12, 0, 0, 123456789, time.UTC)
+	clock := sqlraw.WithSnapshotClock(func() time.Time { return ts.Add(time.Minute) })
+
+	It("renders AS OF SYSTEM TIME in UTC with microsecond precision", func() {
+		Expect(sqlraw.AsOfSystemTime(ts.In(time.FixedZone("CET", 3600)))).
+			To(Equal("AS OF SYSTEM TIME '2024-01-01 12:00:00.123456'"))
+	})
+
+	It("renders no clause for a token without snapshot", func() {
+		clause, err := sqlraw.SnapshotClause(&pagetoken.KeysetToken{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(clause).To(BeEmpty())
+	})
+
+	It("renders the clause for a token with snapshot", func() {
+		token := (&pagetoken.KeysetToken{}).Next(pagetoken.WithSnapshotTime(ts))
+
+		clause, err := sqlraw.SnapshotClause(token, clock, sqlraw.WithMaxSnapshotAge(time.Hour))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(clause).To(Equal("AS OF SYSTEM TIME '2024-01-01 12:00:00.123456'"))
+	})
+
+	It("refuses a snapshot older than the maximum age", func() {
+		token := (&pagetoken.KeysetToken{}).Next(pagetoken.WithSnapshotTime(ts))
+
+		_, err := sqlraw.SnapshotClause(token, clock, sqlraw.WithMaxSnapshotAge(30*time.Second))
+		Expect(err).To(MatchError(sqlraw.ErrSnapshotTooOld))
+	})
+})
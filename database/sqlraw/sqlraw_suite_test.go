@@ -0,0 +1,13 @@
+package sqlraw_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestSqlraw(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Sqlraw Suite")
+}
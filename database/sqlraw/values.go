@@ -0,0 +1,25 @@
+package sqlraw
+
+import "github.com/pixlcrashr/go-pagetoken"
+
+// CollatedValue binds the original string of a value stored via
+// pagetoken.KeysetPayloadBuilder.AddCollated. The database applies its own
+// collation (ORDER BY name COLLATE "de-DE"), which must match the language
+// the value was collated with.
+func CollatedValue(column string, payload *pagetoken.KeysetPayload) (any, error) {
+	v, _, err := payload.CollatedString(column)
+	return v, err
+}
+
+// DateValue binds a value stored via pagetoken.KeysetPayloadBuilder.AddDate
+// as its "2006-01-02" string, so that the database compares it to a DATE
+// column as a date. Binding a time.Time instead makes drivers send a
+// timestamp, whose date depends on the time zone it is converted to.
+func DateValue(column string, payload *pagetoken.KeysetPayload) (any, error) {
+	v, _, err := payload.Date(column)
+	if err != nil {
+		return nil, err
+	}
+
+	return v.String(), nil
+}
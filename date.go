@@ -0,0 +1,38 @@
+package pagetoken
+
+import (
+	"fmt"
+	"time"
+)
+
+// Date is a calendar date without a time of day or time zone, as stored in
+// DATE columns. It is stored in a keyset via AddDate.
+type Date struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+// DateOf returns the date of t in t's location. Use it instead of passing
+// the midnight of a date through AddTime: a midnight converted to another
+// location, e.g. to UTC, falls on a neighboring day.
+func DateOf(t time.Time) Date {
+	y, m, d := t.Date()
+	return Date{Year: y, Month: m, Day: d}
+}
+
+// ParseDate parses an ISO 8601 date such as "2024-03-01".
+func ParseDate(s string) (Date, error) {
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		return Date{}, fmt.Errorf("invalid date %q: %w", s, err)
+	}
+
+	return DateOf(t), nil
+}
+
+// String returns the date in ISO 8601 form, e.g. "2024-03-01". Dates out of
+// range, such as February 30, are normalized like time.Date does.
+func (d Date) String() string {
+	return time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, time.UTC).Format(time.DateOnly)
+}
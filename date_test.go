@@ -0,0 +1,62 @@
+package pagetoken_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/order"
+)
+
+var _ = Describe("Dates", func() {
+	It("AddDate stores the ISO form", func() {
+		p := pagetoken.NewKeysetPayloadBuilder().AddDate("d", 2024, time.March, 1, order.Asc).Build()
+		Expect(p.Values()[0].Value).To(Equal("2024-03-01"))
+	})
+
+	It("AddDate normalizes dates out of range", func() {
+		p := pagetoken.NewKeysetPayloadBuilder().AddDate("d", 2023, time.February, 29, order.Asc).Build()
+		Expect(p.Values()[0].Value).To(Equal("2023-03-01"))
+	})
+
+	It("round-trips via Date", func() {
+		p := pagetoken.NewKeysetPayloadBuilder().AddDate("d", 812, time.December, 31, order.Desc).Build()
+
+		d, o, err := p.Date("d")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(d).To(Equal(pagetoken.Date{Year: 812, Month: time.December, Day: 31}))
+		Expect(o).To(Equal(order.Desc))
+	})
+
+	It("rejects values that are not dates", func() {
+		p := pagetoken.NewKeysetPayloadBuilder().
+			AddTime("d", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), order.Asc).
+			Build()
+
+		_, _, err := p.Date("d")
+		Expect(err).To(HaveOccurred())
+	})
+
+	// regression: the midnight of a date in a location west of UTC lies on
+	// the next day in UTC, so its timestamp does not name the DB date
+	It("DateOf takes the date in the location of the time", func() {
+		pst := time.FixedZone("PST", -8*60*60)
+		evening := time.Date(2024, time.March, 1, 20, 0, 0, 0, pst)
+
+		local := pagetoken.DateOf(evening)
+		p := pagetoken.NewKeysetPayloadBuilder().
+			AddDate("d", local.Year, local.Month, local.Day, order.Asc).
+			AddTime("t", evening.UTC(), order.Asc).
+			Build()
+
+		d, _, err := p.Date("d")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(d.String()).To(Equal("2024-03-01"))
+
+		t, _, err := p.Time("t")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pagetoken.DateOf(t).String()).To(Equal("2024-03-02"))
+	})
+})
@@ -0,0 +1,140 @@
+package pagetoken
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/pixlcrashr/go-pagetoken/encryption"
+)
+
+// WithDecodeCache makes the reader keep the last size page tokens it
+// decoded, keyed by the token string, so that repeated reads of the same
+// token, e.g. retries of clients, skip decryption and decoding. Only tokens
+// that decode successfully are cached. The checks of a read that depend on
+// the request, such as the checksum and the revocation check, run on every
+// read.
+//
+// The cache is dropped whenever the keys of an encryption.Generational
+// encryptor change, so that tokens of removed keys are rejected right away.
+// Cached tokens hold their keyset values in memory in plaintext. A
+// non-positive size disables the cache.
+func WithDecodeCache(size int) RequestReaderOpt {
+	return func(rr *RequestReader) {
+		rr.decodeCache = nil
+		if size > 0 {
+			rr.decodeCache = newDecodeCache(size)
+		}
+	}
+}
+
+// decodeCache is a least recently used cache of decoded page tokens. It is
+// safe for concurrent use.
+type decodeCache struct {
+	mu      sync.Mutex
+	size    int
+	gen     uint64
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type decodeCacheEntry struct {
+	token string
+	t     *KeysetToken
+}
+
+func newDecodeCache(size int) *decodeCache {
+	return &decodeCache{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[string]*list.Element, size),
+	}
+}
+
+// get returns a copy of the token decoded from s under the keys of
+// generation gen, if cached.
+func (c *decodeCache) get(s string, gen uint64) (*KeysetToken, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if gen != c.gen {
+		return nil, false
+	}
+
+	el, ok := c.entries[s]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+
+	return el.Value.(*decodeCacheEntry).t.clone(), true
+}
+
+// add caches a copy of the token t decoded from s under the keys of
+// generation gen, evicting the least recently used token if the cache is
+// full. A newer generation drops all cached tokens, tokens of an older one
+// are not cached.
+func (c *decodeCache) add(s string, gen uint64, t *KeysetToken) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch {
+	case gen < c.gen:
+		return
+	case gen > c.gen:
+		c.gen = gen
+		c.order.Init()
+		clear(c.entries)
+	}
+
+	if el, ok := c.entries[s]; ok {
+		c.order.MoveToFront(el)
+		return
+	}
+
+	c.entries[s] = c.order.PushFront(&decodeCacheEntry{token: s, t: t.clone()})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*decodeCacheEntry).token)
+	}
+}
+
+// generation returns the key generation of e, or 0 if its keys never
+// change.
+func generation(e encryption.Crypter) uint64 {
+	if g, ok := e.(encryption.Generational); ok {
+		return g.Generation()
+	}
+
+	return 0
+}
+
+// clone returns a copy of the decoded token c, which callers may modify
+// without affecting c. The keyset payloads are shared, as they are
+// immutable; the composite payload is copied, as its parts can be set. The
+// fields are copied one by one, as serialized must not be copied and the
+// results of a read, checksumMismatched and recovered, must not carry over.
+func (c *KeysetToken) clone() *KeysetToken {
+	return &KeysetToken{
+		checksum:      c.checksum,
+		e:             c.e,
+		payload:       c.payload,
+		totalCount:    c.totalCount,
+		hasTotalCount: c.hasTotalCount,
+		snapshot:      c.snapshot,
+		upstream:      c.upstream,
+		hasUpstream:   c.hasUpstream,
+		composite:     c.composite.clone(),
+		groups:        c.groups,
+		upperBound:    c.upperBound,
+		pageIndex:     c.pageIndex,
+		issuedAt:      c.issuedAt,
+		scope:         c.scope,
+		id:            c.id,
+		ids:           c.ids,
+		legacy:        c.legacy,
+		prefix:        c.prefix,
+		now:           c.now,
+		pageSize:      c.pageSize,
+	}
+}
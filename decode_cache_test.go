@@ -0,0 +1,261 @@
+package pagetoken_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/encryption"
+	"github.com/pixlcrashr/go-pagetoken/order"
+)
+
+// decryptCounter counts the decryptions of the crypter it wraps.
+type decryptCounter struct {
+	encryption.Crypter
+	calls atomic.Int64
+}
+
+func (c *decryptCounter) Decrypt(token string) ([]byte, error) {
+	c.calls.Add(1)
+	return c.Crypter.Decrypt(token)
+}
+
+var _ = Describe("WithDecodeCache", func() {
+	var e *decryptCounter
+
+	BeforeEach(func() {
+		key, err := encryption.Rand32ByteKey()
+		Expect(err).NotTo(HaveOccurred())
+		aead, err := encryption.NewAEADEncryptor(key)
+		Expect(err).NotTo(HaveOccurred())
+		e = &decryptCounter{Crypter: aead}
+	})
+
+	tokenAfter := func(rr *pagetoken.RequestReader, id int) string {
+		t, err := rr.Read(filterRequest{status: "active"})
+		Expect(err).NotTo(HaveOccurred())
+		s, err := rr.NextToken(t, pagetoken.NewKeysetPayloadBuilder().AddInt("id", id, order.Asc).Build())
+		Expect(err).NotTo(HaveOccurred())
+		return s
+	}
+
+	It("decrypts a repeated token once", func() {
+		rr := pagetoken.NewRequestReader(pagetoken.WithEncryptor(e), pagetoken.WithDecodeCache(8))
+		s := tokenAfter(rr, 42)
+
+		for range 3 {
+			t, err := rr.Read(filterRequest{status: "active", token: s})
+			Expect(err).NotTo(HaveOccurred())
+			id, _, err := t.Payload().Int("id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(id).To(Equal(42))
+		}
+
+		Expect(e.calls.Load()).To(BeEquivalentTo(1))
+	})
+
+	It("verifies the checksum of cached tokens", func() {
+		rr := pagetoken.NewRequestReader(pagetoken.WithEncryptor(e), pagetoken.WithDecodeCache(8))
+		s := tokenAfter(rr, 42)
+
+		_, err := rr.Read(filterRequest{status: "active", token: s})
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = rr.Read(filterRequest{status: "archived", token: s})
+		Expect(err).To(MatchError(pagetoken.ErrChecksumMismatch))
+	})
+
+	It("runs the revocation check for cached tokens", func() {
+		var revoked atomic.Bool
+		rr := pagetoken.NewRequestReader(
+			pagetoken.WithEncryptor(e),
+			pagetoken.WithDecodeCache(8),
+			pagetoken.WithRevocationCheck(func(context.Context, pagetoken.TokenInfo) error {
+				if revoked.Load() {
+					return errors.New("revoked")
+				}
+				return nil
+			}),
+		)
+		s := tokenAfter(rr, 42)
+
+		_, err := rr.Read(filterRequest{status: "active", token: s})
+		Expect(err).NotTo(HaveOccurred())
+
+		revoked.Store(true)
+		_, err = rr.Read(filterRequest{status: "active", token: s})
+		Expect(err).To(MatchError(pagetoken.ErrTokenRevoked))
+	})
+
+	It("does not cache invalid tokens", func() {
+		rr := pagetoken.NewRequestReader(pagetoken.WithEncryptor(e), pagetoken.WithDecodeCache(8))
+
+		for range 2 {
+			_, err := rr.Read(filterRequest{status: "active", token: "garbage"})
+			Expect(err).To(MatchError(pagetoken.ErrInvalidToken))
+		}
+
+		Expect(e.calls.Load()).To(BeEquivalentTo(2))
+	})
+
+	It("evicts the least recently used token", func() {
+		rr := pagetoken.NewRequestReader(pagetoken.WithEncryptor(e), pagetoken.WithDecodeCache(2))
+		a, b, c := tokenAfter(rr, 1), tokenAfter(rr, 2), tokenAfter(rr, 3)
+
+		for _, s := range []string{a, b, a, c, a, b} {
+			_, err := rr.Read(filterRequest{status: "active", token: s})
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		// a, b and c miss once each, b again after c evicted it
+		Expect(e.calls.Load()).To(BeEquivalentTo(4))
+	})
+
+	It("returns tokens that do not share state with the cache", func() {
+		rr := pagetoken.NewRequestReader(pagetoken.WithEncryptor(e), pagetoken.WithDecodeCache(8))
+		s := tokenAfter(rr, 42)
+
+		t, err := rr.Read(filterRequest{status: "active", token: s})
+		Expect(err).NotTo(HaveOccurred())
+		t.SetTotalCount(100)
+
+		t, err = rr.Read(filterRequest{status: "active", token: s})
+		Expect(err).NotTo(HaveOccurred())
+		_, ok := t.TotalCount()
+		Expect(ok).To(BeFalse())
+	})
+
+	It("returns composite payloads that do not share state with the cache", func() {
+		rr := pagetoken.NewRequestReader(pagetoken.WithEncryptor(e), pagetoken.WithDecodeCache(8))
+		c := pagetoken.NewCompositePayload()
+		c.Set("shard-1", pagetoken.NewKeysetPayloadBuilder().AddInt("id", 7, order.Asc).Build())
+
+		t, err := rr.Read(filterRequest{status: "active"})
+		Expect(err).NotTo(HaveOccurred())
+		s, err := t.Next(pagetoken.WithCompositePayload(c)).String()
+		Expect(err).NotTo(HaveOccurred())
+
+		t, err = rr.Read(filterRequest{status: "active", token: s})
+		Expect(err).NotTo(HaveOccurred())
+		t.CompositePayload().SetDone("shard-1")
+		t.CompositePayload().Set("shard-2", pagetoken.NewKeysetPayloadBuilder().AddInt("id", 9, order.Asc).Build())
+
+		t, err = rr.Read(filterRequest{status: "active", token: s})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(e.calls.Load()).To(BeEquivalentTo(1))
+		got := t.CompositePayload()
+		Expect(got.Names()).To(Equal([]string{"shard-1"}))
+		Expect(got.Done("shard-1")).To(BeFalse())
+		id, _, err := got.Get("shard-1").Int("id")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(id).To(Equal(7))
+	})
+
+	It("drops the cache when the keys of the encryptor change", func() {
+		oldKey, err := encryption.Rand32ByteKey()
+		Expect(err).NotTo(HaveOccurred())
+		newKey, err := encryption.Rand32ByteKey()
+		Expect(err).NotTo(HaveOccurred())
+
+		var mu sync.Mutex
+		keys := []encryption.Key{oldKey}
+		dc, err := encryption.NewDynamicCrypter(func() ([]encryption.Key, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			return keys, nil
+		}, 0)
+		Expect(err).NotTo(HaveOccurred())
+
+		rr := pagetoken.NewRequestReader(pagetoken.WithEncryptor(dc), pagetoken.WithDecodeCache(8))
+		s := tokenAfter(rr, 42)
+
+		_, err = rr.Read(filterRequest{status: "active", token: s})
+		Expect(err).NotTo(HaveOccurred())
+
+		mu.Lock()
+		keys = []encryption.Key{newKey}
+		mu.Unlock()
+		Expect(dc.Refresh()).To(Succeed())
+
+		_, err = rr.Read(filterRequest{status: "active", token: s})
+		Expect(err).To(MatchError(pagetoken.ErrInvalidToken))
+	})
+
+	It("is safe for concurrent use", func() {
+		rr := pagetoken.NewRequestReader(pagetoken.WithEncryptor(e), pagetoken.WithDecodeCache(4))
+		tokens := make([]string, 8)
+		for i := range tokens {
+			tokens[i] = tokenAfter(rr, i)
+		}
+
+		var wg sync.WaitGroup
+		for g := range 8 {
+			wg.Go(func() {
+				defer GinkgoRecover()
+				for i := range 100 {
+					id := (g + i) % len(tokens)
+					t, err := rr.Read(filterRequest{status: "active", token: tokens[id]})
+					Expect(err).NotTo(HaveOccurred())
+					v, _, err := t.Payload().Int("id")
+					Expect(err).NotTo(HaveOccurred())
+					Expect(v).To(Equal(id))
+				}
+			})
+		}
+		wg.Wait()
+	})
+})
+
+// BenchmarkRequestReaderRead reads tokens of which nine in ten are retries
+// of one of a few hot tokens and the rest are seen once, with and without a
+// decode cache.
+func BenchmarkRequestReaderRead(b *testing.B) {
+	key, err := encryption.Rand32ByteKey()
+	if err != nil {
+		b.Fatal(err)
+	}
+	e, err := encryption.NewAEADEncryptor(key)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	issuer := pagetoken.NewRequestReader(pagetoken.WithEncryptor(e))
+	first, err := issuer.Read(filterRequest{status: "active"})
+	if err != nil {
+		b.Fatal(err)
+	}
+	tokens := make([]string, 1000)
+	for i := range tokens {
+		tokens[i], err = issuer.NextToken(first, benchmarkPayload(3))
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	for _, size := range []int{0, 128} {
+		b.Run("cache="+strconv.Itoa(size), func(b *testing.B) {
+			rr := pagetoken.NewRequestReader(pagetoken.WithEncryptor(e), pagetoken.WithDecodeCache(size))
+
+			b.ReportAllocs()
+			i := 0
+			for b.Loop() {
+				s := tokens[i%10]
+				if i%10 == 0 {
+					s = tokens[10+(i/10)%(len(tokens)-10)]
+				}
+				i++
+
+				if _, err := rr.Read(filterRequest{status: "active", token: s}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
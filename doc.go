@@ -33,6 +33,7 @@
 //	    "github.com/pixlcrashr/go-pagetoken"
 //	    "github.com/pixlcrashr/go-pagetoken/checksum"
 //	    "github.com/pixlcrashr/go-pagetoken/encryption"
+//	    "github.com/pixlcrashr/go-pagetoken/order"
 //	)
 //
 //	// Define your request type
@@ -92,10 +93,12 @@
 //	    // Create next page token
 //	    if len(users) > 0 {
 //	        lastUser := users[len(users)-1]
-//	        nextCursor := cursor.Next(
-//	            pagetoken.Field("id", lastUser.ID, pagetoken.OrderAsc),
-//	            pagetoken.Field("created_at", lastUser.CreatedAt.Format(time.RFC3339), pagetoken.OrderDesc),
-//	        )
+//	        nextCursor := cursor.Next(pagetoken.WithKeysetPayload(
+//	            pagetoken.NewKeysetPayloadBuilder().
+//	                AddTime("created_at", lastUser.CreatedAt, order.Desc).
+//	                AddString("id", lastUser.ID, order.Asc).
+//	                Build(),
+//	        ))
 //
 //	        nextToken, err := nextCursor.String()
 //	        if err != nil {
@@ -106,27 +109,25 @@
 //	    }
 //	}
 //
-// # Example: Using Cursor Fields
+// # Example: Reading the Keyset
 //
-//	// Extract cursor fields for database queries
+//	// Extract the keyset values for database queries
 //	cursor, err := pagetoken.FromRequest(encryptor, req)
 //	if err != nil {
 //	    log.Fatal(err)
 //	}
 //
 //	// Check if this is a continuation request
-//	if len(cursor.Fields()) > 0 {
-//	    // Get specific field values
-//	    idField, err := cursor.Field("id")
+//	if payload := cursor.Payload(); len(payload.Values()) > 0 {
+//	    // Get specific values
+//	    lastID, o, err := payload.String("id")
 //	    if err == nil {
-//	        lastID := idField.Value
-//	        order := idField.Order
-//	        fmt.Printf("Continue from ID: %s (order: %v)\n", lastID, order)
+//	        fmt.Printf("Continue from ID: %s (order: %v)\n", lastID, o)
 //	    }
 //
-//	    // Iterate all fields
-//	    for _, field := range cursor.Fields() {
-//	        fmt.Printf("Field: %s = %s (order: %v)\n", field.Path, field.Value, field.Order)
+//	    // Iterate all values
+//	    for _, v := range payload.Values() {
+//	        fmt.Printf("Value: %s = %s (order: %v)\n", v.Path, v.Value, v.Order)
 //	    }
 //	}
 //
@@ -147,4 +148,12 @@
 // requests. For example, if a client requests page 1 with status=active, they
 // cannot use the returned token with status=inactive for page 2. The checksum
 // will detect this and reject the request.
+//
+// Endpoints that should rather start over from the first page use a reader
+// created with WithSoftChecksum, whose tokens report the restart through
+// KeysetToken.ChecksumMismatched.
+//
+// A request without checksum fields accepts the tokens of every request
+// without them, e.g. of another endpoint. Readers created with
+// WithRequireChecksumFields reject such requests with ErrNoChecksumFields.
 package pagetoken
@@ -0,0 +1,96 @@
+package pagetoken
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/pixlcrashr/go-pagetoken/encryption"
+)
+
+// RedactedValue replaces the keyset values of a TokenDump unless
+// WithUnredactedValues is given.
+const RedactedValue = "[redacted]"
+
+// RedactSensitive returns the replacement of the sensitive value v in
+// DumpToken output and logs: "sha256:" followed by the first 16 hex digits of
+// the SHA-256 hash of v. Equal values have equal hashes, so a value known to
+// support staff can be recognized without revealing the others.
+func RedactSensitive(v string) string {
+	h := sha256.Sum256([]byte(v))
+	return "sha256:" + hex.EncodeToString(h[:8])
+}
+
+// TokenDump is the decrypted content of a page token, for support and
+// debugging tools. It marshals to JSON.
+type TokenDump struct {
+	Checksum   uint32      `json:"checksum"`
+	Keyset     []DumpValue `json:"keyset"`
+	TotalCount *int64      `json:"total_count,omitempty"`
+	Snapshot   *time.Time  `json:"snapshot,omitempty"`
+}
+
+// DumpValue is a keyset value of a TokenDump.
+type DumpValue struct {
+	Path  string `json:"path"`
+	Order string `json:"order"`
+	Value string `json:"value"`
+	// Sensitive is set for sensitive values, whose Value is always replaced
+	// by RedactSensitive.
+	Sensitive bool `json:"sensitive,omitempty"`
+}
+
+type dumpConfig struct {
+	unredacted bool
+}
+
+type DumpOpt func(*dumpConfig)
+
+// WithUnredactedValues makes DumpToken include the keyset values, which may
+// hold user data such as names or e-mail addresses. By default they are
+// replaced by RedactedValue. Sensitive values are replaced by
+// RedactSensitive either way.
+func WithUnredactedValues() DumpOpt {
+	return func(c *dumpConfig) {
+		c.unredacted = true
+	}
+}
+
+// DumpToken decrypts token with e and returns its content. Tokens that
+// cannot be decrypted or decoded are reported with an error wrapping
+// ErrInvalidToken. The checksum is returned as is, not verified.
+func DumpToken(e encryption.Crypter, token string, opts ...DumpOpt) (*TokenDump, error) {
+	cfg := &dumpConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	t, err := NewKeysetTokenParser(WithKeysetTokenEncryptor(e)).Parse(token)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &TokenDump{
+		Checksum: t.Checksum(),
+		Keyset:   []DumpValue{},
+	}
+	for _, v := range t.Payload().Values() {
+		dv := DumpValue{Path: v.Path, Order: v.Order.String(), Value: RedactedValue}
+		switch {
+		case v.Sensitive:
+			dv.Value = RedactSensitive(v.Value)
+			dv.Sensitive = true
+		case cfg.unredacted:
+			dv.Value = v.Value
+		}
+		d.Keyset = append(d.Keyset, dv)
+	}
+	if n, ok := t.TotalCount(); ok {
+		d.TotalCount = &n
+	}
+	if s, ok := t.SnapshotTime(); ok {
+		d.Snapshot = &s
+	}
+
+	return d, nil
+}
@@ -0,0 +1,53 @@
+package pagetoken_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/encryption"
+	"github.com/pixlcrashr/go-pagetoken/order"
+)
+
+var _ = Describe("DumpToken", func() {
+	var (
+		e *encryption.AEADEncryptor
+		s string
+	)
+
+	BeforeEach(func() {
+		key, err := encryption.Rand32ByteKey()
+		Expect(err).NotTo(HaveOccurred())
+		e, err = encryption.NewAEADEncryptor(key)
+		Expect(err).NotTo(HaveOccurred())
+
+		s, err = pagetoken.NewKeysetToken(e,
+			pagetoken.WithKeysetPayload(pagetoken.NewKeysetPayloadBuilder().
+				AddString("email", "jane@example.com", order.Asc).
+				Build()),
+			pagetoken.WithTotalCount(7),
+		).String()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("redacts the keyset values by default", func() {
+		d, err := pagetoken.DumpToken(e, s)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(d.Keyset).To(Equal([]pagetoken.DumpValue{
+			{Path: "email", Order: "asc", Value: pagetoken.RedactedValue},
+		}))
+		Expect(d.TotalCount).To(HaveValue(Equal(int64(7))))
+		Expect(d.Snapshot).To(BeNil())
+	})
+
+	It("includes the keyset values on request", func() {
+		d, err := pagetoken.DumpToken(e, s, pagetoken.WithUnredactedValues())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(d.Keyset[0].Value).To(Equal("jane@example.com"))
+	})
+
+	It("rejects undecryptable tokens with ErrInvalidToken", func() {
+		_, err := pagetoken.DumpToken(e, "bm90LWEtdG9rZW4")
+		Expect(err).To(MatchError(pagetoken.ErrInvalidToken))
+	})
+})
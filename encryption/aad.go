@@ -0,0 +1,34 @@
+package encryption
+
+import "bytes"
+
+// AADCrypter is implemented by crypters that can authenticate associated
+// data along with a token, such as AEADEncryptor. The associated data is
+// not part of the token: a token only decrypts with the data it was
+// encrypted with, e.g. the tenant it was issued to.
+type AADCrypter interface {
+	EncryptWithAAD(d, aad []byte) (string, error)
+	DecryptWithAAD(token string, aad []byte) ([]byte, error)
+}
+
+// BoundCrypter is a Crypter that encrypts and decrypts all tokens with the
+// same associated data, see BindAAD.
+type BoundCrypter struct {
+	c   AADCrypter
+	aad []byte
+}
+
+// BindAAD returns a Crypter that encrypts and decrypts with c and aad as
+// associated data, e.g. to hand a crypter bound to the subject of a request
+// to code that only knows Crypter.
+func BindAAD(c AADCrypter, aad []byte) *BoundCrypter {
+	return &BoundCrypter{c: c, aad: bytes.Clone(aad)}
+}
+
+func (b *BoundCrypter) Encrypt(d []byte) (string, error) {
+	return b.c.EncryptWithAAD(d, b.aad)
+}
+
+func (b *BoundCrypter) Decrypt(token string) ([]byte, error) {
+	return b.c.DecryptWithAAD(token, b.aad)
+}
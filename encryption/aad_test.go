@@ -0,0 +1,107 @@
+package encryption_test
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/pixlcrashr/go-pagetoken/encryption"
+)
+
+var _ = Describe("Associated data", func() {
+	newSigned := func() encryption.AADCrypter {
+		c, err := encryption.NewSignedCrypter(randKey(encryption.MinSignKeySize), encryption.MustRandKey(32))
+		Expect(err).ToNot(HaveOccurred())
+		return c
+	}
+	newAEAD := func() encryption.AADCrypter {
+		e, err := encryption.NewAEADEncryptor(randKey(32))
+		Expect(err).ToNot(HaveOccurred())
+		return e
+	}
+	newDynamic := func() encryption.AADCrypter {
+		c, err := encryption.NewDynamicCrypter(func() ([]encryption.Key, error) {
+			return []encryption.Key{randKey(32)}, nil
+		}, 0)
+		Expect(err).ToNot(HaveOccurred())
+		return c
+	}
+
+	for _, crypter := range []struct {
+		name string
+		new  func() encryption.AADCrypter
+	}{
+		{"AEADEncryptor", newAEAD},
+		{"SignedCrypter", newSigned},
+		{"DynamicCrypter", newDynamic},
+	} {
+		Describe(crypter.name, func() {
+			var c encryption.AADCrypter
+
+			BeforeEach(func() {
+				c = crypter.new()
+			})
+
+			It("decrypts with the same associated data", func() {
+				token, err := c.EncryptWithAAD([]byte("payload"), []byte("tenant-a"))
+				Expect(err).ToNot(HaveOccurred())
+
+				d, err := c.DecryptWithAAD(token, []byte("tenant-a"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(d).To(Equal([]byte("payload")))
+			})
+
+			It("fails to authenticate other associated data", func() {
+				token, err := c.EncryptWithAAD([]byte("payload"), []byte("tenant-a"))
+				Expect(err).ToNot(HaveOccurred())
+
+				_, err = c.DecryptWithAAD(token, []byte("tenant-b"))
+				Expect(err).To(HaveOccurred())
+				_, err = c.DecryptWithAAD(token, nil)
+				Expect(err).To(HaveOccurred())
+			})
+
+			It("treats empty associated data as none", func() {
+				token, err := c.EncryptWithAAD([]byte("payload"), []byte{})
+				Expect(err).ToNot(HaveOccurred())
+
+				d, err := c.(encryption.Crypter).Decrypt(token)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(d).To(Equal([]byte("payload")))
+			})
+		})
+	}
+
+	Describe("BindAAD", func() {
+		It("encrypts and decrypts with the bound data", func() {
+			c := newAEAD()
+			aad := []byte("tenant-a")
+			b := encryption.BindAAD(c, aad)
+			aad[0] = 'x'
+
+			token, err := b.Encrypt([]byte("payload"))
+			Expect(err).ToNot(HaveOccurred())
+
+			d, err := c.DecryptWithAAD(token, []byte("tenant-a"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(d).To(Equal([]byte("payload")))
+
+			d, err = b.Decrypt(token)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(d).To(Equal([]byte("payload")))
+
+			_, err = encryption.BindAAD(c, []byte("tenant-b")).Decrypt(token)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("does not embed the data in the token", func() {
+			aad := bytes.Repeat([]byte("tenant-a"), 8)
+			token, err := encryption.BindAAD(newAEAD(), aad).Encrypt([]byte("payload"))
+			Expect(err).ToNot(HaveOccurred())
+
+			plain, err := encryption.BindAAD(newAEAD(), nil).Encrypt([]byte("payload"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(token).To(HaveLen(len(plain)))
+		})
+	})
+})
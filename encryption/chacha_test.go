@@ -0,0 +1,96 @@
+package encryption_test
+
+import (
+	"encoding/base64"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/pixlcrashr/go-pagetoken/encryption"
+)
+
+var _ = Describe("ChaCha20-Poly1305", func() {
+	for _, alg := range []struct {
+		name      string
+		new       func(key []byte) (*encryption.AEADEncryptor, error)
+		nonceSize int
+	}{
+		{"ChaCha20-Poly1305", encryption.NewChaCha20Poly1305Encryptor, 12},
+		{"XChaCha20-Poly1305", encryption.NewXChaCha20Poly1305Encryptor, 24},
+	} {
+		Describe(alg.name, func() {
+			var e *encryption.AEADEncryptor
+
+			BeforeEach(func() {
+				var err error
+				e, err = alg.new(randKey(32))
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("round-trips tokens", func() {
+				token, err := e.Encrypt([]byte("page 2"))
+				Expect(err).ToNot(HaveOccurred())
+
+				d, err := e.Decrypt(token)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(d).To(Equal([]byte("page 2")))
+			})
+
+			It("lays tokens out as nonce || ciphertext || tag", func() {
+				token, err := e.Encrypt([]byte("page 2"))
+				Expect(err).ToNot(HaveOccurred())
+
+				raw, err := base64.RawURLEncoding.DecodeString(token)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(raw).To(HaveLen(alg.nonceSize + len("page 2") + 16))
+			})
+
+			It("rejects tampered tokens", func() {
+				token, err := e.Encrypt([]byte("page 2"))
+				Expect(err).ToNot(HaveOccurred())
+
+				raw, err := base64.RawURLEncoding.DecodeString(token)
+				Expect(err).ToNot(HaveOccurred())
+				raw[len(raw)-1] ^= 1
+
+				_, err = e.Decrypt(base64.RawURLEncoding.EncodeToString(raw))
+				Expect(err).To(HaveOccurred())
+			})
+
+			DescribeTable("rejects keys that are not 32 bytes",
+				func(size int) {
+					_, err := alg.new(make([]byte, size))
+					Expect(err).To(MatchError(ContainSubstring("must be 32 bytes")))
+				},
+				Entry("empty", 0),
+				Entry("16 bytes", 16),
+				Entry("33 bytes", 33),
+			)
+		})
+	}
+
+	It("does not decrypt tokens of another algorithm", func() {
+		key := randKey(32)
+		aes, err := encryption.NewAEADEncryptor(key)
+		Expect(err).ToNot(HaveOccurred())
+		chacha, err := encryption.NewChaCha20Poly1305Encryptor(key)
+		Expect(err).ToNot(HaveOccurred())
+		xchacha, err := encryption.NewXChaCha20Poly1305Encryptor(key)
+		Expect(err).ToNot(HaveOccurred())
+
+		crypters := []*encryption.AEADEncryptor{aes, chacha, xchacha}
+		for i, from := range crypters {
+			token, err := from.Encrypt([]byte("page 2"))
+			Expect(err).ToNot(HaveOccurred())
+
+			for j, to := range crypters {
+				if i == j {
+					continue
+				}
+				Expect(func() {
+					_, err = to.Decrypt(token)
+				}).NotTo(Panic())
+				Expect(err).To(HaveOccurred(), "%d -> %d", i, j)
+			}
+		}
+	})
+})
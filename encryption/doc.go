@@ -7,7 +7,9 @@
 // # Features
 //
 //   - AES-GCM AEAD encryption (128, 192, or 256-bit keys)
-//   - ChaCha8 PRNG for nonce generation
+//   - ChaCha20-Poly1305 and XChaCha20-Poly1305 for platforms without AES
+//     instructions (256-bit keys)
+//   - Random nonces from crypto/rand
 //   - Base64 URL-safe encoding
 //   - Interface-based design for extensibility
 //   - Helper functions for secure key generation
@@ -21,6 +23,9 @@
 //	    Decrypt(token string) ([]byte, error)
 //	}
 //
+// Encrypt must not modify or retain the plaintext it is passed, as its
+// buffer is reused for the next token.
+//
 // # Example: Basic Usage
 //
 //	package main
@@ -164,7 +169,8 @@
 //   - Use 32-byte keys (AES-256) for production environments
 //   - Store keys securely using environment variables or secrets managers
 //   - Never hardcode encryption keys in source code
-//   - The ChaCha8 PRNG is suitable for nonce generation in GCM mode
+//   - Every token gets a fresh 96-bit nonce from crypto/rand, as a nonce
+//     repeated under the same key breaks GCM
 //   - Always use HTTPS to prevent token interception
 //   - Consider implementing token expiration for additional security
 //
@@ -176,4 +182,56 @@
 //
 // The nonce is prepended to the ciphertext, and the authentication tag is
 // appended by the GCM mode. This ensures both confidentiality and authenticity.
+//
+// NewChaCha20Poly1305Encryptor and NewXChaCha20Poly1305Encryptor return
+// encryptors with the same layout, with 12- and 24-byte nonces. Tokens of
+// one algorithm do not decrypt with another, even under the same key.
+//
+// # Signed Tokens
+//
+// With AEADEncryptor, whoever holds the key can both read and forge tokens.
+// SignedCrypter separates the two: it signs the plaintext with
+// HMAC-SHA256 under its own key before encrypting it,
+//
+//	base64(nonce || AES-GCM(plaintext || HMAC-SHA256(plaintext)) || authentication_tag)
+//
+// so that a leaked encryption key does not allow forging tokens:
+//
+//	c, err := encryption.NewSignedCrypter(signKey, encKey)
+//
+// Its Decrypt reports tokens it cannot decrypt with ErrDecryptionFailed and
+// tokens with a wrong signature with ErrInvalidSignature.
+//
+// SignerEncryptor only signs, leaving tokens readable, e.g. for staging
+// environments where developers inspect their keysets:
+//
+//	base64url(payload) + "." + base64url(HMAC-SHA256(payload))
+//
+// # Key Rotation
+//
+// DynamicCrypter reloads its keys from a KeyProvider periodically, so keys
+// can rotate without restarting the process. It encrypts with the first key
+// and decrypts with all of them:
+//
+//	c, err := encryption.NewDynamicCrypter(func() ([]encryption.Key, error) {
+//	    return loadKeys("/run/secrets/page-token-keys")
+//	}, time.Minute, encryption.WithRefreshErrorFn(func(err error) {
+//	    log.Printf("page token key refresh failed: %v", err)
+//	}))
+//
+// To rotate, prepend the new key to the provider's list, and drop the old
+// one once the tokens issued with it may expire.
+//
+// KeyRing tags every token with the ID of its key instead, so it decrypts
+// with the right key right away and reports tokens of removed keys with
+// ErrUnknownKeyID:
+//
+//	base64(len(id) || id || nonce || ciphertext || authentication_tag)
+//
+// Keys are managed explicitly:
+//
+//	ring := encryption.NewKeyRing()
+//	err := ring.AddKey("2024q4", newKey)
+//	err = ring.SetPrimary("2024q4")
+//	err = ring.RemoveKey("2024q3")
 package encryption
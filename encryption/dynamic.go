@@ -0,0 +1,211 @@
+package encryption
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrNoKeys is returned by NewDynamicCrypter and DynamicCrypter.Refresh if
+// the key provider returns no keys.
+var ErrNoKeys = errors.New("key provider returned no keys")
+
+// Key is an AES key of 16, 24 or 32 bytes, see NewAEADEncryptor.
+type Key []byte
+
+// KeyProvider returns the current keys of a DynamicCrypter, the one to
+// encrypt with first, e.g. read from a file kept up to date by a secrets
+// manager.
+type KeyProvider func() ([]Key, error)
+
+type dynamicCrypterConfig struct {
+	onRefreshError func(error)
+}
+
+type DynamicCrypterOpt func(*dynamicCrypterConfig)
+
+// WithRefreshErrorFn makes a DynamicCrypter report failed periodic
+// refreshes to fn, e.g. to log them or to fail health checks. The crypter
+// keeps using its previous keys in that case. fn is called from the refresh
+// goroutine.
+func WithRefreshErrorFn(fn func(error)) DynamicCrypterOpt {
+	return func(c *dynamicCrypterConfig) {
+		c.onRefreshError = fn
+	}
+}
+
+// DynamicCrypter encrypts page tokens with AES-GCM under keys that may
+// rotate while the process runs. It encrypts with the first key of its
+// provider and decrypts tokens of any of its keys, so tokens issued before a
+// rotation stay valid until their key is dropped from the provider.
+//
+// The keys are swapped atomically, so DynamicCrypter is safe for concurrent
+// use by multiple goroutines, including during refreshes.
+type DynamicCrypter struct {
+	provider KeyProvider
+	cfg      dynamicCrypterConfig
+	keys     atomic.Pointer[dynamicKeys]
+
+	// mu serializes refreshes, so that the generation only changes along
+	// with the keys.
+	mu         sync.Mutex
+	generation atomic.Uint64
+
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// dynamicKeys is the set of keys a DynamicCrypter uses at a time.
+type dynamicKeys struct {
+	keys       []Key
+	encryptors []*AEADEncryptor
+}
+
+// equal reports whether r holds keys, in the same order.
+func (r *dynamicKeys) equal(keys []Key) bool {
+	if r == nil || len(r.keys) != len(keys) {
+		return false
+	}
+	for i := range keys {
+		if !bytes.Equal(r.keys[i], keys[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// NewDynamicCrypter returns a DynamicCrypter with the keys of provider,
+// which it reloads every refreshInterval until Close is called. A
+// non-positive refreshInterval disables periodic refreshes, see Refresh.
+// It fails if the initial keys cannot be loaded.
+func NewDynamicCrypter(provider KeyProvider, refreshInterval time.Duration, opts ...DynamicCrypterOpt) (*DynamicCrypter, error) {
+	c := &DynamicCrypter{
+		provider: provider,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(&c.cfg)
+	}
+
+	if err := c.Refresh(); err != nil {
+		return nil, err
+	}
+
+	if refreshInterval <= 0 {
+		close(c.done)
+		return c, nil
+	}
+
+	go c.run(refreshInterval)
+
+	return c, nil
+}
+
+func (c *DynamicCrypter) run(interval time.Duration) {
+	defer close(c.done)
+
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-t.C:
+			if err := c.Refresh(); err != nil && c.cfg.onRefreshError != nil {
+				c.cfg.onRefreshError(err)
+			}
+		}
+	}
+}
+
+// Refresh loads the keys of the provider and swaps them in, e.g. when
+// notified of a rotation between periodic refreshes. On failure, the
+// previous keys stay in use.
+func (c *DynamicCrypter) Refresh() error {
+	keys, err := c.provider()
+	if err != nil {
+		return fmt.Errorf("failed to load keys: %w", err)
+	}
+	if len(keys) == 0 {
+		return ErrNoKeys
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.keys.Load().equal(keys) {
+		return nil
+	}
+
+	ring := &dynamicKeys{
+		keys:       make([]Key, len(keys)),
+		encryptors: make([]*AEADEncryptor, len(keys)),
+	}
+	for i, k := range keys {
+		if ring.encryptors[i], err = NewAEADEncryptor(k); err != nil {
+			return fmt.Errorf("key %d: %w", i, err)
+		}
+		ring.keys[i] = bytes.Clone(k)
+	}
+
+	c.keys.Store(ring)
+	c.generation.Add(1)
+
+	return nil
+}
+
+// Generation implements Generational. It changes whenever a refresh loads
+// keys that differ from the previous ones.
+func (c *DynamicCrypter) Generation() uint64 {
+	return c.generation.Load()
+}
+
+// Close stops the periodic refreshes. The crypter remains usable with its
+// last keys.
+func (c *DynamicCrypter) Close() error {
+	c.stopOnce.Do(func() { close(c.stop) })
+	<-c.done
+
+	return nil
+}
+
+// Encrypt encrypts d with the first key, see AEADEncryptor.Encrypt.
+func (c *DynamicCrypter) Encrypt(d []byte) (string, error) {
+	return c.EncryptWithAAD(d, nil)
+}
+
+// EncryptWithAAD encrypts d with the first key, see
+// AEADEncryptor.EncryptWithAAD.
+func (c *DynamicCrypter) EncryptWithAAD(d, aad []byte) (string, error) {
+	return c.keys.Load().encryptors[0].EncryptWithAAD(d, aad)
+}
+
+// Decrypt decrypts a token returned by Encrypt with any of the current
+// keys. If none of them succeeds, the error is the one of the first key.
+func (c *DynamicCrypter) Decrypt(token string) ([]byte, error) {
+	return c.DecryptWithAAD(token, nil)
+}
+
+// DecryptWithAAD decrypts a token returned by EncryptWithAAD with any of
+// the current keys, see Decrypt.
+func (c *DynamicCrypter) DecryptWithAAD(token string, aad []byte) ([]byte, error) {
+	var first error
+	for _, e := range c.keys.Load().encryptors {
+		d, err := e.DecryptWithAAD(token, aad)
+		if err == nil {
+			return d, nil
+		}
+		if first == nil {
+			first = err
+		}
+	}
+
+	return nil, first
+}
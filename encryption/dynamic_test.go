@@ -0,0 +1,173 @@
+package encryption_test
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/pixlcrashr/go-pagetoken/encryption"
+)
+
+// keySource is a KeyProvider whose keys can be swapped by tests.
+type keySource struct {
+	mu   sync.Mutex
+	keys []encryption.Key
+	err  error
+}
+
+func (s *keySource) set(keys []encryption.Key, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys, s.err = keys, err
+}
+
+func (s *keySource) provide() ([]encryption.Key, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.keys, s.err
+}
+
+var _ = Describe("DynamicCrypter", func() {
+	var (
+		oldKey, newKey encryption.Key
+		src            *keySource
+	)
+
+	BeforeEach(func() {
+		var err error
+		oldKey, err = encryption.Rand32ByteKey()
+		Expect(err).ToNot(HaveOccurred())
+		newKey, err = encryption.Rand32ByteKey()
+		Expect(err).ToNot(HaveOccurred())
+
+		src = &keySource{keys: []encryption.Key{oldKey}}
+	})
+
+	newCrypter := func(interval time.Duration, opts ...encryption.DynamicCrypterOpt) *encryption.DynamicCrypter {
+		c, err := encryption.NewDynamicCrypter(src.provide, interval, opts...)
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(c.Close)
+		return c
+	}
+
+	encrypt := func(c encryption.Crypter) string {
+		token, err := c.Encrypt([]byte("payload"))
+		Expect(err).ToNot(HaveOccurred())
+		return token
+	}
+
+	It("should encrypt from and decrypt to the same value", func() {
+		c := newCrypter(0)
+
+		out, err := c.Decrypt(encrypt(c))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out).To(Equal([]byte("payload")))
+	})
+
+	It("should change its generation only along with its keys", func() {
+		c := newCrypter(0)
+		gen := c.Generation()
+
+		Expect(c.Refresh()).To(Succeed())
+		Expect(c.Generation()).To(Equal(gen))
+
+		src.set([]encryption.Key{newKey, oldKey}, nil)
+		Expect(c.Refresh()).To(Succeed())
+		Expect(c.Generation()).NotTo(Equal(gen))
+	})
+
+	It("should decrypt old tokens until their key is dropped", func() {
+		c := newCrypter(0)
+		oldToken := encrypt(c)
+
+		src.set([]encryption.Key{newKey, oldKey}, nil)
+		Expect(c.Refresh()).To(Succeed())
+
+		newToken := encrypt(c)
+		aead, err := encryption.NewAEADEncryptor(newKey)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = aead.Decrypt(newToken)
+		Expect(err).ToNot(HaveOccurred(), "new tokens must be encrypted with the first key")
+
+		_, err = c.Decrypt(oldToken)
+		Expect(err).ToNot(HaveOccurred())
+
+		src.set([]encryption.Key{newKey}, nil)
+		Expect(c.Refresh()).To(Succeed())
+
+		_, err = c.Decrypt(oldToken)
+		Expect(err).To(HaveOccurred())
+		_, err = c.Decrypt(newToken)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should refresh the keys periodically", func() {
+		c := newCrypter(time.Millisecond)
+		oldToken := encrypt(c)
+
+		src.set([]encryption.Key{newKey}, nil)
+
+		Eventually(func() error {
+			_, err := c.Decrypt(oldToken)
+			return err
+		}).Should(HaveOccurred())
+	})
+
+	It("should keep the previous keys and report failed refreshes", func() {
+		failures := make(chan error, 1)
+		c := newCrypter(time.Millisecond, encryption.WithRefreshErrorFn(func(err error) {
+			select {
+			case failures <- err:
+			default:
+			}
+		}))
+		token := encrypt(c)
+
+		errUnavailable := errors.New("secrets manager unavailable")
+		src.set(nil, errUnavailable)
+
+		Eventually(failures).Should(Receive(MatchError(errUnavailable)))
+		_, err := c.Decrypt(token)
+		Expect(err).ToNot(HaveOccurred())
+
+		src.set(nil, nil)
+		Expect(c.Refresh()).To(MatchError(encryption.ErrNoKeys))
+		src.set([]encryption.Key{[]byte("short")}, nil)
+		Expect(c.Refresh()).To(HaveOccurred())
+
+		_, err = c.Decrypt(token)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should fail without initial keys", func() {
+		src.set(nil, nil)
+
+		_, err := encryption.NewDynamicCrypter(src.provide, time.Minute)
+		Expect(err).To(MatchError(encryption.ErrNoKeys))
+	})
+
+	It("should be safe for concurrent use during refreshes", func() {
+		c := newCrypter(time.Millisecond)
+
+		var wg sync.WaitGroup
+		for i := range 4 {
+			wg.Go(func() {
+				defer GinkgoRecover()
+				for range 200 {
+					token := encrypt(c)
+					_, err := c.Decrypt(token)
+					Expect(err).ToNot(HaveOccurred())
+				}
+			})
+
+			if i%2 == 0 {
+				src.set([]encryption.Key{newKey, oldKey}, nil)
+			} else {
+				src.set([]encryption.Key{oldKey, newKey}, nil)
+			}
+		}
+		wg.Wait()
+	})
+})
@@ -7,6 +7,10 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"slices"
+
+	"github.com/pixlcrashr/go-pagetoken/internal/bufpool"
+	"github.com/pixlcrashr/go-pagetoken/internal/frozen"
 )
 
 func randKey(size int) ([]byte, error) {
@@ -27,6 +31,24 @@ func Rand32ByteKey() ([]byte, error) {
 	return randKey(32)
 }
 
+// MustRandKey returns a random AES key of size bytes, e.g. for keys
+// generated at init time. It panics if size is not 16, 24 or 32, or if the
+// system's random source fails.
+func MustRandKey(size int) []byte {
+	if size != 16 && size != 24 && size != 32 {
+		panic(fmt.Sprintf("encryption: invalid key size %d: must be 16, 24, or 32 bytes", size))
+	}
+
+	key, err := randKey(size)
+	if err != nil {
+		panic(fmt.Sprintf("encryption: failed to generate key: %v", err))
+	}
+
+	return key
+}
+
+// Encrypter encrypts token plaintexts. Encrypt must not modify d or retain
+// it after returning: callers reuse the buffer for the next token.
 type Encrypter interface {
 	Encrypt(d []byte) (string, error)
 }
@@ -40,8 +62,22 @@ type Crypter interface {
 	Decrypter
 }
 
+// Generational is implemented by crypters whose keys can change while they
+// are in use, such as DynamicCrypter. Generation returns a value that
+// changes whenever the keys do, so that callers caching decrypted tokens
+// know when to drop them.
+type Generational interface {
+	Generation() uint64
+}
+
+// AEADEncryptor encrypts page tokens with AES-GCM, or with
+// (X)ChaCha20-Poly1305, see NewChaCha20Poly1305Encryptor. It is safe for
+// concurrent use by multiple goroutines: it holds no state but the cipher,
+// and every call to Encrypt draws a fresh random nonce. Builds with the
+// pagetokendebug tag panic if the cipher is replaced while in use.
 type AEADEncryptor struct {
-	aead cipher.AEAD
+	aead        cipher.AEAD
+	fingerprint string
 }
 
 func NewAEADEncryptor(key []byte) (*AEADEncryptor, error) {
@@ -60,36 +96,128 @@ func NewAEADEncryptor(key []byte) (*AEADEncryptor, error) {
 		return nil, fmt.Errorf("failed to create GCM: %w", err)
 	}
 
-	return &AEADEncryptor{aead: aead}, nil
+	return newAEADEncryptor(aead), nil
+}
+
+func newAEADEncryptor(aead cipher.AEAD) *AEADEncryptor {
+	e := &AEADEncryptor{aead: aead}
+	if frozen.Enabled {
+		e.fingerprint = e.state()
+	}
+
+	return e
+}
+
+// state returns a fingerprint of e for frozen.Check.
+func (e *AEADEncryptor) state() string {
+	return fmt.Sprintf("%T(%p)", e.aead, e.aead)
+}
+
+func (e *AEADEncryptor) assertFrozen() {
+	if frozen.Enabled {
+		frozen.Check("AEADEncryptor", e.fingerprint, e.state())
+	}
 }
 
+// Encrypt encrypts d with a random nonce and returns it as unpadded URL-safe
+// base64.
 func (e *AEADEncryptor) Encrypt(d []byte) (string, error) {
-	nonce := make([]byte, e.aead.NonceSize())
-	if _, err := rand.Read(nonce); err != nil {
+	return e.EncryptWithAAD(d, nil)
+}
+
+// EncryptWithAAD is like Encrypt, but authenticates aad as the associated
+// data of the token, which is not part of the token itself. The token only
+// decrypts with DecryptWithAAD and the same aad; an empty aad is the same as
+// none.
+func (e *AEADEncryptor) EncryptWithAAD(d, aad []byte) (string, error) {
+	return e.encrypt(nil, d, aad)
+}
+
+// encrypt encrypts d with a random nonce and returns it preceded by header
+// as unpadded URL-safe base64.
+func (e *AEADEncryptor) encrypt(header, d, aad []byte) (string, error) {
+	e.assertFrozen()
+
+	// Layout: header || nonce || ciphertext || tag, sealed into a pooled
+	// scratch buffer and followed by its base64 encoding, so that only the
+	// returned string is allocated
+	hs, ns := len(header), e.aead.NonceSize()
+	n := hs + ns + len(d) + e.aead.Overhead()
+
+	scratch := bufpool.Get()
+	defer bufpool.Put(scratch)
+
+	buf := slices.Grow(*scratch, n+base64.RawURLEncoding.EncodedLen(n))[:hs+ns]
+	copy(buf, header)
+	if _, err := rand.Read(buf[hs:]); err != nil {
 		return "", fmt.Errorf("failed to generate nonce: %w", err)
 	}
 
-	// Encrypt and append nonce to ciphertext
-	// Layout: nonce || ciphertext || tag
-	ciphertext := e.aead.Seal(nonce, nonce, d, nil)
+	sealed := e.aead.Seal(buf, buf[hs:], d, aad)
+	buf = base64.RawURLEncoding.AppendEncode(sealed, sealed)
+	*scratch = buf
+
+	return string(buf[n:]), nil
+}
+
+// decodings are the base64 variants Decrypt accepts, in the order they are
+// tried. Encrypt emits the first one; the others cover tokens issued with
+// padding and tokens re-encoded by clients or proxies.
+var decodings = []*base64.Encoding{
+	base64.RawURLEncoding,
+	base64.URLEncoding,
+	base64.RawStdEncoding,
+	base64.StdEncoding,
+}
 
-	return base64.URLEncoding.EncodeToString(ciphertext), nil
+// decodeToken decodes token with the first of decodings that accepts it.
+// The error is the one of the unpadded URL-safe variant Encrypt emits.
+func decodeToken(token string) ([]byte, error) {
+	var first error
+	for _, enc := range decodings {
+		d, err := enc.DecodeString(token)
+		if err == nil {
+			return d, nil
+		}
+		if first == nil {
+			first = err
+		}
+	}
+
+	return nil, first
 }
 
+// Decrypt decrypts a token returned by Encrypt. Besides the unpadded
+// URL-safe base64 Encrypt emits, it accepts the padded and the standard
+// alphabet variants, so tokens survive being re-encoded on their way back.
 func (e *AEADEncryptor) Decrypt(token string) ([]byte, error) {
-	ciphertext, err := base64.URLEncoding.DecodeString(token)
+	return e.DecryptWithAAD(token, nil)
+}
+
+// DecryptWithAAD decrypts a token returned by EncryptWithAAD, see Decrypt.
+// It fails to authenticate tokens encrypted with other associated data than
+// aad.
+func (e *AEADEncryptor) DecryptWithAAD(token string, aad []byte) ([]byte, error) {
+	ciphertext, err := decodeToken(token)
 	if err != nil {
 		return nil, fmt.Errorf("failed to decode token: %w", err)
 	}
 
+	return e.open(ciphertext, aad)
+}
+
+// open decrypts the decoded token ciphertext in place.
+func (e *AEADEncryptor) open(ciphertext, aad []byte) ([]byte, error) {
+	e.assertFrozen()
+
 	nonceSize := e.aead.NonceSize()
 	if len(ciphertext) < nonceSize {
 		return nil, errors.New("ciphertext too short")
 	}
 
-	// Extract nonce and decrypt
+	// Extract nonce and decrypt in place, the decoded buffer is ours
 	nonce, ciphertext := ciphertext[:nonceSize], ciphertext[nonceSize:]
-	plaintext, err := e.aead.Open(nil, nonce, ciphertext, nil)
+	plaintext, err := e.aead.Open(ciphertext[:0], nonce, ciphertext, aad)
 	if err != nil {
 		return nil, fmt.Errorf("failed to decrypt: %w", err)
 	}
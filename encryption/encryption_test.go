@@ -1,8 +1,12 @@
 package encryption_test
 
 import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
 	"fmt"
 	"math/rand/v2"
+	"sync"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -19,6 +23,29 @@ func randKey(size int) []byte {
 
 var keySizes = []int{16, 24, 32}
 
+var _ = Describe("Key generation", func() {
+	DescribeTable("returns a fresh key on every call",
+		func(gen func() ([]byte, error), size int) {
+			a, err := gen()
+			Expect(err).ToNot(HaveOccurred())
+			b, err := gen()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(a).To(HaveLen(size))
+			Expect(b).To(HaveLen(size))
+			Expect(a).NotTo(Equal(b))
+		},
+		Entry("16 bytes", encryption.Rand16ByteKey, 16),
+		Entry("24 bytes", encryption.Rand24ByteKey, 24),
+		Entry("32 bytes", encryption.Rand32ByteKey, 32),
+		Entry("MustRandKey", func() ([]byte, error) { return encryption.MustRandKey(32), nil }, 32),
+	)
+
+	It("panics for invalid key sizes", func() {
+		Expect(func() { encryption.MustRandKey(20) }).To(PanicWith(ContainSubstring("invalid key size 20")))
+	})
+})
+
 var _ = Describe("Encryption", func() {
 
 	for _, keySize := range keySizes {
@@ -46,6 +73,105 @@ var _ = Describe("Encryption", func() {
 				Expect(err).ToNot(HaveOccurred())
 				Expect(out).To(Equal(in))
 			})
+
+			It("should emit unpadded URL-safe base64", func() {
+				for range 50 {
+					d, err := e.Encrypt([]byte("payload"))
+					Expect(err).ToNot(HaveOccurred())
+					Expect(d).To(MatchRegexp(`^[A-Za-z0-9_-]+$`))
+				}
+			})
+
+			It("should encrypt the same plaintext to different tokens", func() {
+				a, err := e.Encrypt([]byte("payload"))
+				Expect(err).ToNot(HaveOccurred())
+				b, err := e.Encrypt([]byte("payload"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(a).NotTo(Equal(b))
+			})
+
+			It("should never repeat a nonce", func() {
+				seen := make(map[string]struct{}, 10000)
+				for range 10000 {
+					d, err := e.Encrypt([]byte("payload"))
+					Expect(err).ToNot(HaveOccurred())
+					ciphertext, err := base64.RawURLEncoding.DecodeString(d)
+					Expect(err).ToNot(HaveOccurred())
+
+					nonce := string(ciphertext[:12])
+					_, repeated := seen[nonce]
+					Expect(repeated).To(BeFalse())
+					seen[nonce] = struct{}{}
+				}
+			})
+
+			It("should decrypt tokens laid out as nonce, ciphertext and tag", func() {
+				block, err := aes.NewCipher(randKey(keySize))
+				Expect(err).ToNot(HaveOccurred())
+				aead, err := cipher.NewGCM(block)
+				Expect(err).ToNot(HaveOccurred())
+
+				nonce := make([]byte, aead.NonceSize())
+				nonce[0] = 1
+				token := base64.RawURLEncoding.EncodeToString(aead.Seal(nonce, nonce, []byte("payload"), nil))
+
+				out, err := e.Decrypt(token)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(out).To(Equal([]byte("payload")))
+			})
+
+			It("should decrypt the same ciphertext in all base64 variants", func() {
+				in := []byte("payload")
+				d, err := e.Encrypt(in)
+				Expect(err).ToNot(HaveOccurred())
+				ciphertext, err := base64.RawURLEncoding.DecodeString(d)
+				Expect(err).ToNot(HaveOccurred())
+
+				for _, enc := range []*base64.Encoding{
+					base64.RawURLEncoding,
+					base64.URLEncoding,
+					base64.RawStdEncoding,
+					base64.StdEncoding,
+				} {
+					out, err := e.Decrypt(enc.EncodeToString(ciphertext))
+					Expect(err).ToNot(HaveOccurred())
+					Expect(out).To(Equal(in))
+				}
+			})
+
+			It("should encrypt and decrypt concurrently", func() {
+				var wg sync.WaitGroup
+				for g := range 16 {
+					wg.Go(func() {
+						defer GinkgoRecover()
+
+						for i := range 100 {
+							in := []byte(fmt.Sprintf("goroutine %d payload %d", g, i))
+							d, err := e.Encrypt(in)
+							Expect(err).ToNot(HaveOccurred())
+							out, err := e.Decrypt(d)
+							Expect(err).ToNot(HaveOccurred())
+							Expect(out).To(Equal(in))
+						}
+					})
+				}
+				wg.Wait()
+			})
+
+			It("should fail cleanly on corrupted characters", func() {
+				d, err := e.Encrypt([]byte("payload"))
+				Expect(err).ToNot(HaveOccurred())
+
+				_, err = e.Decrypt(d[:5] + "*" + d[6:])
+				Expect(err).To(MatchError(ContainSubstring("failed to decode token")))
+
+				c := byte('A')
+				if d[5] == 'A' {
+					c = 'B'
+				}
+				_, err = e.Decrypt(d[:5] + string(c) + d[6:])
+				Expect(err).To(MatchError(ContainSubstring("failed to decrypt")))
+			})
 		})
 	}
 })
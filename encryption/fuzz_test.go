@@ -0,0 +1,51 @@
+package encryption_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/pixlcrashr/go-pagetoken/encryption"
+)
+
+// FuzzAEADDecrypt feeds arbitrary tokens to Decrypt, which must reject them
+// without panicking, and checks that every plaintext survives a round trip.
+func FuzzAEADDecrypt(f *testing.F) {
+	e, err := encryption.NewAEADEncryptor(bytes.Repeat([]byte{0x2a}, 32))
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	valid, err := e.Encrypt([]byte(`["id","42","asc","1234567890"]`))
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	f.Add(valid)
+	f.Add(valid[:len(valid)/2])
+	f.Add(valid + "==")
+	f.Add(strings.NewReplacer("-", "+", "_", "/").Replace(valid))
+	f.Add("")
+	f.Add("AAAA")
+
+	f.Fuzz(func(t *testing.T, token string) {
+		if d, err := e.Decrypt(token); err == nil && token != valid {
+			// only re-encodings of valid may decrypt
+			if want, _ := e.Decrypt(valid); !bytes.Equal(d, want) {
+				t.Fatalf("Decrypt accepted a forged token: %q", token)
+			}
+		}
+
+		s, err := e.Encrypt([]byte(token))
+		if err != nil {
+			t.Fatalf("Encrypt failed: %v", err)
+		}
+		d, err := e.Decrypt(s)
+		if err != nil {
+			t.Fatalf("Decrypt of an encrypted token failed: %v", err)
+		}
+		if string(d) != token {
+			t.Fatalf("plaintext changed on round trip: %q", token)
+		}
+	})
+}
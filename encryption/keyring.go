@@ -0,0 +1,171 @@
+package encryption
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	// ErrUnknownKeyID is returned by KeyRing.Decrypt for tokens encrypted
+	// with a key that is not registered, e.g. one removed after a rotation,
+	// and by KeyRing.SetPrimary and KeyRing.RemoveKey for unknown IDs.
+	ErrUnknownKeyID = errors.New("unknown key id")
+	// ErrNoPrimaryKey is returned by KeyRing.Encrypt if no primary key is
+	// set.
+	ErrNoPrimaryKey = errors.New("no primary key")
+)
+
+// MaxKeyIDLen is the maximum length of a key ID of a KeyRing. IDs are part
+// of every token, so they should be kept short, e.g. "2024q3".
+const MaxKeyIDLen = 255
+
+// KeyRing encrypts page tokens with AES-GCM under a primary key and
+// decrypts tokens of any of its keys, so that tokens issued before a
+// rotation stay valid until their key is removed. Every token starts with
+// the ID of its key, before the nonce, so decryption does not need to try
+// all keys:
+//
+//	ring := encryption.NewKeyRing()
+//	_ = ring.AddKey("2024q3", oldKey)
+//	_ = ring.AddKey("2024q4", newKey)
+//	_ = ring.SetPrimary("2024q4")
+//
+// The key ID is not encrypted. Tokens of a KeyRing are not compatible with
+// those of AEADEncryptor. KeyRing is safe for concurrent use by multiple
+// goroutines, including while keys are added or removed.
+type KeyRing struct {
+	mu         sync.RWMutex
+	keys       map[string]*AEADEncryptor
+	primary    string
+	generation atomic.Uint64
+}
+
+// NewKeyRing returns an empty KeyRing. Keys are registered with AddKey
+// and the primary one is chosen with SetPrimary.
+func NewKeyRing() *KeyRing {
+	return &KeyRing{keys: make(map[string]*AEADEncryptor)}
+}
+
+// AddKey registers key, which must be a valid AES key, see
+// NewAEADEncryptor, under id, replacing the key of the same ID, if any. The
+// first key added becomes the primary one.
+func (r *KeyRing) AddKey(id string, key []byte) error {
+	if len(id) == 0 || len(id) > MaxKeyIDLen {
+		return fmt.Errorf("invalid key id length %d: must be between 1 and %d bytes", len(id), MaxKeyIDLen)
+	}
+
+	e, err := NewAEADEncryptor(key)
+	if err != nil {
+		return fmt.Errorf("key %q: %w", id, err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.keys[id]; ok {
+		r.generation.Add(1)
+	}
+	r.keys[id] = e
+	if r.primary == "" {
+		r.primary = id
+	}
+
+	return nil
+}
+
+// SetPrimary makes the key of id the one tokens are encrypted with. It
+// returns an error wrapping ErrUnknownKeyID if no key of id is registered.
+func (r *KeyRing) SetPrimary(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.keys[id]; !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownKeyID, id)
+	}
+	r.primary = id
+
+	return nil
+}
+
+// RemoveKey removes the key of id, e.g. once all tokens issued with it
+// have expired, after which its tokens fail with ErrUnknownKeyID. The
+// primary key cannot be removed.
+func (r *KeyRing) RemoveKey(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.keys[id]; !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownKeyID, id)
+	}
+	if id == r.primary {
+		return fmt.Errorf("cannot remove primary key %q", id)
+	}
+
+	delete(r.keys, id)
+	r.generation.Add(1)
+
+	return nil
+}
+
+// Generation implements Generational. It changes whenever a key is
+// replaced or removed, but not when keys are added, as that does not
+// affect tokens that decrypted before.
+func (r *KeyRing) Generation() uint64 {
+	return r.generation.Load()
+}
+
+// Encrypt encrypts d with the primary key, see AEADEncryptor.Encrypt. It
+// returns ErrNoPrimaryKey if no key was added yet.
+func (r *KeyRing) Encrypt(d []byte) (string, error) {
+	return r.EncryptWithAAD(d, nil)
+}
+
+// EncryptWithAAD is like Encrypt, but authenticates aad as associated
+// data, see AEADEncryptor.EncryptWithAAD.
+func (r *KeyRing) EncryptWithAAD(d, aad []byte) (string, error) {
+	r.mu.RLock()
+	id, e := r.primary, r.keys[r.primary]
+	r.mu.RUnlock()
+
+	if e == nil {
+		return "", ErrNoPrimaryKey
+	}
+
+	// Layout: len(id) || id || nonce || ciphertext || tag
+	header := make([]byte, 0, 1+len(id))
+	header = append(append(header, byte(len(id))), id...)
+
+	return e.encrypt(header, d, aad)
+}
+
+// Decrypt decrypts a token returned by Encrypt with the key whose ID it
+// starts with, see AEADEncryptor.Decrypt. It returns an error wrapping
+// ErrUnknownKeyID if that key is not registered.
+func (r *KeyRing) Decrypt(token string) ([]byte, error) {
+	return r.DecryptWithAAD(token, nil)
+}
+
+// DecryptWithAAD decrypts a token returned by EncryptWithAAD, see Decrypt.
+func (r *KeyRing) DecryptWithAAD(token string, aad []byte) ([]byte, error) {
+	d, err := decodeToken(token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode token: %w", err)
+	}
+
+	if len(d) == 0 || len(d) < 1+int(d[0]) {
+		return nil, errors.New("key id too short")
+	}
+	id, ciphertext := string(d[1:1+d[0]]), d[1+d[0]:]
+
+	r.mu.RLock()
+	e := r.keys[id]
+	r.mu.RUnlock()
+
+	if e == nil {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKeyID, id)
+	}
+
+	return e.open(ciphertext, aad)
+}
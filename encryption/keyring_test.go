@@ -0,0 +1,103 @@
+package encryption_test
+
+import (
+	"encoding/base64"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/pixlcrashr/go-pagetoken/encryption"
+)
+
+var _ = Describe("KeyRing", func() {
+	var ring *encryption.KeyRing
+
+	BeforeEach(func() {
+		ring = encryption.NewKeyRing()
+		Expect(ring.AddKey("a", encryption.MustRandKey(32))).To(Succeed())
+	})
+
+	It("decrypts tokens of the previous primary key after a rotation", func() {
+		old, err := ring.Encrypt([]byte("page 2"))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(ring.AddKey("b", encryption.MustRandKey(16))).To(Succeed())
+		Expect(ring.SetPrimary("b")).To(Succeed())
+
+		d, err := ring.Decrypt(old)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(d).To(Equal([]byte("page 2")))
+
+		next, err := ring.Encrypt([]byte("page 3"))
+		Expect(err).ToNot(HaveOccurred())
+		raw, err := base64.RawURLEncoding.DecodeString(next)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(raw[:2]).To(Equal([]byte{1, 'b'}))
+	})
+
+	It("rejects tokens of unknown keys with ErrUnknownKeyID", func() {
+		other := encryption.NewKeyRing()
+		Expect(other.AddKey("z", encryption.MustRandKey(32))).To(Succeed())
+		token, err := other.Encrypt([]byte("page 2"))
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = ring.Decrypt(token)
+		Expect(err).To(MatchError(encryption.ErrUnknownKeyID))
+		Expect(err).To(MatchError(ContainSubstring(`"z"`)))
+	})
+
+	It("rejects tokens of removed keys with ErrUnknownKeyID", func() {
+		token, err := ring.Encrypt([]byte("page 2"))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(ring.AddKey("b", encryption.MustRandKey(32))).To(Succeed())
+		Expect(ring.SetPrimary("b")).To(Succeed())
+		gen := ring.Generation()
+		Expect(ring.RemoveKey("a")).To(Succeed())
+		Expect(ring.Generation()).To(BeNumerically(">", gen))
+
+		_, err = ring.Decrypt(token)
+		Expect(err).To(MatchError(encryption.ErrUnknownKeyID))
+	})
+
+	It("rejects tokens encrypted with another key of the same ID", func() {
+		other := encryption.NewKeyRing()
+		Expect(other.AddKey("a", encryption.MustRandKey(32))).To(Succeed())
+		token, err := other.Encrypt([]byte("page 2"))
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = ring.Decrypt(token)
+		Expect(err).To(HaveOccurred())
+		Expect(err).NotTo(MatchError(encryption.ErrUnknownKeyID))
+	})
+
+	It("rejects truncated tokens", func() {
+		for _, raw := range [][]byte{{}, {5, 'a'}} {
+			_, err := ring.Decrypt(base64.RawURLEncoding.EncodeToString(raw))
+			Expect(err).To(HaveOccurred())
+		}
+	})
+
+	It("authenticates associated data", func() {
+		token, err := ring.EncryptWithAAD([]byte("page 2"), []byte("tenant-a"))
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = ring.DecryptWithAAD(token, []byte("tenant-b"))
+		Expect(err).To(HaveOccurred())
+		d, err := ring.DecryptWithAAD(token, []byte("tenant-a"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(d).To(Equal([]byte("page 2")))
+	})
+
+	It("validates keys and IDs", func() {
+		Expect(ring.AddKey("", encryption.MustRandKey(32))).NotTo(Succeed())
+		Expect(ring.AddKey(string(make([]byte, encryption.MaxKeyIDLen+1)), encryption.MustRandKey(32))).NotTo(Succeed())
+		Expect(ring.AddKey("b", []byte("short"))).NotTo(Succeed())
+		Expect(ring.SetPrimary("b")).To(MatchError(encryption.ErrUnknownKeyID))
+		Expect(ring.RemoveKey("a")).NotTo(Succeed())
+	})
+
+	It("fails to encrypt without keys", func() {
+		_, err := encryption.NewKeyRing().Encrypt([]byte("page 2"))
+		Expect(err).To(MatchError(encryption.ErrNoPrimaryKey))
+	})
+})
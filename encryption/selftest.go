@@ -0,0 +1,36 @@
+package encryption
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+// selfTestProbe is the plaintext SelfTest encrypts. It resembles a page
+// token plaintext, including bytes outside of ASCII.
+var selfTestProbe = []byte("\x01{\"k\":[\"created_at\",\"2024-01-01T00:00:00Z\",\"desc\",\"id\",\"42\",\"asc\"],\"c\":1234567890}\xff")
+
+// SelfTest checks that c works, e.g. at startup or in a readiness probe: it
+// encrypts a probe plaintext and decrypts it again. It returns an error
+// describing the first step that failed.
+func SelfTest(c Crypter) error {
+	if c == nil {
+		return errors.New("crypter is nil")
+	}
+
+	token, err := c.Encrypt(selfTestProbe)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt probe: %w", err)
+	}
+
+	d, err := c.Decrypt(token)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt probe: %w", err)
+	}
+
+	if !bytes.Equal(d, selfTestProbe) {
+		return errors.New("decrypted probe differs from the encrypted one")
+	}
+
+	return nil
+}
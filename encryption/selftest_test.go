@@ -0,0 +1,42 @@
+package encryption_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken/encryption"
+)
+
+// faultyCrypter fails to encrypt with err or, if err is nil, decrypts every
+// token to garbage.
+type faultyCrypter struct {
+	err error
+}
+
+func (c faultyCrypter) Encrypt([]byte) (string, error) { return "token", c.err }
+func (c faultyCrypter) Decrypt(string) ([]byte, error) { return []byte("garbage"), nil }
+
+var _ = Describe("SelfTest", func() {
+	It("passes for the AEAD encryptor", func() {
+		e, err := encryption.NewAEADEncryptor(randKey(32))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(encryption.SelfTest(e)).To(Succeed())
+	})
+
+	It("fails for a nil crypter", func() {
+		Expect(encryption.SelfTest(nil)).To(MatchError("crypter is nil"))
+	})
+
+	It("fails with the encryption error", func() {
+		errKeySize := errors.New("invalid key size")
+		err := encryption.SelfTest(faultyCrypter{err: errKeySize})
+		Expect(err).To(MatchError(errKeySize))
+		Expect(err).To(MatchError(ContainSubstring("failed to encrypt probe")))
+	})
+
+	It("fails if the probe does not round-trip", func() {
+		Expect(encryption.SelfTest(faultyCrypter{})).To(MatchError(ContainSubstring("differs")))
+	})
+})
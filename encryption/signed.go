@@ -0,0 +1,107 @@
+package encryption
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+
+	"github.com/pixlcrashr/go-pagetoken/internal/bufpool"
+)
+
+var (
+	// ErrInvalidSignature is returned by SignedCrypter.Decrypt for tokens
+	// that decrypt but were not signed with its signing key.
+	ErrInvalidSignature = errors.New("invalid token signature")
+	// ErrDecryptionFailed is returned by SignedCrypter.Decrypt for tokens
+	// that cannot be decoded or decrypted with its encryption key.
+	ErrDecryptionFailed = errors.New("token decryption failed")
+)
+
+// MinSignKeySize is the minimum size of the signing key of a SignedCrypter,
+// the output size of SHA-256.
+const MinSignKeySize = sha256.Size
+
+// SignedCrypter signs token plaintexts with HMAC-SHA256 before encrypting
+// them with AES-GCM, using independent keys for both, so that a leaked
+// encryption key discloses tokens but does not allow forging them. It is
+// safe for concurrent use by multiple goroutines.
+type SignedCrypter struct {
+	signKey []byte
+	aead    *AEADEncryptor
+}
+
+// NewSignedCrypter returns a SignedCrypter signing with signKey, which must
+// be at least MinSignKeySize bytes long, and encrypting with encKey, which
+// must be a valid AES key, see NewAEADEncryptor. The keys must differ.
+func NewSignedCrypter(signKey, encKey []byte) (*SignedCrypter, error) {
+	if len(signKey) < MinSignKeySize {
+		return nil, fmt.Errorf("invalid signing key size: must be at least %d bytes", MinSignKeySize)
+	}
+	if bytes.Equal(signKey, encKey) {
+		return nil, errors.New("signing and encryption keys must differ")
+	}
+
+	aead, err := NewAEADEncryptor(encKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SignedCrypter{signKey: bytes.Clone(signKey), aead: aead}, nil
+}
+
+// Encrypt appends the HMAC-SHA256 of d to d and encrypts the result, see
+// AEADEncryptor.Encrypt.
+func (c *SignedCrypter) Encrypt(d []byte) (string, error) {
+	return c.EncryptWithAAD(d, nil)
+}
+
+// EncryptWithAAD is like Encrypt, but authenticates aad as associated
+// data, see AEADEncryptor.EncryptWithAAD.
+func (c *SignedCrypter) EncryptWithAAD(d, aad []byte) (string, error) {
+	scratch := bufpool.Get()
+	defer bufpool.Put(scratch)
+
+	buf := append(*scratch, d...)
+	buf = c.sign(buf, d)
+	*scratch = buf
+
+	return c.aead.EncryptWithAAD(buf, aad)
+}
+
+// Decrypt decrypts a token returned by Encrypt and verifies its signature.
+// It returns an error wrapping ErrDecryptionFailed if the token cannot be
+// decrypted and one wrapping ErrInvalidSignature if its signature does not
+// match.
+func (c *SignedCrypter) Decrypt(token string) ([]byte, error) {
+	return c.DecryptWithAAD(token, nil)
+}
+
+// DecryptWithAAD decrypts a token returned by EncryptWithAAD, see Decrypt.
+// Tokens encrypted with other associated data than aad fail with an error
+// wrapping ErrDecryptionFailed.
+func (c *SignedCrypter) DecryptWithAAD(token string, aad []byte) ([]byte, error) {
+	d, err := c.aead.DecryptWithAAD(token, aad)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDecryptionFailed, err)
+	}
+
+	if len(d) < sha256.Size {
+		return nil, fmt.Errorf("%w: signature too short", ErrInvalidSignature)
+	}
+
+	plaintext, mac := d[:len(d)-sha256.Size], d[len(d)-sha256.Size:]
+	if !hmac.Equal(mac, c.sign(nil, plaintext)) {
+		return nil, ErrInvalidSignature
+	}
+
+	return plaintext, nil
+}
+
+// sign appends the HMAC-SHA256 of d to dst.
+func (c *SignedCrypter) sign(dst, d []byte) []byte {
+	h := hmac.New(sha256.New, c.signKey)
+	h.Write(d)
+	return h.Sum(dst)
+}
@@ -0,0 +1,115 @@
+package encryption_test
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/pixlcrashr/go-pagetoken/encryption"
+)
+
+var _ = Describe("SignedCrypter", func() {
+	var signKey, encKey []byte
+
+	BeforeEach(func() {
+		var err error
+		signKey, err = encryption.Rand32ByteKey()
+		Expect(err).ToNot(HaveOccurred())
+		encKey, err = encryption.Rand32ByteKey()
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	newCrypter := func(signKey, encKey []byte) *encryption.SignedCrypter {
+		c, err := encryption.NewSignedCrypter(signKey, encKey)
+		Expect(err).ToNot(HaveOccurred())
+		return c
+	}
+
+	It("should encrypt from and decrypt to the same value", func() {
+		c := newCrypter(signKey, encKey)
+
+		in := []byte("payload")
+		token, err := c.Encrypt(in)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(in).To(Equal([]byte("payload")))
+
+		out, err := c.Decrypt(token)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out).To(Equal(in))
+	})
+
+	It("should append the signature to the plaintext before encrypting", func() {
+		token, err := newCrypter(signKey, encKey).Encrypt([]byte("payload"))
+		Expect(err).ToNot(HaveOccurred())
+
+		aead, err := encryption.NewAEADEncryptor(encKey)
+		Expect(err).ToNot(HaveOccurred())
+		out, err := aead.Decrypt(token)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out).To(HaveLen(len("payload") + 32))
+		Expect(out).ToNot(Equal([]byte("payload")))
+	})
+
+	It("should reject tokens signed with another signing key", func() {
+		otherSignKey, err := encryption.Rand32ByteKey()
+		Expect(err).ToNot(HaveOccurred())
+
+		token, err := newCrypter(otherSignKey, encKey).Encrypt([]byte("payload"))
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = newCrypter(signKey, encKey).Decrypt(token)
+		Expect(err).To(MatchError(encryption.ErrInvalidSignature))
+		Expect(err).ToNot(MatchError(encryption.ErrDecryptionFailed))
+	})
+
+	It("should reject tokens forged with a leaked encryption key", func() {
+		aead, err := encryption.NewAEADEncryptor(encKey)
+		Expect(err).ToNot(HaveOccurred())
+		token, err := aead.Encrypt(append([]byte("payload"), bytes.Repeat([]byte{0}, 32)...))
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = newCrypter(signKey, encKey).Decrypt(token)
+		Expect(err).To(MatchError(encryption.ErrInvalidSignature))
+	})
+
+	It("should reject tokens encrypted with another encryption key", func() {
+		otherEncKey, err := encryption.Rand32ByteKey()
+		Expect(err).ToNot(HaveOccurred())
+
+		token, err := newCrypter(signKey, otherEncKey).Encrypt([]byte("payload"))
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = newCrypter(signKey, encKey).Decrypt(token)
+		Expect(err).To(MatchError(encryption.ErrDecryptionFailed))
+		Expect(err).ToNot(MatchError(encryption.ErrInvalidSignature))
+	})
+
+	It("should reject tokens of a crypter with swapped keys", func() {
+		token, err := newCrypter(encKey, signKey).Encrypt([]byte("payload"))
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = newCrypter(signKey, encKey).Decrypt(token)
+		Expect(err).To(MatchError(encryption.ErrDecryptionFailed))
+	})
+
+	It("should reject truncated plaintexts as unsigned", func() {
+		aead, err := encryption.NewAEADEncryptor(encKey)
+		Expect(err).ToNot(HaveOccurred())
+		token, err := aead.Encrypt([]byte("short"))
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = newCrypter(signKey, encKey).Decrypt(token)
+		Expect(err).To(MatchError(encryption.ErrInvalidSignature))
+	})
+
+	It("should reject invalid keys", func() {
+		_, err := encryption.NewSignedCrypter(signKey[:16], encKey)
+		Expect(err).To(HaveOccurred())
+
+		_, err = encryption.NewSignedCrypter(signKey, encKey[:15])
+		Expect(err).To(HaveOccurred())
+
+		_, err = encryption.NewSignedCrypter(signKey, signKey)
+		Expect(err).To(HaveOccurred())
+	})
+})
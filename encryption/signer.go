@@ -0,0 +1,94 @@
+package encryption
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// SignerEncryptor signs page tokens with HMAC-SHA256 without encrypting
+// them, so that they cannot be forged but can be read by anyone, e.g. by
+// developers inspecting the keyset of a token in staging:
+//
+//	e, err := encryption.NewSignerEncryptor(signKey)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	reader := pagetoken.NewRequestReader(pagetoken.WithEncryptor(e))
+//
+// Tokens have the form
+//
+//	base64url(payload) + "." + base64url(HMAC-SHA256(payload))
+//
+// so the payload of a token can be decoded with
+//
+//	echo "${token%%.*}" | basenc --base64url -d
+//
+// Tokens disclose their keyset values and the checksum of their request,
+// so SignerEncryptor is not meant for production listings of sensitive
+// data. It does not support associated data, see AADCrypter. It is safe
+// for concurrent use by multiple goroutines.
+type SignerEncryptor struct {
+	key []byte
+}
+
+// NewSignerEncryptor returns a SignerEncryptor signing with key, which must
+// be at least MinSignKeySize bytes long.
+func NewSignerEncryptor(key []byte) (*SignerEncryptor, error) {
+	if len(key) < MinSignKeySize {
+		return nil, fmt.Errorf("invalid signing key size: must be at least %d bytes", MinSignKeySize)
+	}
+
+	return &SignerEncryptor{key: bytes.Clone(key)}, nil
+}
+
+// Encrypt returns d and its signature as unpadded URL-safe base64,
+// separated by a dot.
+func (e *SignerEncryptor) Encrypt(d []byte) (string, error) {
+	enc := base64.RawURLEncoding
+
+	buf := make([]byte, 0, enc.EncodedLen(len(d))+1+enc.EncodedLen(sha256.Size))
+	buf = enc.AppendEncode(buf, d)
+	buf = append(buf, '.')
+	buf = enc.AppendEncode(buf, e.sign(d))
+
+	return string(buf), nil
+}
+
+// Decrypt returns the payload of a token returned by Encrypt after
+// verifying its signature in constant time. All tokens it rejects, whether
+// their signature does not match or they are not of the form Encrypt
+// emits, fail with an error wrapping ErrInvalidSignature. Like
+// AEADEncryptor.Decrypt, it accepts padded and standard base64.
+func (e *SignerEncryptor) Decrypt(token string) ([]byte, error) {
+	payload, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, fmt.Errorf("%w: missing separator", ErrInvalidSignature)
+	}
+
+	d, err := decodeToken(payload)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to decode payload: %w", ErrInvalidSignature, err)
+	}
+
+	mac, err := decodeToken(sig)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to decode signature: %w", ErrInvalidSignature, err)
+	}
+
+	if !hmac.Equal(mac, e.sign(d)) {
+		return nil, ErrInvalidSignature
+	}
+
+	return d, nil
+}
+
+// sign returns the HMAC-SHA256 of d.
+func (e *SignerEncryptor) sign(d []byte) []byte {
+	h := hmac.New(sha256.New, e.key)
+	h.Write(d)
+	return h.Sum(nil)
+}
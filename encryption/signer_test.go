@@ -0,0 +1,96 @@
+package encryption_test
+
+import (
+	"encoding/base64"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/pixlcrashr/go-pagetoken/encryption"
+)
+
+var _ = Describe("SignerEncryptor", func() {
+	var e *encryption.SignerEncryptor
+
+	BeforeEach(func() {
+		var err error
+		e, err = encryption.NewSignerEncryptor(encryption.MustRandKey(32))
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should sign from and verify to the same value", func() {
+		token, err := e.Encrypt([]byte(`{"v":1}`))
+		Expect(err).ToNot(HaveOccurred())
+
+		out, err := e.Decrypt(token)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out).To(Equal([]byte(`{"v":1}`)))
+	})
+
+	It("should leave the payload readable", func() {
+		token, err := e.Encrypt([]byte(`{"v":1}`))
+		Expect(err).ToNot(HaveOccurred())
+
+		payload, _, ok := strings.Cut(token, ".")
+		Expect(ok).To(BeTrue())
+		d, err := base64.RawURLEncoding.DecodeString(payload)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(d).To(Equal([]byte(`{"v":1}`)))
+	})
+
+	It("should reject tampered payloads", func() {
+		token, err := e.Encrypt([]byte(`{"v":1}`))
+		Expect(err).ToNot(HaveOccurred())
+
+		_, sig, _ := strings.Cut(token, ".")
+		forged := base64.RawURLEncoding.EncodeToString([]byte(`{"v":2}`)) + "." + sig
+
+		_, err = e.Decrypt(forged)
+		Expect(err).To(MatchError(encryption.ErrInvalidSignature))
+	})
+
+	It("should reject tampered signatures", func() {
+		token, err := e.Encrypt([]byte(`{"v":1}`))
+		Expect(err).ToNot(HaveOccurred())
+
+		payload, sig, _ := strings.Cut(token, ".")
+		mac, err := base64.RawURLEncoding.DecodeString(sig)
+		Expect(err).ToNot(HaveOccurred())
+		mac[0] ^= 1
+
+		for _, forged := range []string{
+			payload + "." + base64.RawURLEncoding.EncodeToString(mac),
+			payload + "." + base64.RawURLEncoding.EncodeToString(mac[:16]),
+			payload + ".",
+		} {
+			_, err = e.Decrypt(forged)
+			Expect(err).To(MatchError(encryption.ErrInvalidSignature), forged)
+		}
+	})
+
+	It("should reject tokens signed with another key", func() {
+		other, err := encryption.NewSignerEncryptor(encryption.MustRandKey(32))
+		Expect(err).ToNot(HaveOccurred())
+		token, err := other.Encrypt([]byte(`{"v":1}`))
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = e.Decrypt(token)
+		Expect(err).To(MatchError(encryption.ErrInvalidSignature))
+	})
+
+	DescribeTable("should reject malformed tokens",
+		func(token string) {
+			_, err := e.Decrypt(token)
+			Expect(err).To(MatchError(encryption.ErrInvalidSignature))
+		},
+		Entry("empty", ""),
+		Entry("without separator", base64.RawURLEncoding.EncodeToString([]byte(`{"v":1}`))),
+		Entry("with an invalid payload", "!!.AAAA"),
+		Entry("with an extra separator", "e30.AAAA.AAAA"),
+	)
+
+	It("should reject short keys", func() {
+		_, err := encryption.NewSignerEncryptor(make([]byte, encryption.MinSignKeySize-1))
+		Expect(err).To(HaveOccurred())
+	})
+})
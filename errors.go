@@ -0,0 +1,85 @@
+package pagetoken
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var (
+	// ErrInvalidToken is returned when a page token cannot be decrypted or
+	// decoded, e.g. because it was tampered with, truncated or issued with a
+	// different key.
+	ErrInvalidToken = errors.New("invalid page token")
+	// ErrMalformedToken is returned along with ErrInvalidToken when a page
+	// token decrypts but its keyset is not a sequence of path, value and
+	// order triples, lacks the checksum, or its values fail the checks of
+	// WithStrictValues or WithMaxValueLen.
+	ErrMalformedToken = errors.New("malformed page token")
+	// ErrChecksumMismatch is returned when a page token was issued for a
+	// request with different parameters, e.g. after the client changed a
+	// filter between pages.
+	ErrChecksumMismatch = errors.New("page token checksum mismatch")
+	// ErrTokenExpired is returned when a page token, or the snapshot it
+	// pins, is older than the configured maximum age.
+	ErrTokenExpired = errors.New("page token expired")
+	// ErrScopeMismatch is returned when a page token was issued for another
+	// scope, such as another endpoint or method, than the request it is
+	// presented with, see Scoper. Tokens bound to another subject, see
+	// WithSubject, fail to decrypt and are rejected with ErrInvalidToken
+	// instead.
+	ErrScopeMismatch = errors.New("page token scope mismatch")
+	// ErrInvalidPageSize is returned when a request asks for a negative page
+	// size.
+	ErrInvalidPageSize = errors.New("invalid page size")
+	// ErrTokenRevoked is returned when the revocation check of a
+	// RequestReader rejects a page token.
+	ErrTokenRevoked = errors.New("page token revoked")
+	// ErrStaleToken is returned when the keyset of a page token does not
+	// have the columns the listing is currently sorted by, e.g. because the
+	// sort order of an endpoint changed since the token was issued.
+	ErrStaleToken = errors.New("page token stale")
+	// ErrNoChecksumFields is returned by a RequestReader created with
+	// WithRequireChecksumFields for a request without checksum fields. It
+	// is a configuration error of the server, not of the client.
+	ErrNoChecksumFields = errors.New("request has no checksum fields")
+)
+
+// ChecksumMismatchError is the error of a page token issued for a request
+// with other checksum fields than the one it is presented with. It
+// unwraps to ErrChecksumMismatch.
+type ChecksumMismatchError struct {
+	// Got is the checksum of the request the token is presented with.
+	Got uint32
+	// Want is the checksum of the request the token was issued for.
+	Want uint32
+}
+
+func (e *ChecksumMismatchError) Error() string {
+	return fmt.Sprintf("%v (got 0x%x but expected 0x%x)", ErrChecksumMismatch, e.Got, e.Want)
+}
+
+func (e *ChecksumMismatchError) Unwrap() error {
+	return ErrChecksumMismatch
+}
+
+// HTTPStatus maps an error returned by this package to the HTTP status code
+// an API should respond with: http.StatusBadRequest for errors caused by the
+// client's page token and http.StatusInternalServerError for all others. A
+// nil error maps to http.StatusOK.
+func HTTPStatus(err error) int {
+	switch {
+	case err == nil:
+		return http.StatusOK
+	case errors.Is(err, ErrInvalidToken),
+		errors.Is(err, ErrChecksumMismatch),
+		errors.Is(err, ErrTokenExpired),
+		errors.Is(err, ErrScopeMismatch),
+		errors.Is(err, ErrInvalidPageSize),
+		errors.Is(err, ErrTokenRevoked),
+		errors.Is(err, ErrStaleToken):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
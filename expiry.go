@@ -0,0 +1,80 @@
+package pagetoken
+
+import (
+	"fmt"
+	"time"
+)
+
+// maxClockSkew is how far in the future the issue time of a token may lie
+// when its age is checked, to tolerate clocks of servers sharing a key
+// drifting apart.
+const maxClockSkew = time.Minute
+
+// WithKeysetTokenMaxAge makes the parser reject tokens issued more than d
+// ago with an error wrapping ErrTokenExpired. Tokens that do not record when
+// they were issued, i.e. legacy tokens and versioned tokens issued before
+// tokens were stamped, are rejected as well, as their age is unknown, and
+// tokens issued more than a minute in the future with an error wrapping
+// ErrInvalidToken. A d of zero or less disables the checks, which is the
+// default.
+func WithKeysetTokenMaxAge(d time.Duration) KeysetTokenParserOpt {
+	return func(p *KeysetTokenParser) {
+		p.maxAge = d
+	}
+}
+
+// WithKeysetTokenClock sets the clock the parser checks the age of tokens
+// against, see WithKeysetTokenMaxAge. It defaults to time.Now.
+func WithKeysetTokenClock(now func() time.Time) KeysetTokenParserOpt {
+	return func(p *KeysetTokenParser) {
+		p.now = now
+	}
+}
+
+// WithMaxAge makes the reader reject page tokens issued more than d ago with
+// an error wrapping ErrTokenExpired, see WithKeysetTokenMaxAge. Tokens are
+// checked on every read, including those served from the decode cache and
+// those parsed by Middleware. Requests without a page token are not checked.
+// Combined with WithFallbackToFirstPage for ErrorKindExpired, expired tokens
+// restart the listing instead.
+func WithMaxAge(d time.Duration) RequestReaderOpt {
+	return func(rr *RequestReader) {
+		rr.maxAge = d
+	}
+}
+
+// WithClock sets the clock the reader checks the age of page tokens against,
+// see WithMaxAge, and stamps the tokens it issues with, including those
+// derived via Next from tokens it returned. It defaults to time.Now.
+func WithClock(now func() time.Time) RequestReaderOpt {
+	return func(rr *RequestReader) {
+		rr.now = now
+	}
+}
+
+// checkAge returns an error wrapping ErrTokenExpired if t was issued more
+// than maxAge before now, or does not record when it was issued, and one
+// wrapping ErrInvalidToken if t was issued more than maxClockSkew after now.
+func checkAge(t *KeysetToken, maxAge time.Duration, now func() time.Time) error {
+	if maxAge <= 0 {
+		return nil
+	}
+
+	issuedAt, ok := t.IssuedAt()
+	if !ok {
+		return fmt.Errorf("%w: token does not record when it was issued", ErrTokenExpired)
+	}
+
+	if now == nil {
+		now = time.Now
+	}
+	age := now().Sub(issuedAt)
+	if age < -maxClockSkew {
+		return fmt.Errorf("%w: issued %s in the future", ErrInvalidToken, (-age).Truncate(time.Second))
+	}
+	if age > maxAge {
+		return fmt.Errorf("%w: issued %s ago, maximum age is %s", ErrTokenExpired, age.Truncate(time.Second), maxAge)
+	}
+
+	return nil
+}
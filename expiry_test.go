@@ -0,0 +1,228 @@
+package pagetoken_test
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/encryption"
+	"github.com/pixlcrashr/go-pagetoken/pagetokentest"
+)
+
+var _ = Describe("Token expiry", func() {
+	var (
+		e     *encryption.AEADEncryptor
+		now   time.Time
+		clock func() time.Time
+	)
+
+	BeforeEach(func() {
+		key, err := encryption.Rand32ByteKey()
+		Expect(err).NotTo(HaveOccurred())
+		e, err = encryption.NewAEADEncryptor(key)
+		Expect(err).NotTo(HaveOccurred())
+
+		now = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+		clock = func() time.Time { return now }
+	})
+
+	// issue returns a token for the second page of filterRequest, stamped
+	// with the current time of the fake clock.
+	issue := func() string {
+		t, err := pagetoken.NewRequestReader(pagetoken.WithEncryptor(e)).Read(filterRequest{status: "active"})
+		Expect(err).NotTo(HaveOccurred())
+		s, err := pagetoken.NewKeysetToken(e,
+			pagetoken.WithChecksum(t.Checksum()),
+			pagetoken.WithIssueClock(clock),
+		).String()
+		Expect(err).NotTo(HaveOccurred())
+		return s
+	}
+
+	Describe("WithKeysetTokenMaxAge", func() {
+		parser := func(opts ...pagetoken.KeysetTokenParserOpt) *pagetoken.KeysetTokenParser {
+			return pagetoken.NewKeysetTokenParser(append([]pagetoken.KeysetTokenParserOpt{
+				pagetoken.WithKeysetTokenEncryptor(e),
+				pagetoken.WithKeysetTokenClock(clock),
+			}, opts...)...)
+		}
+
+		It("exposes the issue time of parsed tokens", func() {
+			s := issue()
+
+			t, err := parser().Parse(s)
+			Expect(err).NotTo(HaveOccurred())
+			issuedAt, ok := t.IssuedAt()
+			Expect(ok).To(BeTrue())
+			Expect(issuedAt).To(BeTemporally("==", now))
+		})
+
+		It("accepts tokens up to the maximum age", func() {
+			s := issue()
+			now = now.Add(time.Hour)
+
+			_, err := parser(pagetoken.WithKeysetTokenMaxAge(time.Hour)).Parse(s)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("rejects older tokens with ErrTokenExpired", func() {
+			s := issue()
+			now = now.Add(time.Hour + time.Second)
+
+			_, err := parser(pagetoken.WithKeysetTokenMaxAge(time.Hour)).Parse(s)
+			Expect(err).To(MatchError(pagetoken.ErrTokenExpired))
+			Expect(err).NotTo(MatchError(pagetoken.ErrInvalidToken))
+		})
+
+		It("does not check the age without a maximum age", func() {
+			s := issue()
+			now = now.AddDate(10, 0, 0)
+
+			_, err := parser().Parse(s)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		Describe("tokens without issue time", func() {
+			const legacy = `["id","42","asc","1"]`
+
+			parse := func(opts ...pagetoken.KeysetTokenParserOpt) error {
+				s, err := pagetokentest.StaticCrypter{}.Encrypt([]byte(legacy))
+				Expect(err).NotTo(HaveOccurred())
+				_, err = pagetoken.NewKeysetTokenParser(append([]pagetoken.KeysetTokenParserOpt{
+					pagetoken.WithKeysetTokenEncryptor(pagetokentest.StaticCrypter{}),
+				}, opts...)...).Parse(s)
+				return err
+			}
+
+			It("parses them without a maximum age", func() {
+				Expect(parse()).To(Succeed())
+			})
+
+			It("rejects them with a maximum age", func() {
+				Expect(parse(pagetoken.WithKeysetTokenMaxAge(time.Hour))).To(MatchError(pagetoken.ErrTokenExpired))
+			})
+		})
+	})
+
+	Describe("WithMaxAge", func() {
+		reader := func(opts ...pagetoken.RequestReaderOpt) *pagetoken.RequestReader {
+			return pagetoken.NewRequestReader(append([]pagetoken.RequestReaderOpt{
+				pagetoken.WithEncryptor(e),
+				pagetoken.WithClock(clock),
+				pagetoken.WithMaxAge(time.Hour),
+			}, opts...)...)
+		}
+
+		It("accepts fresh tokens", func() {
+			s := issue()
+			now = now.Add(30 * time.Minute)
+
+			_, err := reader().Read(filterRequest{status: "active", token: s})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("rejects expired tokens with a 400", func() {
+			s := issue()
+			now = now.Add(2 * time.Hour)
+
+			_, err := reader().Read(filterRequest{status: "active", token: s})
+			Expect(err).To(MatchError(pagetoken.ErrTokenExpired))
+			Expect(pagetoken.HTTPStatus(err)).To(Equal(http.StatusBadRequest))
+		})
+
+		It("does not fall back to the first page for expired tokens as invalid tokens", func() {
+			s := issue()
+			now = now.Add(2 * time.Hour)
+
+			_, err := reader(pagetoken.WithFallbackToFirstPage(pagetoken.ErrorKindInvalid)).Read(filterRequest{status: "active", token: s})
+			Expect(err).To(MatchError(pagetoken.ErrTokenExpired))
+		})
+
+		It("falls back to the first page for expired tokens with ErrorKindExpired", func() {
+			s := issue()
+			now = now.Add(2 * time.Hour)
+			rr := reader(pagetoken.WithFallbackToFirstPage(pagetoken.ErrorKindExpired))
+			req := filterRequest{status: "active", token: s}
+
+			t, err := rr.Read(req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(t.Recovered()).To(MatchError(pagetoken.ErrTokenExpired))
+			Expect(t.PageIndex()).To(BeZero())
+
+			parsed, err := pagetoken.NewKeysetTokenParser(pagetoken.WithKeysetTokenEncryptor(e)).Parse(s)
+			Expect(err).NotTo(HaveOccurred())
+			t, err = rr.ReadContext(pagetoken.NewContext(context.Background(), parsed), req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(t.Recovered()).To(MatchError(pagetoken.ErrTokenExpired))
+			Expect(t.PageIndex()).To(BeZero())
+		})
+
+		It("rejects tokens expiring while cached", func() {
+			s := issue()
+			rr := reader(pagetoken.WithDecodeCache(8))
+
+			_, err := rr.Read(filterRequest{status: "active", token: s})
+			Expect(err).NotTo(HaveOccurred())
+
+			now = now.Add(2 * time.Hour)
+			_, err = rr.Read(filterRequest{status: "active", token: s})
+			Expect(err).To(MatchError(pagetoken.ErrTokenExpired))
+		})
+
+		It("stamps the tokens it issues with its clock", func() {
+			rr := reader()
+			t, err := rr.Read(filterRequest{status: "active"})
+			Expect(err).NotTo(HaveOccurred())
+			s, err := t.Next().String()
+			Expect(err).NotTo(HaveOccurred())
+
+			now = now.Add(30 * time.Minute)
+			t, err = rr.Read(filterRequest{status: "active", token: s})
+			Expect(err).NotTo(HaveOccurred())
+			issuedAt, _ := t.IssuedAt()
+			Expect(issuedAt).To(BeTemporally("==", now.Add(-30*time.Minute)))
+
+			next, err := rr.NextToken(t, t.Payload())
+			Expect(err).NotTo(HaveOccurred())
+			parsed, err := pagetoken.NewKeysetTokenParser(pagetoken.WithKeysetTokenEncryptor(e)).Parse(next)
+			Expect(err).NotTo(HaveOccurred())
+			issuedAt, _ = parsed.IssuedAt()
+			Expect(issuedAt).To(BeTemporally("==", now))
+
+			s, err = t.Next().String()
+			Expect(err).NotTo(HaveOccurred())
+			parsed, err = pagetoken.NewKeysetTokenParser(pagetoken.WithKeysetTokenEncryptor(e)).Parse(s)
+			Expect(err).NotTo(HaveOccurred())
+			issuedAt, _ = parsed.IssuedAt()
+			Expect(issuedAt).To(BeTemporally("==", now))
+		})
+
+		It("tolerates tokens issued slightly in the future", func() {
+			s := issue()
+			now = now.Add(-30 * time.Second)
+
+			_, err := reader().Read(filterRequest{status: "active", token: s})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("rejects tokens issued further in the future as invalid", func() {
+			s := issue()
+			now = now.Add(-2 * time.Minute)
+
+			_, err := reader().Read(filterRequest{status: "active", token: s})
+			Expect(err).To(MatchError(pagetoken.ErrInvalidToken))
+			Expect(err).NotTo(MatchError(pagetoken.ErrTokenExpired))
+		})
+
+		It("accepts requests without a page token", func() {
+			now = now.AddDate(10, 0, 0)
+
+			_, err := reader().Read(filterRequest{status: "active"})
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,97 @@
+package pagetoken
+
+import "errors"
+
+// ErrorKind classifies the page token errors a RequestReader can recover
+// from, see WithFallbackToFirstPage.
+type ErrorKind uint8
+
+const (
+	// ErrorKindInvalid covers tokens failing with ErrInvalidToken, e.g.
+	// corrupt tokens, tokens issued with a retired key or tokens not
+	// matching the payload schema.
+	ErrorKindInvalid ErrorKind = iota
+	// ErrorKindChecksumMismatch covers tokens failing with
+	// ErrChecksumMismatch, see also WithSoftChecksum.
+	ErrorKindChecksumMismatch
+	// ErrorKindStale covers tokens failing with ErrStaleToken.
+	ErrorKindStale
+	// ErrorKindExpired covers tokens failing with ErrTokenExpired, see
+	// WithMaxAge.
+	ErrorKindExpired
+)
+
+func (k ErrorKind) String() string {
+	switch k {
+	case ErrorKindInvalid:
+		return "invalid"
+	case ErrorKindChecksumMismatch:
+		return "checksum mismatch"
+	case ErrorKindStale:
+		return "stale"
+	case ErrorKindExpired:
+		return "expired"
+	default:
+		return "unknown"
+	}
+}
+
+// WithFallbackToFirstPage makes Read and ReadContext restart the listing
+// instead of failing for page tokens with an error of one of kinds, e.g. for
+// infinite scroll feeds where a restart is preferable to an error. They
+// return the token of the first page of the request, whose Recovered
+// reports the error for handlers to log. Verify still fails.
+//
+// Tokens failing with ErrTokenRevoked or ErrScopeMismatch are never
+// recovered from, as they are rejected for security reasons. Tokens
+// rejected by Middleware do not reach the reader at all.
+func WithFallbackToFirstPage(kinds ...ErrorKind) RequestReaderOpt {
+	return func(rr *RequestReader) {
+		for _, k := range kinds {
+			rr.fallbackKinds |= 1 << k
+		}
+	}
+}
+
+// errorKindOf returns the kind of err and whether it can be recovered from.
+func errorKindOf(err error) (ErrorKind, bool) {
+	switch {
+	case errors.Is(err, ErrTokenRevoked), errors.Is(err, ErrScopeMismatch):
+		return 0, false
+	case errors.Is(err, ErrChecksumMismatch):
+		return ErrorKindChecksumMismatch, true
+	case errors.Is(err, ErrStaleToken):
+		return ErrorKindStale, true
+	case errors.Is(err, ErrTokenExpired):
+		return ErrorKindExpired, true
+	case errors.Is(err, ErrInvalidToken):
+		return ErrorKindInvalid, true
+	default:
+		return 0, false
+	}
+}
+
+// fallback returns the token of the first page of req if err is of a kind
+// configured by WithFallbackToFirstPage, and err otherwise.
+func (r *RequestReader) fallback(req Request, pageSize int, err error) (*KeysetToken, error) {
+	k, ok := errorKindOf(err)
+	if !ok || r.fallbackKinds&(1<<k) == 0 {
+		return nil, err
+	}
+
+	t, ferr := r.first(req, pageSize)
+	if ferr != nil {
+		return nil, ferr
+	}
+	t.recovered = err
+	t.checksumMismatched = k == ErrorKindChecksumMismatch
+
+	return t, nil
+}
+
+// Recovered returns the error of the page token of the request if a
+// RequestReader created with WithFallbackToFirstPage returned the token of
+// the first page instead of failing, and nil otherwise.
+func (c *KeysetToken) Recovered() error {
+	return c.recovered
+}
@@ -0,0 +1,165 @@
+package pagetoken_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/encryption"
+	"github.com/pixlcrashr/go-pagetoken/order"
+)
+
+var _ = Describe("WithFallbackToFirstPage", func() {
+	var e encryption.Crypter
+
+	BeforeEach(func() {
+		key, err := encryption.Rand32ByteKey()
+		Expect(err).NotTo(HaveOccurred())
+		e, err = encryption.NewAEADEncryptor(key)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	allKinds := []pagetoken.ErrorKind{
+		pagetoken.ErrorKindInvalid,
+		pagetoken.ErrorKindChecksumMismatch,
+		pagetoken.ErrorKindStale,
+		pagetoken.ErrorKindExpired,
+	}
+
+	// issue returns the token of the second page of a listing by status.
+	issue := func(rr *pagetoken.RequestReader, status string) string {
+		t, err := rr.Read(filterRequest{status: status})
+		Expect(err).NotTo(HaveOccurred())
+		s, err := rr.NextToken(t, pagetoken.NewKeysetPayloadBuilder().AddInt("id", 7, order.Asc).Build())
+		Expect(err).NotTo(HaveOccurred())
+		return s
+	}
+
+	type failure struct {
+		// opts configure the reader for the failure.
+		opts []pagetoken.RequestReaderOpt
+		// request returns the request failing with err.
+		request func(rr *pagetoken.RequestReader) filterRequest
+		err     error
+	}
+
+	failures := map[pagetoken.ErrorKind]failure{
+		pagetoken.ErrorKindInvalid: {
+			request: func(rr *pagetoken.RequestReader) filterRequest {
+				s := []byte(issue(rr, "active"))
+				s[len(s)/2] ^= 0x01
+				return filterRequest{status: "active", token: string(s)}
+			},
+			err: pagetoken.ErrInvalidToken,
+		},
+		pagetoken.ErrorKindChecksumMismatch: {
+			request: func(rr *pagetoken.RequestReader) filterRequest {
+				return filterRequest{status: "inactive", token: issue(rr, "active")}
+			},
+			err: pagetoken.ErrChecksumMismatch,
+		},
+		pagetoken.ErrorKindStale: {
+			opts: []pagetoken.RequestReaderOpt{pagetoken.WithExpectedKeysetColumns(func(pagetoken.Request) []string {
+				return []string{"created_at", "id"}
+			})},
+			request: func(rr *pagetoken.RequestReader) filterRequest {
+				return filterRequest{status: "active", token: issue(rr, "active")}
+			},
+			err: pagetoken.ErrStaleToken,
+		},
+		pagetoken.ErrorKindExpired: {
+			opts: []pagetoken.RequestReaderOpt{pagetoken.WithMaxAge(time.Hour)},
+			request: func(rr *pagetoken.RequestReader) filterRequest {
+				t, err := rr.Read(filterRequest{status: "active"})
+				Expect(err).NotTo(HaveOccurred())
+				s, err := t.Next(pagetoken.WithIssueClock(func() time.Time { return time.Now().Add(-2 * time.Hour) })).String()
+				Expect(err).NotTo(HaveOccurred())
+				return filterRequest{status: "active", token: s}
+			},
+			err: pagetoken.ErrTokenExpired,
+		},
+	}
+
+	for _, kind := range allKinds {
+		Describe(kind.String()+" tokens", func() {
+			f := failures[kind]
+
+			It("fail without the option", func() {
+				rr := pagetoken.NewRequestReader(append([]pagetoken.RequestReaderOpt{pagetoken.WithEncryptor(e)}, f.opts...)...)
+
+				_, err := rr.Read(f.request(rr))
+				Expect(err).To(MatchError(f.err))
+			})
+
+			It("fail if only other kinds are recovered from", func() {
+				others := []pagetoken.ErrorKind{}
+				for _, k := range allKinds {
+					if k != kind {
+						others = append(others, k)
+					}
+				}
+				rr := pagetoken.NewRequestReader(append([]pagetoken.RequestReaderOpt{
+					pagetoken.WithEncryptor(e),
+					pagetoken.WithFallbackToFirstPage(others...),
+				}, f.opts...)...)
+
+				_, err := rr.Read(f.request(rr))
+				Expect(err).To(MatchError(f.err))
+			})
+
+			It("restart from the first page with the option", func() {
+				rr := pagetoken.NewRequestReader(append([]pagetoken.RequestReaderOpt{
+					pagetoken.WithEncryptor(e),
+					pagetoken.WithFallbackToFirstPage(kind),
+				}, f.opts...)...)
+				req := f.request(rr)
+
+				t, err := rr.Read(req)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(t.Recovered()).To(MatchError(f.err))
+				Expect(t.ChecksumMismatched()).To(Equal(kind == pagetoken.ErrorKindChecksumMismatch))
+				Expect(t.PageIndex()).To(BeZero())
+				Expect(t.Payload().Values()).To(BeEmpty())
+
+				first, err := rr.Read(filterRequest{status: req.status})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(t.Checksum()).To(Equal(first.Checksum()))
+				Expect(first.Recovered()).To(BeNil())
+
+				Expect(rr.Verify(t, req)).To(Succeed())
+			})
+		})
+	}
+
+	It("never recovers from revoked tokens", func() {
+		revoked := errors.New("replayed")
+		for _, withFallback := range []bool{false, true} {
+			opts := []pagetoken.RequestReaderOpt{
+				pagetoken.WithEncryptor(e),
+				pagetoken.WithRevocationCheck(func(context.Context, pagetoken.TokenInfo) error { return revoked }),
+			}
+			if withFallback {
+				opts = append(opts, pagetoken.WithFallbackToFirstPage(allKinds...))
+			}
+			rr := pagetoken.NewRequestReader(opts...)
+
+			_, err := rr.Read(filterRequest{status: "active", token: issue(rr, "active")})
+			Expect(err).To(MatchError(pagetoken.ErrTokenRevoked))
+		}
+	})
+
+	It("never recovers from scope mismatches", func() {
+		rr := pagetoken.NewRequestReader(
+			pagetoken.WithEncryptor(e),
+			pagetoken.WithFallbackToFirstPage(allKinds...),
+		)
+		s := issue(rr, "active")
+
+		_, err := rr.Read(scopedRequest{filterRequest: filterRequest{status: "active", token: s}, scope: "ListArchivedBooks"})
+		Expect(err).To(MatchError(pagetoken.ErrScopeMismatch))
+	})
+})
@@ -0,0 +1,43 @@
+//go:build pagetokendebug
+
+package pagetoken_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/encryption"
+)
+
+var _ = Describe("Frozen configuration", func() {
+	var e *encryption.AEADEncryptor
+
+	BeforeEach(func() {
+		key, err := encryption.Rand32ByteKey()
+		Expect(err).NotTo(HaveOccurred())
+		e, err = encryption.NewAEADEncryptor(key)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("panics when a RequestReader in use is reconfigured", func() {
+		rr := pagetoken.NewRequestReader(pagetoken.WithEncryptor(e))
+		_, err := rr.Read(filterRequest{status: "active"})
+		Expect(err).NotTo(HaveOccurred())
+
+		pagetoken.WithTokenPrefix("pt2_")(rr)
+		Expect(func() { _, _ = rr.Read(filterRequest{status: "active"}) }).To(PanicWith(ContainSubstring("RequestReader was modified")))
+	})
+
+	It("panics when a KeysetTokenParser in use is reconfigured", func() {
+		s, err := pagetoken.NewKeysetToken(e).String()
+		Expect(err).NotTo(HaveOccurred())
+
+		p := pagetoken.NewKeysetTokenParser(pagetoken.WithKeysetTokenEncryptor(e))
+		_, err = p.Parse(s)
+		Expect(err).NotTo(HaveOccurred())
+
+		pagetoken.WithKeysetTokenEncryptor(encryption.Crypter(nil))(p)
+		Expect(func() { _, _ = p.Parse(s) }).To(PanicWith(ContainSubstring("KeysetTokenParser was modified")))
+	})
+})
@@ -0,0 +1,153 @@
+package pagetoken_test
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/order"
+	"github.com/pixlcrashr/go-pagetoken/pagetokentest"
+)
+
+// wireV1 mirrors the version 1 plaintext body without composite payloads,
+// to compare the encoding of the package with that of encoding/json.
+type wireV1 struct {
+	K []string `json:"k"`
+	C uint32   `json:"c"`
+	N *int64   `json:"n,omitempty"`
+	S *int64   `json:"s,omitempty"`
+	U *string  `json:"u,omitempty"`
+	I int      `json:"i,omitempty"`
+	T int64    `json:"t,omitempty"`
+}
+
+func flatten(vs []pagetoken.KeysetValue) []string {
+	ps := []string{}
+	for _, v := range vs {
+		ps = append(ps, v.Path, v.Value, v.Order.String())
+	}
+	return ps
+}
+
+var fuzzParser = pagetoken.NewKeysetTokenParser(
+	pagetoken.WithKeysetTokenEncryptor(pagetokentest.StaticCrypter{}),
+)
+
+// FuzzKeysetTokenParse feeds arbitrary plaintexts to the parser. Tokens it
+// accepts must serialize again and parse to an equivalent token.
+func FuzzKeysetTokenParse(f *testing.F) {
+	f.Add(`["created_at","2024-01-01T00:00:00Z","desc","id","42","asc","1234567890"]`)
+	f.Add("\x01" + `{"k":["id","42","asc"],"c":7,"n":250,"s":1704067200000000000,"u":"next","i":3,"t":1704067200}`)
+	f.Add("\x01" + `{"k":[],"c":1,"p":{"p":{"shard-1":["id","7","asc"]},"d":["shard-2"]}}`)
+	f.Add(`[]`)
+	f.Add(`null`)
+	f.Add(`["id","42","1"]`)
+	f.Add("\x01" + `{"k":["id","42"],"c":1}`)
+
+	f.Fuzz(func(t *testing.T, plaintext string) {
+		s, _ := pagetokentest.StaticCrypter{}.Encrypt([]byte(plaintext))
+
+		kt, err := fuzzParser.Parse(s)
+		if err != nil {
+			if !errors.Is(err, pagetoken.ErrInvalidToken) {
+				t.Fatalf("Parse returned %v, which does not wrap ErrInvalidToken", err)
+			}
+			return
+		}
+
+		var ref wireV1
+		if strings.HasPrefix(plaintext, "\x01") && json.Unmarshal([]byte(plaintext[1:]), &ref) == nil {
+			if got := flatten(kt.Payload().Values()); !slices.Equal(got, ref.K) && len(got)+len(ref.K) > 0 {
+				t.Fatalf("keyset %q differs from encoding/json: %q", got, ref.K)
+			}
+		}
+
+		s, err = kt.String()
+		if errors.Is(err, pagetoken.ErrUpstreamTokenTooLong) {
+			return
+		}
+		if err != nil {
+			t.Fatalf("String of a parsed token failed: %v", err)
+		}
+
+		again, err := fuzzParser.Parse(s)
+		if err != nil {
+			t.Fatalf("Parse of a serialized token failed: %v", err)
+		}
+		if !pagetokentest.Equivalent(kt, again) {
+			t.Fatalf("token changed on round trip: %q", plaintext)
+		}
+	})
+}
+
+// FuzzKeysetTokenRoundTrip builds tokens from random values, serializes and
+// parses them again.
+func FuzzKeysetTokenRoundTrip(f *testing.F) {
+	f.Add("id", "42", false, int64(250), int64(1704067200000000000), "", 3)
+	f.Add("name", "user <a>", true, int64(-1), int64(0), "next", 0)
+	f.Add("", "", false, int64(0), int64(-1), strings.Repeat("u", pagetoken.MaxUpstreamTokenLength+1), 1)
+
+	f.Fuzz(func(t *testing.T, path, value string, desc bool, total, snapshot int64, upstream string, pages int) {
+		// JSON replaces invalid UTF-8 with U+FFFD, so such strings cannot
+		// round trip by design
+		if !utf8.ValidString(path) || !utf8.ValidString(value) || !utf8.ValidString(upstream) {
+			t.Skip()
+		}
+
+		o := order.Asc
+		if desc {
+			o = order.Desc
+		}
+
+		kt := pagetoken.NewKeysetToken(pagetokentest.StaticCrypter{},
+			pagetoken.WithKeysetPayload(pagetokentest.Payload(
+				pagetoken.KeysetValue{Path: path, Value: value, Order: o},
+			)),
+			pagetoken.WithTotalCount(total),
+			pagetoken.WithSnapshotTime(time.Unix(0, snapshot).UTC()),
+			pagetoken.WithUpstreamToken(upstream),
+		)
+		for range pages % 8 {
+			kt = kt.Next()
+		}
+
+		s, err := kt.String()
+		if len(upstream) > pagetoken.MaxUpstreamTokenLength {
+			if !errors.Is(err, pagetoken.ErrUpstreamTokenTooLong) {
+				t.Fatalf("String returned %v for an upstream token of %d bytes", err, len(upstream))
+			}
+			return
+		}
+		if err != nil {
+			t.Fatalf("String failed: %v", err)
+		}
+
+		parsed := pagetokentest.ParseForTest(t, s)
+		if !pagetokentest.Equivalent(kt, parsed) {
+			t.Fatalf("token changed on round trip: %q", s)
+		}
+
+		at, _ := parsed.IssuedAt()
+		n, s2 := total, time.Unix(0, snapshot).UnixNano()
+		ref, err := json.Marshal(wireV1{
+			K: flatten(kt.Payload().Values()),
+			C: kt.Checksum(),
+			N: &n,
+			S: &s2,
+			U: &upstream,
+			I: kt.PageIndex(),
+			T: at.Unix(),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d, _ := (pagetokentest.StaticCrypter{}).Decrypt(s); string(d[1:]) != string(ref) {
+			t.Fatalf("plaintext %q differs from encoding/json: %q", d[1:], ref)
+		}
+	})
+}
@@ -0,0 +1,72 @@
+package pagetoken_test
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/encryption"
+	"github.com/pixlcrashr/go-pagetoken/internal/golden"
+	"github.com/pixlcrashr/go-pagetoken/pagetokentest"
+)
+
+var _ = Describe("Golden tokens", func() {
+	var fixtures []golden.Fixture
+
+	BeforeEach(func() {
+		var err error
+		fixtures, err = golden.Load("testdata/golden")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fixtures).NotTo(BeEmpty())
+	})
+
+	parse := func(e encryption.Crypter, token string) *pagetoken.KeysetToken {
+		t, err := pagetoken.NewKeysetTokenParser(pagetoken.WithKeysetTokenEncryptor(e)).Parse(token)
+		Expect(err).NotTo(HaveOccurred())
+		return t
+	}
+
+	It("keeps parsing the fixtures of every format version", func() {
+		for _, f := range fixtures {
+			By(fmt.Sprintf("parsing version %d", f.Version))
+			Expect(f.Check(parse(pagetokentest.StaticCrypter{}, f.Tokens.Static))).To(Succeed())
+			Expect(f.Check(parse(golden.Crypter(), f.Tokens.AEAD))).To(Succeed())
+		}
+	})
+
+	It("upgrades the tokens of every format version to the latest one", func() {
+		latest := fixtures[len(fixtures)-1]
+
+		for _, f := range fixtures {
+			By(fmt.Sprintf("upgrading version %d", f.Version))
+			t := parse(pagetokentest.StaticCrypter{}, f.Tokens.Static)
+			s, err := t.Next().String()
+			Expect(err).NotTo(HaveOccurred())
+
+			v, err := golden.PlaintextVersion(s)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(v).To(Equal(latest.Version))
+
+			next := parse(pagetokentest.StaticCrypter{}, s)
+			Expect(next.Checksum()).To(Equal(f.Checksum))
+			Expect(next.Payload().Values()).To(Equal(t.Payload().Values()))
+			Expect(next.PageIndex()).To(Equal(f.PageIndex + 1))
+		}
+	})
+
+	It("serializes tokens exactly like the fixture of the latest version", func() {
+		latest := fixtures[len(fixtures)-1]
+
+		t, err := latest.Token(pagetokentest.StaticCrypter{})
+		Expect(err).NotTo(HaveOccurred())
+		s, err := t.String()
+		Expect(err).NotTo(HaveOccurred())
+
+		v, err := golden.PlaintextVersion(s)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(v).To(Equal(latest.Version), "the format version changed, run go generate to add its fixture")
+		Expect(s).To(Equal(latest.Tokens.Static), "the wire format changed without a new format version")
+	})
+})
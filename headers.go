@@ -0,0 +1,118 @@
+package pagetoken
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// Defaults of HeaderConfig.
+const (
+	DefaultNextPageTokenHeader = "X-Next-Page-Token"
+	DefaultPrevPageTokenHeader = "X-Prev-Page-Token"
+	DefaultTotalCountHeader    = "X-Total-Count"
+)
+
+// HeaderConfig configures the headers written by WriteHeaders and read by
+// ReadHeaders. Empty names are replaced by the package defaults.
+type HeaderConfig struct {
+	// NextHeader is the header holding the next page token. The default is
+	// DefaultNextPageTokenHeader.
+	NextHeader string
+	// PrevHeader is the header holding the previous page token. The default
+	// is DefaultPrevPageTokenHeader.
+	PrevHeader string
+	// TotalHeader is the header holding the total number of items. The
+	// default is DefaultTotalCountHeader.
+	TotalHeader string
+	// LinkBaseURL, if set, makes WriteHeaders also add the Link header of
+	// LinkHeader, reusing the serialized tokens.
+	LinkBaseURL *url.URL
+	// TokenParam is the page token query parameter of the Link header. The
+	// default is DefaultPageTokenParam.
+	TokenParam string
+}
+
+func (c HeaderConfig) withDefaults() HeaderConfig {
+	if c.NextHeader == "" {
+		c.NextHeader = DefaultNextPageTokenHeader
+	}
+	if c.PrevHeader == "" {
+		c.PrevHeader = DefaultPrevPageTokenHeader
+	}
+	if c.TotalHeader == "" {
+		c.TotalHeader = DefaultTotalCountHeader
+	}
+	return c
+}
+
+// WriteHeaders sets the pagination headers of a response: the serialized
+// next and previous page tokens and the total number of items. Nil values
+// are skipped. If cfg.LinkBaseURL is set, the Link header is added as well;
+// each token is serialized only once. It must be called before the response
+// header is written.
+func WriteHeaders(w http.ResponseWriter, next, prev *KeysetToken, total *int64, cfg HeaderConfig) error {
+	cfg = cfg.withDefaults()
+
+	nextStr, err := tokenString(next)
+	if err != nil {
+		return err
+	}
+	prevStr, err := tokenString(prev)
+	if err != nil {
+		return err
+	}
+
+	h := w.Header()
+	if nextStr != "" {
+		h.Set(cfg.NextHeader, nextStr)
+	}
+	if prevStr != "" {
+		h.Set(cfg.PrevHeader, prevStr)
+	}
+	if total != nil {
+		h.Set(cfg.TotalHeader, strconv.FormatInt(*total, 10))
+	}
+
+	if cfg.LinkBaseURL != nil {
+		if v := linkHeader(cfg.LinkBaseURL, nextStr, prevStr, cfg.TokenParam); v != "" {
+			h.Add("Link", v)
+		}
+	}
+
+	return nil
+}
+
+// PageHeaders are the pagination headers of a response, as read by
+// ReadHeaders.
+type PageHeaders struct {
+	// NextToken and PrevToken are the serialized page tokens, or empty if
+	// there is no such page.
+	NextToken string
+	PrevToken string
+	// Total is the total number of items, or nil if the response has none.
+	Total *int64
+}
+
+// ReadHeaders reads the pagination headers written by WriteHeaders with the
+// same cfg from a response header. A total count that is not a
+// non-negative integer is an error.
+func ReadHeaders(h http.Header, cfg HeaderConfig) (PageHeaders, error) {
+	cfg = cfg.withDefaults()
+
+	p := PageHeaders{
+		NextToken: h.Get(cfg.NextHeader),
+		PrevToken: h.Get(cfg.PrevHeader),
+	}
+
+	if v := h.Get(cfg.TotalHeader); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 0 {
+			return PageHeaders{}, fmt.Errorf("invalid %s header %q", cfg.TotalHeader, v)
+		}
+		p.Total = &n
+	}
+
+	return p, nil
+}
@@ -0,0 +1,103 @@
+package pagetoken_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken"
+)
+
+// countingCrypter encrypts every plaintext to the same token and counts the
+// encryptions.
+type countingCrypter struct {
+	token string
+	calls *int
+}
+
+func (c countingCrypter) Encrypt([]byte) (string, error) {
+	*c.calls++
+	return c.token, nil
+}
+
+func (c countingCrypter) Decrypt(string) ([]byte, error) { return nil, nil }
+
+var _ = Describe("WriteHeaders", func() {
+	var calls int
+
+	BeforeEach(func() {
+		calls = 0
+	})
+
+	token := func(s string) *pagetoken.KeysetToken {
+		return pagetoken.NewKeysetToken(countingCrypter{token: s, calls: &calls})
+	}
+
+	It("writes the default headers and skips absent values", func() {
+		w := httptest.NewRecorder()
+		Expect(pagetoken.WriteHeaders(w, token("bmV4dA"), nil, nil, pagetoken.HeaderConfig{})).To(Succeed())
+
+		Expect(w.Header().Get(pagetoken.DefaultNextPageTokenHeader)).To(Equal("bmV4dA"))
+		Expect(w.Header()).NotTo(HaveKey(pagetoken.DefaultPrevPageTokenHeader))
+		Expect(w.Header()).NotTo(HaveKey(pagetoken.DefaultTotalCountHeader))
+		Expect(w.Header()).NotTo(HaveKey("Link"))
+	})
+
+	It("uses custom header names and reads them back", func() {
+		cfg := pagetoken.HeaderConfig{
+			NextHeader:  "Next-Cursor",
+			PrevHeader:  "Prev-Cursor",
+			TotalHeader: "Result-Count",
+		}
+		total := int64(42)
+
+		w := httptest.NewRecorder()
+		Expect(pagetoken.WriteHeaders(w, token("bmV4dA"), token("cHJldg"), &total, cfg)).To(Succeed())
+		Expect(w.Header().Get("Next-Cursor")).To(Equal("bmV4dA"))
+		Expect(w.Header().Get("Prev-Cursor")).To(Equal("cHJldg"))
+		Expect(w.Header().Get("Result-Count")).To(Equal("42"))
+		Expect(w.Header()).NotTo(HaveKey(pagetoken.DefaultNextPageTokenHeader))
+
+		p, err := pagetoken.ReadHeaders(w.Header(), cfg)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(p.NextToken).To(Equal("bmV4dA"))
+		Expect(p.PrevToken).To(Equal("cHJldg"))
+		Expect(p.Total).To(HaveValue(Equal(int64(42))))
+	})
+
+	It("emits the Link header from the same serialized tokens", func() {
+		base, err := url.Parse("https://api.example.com/users?status=active")
+		Expect(err).NotTo(HaveOccurred())
+
+		w := httptest.NewRecorder()
+		Expect(pagetoken.WriteHeaders(w, token("bmV4dA"), token("cHJldg"), nil, pagetoken.HeaderConfig{
+			LinkBaseURL: base,
+			TokenParam:  "cursor",
+		})).To(Succeed())
+
+		Expect(calls).To(Equal(2))
+		Expect(w.Header().Get("Link")).To(Equal(
+			`<https://api.example.com/users?cursor=bmV4dA&status=active>; rel="next", ` +
+				`<https://api.example.com/users?cursor=cHJldg&status=active>; rel="prev"`,
+		))
+		Expect(w.Header().Get(pagetoken.DefaultNextPageTokenHeader)).To(Equal("bmV4dA"))
+	})
+
+	Describe("ReadHeaders", func() {
+		It("returns zero values for absent headers", func() {
+			p, err := pagetoken.ReadHeaders(http.Header{}, pagetoken.HeaderConfig{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(p).To(Equal(pagetoken.PageHeaders{}))
+		})
+
+		It("rejects a malformed total count", func() {
+			h := http.Header{}
+			h.Set(pagetoken.DefaultTotalCountHeader, "-1")
+			_, err := pagetoken.ReadHeaders(h, pagetoken.HeaderConfig{})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
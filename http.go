@@ -0,0 +1,124 @@
+package pagetoken
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/pixlcrashr/go-pagetoken/checksum"
+)
+
+// DefaultPageTokenParam is the query parameter HTTPRequest reads the page
+// token from.
+const DefaultPageTokenParam = "page_token"
+
+type httpRequest struct {
+	r              *http.Request
+	tokenParam     string
+	tokenHeader    string
+	checksumParams []string
+	excludedParams []string
+}
+
+type HTTPRequestOpt func(*httpRequest)
+
+// WithPageTokenParam sets the query parameter the page token is read from.
+// The default is DefaultPageTokenParam.
+func WithPageTokenParam(name string) HTTPRequestOpt {
+	return func(hr *httpRequest) {
+		hr.tokenParam = name
+	}
+}
+
+// WithPageTokenHeader reads the page token from the named header. The query
+// parameter is only used if the header is missing or empty.
+func WithPageTokenHeader(name string) HTTPRequestOpt {
+	return func(hr *httpRequest) {
+		hr.tokenHeader = name
+	}
+}
+
+// WithChecksumParams sets the query parameters the checksum is built from.
+// Without it, all query parameters are used.
+func WithChecksumParams(names ...string) HTTPRequestOpt {
+	return func(hr *httpRequest) {
+		hr.checksumParams = names
+	}
+}
+
+// WithExcludedParams leaves the named query parameters out of the checksum,
+// e.g. a page size that clients may change between pages.
+func WithExcludedParams(names ...string) HTTPRequestOpt {
+	return func(hr *httpRequest) {
+		hr.excludedParams = append(hr.excludedParams, names...)
+	}
+}
+
+// HTTPRequest adapts a net/http request to the Request interface. The page
+// token is read from the page_token query parameter and the checksum is built
+// from the named query parameters, or from all of them if none are named:
+//
+//	func listUsers(w http.ResponseWriter, r *http.Request) {
+//	    t, err := reader.Read(pagetoken.HTTPRequest(r, "status", "sort"))
+//	    ...
+//	}
+//
+// Use NewHTTPRequest for header-sourced tokens and excluded parameters.
+func HTTPRequest(r *http.Request, checksumParams ...string) Request {
+	return NewHTTPRequest(r, WithChecksumParams(checksumParams...))
+}
+
+// NewHTTPRequest adapts a net/http request to the Request interface.
+//
+// The checksum fields are canonical: parameters are sorted by name and every
+// value of a repeated parameter becomes a field of its own, in the order of
+// the query string. A missing parameter contributes no field, whereas an
+// empty one does. The page token parameter itself is never included.
+func NewHTTPRequest(r *http.Request, opts ...HTTPRequestOpt) Request {
+	hr := &httpRequest{
+		r:          r,
+		tokenParam: DefaultPageTokenParam,
+	}
+	for _, opt := range opts {
+		opt(hr)
+	}
+
+	return hr
+}
+
+func (hr *httpRequest) GetPageToken() string {
+	if hr.tokenHeader != "" {
+		if t := hr.r.Header.Get(hr.tokenHeader); t != "" {
+			return t
+		}
+	}
+
+	return hr.r.URL.Query().Get(hr.tokenParam)
+}
+
+func (hr *httpRequest) GetChecksumFields() []checksum.BuilderOpt {
+	query := hr.r.URL.Query()
+
+	names := hr.checksumParams
+	if len(names) == 0 {
+		names = make([]string, 0, len(query))
+		for name := range query {
+			names = append(names, name)
+		}
+	}
+	names = slices.Clone(names)
+	slices.Sort(names)
+	names = slices.Compact(names)
+
+	fields := []checksum.BuilderOpt{}
+	for _, name := range names {
+		if name == hr.tokenParam || slices.Contains(hr.excludedParams, name) {
+			continue
+		}
+
+		for _, v := range query[name] {
+			fields = append(fields, checksum.Field(name, v))
+		}
+	}
+
+	return fields
+}
@@ -0,0 +1,112 @@
+package pagetoken_test
+
+import (
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/checksum"
+	"github.com/pixlcrashr/go-pagetoken/encryption"
+)
+
+func checksumOf(req pagetoken.Request) uint32 {
+	crc, err := checksum.NewBuilder(req.GetChecksumFields()...).Build()
+	Expect(err).NotTo(HaveOccurred())
+	return crc
+}
+
+var _ = Describe("HTTPRequest", func() {
+	It("reads the page token from the query", func() {
+		r := httptest.NewRequest("GET", "/users?page_token=abc&status=active", nil)
+		Expect(pagetoken.HTTPRequest(r).GetPageToken()).To(Equal("abc"))
+	})
+
+	It("returns an empty token if it is missing", func() {
+		r := httptest.NewRequest("GET", "/users?status=active", nil)
+		Expect(pagetoken.HTTPRequest(r, "status").GetPageToken()).To(BeEmpty())
+	})
+
+	It("reads the page token from a custom parameter", func() {
+		r := httptest.NewRequest("GET", "/users?cursor=abc", nil)
+		req := pagetoken.NewHTTPRequest(r, pagetoken.WithPageTokenParam("cursor"))
+		Expect(req.GetPageToken()).To(Equal("abc"))
+		Expect(checksumOf(req)).To(Equal(checksumOf(pagetoken.HTTPRequest(httptest.NewRequest("GET", "/users", nil)))))
+	})
+
+	It("prefers the page token header over the query", func() {
+		r := httptest.NewRequest("GET", "/users?page_token=query", nil)
+		r.Header.Set("X-Page-Token", "header")
+		Expect(pagetoken.NewHTTPRequest(r, pagetoken.WithPageTokenHeader("X-Page-Token")).GetPageToken()).To(Equal("header"))
+
+		r.Header.Del("X-Page-Token")
+		Expect(pagetoken.NewHTTPRequest(r, pagetoken.WithPageTokenHeader("X-Page-Token")).GetPageToken()).To(Equal("query"))
+	})
+
+	Describe("checksum fields", func() {
+		It("do not depend on the parameter order", func() {
+			a := httptest.NewRequest("GET", "/users?status=active&sort=name&page_token=x", nil)
+			b := httptest.NewRequest("GET", "/users?sort=name&page_token=y&status=active", nil)
+
+			Expect(checksumOf(pagetoken.HTTPRequest(a, "status", "sort"))).
+				To(Equal(checksumOf(pagetoken.HTTPRequest(b, "sort", "status"))))
+		})
+
+		It("only include the named parameters", func() {
+			a := httptest.NewRequest("GET", "/users?status=active&page_size=10", nil)
+			b := httptest.NewRequest("GET", "/users?status=active&page_size=50", nil)
+
+			Expect(checksumOf(pagetoken.HTTPRequest(a, "status"))).
+				To(Equal(checksumOf(pagetoken.HTTPRequest(b, "status"))))
+			Expect(checksumOf(pagetoken.HTTPRequest(a))).
+				NotTo(Equal(checksumOf(pagetoken.HTTPRequest(b))))
+		})
+
+		It("include every value of repeated parameters in order", func() {
+			ab := httptest.NewRequest("GET", "/users?tag=a&tag=b", nil)
+			ba := httptest.NewRequest("GET", "/users?tag=b&tag=a", nil)
+			joined := httptest.NewRequest("GET", "/users?tag=a,b", nil)
+
+			Expect(checksumOf(pagetoken.HTTPRequest(ab, "tag"))).
+				To(Equal(checksumOf(pagetoken.HTTPRequest(httptest.NewRequest("GET", "/users?tag=a&tag=b", nil), "tag"))))
+			Expect(checksumOf(pagetoken.HTTPRequest(ab, "tag"))).NotTo(Equal(checksumOf(pagetoken.HTTPRequest(ba, "tag"))))
+			Expect(checksumOf(pagetoken.HTTPRequest(ab, "tag"))).NotTo(Equal(checksumOf(pagetoken.HTTPRequest(joined, "tag"))))
+		})
+
+		It("tell missing and empty parameters apart", func() {
+			missing := httptest.NewRequest("GET", "/users", nil)
+			empty := httptest.NewRequest("GET", "/users?status=", nil)
+
+			Expect(checksumOf(pagetoken.HTTPRequest(missing, "status"))).
+				NotTo(Equal(checksumOf(pagetoken.HTTPRequest(empty, "status"))))
+		})
+
+		It("leave out excluded parameters", func() {
+			a := httptest.NewRequest("GET", "/users?status=active&page_size=10", nil)
+			b := httptest.NewRequest("GET", "/users?status=active&page_size=50", nil)
+
+			Expect(checksumOf(pagetoken.NewHTTPRequest(a, pagetoken.WithExcludedParams("page_size")))).
+				To(Equal(checksumOf(pagetoken.NewHTTPRequest(b, pagetoken.WithExcludedParams("page_size")))))
+		})
+	})
+
+	It("works with the RequestReader", func() {
+		key, err := encryption.Rand32ByteKey()
+		Expect(err).NotTo(HaveOccurred())
+		e, err := encryption.NewAEADEncryptor(key)
+		Expect(err).NotTo(HaveOccurred())
+		rr := pagetoken.NewRequestReader(pagetoken.WithEncryptor(e))
+
+		t, err := rr.Read(pagetoken.HTTPRequest(httptest.NewRequest("GET", "/users?status=active", nil), "status"))
+		Expect(err).NotTo(HaveOccurred())
+		s, err := t.Next().String()
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = rr.Read(pagetoken.HTTPRequest(httptest.NewRequest("GET", "/users?status=active&page_token="+s, nil), "status"))
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = rr.Read(pagetoken.HTTPRequest(httptest.NewRequest("GET", "/users?status=inactive&page_token="+s, nil), "status"))
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,13 @@
+package checksumpb
+
+import (
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+
+	"github.com/pixlcrashr/go-pagetoken/checksum"
+)
+
+// FieldMask adds the paths of mask as the field key, see
+// checksum.FieldMask. A nil mask is added like an empty one.
+func FieldMask(key string, mask *fieldmaskpb.FieldMask) checksum.BuilderOpt {
+	return checksum.FieldMask(key, mask.GetPaths())
+}
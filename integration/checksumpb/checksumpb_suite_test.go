@@ -0,0 +1,13 @@
+package checksumpb_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestChecksumpb(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Checksumpb Suite")
+}
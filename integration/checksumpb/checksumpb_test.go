@@ -0,0 +1,40 @@
+package checksumpb_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+
+	"github.com/pixlcrashr/go-pagetoken/checksum"
+	"github.com/pixlcrashr/go-pagetoken/integration/checksumpb"
+)
+
+func maskChecksum(mask *fieldmaskpb.FieldMask) uint32 {
+	crc, err := checksum.NewBuilder(checksumpb.FieldMask("read_mask", mask)).Build()
+	Expect(err).NotTo(HaveOccurred())
+	return crc
+}
+
+var _ = Describe("FieldMask", func() {
+	It("matches checksum.FieldMask", func() {
+		paths := []string{"title", "author.name"}
+
+		crc, err := checksum.NewBuilder(checksum.FieldMask("read_mask", paths)).Build()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(maskChecksum(&fieldmaskpb.FieldMask{Paths: paths})).To(Equal(crc))
+	})
+
+	It("yields equal checksums for equivalent masks", func() {
+		Expect(maskChecksum(&fieldmaskpb.FieldMask{Paths: []string{"title", "author"}})).
+			To(Equal(maskChecksum(&fieldmaskpb.FieldMask{Paths: []string{"author", "author.name", "title"}})))
+	})
+
+	It("yields different checksums for different masks", func() {
+		Expect(maskChecksum(&fieldmaskpb.FieldMask{Paths: []string{"title"}})).
+			NotTo(Equal(maskChecksum(&fieldmaskpb.FieldMask{Paths: []string{"title", "author"}})))
+	})
+
+	It("adds a nil mask like an empty one", func() {
+		Expect(maskChecksum(nil)).To(Equal(maskChecksum(&fieldmaskpb.FieldMask{})))
+	})
+})
@@ -0,0 +1,16 @@
+// Package checksumpb adds protobuf field masks to page token checksums.
+//
+// FieldMask binds a token to the google.protobuf.FieldMask of a list
+// request, normalized like checksum.FieldMask, so that equivalent masks in a
+// different order keep the token valid and different masks invalidate it:
+//
+//	func (r *ListBooksRequest) GetChecksumFields() []checksum.BuilderOpt {
+//	    return []checksum.BuilderOpt{
+//	        checksumpb.FieldMask("read_mask", r.GetReadMask()),
+//	        checksum.Field("view", r.GetView().String()),
+//	    }
+//	}
+//
+// The package lives in its own Go module so that depending on the core
+// pagetoken package does not pull in the protobuf runtime.
+package checksumpb
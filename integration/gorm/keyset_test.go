@@ -0,0 +1,249 @@
+//go:build integration
+
+package gorm_test
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/testcontainers/testcontainers-go"
+	tcmysql "github.com/testcontainers/testcontainers-go/modules/mysql"
+	tcpostgres "github.com/testcontainers/testcontainers-go/modules/postgres"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	ptgorm "github.com/pixlcrashr/go-pagetoken/database/gorm"
+	"github.com/pixlcrashr/go-pagetoken/database/sqlraw"
+	"github.com/pixlcrashr/go-pagetoken/order"
+	"github.com/pixlcrashr/go-pagetoken/pagetokentest"
+)
+
+// walkRow is seeded with data that trips up keyset pagination: sort keys
+// shared by many rows, NULLs, strings that compare equal or differ in case,
+// accents or trailing spaces only, and timestamps colliding once stored at
+// microsecond precision.
+type walkRow struct {
+	ID    int64     `gorm:"primaryKey;autoIncrement:false"`
+	At    time.Time `gorm:"precision:6"`
+	Name  string    `gorm:"size:64"`
+	Score *int
+}
+
+// scoreOrNull sorts NULL scores like -1. Keyset values cannot be NULL, so a
+// nullable column is paginated by a coalesced expression.
+const scoreOrNull = "COALESCE(score, -1)"
+
+var walkColumns = []string{"at", "name", scoreOrNull}
+
+var walkNames = []string{"a", "A", "ä", "Ä", "á", "b", "B", "e", "é", "E", "ss", "ß", "z", "Z", "😀", "", "a ", " a"}
+
+func seedWalkRows(db *gorm.DB) {
+	Expect(db.Migrator().DropTable(&walkRow{})).To(Succeed())
+	Expect(db.AutoMigrate(&walkRow{})).To(Succeed())
+	DeferCleanup(func() {
+		Expect(db.Migrator().DropTable(&walkRow{})).To(Succeed())
+	})
+
+	base := time.Date(2024, 2, 29, 23, 59, 59, 999_000_000, time.UTC)
+
+	rows := make([]walkRow, 72)
+	for i := range rows {
+		rows[i] = walkRow{
+			ID: int64(i + 1),
+			// groups of eight share a microsecond once stored, although
+			// every other row of a group is 100ns later when inserted
+			At:   base.Add(time.Duration(i/8)*time.Microsecond + time.Duration(i%2)*100*time.Nanosecond),
+			Name: walkNames[i%len(walkNames)],
+		}
+		if i%4 != 0 {
+			s := i%7 - 3
+			rows[i].Score = &s
+		}
+	}
+	Expect(db.Create(&rows).Error).To(Succeed())
+}
+
+func walkRowValue(column string, payload *pagetoken.KeysetPayload) (any, error) {
+	switch column {
+	case "at":
+		v, _, err := payload.Time(column)
+		return v, err
+	case "name":
+		v, _, err := payload.String(column)
+		return v, err
+	default:
+		v, _, err := payload.Int64(column)
+		return v, err
+	}
+}
+
+func walkRowKeyset(r walkRow, spec pagetoken.SortSpec) (*pagetoken.KeysetPayload, error) {
+	b := pagetoken.NewKeysetPayloadBuilder()
+	for _, f := range spec {
+		switch f.Path {
+		case "id":
+			b.AddInt64(f.Path, r.ID, f.Order)
+		case "at":
+			b.AddTime(f.Path, r.At, f.Order)
+		case "name":
+			b.AddString(f.Path, r.Name, f.Order)
+		case scoreOrNull:
+			s := -1
+			if r.Score != nil {
+				s = *r.Score
+			}
+			b.AddInt64(f.Path, int64(s), f.Order)
+		default:
+			return nil, fmt.Errorf("unknown column %q", f.Path)
+		}
+	}
+	return b.Build(), nil
+}
+
+// walkSpecs returns every sort spec of one or two distinct columns of
+// walkColumns in every combination of directions.
+func walkSpecs() []pagetoken.SortSpec {
+	orders := []order.Order{order.Asc, order.Desc}
+
+	var specs []pagetoken.SortSpec
+	for _, c1 := range walkColumns {
+		for _, o1 := range orders {
+			specs = append(specs, pagetoken.SortSpec{{Path: c1, Order: o1}})
+
+			for _, c2 := range walkColumns {
+				if c2 == c1 {
+					continue
+				}
+				for _, o2 := range orders {
+					specs = append(specs, pagetoken.SortSpec{{Path: c1, Order: o1}, {Path: c2, Order: o2}})
+				}
+			}
+		}
+	}
+	return specs
+}
+
+// wantWalkIDs returns the ids of all rows in the order the database sorts
+// them by spec in a single query, which pagination must reproduce.
+func wantWalkIDs(db *gorm.DB, spec pagetoken.SortSpec) []string {
+	var ids []int64
+	Expect(db.Model(&walkRow{}).
+		Order(sqlraw.NewBuilder().OrderBy(pagetoken.EnsureTiebreak(spec, "id"))).
+		Pluck("id", &ids).Error).To(Succeed())
+
+	want := make([]string, len(ids))
+	for i, id := range ids {
+		want[i] = strconv.FormatInt(id, 10)
+	}
+	return want
+}
+
+func fetchWalkRows(db *gorm.DB, pageSize int, spec pagetoken.SortSpec, opts ...ptgorm.KeysetWhereOrderLimitOpt) func(string) ([]walkRow, string, error) {
+	cfg := ptgorm.ListConfig[walkRow]{
+		Spec:     spec,
+		ValueFn:  walkRowValue,
+		KeysetFn: walkRowKeyset,
+		Opts:     opts,
+	}
+
+	return func(token string) ([]walkRow, string, error) {
+		var keyset *pagetoken.KeysetPayload
+		if token != "" {
+			keyset = pagetokentest.ParseForTest(GinkgoT(), token).Payload()
+		}
+
+		rows, next, err := ptgorm.ListKeyset(db.Model(&walkRow{}), keyset, pageSize, cfg)
+		if err != nil || next == nil {
+			return rows, "", err
+		}
+
+		return rows, pagetokentest.MustToken(GinkgoT(), next.Values()...), nil
+	}
+}
+
+func walkRowID(r walkRow) string {
+	return strconv.FormatInt(r.ID, 10)
+}
+
+// describeWalks walks the rows of the database returned by open in every
+// sort order, with strict and inclusive boundaries.
+func describeWalks(open func() *gorm.DB) {
+	var db *gorm.DB
+
+	BeforeAll(func() {
+		db = open()
+		seedWalkRows(db)
+	})
+
+	modes := []struct {
+		name string
+		opts []ptgorm.KeysetWhereOrderLimitOpt
+	}{
+		{name: "strict"},
+		{name: "inclusive", opts: []ptgorm.KeysetWhereOrderLimitOpt{ptgorm.WithInclusiveBoundary("id")}},
+	}
+
+	for _, mode := range modes {
+		for _, spec := range walkSpecs() {
+			It(fmt.Sprintf("visits every row once by %s with %s boundaries", sqlraw.NewBuilder().OrderBy(spec), mode.name), func() {
+				want := wantWalkIDs(db, spec)
+				Expect(want).To(HaveLen(72))
+
+				for _, pageSize := range []int{1, 5, 16} {
+					By("pages of " + strconv.Itoa(pageSize))
+					pagetokentest.WalkAllPages(GinkgoT(), fetchWalkRows(db, pageSize, spec, mode.opts...), walkRowID, want)
+				}
+			})
+		}
+	}
+}
+
+var _ = Describe("KeysetWhereOrderLimit on Postgres", Ordered, func() {
+	describeWalks(func() *gorm.DB {
+		ctx := context.Background()
+
+		c, err := tcpostgres.Run(ctx, "postgres:17-alpine",
+			tcpostgres.WithDatabase("pagetoken"),
+			tcpostgres.WithUsername("pagetoken"),
+			tcpostgres.WithPassword("pagetoken"),
+			tcpostgres.BasicWaitStrategies(),
+		)
+		DeferCleanup(testcontainers.TerminateContainer, c)
+		Expect(err).NotTo(HaveOccurred())
+
+		dsn, err := c.ConnectionString(ctx, "sslmode=disable")
+		Expect(err).NotTo(HaveOccurred())
+
+		db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Discard})
+		Expect(err).NotTo(HaveOccurred())
+		return db
+	})
+})
+
+var _ = Describe("KeysetWhereOrderLimit on MySQL", Ordered, func() {
+	describeWalks(func() *gorm.DB {
+		ctx := context.Background()
+
+		c, err := tcmysql.Run(ctx, "mysql:8.4",
+			tcmysql.WithDatabase("pagetoken"),
+			tcmysql.WithUsername("pagetoken"),
+			tcmysql.WithPassword("pagetoken"),
+		)
+		DeferCleanup(testcontainers.TerminateContainer, c)
+		Expect(err).NotTo(HaveOccurred())
+
+		dsn, err := c.ConnectionString(ctx, "parseTime=true", "loc=UTC")
+		Expect(err).NotTo(HaveOccurred())
+
+		db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{Logger: logger.Discard})
+		Expect(err).NotTo(HaveOccurred())
+		return db
+	})
+})
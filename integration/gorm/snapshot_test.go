@@ -0,0 +1,115 @@
+//go:build integration
+
+package gorm_test
+
+import (
+	"context"
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	ptgorm "github.com/pixlcrashr/go-pagetoken/database/gorm"
+	"github.com/pixlcrashr/go-pagetoken/database/sqlraw"
+	"github.com/pixlcrashr/go-pagetoken/order"
+)
+
+type snapshotItem struct {
+	ID int64 `gorm:"primaryKey"`
+}
+
+func openDSN(env string) *gorm.DB {
+	dsn := os.Getenv(env)
+	if dsn == "" {
+		Skip(env + " is not set")
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Discard})
+	Expect(err).NotTo(HaveOccurred())
+
+	Expect(db.Migrator().DropTable(&snapshotItem{})).To(Succeed())
+	Expect(db.AutoMigrate(&snapshotItem{})).To(Succeed())
+	DeferCleanup(func() {
+		Expect(db.Migrator().DropTable(&snapshotItem{})).To(Succeed())
+	})
+
+	return db
+}
+
+func seedSnapshotItems(db *gorm.DB, from, to int64) {
+	for id := from; id <= to; id++ {
+		Expect(db.Create(&snapshotItem{ID: id}).Error).To(Succeed())
+	}
+}
+
+func snapshotItemValue(column string, payload *pagetoken.KeysetPayload) (any, error) {
+	v, _, err := payload.Int64(column)
+	return v, err
+}
+
+var byID = pagetoken.SortSpec{{Path: "id", Order: order.Asc}}
+
+var _ = Describe("CockroachSnapshotTx", func() {
+	It("reads every page at the snapshot of the first page", func() {
+		db := openDSN("PAGETOKEN_COCKROACH_DSN")
+		seedSnapshotItems(db, 1, 10)
+
+		// AS OF SYSTEM TIME must not be in the future of the cluster clock
+		time.Sleep(100 * time.Millisecond)
+		ts := time.Now().Add(-50 * time.Millisecond)
+		token := (&pagetoken.KeysetToken{}).Next(pagetoken.WithSnapshotTime(ts))
+
+		// written after the snapshot, so no page must see it
+		seedSnapshotItems(db, 11, 12)
+
+		ctx := context.Background()
+		seen := []int64{}
+		var keyset *pagetoken.KeysetPayload
+
+		for {
+			var page []snapshotItem
+			Expect(ptgorm.CockroachSnapshotTx(ctx, db, token, func(tx *gorm.DB) error {
+				q, err := ptgorm.KeysetWhereOrderLimit(tx.Model(&snapshotItem{}), keyset, snapshotItemValue, ptgorm.WithSortSpec(byID))
+				if err != nil {
+					return err
+				}
+				return q.Limit(4).Find(&page).Error
+			}, sqlraw.WithMaxSnapshotAge(time.Minute))).To(Succeed())
+
+			for _, it := range page {
+				seen = append(seen, it.ID)
+			}
+			if len(page) < 4 {
+				break
+			}
+
+			keyset = pagetoken.NewKeysetPayloadBuilder().AddInt64("id", page[len(page)-1].ID, order.Asc).Build()
+		}
+
+		Expect(seen).To(Equal([]int64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}))
+	})
+})
+
+var _ = Describe("PostgresSnapshotTx", func() {
+	It("runs a read-only repeatable read transaction", func() {
+		db := openDSN("PAGETOKEN_POSTGRES_DSN")
+		seedSnapshotItems(db, 1, 3)
+
+		token := (&pagetoken.KeysetToken{}).Next(pagetoken.WithSnapshotTime(time.Now()))
+
+		Expect(ptgorm.PostgresSnapshotTx(context.Background(), db, token, func(tx *gorm.DB) error {
+			var isolation string
+			if err := tx.Raw("SHOW transaction_isolation").Scan(&isolation).Error; err != nil {
+				return err
+			}
+			Expect(isolation).To(Equal("repeatable read"))
+
+			return tx.Create(&snapshotItem{ID: 4}).Error
+		}, sqlraw.WithMaxSnapshotAge(time.Minute))).NotTo(Succeed(), "writes must fail in a read-only transaction")
+	})
+})
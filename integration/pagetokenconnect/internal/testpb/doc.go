@@ -0,0 +1,6 @@
+// Package testpb contains the messages of books.proto, a toy AIP-158 list
+// service used by the tests of pagetokenconnect. The Connect stubs are in
+// testpbconnect.
+//
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --connect-go_out=. --connect-go_opt=paths=source_relative books.proto
+package testpb
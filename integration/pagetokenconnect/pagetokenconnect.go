@@ -0,0 +1,229 @@
+// Package pagetokenconnect wires page tokens into Connect services
+// (connectrpc.com/connect).
+//
+// UnaryInterceptor reads and validates the page token of every request
+// message implementing PageTokenCarrier before the handler runs and stores
+// it in the context, where handlers retrieve it with pagetoken.FromContext.
+// Tokens are scoped to the procedure they were issued by, see
+// pagetoken.Scoper, so a token of one list method is rejected by all others
+// with pagetoken.ErrScopeMismatch. Token errors are mapped to
+// connect.CodeInvalidArgument with error details.
+//
+// The package lives in its own Go module so that depending on the core
+// pagetoken package does not pull in Connect.
+//
+// # Example
+//
+//	path, handler := booksv1connect.NewBookServiceHandler(&server{},
+//	    connect.WithInterceptors(pagetokenconnect.UnaryInterceptor(reader)),
+//	)
+//
+//	func (s *server) ListBooks(ctx context.Context, req *connect.Request[booksv1.ListBooksRequest]) (*connect.Response[booksv1.ListBooksResponse], error) {
+//	    t, _ := pagetoken.FromContext(ctx)
+//	    ...
+//	}
+package pagetokenconnect
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"connectrpc.com/connect"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/checksum"
+)
+
+// ErrorDomain is the domain of the errdetails.ErrorInfo attached to token
+// errors.
+const ErrorDomain = "pagetoken"
+
+// Reasons of the errdetails.ErrorInfo attached to token errors.
+const (
+	ReasonInvalidPageToken = "INVALID_PAGE_TOKEN"
+	ReasonChecksumMismatch = "PAGE_TOKEN_MISMATCH"
+	ReasonScopeMismatch    = "PAGE_TOKEN_SCOPE_MISMATCH"
+	ReasonTokenExpired     = "PAGE_TOKEN_EXPIRED"
+	ReasonTokenRevoked     = "PAGE_TOKEN_REVOKED"
+	ReasonStaleToken       = "PAGE_TOKEN_STALE"
+)
+
+// PageTokenCarrier is implemented by request messages carrying a page token,
+// such as AIP-158 list requests generated by protoc-gen-go.
+type PageTokenCarrier interface {
+	GetPageToken() string
+}
+
+// ChecksumFieldsFn returns the checksum fields of a request message.
+type ChecksumFieldsFn func(msg any) ([]checksum.BuilderOpt, error)
+
+// DefaultChecksumFields builds the checksum from the GetChecksumFields method
+// of messages implementing pagetoken.Request. For other proto messages it
+// uses all set fields except page_token and page_size, so that clients may
+// change the page size between pages as AIP-158 allows.
+func DefaultChecksumFields(msg any) ([]checksum.BuilderOpt, error) {
+	switch msg := msg.(type) {
+	case pagetoken.Request:
+		return msg.GetChecksumFields(), nil
+	case proto.Message:
+		return protoFields(msg)
+	default:
+		return nil, fmt.Errorf("%T is neither a pagetoken.Request nor a proto message", msg)
+	}
+}
+
+// protoFields encodes every set field of msg except page_token and page_size
+// as checksum field. The value is the deterministic wire encoding of a
+// message holding only that field, which covers scalar, repeated, map and
+// message fields alike.
+func protoFields(msg proto.Message) ([]checksum.BuilderOpt, error) {
+	m := msg.ProtoReflect()
+	fds := m.Descriptor().Fields()
+
+	fields := []checksum.BuilderOpt{}
+	for i := 0; i < fds.Len(); i++ {
+		fd := fds.Get(i)
+		if fd.Name() == "page_token" || fd.Name() == "page_size" || !m.Has(fd) {
+			continue
+		}
+
+		only := m.Type().New()
+		only.Set(fd, m.Get(fd))
+
+		bs, err := proto.MarshalOptions{Deterministic: true}.Marshal(only.Interface())
+		if err != nil {
+			return nil, err
+		}
+
+		fields = append(fields, checksum.Field(string(fd.Name()), base64.RawStdEncoding.EncodeToString(bs)))
+	}
+
+	return fields, nil
+}
+
+type request struct {
+	token     string
+	fields    []checksum.BuilderOpt
+	procedure string
+}
+
+func (r *request) GetPageToken() string                     { return r.token }
+func (r *request) GetChecksumFields() []checksum.BuilderOpt { return r.fields }
+func (r *request) GetTokenScope() string                    { return r.procedure }
+
+type interceptor struct {
+	reader *pagetoken.RequestReader
+	fields ChecksumFieldsFn
+}
+
+type InterceptorOpt func(*interceptor)
+
+// WithChecksumFields sets the function building the checksum fields of
+// request messages. The default is DefaultChecksumFields. Tokens are scoped
+// to the procedure in any case.
+func WithChecksumFields(fields ChecksumFieldsFn) InterceptorOpt {
+	return func(i *interceptor) {
+		i.fields = fields
+	}
+}
+
+// UnaryInterceptor returns a handler interceptor that reads the page token
+// of every request message implementing PageTokenCarrier with reader and
+// stores it in the context for pagetoken.FromContext to return. The
+// checksum covers the message's checksum fields and the token scope is the
+// procedure. Requests without a page token get a fresh token carrying their
+// checksum and scope. Invalid or mismatching tokens are rejected with the
+// error returned by Error, so the handler is not called.
+//
+// Other requests, and all requests when used as a client interceptor, are
+// passed through unchanged.
+func UnaryInterceptor(reader *pagetoken.RequestReader, opts ...InterceptorOpt) connect.UnaryInterceptorFunc {
+	i := &interceptor{
+		reader: reader,
+		fields: DefaultChecksumFields,
+	}
+	for _, opt := range opts {
+		opt(i)
+	}
+
+	return func(next connect.UnaryFunc) connect.UnaryFunc {
+		return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+			msg := req.Any()
+			carrier, ok := msg.(PageTokenCarrier)
+			if !ok || req.Spec().IsClient {
+				return next(ctx, req)
+			}
+
+			fields, err := i.fields(msg)
+			if err != nil {
+				return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to build page token checksum: %w", err))
+			}
+
+			t, err := i.reader.Read(&request{
+				token:     carrier.GetPageToken(),
+				fields:    fields,
+				procedure: req.Spec().Procedure,
+			})
+			if err != nil {
+				return nil, Error(err)
+			}
+
+			return next(pagetoken.NewContext(ctx, t), req)
+		}
+	}
+}
+
+// Error maps an error returned by the pagetoken package to a *connect.Error.
+// Errors pagetoken.HTTPStatus maps to 400 become
+// connect.CodeInvalidArgument with an errdetails.BadRequest naming the
+// page_token field and an errdetails.ErrorInfo with a machine-readable
+// reason; all other errors become connect.CodeInternal.
+func Error(err error) *connect.Error {
+	if pagetoken.HTTPStatus(err) != http.StatusBadRequest {
+		return connect.NewError(connect.CodeInternal, errors.New("failed to read page token"))
+	}
+
+	reason, desc := ReasonInvalidPageToken, "invalid page token"
+	switch {
+	case errors.Is(err, pagetoken.ErrScopeMismatch):
+		reason = ReasonScopeMismatch
+		desc = "page token was issued by another procedure"
+	case errors.Is(err, pagetoken.ErrChecksumMismatch):
+		reason = ReasonChecksumMismatch
+		desc = "page token does not match the request parameters"
+	case errors.Is(err, pagetoken.ErrTokenExpired):
+		reason = ReasonTokenExpired
+		desc = "page token expired"
+	case errors.Is(err, pagetoken.ErrTokenRevoked):
+		reason = ReasonTokenRevoked
+		desc = "page token revoked"
+	case errors.Is(err, pagetoken.ErrStaleToken):
+		reason = ReasonStaleToken
+		desc = "page token does not match the current sort order"
+	}
+
+	ce := connect.NewError(connect.CodeInvalidArgument, errors.New(desc))
+	for _, d := range []proto.Message{
+		&errdetails.BadRequest{
+			FieldViolations: []*errdetails.BadRequest_FieldViolation{{
+				Field:       "page_token",
+				Description: desc,
+			}},
+		},
+		&errdetails.ErrorInfo{
+			Reason: reason,
+			Domain: ErrorDomain,
+		},
+	} {
+		if detail, err := connect.NewErrorDetail(d); err == nil {
+			ce.AddDetail(detail)
+		}
+	}
+
+	return ce
+}
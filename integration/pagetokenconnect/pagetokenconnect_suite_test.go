@@ -0,0 +1,13 @@
+package pagetokenconnect_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestPagetokenconnect(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Pagetokenconnect Suite")
+}
@@ -0,0 +1,212 @@
+package pagetokenconnect_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	"connectrpc.com/connect"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/encryption"
+	"github.com/pixlcrashr/go-pagetoken/integration/pagetokenconnect"
+	"github.com/pixlcrashr/go-pagetoken/integration/pagetokenconnect/internal/testpb"
+	"github.com/pixlcrashr/go-pagetoken/integration/pagetokenconnect/internal/testpb/testpbconnect"
+	"github.com/pixlcrashr/go-pagetoken/order"
+)
+
+const listArchivedBooksProcedure = "/pagetoken.test.v1.BookService/ListArchivedBooks"
+
+// memTransport serves requests with an http.Handler in memory.
+type memTransport struct {
+	h http.Handler
+}
+
+func (t memTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	rec := httptest.NewRecorder()
+	t.h.ServeHTTP(rec, r)
+	return rec.Result(), nil
+}
+
+// bookServer lists ten books ordered by id, optionally filtered to odd ids.
+type bookServer struct {
+	called int
+}
+
+func (s *bookServer) ListBooks(ctx context.Context, req *connect.Request[testpb.ListBooksRequest]) (*connect.Response[testpb.ListBooksResponse], error) {
+	s.called++
+
+	t, ok := pagetoken.FromContext(ctx)
+	if !ok {
+		return nil, connect.NewError(connect.CodeInternal, errors.New("no page token in context"))
+	}
+
+	after, _, err := t.Payload().Int("id")
+	if err != nil {
+		after = 0
+	}
+
+	size := int(req.Msg.GetPageSize())
+
+	// fetch one more book than requested to tell whether there is a next page
+	books := []*testpb.Book{}
+	for id := after + 1; id <= 10 && len(books) <= size; id++ {
+		if req.Msg.GetFilter() == "odd" && id%2 == 0 {
+			continue
+		}
+		books = append(books, &testpb.Book{Id: int64(id), Title: fmt.Sprintf("Book %d", id)})
+	}
+
+	resp := &testpb.ListBooksResponse{Books: books}
+	if len(books) > size {
+		resp.Books = books[:size]
+		resp.NextPageToken, err = t.Next(pagetoken.WithKeysetPayload(
+			pagetoken.NewKeysetPayloadBuilder().AddInt("id", int(resp.Books[size-1].Id), order.Asc).Build(),
+		)).String()
+		if err != nil {
+			return nil, pagetokenconnect.Error(err)
+		}
+	}
+
+	return connect.NewResponse(resp), nil
+}
+
+var _ = Describe("pagetokenconnect", func() {
+	var (
+		srv     *bookServer
+		httpc   *http.Client
+		archive *connect.Client[testpb.ListBooksRequest, testpb.ListBooksResponse]
+		ctx     context.Context
+	)
+
+	BeforeEach(func() {
+		key, err := encryption.Rand32ByteKey()
+		Expect(err).NotTo(HaveOccurred())
+		e, err := encryption.NewAEADEncryptor(key)
+		Expect(err).NotTo(HaveOccurred())
+
+		interceptors := connect.WithInterceptors(pagetokenconnect.UnaryInterceptor(
+			pagetoken.NewRequestReader(pagetoken.WithEncryptor(e)),
+		))
+
+		srv = &bookServer{}
+		mux := http.NewServeMux()
+		mux.Handle(testpbconnect.NewBookServiceHandler(srv, interceptors))
+		mux.Handle(listArchivedBooksProcedure, connect.NewUnaryHandler(listArchivedBooksProcedure, srv.ListBooks, interceptors))
+
+		httpc = &http.Client{Transport: memTransport{h: mux}}
+		archive = connect.NewClient[testpb.ListBooksRequest, testpb.ListBooksResponse](httpc, "http://mem"+listArchivedBooksProcedure)
+		ctx = context.Background()
+	})
+
+	ids := func(resp *connect.Response[testpb.ListBooksResponse]) []int64 {
+		out := []int64{}
+		for _, b := range resp.Msg.GetBooks() {
+			out = append(out, b.GetId())
+		}
+		return out
+	}
+
+	expectInvalidArgument := func(err error, reason string) {
+		var ce *connect.Error
+		Expect(errors.As(err, &ce)).To(BeTrue())
+		Expect(ce.Code()).To(Equal(connect.CodeInvalidArgument))
+
+		var br *errdetails.BadRequest
+		var info *errdetails.ErrorInfo
+		for _, d := range ce.Details() {
+			v, err := d.Value()
+			Expect(err).NotTo(HaveOccurred())
+			switch v := v.(type) {
+			case *errdetails.BadRequest:
+				br = v
+			case *errdetails.ErrorInfo:
+				info = v
+			}
+		}
+		Expect(br).NotTo(BeNil())
+		Expect(br.GetFieldViolations()[0].GetField()).To(Equal("page_token"))
+		Expect(info).NotTo(BeNil())
+		Expect(info.GetDomain()).To(Equal(pagetokenconnect.ErrorDomain))
+		Expect(info.GetReason()).To(Equal(reason))
+	}
+
+	for _, codec := range []struct {
+		name string
+		opts []connect.ClientOption
+	}{
+		{"proto", nil},
+		{"JSON", []connect.ClientOption{connect.WithProtoJSON()}},
+	} {
+		Context("with the "+codec.name+" codec", func() {
+			var client testpbconnect.BookServiceClient
+
+			BeforeEach(func() {
+				client = testpbconnect.NewBookServiceClient(httpc, "http://mem", codec.opts...)
+			})
+
+			It("walks all pages and allows the page size to change", func() {
+				resp, err := client.ListBooks(ctx, connect.NewRequest(&testpb.ListBooksRequest{PageSize: 4, Filter: "odd"}))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(ids(resp)).To(Equal([]int64{1, 3, 5, 7}))
+
+				resp, err = client.ListBooks(ctx, connect.NewRequest(&testpb.ListBooksRequest{
+					PageSize: 2, Filter: "odd", PageToken: resp.Msg.GetNextPageToken(),
+				}))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(ids(resp)).To(Equal([]int64{9}))
+				Expect(resp.Msg.GetNextPageToken()).To(BeEmpty())
+			})
+
+			It("rejects a tampered token before the handler runs", func() {
+				resp, err := client.ListBooks(ctx, connect.NewRequest(&testpb.ListBooksRequest{PageSize: 2}))
+				Expect(err).NotTo(HaveOccurred())
+
+				s := []byte(resp.Msg.GetNextPageToken())
+				s[len(s)/2] ^= 0x01
+
+				_, err = client.ListBooks(ctx, connect.NewRequest(&testpb.ListBooksRequest{PageSize: 2, PageToken: string(s)}))
+				expectInvalidArgument(err, pagetokenconnect.ReasonInvalidPageToken)
+				Expect(srv.called).To(Equal(1))
+			})
+
+			It("rejects a token issued for another filter", func() {
+				resp, err := client.ListBooks(ctx, connect.NewRequest(&testpb.ListBooksRequest{PageSize: 2, Filter: "odd"}))
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = client.ListBooks(ctx, connect.NewRequest(&testpb.ListBooksRequest{PageSize: 2, PageToken: resp.Msg.GetNextPageToken()}))
+				expectInvalidArgument(err, pagetokenconnect.ReasonChecksumMismatch)
+				Expect(srv.called).To(Equal(1))
+			})
+
+			It("rejects a token issued by another procedure", func() {
+				resp, err := client.ListBooks(ctx, connect.NewRequest(&testpb.ListBooksRequest{PageSize: 2}))
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = archive.CallUnary(ctx, connect.NewRequest(&testpb.ListBooksRequest{PageSize: 2, PageToken: resp.Msg.GetNextPageToken()}))
+				expectInvalidArgument(err, pagetokenconnect.ReasonScopeMismatch)
+				Expect(srv.called).To(Equal(1))
+			})
+		})
+	}
+
+	It("maps expired tokens to connect.CodeInvalidArgument", func() {
+		err := fmt.Errorf("read page token: %w", pagetoken.ErrTokenExpired)
+		expectInvalidArgument(pagetokenconnect.Error(err), pagetokenconnect.ReasonTokenExpired)
+	})
+
+	It("maps revoked tokens to connect.CodeInvalidArgument", func() {
+		err := fmt.Errorf("read page token: %w", pagetoken.ErrTokenRevoked)
+		expectInvalidArgument(pagetokenconnect.Error(err), pagetokenconnect.ReasonTokenRevoked)
+	})
+
+	It("maps stale tokens to connect.CodeInvalidArgument", func() {
+		err := fmt.Errorf("read page token: %w", pagetoken.ErrStaleToken)
+		expectInvalidArgument(pagetokenconnect.Error(err), pagetokenconnect.ReasonStaleToken)
+	})
+})
@@ -0,0 +1,190 @@
+// Package pagetokenecho wires page tokens into Echo applications.
+//
+// Middleware reads the page token of every request it handles, validates it
+// against the route's checksum fields and stores the token in the
+// echo.Context, where handlers retrieve it with Token. Token errors are
+// turned into *echo.HTTPError values with the status of pagetoken.HTTPStatus.
+// Handlers that bind their own request structs use Bind instead.
+//
+// The package lives in its own Go module so that depending on the core
+// pagetoken package does not pull in Echo.
+//
+// # Example
+//
+//	e.Use(pagetokenecho.Middleware(reader, pagetokenecho.Config{
+//	    Routes: map[string]pagetokenecho.ChecksumFieldsFn{
+//	        "/books": func(c echo.Context) []checksum.BuilderOpt {
+//	            return []checksum.BuilderOpt{checksum.Field("status", c.QueryParam("status"))}
+//	        },
+//	    },
+//	}))
+//
+//	e.GET("/books", func(c echo.Context) error {
+//	    t := pagetokenecho.Token(c)
+//	    ...
+//	})
+package pagetokenecho
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/checksum"
+)
+
+// ContextKey is the echo.Context key Middleware stores the page token under.
+const ContextKey = "pagetoken"
+
+// ChecksumFieldsFn returns the checksum fields of a request.
+type ChecksumFieldsFn func(c echo.Context) []checksum.BuilderOpt
+
+// Config configures Middleware.
+type Config struct {
+	// Skipper defines a function to skip the middleware for some requests.
+	Skipper middleware.Skipper
+	// TokenParam is the query parameter the page token is read from. The
+	// default is pagetoken.DefaultPageTokenParam.
+	TokenParam string
+	// TokenHeader, if set, names a header the page token is read from. The
+	// query parameter is only used if the header is missing or empty.
+	TokenHeader string
+	// Routes maps route paths, as registered with Echo and returned by
+	// echo.Context.Path, to the checksum fields of their requests.
+	Routes map[string]ChecksumFieldsFn
+	// ChecksumFields returns the checksum fields of requests to routes that
+	// are not listed in Routes. If nil, the checksum is built from all query
+	// parameters as in pagetoken.NewHTTPRequest.
+	//
+	// It can also be used to declare the fields per route by passing the
+	// middleware to the route only:
+	//
+	//	e.GET("/books", listBooks, pagetokenecho.Middleware(reader, pagetokenecho.Config{
+	//	    ChecksumFields: booksChecksumFields,
+	//	}))
+	ChecksumFields ChecksumFieldsFn
+}
+
+type request struct {
+	c      echo.Context
+	cfg    Config
+	fields ChecksumFieldsFn
+}
+
+func (r *request) GetPageToken() string {
+	if r.cfg.TokenHeader != "" {
+		if t := r.c.Request().Header.Get(r.cfg.TokenHeader); t != "" {
+			return t
+		}
+	}
+
+	return r.c.QueryParam(r.cfg.TokenParam)
+}
+
+func (r *request) GetChecksumFields() []checksum.BuilderOpt {
+	if r.fields != nil {
+		return r.fields(r.c)
+	}
+
+	return pagetoken.NewHTTPRequest(
+		r.c.Request(),
+		pagetoken.WithPageTokenParam(r.cfg.TokenParam),
+	).GetChecksumFields()
+}
+
+// Middleware returns a middleware that reads the page token of each request
+// with reader and stores it in the context for Token to return. Requests
+// without a page token get a fresh token carrying their checksum. Invalid or
+// mismatching tokens are rejected with the error returned by Error, so the
+// handler is not called.
+func Middleware(reader *pagetoken.RequestReader, cfg Config) echo.MiddlewareFunc {
+	if cfg.Skipper == nil {
+		cfg.Skipper = middleware.DefaultSkipper
+	}
+	if cfg.TokenParam == "" {
+		cfg.TokenParam = pagetoken.DefaultPageTokenParam
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if cfg.Skipper(c) {
+				return next(c)
+			}
+
+			fields, ok := cfg.Routes[c.Path()]
+			if !ok {
+				fields = cfg.ChecksumFields
+			}
+
+			t, err := reader.Read(&request{c: c, cfg: cfg, fields: fields})
+			if err != nil {
+				return Error(err)
+			}
+
+			c.Set(ContextKey, t)
+			return next(c)
+		}
+	}
+}
+
+// Token returns the page token stored by Middleware, or nil if the
+// middleware did not run for the request.
+func Token(c echo.Context) *pagetoken.KeysetToken {
+	t, _ := c.Get(ContextKey).(*pagetoken.KeysetToken)
+	return t
+}
+
+// Bind binds the request to req with echo.Context.Bind and reads its page
+// token with reader. It is the counterpart of Middleware for handlers whose
+// request structs implement pagetoken.Request:
+//
+//	func listBooks(c echo.Context) error {
+//	    var req ListBooksRequest
+//	    t, err := pagetokenecho.Bind(c, reader, &req)
+//	    if err != nil {
+//	        return err
+//	    }
+//	    ...
+//	}
+//
+// Token errors are returned as by Error; binding errors are returned as is.
+func Bind(c echo.Context, reader *pagetoken.RequestReader, req pagetoken.Request) (*pagetoken.KeysetToken, error) {
+	if err := c.Bind(req); err != nil {
+		return nil, err
+	}
+
+	t, err := reader.Read(req)
+	if err != nil {
+		return nil, Error(err)
+	}
+
+	return t, nil
+}
+
+// Error maps an error returned by the pagetoken package to an
+// *echo.HTTPError with the status of pagetoken.HTTPStatus. Client errors
+// carry a generic message; the original error is kept as the internal error
+// for logging.
+func Error(err error) *echo.HTTPError {
+	var msg string
+	switch {
+	case pagetoken.HTTPStatus(err) != http.StatusBadRequest:
+		msg = "failed to read page token"
+	case errors.Is(err, pagetoken.ErrInvalidPageSize):
+		msg = "invalid page size"
+	case errors.Is(err, pagetoken.ErrChecksumMismatch):
+		msg = "page token does not match the request parameters"
+	case errors.Is(err, pagetoken.ErrTokenExpired):
+		msg = "page token expired"
+	case errors.Is(err, pagetoken.ErrTokenRevoked):
+		msg = "page token revoked"
+	case errors.Is(err, pagetoken.ErrStaleToken):
+		msg = "page token does not match the current sort order"
+	default:
+		msg = "invalid page token"
+	}
+
+	return echo.NewHTTPError(pagetoken.HTTPStatus(err), msg).SetInternal(err)
+}
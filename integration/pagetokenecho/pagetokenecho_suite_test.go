@@ -0,0 +1,13 @@
+package pagetokenecho_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestPagetokenecho(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Pagetokenecho Suite")
+}
@@ -0,0 +1,229 @@
+package pagetokenecho_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	"github.com/labstack/echo/v4"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/checksum"
+	"github.com/pixlcrashr/go-pagetoken/encryption"
+	"github.com/pixlcrashr/go-pagetoken/integration/pagetokenecho"
+	"github.com/pixlcrashr/go-pagetoken/order"
+)
+
+type listRequest struct {
+	Status    string `query:"status"`
+	PageToken string `query:"page_token"`
+}
+
+func (r *listRequest) GetPageToken() string { return r.PageToken }
+func (r *listRequest) GetChecksumFields() []checksum.BuilderOpt {
+	return []checksum.BuilderOpt{checksum.Field("status", r.Status)}
+}
+
+type listResponse struct {
+	Page          int    `json:"page"`
+	NextPageToken string `json:"next_page_token"`
+}
+
+// respond counts pages in the keyset and always returns a next page token.
+func respond(c echo.Context, t *pagetoken.KeysetToken) error {
+	page, _, err := t.Payload().Int("page")
+	if err != nil {
+		page = 0
+	}
+
+	next, err := t.Next(pagetoken.WithKeysetPayload(
+		pagetoken.NewKeysetPayloadBuilder().AddInt("page", page+1, order.Asc).Build(),
+	)).String()
+	if err != nil {
+		return pagetokenecho.Error(err)
+	}
+
+	return c.JSON(http.StatusOK, listResponse{Page: page, NextPageToken: next})
+}
+
+var _ = Describe("pagetokenecho", func() {
+	var (
+		e      *echo.Echo
+		reader *pagetoken.RequestReader
+	)
+
+	BeforeEach(func() {
+		key, err := encryption.Rand32ByteKey()
+		Expect(err).NotTo(HaveOccurred())
+		enc, err := encryption.NewAEADEncryptor(key)
+		Expect(err).NotTo(HaveOccurred())
+
+		reader = pagetoken.NewRequestReader(pagetoken.WithEncryptor(enc))
+		e = echo.New()
+	})
+
+	get := func(path string, query url.Values) (*httptest.ResponseRecorder, listResponse) {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path+"?"+query.Encode(), nil))
+
+		var resp listResponse
+		if rec.Code == http.StatusOK {
+			Expect(json.Unmarshal(rec.Body.Bytes(), &resp)).To(Succeed())
+		}
+		return rec, resp
+	}
+
+	Describe("Middleware", func() {
+		var called int
+
+		BeforeEach(func() {
+			called = 0
+			e.Use(pagetokenecho.Middleware(reader, pagetokenecho.Config{
+				Routes: map[string]pagetokenecho.ChecksumFieldsFn{
+					"/items": func(c echo.Context) []checksum.BuilderOpt {
+						return []checksum.BuilderOpt{checksum.Field("status", c.QueryParam("status"))}
+					},
+				},
+			}))
+			e.GET("/items", func(c echo.Context) error {
+				called++
+				return respond(c, pagetokenecho.Token(c))
+			})
+			e.GET("/all", func(c echo.Context) error {
+				called++
+				return respond(c, pagetokenecho.Token(c))
+			})
+		})
+
+		It("issues a fresh token when the page token is missing", func() {
+			rec, resp := get("/items", url.Values{"status": {"active"}})
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			Expect(resp.Page).To(BeZero())
+			Expect(resp.NextPageToken).NotTo(BeEmpty())
+		})
+
+		It("accepts a valid token", func() {
+			_, first := get("/items", url.Values{"status": {"active"}})
+
+			rec, resp := get("/items", url.Values{"status": {"active"}, "page_token": {first.NextPageToken}})
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			Expect(resp.Page).To(Equal(1))
+		})
+
+		It("ignores query parameters that are not checksum fields of the route", func() {
+			_, first := get("/items", url.Values{"status": {"active"}})
+
+			rec, _ := get("/items", url.Values{"status": {"active"}, "limit": {"5"}, "page_token": {first.NextPageToken}})
+			Expect(rec.Code).To(Equal(http.StatusOK))
+		})
+
+		It("rejects a tampered token before the handler runs", func() {
+			_, first := get("/items", url.Values{"status": {"active"}})
+			called = 0
+
+			tampered := []byte(first.NextPageToken)
+			tampered[len(tampered)/2] ^= 1
+
+			rec, _ := get("/items", url.Values{"status": {"active"}, "page_token": {string(tampered)}})
+			Expect(rec.Code).To(Equal(http.StatusBadRequest))
+			Expect(rec.Body.String()).To(ContainSubstring("invalid page token"))
+			Expect(called).To(BeZero())
+		})
+
+		It("rejects a token issued for other request parameters", func() {
+			_, first := get("/items", url.Values{"status": {"active"}})
+			called = 0
+
+			rec, _ := get("/items", url.Values{"status": {"archived"}, "page_token": {first.NextPageToken}})
+			Expect(rec.Code).To(Equal(http.StatusBadRequest))
+			Expect(rec.Body.String()).To(ContainSubstring("does not match the request parameters"))
+			Expect(called).To(BeZero())
+		})
+
+		It("builds the checksum from all query parameters of unlisted routes", func() {
+			_, first := get("/all", url.Values{"status": {"active"}})
+
+			rec, _ := get("/all", url.Values{"status": {"active"}, "limit": {"5"}, "page_token": {first.NextPageToken}})
+			Expect(rec.Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	It("reads the token from the configured header", func() {
+		e.GET("/items", func(c echo.Context) error {
+			return respond(c, pagetokenecho.Token(c))
+		}, pagetokenecho.Middleware(reader, pagetokenecho.Config{TokenHeader: "X-Page-Token"}))
+
+		_, first := get("/items", nil)
+
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/items", nil)
+		req.Header.Set("X-Page-Token", first.NextPageToken)
+		e.ServeHTTP(rec, req)
+
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(rec.Body.String()).To(ContainSubstring(`"page":1`))
+	})
+
+	Describe("Bind", func() {
+		BeforeEach(func() {
+			e.GET("/items", func(c echo.Context) error {
+				var req listRequest
+				t, err := pagetokenecho.Bind(c, reader, &req)
+				if err != nil {
+					return err
+				}
+				return respond(c, t)
+			})
+		})
+
+		It("binds the request and reads its token", func() {
+			_, first := get("/items", url.Values{"status": {"active"}})
+
+			rec, resp := get("/items", url.Values{"status": {"active"}, "page_token": {first.NextPageToken}})
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			Expect(resp.Page).To(Equal(1))
+		})
+
+		It("rejects a token issued for other request parameters", func() {
+			_, first := get("/items", url.Values{"status": {"active"}})
+
+			rec, _ := get("/items", url.Values{"status": {"archived"}, "page_token": {first.NextPageToken}})
+			Expect(rec.Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	Describe("Error", func() {
+		It("maps token errors to 400", func() {
+			Expect(pagetokenecho.Error(pagetoken.ErrInvalidToken).Code).To(Equal(http.StatusBadRequest))
+			Expect(pagetokenecho.Error(pagetoken.ErrChecksumMismatch).Code).To(Equal(http.StatusBadRequest))
+		})
+
+		It("maps expired tokens to 400", func() {
+			err := pagetokenecho.Error(fmt.Errorf("read page token: %w", pagetoken.ErrTokenExpired))
+			Expect(err.Code).To(Equal(http.StatusBadRequest))
+			Expect(err.Message).To(Equal("page token expired"))
+		})
+
+		It("maps revoked tokens to 400", func() {
+			err := pagetokenecho.Error(fmt.Errorf("read page token: %w", pagetoken.ErrTokenRevoked))
+			Expect(err.Code).To(Equal(http.StatusBadRequest))
+			Expect(err.Message).To(Equal("page token revoked"))
+		})
+
+		It("maps stale tokens to 400", func() {
+			err := pagetokenecho.Error(fmt.Errorf("read page token: %w", pagetoken.ErrStaleToken))
+			Expect(err.Code).To(Equal(http.StatusBadRequest))
+			Expect(err.Message).To(Equal("page token does not match the current sort order"))
+		})
+
+		It("maps other errors to 500 and keeps them as internal error", func() {
+			err := pagetokenecho.Error(echo.ErrNotFound)
+			Expect(err.Code).To(Equal(http.StatusInternalServerError))
+			Expect(err.Internal).To(Equal(echo.ErrNotFound))
+		})
+	})
+})
@@ -0,0 +1,117 @@
+// Package pagetokenfiber wires page tokens into Fiber applications.
+//
+// ReadToken reads the page token of a request struct inside a handler and
+// returns the pagetoken package's typed errors, which ErrorHandler turns into
+// Fiber errors with the status of pagetoken.HTTPStatus. WritePage writes a
+// JSON envelope with the next page token.
+//
+// The package lives in its own Go module so that depending on the core
+// pagetoken package does not pull in Fiber.
+//
+// # Example
+//
+//	app := fiber.New(fiber.Config{ErrorHandler: pagetokenfiber.ErrorHandler})
+//
+//	app.Get("/books", func(c *fiber.Ctx) error {
+//	    var req ListBooksRequest
+//	    if err := c.QueryParser(&req); err != nil {
+//	        return err
+//	    }
+//	    t, err := pagetokenfiber.ReadToken(c, reader, &req)
+//	    if err != nil {
+//	        return err
+//	    }
+//	    books, next := listBooks(t)
+//	    return pagetokenfiber.WritePage(c, books, next)
+//	})
+package pagetokenfiber
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pixlcrashr/go-pagetoken"
+)
+
+// Page is the response envelope written by WritePage.
+type Page[T any] struct {
+	Items         []T    `json:"items"`
+	NextPageToken string `json:"next_page_token,omitempty"`
+}
+
+type tokenKey struct{}
+
+// ReadToken reads the page token of req with reader and stores it in the
+// locals of c for Token to return. Errors are returned as is, so handlers can
+// return them for ErrorHandler to map.
+func ReadToken(c *fiber.Ctx, reader *pagetoken.RequestReader, req pagetoken.Request) (*pagetoken.KeysetToken, error) {
+	t, err := reader.Read(req)
+	if err != nil {
+		return nil, err
+	}
+
+	c.Locals(tokenKey{}, t)
+	return t, nil
+}
+
+// Token returns the page token read by ReadToken, or nil if none was read
+// for the request.
+func Token(c *fiber.Ctx) *pagetoken.KeysetToken {
+	t, _ := c.Locals(tokenKey{}).(*pagetoken.KeysetToken)
+	return t
+}
+
+// Error maps an error returned by the pagetoken package to a *fiber.Error
+// with the status of pagetoken.HTTPStatus. Client errors carry a generic
+// message, so token internals are not disclosed.
+func Error(err error) *fiber.Error {
+	switch {
+	case pagetoken.HTTPStatus(err) != http.StatusBadRequest:
+		return fiber.NewError(http.StatusInternalServerError, "failed to read page token")
+	case errors.Is(err, pagetoken.ErrInvalidPageSize):
+		return fiber.NewError(http.StatusBadRequest, "invalid page size")
+	case errors.Is(err, pagetoken.ErrChecksumMismatch):
+		return fiber.NewError(http.StatusBadRequest, "page token does not match the request parameters")
+	case errors.Is(err, pagetoken.ErrTokenExpired):
+		return fiber.NewError(http.StatusBadRequest, "page token expired")
+	case errors.Is(err, pagetoken.ErrTokenRevoked):
+		return fiber.NewError(http.StatusBadRequest, "page token revoked")
+	case errors.Is(err, pagetoken.ErrStaleToken):
+		return fiber.NewError(http.StatusBadRequest, "page token does not match the current sort order")
+	default:
+		return fiber.NewError(http.StatusBadRequest, "invalid page token")
+	}
+}
+
+// ErrorHandler is a fiber.ErrorHandler that responds to the errors
+// pagetoken.HTTPStatus maps to 400 with a JSON *fiber.Error as returned by
+// Error. All other errors are passed to fiber.DefaultErrorHandler. Custom
+// error handlers can use Error instead.
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	if pagetoken.HTTPStatus(err) == http.StatusBadRequest {
+		fe := Error(err)
+		return c.Status(fe.Code).JSON(fe)
+	}
+
+	return fiber.DefaultErrorHandler(c, err)
+}
+
+// WritePage writes items as a JSON Page. next is the token of the next page,
+// or nil on the last page.
+func WritePage[T any](c *fiber.Ctx, items []T, next *pagetoken.KeysetToken) error {
+	if items == nil {
+		items = []T{}
+	}
+	p := Page[T]{Items: items}
+
+	if next != nil {
+		s, err := next.String()
+		if err != nil {
+			return err
+		}
+		p.NextPageToken = s
+	}
+
+	return c.JSON(p)
+}
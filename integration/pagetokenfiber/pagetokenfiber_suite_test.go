@@ -0,0 +1,13 @@
+package pagetokenfiber_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestPagetokenfiber(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Pagetokenfiber Suite")
+}
@@ -0,0 +1,201 @@
+package pagetokenfiber_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	"github.com/gofiber/fiber/v2"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/checksum"
+	"github.com/pixlcrashr/go-pagetoken/encryption"
+	"github.com/pixlcrashr/go-pagetoken/integration/pagetokenfiber"
+	"github.com/pixlcrashr/go-pagetoken/order"
+)
+
+type book struct {
+	ID     int    `json:"id"`
+	Status string `json:"status"`
+}
+
+var books = []book{
+	{1, "active"}, {2, "archived"}, {3, "active"}, {4, "active"},
+	{5, "active"}, {6, "archived"}, {7, "active"},
+}
+
+type listRequest struct {
+	Status    string `query:"status"`
+	PageToken string `query:"page_token"`
+}
+
+func (r *listRequest) GetPageToken() string { return r.PageToken }
+func (r *listRequest) GetChecksumFields() []checksum.BuilderOpt {
+	return []checksum.BuilderOpt{checksum.Field("status", r.Status)}
+}
+
+const pageSize = 3
+
+// listBooks returns the page of books with the status of req following the
+// keyset of t, and the token of the next page if there is one.
+func listBooks(req *listRequest, t *pagetoken.KeysetToken) ([]book, *pagetoken.KeysetToken) {
+	after, _, err := t.Payload().Int("id")
+	if err != nil {
+		after = 0
+	}
+
+	page := []book{}
+	for _, b := range books {
+		if b.ID > after && b.Status == req.Status {
+			page = append(page, b)
+		}
+	}
+
+	if len(page) <= pageSize {
+		return page, nil
+	}
+
+	page = page[:pageSize]
+	return page, t.Next(pagetoken.WithKeysetPayload(
+		pagetoken.NewKeysetPayloadBuilder().AddInt("id", page[len(page)-1].ID, order.Asc).Build(),
+	))
+}
+
+var _ = Describe("pagetokenfiber", func() {
+	var app *fiber.App
+
+	BeforeEach(func() {
+		key, err := encryption.Rand32ByteKey()
+		Expect(err).NotTo(HaveOccurred())
+		e, err := encryption.NewAEADEncryptor(key)
+		Expect(err).NotTo(HaveOccurred())
+		reader := pagetoken.NewRequestReader(pagetoken.WithEncryptor(e))
+
+		app = fiber.New(fiber.Config{ErrorHandler: pagetokenfiber.ErrorHandler})
+		app.Get("/books", func(c *fiber.Ctx) error {
+			var req listRequest
+			if err := c.QueryParser(&req); err != nil {
+				return err
+			}
+
+			t, err := pagetokenfiber.ReadToken(c, reader, &req)
+			if err != nil {
+				return err
+			}
+			Expect(pagetokenfiber.Token(c)).To(BeIdenticalTo(t))
+
+			page, next := listBooks(&req, t)
+			return pagetokenfiber.WritePage(c, page, next)
+		})
+	})
+
+	get := func(query url.Values) (int, []byte) {
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/books?"+query.Encode(), nil))
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		Expect(err).NotTo(HaveOccurred())
+		return resp.StatusCode, body
+	}
+
+	getPage := func(query url.Values) pagetokenfiber.Page[book] {
+		status, body := get(query)
+		Expect(status).To(Equal(http.StatusOK), string(body))
+
+		var p pagetokenfiber.Page[book]
+		Expect(json.Unmarshal(body, &p)).To(Succeed())
+		return p
+	}
+
+	It("walks two pages", func() {
+		first := getPage(url.Values{"status": {"active"}})
+		Expect(first.Items).To(Equal([]book{{1, "active"}, {3, "active"}, {4, "active"}}))
+		Expect(first.NextPageToken).NotTo(BeEmpty())
+
+		second := getPage(url.Values{"status": {"active"}, "page_token": {first.NextPageToken}})
+		Expect(second.Items).To(Equal([]book{{5, "active"}, {7, "active"}}))
+		Expect(second.NextPageToken).To(BeEmpty())
+	})
+
+	It("responds to a tampered token with a 400 fiber error", func() {
+		first := getPage(url.Values{"status": {"active"}})
+		s := []byte(first.NextPageToken)
+		s[len(s)/2] ^= 0x01
+
+		status, body := get(url.Values{"status": {"active"}, "page_token": {string(s)}})
+		Expect(status).To(Equal(http.StatusBadRequest))
+
+		var fe fiber.Error
+		Expect(json.Unmarshal(body, &fe)).To(Succeed())
+		Expect(fe).To(Equal(fiber.Error{Code: http.StatusBadRequest, Message: "invalid page token"}))
+	})
+
+	It("responds to a checksum mismatch with a 400 fiber error", func() {
+		first := getPage(url.Values{"status": {"active"}})
+
+		status, body := get(url.Values{"status": {"archived"}, "page_token": {first.NextPageToken}})
+		Expect(status).To(Equal(http.StatusBadRequest))
+		Expect(string(body)).To(ContainSubstring("does not match the request parameters"))
+	})
+
+	It("responds to an expired token with a 400 fiber error", func() {
+		app.Get("/expired", func(c *fiber.Ctx) error {
+			return fmt.Errorf("read page token: %w", pagetoken.ErrTokenExpired)
+		})
+
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/expired", nil))
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+		Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+
+		var fe fiber.Error
+		Expect(json.NewDecoder(resp.Body).Decode(&fe)).To(Succeed())
+		Expect(fe).To(Equal(fiber.Error{Code: http.StatusBadRequest, Message: "page token expired"}))
+	})
+
+	It("responds to a revoked token with a 400 fiber error", func() {
+		app.Get("/revoked", func(c *fiber.Ctx) error {
+			return fmt.Errorf("read page token: %w", pagetoken.ErrTokenRevoked)
+		})
+
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/revoked", nil))
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+		Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+
+		var fe fiber.Error
+		Expect(json.NewDecoder(resp.Body).Decode(&fe)).To(Succeed())
+		Expect(fe).To(Equal(fiber.Error{Code: http.StatusBadRequest, Message: "page token revoked"}))
+	})
+
+	It("responds to a stale token with a 400 fiber error", func() {
+		app.Get("/stale", func(c *fiber.Ctx) error {
+			return fmt.Errorf("read page token: %w", pagetoken.ErrStaleToken)
+		})
+
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/stale", nil))
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+		Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+
+		var fe fiber.Error
+		Expect(json.NewDecoder(resp.Body).Decode(&fe)).To(Succeed())
+		Expect(fe).To(Equal(fiber.Error{Code: http.StatusBadRequest, Message: "page token does not match the current sort order"}))
+	})
+
+	It("leaves other errors to the default error handler", func() {
+		app.Get("/missing", func(c *fiber.Ctx) error {
+			return fiber.ErrNotFound
+		})
+
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/missing", nil))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+	})
+})
@@ -0,0 +1,186 @@
+// Package pagetokengin wires page tokens into Gin applications.
+//
+// MustToken reads the page token of a request struct inside a handler,
+// Middleware does the same for plain query parameters before the handler
+// runs. Both abort the request with a structured 400 JSON body on token
+// errors and store the token in the gin.Context, where TokenFrom retrieves
+// it. WritePage writes a response envelope with the next and previous page
+// tokens.
+//
+// The package lives in its own Go module so that depending on the core
+// pagetoken package does not pull in Gin.
+//
+// # Example
+//
+//	r.GET("/books", func(c *gin.Context) {
+//	    var req ListBooksRequest
+//	    if err := c.ShouldBindQuery(&req); err != nil {
+//	        ...
+//	    }
+//	    t := pagetokengin.MustToken(c, reader, &req)
+//	    if t == nil {
+//	        return
+//	    }
+//	    books, next := listBooks(t)
+//	    pagetokengin.WritePage(c, books, next, nil)
+//	})
+package pagetokengin
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pixlcrashr/go-pagetoken"
+)
+
+// Error codes of ErrorBody.
+const (
+	CodeInvalidPageToken  = "invalid_page_token"
+	CodeChecksumMismatch  = "page_token_mismatch"
+	CodeInvalidPageSize   = "invalid_page_size"
+	CodeTokenExpired      = "page_token_expired"
+	CodeTokenRevoked      = "page_token_revoked"
+	CodeStaleToken        = "page_token_stale"
+	CodePageTokenInternal = "page_token_internal"
+)
+
+// ErrorResponse is the JSON body requests are aborted with on token errors:
+//
+//	{"error":{"code":"invalid_page_token","message":"invalid page token"}}
+type ErrorResponse struct {
+	Error ErrorBody `json:"error"`
+}
+
+// ErrorBody describes a token error.
+type ErrorBody struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Page is the response envelope written by WritePage.
+type Page[T any] struct {
+	Items         []T    `json:"items"`
+	NextPageToken string `json:"next_page_token,omitempty"`
+	PrevPageToken string `json:"prev_page_token,omitempty"`
+}
+
+type tokenKey struct{}
+
+// MustToken reads the page token of req with reader and stores it in c for
+// TokenFrom to return. If the token is invalid or does not match req, the
+// request is aborted with an ErrorResponse and MustToken returns nil; the
+// handler must return without writing a response then.
+func MustToken(c *gin.Context, reader *pagetoken.RequestReader, req pagetoken.Request) *pagetoken.KeysetToken {
+	t, err := reader.Read(req)
+	if err != nil {
+		Abort(c, err)
+		return nil
+	}
+
+	c.Set(tokenKey{}, t)
+	return t
+}
+
+// Middleware returns a handler that reads the page token from the query of
+// each request, as configured by opts, and stores it in the context for
+// TokenFrom to return. Without options, the token is read from the
+// page_token parameter and the checksum is built from all other query
+// parameters. Requests with invalid or mismatching tokens are aborted with
+// an ErrorResponse, so later handlers are not called.
+func Middleware(reader *pagetoken.RequestReader, opts ...pagetoken.HTTPRequestOpt) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if MustToken(c, reader, pagetoken.NewHTTPRequest(c.Request, opts...)) == nil {
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// TokenFrom returns the page token stored by MustToken or Middleware.
+func TokenFrom(c *gin.Context) (*pagetoken.KeysetToken, bool) {
+	v, ok := c.Get(tokenKey{})
+	if !ok {
+		return nil, false
+	}
+
+	t, ok := v.(*pagetoken.KeysetToken)
+	return t, ok
+}
+
+// Abort aborts the request with the status of pagetoken.HTTPStatus and an
+// ErrorResponse describing err. Errors it maps to 400 are described by a
+// generic message, all others by CodePageTokenInternal. The original error
+// is attached to c for logging.
+func Abort(c *gin.Context, err error) {
+	status := pagetoken.HTTPStatus(err)
+
+	body := ErrorBody{
+		Code:    CodePageTokenInternal,
+		Message: "failed to read page token",
+	}
+	if status == http.StatusBadRequest {
+		switch {
+		case errors.Is(err, pagetoken.ErrInvalidPageSize):
+			body = ErrorBody{
+				Code:    CodeInvalidPageSize,
+				Message: "invalid page size",
+			}
+		case errors.Is(err, pagetoken.ErrChecksumMismatch):
+			body = ErrorBody{
+				Code:    CodeChecksumMismatch,
+				Message: "page token does not match the request parameters",
+			}
+		case errors.Is(err, pagetoken.ErrTokenExpired):
+			body = ErrorBody{
+				Code:    CodeTokenExpired,
+				Message: "page token expired",
+			}
+		case errors.Is(err, pagetoken.ErrTokenRevoked):
+			body = ErrorBody{
+				Code:    CodeTokenRevoked,
+				Message: "page token revoked",
+			}
+		case errors.Is(err, pagetoken.ErrStaleToken):
+			body = ErrorBody{
+				Code:    CodeStaleToken,
+				Message: "page token does not match the current sort order",
+			}
+		default:
+			body = ErrorBody{
+				Code:    CodeInvalidPageToken,
+				Message: "invalid page token",
+			}
+		}
+	}
+
+	_ = c.Error(err)
+	c.AbortWithStatusJSON(status, ErrorResponse{Error: body})
+}
+
+// WritePage writes items as a Page with status 200. next and prev are the
+// tokens of the adjacent pages, or nil if there are none. If a token cannot
+// be serialized, the request is aborted as by Abort instead.
+func WritePage[T any](c *gin.Context, items []T, next, prev *pagetoken.KeysetToken) {
+	if items == nil {
+		items = []T{}
+	}
+	p := Page[T]{Items: items}
+
+	var err error
+	if next != nil {
+		if p.NextPageToken, err = next.String(); err != nil {
+			Abort(c, err)
+			return
+		}
+	}
+	if prev != nil {
+		if p.PrevPageToken, err = prev.String(); err != nil {
+			Abort(c, err)
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, p)
+}
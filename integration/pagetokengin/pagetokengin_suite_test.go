@@ -0,0 +1,13 @@
+package pagetokengin_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestPagetokengin(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Pagetokengin Suite")
+}
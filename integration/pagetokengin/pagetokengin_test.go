@@ -0,0 +1,232 @@
+package pagetokengin_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/checksum"
+	"github.com/pixlcrashr/go-pagetoken/encryption"
+	"github.com/pixlcrashr/go-pagetoken/integration/pagetokengin"
+	"github.com/pixlcrashr/go-pagetoken/order"
+)
+
+type listRequest struct {
+	Status    string `form:"status"`
+	PageToken string `form:"page_token"`
+}
+
+func (r *listRequest) GetPageToken() string { return r.PageToken }
+func (r *listRequest) GetChecksumFields() []checksum.BuilderOpt {
+	return []checksum.BuilderOpt{checksum.Field("status", r.Status)}
+}
+
+// respond counts pages in the keyset and always returns a next page token.
+func respond(c *gin.Context, t *pagetoken.KeysetToken) {
+	page, _, err := t.Payload().Int("page")
+	if err != nil {
+		page = 0
+	}
+
+	next := t.Next(pagetoken.WithKeysetPayload(
+		pagetoken.NewKeysetPayloadBuilder().AddInt("page", page+1, order.Asc).Build(),
+	))
+	pagetokengin.WritePage(c, []int{page}, next, nil)
+}
+
+var _ = Describe("pagetokengin", func() {
+	var (
+		router *gin.Engine
+		reader *pagetoken.RequestReader
+		called int
+	)
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+
+		key, err := encryption.Rand32ByteKey()
+		Expect(err).NotTo(HaveOccurred())
+		e, err := encryption.NewAEADEncryptor(key)
+		Expect(err).NotTo(HaveOccurred())
+
+		reader = pagetoken.NewRequestReader(pagetoken.WithEncryptor(e))
+		router = gin.New()
+		called = 0
+	})
+
+	get := func(path string, query url.Values) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path+"?"+query.Encode(), nil))
+		return rec
+	}
+
+	page := func(rec *httptest.ResponseRecorder) pagetokengin.Page[int] {
+		var p pagetokengin.Page[int]
+		Expect(json.Unmarshal(rec.Body.Bytes(), &p)).To(Succeed())
+		return p
+	}
+
+	errorBody := func(rec *httptest.ResponseRecorder) pagetokengin.ErrorBody {
+		var resp pagetokengin.ErrorResponse
+		Expect(json.Unmarshal(rec.Body.Bytes(), &resp)).To(Succeed())
+		return resp.Error
+	}
+
+	tamper := func(s string) string {
+		bs := []byte(s)
+		bs[len(bs)/2] ^= 1
+		return string(bs)
+	}
+
+	Describe("MustToken", func() {
+		BeforeEach(func() {
+			router.GET("/items", func(c *gin.Context) {
+				var req listRequest
+				Expect(c.ShouldBindQuery(&req)).To(Succeed())
+
+				t := pagetokengin.MustToken(c, reader, &req)
+				if t == nil {
+					return
+				}
+				called++
+
+				stored, ok := pagetokengin.TokenFrom(c)
+				Expect(ok).To(BeTrue())
+				Expect(stored).To(BeIdenticalTo(t))
+
+				respond(c, t)
+			})
+		})
+
+		It("walks pages with the tokens of the envelope", func() {
+			rec := get("/items", url.Values{"status": {"active"}})
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			first := page(rec)
+			Expect(first.Items).To(Equal([]int{0}))
+			Expect(first.NextPageToken).NotTo(BeEmpty())
+			Expect(first.PrevPageToken).To(BeEmpty())
+
+			rec = get("/items", url.Values{"status": {"active"}, "page_token": {first.NextPageToken}})
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			Expect(page(rec).Items).To(Equal([]int{1}))
+		})
+
+		It("aborts with a structured body on a tampered token", func() {
+			first := page(get("/items", url.Values{"status": {"active"}}))
+			called = 0
+
+			rec := get("/items", url.Values{"status": {"active"}, "page_token": {tamper(first.NextPageToken)}})
+			Expect(rec.Code).To(Equal(http.StatusBadRequest))
+			Expect(errorBody(rec).Code).To(Equal(pagetokengin.CodeInvalidPageToken))
+			Expect(called).To(BeZero())
+		})
+
+		It("aborts with a structured body on a checksum mismatch", func() {
+			first := page(get("/items", url.Values{"status": {"active"}}))
+			called = 0
+
+			rec := get("/items", url.Values{"status": {"archived"}, "page_token": {first.NextPageToken}})
+			Expect(rec.Code).To(Equal(http.StatusBadRequest))
+			Expect(errorBody(rec).Code).To(Equal(pagetokengin.CodeChecksumMismatch))
+			Expect(called).To(BeZero())
+		})
+	})
+
+	Describe("Middleware", func() {
+		BeforeEach(func() {
+			router.GET("/items", pagetokengin.Middleware(reader, pagetoken.WithExcludedParams("limit")), func(c *gin.Context) {
+				called++
+
+				t, ok := pagetokengin.TokenFrom(c)
+				Expect(ok).To(BeTrue())
+				respond(c, t)
+			})
+		})
+
+		It("stores the token for later handlers", func() {
+			first := page(get("/items", url.Values{"status": {"active"}}))
+
+			rec := get("/items", url.Values{"status": {"active"}, "limit": {"5"}, "page_token": {first.NextPageToken}})
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			Expect(page(rec).Items).To(Equal([]int{1}))
+		})
+
+		It("aborts before later handlers on a tampered token", func() {
+			first := page(get("/items", url.Values{"status": {"active"}}))
+			called = 0
+
+			rec := get("/items", url.Values{"status": {"active"}, "page_token": {tamper(first.NextPageToken)}})
+			Expect(rec.Code).To(Equal(http.StatusBadRequest))
+			Expect(errorBody(rec).Code).To(Equal(pagetokengin.CodeInvalidPageToken))
+			Expect(called).To(BeZero())
+		})
+
+		It("aborts before later handlers on a checksum mismatch", func() {
+			first := page(get("/items", url.Values{"status": {"active"}}))
+			called = 0
+
+			rec := get("/items", url.Values{"status": {"archived"}, "page_token": {first.NextPageToken}})
+			Expect(rec.Code).To(Equal(http.StatusBadRequest))
+			Expect(errorBody(rec).Code).To(Equal(pagetokengin.CodeChecksumMismatch))
+			Expect(called).To(BeZero())
+		})
+	})
+
+	It("has no token in contexts without middleware", func() {
+		router.GET("/plain", func(c *gin.Context) {
+			_, ok := pagetokengin.TokenFrom(c)
+			Expect(ok).To(BeFalse())
+			c.Status(http.StatusNoContent)
+		})
+
+		Expect(get("/plain", nil).Code).To(Equal(http.StatusNoContent))
+	})
+
+	It("aborts with a structured body on an expired token", func() {
+		router.GET("/expired", func(c *gin.Context) {
+			pagetokengin.Abort(c, fmt.Errorf("read page token: %w", pagetoken.ErrTokenExpired))
+		})
+
+		rec := get("/expired", nil)
+		Expect(rec.Code).To(Equal(http.StatusBadRequest))
+		Expect(errorBody(rec)).To(Equal(pagetokengin.ErrorBody{Code: pagetokengin.CodeTokenExpired, Message: "page token expired"}))
+	})
+
+	It("aborts with a structured body on a revoked token", func() {
+		router.GET("/revoked", func(c *gin.Context) {
+			pagetokengin.Abort(c, fmt.Errorf("read page token: %w", pagetoken.ErrTokenRevoked))
+		})
+
+		rec := get("/revoked", nil)
+		Expect(rec.Code).To(Equal(http.StatusBadRequest))
+		Expect(errorBody(rec)).To(Equal(pagetokengin.ErrorBody{Code: pagetokengin.CodeTokenRevoked, Message: "page token revoked"}))
+	})
+
+	It("aborts with a structured body on a stale token", func() {
+		router.GET("/stale", func(c *gin.Context) {
+			pagetokengin.Abort(c, fmt.Errorf("read page token: %w", pagetoken.ErrStaleToken))
+		})
+
+		rec := get("/stale", nil)
+		Expect(rec.Code).To(Equal(http.StatusBadRequest))
+		Expect(errorBody(rec)).To(Equal(pagetokengin.ErrorBody{Code: pagetokengin.CodeStaleToken, Message: "page token does not match the current sort order"}))
+	})
+
+	It("aborts with 500 on other errors", func() {
+		router.GET("/fail", func(c *gin.Context) {
+			pagetokengin.Abort(c, errors.New("boom"))
+		})
+
+		rec := get("/fail", nil)
+		Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+		Expect(errorBody(rec).Code).To(Equal(pagetokengin.CodePageTokenInternal))
+	})
+})
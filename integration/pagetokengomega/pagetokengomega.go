@@ -0,0 +1,111 @@
+// Package pagetokengomega provides Gomega matchers asserting on page tokens
+// created with pagetokentest.StaticCrypter:
+//
+//	Expect(resp.NextPageToken).To(pagetokengomega.HaveKeysetField("id", "52", order.Asc))
+//
+// The matchers wrap pagetokentest.HasKeysetField and
+// pagetokentest.Equivalent. The package lives in its own Go module so that
+// depending on pagetokentest does not pull in Gomega.
+package pagetokengomega
+
+import (
+	"fmt"
+
+	"github.com/onsi/gomega/format"
+	"github.com/onsi/gomega/types"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/order"
+	"github.com/pixlcrashr/go-pagetoken/pagetokentest"
+)
+
+// token returns the token matched by a matcher, which is either a
+// *pagetoken.KeysetToken or a token string created with StaticCrypter.
+func token(actual any) (*pagetoken.KeysetToken, error) {
+	switch t := actual.(type) {
+	case *pagetoken.KeysetToken:
+		if t == nil {
+			return nil, fmt.Errorf("expected a page token, got nil")
+		}
+		return t, nil
+	case string:
+		return pagetoken.NewKeysetTokenParser(
+			pagetoken.WithKeysetTokenEncryptor(pagetokentest.StaticCrypter{}),
+		).Parse(t)
+	default:
+		return nil, fmt.Errorf("expected a *pagetoken.KeysetToken or a token string, got\n%s", format.Object(actual, 1))
+	}
+}
+
+type keysetFieldMatcher struct {
+	field pagetoken.KeysetValue
+}
+
+// HaveKeysetField succeeds if the keyset of the actual token carries the
+// field path with the given value and order. The actual value is either a
+// *pagetoken.KeysetToken or a token string created with StaticCrypter.
+func HaveKeysetField(path, value string, o order.Order) types.GomegaMatcher {
+	return &keysetFieldMatcher{field: pagetoken.KeysetValue{Path: path, Value: value, Order: o}}
+}
+
+func (m *keysetFieldMatcher) Match(actual any) (bool, error) {
+	t, err := token(actual)
+	if err != nil {
+		return false, err
+	}
+
+	return pagetokentest.HasKeysetField(t, m.field.Path, m.field.Value, m.field.Order), nil
+}
+
+func (m *keysetFieldMatcher) FailureMessage(actual any) string {
+	return format.Message(keyset(actual), "to have keyset field", m.field)
+}
+
+func (m *keysetFieldMatcher) NegatedFailureMessage(actual any) string {
+	return format.Message(keyset(actual), "not to have keyset field", m.field)
+}
+
+type equivalentTokenMatcher struct {
+	expected any
+}
+
+// BeEquivalentToken succeeds if the actual token is equivalent to expected,
+// see pagetokentest.Equivalent.
+// Both values are either a *pagetoken.KeysetToken or a token string created
+// with StaticCrypter.
+func BeEquivalentToken(expected any) types.GomegaMatcher {
+	return &equivalentTokenMatcher{expected: expected}
+}
+
+func (m *equivalentTokenMatcher) Match(actual any) (bool, error) {
+	a, err := token(actual)
+	if err != nil {
+		return false, err
+	}
+
+	e, err := token(m.expected)
+	if err != nil {
+		return false, err
+	}
+
+	return pagetokentest.Equivalent(a, e), nil
+}
+
+func (m *equivalentTokenMatcher) FailureMessage(actual any) string {
+	return format.Message(actual, "to be equivalent to", m.expected)
+}
+
+func (m *equivalentTokenMatcher) NegatedFailureMessage(actual any) string {
+	return format.Message(actual, "not to be equivalent to", m.expected)
+}
+
+// keyset returns the keyset of the actual token for failure messages, or
+// actual itself if it is no token.
+func keyset(actual any) any {
+	t, err := token(actual)
+	if err != nil {
+		return actual
+	}
+
+	return t.Payload().Values()
+}
@@ -0,0 +1,13 @@
+package pagetokengomega_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestPagetokengomega(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Pagetokengomega Suite")
+}
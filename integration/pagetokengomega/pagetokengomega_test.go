@@ -0,0 +1,54 @@
+package pagetokengomega_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/integration/pagetokengomega"
+	"github.com/pixlcrashr/go-pagetoken/order"
+	"github.com/pixlcrashr/go-pagetoken/pagetokentest"
+)
+
+var id42 = pagetoken.KeysetValue{Path: "id", Value: "42", Order: order.Asc}
+
+var _ = Describe("HaveKeysetField", func() {
+	It("matches keyset fields of tokens and token strings", func() {
+		s := pagetokentest.MustToken(GinkgoT(), id42)
+
+		Expect(s).To(pagetokengomega.HaveKeysetField("id", "42", order.Asc))
+		Expect(s).NotTo(pagetokengomega.HaveKeysetField("id", "42", order.Desc))
+		Expect(pagetokentest.ParseForTest(GinkgoT(), s)).NotTo(pagetokengomega.HaveKeysetField("name", "42", order.Asc))
+	})
+
+	It("fails on values that are no tokens", func() {
+		_, err := pagetokengomega.HaveKeysetField("id", "42", order.Asc).Match(42)
+		Expect(err).To(HaveOccurred())
+
+		_, err = pagetokengomega.HaveKeysetField("id", "42", order.Asc).Match("not a token")
+		Expect(err).To(MatchError(pagetoken.ErrInvalidToken))
+	})
+})
+
+var _ = Describe("BeEquivalentToken", func() {
+	It("considers a token equivalent to its parsed string", func() {
+		t := pagetokentest.ParseForTest(GinkgoT(), pagetokentest.MustToken(GinkgoT(), id42))
+		t.SetTotalCount(7)
+		t.SetUpstreamToken("upstream")
+
+		s, err := t.String()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s).To(pagetokengomega.BeEquivalentToken(t))
+	})
+
+	DescribeTable("tells tokens apart",
+		func(opts ...pagetoken.KeysetTokenOpt) {
+			t := pagetokentest.ParseForTest(GinkgoT(), pagetokentest.MustToken(GinkgoT(), id42))
+			Expect(t.Next()).NotTo(pagetokengomega.BeEquivalentToken(t))
+			Expect(t.Next(opts...)).NotTo(pagetokengomega.BeEquivalentToken(t.Next()))
+		},
+		Entry("by total count", pagetoken.WithTotalCount(1)),
+		Entry("by upstream token", pagetoken.WithUpstreamToken("")),
+		Entry("by keyset", pagetoken.WithKeysetPayload(pagetokentest.Payload())),
+	)
+})
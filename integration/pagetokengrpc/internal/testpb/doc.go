@@ -0,0 +1,5 @@
+// Package testpb contains the messages and service of books.proto, a toy
+// AIP-158 list service used by the tests of pagetokengrpc.
+//
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative books.proto
+package testpb
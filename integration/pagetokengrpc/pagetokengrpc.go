@@ -0,0 +1,281 @@
+// Package pagetokengrpc wires page tokens into gRPC services following
+// AIP-158 (https://google.aip.dev/158), whose list requests carry page_size
+// and page_token fields and whose responses carry next_page_token.
+//
+// NewRequest adapts such a request to pagetoken.Request, with the checksum
+// built from its fields. UnaryServerInterceptor reads and validates the page
+// token of every list request before the handler runs and stores it in the
+// context, where handlers retrieve it with pagetoken.FromContext. Token
+// errors are mapped to codes.InvalidArgument with structured details.
+//
+// The package lives in its own Go module so that depending on the core
+// pagetoken package does not pull in gRPC.
+//
+// # Example
+//
+//	srv := grpc.NewServer(grpc.UnaryInterceptor(pagetokengrpc.UnaryServerInterceptor(reader,
+//	    pagetokengrpc.WithMethod(booksv1.BookService_ListBooks_FullMethodName, pagetokengrpc.FieldMask("filter", "order_by")),
+//	)))
+//
+//	func (s *server) ListBooks(ctx context.Context, req *booksv1.ListBooksRequest) (*booksv1.ListBooksResponse, error) {
+//	    t, _ := pagetoken.FromContext(ctx)
+//	    ...
+//	}
+package pagetokengrpc
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/checksum"
+)
+
+// ErrorDomain is the domain of the errdetails.ErrorInfo attached to token
+// errors.
+const ErrorDomain = "pagetoken"
+
+// Reasons of the errdetails.ErrorInfo attached to token errors.
+const (
+	ReasonInvalidPageToken = "INVALID_PAGE_TOKEN"
+	ReasonChecksumMismatch = "PAGE_TOKEN_MISMATCH"
+	ReasonInvalidPageSize  = "INVALID_PAGE_SIZE"
+	ReasonTokenExpired     = "PAGE_TOKEN_EXPIRED"
+	ReasonTokenRevoked     = "PAGE_TOKEN_REVOKED"
+	ReasonStaleToken       = "PAGE_TOKEN_STALE"
+)
+
+// PageRequest is implemented by AIP-158 list requests generated by
+// protoc-gen-go.
+type PageRequest interface {
+	proto.Message
+	GetPageToken() string
+	GetPageSize() int32
+}
+
+// ChecksumFieldsFn returns the checksum fields of a request.
+type ChecksumFieldsFn func(req proto.Message) ([]checksum.BuilderOpt, error)
+
+// FieldMask returns a ChecksumFieldsFn building the checksum from the named
+// top-level fields of the request. Fields that are not set contribute no
+// checksum field. Naming a field the request does not have is an error.
+func FieldMask(names ...string) ChecksumFieldsFn {
+	return func(req proto.Message) ([]checksum.BuilderOpt, error) {
+		m := req.ProtoReflect()
+		fds := m.Descriptor().Fields()
+
+		fields := []checksum.BuilderOpt{}
+		for _, name := range names {
+			fd := fds.ByName(protoreflect.Name(name))
+			if fd == nil {
+				return nil, fmt.Errorf("%s has no field %q", m.Descriptor().FullName(), name)
+			}
+
+			f, ok, err := field(m, fd)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				fields = append(fields, f)
+			}
+		}
+
+		return fields, nil
+	}
+}
+
+// AllFields is a ChecksumFieldsFn building the checksum from all fields of
+// the request except page_token and page_size, so that clients may change
+// the page size between pages as AIP-158 allows.
+func AllFields(req proto.Message) ([]checksum.BuilderOpt, error) {
+	m := req.ProtoReflect()
+	fds := m.Descriptor().Fields()
+
+	fields := []checksum.BuilderOpt{}
+	for i := 0; i < fds.Len(); i++ {
+		fd := fds.Get(i)
+		if fd.Name() == "page_token" || fd.Name() == "page_size" {
+			continue
+		}
+
+		f, ok, err := field(m, fd)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			fields = append(fields, f)
+		}
+	}
+
+	return fields, nil
+}
+
+// field encodes the value of fd in m as checksum field. The value is the
+// deterministic wire encoding of a message holding only that field, which
+// covers scalar, repeated, map and message fields alike.
+func field(m protoreflect.Message, fd protoreflect.FieldDescriptor) (checksum.BuilderOpt, bool, error) {
+	if !m.Has(fd) {
+		return nil, false, nil
+	}
+
+	only := m.Type().New()
+	only.Set(fd, m.Get(fd))
+
+	bs, err := proto.MarshalOptions{Deterministic: true}.Marshal(only.Interface())
+	if err != nil {
+		return nil, false, err
+	}
+
+	return checksum.Field(string(fd.Name()), base64.RawStdEncoding.EncodeToString(bs)), true, nil
+}
+
+type request struct {
+	token  string
+	size   int
+	fields []checksum.BuilderOpt
+}
+
+func (r *request) GetPageToken() string                     { return r.token }
+func (r *request) GetPageSize() int                         { return r.size }
+func (r *request) GetChecksumFields() []checksum.BuilderOpt { return r.fields }
+
+// NewRequest adapts req to pagetoken.Request, with the checksum built by
+// fields. If fields is nil, AllFields is used. The returned request also
+// implements pagetoken.PageSizer for readers created with
+// pagetoken.WithAIP158.
+func NewRequest(req PageRequest, fields ChecksumFieldsFn) (pagetoken.Request, error) {
+	if fields == nil {
+		fields = AllFields
+	}
+
+	fs, err := fields(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &request{token: req.GetPageToken(), size: int(req.GetPageSize()), fields: fs}, nil
+}
+
+type interceptor struct {
+	reader   *pagetoken.RequestReader
+	methods  map[string]ChecksumFieldsFn
+	fallback ChecksumFieldsFn
+}
+
+type InterceptorOpt func(*interceptor)
+
+// WithMethod sets the checksum fields of the method with the given full
+// name, e.g. "/books.v1.BookService/ListBooks".
+func WithMethod(fullMethod string, fields ChecksumFieldsFn) InterceptorOpt {
+	return func(i *interceptor) {
+		i.methods[fullMethod] = fields
+	}
+}
+
+// WithDefaultChecksumFields sets the checksum fields of methods without
+// WithMethod. The default is AllFields.
+func WithDefaultChecksumFields(fields ChecksumFieldsFn) InterceptorOpt {
+	return func(i *interceptor) {
+		i.fallback = fields
+	}
+}
+
+// UnaryServerInterceptor returns an interceptor that reads the page token of
+// every request implementing PageRequest with reader and stores it in the
+// context for pagetoken.FromContext to return. Requests without a page token
+// get a fresh token carrying their checksum. Invalid or mismatching tokens
+// are rejected with the status returned by Status, so the handler is not
+// called. Other requests are passed through unchanged.
+func UnaryServerInterceptor(reader *pagetoken.RequestReader, opts ...InterceptorOpt) grpc.UnaryServerInterceptor {
+	i := &interceptor{
+		reader:   reader,
+		methods:  map[string]ChecksumFieldsFn{},
+		fallback: AllFields,
+	}
+	for _, opt := range opts {
+		opt(i)
+	}
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		pr, ok := req.(PageRequest)
+		if !ok {
+			return handler(ctx, req)
+		}
+
+		fields, ok := i.methods[info.FullMethod]
+		if !ok {
+			fields = i.fallback
+		}
+
+		r, err := NewRequest(pr, fields)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to build page token checksum: %v", err)
+		}
+
+		t, err := i.reader.Read(r)
+		if err != nil {
+			return nil, Status(err).Err()
+		}
+
+		return handler(pagetoken.NewContext(ctx, t), req)
+	}
+}
+
+// Status maps an error returned by the pagetoken package to a gRPC status.
+// Errors pagetoken.HTTPStatus maps to 400, i.e. token and page size errors,
+// become codes.InvalidArgument with an errdetails.BadRequest naming the
+// offending field and an errdetails.ErrorInfo with a machine-readable
+// reason; all other errors become codes.Internal.
+func Status(err error) *status.Status {
+	if pagetoken.HTTPStatus(err) != http.StatusBadRequest {
+		return status.New(codes.Internal, "failed to read page token")
+	}
+
+	reason, desc, fieldName := ReasonInvalidPageToken, "invalid page token", "page_token"
+	switch {
+	case errors.Is(err, pagetoken.ErrInvalidPageSize):
+		reason = ReasonInvalidPageSize
+		desc = "page size must not be negative"
+		fieldName = "page_size"
+	case errors.Is(err, pagetoken.ErrChecksumMismatch):
+		reason = ReasonChecksumMismatch
+		desc = "page token does not match the request parameters"
+	case errors.Is(err, pagetoken.ErrTokenExpired):
+		reason = ReasonTokenExpired
+		desc = "page token expired"
+	case errors.Is(err, pagetoken.ErrTokenRevoked):
+		reason = ReasonTokenRevoked
+		desc = "page token revoked"
+	case errors.Is(err, pagetoken.ErrStaleToken):
+		reason = ReasonStaleToken
+		desc = "page token does not match the current sort order"
+	}
+
+	st := status.New(codes.InvalidArgument, desc)
+	if ds, err := st.WithDetails(
+		&errdetails.BadRequest{
+			FieldViolations: []*errdetails.BadRequest_FieldViolation{{
+				Field:       fieldName,
+				Description: desc,
+			}},
+		},
+		&errdetails.ErrorInfo{
+			Reason: reason,
+			Domain: ErrorDomain,
+		},
+	); err == nil {
+		st = ds
+	}
+
+	return st
+}
@@ -0,0 +1,13 @@
+package pagetokengrpc_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestPagetokengrpc(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Pagetokengrpc Suite")
+}
@@ -0,0 +1,230 @@
+package pagetokengrpc_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/encryption"
+	"github.com/pixlcrashr/go-pagetoken/integration/pagetokengrpc"
+	"github.com/pixlcrashr/go-pagetoken/integration/pagetokengrpc/internal/testpb"
+	"github.com/pixlcrashr/go-pagetoken/order"
+)
+
+// bookServer lists ten books ordered by id, optionally filtered to odd ids.
+type bookServer struct {
+	testpb.UnimplementedBookServiceServer
+	called int
+}
+
+func (s *bookServer) ListBooks(ctx context.Context, req *testpb.ListBooksRequest) (*testpb.ListBooksResponse, error) {
+	s.called++
+
+	t, ok := pagetoken.FromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Internal, "no page token in context")
+	}
+
+	after, _, err := t.Payload().Int("id")
+	if err != nil {
+		after = 0
+	}
+
+	// fetch one more book than requested to tell whether there is a next page
+	books := []*testpb.Book{}
+	for id := after + 1; id <= 10 && len(books) <= int(req.GetPageSize()); id++ {
+		if req.GetFilter() == "odd" && id%2 == 0 {
+			continue
+		}
+		books = append(books, &testpb.Book{Id: int64(id), Title: fmt.Sprintf("Book %d", id)})
+	}
+
+	resp := &testpb.ListBooksResponse{Books: books}
+	if len(books) > int(req.GetPageSize()) {
+		resp.Books = books[:req.GetPageSize()]
+		last := int(resp.Books[len(resp.Books)-1].Id)
+		resp.NextPageToken, err = t.Next(pagetoken.WithKeysetPayload(
+			pagetoken.NewKeysetPayloadBuilder().AddInt("id", last, order.Asc).Build(),
+		)).String()
+		if err != nil {
+			return nil, pagetokengrpc.Status(err).Err()
+		}
+	}
+
+	return resp, nil
+}
+
+var _ = Describe("pagetokengrpc", func() {
+	var (
+		srv    *bookServer
+		client testpb.BookServiceClient
+		ctx    context.Context
+	)
+
+	start := func(opts ...pagetokengrpc.InterceptorOpt) {
+		key, err := encryption.Rand32ByteKey()
+		Expect(err).NotTo(HaveOccurred())
+		e, err := encryption.NewAEADEncryptor(key)
+		Expect(err).NotTo(HaveOccurred())
+		reader := pagetoken.NewRequestReader(pagetoken.WithEncryptor(e))
+
+		lis := bufconn.Listen(1 << 20)
+		s := grpc.NewServer(grpc.UnaryInterceptor(pagetokengrpc.UnaryServerInterceptor(reader, opts...)))
+		srv = &bookServer{}
+		testpb.RegisterBookServiceServer(s, srv)
+		go func() {
+			defer GinkgoRecover()
+			Expect(s.Serve(lis)).To(Succeed())
+		}()
+		DeferCleanup(s.Stop)
+
+		conn, err := grpc.NewClient("passthrough:///bufnet",
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+				return lis.DialContext(ctx)
+			}),
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(conn.Close)
+
+		client = testpb.NewBookServiceClient(conn)
+		ctx = context.Background()
+	}
+
+	ids := func(resp *testpb.ListBooksResponse) []int64 {
+		out := []int64{}
+		for _, b := range resp.GetBooks() {
+			out = append(out, b.GetId())
+		}
+		return out
+	}
+
+	expectInvalidArgument := func(err error, field, reason string) {
+		st, ok := status.FromError(err)
+		Expect(ok).To(BeTrue())
+		Expect(st.Code()).To(Equal(codes.InvalidArgument))
+
+		var br *errdetails.BadRequest
+		var info *errdetails.ErrorInfo
+		for _, d := range st.Details() {
+			switch d := d.(type) {
+			case *errdetails.BadRequest:
+				br = d
+			case *errdetails.ErrorInfo:
+				info = d
+			}
+		}
+		Expect(br).NotTo(BeNil())
+		Expect(br.GetFieldViolations()).To(HaveLen(1))
+		Expect(br.GetFieldViolations()[0].GetField()).To(Equal(field))
+		Expect(info).NotTo(BeNil())
+		Expect(info.GetDomain()).To(Equal(pagetokengrpc.ErrorDomain))
+		Expect(info.GetReason()).To(Equal(reason))
+	}
+
+	Context("with the default checksum fields", func() {
+		BeforeEach(func() {
+			start()
+		})
+
+		It("walks all pages and allows the page size to change", func() {
+			resp, err := client.ListBooks(ctx, &testpb.ListBooksRequest{PageSize: 4, Filter: "odd"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ids(resp)).To(Equal([]int64{1, 3, 5, 7}))
+
+			resp, err = client.ListBooks(ctx, &testpb.ListBooksRequest{PageSize: 2, Filter: "odd", PageToken: resp.GetNextPageToken()})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ids(resp)).To(Equal([]int64{9}))
+			Expect(resp.GetNextPageToken()).To(BeEmpty())
+		})
+
+		It("rejects a tampered token before the handler runs", func() {
+			resp, err := client.ListBooks(ctx, &testpb.ListBooksRequest{PageSize: 2})
+			Expect(err).NotTo(HaveOccurred())
+
+			s := []byte(resp.GetNextPageToken())
+			s[len(s)/2] ^= 0x01
+
+			_, err = client.ListBooks(ctx, &testpb.ListBooksRequest{PageSize: 2, PageToken: string(s)})
+			expectInvalidArgument(err, "page_token", pagetokengrpc.ReasonInvalidPageToken)
+			Expect(srv.called).To(Equal(1))
+		})
+
+		It("rejects a token issued for another filter", func() {
+			resp, err := client.ListBooks(ctx, &testpb.ListBooksRequest{PageSize: 2, Filter: "odd"})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = client.ListBooks(ctx, &testpb.ListBooksRequest{PageSize: 2, PageToken: resp.GetNextPageToken()})
+			expectInvalidArgument(err, "page_token", pagetokengrpc.ReasonChecksumMismatch)
+			Expect(srv.called).To(Equal(1))
+		})
+	})
+
+	Context("with per-method checksum fields", func() {
+		BeforeEach(func() {
+			start(pagetokengrpc.WithMethod(
+				testpb.BookService_ListBooks_FullMethodName,
+				pagetokengrpc.FieldMask("filter"),
+			))
+		})
+
+		It("ignores fields outside of the mask", func() {
+			resp, err := client.ListBooks(ctx, &testpb.ListBooksRequest{PageSize: 2, Filter: "odd", OrderBy: "id"})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = client.ListBooks(ctx, &testpb.ListBooksRequest{PageSize: 2, Filter: "odd", PageToken: resp.GetNextPageToken()})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("rejects a token issued for another value of a masked field", func() {
+			resp, err := client.ListBooks(ctx, &testpb.ListBooksRequest{PageSize: 2, Filter: "odd"})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = client.ListBooks(ctx, &testpb.ListBooksRequest{PageSize: 2, Filter: "all", PageToken: resp.GetNextPageToken()})
+			expectInvalidArgument(err, "page_token", pagetokengrpc.ReasonChecksumMismatch)
+		})
+	})
+
+	It("fails with codes.Internal for unknown field mask paths", func() {
+		start(pagetokengrpc.WithDefaultChecksumFields(pagetokengrpc.FieldMask("unknown")))
+
+		_, err := client.ListBooks(ctx, &testpb.ListBooksRequest{PageSize: 2})
+		Expect(status.Code(err)).To(Equal(codes.Internal))
+		Expect(srv.called).To(BeZero())
+	})
+
+	It("maps negative page sizes of AIP-158 readers to codes.InvalidArgument on page_size", func() {
+		reader := pagetoken.NewRequestReader(pagetoken.WithAIP158())
+		r, err := pagetokengrpc.NewRequest(&testpb.ListBooksRequest{PageSize: -1}, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = reader.Read(r)
+		Expect(err).To(MatchError(pagetoken.ErrInvalidPageSize))
+		expectInvalidArgument(pagetokengrpc.Status(err).Err(), "page_size", pagetokengrpc.ReasonInvalidPageSize)
+	})
+
+	It("maps expired tokens to codes.InvalidArgument on page_token", func() {
+		err := fmt.Errorf("read page token: %w", pagetoken.ErrTokenExpired)
+		expectInvalidArgument(pagetokengrpc.Status(err).Err(), "page_token", pagetokengrpc.ReasonTokenExpired)
+	})
+
+	It("maps revoked tokens to codes.InvalidArgument on page_token", func() {
+		err := fmt.Errorf("read page token: %w", pagetoken.ErrTokenRevoked)
+		expectInvalidArgument(pagetokengrpc.Status(err).Err(), "page_token", pagetokengrpc.ReasonTokenRevoked)
+	})
+
+	It("maps stale tokens to codes.InvalidArgument on page_token", func() {
+		err := fmt.Errorf("read page token: %w", pagetoken.ErrStaleToken)
+		expectInvalidArgument(pagetokengrpc.Status(err).Err(), "page_token", pagetokengrpc.ReasonStaleToken)
+	})
+})
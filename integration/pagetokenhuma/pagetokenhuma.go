@@ -0,0 +1,165 @@
+// Package pagetokenhuma wires page tokens into Huma APIs.
+//
+// It provides a PageToken parameter type with schema metadata, a middleware
+// that makes a RequestReader available to resolvers, a Resolve helper that
+// reads the page token while Huma resolves the request, and an error mapping
+// from the pagetoken package's typed errors to Huma errors.
+//
+// The package lives in its own Go module so that depending on the core
+// pagetoken package does not pull in Huma.
+//
+// # Example
+//
+//	type ListBooksRequest struct {
+//	    pagetokenhuma.Pagination
+//	    Status    string                  `query:"status"`
+//	    PageToken pagetokenhuma.PageToken `query:"page_token"`
+//	}
+//
+//	func (r *ListBooksRequest) GetPageToken() string { return string(r.PageToken) }
+//	func (r *ListBooksRequest) GetChecksumFields() []checksum.BuilderOpt {
+//	    return []checksum.BuilderOpt{checksum.Field("status", r.Status)}
+//	}
+//	func (r *ListBooksRequest) Resolve(ctx huma.Context) []error {
+//	    return pagetokenhuma.Resolve(ctx, r, &r.Pagination)
+//	}
+//
+//	api.UseMiddleware(pagetokenhuma.Middleware(reader))
+//	huma.Get(api, "/books", func(ctx context.Context, req *ListBooksRequest) (*ListBooksResponse, error) {
+//	    t := req.Token()
+//	    ...
+//	})
+package pagetokenhuma
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/pixlcrashr/go-pagetoken"
+)
+
+// MaxLength is the maximum length of a PageToken accepted by the schema. It
+// matches the limit of pagetoken.Middleware.
+const MaxLength = pagetoken.DefaultMaxTokenLength
+
+// ErrNoReader is returned by Resolve if no RequestReader was attached to the
+// context via Middleware.
+var ErrNoReader = errors.New("pagetokenhuma: no request reader in context, register Middleware")
+
+var maxLength = MaxLength
+
+// PageToken is an opaque page token parameter. Its schema documents the
+// format and limits the length, so oversized tokens fail Huma's validation.
+type PageToken string
+
+func (PageToken) Schema(r huma.Registry) *huma.Schema {
+	return &huma.Schema{
+		Type:        huma.TypeString,
+		Format:      "pagetoken",
+		MaxLength:   &maxLength,
+		Description: "Opaque continuation token from the previous response; omit for the first page",
+	}
+}
+
+// Pagination holds the page token of a request once it has been resolved.
+// Embed it into request structs and call Resolve from their Resolve method.
+type Pagination struct {
+	token *pagetoken.KeysetToken
+}
+
+// Token returns the resolved page token. On the first page it is a fresh
+// token carrying the request's checksum.
+func (p *Pagination) Token() *pagetoken.KeysetToken {
+	return p.token
+}
+
+type readerKey struct{}
+
+// Middleware attaches reader to the context of every request, for Resolve to
+// read page tokens with.
+func Middleware(reader *pagetoken.RequestReader) func(ctx huma.Context, next func(huma.Context)) {
+	return func(ctx huma.Context, next func(huma.Context)) {
+		next(huma.WithValue(ctx, readerKey{}, reader))
+	}
+}
+
+// Resolve reads the page token of req with the RequestReader attached via
+// Middleware and stores it in p. Errors are mapped via Error, so invalid or
+// mismatching tokens are rejected with 400 Bad Request before the handler
+// runs.
+func Resolve(ctx huma.Context, req pagetoken.Request, p *Pagination) []error {
+	reader, ok := ctx.Context().Value(readerKey{}).(*pagetoken.RequestReader)
+	if !ok {
+		return []error{Error(ErrNoReader)}
+	}
+
+	token, err := reader.Read(req)
+	if err != nil {
+		return []error{Error(err)}
+	}
+
+	p.token = token
+	return nil
+}
+
+// Error maps an error returned by the pagetoken package to a Huma error with
+// the status of pagetoken.HTTPStatus. It can be used to transform errors of
+// handlers that read or write tokens themselves. Errors that are not the
+// client's fault are described by a generic message; the original error is
+// only available through errors.Unwrap, for logging.
+func Error(err error) huma.StatusError {
+	switch {
+	case errors.Is(err, pagetoken.ErrChecksumMismatch):
+		return huma.Error400BadRequest("page_token does not match the request parameters", &huma.ErrorDetail{
+			Location: "query.page_token",
+			Message:  "the request parameters changed since the token was issued",
+		})
+	case errors.Is(err, pagetoken.ErrInvalidToken):
+		return huma.Error400BadRequest("invalid page_token", &huma.ErrorDetail{
+			Location: "query.page_token",
+			Message:  "the token is malformed or was not issued by this API",
+		})
+	case errors.Is(err, pagetoken.ErrTokenExpired):
+		return huma.Error400BadRequest("page_token expired", &huma.ErrorDetail{
+			Location: "query.page_token",
+			Message:  "the token is older than this API accepts, start from the first page",
+		})
+	case errors.Is(err, pagetoken.ErrTokenRevoked):
+		return huma.Error400BadRequest("page_token revoked", &huma.ErrorDetail{
+			Location: "query.page_token",
+			Message:  "the token was revoked, start from the first page",
+		})
+	case errors.Is(err, pagetoken.ErrStaleToken):
+		return huma.Error400BadRequest("page_token does not match the current sort order", &huma.ErrorDetail{
+			Location: "query.page_token",
+			Message:  "the sort order changed since the token was issued, start from the first page",
+		})
+	case pagetoken.HTTPStatus(err) == http.StatusBadRequest:
+		return huma.Error400BadRequest("invalid page_token", &huma.ErrorDetail{
+			Location: "query.page_token",
+			Message:  "the token is no longer accepted by this API",
+		})
+	default:
+		status := pagetoken.HTTPStatus(err)
+		return &internalError{
+			ErrorModel: &huma.ErrorModel{
+				Status: status,
+				Title:  http.StatusText(status),
+				Detail: "failed to read page_token",
+			},
+			err: err,
+		}
+	}
+}
+
+// internalError is a Huma error whose body omits the error it was created
+// from, so that internals do not leak to clients.
+type internalError struct {
+	*huma.ErrorModel
+	err error
+}
+
+func (e *internalError) Unwrap() error {
+	return e.err
+}
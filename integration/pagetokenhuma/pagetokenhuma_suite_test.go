@@ -0,0 +1,13 @@
+package pagetokenhuma_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestPagetokenhuma(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Pagetokenhuma Suite")
+}
@@ -0,0 +1,177 @@
+package pagetokenhuma_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/checksum"
+	"github.com/pixlcrashr/go-pagetoken/encryption"
+	"github.com/pixlcrashr/go-pagetoken/integration/pagetokenhuma"
+	"github.com/pixlcrashr/go-pagetoken/order"
+)
+
+type listRequest struct {
+	pagetokenhuma.Pagination
+	Status    string                  `query:"status"`
+	PageToken pagetokenhuma.PageToken `query:"page_token"`
+}
+
+func (r *listRequest) GetPageToken() string { return string(r.PageToken) }
+func (r *listRequest) GetChecksumFields() []checksum.BuilderOpt {
+	return []checksum.BuilderOpt{checksum.Field("status", r.Status)}
+}
+func (r *listRequest) Resolve(ctx huma.Context) []error {
+	return pagetokenhuma.Resolve(ctx, r, &r.Pagination)
+}
+
+type listResponse struct {
+	Body struct {
+		Page          int    `json:"page"`
+		NextPageToken string `json:"next_page_token"`
+	}
+}
+
+// register adds an endpoint that counts pages in the keyset and always
+// returns a next page token.
+func register(api huma.API) {
+	huma.Get(api, "/items", func(ctx context.Context, req *listRequest) (*listResponse, error) {
+		t := req.Token()
+
+		page, _, err := t.Payload().Int("page")
+		if err != nil {
+			page = 0
+		}
+
+		next, err := t.Next(pagetoken.WithKeysetPayload(
+			pagetoken.NewKeysetPayloadBuilder().AddInt("page", page+1, order.Asc).Build(),
+		)).String()
+		if err != nil {
+			return nil, pagetokenhuma.Error(err)
+		}
+
+		resp := &listResponse{}
+		resp.Body.Page = page
+		resp.Body.NextPageToken = next
+		return resp, nil
+	})
+}
+
+var _ = Describe("pagetokenhuma", func() {
+	var api humatest.TestAPI
+
+	BeforeEach(func() {
+		key, err := encryption.Rand32ByteKey()
+		Expect(err).NotTo(HaveOccurred())
+		e, err := encryption.NewAEADEncryptor(key)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, api = humatest.New(GinkgoT())
+		api.UseMiddleware(pagetokenhuma.Middleware(pagetoken.NewRequestReader(pagetoken.WithEncryptor(e))))
+		register(api)
+	})
+
+	get := func(path string) (int, listResponse) {
+		resp := api.Get(path)
+		var body listResponse
+		if resp.Code == http.StatusOK {
+			Expect(json.Unmarshal(resp.Body.Bytes(), &body.Body)).To(Succeed())
+		}
+		return resp.Code, body
+	}
+
+	It("resolves a fresh token on the first page and continues with the next one", func() {
+		code, first := get("/items?status=active")
+		Expect(code).To(Equal(http.StatusOK))
+		Expect(first.Body.Page).To(Equal(0))
+
+		code, second := get("/items?status=active&page_token=" + first.Body.NextPageToken)
+		Expect(code).To(Equal(http.StatusOK))
+		Expect(second.Body.Page).To(Equal(1))
+	})
+
+	It("rejects a tampered token with 400", func() {
+		_, first := get("/items?status=active")
+		s := []byte(first.Body.NextPageToken)
+		s[len(s)/2] ^= 0x01
+
+		resp := api.Get("/items?status=active&page_token=" + string(s))
+		Expect(resp.Code).To(Equal(http.StatusBadRequest))
+		Expect(resp.Body.String()).To(ContainSubstring("invalid page_token"))
+	})
+
+	It("rejects a token of other request parameters with 400", func() {
+		_, first := get("/items?status=active")
+
+		resp := api.Get("/items?status=inactive&page_token=" + first.Body.NextPageToken)
+		Expect(resp.Code).To(Equal(http.StatusBadRequest))
+		Expect(resp.Body.String()).To(ContainSubstring("does not match the request parameters"))
+	})
+
+	It("maps expired tokens to 400", func() {
+		err := pagetokenhuma.Error(fmt.Errorf("read page token: %w", pagetoken.ErrTokenExpired))
+		Expect(err.GetStatus()).To(Equal(http.StatusBadRequest))
+		Expect(err.Error()).To(ContainSubstring("page_token expired"))
+	})
+
+	It("maps revoked tokens to 400", func() {
+		err := pagetokenhuma.Error(fmt.Errorf("read page token: %w", pagetoken.ErrTokenRevoked))
+		Expect(err.GetStatus()).To(Equal(http.StatusBadRequest))
+		Expect(err.Error()).To(ContainSubstring("page_token revoked"))
+	})
+
+	It("maps stale tokens to 400", func() {
+		err := pagetokenhuma.Error(fmt.Errorf("read page token: %w", pagetoken.ErrStaleToken))
+		Expect(err.GetStatus()).To(Equal(http.StatusBadRequest))
+		Expect(err.Error()).To(ContainSubstring("page_token does not match the current sort order"))
+	})
+
+	It("rejects an oversized token during validation", func() {
+		resp := api.Get("/items?page_token=" + strings.Repeat("a", pagetokenhuma.MaxLength+1))
+		Expect(resp.Code).To(Equal(http.StatusBadRequest))
+		Expect(resp.Body.String()).To(ContainSubstring("expected length <= 512"))
+	})
+
+	It("documents the page token parameter", func() {
+		op := api.OpenAPI().Paths["/items"].Get
+		var param *huma.Param
+		for _, p := range op.Parameters {
+			if p.Name == "page_token" {
+				param = p
+			}
+		}
+		Expect(param).NotTo(BeNil())
+		Expect(param.Schema.Format).To(Equal("pagetoken"))
+		Expect(*param.Schema.MaxLength).To(Equal(pagetokenhuma.MaxLength))
+	})
+
+	It("fails without the middleware", func() {
+		_, bare := humatest.New(GinkgoT())
+		register(bare)
+
+		resp := bare.Get("/items")
+		Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+		Expect(resp.Body.String()).NotTo(ContainSubstring("no request reader"))
+	})
+
+	It("keeps internal errors out of the response but available for logging", func() {
+		cause := errors.New("dial tcp 10.0.0.7:6379: connection refused")
+		err := pagetokenhuma.Error(fmt.Errorf("check revocation: %w", cause))
+		Expect(err.GetStatus()).To(Equal(http.StatusInternalServerError))
+		Expect(err.Error()).To(Equal("failed to read page_token"))
+		Expect(errors.Is(err, cause)).To(BeTrue())
+
+		body, jerr := json.Marshal(err)
+		Expect(jerr).NotTo(HaveOccurred())
+		Expect(string(body)).NotTo(ContainSubstring("10.0.0.7"))
+	})
+})
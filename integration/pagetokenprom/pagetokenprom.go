@@ -0,0 +1,220 @@
+// Package pagetokenprom exports Prometheus metrics about the page tokens a
+// pagetoken.RequestReader reads.
+//
+// A Collector counts the reads of every scope, e.g. an endpoint, by
+// outcome and records the number of keyset fields and the length of the
+// tokens it accepts. Handlers read their tokens through the collector
+// instead of the reader:
+//
+//	metrics := pagetokenprom.NewCollector()
+//	prometheus.MustRegister(metrics)
+//
+//	func (h *handler) ListBooks(w http.ResponseWriter, r *http.Request) {
+//	    t, err := metrics.ReadContext(r.Context(), h.reader, "GET /books", req)
+//	    ...
+//	}
+package pagetokenprom
+
+import (
+	"context"
+	"errors"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Outcomes of a read, the values of the outcome label of the reads counter.
+const (
+	// OutcomeOK is a request whose page token was accepted.
+	OutcomeOK = "ok"
+	// OutcomeFirstPage is a request without a page token.
+	OutcomeFirstPage = "first_page"
+	// OutcomeInvalid is a token failing with pagetoken.ErrInvalidToken.
+	OutcomeInvalid = "invalid"
+	// OutcomeChecksumMismatch is a token failing with
+	// pagetoken.ErrChecksumMismatch, including tokens a soft checksum
+	// replaced by the first page.
+	OutcomeChecksumMismatch = "checksum_mismatch"
+	// OutcomeExpired is a token failing with pagetoken.ErrTokenExpired.
+	OutcomeExpired = "expired"
+	// OutcomeReplayed is a token rejected by the revocation check of the
+	// reader, i.e. failing with pagetoken.ErrTokenRevoked, which is how
+	// replayed tokens are rejected.
+	OutcomeReplayed = "replayed"
+	// OutcomeStale is a token failing with pagetoken.ErrStaleToken.
+	OutcomeStale = "stale"
+	// OutcomeScopeMismatch is a token failing with
+	// pagetoken.ErrScopeMismatch.
+	OutcomeScopeMismatch = "scope_mismatch"
+	// OutcomeError is a read failing with any other error.
+	OutcomeError = "error"
+)
+
+// Collector is a prometheus.Collector of page token metrics. Its methods
+// are safe for concurrent use.
+type Collector struct {
+	reads     *prometheus.CounterVec
+	fields    *prometheus.HistogramVec
+	tokenSize *prometheus.HistogramVec
+}
+
+type collectorConfig struct {
+	namespace   string
+	scopeLabel  string
+	constLabels prometheus.Labels
+}
+
+type CollectorOpt func(*collectorConfig)
+
+// WithNamespace sets the namespace of the metric names, "pagetoken" by
+// default.
+func WithNamespace(ns string) CollectorOpt {
+	return func(c *collectorConfig) {
+		c.namespace = ns
+	}
+}
+
+// WithScopeLabel sets the name of the label holding the scope of a read,
+// "scope" by default.
+func WithScopeLabel(name string) CollectorOpt {
+	return func(c *collectorConfig) {
+		c.scopeLabel = name
+	}
+}
+
+// WithConstLabels adds labels with fixed values to all metrics, e.g. the
+// name of the service.
+func WithConstLabels(labels prometheus.Labels) CollectorOpt {
+	return func(c *collectorConfig) {
+		c.constLabels = labels
+	}
+}
+
+// NewCollector returns a Collector of the metrics
+//
+//   - pagetoken_reads_total, a counter of reads by scope and outcome,
+//   - pagetoken_payload_fields, a histogram of the number of keyset fields
+//     of accepted tokens by scope, and
+//   - pagetoken_token_bytes, a histogram of the length of accepted tokens
+//     by scope.
+//
+// It must be registered, e.g. with prometheus.MustRegister, to be scraped.
+func NewCollector(opts ...CollectorOpt) *Collector {
+	cfg := &collectorConfig{
+		namespace:  "pagetoken",
+		scopeLabel: "scope",
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return &Collector{
+		reads: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   cfg.namespace,
+			Name:        "reads_total",
+			Help:        "Number of page token reads by outcome.",
+			ConstLabels: cfg.constLabels,
+		}, []string{cfg.scopeLabel, "outcome"}),
+		fields: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace:   cfg.namespace,
+			Name:        "payload_fields",
+			Help:        "Number of keyset fields of accepted page tokens.",
+			ConstLabels: cfg.constLabels,
+			Buckets:     []float64{1, 2, 3, 4, 6, 8, 12, 16},
+		}, []string{cfg.scopeLabel}),
+		tokenSize: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace:   cfg.namespace,
+			Name:        "token_bytes",
+			Help:        "Length of accepted page tokens in bytes.",
+			ConstLabels: cfg.constLabels,
+			Buckets:     prometheus.ExponentialBuckets(64, 2, 8),
+		}, []string{cfg.scopeLabel}),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.reads.Describe(ch)
+	c.fields.Describe(ch)
+	c.tokenSize.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.reads.Collect(ch)
+	c.fields.Collect(ch)
+	c.tokenSize.Collect(ch)
+}
+
+// Read reads the page token of req with rr and observes the result for
+// scope.
+func (c *Collector) Read(rr *pagetoken.RequestReader, scope string, req pagetoken.Request) (*pagetoken.KeysetToken, error) {
+	t, err := rr.Read(req)
+	c.Observe(scope, req.GetPageToken(), t, err)
+	return t, err
+}
+
+// ReadContext is like Read, but reads with rr.ReadContext.
+func (c *Collector) ReadContext(ctx context.Context, rr *pagetoken.RequestReader, scope string, req pagetoken.Request) (*pagetoken.KeysetToken, error) {
+	t, err := rr.ReadContext(ctx, req)
+	c.Observe(scope, req.GetPageToken(), t, err)
+	return t, err
+}
+
+// Observe records the read of the page token string token for scope, which
+// returned t and err. It is meant for handlers reading tokens without
+// Read, e.g. through a framework integration.
+func (c *Collector) Observe(scope, token string, t *pagetoken.KeysetToken, err error) {
+	outcome := Outcome(token, t, err)
+	c.reads.WithLabelValues(scope, outcome).Inc()
+	if outcome != OutcomeOK {
+		return
+	}
+
+	n := 0
+	if p := t.Payload(); p != nil {
+		n = len(p.Values())
+	}
+	c.fields.WithLabelValues(scope).Observe(float64(n))
+	c.tokenSize.WithLabelValues(scope).Observe(float64(len(token)))
+}
+
+// Outcome returns the outcome of a read of the page token string token,
+// which returned t and err. Tokens a reader replaced by the first page, see
+// pagetoken.WithSoftChecksum and pagetoken.WithFallbackToFirstPage, have
+// the outcome of the error they were replaced for.
+func Outcome(token string, t *pagetoken.KeysetToken, err error) string {
+	if err == nil && t != nil {
+		switch {
+		case t.Recovered() != nil:
+			err = t.Recovered()
+		case t.ChecksumMismatched():
+			return OutcomeChecksumMismatch
+		}
+	}
+
+	switch {
+	case err != nil:
+	case token == "":
+		return OutcomeFirstPage
+	default:
+		return OutcomeOK
+	}
+
+	switch {
+	case errors.Is(err, pagetoken.ErrTokenExpired):
+		return OutcomeExpired
+	case errors.Is(err, pagetoken.ErrTokenRevoked):
+		return OutcomeReplayed
+	case errors.Is(err, pagetoken.ErrScopeMismatch):
+		return OutcomeScopeMismatch
+	case errors.Is(err, pagetoken.ErrStaleToken):
+		return OutcomeStale
+	case errors.Is(err, pagetoken.ErrChecksumMismatch):
+		return OutcomeChecksumMismatch
+	case errors.Is(err, pagetoken.ErrInvalidToken):
+		return OutcomeInvalid
+	default:
+		return OutcomeError
+	}
+}
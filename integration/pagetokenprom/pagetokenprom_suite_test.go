@@ -0,0 +1,13 @@
+package pagetokenprom_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestPagetokenprom(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Pagetokenprom Suite")
+}
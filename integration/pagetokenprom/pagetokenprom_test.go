@@ -0,0 +1,197 @@
+package pagetokenprom_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/checksum"
+	"github.com/pixlcrashr/go-pagetoken/encryption"
+	"github.com/pixlcrashr/go-pagetoken/integration/pagetokenprom"
+	"github.com/pixlcrashr/go-pagetoken/order"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type listRequest struct {
+	filter string
+	token  string
+}
+
+func (r listRequest) GetChecksumFields() []checksum.BuilderOpt {
+	return []checksum.BuilderOpt{checksum.Field("filter", r.filter)}
+}
+
+func (r listRequest) GetPageToken() string {
+	return r.token
+}
+
+var errReplayed = errors.New("token already used")
+
+var _ = Describe("Collector", func() {
+	var (
+		e       encryption.Crypter
+		rr      *pagetoken.RequestReader
+		reg     *prometheus.Registry
+		metrics *pagetokenprom.Collector
+		revoke  error
+	)
+
+	payload := pagetoken.NewKeysetPayloadBuilder().
+		AddString("name", "n", order.Asc).
+		AddInt("id", 42, order.Asc).
+		Build()
+
+	BeforeEach(func() {
+		key, err := encryption.Rand32ByteKey()
+		Expect(err).NotTo(HaveOccurred())
+		e, err = encryption.NewAEADEncryptor(key)
+		Expect(err).NotTo(HaveOccurred())
+
+		revoke = nil
+		rr = pagetoken.NewRequestReader(
+			pagetoken.WithEncryptor(e),
+			pagetoken.WithRevocationCheck(func(context.Context, pagetoken.TokenInfo) error {
+				return revoke
+			}),
+		)
+
+		reg = prometheus.NewPedanticRegistry()
+		metrics = pagetokenprom.NewCollector()
+		Expect(reg.Register(metrics)).To(Succeed())
+	})
+
+	issue := func() string {
+		t, err := rr.Read(listRequest{filter: "a"})
+		Expect(err).NotTo(HaveOccurred())
+
+		s, err := rr.NextToken(t, payload)
+		Expect(err).NotTo(HaveOccurred())
+		return s
+	}
+
+	It("counts reads by outcome", func() {
+		s := issue()
+
+		_, err := metrics.Read(rr, "books", listRequest{filter: "a"})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = metrics.Read(rr, "books", listRequest{filter: "a", token: s})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = metrics.Read(rr, "books", listRequest{filter: "a", token: s})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = metrics.Read(rr, "books", listRequest{filter: "a", token: "garbage"})
+		Expect(err).To(MatchError(pagetoken.ErrInvalidToken))
+		_, err = metrics.Read(rr, "books", listRequest{filter: "b", token: s})
+		Expect(err).To(MatchError(pagetoken.ErrChecksumMismatch))
+
+		revoke = errReplayed
+		_, err = metrics.Read(rr, "books", listRequest{filter: "a", token: s})
+		Expect(err).To(MatchError(pagetoken.ErrTokenRevoked))
+
+		revoke = pagetoken.ErrTokenExpired
+		_, err = metrics.Read(rr, "authors", listRequest{filter: "a", token: s})
+		Expect(err).To(MatchError(pagetoken.ErrTokenExpired))
+
+		Expect(reads(reg, "books", pagetokenprom.OutcomeFirstPage)).To(Equal(1.0))
+		Expect(reads(reg, "books", pagetokenprom.OutcomeOK)).To(Equal(2.0))
+		Expect(reads(reg, "books", pagetokenprom.OutcomeInvalid)).To(Equal(1.0))
+		Expect(reads(reg, "books", pagetokenprom.OutcomeChecksumMismatch)).To(Equal(1.0))
+		Expect(reads(reg, "books", pagetokenprom.OutcomeReplayed)).To(Equal(1.0))
+		Expect(reads(reg, "authors", pagetokenprom.OutcomeExpired)).To(Equal(1.0))
+		Expect(reads(reg, "books", pagetokenprom.OutcomeExpired)).To(Equal(0.0))
+	})
+
+	It("records the field count and length of accepted tokens", func() {
+		s := issue()
+
+		_, err := metrics.Read(rr, "books", listRequest{filter: "a", token: s})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = metrics.Read(rr, "books", listRequest{filter: "a", token: "garbage"})
+		Expect(err).To(HaveOccurred())
+
+		Expect(testutil.CollectAndCount(metrics, "pagetoken_payload_fields")).To(Equal(1))
+
+		families, err := reg.Gather()
+		Expect(err).NotTo(HaveOccurred())
+
+		histograms := map[string][2]float64{}
+		for _, f := range families {
+			for _, m := range f.GetMetric() {
+				if h := m.GetHistogram(); h != nil {
+					histograms[f.GetName()] = [2]float64{float64(h.GetSampleCount()), h.GetSampleSum()}
+				}
+			}
+		}
+		Expect(histograms).To(Equal(map[string][2]float64{
+			"pagetoken_payload_fields": {1, 2},
+			"pagetoken_token_bytes":    {1, float64(len(s))},
+		}))
+	})
+
+	It("counts tokens replaced by the first page by their error", func() {
+		rr = pagetoken.NewRequestReader(pagetoken.WithEncryptor(e), pagetoken.WithSoftChecksum())
+		s := issue()
+
+		t, err := metrics.Read(rr, "books", listRequest{filter: "b", token: s})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(t.ChecksumMismatched()).To(BeTrue())
+
+		Expect(reads(reg, "books", pagetokenprom.OutcomeChecksumMismatch)).To(Equal(1.0))
+		Expect(testutil.CollectAndCount(metrics, "pagetoken_token_bytes")).To(BeZero())
+	})
+
+	It("applies the configured names", func() {
+		reg := prometheus.NewPedanticRegistry()
+		metrics := pagetokenprom.NewCollector(
+			pagetokenprom.WithNamespace("api"),
+			pagetokenprom.WithScopeLabel("endpoint"),
+			pagetokenprom.WithConstLabels(prometheus.Labels{"service": "library"}),
+		)
+		Expect(reg.Register(metrics)).To(Succeed())
+
+		_, err := metrics.Read(rr, "GET /books", listRequest{filter: "a"})
+		Expect(err).NotTo(HaveOccurred())
+
+		families, err := reg.Gather()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(families).To(HaveLen(1))
+		Expect(families[0].GetName()).To(Equal("api_reads_total"))
+
+		labels := map[string]string{}
+		for _, l := range families[0].GetMetric()[0].GetLabel() {
+			labels[l.GetName()] = l.GetValue()
+		}
+		Expect(labels).To(Equal(map[string]string{
+			"endpoint": "GET /books",
+			"outcome":  pagetokenprom.OutcomeFirstPage,
+			"service":  "library",
+		}))
+	})
+})
+
+// reads returns the value of the reads counter of scope and outcome in reg,
+// or 0 if there is none.
+func reads(reg *prometheus.Registry, scope, outcome string) float64 {
+	families, err := reg.Gather()
+	Expect(err).NotTo(HaveOccurred())
+
+	for _, f := range families {
+		if f.GetName() != "pagetoken_reads_total" {
+			continue
+		}
+		for _, m := range f.GetMetric() {
+			labels := map[string]string{}
+			for _, l := range m.GetLabel() {
+				labels[l.GetName()] = l.GetValue()
+			}
+			if labels["scope"] == scope && labels["outcome"] == outcome {
+				return m.GetCounter().GetValue()
+			}
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,175 @@
+// Package pagetokenrelay builds Relay-style connections
+// (https://relay.dev/graphql/connections.htm) on top of keyset pagination,
+// e.g. for gqlgen resolvers.
+//
+// Every edge carries an encrypted cursor holding the keyset payload of its
+// node. The after and before arguments decode back into payloads, which
+// bound the keyset query the same way a page token does. BuildConnection
+// then trims the fetched nodes to first or last and derives the PageInfo.
+//
+// # Example
+//
+//	func (r *queryResolver) Books(ctx context.Context, first *int, after *string, last *int, before *string) (*pagetokenrelay.Connection[*Book], error) {
+//	    args := pagetokenrelay.Args{First: first, Last: last, After: deref(after), Before: deref(before)}
+//	    if err := args.Validate(); err != nil {
+//	        return nil, err
+//	    }
+//	    afterP, err := pagetokenrelay.DecodeCursor(r.crypter, args.After)
+//	    ...
+//	    // fetch args.Limit() books between the cursors, in connection order
+//	    conn, err := pagetokenrelay.BuildConnection(r.crypter, books, args, bookKeyset)
+//	    return &conn, err
+//	}
+package pagetokenrelay
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/encryption"
+)
+
+// ErrInvalidArgs is returned for connection arguments the Relay
+// specification rejects, i.e. a negative first or last.
+var ErrInvalidArgs = errors.New("invalid connection arguments")
+
+// Args are the Relay connection arguments. An empty After or Before means the
+// argument was not given.
+type Args struct {
+	First  *int
+	After  string
+	Last   *int
+	Before string
+}
+
+// Validate reports an error wrapping ErrInvalidArgs if First or Last is
+// negative.
+func (a Args) Validate() error {
+	if a.First != nil && *a.First < 0 {
+		return fmt.Errorf("%w: first must not be negative", ErrInvalidArgs)
+	}
+	if a.Last != nil && *a.Last < 0 {
+		return fmt.Errorf("%w: last must not be negative", ErrInvalidArgs)
+	}
+
+	return nil
+}
+
+// Limit returns the number of nodes to fetch for BuildConnection: one more
+// than First or Last, so that BuildConnection can tell whether there are
+// more nodes in the direction of pagination. It returns 0, meaning no limit,
+// if neither is given. If both are given, First wins, as the Relay
+// algorithm applies it first.
+func (a Args) Limit() int {
+	switch {
+	case a.First != nil:
+		return *a.First + 1
+	case a.Last != nil:
+		return *a.Last + 1
+	default:
+		return 0
+	}
+}
+
+// Backward reports whether the connection is paginated backwards, i.e. with
+// Last but without First. The nodes before the Before cursor are then
+// fetched in reverse order, closest first, and reversed into connection
+// order before calling BuildConnection.
+func (a Args) Backward() bool {
+	return a.First == nil && a.Last != nil
+}
+
+// Edge is an edge of a Connection.
+type Edge[T any] struct {
+	Node   T      `json:"node"`
+	Cursor string `json:"cursor"`
+}
+
+// PageInfo is the page info of a Connection.
+type PageInfo struct {
+	HasPreviousPage bool    `json:"hasPreviousPage"`
+	HasNextPage     bool    `json:"hasNextPage"`
+	StartCursor     *string `json:"startCursor"`
+	EndCursor       *string `json:"endCursor"`
+}
+
+// Connection is a Relay connection of nodes of type T.
+type Connection[T any] struct {
+	Edges    []Edge[T] `json:"edges"`
+	PageInfo PageInfo  `json:"pageInfo"`
+}
+
+// EncodeEdgeCursor encrypts payload into an edge cursor with e.
+func EncodeEdgeCursor(e encryption.Crypter, payload *pagetoken.KeysetPayload) (string, error) {
+	return pagetoken.NewKeysetToken(e, pagetoken.WithKeysetPayload(payload)).String()
+}
+
+// DecodeCursor decrypts a cursor created by EncodeEdgeCursor. An empty cursor
+// decodes to a nil payload, meaning the argument was not given. Invalid
+// cursors are reported with an error wrapping pagetoken.ErrInvalidToken.
+func DecodeCursor(e encryption.Crypter, cursor string) (*pagetoken.KeysetPayload, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+
+	t, err := pagetoken.NewKeysetTokenParser(pagetoken.WithKeysetTokenEncryptor(e)).Parse(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.Payload(), nil
+}
+
+// BuildConnection builds the connection of nodes following the Relay
+// pagination algorithm.
+//
+// nodes must already be restricted to the nodes after the After cursor and
+// before the Before cursor, in connection order, and hold at most
+// args.Limit() of them. The node fetched in excess of First (at the end) or
+// Last (at the start) is dropped and reported through HasNextPage or
+// HasPreviousPage. As the specification permits, HasPreviousPage is false
+// when paginating forwards and HasNextPage is false when paginating
+// backwards, as finding out would take another query.
+//
+// cursorFor returns the keyset payload of a node, which is encrypted with e
+// into the cursor of its edge by pagetoken.ItemCursors.
+func BuildConnection[T any](e encryption.Crypter, nodes []T, args Args, cursorFor func(T) *pagetoken.KeysetPayload) (Connection[T], error) {
+	if err := args.Validate(); err != nil {
+		return Connection[T]{}, err
+	}
+
+	var info PageInfo
+
+	if args.First != nil && len(nodes) > *args.First {
+		nodes = nodes[:*args.First]
+		info.HasNextPage = true
+	}
+	if args.Last != nil && len(nodes) > *args.Last {
+		nodes = nodes[len(nodes)-*args.Last:]
+		info.HasPreviousPage = true
+	}
+
+	payloads := make([]*pagetoken.KeysetPayload, len(nodes))
+	for i, n := range nodes {
+		payloads[i] = cursorFor(n)
+	}
+
+	cursors, err := pagetoken.ItemCursors(pagetoken.NewKeysetToken(e), payloads)
+	if err != nil {
+		return Connection[T]{}, err
+	}
+
+	edges := make([]Edge[T], len(nodes))
+	for i, n := range nodes {
+		edges[i] = Edge[T]{Node: n, Cursor: cursors[i]}
+	}
+
+	if len(edges) > 0 {
+		start, end := edges[0].Cursor, edges[len(edges)-1].Cursor
+		info.StartCursor = &start
+		info.EndCursor = &end
+	}
+
+	return Connection[T]{Edges: edges, PageInfo: info}, nil
+}
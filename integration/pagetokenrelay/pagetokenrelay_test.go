@@ -0,0 +1,180 @@
+package pagetokenrelay_test
+
+import (
+	"slices"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/encryption"
+	"github.com/pixlcrashr/go-pagetoken/integration/pagetokenrelay"
+	"github.com/pixlcrashr/go-pagetoken/order"
+)
+
+func bookKeyset(id int) *pagetoken.KeysetPayload {
+	return pagetoken.NewKeysetPayloadBuilder().AddInt("id", id, order.Asc).Build()
+}
+
+var _ = Describe("pagetokenrelay", func() {
+	var e *encryption.AEADEncryptor
+
+	BeforeEach(func() {
+		key, err := encryption.Rand32ByteKey()
+		Expect(err).NotTo(HaveOccurred())
+		e, err = encryption.NewAEADEncryptor(key)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	// books resolves a connection over the books 1 to 10 like a keyset query
+	// would: bounded by the cursors, limited to args.Limit() and read in
+	// reverse when paginating backwards.
+	books := func(args pagetokenrelay.Args) pagetokenrelay.Connection[int] {
+		bound := func(cursor string) int {
+			p, err := pagetokenrelay.DecodeCursor(e, cursor)
+			Expect(err).NotTo(HaveOccurred())
+			if p == nil {
+				return 0
+			}
+			id, _, err := p.Int("id")
+			Expect(err).NotTo(HaveOccurred())
+			return id
+		}
+		after, before := bound(args.After), bound(args.Before)
+
+		ids := []int{}
+		for id := 1; id <= 10; id++ {
+			if id > after && (before == 0 || id < before) {
+				ids = append(ids, id)
+			}
+		}
+
+		if args.Backward() {
+			slices.Reverse(ids)
+		}
+		if n := args.Limit(); n > 0 && len(ids) > n {
+			ids = ids[:n]
+		}
+		if args.Backward() {
+			slices.Reverse(ids)
+		}
+
+		conn, err := pagetokenrelay.BuildConnection(e, ids, args, bookKeyset)
+		Expect(err).NotTo(HaveOccurred())
+		return conn
+	}
+
+	nodes := func(conn pagetokenrelay.Connection[int]) []int {
+		out := []int{}
+		for _, edge := range conn.Edges {
+			out = append(out, edge.Node)
+		}
+		return out
+	}
+
+	n := func(v int) *int { return &v }
+
+	It("round-trips edge cursors", func() {
+		c, err := pagetokenrelay.EncodeEdgeCursor(e, bookKeyset(7))
+		Expect(err).NotTo(HaveOccurred())
+
+		p, err := pagetokenrelay.DecodeCursor(e, c)
+		Expect(err).NotTo(HaveOccurred())
+		id, _, err := p.Int("id")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(id).To(Equal(7))
+	})
+
+	It("rejects tampered cursors with pagetoken.ErrInvalidToken", func() {
+		c, err := pagetokenrelay.EncodeEdgeCursor(e, bookKeyset(7))
+		Expect(err).NotTo(HaveOccurred())
+		bs := []byte(c)
+		bs[len(bs)/2] ^= 0x01
+
+		_, err = pagetokenrelay.DecodeCursor(e, string(bs))
+		Expect(err).To(MatchError(pagetoken.ErrInvalidToken))
+	})
+
+	It("paginates forwards with first and after", func() {
+		conn := books(pagetokenrelay.Args{First: n(4)})
+		Expect(nodes(conn)).To(Equal([]int{1, 2, 3, 4}))
+		Expect(conn.PageInfo.HasNextPage).To(BeTrue())
+		Expect(conn.PageInfo.HasPreviousPage).To(BeFalse())
+		Expect(*conn.PageInfo.StartCursor).To(Equal(conn.Edges[0].Cursor))
+
+		conn = books(pagetokenrelay.Args{First: n(4), After: *conn.PageInfo.EndCursor})
+		Expect(nodes(conn)).To(Equal([]int{5, 6, 7, 8}))
+		Expect(conn.PageInfo.HasNextPage).To(BeTrue())
+
+		conn = books(pagetokenrelay.Args{First: n(4), After: *conn.PageInfo.EndCursor})
+		Expect(nodes(conn)).To(Equal([]int{9, 10}))
+		Expect(conn.PageInfo.HasNextPage).To(BeFalse())
+	})
+
+	It("paginates backwards with last and before", func() {
+		conn := books(pagetokenrelay.Args{Last: n(4)})
+		Expect(nodes(conn)).To(Equal([]int{7, 8, 9, 10}))
+		Expect(conn.PageInfo.HasPreviousPage).To(BeTrue())
+		Expect(conn.PageInfo.HasNextPage).To(BeFalse())
+
+		conn = books(pagetokenrelay.Args{Last: n(4), Before: *conn.PageInfo.StartCursor})
+		Expect(nodes(conn)).To(Equal([]int{3, 4, 5, 6}))
+		Expect(conn.PageInfo.HasPreviousPage).To(BeTrue())
+
+		conn = books(pagetokenrelay.Args{Last: n(4), Before: *conn.PageInfo.StartCursor})
+		Expect(nodes(conn)).To(Equal([]int{1, 2}))
+		Expect(conn.PageInfo.HasPreviousPage).To(BeFalse())
+	})
+
+	It("bounds the window by both after and before", func() {
+		after, err := pagetokenrelay.EncodeEdgeCursor(e, bookKeyset(2))
+		Expect(err).NotTo(HaveOccurred())
+		before, err := pagetokenrelay.EncodeEdgeCursor(e, bookKeyset(6))
+		Expect(err).NotTo(HaveOccurred())
+
+		conn := books(pagetokenrelay.Args{First: n(10), After: after, Before: before})
+		Expect(nodes(conn)).To(Equal([]int{3, 4, 5}))
+		Expect(conn.PageInfo.HasNextPage).To(BeFalse())
+	})
+
+	It("detects over-fetching only when more nodes than requested were fetched", func() {
+		conn, err := pagetokenrelay.BuildConnection(e, []int{1, 2, 3}, pagetokenrelay.Args{First: n(3)}, bookKeyset)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(nodes(conn)).To(Equal([]int{1, 2, 3}))
+		Expect(conn.PageInfo.HasNextPage).To(BeFalse())
+
+		conn, err = pagetokenrelay.BuildConnection(e, []int{1, 2, 3, 4}, pagetokenrelay.Args{First: n(3)}, bookKeyset)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(nodes(conn)).To(Equal([]int{1, 2, 3}))
+		Expect(conn.PageInfo.HasNextPage).To(BeTrue())
+
+		conn, err = pagetokenrelay.BuildConnection(e, []int{1, 2, 3, 4}, pagetokenrelay.Args{Last: n(3)}, bookKeyset)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(nodes(conn)).To(Equal([]int{2, 3, 4}))
+		Expect(conn.PageInfo.HasPreviousPage).To(BeTrue())
+	})
+
+	It("applies first before last when both are given", func() {
+		conn, err := pagetokenrelay.BuildConnection(e, []int{1, 2, 3, 4, 5}, pagetokenrelay.Args{First: n(4), Last: n(2)}, bookKeyset)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(nodes(conn)).To(Equal([]int{3, 4}))
+		Expect(conn.PageInfo.HasNextPage).To(BeTrue())
+		Expect(conn.PageInfo.HasPreviousPage).To(BeTrue())
+	})
+
+	It("has no cursors for an empty connection", func() {
+		conn := books(pagetokenrelay.Args{First: n(0)})
+		Expect(conn.Edges).To(BeEmpty())
+		Expect(conn.PageInfo.StartCursor).To(BeNil())
+		Expect(conn.PageInfo.EndCursor).To(BeNil())
+		Expect(conn.PageInfo.HasNextPage).To(BeTrue())
+	})
+
+	It("rejects negative first and last", func() {
+		_, err := pagetokenrelay.BuildConnection(e, []int{1}, pagetokenrelay.Args{First: n(-1)}, bookKeyset)
+		Expect(err).To(MatchError(pagetokenrelay.ErrInvalidArgs))
+
+		_, err = pagetokenrelay.BuildConnection(e, []int{1}, pagetokenrelay.Args{Last: n(-1)}, bookKeyset)
+		Expect(err).To(MatchError(pagetokenrelay.ErrInvalidArgs))
+	})
+})
@@ -0,0 +1,33 @@
+// Package redis pages through Redis sorted sets with keyset page tokens.
+//
+// The natural cursor of a sorted set is the (score, member) pair of the last
+// returned entry: Redis orders entries by score and entries sharing a score
+// by member, compared byte-wise. The keyset therefore holds two values,
+// ScorePath and MemberPath, both in the direction of the listing.
+//
+// Continuing after a boundary takes two steps. Entries tied with the boundary
+// score are selected by score, starting at the rank of the boundary member,
+// and compared lexically by member, and the remaining entries follow from the
+// exclusive score bound "(score". ZRANGEBYLEX
+// cannot be used for the tie step on its own, as its result is unspecified
+// for sets whose members do not all share one score; TieRange is provided for
+// such single-score sets.
+//
+// The package lives in its own Go module so that depending on the core
+// pagetoken package does not pull in the Redis client.
+//
+// # Example
+//
+//	entries, next, err := redis.Page(ctx, rdb, "leaderboard", t.Payload(), order.Desc, 50)
+//	if err != nil {
+//	    return err
+//	}
+//
+//	var nextToken string
+//	if next != nil {
+//	    nextToken, err = t.Next(pagetoken.WithKeysetPayload(next)).String()
+//	    if err != nil {
+//	        return err
+//	    }
+//	}
+package redis
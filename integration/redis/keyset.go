@@ -0,0 +1,271 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/order"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+const (
+	// ScorePath is the keyset path of the boundary entry's score.
+	ScorePath = "score"
+	// MemberPath is the keyset path of the boundary entry's member.
+	MemberPath = "member"
+)
+
+// ErrInvalidKeyset is returned for keysets that do not consist of ScorePath
+// followed by MemberPath, both in the same order.
+var ErrInvalidKeyset = errors.New("keyset must contain score and member in the same order")
+
+// Boundary is the last entry of the previous page.
+type Boundary struct {
+	Score  float64
+	Member string
+	Order  order.Order
+}
+
+// ParseKeyset extracts the boundary from keyset. It reports false for an
+// empty keyset, i.e. the first page.
+func ParseKeyset(keyset *pagetoken.KeysetPayload) (Boundary, bool, error) {
+	if keyset == nil || len(keyset.Values()) == 0 {
+		return Boundary{}, false, nil
+	}
+
+	vs := keyset.Values()
+	if len(vs) != 2 || vs[0].Path != ScorePath || vs[1].Path != MemberPath || vs[0].Order != vs[1].Order {
+		return Boundary{}, false, ErrInvalidKeyset
+	}
+
+	score, o, err := keyset.Float64(ScorePath)
+	if err != nil {
+		return Boundary{}, false, err
+	}
+
+	member, _, err := keyset.String(MemberPath)
+	if err != nil {
+		return Boundary{}, false, err
+	}
+
+	return Boundary{Score: score, Member: member, Order: o}, true, nil
+}
+
+// NextPayload builds the keyset of the page following entry.
+func NextPayload(entry goredis.Z, o order.Order) (*pagetoken.KeysetPayload, error) {
+	member, ok := entry.Member.(string)
+	if !ok {
+		return nil, fmt.Errorf("unsupported member type %T", entry.Member)
+	}
+
+	return pagetoken.NewKeysetPayloadBuilder().
+		AddFloat64(ScorePath, entry.Score, o).
+		AddString(MemberPath, member, o).
+		Build(), nil
+}
+
+func formatScore(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "+inf"
+	case math.IsInf(f, -1):
+		return "-inf"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// ScoreRange returns the ZRANGEBYSCORE arguments, or ZREVRANGEBYSCORE ones
+// for descending boundaries, selecting up to count entries whose score lies
+// strictly past the boundary score:
+//
+//	ZRANGEBYSCORE key (score +inf LIMIT 0 count
+//	ZREVRANGEBYSCORE key (score -inf LIMIT 0 count
+func ScoreRange(b Boundary, count int64) *goredis.ZRangeBy {
+	if b.Order == order.Desc {
+		return &goredis.ZRangeBy{Min: "-inf", Max: "(" + formatScore(b.Score), Count: count}
+	}
+	return &goredis.ZRangeBy{Min: "(" + formatScore(b.Score), Max: "+inf", Count: count}
+}
+
+// TiedRange returns the ZRANGEBYSCORE (or ZREVRANGEBYSCORE) arguments
+// selecting the entries that share the boundary score. Redis returns them in
+// member order, so the ones past the boundary follow after the boundary
+// member.
+func TiedRange(b Boundary, offset, count int64) *goredis.ZRangeBy {
+	s := formatScore(b.Score)
+	return &goredis.ZRangeBy{Min: s, Max: s, Offset: offset, Count: count}
+}
+
+// TieRange returns the ZRANGEBYLEX arguments, or ZREVRANGEBYLEX ones for
+// descending boundaries, selecting the members strictly past the boundary
+// member:
+//
+//	ZRANGEBYLEX key (member + LIMIT 0 count
+//	ZREVRANGEBYLEX key (member - LIMIT 0 count
+//
+// Redis only defines the result for sets whose members all share one score,
+// such as pure lexical indexes. Page does not rely on it.
+func TieRange(b Boundary, count int64) *goredis.ZRangeBy {
+	if b.Order == order.Desc {
+		return &goredis.ZRangeBy{Min: "-", Max: "(" + b.Member, Count: count}
+	}
+	return &goredis.ZRangeBy{Min: "(" + b.Member, Max: "+", Count: count}
+}
+
+// past reports whether member sorts strictly after the boundary member in
+// the direction of the boundary. Go compares strings byte-wise, just like
+// Redis compares members.
+func (b Boundary) past(member string) bool {
+	if b.Order == order.Desc {
+		return member < b.Member
+	}
+	return member > b.Member
+}
+
+func rangeByScore(ctx context.Context, rdb goredis.Cmdable, key string, o order.Order, by *goredis.ZRangeBy) ([]goredis.Z, error) {
+	if o == order.Desc {
+		return rdb.ZRevRangeByScoreWithScores(ctx, key, by).Result()
+	}
+	return rdb.ZRangeByScoreWithScores(ctx, key, by).Result()
+}
+
+// tiedRank returns the position of the boundary member among the entries
+// sharing the boundary score, taken from its rank in one transaction, so
+// Page can continue the tied entries right after it. It returns 0 if the
+// member is gone or has moved to another score.
+func tiedRank(ctx context.Context, rdb goredis.Cmdable, key string, b Boundary) (int64, error) {
+	var (
+		score        *goredis.FloatCmd
+		rank, before *goredis.IntCmd
+	)
+
+	_, err := rdb.TxPipelined(ctx, func(p goredis.Pipeliner) error {
+		score = p.ZScore(ctx, key, b.Member)
+		if b.Order == order.Desc {
+			rank = p.ZRevRank(ctx, key, b.Member)
+			before = p.ZCount(ctx, key, "("+formatScore(b.Score), "+inf")
+		} else {
+			rank = p.ZRank(ctx, key, b.Member)
+			before = p.ZCount(ctx, key, "-inf", "("+formatScore(b.Score))
+		}
+		return nil
+	})
+	if errors.Is(err, goredis.Nil) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if score.Val() != b.Score {
+		return 0, nil
+	}
+	return rank.Val() - before.Val(), nil
+}
+
+// Page loads up to limit entries of the sorted set key following keyset, or
+// the first page in order o if keyset is empty, and returns them together
+// with the keyset of the next page. The next keyset is nil once the last
+// page has been loaded. If limit is not positive,
+// pagetoken.DefaultPageSize is used.
+//
+// Entries sharing the boundary score are continued from the rank of the
+// boundary member. If it was removed or its score changed, they are scanned
+// from the first one with the same score instead.
+func Page(
+	ctx context.Context,
+	rdb goredis.Cmdable,
+	key string,
+	keyset *pagetoken.KeysetPayload,
+	o order.Order,
+	limit int,
+) ([]goredis.Z, *pagetoken.KeysetPayload, error) {
+	b, ok, err := ParseKeyset(keyset)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if limit <= 0 {
+		limit = pagetoken.DefaultPageSize
+	}
+
+	want := int64(limit) + 1
+	entries := []goredis.Z{}
+
+	if !ok {
+		entries, err = rangeByScore(ctx, rdb, key, o, &goredis.ZRangeBy{Min: "-inf", Max: "+inf", Count: want})
+		if err != nil {
+			return nil, nil, err
+		}
+	} else {
+		if b.Order != o {
+			return nil, nil, ErrInvalidKeyset
+		}
+
+		// entries tied with the boundary score, compared by member
+		offset, err := tiedRank(ctx, rdb, key, b)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// a range starting at the boundary member holds the member itself,
+		// which tells whether the tied entries changed since it was ranked
+		checked := offset == 0
+		for int64(len(entries)) < want {
+			n := want
+			if !checked {
+				n++
+			}
+
+			tied, err := rangeByScore(ctx, rdb, key, o, TiedRange(b, offset, n))
+			if err != nil {
+				return nil, nil, err
+			}
+
+			if !checked {
+				checked = true
+				if len(tied) == 0 || tied[0].Member != b.Member {
+					// scan the tied entries from the first one instead
+					offset = 0
+					continue
+				}
+			}
+
+			for _, z := range tied {
+				if m, ok := z.Member.(string); ok && b.past(m) && int64(len(entries)) < want {
+					entries = append(entries, z)
+				}
+			}
+
+			if int64(len(tied)) < n {
+				break
+			}
+			offset += n
+		}
+
+		// entries past the boundary score
+		if n := want - int64(len(entries)); n > 0 {
+			rest, err := rangeByScore(ctx, rdb, key, o, ScoreRange(b, n))
+			if err != nil {
+				return nil, nil, err
+			}
+			entries = append(entries, rest...)
+		}
+	}
+
+	if int64(len(entries)) < want {
+		return entries, nil, nil
+	}
+	entries = entries[:limit]
+
+	next, err := NextPayload(entries[limit-1], o)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return entries, next, nil
+}
@@ -0,0 +1,204 @@
+package redis_test
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/alicebob/miniredis/v2"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	goredis "github.com/redis/go-redis/v9"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/integration/redis"
+	"github.com/pixlcrashr/go-pagetoken/order"
+)
+
+const leaderboard = "leaderboard"
+
+// walk pages through the leaderboard, calling between after every page with
+// the boundary entry, and returns the visited members in order.
+func walk(ctx context.Context, rdb *goredis.Client, o order.Order, limit int, between func(boundary goredis.Z)) []string {
+	var keyset *pagetoken.KeysetPayload
+	seen := []string{}
+
+	for {
+		entries, next, err := redis.Page(ctx, rdb, leaderboard, keyset, o, limit)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(len(entries)).To(BeNumerically("<=", limit))
+
+		for _, z := range entries {
+			seen = append(seen, z.Member.(string))
+		}
+
+		if next == nil {
+			return seen
+		}
+		keyset = next
+
+		if between != nil {
+			between(entries[len(entries)-1])
+		}
+	}
+}
+
+// tiedRanges counts the range commands selecting the entries of a single
+// score.
+type tiedRanges struct {
+	n int
+}
+
+func (h *tiedRanges) DialHook(next goredis.DialHook) goredis.DialHook {
+	return next
+}
+
+func (h *tiedRanges) ProcessHook(next goredis.ProcessHook) goredis.ProcessHook {
+	return func(ctx context.Context, cmd goredis.Cmder) error {
+		args := cmd.Args()
+		if (cmd.Name() == "zrangebyscore" || cmd.Name() == "zrevrangebyscore") && args[2] == args[3] {
+			h.n++
+		}
+		return next(ctx, cmd)
+	}
+}
+
+func (h *tiedRanges) ProcessPipelineHook(next goredis.ProcessPipelineHook) goredis.ProcessPipelineHook {
+	return next
+}
+
+var _ = Describe("Page", func() {
+	var (
+		ctx context.Context
+		rdb *goredis.Client
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		mr := miniredis.RunT(GinkgoT())
+		rdb = goredis.NewClient(&goredis.Options{Addr: mr.Addr()})
+		DeferCleanup(rdb.Close)
+
+		// scores repeat in groups of four, so ties span pages of three
+		zs := []goredis.Z{}
+		for i := range 14 {
+			zs = append(zs, goredis.Z{Score: float64(i / 4), Member: fmt.Sprintf("player-%02d", i)})
+		}
+		Expect(rdb.ZAdd(ctx, leaderboard, zs...).Err()).To(Succeed())
+	})
+
+	for _, o := range []order.Order{order.Asc, order.Desc} {
+		Describe("in "+o.String()+" order", func() {
+			var all []string
+
+			BeforeEach(func() {
+				var err error
+				if o == order.Desc {
+					all, err = rdb.ZRevRange(ctx, leaderboard, 0, -1).Result()
+				} else {
+					all, err = rdb.ZRange(ctx, leaderboard, 0, -1).Result()
+				}
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("visits every entry exactly once across score ties", func() {
+				for _, limit := range []int{1, 2, 3, 5, 14, 20} {
+					Expect(walk(ctx, rdb, o, limit, nil)).To(Equal(all), "limit %d", limit)
+				}
+			})
+
+			It("loses no entries when the boundary entry is removed between pages", func() {
+				seen := walk(ctx, rdb, o, 3, func(boundary goredis.Z) {
+					Expect(rdb.ZRem(ctx, leaderboard, boundary.Member).Err()).To(Succeed())
+				})
+				Expect(seen).To(Equal(all))
+			})
+		})
+	}
+
+	It("continues ties from the boundary member", func() {
+		zs := []goredis.Z{}
+		for i := range 30 {
+			zs = append(zs, goredis.Z{Score: 10, Member: fmt.Sprintf("tied-%02d", i)})
+		}
+		Expect(rdb.ZAdd(ctx, leaderboard, zs...).Err()).To(Succeed())
+
+		h := &tiedRanges{}
+		rdb.AddHook(h)
+
+		Expect(walk(ctx, rdb, order.Asc, 2, nil)).To(HaveLen(44))
+		// one tied range for each of the 21 pages after the first
+		Expect(h.n).To(Equal(21))
+	})
+
+	for _, limit := range []int{0, -1} {
+		It(fmt.Sprintf("loads DefaultPageSize entries for a limit of %d", limit), func() {
+			for i := range 10 {
+				Expect(rdb.ZAdd(ctx, leaderboard, goredis.Z{Score: 10, Member: fmt.Sprintf("late-%02d", i)}).Err()).To(Succeed())
+			}
+
+			entries, next, err := redis.Page(ctx, rdb, leaderboard, nil, order.Asc, limit)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(entries).To(HaveLen(pagetoken.DefaultPageSize))
+			Expect(next).NotTo(BeNil())
+		})
+	}
+
+	It("rejects a keyset of a different order", func() {
+		keyset := pagetoken.NewKeysetPayloadBuilder().
+			AddFloat64(redis.ScorePath, 1, order.Asc).
+			AddString(redis.MemberPath, "player-04", order.Asc).
+			Build()
+
+		_, _, err := redis.Page(ctx, rdb, leaderboard, keyset, order.Desc, 3)
+		Expect(err).To(MatchError(redis.ErrInvalidKeyset))
+	})
+})
+
+var _ = Describe("ParseKeyset", func() {
+	It("reports the first page for an empty keyset", func() {
+		_, ok, err := redis.ParseKeyset(nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeFalse())
+	})
+
+	It("rejects keysets with other paths", func() {
+		_, _, err := redis.ParseKeyset(pagetoken.NewKeysetPayloadBuilder().
+			AddFloat64(redis.ScorePath, 1, order.Asc).
+			AddString("id", "x", order.Asc).
+			Build())
+		Expect(err).To(MatchError(redis.ErrInvalidKeyset))
+	})
+
+	It("rejects keysets with mixed orders", func() {
+		_, _, err := redis.ParseKeyset(pagetoken.NewKeysetPayloadBuilder().
+			AddFloat64(redis.ScorePath, 1, order.Asc).
+			AddString(redis.MemberPath, "x", order.Desc).
+			Build())
+		Expect(err).To(MatchError(redis.ErrInvalidKeyset))
+	})
+})
+
+var _ = Describe("ranges", func() {
+	asc := redis.Boundary{Score: 2.5, Member: "bob", Order: order.Asc}
+	desc := redis.Boundary{Score: 2.5, Member: "bob", Order: order.Desc}
+
+	It("uses exclusive score bounds", func() {
+		Expect(redis.ScoreRange(asc, 10)).To(Equal(&goredis.ZRangeBy{Min: "(2.5", Max: "+inf", Count: 10}))
+		Expect(redis.ScoreRange(desc, 10)).To(Equal(&goredis.ZRangeBy{Min: "-inf", Max: "(2.5", Count: 10}))
+	})
+
+	It("formats infinite scores", func() {
+		b := redis.Boundary{Score: math.Inf(-1), Order: order.Asc}
+		Expect(redis.ScoreRange(b, 1).Min).To(Equal("(-inf"))
+	})
+
+	It("selects ties by the boundary score", func() {
+		Expect(redis.TiedRange(asc, 4, 10)).To(Equal(&goredis.ZRangeBy{Min: "2.5", Max: "2.5", Offset: 4, Count: 10}))
+	})
+
+	It("uses exclusive lexical bounds", func() {
+		Expect(redis.TieRange(asc, 10)).To(Equal(&goredis.ZRangeBy{Min: "(bob", Max: "+", Count: 10}))
+		Expect(redis.TieRange(desc, 10)).To(Equal(&goredis.ZRangeBy{Min: "-", Max: "(bob", Count: 10}))
+	})
+})
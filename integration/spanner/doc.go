@@ -0,0 +1,42 @@
+// Package spanner adapts keyset page tokens to Cloud Spanner queries.
+//
+// It mirrors the GORM integration for Spanner's SQL dialect: the boundary of
+// the previous page is turned into a WHERE fragment using named @param
+// placeholders, and the last row of the current page is turned back into a
+// KeysetPayload for the next token.
+//
+// The package lives in its own Go module so that depending on the core
+// pagetoken package does not pull in the Spanner client.
+//
+// # Example
+//
+//	spec := pagetoken.SortSpec{
+//	    {Path: "CreatedAt", Order: order.Desc},
+//	    {Path: "Id", Order: order.Asc},
+//	}
+//
+//	where, params, err := spanner.KeysetWhere(t.Payload(), spanner.ColumnValues(map[string]spanner.KeysetValueFn{
+//	    "CreatedAt": spanner.TimeValue,
+//	    "Id":        spanner.StringValue,
+//	}))
+//	if err != nil {
+//	    return err
+//	}
+//
+//	sql := "SELECT Id, Title, CreatedAt FROM Books"
+//	if where != "" {
+//	    sql += " WHERE " + where
+//	}
+//	sql += fmt.Sprintf(" ORDER BY %s LIMIT %d", spanner.KeysetOrderBy(spec), pageSize+1)
+//
+//	// ... run the query, keep pageSize rows ...
+//
+//	next, err := spanner.NextPayload(lastRow, spec)
+//
+// # Testing
+//
+// The SQL generation is covered by plain unit tests. Specs that need the
+// Spanner emulator are guarded by the "emulator" build tag:
+//
+//	SPANNER_EMULATOR_HOST=localhost:9010 go test -tags emulator ./...
+package spanner
@@ -0,0 +1,228 @@
+//go:build emulator
+
+package spanner_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	gspanner "cloud.google.com/go/spanner"
+	database "cloud.google.com/go/spanner/admin/database/apiv1"
+	"cloud.google.com/go/spanner/admin/database/apiv1/databasepb"
+	instance "cloud.google.com/go/spanner/admin/instance/apiv1"
+	"cloud.google.com/go/spanner/admin/instance/apiv1/instancepb"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/api/iterator"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/integration/spanner"
+	"github.com/pixlcrashr/go-pagetoken/order"
+)
+
+// These specs run against the Cloud Spanner emulator:
+//
+//	docker run -p 9010:9010 gcr.io/cloud-spanner-emulator/emulator
+//	SPANNER_EMULATOR_HOST=localhost:9010 go test -tags emulator ./...
+var _ = Describe("Emulator", Ordered, func() {
+	const (
+		projectID  = "pagetoken"
+		instanceID = "pagetoken"
+	)
+
+	var (
+		ctx    context.Context
+		client *gspanner.Client
+		ids    []string
+		// archived holds the ArchivedAt of every book by ID, nil for NULL.
+		archived = map[string]*time.Time{}
+	)
+
+	BeforeAll(func() {
+		if os.Getenv("SPANNER_EMULATOR_HOST") == "" {
+			Skip("SPANNER_EMULATOR_HOST is not set")
+		}
+
+		ctx = context.Background()
+		dbID := fmt.Sprintf("books-%d", time.Now().UnixNano()%1_000_000)
+
+		ic, err := instance.NewInstanceAdminClient(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(ic.Close)
+
+		iop, err := ic.CreateInstance(ctx, &instancepb.CreateInstanceRequest{
+			Parent:     "projects/" + projectID,
+			InstanceId: instanceID,
+			Instance: &instancepb.Instance{
+				Config:      "projects/" + projectID + "/instanceConfigs/emulator-config",
+				DisplayName: instanceID,
+				NodeCount:   1,
+			},
+		})
+		if err == nil {
+			_, err = iop.Wait(ctx)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		dc, err := database.NewDatabaseAdminClient(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(dc.Close)
+
+		dop, err := dc.CreateDatabase(ctx, &databasepb.CreateDatabaseRequest{
+			Parent:          "projects/" + projectID + "/instances/" + instanceID,
+			CreateStatement: "CREATE DATABASE `" + dbID + "`",
+			ExtraStatements: []string{
+				`CREATE TABLE Books (
+					Id        STRING(36) NOT NULL,
+					Title     STRING(100) NOT NULL,
+					CreatedAt TIMESTAMP NOT NULL,
+					ArchivedAt TIMESTAMP,
+				) PRIMARY KEY (Id)`,
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = dop.Wait(ctx)
+		Expect(err).NotTo(HaveOccurred())
+
+		client, err = gspanner.NewClient(ctx, "projects/"+projectID+"/instances/"+instanceID+"/databases/"+dbID)
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(client.Close)
+
+		// 25 books in groups of three sharing a timestamp, so that ties span
+		// page boundaries. Every other book is not archived, so that NULLs
+		// span page boundaries as well.
+		base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		ms := []*gspanner.Mutation{}
+		for i := 0; i < 25; i++ {
+			id := fmt.Sprintf("b-%03d", i)
+			archivedAt := gspanner.NullTime{}
+			if i%2 == 1 {
+				t := base.Add(time.Duration(i/4) * time.Hour)
+				archivedAt = gspanner.NullTime{Time: t, Valid: true}
+				archived[id] = &t
+			} else {
+				archived[id] = nil
+			}
+			ms = append(ms, gspanner.Insert("Books",
+				[]string{"Id", "Title", "CreatedAt", "ArchivedAt"},
+				[]any{id, fmt.Sprintf("Book %03d", i), base.Add(time.Duration(i/3) * time.Minute), archivedAt},
+			))
+		}
+		_, err = client.Apply(ctx, ms)
+		Expect(err).NotTo(HaveOccurred())
+
+		// expected order: CreatedAt DESC, Id ASC
+		for g := 24 / 3; g >= 0; g-- {
+			for i := g * 3; i < g*3+3 && i < 25; i++ {
+				ids = append(ids, fmt.Sprintf("b-%03d", i))
+			}
+		}
+	})
+
+	// walk returns the IDs of all books in the order of spec, read in pages
+	// of four.
+	walk := func(spec pagetoken.SortSpec, valueFn spanner.KeysetValueFn) []string {
+		const pageSize = 4
+
+		var keyset *pagetoken.KeysetPayload
+		seen := []string{}
+
+		for {
+			where, params, err := spanner.KeysetWhere(keyset, valueFn)
+			Expect(err).NotTo(HaveOccurred())
+
+			sql := "SELECT Id, CreatedAt, ArchivedAt FROM Books"
+			if where != "" {
+				sql += " WHERE " + where
+			}
+			sql += fmt.Sprintf(" ORDER BY %s LIMIT %d", spanner.KeysetOrderBy(spec), pageSize+1)
+
+			rows := []*gspanner.Row{}
+			it := client.Single().Query(ctx, gspanner.Statement{SQL: sql, Params: params})
+			for {
+				row, err := it.Next()
+				if err == iterator.Done {
+					break
+				}
+				Expect(err).NotTo(HaveOccurred())
+				rows = append(rows, row)
+			}
+			it.Stop()
+
+			page := rows
+			if len(rows) > pageSize {
+				page = rows[:pageSize]
+			}
+			for _, row := range page {
+				var id string
+				Expect(row.ColumnByName("Id", &id)).To(Succeed())
+				seen = append(seen, id)
+			}
+
+			if len(rows) <= pageSize {
+				break
+			}
+
+			keyset, err = spanner.NextPayload(page[len(page)-1], spec)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		return seen
+	}
+
+	It("walks every row exactly once across pages", func() {
+		seen := walk(pagetoken.SortSpec{
+			{Path: "CreatedAt", Order: order.Desc},
+			{Path: "Id", Order: order.Asc},
+		}, spanner.ColumnValues(map[string]spanner.KeysetValueFn{
+			"CreatedAt": spanner.TimeValue,
+			"Id":        spanner.StringValue,
+		}))
+
+		Expect(seen).To(Equal(ids))
+	})
+
+	DescribeTable("walks every row exactly once with NULL sort values",
+		func(o order.Order) {
+			seen := walk(pagetoken.SortSpec{
+				{Path: "ArchivedAt", Order: o},
+				{Path: "Id", Order: order.Asc},
+			}, spanner.ColumnValues(map[string]spanner.KeysetValueFn{
+				"ArchivedAt": spanner.NullTimeValue,
+				"Id":         spanner.StringValue,
+			}))
+
+			// Spanner sorts NULLs first in ascending and last in
+			// descending order
+			want := slices.Clone(ids)
+			slices.SortFunc(want, func(a, b string) int {
+				ta, tb := archived[a], archived[b]
+				c := 0
+				switch {
+				case ta == nil && tb == nil:
+				case ta == nil:
+					c = -1
+				case tb == nil:
+					c = 1
+				default:
+					c = ta.Compare(*tb)
+				}
+				if o == order.Desc {
+					c = -c
+				}
+				if c == 0 {
+					c = strings.Compare(a, b)
+				}
+				return c
+			})
+
+			Expect(seen).To(Equal(want))
+		},
+		Entry("ascending", order.Asc),
+		Entry("descending", order.Desc),
+	)
+})
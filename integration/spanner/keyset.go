@@ -0,0 +1,151 @@
+package spanner
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"cloud.google.com/go/spanner"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/order"
+)
+
+// ParamPrefix is prepended to the index of each keyset value to form the
+// Spanner query parameter name (@pt_ks0, @pt_ks1, ...). Pick parameter names
+// for your own filters that do not start with it.
+const ParamPrefix = "pt_ks"
+
+// KeysetValueFn resolves the Spanner parameter value for a keyset column.
+// The returned value is passed to spanner.Statement.Params unchanged, so it
+// must be of a type the Spanner client can encode (string, int64, float64,
+// bool, time.Time, civil.Date, spanner.NullTime, ...).
+type KeysetValueFn func(column string, payload *pagetoken.KeysetPayload) (any, error)
+
+func orderToSQL(o order.Order) string {
+	if o == order.Asc {
+		return "ASC"
+	}
+
+	return "DESC"
+}
+
+func paramName(i int) string {
+	return ParamPrefix + strconv.Itoa(i)
+}
+
+// KeysetWhere builds a boolean Spanner SQL expression selecting the rows after
+// the boundary described by keyset, together with the named parameters it
+// references. Every keyset value is bound exactly once, so a three-column
+// keyset always uses @pt_ks0..@pt_ks2 regardless of how often a column
+// appears in the expanded expression.
+//
+// An empty (or nil) keyset yields an empty expression and nil params; callers
+// should omit the WHERE fragment in that case.
+//
+// Columns whose values are resolved to a spanner.NullableValue, such as the
+// spanner.NullTime bound by NullTimeValue, are treated as nullable, following
+// Spanner's ordering of NULLs first in ascending and last in descending
+// order, so that pagination continues past rows with NULL sort values: NULL
+// values are not bound but compared with IS NULL and IS NOT NULL, and rows
+// with NULL sort after any other value of a descending column. A keyset no
+// row can follow, i.e. a NULL in the only or all descending columns, yields
+// FALSE.
+//
+// Example:
+//
+//	where, params, err := spanner.KeysetWhere(payload, spanner.ColumnValues(map[string]spanner.KeysetValueFn{
+//	    "CreatedAt": spanner.TimeValue,
+//	    "Id":        spanner.StringValue,
+//	}))
+//	stmt := gspanner.Statement{
+//	    SQL:    "SELECT * FROM Books WHERE " + where + " ORDER BY " + spanner.KeysetOrderBy(spec) + " LIMIT 21",
+//	    Params: params,
+//	}
+func KeysetWhere(
+	keyset *pagetoken.KeysetPayload,
+	valueFn KeysetValueFn,
+) (string, map[string]any, error) {
+	if keyset == nil {
+		return "", nil, nil
+	}
+
+	vs := keyset.Values()
+	if len(vs) == 0 {
+		return "", nil, nil
+	}
+
+	params := make(map[string]any, len(vs))
+	nullable, null := make([]bool, len(vs)), make([]bool, len(vs))
+	for i, v := range vs {
+		pV, err := valueFn(v.Path, keyset)
+		if err != nil {
+			return "", nil, err
+		}
+		if n, ok := pV.(spanner.NullableValue); ok {
+			nullable[i], null[i] = true, n.IsNull()
+		}
+		if null[i] {
+			continue
+		}
+		params[paramName(i)] = pV
+	}
+
+	orExprs := make([]string, 0, len(vs))
+	for i := 0; i < len(vs); i++ {
+		after, ok := afterExpr(vs[i], i, nullable[i], null[i])
+		if !ok {
+			// no row sorts after NULL in descending order
+			continue
+		}
+
+		andExprs := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			if null[j] {
+				andExprs = append(andExprs, vs[j].Path+" IS NULL")
+				continue
+			}
+			andExprs = append(andExprs, fmt.Sprintf("%s = @%s", vs[j].Path, paramName(j)))
+		}
+		andExprs = append(andExprs, after)
+
+		orExprs = append(orExprs, "("+strings.Join(andExprs, " AND ")+")")
+	}
+
+	if len(orExprs) == 0 {
+		return "FALSE", params, nil
+	}
+
+	return "(" + strings.Join(orExprs, " OR ") + ")", params, nil
+}
+
+// afterExpr returns the expression selecting the values of the column of v
+// sorting after the i-th keyset value, which is NULL if null is set. It
+// reports false if there are none.
+func afterExpr(v pagetoken.KeysetValue, i int, nullable, null bool) (string, bool) {
+	switch {
+	case null && v.Order == order.Desc:
+		return "", false
+	case null:
+		return v.Path + " IS NOT NULL", true
+	case v.Order == order.Desc && nullable:
+		return fmt.Sprintf("(%s < @%s OR %s IS NULL)", v.Path, paramName(i), v.Path), true
+	case v.Order == order.Desc:
+		return fmt.Sprintf("%s < @%s", v.Path, paramName(i)), true
+	default:
+		return fmt.Sprintf("%s > @%s", v.Path, paramName(i)), true
+	}
+}
+
+// KeysetOrderBy renders spec as the body of an ORDER BY clause, e.g.
+// "CreatedAt DESC, Id ASC". It is derived from the SortSpec rather than the
+// keyset so that the first page (which has no keyset yet) is ordered the same
+// way as every following page.
+func KeysetOrderBy(spec pagetoken.SortSpec) string {
+	exprs := make([]string, 0, len(spec))
+	for _, f := range spec {
+		exprs = append(exprs, fmt.Sprintf("%s %s", f.Path, orderToSQL(f.Order)))
+	}
+
+	return strings.Join(exprs, ", ")
+}
@@ -0,0 +1,177 @@
+package spanner_test
+
+import (
+	"time"
+
+	"cloud.google.com/go/civil"
+	gspanner "cloud.google.com/go/spanner"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/integration/spanner"
+	"github.com/pixlcrashr/go-pagetoken/order"
+)
+
+var _ = Describe("KeysetWhere", func() {
+	It("returns an empty expression for a nil keyset", func() {
+		where, params, err := spanner.KeysetWhere(nil, spanner.StringValue)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(where).To(BeEmpty())
+		Expect(params).To(BeNil())
+	})
+
+	It("returns an empty expression for an empty keyset", func() {
+		where, params, err := spanner.KeysetWhere(pagetoken.NewKeysetPayloadBuilder().Build(), spanner.StringValue)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(where).To(BeEmpty())
+		Expect(params).To(BeNil())
+	})
+
+	It("renders a single ascending column", func() {
+		p := pagetoken.NewKeysetPayloadBuilder().
+			AddString("Id", "b-42", order.Asc).
+			Build()
+
+		where, params, err := spanner.KeysetWhere(p, spanner.StringValue)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(where).To(Equal("((Id > @pt_ks0))"))
+		Expect(params).To(Equal(map[string]any{"pt_ks0": "b-42"}))
+	})
+
+	It("renders a single descending column", func() {
+		p := pagetoken.NewKeysetPayloadBuilder().
+			AddInt64("Rank", 7, order.Desc).
+			Build()
+
+		where, params, err := spanner.KeysetWhere(p, spanner.Int64Value)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(where).To(Equal("((Rank < @pt_ks0))"))
+		Expect(params).To(Equal(map[string]any{"pt_ks0": int64(7)}))
+	})
+
+	It("renders mixed directions and binds each value once", func() {
+		ts := time.Date(2024, 6, 15, 12, 30, 45, 0, time.UTC)
+		p := pagetoken.NewKeysetPayloadBuilder().
+			AddTime("CreatedAt", ts, order.Desc).
+			AddString("Title", "Dune", order.Asc).
+			AddString("Id", "b-42", order.Asc).
+			Build()
+
+		where, params, err := spanner.KeysetWhere(p, spanner.ColumnValues(map[string]spanner.KeysetValueFn{
+			"CreatedAt": spanner.TimeValue,
+			"Title":     spanner.StringValue,
+			"Id":        spanner.StringValue,
+		}))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(where).To(Equal(
+			"((CreatedAt < @pt_ks0)" +
+				" OR (CreatedAt = @pt_ks0 AND Title > @pt_ks1)" +
+				" OR (CreatedAt = @pt_ks0 AND Title = @pt_ks1 AND Id > @pt_ks2))",
+		))
+		Expect(params).To(Equal(map[string]any{
+			"pt_ks0": ts,
+			"pt_ks1": "Dune",
+			"pt_ks2": "b-42",
+		}))
+	})
+
+	It("binds DATE columns as civil.Date", func() {
+		p := pagetoken.NewKeysetPayloadBuilder().
+			AddString("PublishedOn", "2024-02-29", order.Asc).
+			Build()
+
+		_, params, err := spanner.KeysetWhere(p, spanner.DateValue)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(params["pt_ks0"]).To(Equal(civil.Date{Year: 2024, Month: time.February, Day: 29}))
+	})
+
+	It("binds a TIMESTAMP value of a nullable column as a valid NullTime", func() {
+		ts := time.Date(2024, 6, 15, 12, 30, 45, 0, time.UTC)
+		p := pagetoken.NewKeysetPayloadBuilder().
+			AddTime("DeletedAt", ts, order.Asc).
+			Build()
+
+		where, params, err := spanner.KeysetWhere(p, spanner.NullTimeValue)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(where).To(Equal("((DeletedAt > @pt_ks0))"))
+		Expect(params).To(Equal(map[string]any{"pt_ks0": gspanner.NullTime{Time: ts, Valid: true}}))
+	})
+
+	Describe("NULL values", func() {
+		valueFn := spanner.ColumnValues(map[string]spanner.KeysetValueFn{
+			"DeletedAt": spanner.NullTimeValue,
+			"Id":        spanner.StringValue,
+		})
+		keyset := func(o order.Order, deletedAt string) *pagetoken.KeysetPayload {
+			return pagetoken.NewKeysetPayloadBuilder().
+				AddString("DeletedAt", deletedAt, o).
+				AddString("Id", "b-42", order.Asc).
+				Build()
+		}
+
+		It("selects the rows after a NULL in ascending order", func() {
+			where, params, err := spanner.KeysetWhere(keyset(order.Asc, ""), valueFn)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(where).To(Equal("((DeletedAt IS NOT NULL) OR (DeletedAt IS NULL AND Id > @pt_ks1))"))
+			Expect(params).To(Equal(map[string]any{"pt_ks1": "b-42"}))
+		})
+
+		It("selects only ties after a NULL in descending order", func() {
+			where, params, err := spanner.KeysetWhere(keyset(order.Desc, ""), valueFn)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(where).To(Equal("((DeletedAt IS NULL AND Id > @pt_ks1))"))
+			Expect(params).To(Equal(map[string]any{"pt_ks1": "b-42"}))
+		})
+
+		It("selects NULLs after values of a nullable column in descending order", func() {
+			where, _, err := spanner.KeysetWhere(keyset(order.Desc, "2024-06-15T12:30:45Z"), valueFn)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(where).To(Equal("(((DeletedAt < @pt_ks0 OR DeletedAt IS NULL)) OR (DeletedAt = @pt_ks0 AND Id > @pt_ks1))"))
+		})
+
+		It("selects no rows after a NULL in a single descending column", func() {
+			p := pagetoken.NewKeysetPayloadBuilder().
+				AddString("DeletedAt", "", order.Desc).
+				Build()
+
+			where, params, err := spanner.KeysetWhere(p, spanner.NullTimeValue)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(where).To(Equal("FALSE"))
+			Expect(params).To(BeEmpty())
+		})
+	})
+
+	It("rejects columns without a registered value function", func() {
+		p := pagetoken.NewKeysetPayloadBuilder().
+			AddString("Secret", "x", order.Asc).
+			Build()
+
+		_, _, err := spanner.KeysetWhere(p, spanner.ColumnValues(map[string]spanner.KeysetValueFn{
+			"Id": spanner.StringValue,
+		}))
+		Expect(err).To(MatchError(spanner.ErrUnknownColumn))
+	})
+
+	It("propagates decode errors", func() {
+		p := pagetoken.NewKeysetPayloadBuilder().
+			AddString("Rank", "not-a-number", order.Asc).
+			Build()
+
+		_, _, err := spanner.KeysetWhere(p, spanner.Int64Value)
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("KeysetOrderBy", func() {
+	It("renders the spec in order", func() {
+		Expect(spanner.KeysetOrderBy(pagetoken.SortSpec{
+			{Path: "CreatedAt", Order: order.Desc},
+			{Path: "Id", Order: order.Asc},
+		})).To(Equal("CreatedAt DESC, Id ASC"))
+	})
+
+	It("renders an empty spec as an empty string", func() {
+		Expect(spanner.KeysetOrderBy(nil)).To(BeEmpty())
+	})
+})
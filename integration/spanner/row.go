@@ -0,0 +1,109 @@
+package spanner
+
+import (
+	"fmt"
+
+	"cloud.google.com/go/spanner"
+	"cloud.google.com/go/spanner/apiv1/spannerpb"
+	"github.com/pixlcrashr/go-pagetoken"
+)
+
+// NextPayload builds the keyset for the page following row, which must be the
+// last row of the current page. Every column named in spec has to be selected
+// by the query; the value is encoded according to the column's Spanner type
+// so that it round-trips through the matching KeysetValueFn:
+//
+//	STRING    -> AddString  (StringValue)
+//	INT64     -> AddInt64   (Int64Value)
+//	FLOAT64   -> AddFloat64 (Float64Value)
+//	BOOL      -> AddBool    (BoolValue)
+//	TIMESTAMP -> AddTime    (TimeValue, NullTimeValue; NULL is stored as "")
+//	DATE      -> AddString  (DateValue; "2006-01-02")
+//	NUMERIC   -> AddString  (canonical decimal string)
+//
+// NULL values are only supported for TIMESTAMP columns.
+func NextPayload(row *spanner.Row, spec pagetoken.SortSpec) (*pagetoken.KeysetPayload, error) {
+	b := pagetoken.NewKeysetPayloadBuilder()
+
+	for _, f := range spec {
+		i, err := row.ColumnIndex(f.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		switch code := row.ColumnType(i).GetCode(); code {
+		case spannerpb.TypeCode_STRING:
+			var v spanner.NullString
+			if err := row.Column(i, &v); err != nil {
+				return nil, err
+			}
+			if !v.Valid {
+				return nil, errNullColumn(f.Path)
+			}
+			b.AddString(f.Path, v.StringVal, f.Order)
+		case spannerpb.TypeCode_INT64:
+			var v spanner.NullInt64
+			if err := row.Column(i, &v); err != nil {
+				return nil, err
+			}
+			if !v.Valid {
+				return nil, errNullColumn(f.Path)
+			}
+			b.AddInt64(f.Path, v.Int64, f.Order)
+		case spannerpb.TypeCode_FLOAT64:
+			var v spanner.NullFloat64
+			if err := row.Column(i, &v); err != nil {
+				return nil, err
+			}
+			if !v.Valid {
+				return nil, errNullColumn(f.Path)
+			}
+			b.AddFloat64(f.Path, v.Float64, f.Order)
+		case spannerpb.TypeCode_BOOL:
+			var v spanner.NullBool
+			if err := row.Column(i, &v); err != nil {
+				return nil, err
+			}
+			if !v.Valid {
+				return nil, errNullColumn(f.Path)
+			}
+			b.AddBool(f.Path, v.Bool, f.Order)
+		case spannerpb.TypeCode_TIMESTAMP:
+			var v spanner.NullTime
+			if err := row.Column(i, &v); err != nil {
+				return nil, err
+			}
+			if !v.Valid {
+				b.AddString(f.Path, "", f.Order)
+				continue
+			}
+			b.AddTime(f.Path, v.Time, f.Order)
+		case spannerpb.TypeCode_DATE:
+			var v spanner.NullDate
+			if err := row.Column(i, &v); err != nil {
+				return nil, err
+			}
+			if !v.Valid {
+				return nil, errNullColumn(f.Path)
+			}
+			b.AddString(f.Path, v.Date.String(), f.Order)
+		case spannerpb.TypeCode_NUMERIC:
+			var v spanner.NullNumeric
+			if err := row.Column(i, &v); err != nil {
+				return nil, err
+			}
+			if !v.Valid {
+				return nil, errNullColumn(f.Path)
+			}
+			b.AddString(f.Path, spanner.NumericString(&v.Numeric), f.Order)
+		default:
+			return nil, fmt.Errorf("column %s: unsupported keyset type %s", f.Path, code)
+		}
+	}
+
+	return b.Build(), nil
+}
+
+func errNullColumn(column string) error {
+	return fmt.Errorf("column %s: NULL is not supported in a keyset for this type", column)
+}
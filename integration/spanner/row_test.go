@@ -0,0 +1,125 @@
+package spanner_test
+
+import (
+	"math/big"
+	"time"
+
+	"cloud.google.com/go/civil"
+	gspanner "cloud.google.com/go/spanner"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/integration/spanner"
+	"github.com/pixlcrashr/go-pagetoken/order"
+)
+
+var _ = Describe("NextPayload", func() {
+	ts := time.Date(2024, 6, 15, 12, 30, 45, 123456000, time.UTC)
+
+	newRow := func(cols []string, vals []any) *gspanner.Row {
+		row, err := gspanner.NewRow(cols, vals)
+		Expect(err).NotTo(HaveOccurred())
+		return row
+	}
+
+	It("encodes every supported column type in spec order", func() {
+		row := newRow(
+			[]string{"Title", "Rank", "Score", "Active", "CreatedAt", "PublishedOn", "Price", "Unused"},
+			[]any{
+				"Dune",
+				int64(7),
+				float64(4.5),
+				true,
+				ts,
+				civil.Date{Year: 2024, Month: time.February, Day: 29},
+				big.NewRat(1999, 100),
+				"ignored",
+			},
+		)
+
+		p, err := spanner.NextPayload(row, pagetoken.SortSpec{
+			{Path: "CreatedAt", Order: order.Desc},
+			{Path: "Title", Order: order.Asc},
+			{Path: "Rank", Order: order.Asc},
+			{Path: "Score", Order: order.Desc},
+			{Path: "Active", Order: order.Asc},
+			{Path: "PublishedOn", Order: order.Asc},
+			{Path: "Price", Order: order.Asc},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		vs := p.Values()
+		Expect(vs).To(HaveLen(7))
+		Expect(vs[0]).To(Equal(pagetoken.KeysetValue{Path: "CreatedAt", Order: order.Desc, Value: "2024-06-15T12:30:45.123456Z"}))
+		Expect(vs[1]).To(Equal(pagetoken.KeysetValue{Path: "Title", Order: order.Asc, Value: "Dune"}))
+		Expect(vs[2]).To(Equal(pagetoken.KeysetValue{Path: "Rank", Order: order.Asc, Value: "7"}))
+		Expect(vs[3]).To(Equal(pagetoken.KeysetValue{Path: "Score", Order: order.Desc, Value: "4.5"}))
+		Expect(vs[4]).To(Equal(pagetoken.KeysetValue{Path: "Active", Order: order.Asc, Value: "true"}))
+		Expect(vs[5]).To(Equal(pagetoken.KeysetValue{Path: "PublishedOn", Order: order.Asc, Value: "2024-02-29"}))
+		Expect(vs[6]).To(Equal(pagetoken.KeysetValue{Path: "Price", Order: order.Asc, Value: "19.990000000"}))
+	})
+
+	It("round-trips through the matching value functions", func() {
+		row := newRow(
+			[]string{"CreatedAt", "PublishedOn", "Id"},
+			[]any{ts, civil.Date{Year: 2024, Month: time.March, Day: 1}, "b-42"},
+		)
+
+		p, err := spanner.NextPayload(row, pagetoken.SortSpec{
+			{Path: "CreatedAt", Order: order.Desc},
+			{Path: "PublishedOn", Order: order.Asc},
+			{Path: "Id", Order: order.Asc},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		_, params, err := spanner.KeysetWhere(p, spanner.ColumnValues(map[string]spanner.KeysetValueFn{
+			"CreatedAt":   spanner.TimeValue,
+			"PublishedOn": spanner.DateValue,
+			"Id":          spanner.StringValue,
+		}))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(params).To(Equal(map[string]any{
+			"pt_ks0": ts,
+			"pt_ks1": civil.Date{Year: 2024, Month: time.March, Day: 1},
+			"pt_ks2": "b-42",
+		}))
+	})
+
+	It("stores a NULL timestamp as an empty value", func() {
+		row := newRow([]string{"DeletedAt"}, []any{gspanner.NullTime{}})
+
+		p, err := spanner.NextPayload(row, pagetoken.SortSpec{{Path: "DeletedAt", Order: order.Asc}})
+		Expect(err).NotTo(HaveOccurred())
+
+		v, _, err := pagetoken.GetKeysetValue(p, "DeletedAt", func(s string) (string, error) { return s, nil })
+		Expect(err).NotTo(HaveOccurred())
+		Expect(v).To(BeEmpty())
+
+		where, params, err := spanner.KeysetWhere(p, spanner.NullTimeValue)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(where).To(Equal("((DeletedAt IS NOT NULL))"))
+		Expect(params).To(BeEmpty())
+	})
+
+	It("rejects NULL values for other types", func() {
+		row := newRow([]string{"Title"}, []any{gspanner.NullString{}})
+
+		_, err := spanner.NextPayload(row, pagetoken.SortSpec{{Path: "Title", Order: order.Asc}})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("fails for columns missing from the row", func() {
+		row := newRow([]string{"Id"}, []any{"b-42"})
+
+		_, err := spanner.NextPayload(row, pagetoken.SortSpec{{Path: "CreatedAt", Order: order.Desc}})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("fails for unsupported column types", func() {
+		row := newRow([]string{"Blob"}, []any{[]byte{1, 2, 3}})
+
+		_, err := spanner.NextPayload(row, pagetoken.SortSpec{{Path: "Blob", Order: order.Asc}})
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,103 @@
+package spanner
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/civil"
+	"cloud.google.com/go/spanner"
+	"github.com/pixlcrashr/go-pagetoken"
+)
+
+// ErrUnknownColumn is returned by the KeysetValueFn built with ColumnValues
+// when the keyset references a column that has no registered value function.
+var ErrUnknownColumn = errors.New("unknown keyset column")
+
+// ColumnValues dispatches to a per-column KeysetValueFn. Keysets are decoded
+// from client-supplied tokens, so a column missing from m is rejected instead
+// of being bound as a plain string.
+func ColumnValues(m map[string]KeysetValueFn) KeysetValueFn {
+	return func(column string, payload *pagetoken.KeysetPayload) (any, error) {
+		fn, ok := m[column]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownColumn, column)
+		}
+
+		return fn(column, payload)
+	}
+}
+
+// --- STRING ---
+
+func StringValue(column string, payload *pagetoken.KeysetPayload) (any, error) {
+	v, _, err := payload.String(column)
+	return v, err
+}
+
+// --- INT64 ---
+
+func Int64Value(column string, payload *pagetoken.KeysetPayload) (any, error) {
+	v, _, err := payload.Int64(column)
+	return v, err
+}
+
+// --- FLOAT64 ---
+
+func Float64Value(column string, payload *pagetoken.KeysetPayload) (any, error) {
+	v, _, err := payload.Float64(column)
+	return v, err
+}
+
+// --- BOOL ---
+
+func BoolValue(column string, payload *pagetoken.KeysetPayload) (any, error) {
+	v, _, err := payload.Bool(column)
+	return v, err
+}
+
+// --- TIMESTAMP ---
+
+func TimeValue(column string, payload *pagetoken.KeysetPayload) (any, error) {
+	v, _, err := payload.Time(column)
+	return v, err
+}
+
+// NullTimeValue binds a nullable TIMESTAMP column. An empty payload value,
+// which is what NextPayload stores for a NULL timestamp, becomes an invalid
+// spanner.NullTime, which KeysetWhere compares with IS NULL rather than
+// binding it.
+func NullTimeValue(column string, payload *pagetoken.KeysetPayload) (any, error) {
+	v, _, err := pagetoken.GetKeysetValue(payload, column, func(s string) (spanner.NullTime, error) {
+		if s == "" {
+			return spanner.NullTime{}, nil
+		}
+
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return spanner.NullTime{}, err
+		}
+		return spanner.NullTime{Time: t, Valid: true}, nil
+	})
+	return v, err
+}
+
+// --- DATE ---
+
+// DateValue binds a DATE column as civil.Date. Both the ISO form written by
+// NextPayload ("2006-01-02") and an RFC 3339 timestamp are accepted; for the
+// latter only the date part in the timestamp's own location is used.
+func DateValue(column string, payload *pagetoken.KeysetPayload) (any, error) {
+	v, _, err := pagetoken.GetKeysetValue(payload, column, func(s string) (civil.Date, error) {
+		if d, err := civil.ParseDate(s); err == nil {
+			return d, nil
+		}
+
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return civil.Date{}, fmt.Errorf("parse date %q: %w", s, err)
+		}
+		return civil.DateOf(t), nil
+	})
+	return v, err
+}
@@ -0,0 +1,39 @@
+// Package bufpool pools the byte buffers used to serialize and encrypt page
+// tokens, so that issuing many tokens in a burst, e.g. per-item cursors,
+// does not allocate a fresh buffer for every step of every token.
+package bufpool
+
+import "sync"
+
+// MaxSize is the largest buffer capacity Put returns to the pool. Larger
+// buffers, e.g. from tokens with huge upstream tokens, are left to the
+// garbage collector so that the pool does not pin their memory.
+const MaxSize = 64 << 10
+
+// DefaultSize is the capacity of newly allocated buffers, which fits the
+// plaintext and ciphertext of typical tokens.
+const DefaultSize = 512
+
+var pool = sync.Pool{
+	New: func() any {
+		b := make([]byte, 0, DefaultSize)
+		return &b
+	},
+}
+
+// Get returns an empty buffer from the pool. The caller owns it until it is
+// passed to Put and must not use it afterwards.
+func Get() *[]byte {
+	b := pool.Get().(*[]byte)
+	*b = (*b)[:0]
+	return b
+}
+
+// Put returns b to the pool, unless its capacity exceeds MaxSize.
+func Put(b *[]byte) {
+	if cap(*b) > MaxSize {
+		return
+	}
+
+	pool.Put(b)
+}
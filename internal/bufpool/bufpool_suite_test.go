@@ -0,0 +1,13 @@
+package bufpool_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestBufpool(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Bufpool Suite")
+}
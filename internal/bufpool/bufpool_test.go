@@ -0,0 +1,33 @@
+package bufpool_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken/internal/bufpool"
+)
+
+var _ = Describe("Bufpool", func() {
+	It("returns empty buffers", func() {
+		b := bufpool.Get()
+		*b = append(*b, "plaintext"...)
+		bufpool.Put(b)
+
+		for range 10 {
+			b := bufpool.Get()
+			Expect(*b).To(BeEmpty())
+			bufpool.Put(b)
+		}
+	})
+
+	It("does not retain oversized buffers", func() {
+		big := make([]byte, 0, bufpool.MaxSize+1)
+		bufpool.Put(&big)
+
+		for range 10 {
+			b := bufpool.Get()
+			Expect(cap(*b)).To(BeNumerically("<=", bufpool.MaxSize))
+			defer bufpool.Put(b)
+		}
+	})
+})
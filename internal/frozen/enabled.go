@@ -0,0 +1,6 @@
+//go:build pagetokendebug
+
+package frozen
+
+// Enabled reports whether the assertions are compiled in.
+const Enabled = true
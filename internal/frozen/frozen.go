@@ -0,0 +1,25 @@
+// Package frozen asserts that values shared between goroutines, such as a
+// RequestReader used by all handlers of a server, are not mutated after
+// construction.
+//
+// The assertions are compiled in with the pagetokendebug build tag only:
+//
+//	go test -race -tags pagetokendebug ./...
+//
+// Guarded types record a fingerprint of their state when they are created
+// and compare it on every use. Without the tag Enabled is false and the
+// comparisons are removed by the compiler.
+package frozen
+
+import "fmt"
+
+// Check panics if the fingerprint got of a value of type typ differs from
+// the fingerprint want recorded at construction. An empty want, i.e. a value
+// that was not created by its constructor, is not checked.
+func Check(typ, want, got string) {
+	if want == "" || want == got {
+		return
+	}
+
+	panic(fmt.Sprintf("pagetoken: %s was modified after construction, which is not safe while it is in use: state %s changed to %s", typ, want, got))
+}
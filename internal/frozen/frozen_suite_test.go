@@ -0,0 +1,13 @@
+package frozen_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestFrozen(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Frozen Suite")
+}
@@ -0,0 +1,19 @@
+package frozen_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken/internal/frozen"
+)
+
+var _ = Describe("Check", func() {
+	It("accepts unchanged and unrecorded state", func() {
+		Expect(func() { frozen.Check("T", "a", "a") }).NotTo(Panic())
+		Expect(func() { frozen.Check("T", "", "b") }).NotTo(Panic())
+	})
+
+	It("panics on changed state", func() {
+		Expect(func() { frozen.Check("T", "a", "b") }).To(PanicWith(ContainSubstring("T was modified after construction")))
+	})
+})
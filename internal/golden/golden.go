@@ -0,0 +1,322 @@
+// Package golden reads, writes and checks the golden token fixtures in
+// testdata/golden, which pin the wire format of page tokens.
+//
+// Every fixture records the logical contents of a token together with the
+// token serialized in one format version, once with StaticCrypter and once
+// with an AEADEncryptor using Key. Fixtures of past versions must keep
+// parsing to their recorded contents; the fixture of the latest version must
+// also match newly serialized tokens byte for byte.
+package golden
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/checksum"
+	"github.com/pixlcrashr/go-pagetoken/encryption"
+	"github.com/pixlcrashr/go-pagetoken/order"
+	"github.com/pixlcrashr/go-pagetoken/pagetokentest"
+)
+
+// Key is the AES-256 key of the AEAD fixtures. It is public and must never be
+// used for anything else.
+var Key = []byte("pagetoken-golden-fixture-key-32b")
+
+// Value is a keyset value of a fixture.
+type Value struct {
+	Path  string `json:"path"`
+	Value string `json:"value"`
+	Order string `json:"order"`
+}
+
+// Composite is the composite payload of a fixture.
+type Composite struct {
+	Parts map[string][]Value `json:"parts"`
+	Done  []string           `json:"done,omitempty"`
+}
+
+// Tokens are the serialized forms of a fixture.
+type Tokens struct {
+	Static string `json:"static"`
+	AEAD   string `json:"aead"`
+}
+
+// Fixture is a golden token. The checksum is built from ChecksumFields by a
+// RequestReader without options.
+type Fixture struct {
+	Version        int         `json:"version"`
+	ChecksumFields [][2]string `json:"checksum_fields"`
+	Checksum       uint32      `json:"checksum"`
+	Keyset         []Value     `json:"keyset"`
+	TotalCount     *int64      `json:"total_count,omitempty"`
+	Snapshot       *time.Time  `json:"snapshot,omitempty"`
+	Upstream       *string     `json:"upstream,omitempty"`
+	Composite      *Composite  `json:"composite,omitempty"`
+	PageIndex      int         `json:"page_index"`
+	IssuedAt       *time.Time  `json:"issued_at,omitempty"`
+	Tokens         Tokens      `json:"tokens"`
+}
+
+// Path returns the path of the fixture of version in dir.
+func Path(dir string, version int) string {
+	return filepath.Join(dir, fmt.Sprintf("v%d.json", version))
+}
+
+// Load reads all fixtures in dir, ordered by version.
+func Load(dir string) ([]Fixture, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "v*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	fs := make([]Fixture, 0, len(paths))
+	for _, p := range paths {
+		bs, err := os.ReadFile(p)
+		if err != nil {
+			return nil, err
+		}
+
+		var f Fixture
+		if err := json.Unmarshal(bs, &f); err != nil {
+			return nil, fmt.Errorf("%s: %w", p, err)
+		}
+		if p != Path(dir, f.Version) {
+			return nil, fmt.Errorf("%s: fixture of version %d", p, f.Version)
+		}
+
+		fs = append(fs, f)
+	}
+
+	slices.SortFunc(fs, func(a, b Fixture) int { return a.Version - b.Version })
+	return fs, nil
+}
+
+// Write writes f to its path in dir.
+func Write(dir string, f Fixture) error {
+	bs, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(Path(dir, f.Version), append(bs, '\n'), 0o644)
+}
+
+// Crypter returns the AEAD crypter of the fixtures.
+func Crypter() encryption.Crypter {
+	e, err := encryption.NewAEADEncryptor(Key)
+	if err != nil {
+		panic(err)
+	}
+
+	return e
+}
+
+type request struct {
+	fields []checksum.BuilderOpt
+}
+
+func (r request) GetChecksumFields() []checksum.BuilderOpt { return r.fields }
+func (request) GetPageToken() string                       { return "" }
+
+func (f Fixture) request() request {
+	r := request{}
+	for _, kv := range f.ChecksumFields {
+		r.fields = append(r.fields, checksum.Field(kv[0], kv[1]))
+	}
+
+	return r
+}
+
+func payload(vs []Value) (*pagetoken.KeysetPayload, error) {
+	fields := make([]pagetoken.KeysetValue, 0, len(vs))
+	for _, v := range vs {
+		var o order.Order
+		if err := o.UnmarshalString(v.Order); err != nil {
+			return nil, err
+		}
+
+		fields = append(fields, pagetoken.KeysetValue{Path: v.Path, Value: v.Value, Order: o})
+	}
+
+	return pagetokentest.Payload(fields...), nil
+}
+
+// Token builds the token f records, encrypted with e. As tokens carrying a
+// payload are derived from the first page with Next, the page index must be
+// at least one.
+func (f Fixture) Token(e encryption.Crypter) (*pagetoken.KeysetToken, error) {
+	if f.PageIndex < 1 {
+		return nil, errors.New("page index must be at least one")
+	}
+
+	first, err := pagetoken.NewRequestReader(pagetoken.WithEncryptor(e)).Read(f.request())
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := payload(f.Keyset)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []pagetoken.KeysetTokenOpt{pagetoken.WithKeysetPayload(p)}
+	if f.TotalCount != nil {
+		opts = append(opts, pagetoken.WithTotalCount(*f.TotalCount))
+	}
+	if f.Snapshot != nil {
+		opts = append(opts, pagetoken.WithSnapshotTime(*f.Snapshot))
+	}
+	if f.Upstream != nil {
+		opts = append(opts, pagetoken.WithUpstreamToken(*f.Upstream))
+	}
+	if f.Composite != nil {
+		c := pagetoken.NewCompositePayload()
+		for name, vs := range f.Composite.Parts {
+			p, err := payload(vs)
+			if err != nil {
+				return nil, err
+			}
+			c.Set(name, p)
+		}
+		for _, name := range f.Composite.Done {
+			c.SetDone(name)
+		}
+		opts = append(opts, pagetoken.WithCompositePayload(c))
+	}
+	if f.IssuedAt != nil {
+		at := *f.IssuedAt
+		opts = append(opts, pagetoken.WithIssueClock(func() time.Time { return at }))
+	}
+
+	t := first.Next(opts...)
+	for t.PageIndex() < f.PageIndex {
+		t = t.Next()
+	}
+
+	return t, nil
+}
+
+// Check returns an error describing every difference between t and the
+// contents f records.
+func (f Fixture) Check(t *pagetoken.KeysetToken) error {
+	errs := []error{}
+	diff := func(name string, got, want any) {
+		errs = append(errs, fmt.Errorf("%s: got %v, want %v", name, got, want))
+	}
+
+	if t.Checksum() != f.Checksum {
+		diff("checksum", t.Checksum(), f.Checksum)
+	}
+
+	crc, err := pagetoken.NewRequestReader().Read(f.request())
+	if err != nil {
+		errs = append(errs, err)
+	} else if crc.Checksum() != f.Checksum {
+		diff("checksum of checksum_fields", crc.Checksum(), f.Checksum)
+	}
+
+	if got := values(t.Payload().Values()); !slices.Equal(got, f.Keyset) {
+		diff("keyset", got, f.Keyset)
+	}
+
+	if n, ok := t.TotalCount(); ok != (f.TotalCount != nil) || ok && n != *f.TotalCount {
+		diff("total count", optional(n, ok), f.TotalCount)
+	}
+
+	if s, ok := t.SnapshotTime(); ok != (f.Snapshot != nil) || ok && !s.Equal(*f.Snapshot) {
+		diff("snapshot", optional(s, ok), f.Snapshot)
+	}
+
+	if u, ok := t.UpstreamToken(); ok != (f.Upstream != nil) || ok && u != *f.Upstream {
+		diff("upstream", optional(u, ok), f.Upstream)
+	}
+
+	if got := composite(t.CompositePayload()); !equalComposite(got, f.Composite) {
+		diff("composite", got, f.Composite)
+	}
+
+	if t.PageIndex() != f.PageIndex {
+		diff("page index", t.PageIndex(), f.PageIndex)
+	}
+
+	if at, ok := t.IssuedAt(); ok != (f.IssuedAt != nil) || ok && !at.Equal(*f.IssuedAt) {
+		diff("issued at", optional(at, ok), f.IssuedAt)
+	}
+
+	return errors.Join(errs...)
+}
+
+func optional[T any](v T, ok bool) any {
+	if !ok {
+		return "<none>"
+	}
+
+	return v
+}
+
+func values(vs []pagetoken.KeysetValue) []Value {
+	out := make([]Value, 0, len(vs))
+	for _, v := range vs {
+		out = append(out, Value{Path: v.Path, Value: v.Value, Order: v.Order.String()})
+	}
+
+	return out
+}
+
+func composite(c *pagetoken.CompositePayload) *Composite {
+	if c == nil {
+		return nil
+	}
+
+	out := &Composite{Parts: map[string][]Value{}}
+	for _, name := range c.Names() {
+		if c.Done(name) {
+			out.Done = append(out.Done, name)
+		}
+		if p := c.Get(name); p != nil {
+			out.Parts[name] = values(p.Values())
+		}
+	}
+
+	return out
+}
+
+func equalComposite(a, b *Composite) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	if !slices.Equal(a.Done, b.Done) || len(a.Parts) != len(b.Parts) {
+		return false
+	}
+
+	for name, vs := range a.Parts {
+		if !slices.Equal(vs, b.Parts[name]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// PlaintextVersion returns the format version of a token serialized with
+// StaticCrypter. Legacy tokens are version zero.
+func PlaintextVersion(token string) (int, error) {
+	d, err := pagetokentest.StaticCrypter{}.Decrypt(token)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(d) == 0 || strings.HasPrefix(string(d), "[") {
+		return 0, nil
+	}
+
+	return int(d[0]), nil
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/pixlcrashr/go-pagetoken/internal/vectors"
+)
+
+// gen writes the test vectors of the current format.
+//
+// Usage:
+//
+//	go run ./internal/vectors/gen/ [-o <output-file>] [-force]
+//
+// It is run by go generate in internal/vectors. A missing file is written.
+// An existing file is only overwritten with -force, and only if its vectors
+// differ from the generated ones: a difference means the format changed, so
+// the new vectors are a deliberate change to be reviewed together with the
+// code that caused it.
+func main() {
+	outPath := flag.String("o", "internal/vectors/testdata/vectors.json", "Output file for the test vectors")
+	force := flag.Bool("force", false, "Overwrite vectors that differ from the generated ones")
+	flag.Parse()
+
+	if err := run(*outPath, *force); err != nil {
+		fmt.Fprintf(os.Stderr, "gen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(path string, force bool) error {
+	f, err := vectors.Generate()
+	if err != nil {
+		return err
+	}
+
+	old, err := vectors.Load(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return err
+	case vectors.Equal(old, f):
+		fmt.Printf("%s is up to date\n", path)
+		return nil
+	case !force:
+		return fmt.Errorf("%s differs from the generated vectors; review the format change and rerun with -force", path)
+	}
+
+	if err := vectors.Write(path, f); err != nil {
+		return err
+	}
+
+	fmt.Printf("wrote %s\n", path)
+	return nil
+}
@@ -0,0 +1,441 @@
+// Package vectors generates, reads and writes the test vectors in
+// testdata/vectors.json, which let other implementations of the token
+// format, and future versions of this one, verify that they are compatible.
+//
+// The vectors record
+//
+//   - payloads: the fields added to a KeysetPayloadBuilder, with their input
+//     in a textual form per kind, the stored value and the bytes of
+//     EncodePayload,
+//   - checksums: the canonical encoding of checksum fields and the checksum
+//     of every supported mask, with and without AIP-158 page size handling,
+//   - tokens: the plaintext of a token carrying each payload and the token
+//     encrypted with every crypter; AEAD tokens use Key.
+//
+// Byte strings are hex encoded. The vectors are regenerated with
+//
+//	go generate ./internal/vectors
+//
+// which only writes a missing file. A file whose vectors no longer match the
+// package is kept and reported; overwriting it with -force is a deliberate,
+// reviewed change of the format, see gen/main.go.
+package vectors
+
+//go:generate go run ./gen -o testdata/vectors.json
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/checksum"
+	"github.com/pixlcrashr/go-pagetoken/encryption"
+	"github.com/pixlcrashr/go-pagetoken/order"
+	"github.com/pixlcrashr/go-pagetoken/pagetokentest"
+)
+
+// Key is the AES-256 key of the AEAD token vectors. It is public and must
+// never be used for anything else.
+var Key = []byte("pagetoken-test-vectors-aes256key")
+
+// Masks are the checksum masks vectors are generated for.
+var Masks = []uint32{checksum.DefaultChecksumMask, 0, 0xFFFFFFFF}
+
+// IssuedAt is the issue time of all token vectors.
+var IssuedAt = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// File is the contents of a vectors file.
+type File struct {
+	Key       string     `json:"key"`
+	Payloads  []Payload  `json:"payloads"`
+	Checksums []Checksum `json:"checksums"`
+	Tokens    []Token    `json:"tokens"`
+}
+
+// Field is a value added to a KeysetPayloadBuilder by the adder of Kind.
+// Input is the argument of the adder: strings as they are, numbers in
+// decimal, booleans as "true" or "false", times in RFC 3339 with
+// nanoseconds, byte strings and UUIDs in hex. Value is the stored value.
+// Sensitive fields are marked by KeysetPayloadBuilder.Sensitive.
+type Field struct {
+	Path      string `json:"path"`
+	Kind      string `json:"kind"`
+	Input     string `json:"input"`
+	Order     string `json:"order"`
+	Sensitive bool   `json:"sensitive,omitempty"`
+	Value     string `json:"value"`
+}
+
+// Payload is a keyset payload and its encoding by EncodePayload.
+type Payload struct {
+	Name    string  `json:"name"`
+	Fields  []Field `json:"fields"`
+	Encoded string  `json:"encoded"`
+}
+
+// Checksum is the canonical encoding and checksum of Fields under Mask. With
+// AIP158 set, the page size field is left out, as by a RequestReader created
+// with WithAIP158.
+type Checksum struct {
+	Fields    [][2]string `json:"fields"`
+	AIP158    bool        `json:"aip158"`
+	Canonical string      `json:"canonical"`
+	Mask      uint32      `json:"mask"`
+	Checksum  uint32      `json:"checksum"`
+}
+
+// Token is the token of the first page after Payload for a request with
+// ChecksumFields, issued at IssuedAt.
+type Token struct {
+	Payload        string      `json:"payload"`
+	ChecksumFields [][2]string `json:"checksum_fields"`
+	Checksum       uint32      `json:"checksum"`
+	PageIndex      int         `json:"page_index"`
+	IssuedAt       time.Time   `json:"issued_at"`
+	Plaintext      string      `json:"plaintext"`
+	Static         string      `json:"static"`
+	AEAD           string      `json:"aead"`
+}
+
+// Load reads the vectors file at path.
+func Load(path string) (*File, error) {
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &File{}
+	if err := json.Unmarshal(bs, f); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return f, nil
+}
+
+// Write writes f to path.
+func Write(path string, f *File) error {
+	bs, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(bs, '\n'), 0o644)
+}
+
+// Crypter returns the AEAD crypter of the token vectors.
+func Crypter() encryption.Crypter {
+	e, err := encryption.NewAEADEncryptor(Key)
+	if err != nil {
+		panic(err)
+	}
+
+	return e
+}
+
+// Equal reports whether a and b hold the same vectors. AEAD tokens are
+// ignored, as they are encrypted with a random nonce.
+func Equal(a, b *File) bool {
+	return reflect.DeepEqual(withoutAEAD(a), withoutAEAD(b))
+}
+
+func withoutAEAD(f *File) File {
+	c := *f
+	c.Tokens = make([]Token, len(f.Tokens))
+	for i, t := range f.Tokens {
+		t.AEAD = ""
+		c.Tokens[i] = t
+	}
+
+	return c
+}
+
+// Generate computes the vectors of the current format.
+func Generate() (*File, error) {
+	f := &File{Key: hex.EncodeToString(Key)}
+
+	for _, def := range payloads() {
+		p, err := def.generate()
+		if err != nil {
+			return nil, fmt.Errorf("payload %s: %w", def.Name, err)
+		}
+		f.Payloads = append(f.Payloads, p)
+	}
+
+	for _, fields := range checksumFields() {
+		for _, aip158 := range []bool{false, true} {
+			for _, mask := range Masks {
+				c, err := generateChecksum(fields, aip158, mask)
+				if err != nil {
+					return nil, err
+				}
+				f.Checksums = append(f.Checksums, c)
+			}
+		}
+	}
+
+	for _, p := range f.Payloads {
+		t, err := generateToken(p)
+		if err != nil {
+			return nil, fmt.Errorf("token %s: %w", p.Name, err)
+		}
+		f.Tokens = append(f.Tokens, t)
+	}
+
+	return f, nil
+}
+
+func (p Payload) generate() (Payload, error) {
+	kp, err := p.Build()
+	if err != nil {
+		return Payload{}, err
+	}
+
+	for i, v := range kp.Values() {
+		p.Fields[i].Value = v.Value
+	}
+
+	d, err := pagetoken.EncodePayload(kp)
+	if err != nil {
+		return Payload{}, err
+	}
+	p.Encoded = hex.EncodeToString(d)
+
+	return p, nil
+}
+
+// Build adds the fields of p to a KeysetPayloadBuilder by their kind. The
+// recorded values are ignored.
+func (p Payload) Build() (*pagetoken.KeysetPayload, error) {
+	b := pagetoken.NewKeysetPayloadBuilder()
+	for _, f := range p.Fields {
+		if err := f.add(b); err != nil {
+			return nil, fmt.Errorf("field %s: %w", f.Path, err)
+		}
+	}
+
+	return b.Build(), nil
+}
+
+func (f Field) add(b *pagetoken.KeysetPayloadBuilder) error {
+	var o order.Order
+	if err := o.UnmarshalString(f.Order); err != nil {
+		return err
+	}
+
+	switch f.Kind {
+	case "string":
+		b.AddString(f.Path, f.Input, o)
+	case "bool":
+		v, err := strconv.ParseBool(f.Input)
+		if err != nil {
+			return err
+		}
+		b.AddBool(f.Path, v, o)
+	case "int64":
+		v, err := strconv.ParseInt(f.Input, 10, 64)
+		if err != nil {
+			return err
+		}
+		b.AddInt64(f.Path, v, o)
+	case "uint64":
+		v, err := strconv.ParseUint(f.Input, 10, 64)
+		if err != nil {
+			return err
+		}
+		b.AddUint64(f.Path, v, o)
+	case "float64":
+		v, err := strconv.ParseFloat(f.Input, 64)
+		if err != nil {
+			return err
+		}
+		b.AddFloat64(f.Path, v, o)
+	case "time":
+		v, err := time.Parse(time.RFC3339Nano, f.Input)
+		if err != nil {
+			return err
+		}
+		b.AddTime(f.Path, v, o)
+	case "bytes":
+		v, err := hex.DecodeString(f.Input)
+		if err != nil {
+			return err
+		}
+		b.AddBytes(f.Path, v, o)
+	case "uuid":
+		v, err := hex.DecodeString(f.Input)
+		if err != nil {
+			return err
+		}
+		if len(v) != 16 {
+			return fmt.Errorf("uuid of %d bytes", len(v))
+		}
+		b.AddUUID(f.Path, [16]byte(v), o)
+	default:
+		return fmt.Errorf("unknown kind %q", f.Kind)
+	}
+
+	if f.Sensitive {
+		b.Sensitive()
+	}
+
+	return nil
+}
+
+// Request returns a request with the checksum fields fields and no token.
+func Request(fields [][2]string) pagetoken.Request {
+	r := request{}
+	for _, kv := range fields {
+		r.fields = append(r.fields, checksum.Field(kv[0], kv[1]))
+	}
+
+	return r
+}
+
+type request struct {
+	fields []checksum.BuilderOpt
+}
+
+func (r request) GetChecksumFields() []checksum.BuilderOpt { return r.fields }
+func (request) GetPageToken() string                       { return "" }
+
+func generateChecksum(fields [][2]string, aip158 bool, mask uint32) (Checksum, error) {
+	b := ChecksumBuilder(Checksum{Fields: fields, AIP158: aip158, Mask: mask})
+
+	canonical, err := b.Canonical()
+	if err != nil {
+		return Checksum{}, err
+	}
+	sum, err := b.Build()
+	if err != nil {
+		return Checksum{}, err
+	}
+
+	return Checksum{
+		Fields:    fields,
+		AIP158:    aip158,
+		Canonical: string(canonical),
+		Mask:      mask,
+		Checksum:  sum,
+	}, nil
+}
+
+// ChecksumBuilder returns a checksum builder with the mask and fields of c,
+// leaving out the page size field like a RequestReader created with
+// WithAIP158 if c.AIP158 is set.
+func ChecksumBuilder(c Checksum) *checksum.Builder {
+	b := checksum.NewBuilder(checksum.Mask(c.Mask))
+	for _, kv := range c.Fields {
+		checksum.Field(kv[0], kv[1])(b)
+	}
+	if c.AIP158 {
+		checksum.Without(pagetoken.PageSizeField)(b)
+	}
+
+	return b
+}
+
+// tokenChecksumFields are the checksum fields of all token vectors.
+var tokenChecksumFields = [][2]string{{"status", "active"}, {"owner", "42"}}
+
+// NewToken returns the token t records, encrypted with e.
+func NewToken(t Token, p Payload, e encryption.Crypter) (*pagetoken.KeysetToken, error) {
+	kp, err := p.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	first, err := pagetoken.NewRequestReader(pagetoken.WithEncryptor(e)).Read(Request(t.ChecksumFields))
+	if err != nil {
+		return nil, err
+	}
+
+	at := t.IssuedAt
+	return first.Next(
+		pagetoken.WithKeysetPayload(kp),
+		pagetoken.WithIssueClock(func() time.Time { return at }),
+	), nil
+}
+
+func generateToken(p Payload) (Token, error) {
+	t := Token{
+		Payload:        p.Name,
+		ChecksumFields: tokenChecksumFields,
+		PageIndex:      1,
+		IssuedAt:       IssuedAt,
+	}
+
+	static, err := NewToken(t, p, pagetokentest.StaticCrypter{})
+	if err != nil {
+		return Token{}, err
+	}
+	t.Checksum = static.Checksum()
+	if t.Static, err = static.String(); err != nil {
+		return Token{}, err
+	}
+
+	plaintext, err := pagetokentest.StaticCrypter{}.Decrypt(t.Static)
+	if err != nil {
+		return Token{}, err
+	}
+	t.Plaintext = hex.EncodeToString(plaintext)
+
+	aead, err := NewToken(t, p, Crypter())
+	if err != nil {
+		return Token{}, err
+	}
+	if t.AEAD, err = aead.String(); err != nil {
+		return Token{}, err
+	}
+
+	return t, nil
+}
+
+// payloads returns the payload definitions of the vectors.
+func payloads() []Payload {
+	return []Payload{
+		{Name: "empty", Fields: []Field{}},
+		{Name: "single int", Fields: []Field{
+			{Path: "id", Kind: "int64", Input: "42", Order: "asc"},
+		}},
+		{Name: "every kind", Fields: []Field{
+			{Path: "name", Kind: "string", Input: "plain", Order: "asc"},
+			{Path: "active", Kind: "bool", Input: "true", Order: "desc"},
+			{Path: "balance", Kind: "int64", Input: "-9223372036854775808", Order: "asc"},
+			{Path: "views", Kind: "uint64", Input: "18446744073709551615", Order: "desc"},
+			{Path: "score", Kind: "float64", Input: "0.1", Order: "asc"},
+			{Path: "created_at", Kind: "time", Input: "2024-02-29T23:59:59.123456789+02:00", Order: "desc"},
+			{Path: "digest", Kind: "bytes", Input: "00ff10", Order: "asc"},
+			{Path: "id", Kind: "uuid", Input: "0190a1b2c3d47e8f9a0b1c2d3e4f5a6b", Order: "asc"},
+		}},
+		{Name: "escaped strings", Fields: []Field{
+			{Path: "name", Kind: "string", Input: "Zoë \"Q\" <a&b>\\", Order: "asc"},
+			{Path: "note", Kind: "string", Input: "tab\tnewline\n 😀", Order: "desc"},
+			{Path: "", Kind: "string", Input: "", Order: "asc"},
+		}},
+		{Name: "sensitive", Fields: []Field{
+			{Path: "email", Kind: "string", Input: "jane.doe@example.com", Order: "asc", Sensitive: true},
+			{Path: "id", Kind: "int64", Input: "42", Order: "asc"},
+			{Path: "phone", Kind: "string", Input: "+49 30 1234567", Order: "desc", Sensitive: true},
+		}},
+		{Name: "duplicate paths", Fields: []Field{
+			{Path: "id", Kind: "int64", Input: "1", Order: "asc"},
+			{Path: "id", Kind: "int64", Input: "2", Order: "desc"},
+		}},
+	}
+}
+
+// checksumFields returns the checksum field sets of the vectors.
+func checksumFields() [][][2]string {
+	return [][][2]string{
+		{},
+		{{"status", "active"}},
+		{{"status", "active"}, {"owner", "42"}},
+		{{"filter", "name = \"Zoë\" AND age > 3"}, {"order_by", "created_at desc"}},
+		{{"status", "active"}, {pagetoken.PageSizeField, "50"}},
+	}
+}
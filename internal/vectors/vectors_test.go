@@ -0,0 +1,123 @@
+package vectors_test
+
+import (
+	"encoding/hex"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/checksum"
+	"github.com/pixlcrashr/go-pagetoken/encryption"
+	"github.com/pixlcrashr/go-pagetoken/internal/vectors"
+	"github.com/pixlcrashr/go-pagetoken/pagetokentest"
+)
+
+var _ = Describe("Test vectors", func() {
+	var f *vectors.File
+
+	BeforeEach(func() {
+		var err error
+		f, err = vectors.Load("testdata/vectors.json")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("match the vectors generated from the current format", func() {
+		g, err := vectors.Generate()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(vectors.Equal(f, g)).To(BeTrue(), "the format changed, review it and run go generate ./internal/vectors with -force")
+	})
+
+	It("document the key of the AEAD tokens", func() {
+		Expect(hex.DecodeString(f.Key)).To(Equal(vectors.Key))
+	})
+
+	It("encode and decode every payload", func() {
+		Expect(f.Payloads).NotTo(BeEmpty())
+
+		for _, p := range f.Payloads {
+			By(p.Name)
+			kp, err := p.Build()
+			Expect(err).NotTo(HaveOccurred())
+
+			want := make([]pagetoken.KeysetValue, len(p.Fields))
+			for i, fd := range p.Fields {
+				want[i] = pagetoken.KeysetValue{Path: fd.Path, Value: fd.Value, Sensitive: fd.Sensitive}
+				Expect(want[i].Order.UnmarshalString(fd.Order)).To(Succeed())
+			}
+			Expect(kp.Values()).To(Equal(want))
+
+			d, err := hex.DecodeString(p.Encoded)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pagetoken.EncodePayload(kp)).To(Equal(d))
+
+			decoded, err := pagetoken.DecodePayload(d)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(decoded.Values()).To(Equal(want))
+		}
+	})
+
+	It("compute every checksum", func() {
+		Expect(f.Checksums).NotTo(BeEmpty())
+
+		for _, c := range f.Checksums {
+			b := vectors.ChecksumBuilder(c)
+			Expect(b.Canonical()).To(Equal([]byte(c.Canonical)))
+			Expect(b.Build()).To(Equal(c.Checksum))
+
+			if c.Mask != checksum.DefaultChecksumMask {
+				continue
+			}
+
+			var opts []pagetoken.RequestReaderOpt
+			if c.AIP158 {
+				opts = append(opts, pagetoken.WithAIP158())
+			}
+			t, err := pagetoken.NewRequestReader(opts...).Read(vectors.Request(c.Fields))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(t.Checksum()).To(Equal(c.Checksum))
+		}
+	})
+
+	It("parse every token with every crypter", func() {
+		Expect(f.Tokens).NotTo(BeEmpty())
+
+		payloads := map[string]vectors.Payload{}
+		for _, p := range f.Payloads {
+			payloads[p.Name] = p
+		}
+
+		for _, t := range f.Tokens {
+			By(t.Payload)
+			p, ok := payloads[t.Payload]
+			Expect(ok).To(BeTrue())
+			kp, err := p.Build()
+			Expect(err).NotTo(HaveOccurred())
+
+			plaintext, err := hex.DecodeString(t.Plaintext)
+			Expect(err).NotTo(HaveOccurred())
+
+			crypters := []struct {
+				e     encryption.Crypter
+				token string
+			}{
+				{pagetokentest.StaticCrypter{}, t.Static},
+				{vectors.Crypter(), t.AEAD},
+			}
+			for _, c := range crypters {
+				e, token := c.e, c.token
+				Expect(e.Decrypt(token)).To(Equal(plaintext))
+
+				kt, err := pagetoken.NewKeysetTokenParser(pagetoken.WithKeysetTokenEncryptor(e)).Parse(token)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(kt.Checksum()).To(Equal(t.Checksum))
+				Expect(kt.PageIndex()).To(Equal(t.PageIndex))
+				Expect(kt.Payload().Values()).To(Equal(kp.Values()))
+
+				at, ok := kt.IssuedAt()
+				Expect(ok).To(BeTrue())
+				Expect(at).To(BeTemporally("==", t.IssuedAt))
+			}
+		}
+	})
+})
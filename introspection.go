@@ -0,0 +1,108 @@
+package pagetoken
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/pixlcrashr/go-pagetoken/encryption"
+)
+
+type introspectionHandler struct {
+	e         encryption.Crypter
+	maxLength int
+	dumpOpts  []DumpOpt
+}
+
+type IntrospectionOpt func(*introspectionHandler)
+
+// WithIntrospectionUnredactedValues makes IntrospectionHandler respond with
+// the keyset values, see WithUnredactedValues.
+func WithIntrospectionUnredactedValues() IntrospectionOpt {
+	return func(h *introspectionHandler) {
+		h.dumpOpts = append(h.dumpOpts, WithUnredactedValues())
+	}
+}
+
+// WithIntrospectionMaxTokenLength sets the longest token IntrospectionHandler
+// accepts. The default is DefaultMaxTokenLength.
+func WithIntrospectionMaxTokenLength(n int) IntrospectionOpt {
+	return func(h *introspectionHandler) {
+		h.maxLength = n
+	}
+}
+
+// introspectionRequest is the body of a request to IntrospectionHandler.
+type introspectionRequest struct {
+	Token string `json:"token"`
+}
+
+// IntrospectionHandler returns a handler for support tooling that decrypts
+// page tokens. It serves POST requests with a JSON body of the form
+//
+//	{"token": "..."}
+//
+// and responds with the TokenDump of DumpToken as JSON, with the keyset
+// values redacted unless WithIntrospectionUnredactedValues is given; the
+// sensitive ones are always redacted. Tokens
+// that are longer than the maximum length or cannot be decrypted are
+// rejected with the problem details of WriteProblem and status 400; other
+// methods with 405.
+//
+// The handler performs no authorization and must be mounted behind the
+// authentication middleware of the admin interface. It neither logs nor
+// echoes the token.
+func IntrospectionHandler(e encryption.Crypter, opts ...IntrospectionOpt) http.Handler {
+	h := &introspectionHandler{
+		e:         e,
+		maxLength: DefaultMaxTokenLength,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
+}
+
+func (h *introspectionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	// leave room for the JSON envelope around the token
+	body := http.MaxBytesReader(w, r.Body, int64(h.maxLength)+1024)
+
+	var req introspectionRequest
+	if err := json.NewDecoder(body).Decode(&req); err != nil {
+		var mbe *http.MaxBytesError
+		if errors.As(err, &mbe) {
+			WriteProblem(w, fmt.Errorf("%w: longer than %d bytes", ErrInvalidToken, h.maxLength))
+			return
+		}
+
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.Token == "" {
+		http.Error(w, "missing token", http.StatusBadRequest)
+		return
+	}
+	if len(req.Token) > h.maxLength {
+		WriteProblem(w, fmt.Errorf("%w: longer than %d bytes", ErrInvalidToken, h.maxLength))
+		return
+	}
+
+	d, err := DumpToken(h.e, req.Token, h.dumpOpts...)
+	if err != nil {
+		WriteProblem(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	_ = json.NewEncoder(w).Encode(d)
+}
@@ -0,0 +1,91 @@
+package pagetoken_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/encryption"
+	"github.com/pixlcrashr/go-pagetoken/order"
+)
+
+var _ = Describe("IntrospectionHandler", func() {
+	var (
+		e *encryption.AEADEncryptor
+		s string
+	)
+
+	BeforeEach(func() {
+		key, err := encryption.Rand32ByteKey()
+		Expect(err).NotTo(HaveOccurred())
+		e, err = encryption.NewAEADEncryptor(key)
+		Expect(err).NotTo(HaveOccurred())
+
+		s, err = pagetoken.NewKeysetToken(e, pagetoken.WithKeysetPayload(
+			pagetoken.NewKeysetPayloadBuilder().AddInt("id", 42, order.Desc).Build(),
+		)).String()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	post := func(h http.Handler, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/pagetoken", strings.NewReader(body)))
+		return w
+	}
+
+	tokenBody := func(token string) string {
+		bs, err := json.Marshal(map[string]string{"token": token})
+		Expect(err).NotTo(HaveOccurred())
+		return string(bs)
+	}
+
+	It("responds with the redacted dump of a valid token", func() {
+		w := post(pagetoken.IntrospectionHandler(e), tokenBody(s))
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Header().Get("Content-Type")).To(Equal("application/json"))
+		Expect(w.Body.String()).To(MatchJSON(`{"checksum":0,"keyset":[{"path":"id","order":"desc","value":"[redacted]"}]}`))
+	})
+
+	It("responds with the full values if configured", func() {
+		w := post(pagetoken.IntrospectionHandler(e, pagetoken.WithIntrospectionUnredactedValues()), tokenBody(s))
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Body.String()).To(MatchJSON(`{"checksum":0,"keyset":[{"path":"id","order":"desc","value":"42"}]}`))
+	})
+
+	It("rejects a tampered token without echoing it", func() {
+		bs := []byte(s)
+		bs[len(bs)/2] ^= 0x01
+
+		w := post(pagetoken.IntrospectionHandler(e), tokenBody(string(bs)))
+		Expect(w.Code).To(Equal(http.StatusBadRequest))
+		Expect(w.Header().Get("Content-Type")).To(Equal(pagetoken.ProblemContentType))
+		Expect(w.Body.String()).NotTo(ContainSubstring(string(bs)))
+	})
+
+	It("rejects oversized tokens", func() {
+		h := pagetoken.IntrospectionHandler(e, pagetoken.WithIntrospectionMaxTokenLength(len(s)-1))
+		w := post(h, tokenBody(s))
+		Expect(w.Code).To(Equal(http.StatusBadRequest))
+		Expect(w.Header().Get("Content-Type")).To(Equal(pagetoken.ProblemContentType))
+
+		w = post(h, tokenBody(strings.Repeat("A", 1<<20)))
+		Expect(w.Code).To(Equal(http.StatusBadRequest))
+		Expect(w.Header().Get("Content-Type")).To(Equal(pagetoken.ProblemContentType))
+	})
+
+	It("rejects malformed bodies and other methods", func() {
+		h := pagetoken.IntrospectionHandler(e)
+		Expect(post(h, "not json").Code).To(Equal(http.StatusBadRequest))
+		Expect(post(h, `{}`).Code).To(Equal(http.StatusBadRequest))
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/pagetoken", nil))
+		Expect(w.Code).To(Equal(http.StatusMethodNotAllowed))
+		Expect(w.Header().Get("Allow")).To(Equal(http.MethodPost))
+	})
+})
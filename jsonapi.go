@@ -0,0 +1,49 @@
+package pagetoken
+
+import "net/url"
+
+// DefaultJSONAPIPageParam is the query parameter JSONAPILinks places page
+// tokens in, following the page[...] family of the JSON:API specification.
+const DefaultJSONAPIPageParam = "page[cursor]"
+
+// JSONAPILinks returns the pagination links of a JSON:API document:
+//
+//	"links": {
+//	  "first": "https://api.example.com/articles?filter%5Bstatus%5D=published",
+//	  "next": "https://api.example.com/articles?filter%5Bstatus%5D=published&page%5Bcursor%5D=..."
+//	}
+//
+// The links are copies of baseURL with the serialized token set as the
+// pageParam query parameter, or DefaultJSONAPIPageParam if pageParam is
+// empty; all other query parameters are kept. The first link carries no
+// token and is always included. A nil next or prev token omits its link.
+func JSONAPILinks(baseURL *url.URL, next, prev *KeysetToken, pageParam string) (map[string]string, error) {
+	if pageParam == "" {
+		pageParam = DefaultJSONAPIPageParam
+	}
+
+	first, err := pageURL(baseURL, nil, pageParam)
+	if err != nil {
+		return nil, err
+	}
+	links := map[string]string{
+		"first": first.String(),
+	}
+
+	for rel, token := range map[string]*KeysetToken{
+		"next": next,
+		"prev": prev,
+	} {
+		if token == nil {
+			continue
+		}
+
+		u, err := pageURL(baseURL, token, pageParam)
+		if err != nil {
+			return nil, err
+		}
+		links[rel] = u.String()
+	}
+
+	return links, nil
+}
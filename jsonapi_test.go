@@ -0,0 +1,58 @@
+package pagetoken_test
+
+import (
+	"encoding/json"
+	"net/url"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken"
+)
+
+var _ = Describe("JSONAPILinks", func() {
+	var base *url.URL
+
+	BeforeEach(func() {
+		var err error
+		base, err = url.Parse("https://api.example.com/articles?filter[status]=published&sort=-created&page[cursor]=old")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("produces the links object of the cursor pagination profile", func() {
+		links, err := pagetoken.JSONAPILinks(base,
+			pagetoken.NewKeysetToken(fixedCrypter("bmV4dA==")),
+			pagetoken.NewKeysetToken(fixedCrypter("cHJldg=")),
+			"",
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		bs, err := json.Marshal(map[string]any{"links": links})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(bs).To(MatchJSON(`{
+			"links": {
+				"first": "https://api.example.com/articles?filter%5Bstatus%5D=published&sort=-created",
+				"next": "https://api.example.com/articles?filter%5Bstatus%5D=published&page%5Bcursor%5D=bmV4dA%3D%3D&sort=-created",
+				"prev": "https://api.example.com/articles?filter%5Bstatus%5D=published&page%5Bcursor%5D=cHJldg%3D&sort=-created"
+			}
+		}`))
+	})
+
+	It("omits exhausted directions but always links the first page", func() {
+		links, err := pagetoken.JSONAPILinks(base, nil, nil, "page[after]")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(links).To(Equal(map[string]string{
+			"first": "https://api.example.com/articles?filter%5Bstatus%5D=published&page%5Bcursor%5D=old&sort=-created",
+		}))
+	})
+
+	It("places tokens in the given parameter so that they parse back", func() {
+		links, err := pagetoken.JSONAPILinks(base, pagetoken.NewKeysetToken(fixedCrypter("a+b/c=")), nil, "page[after]")
+		Expect(err).NotTo(HaveOccurred())
+
+		u, err := url.Parse(links["next"])
+		Expect(err).NotTo(HaveOccurred())
+		Expect(u.Query().Get("page[after]")).To(Equal("a+b/c="))
+		Expect(u.Query().Get("filter[status]")).To(Equal("published"))
+	})
+})
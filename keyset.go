@@ -1,12 +1,16 @@
 package pagetoken
 
 import (
-	"bytes"
 	"encoding/json"
 	"errors"
-	"strconv"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/pixlcrashr/go-pagetoken/encryption"
+	"github.com/pixlcrashr/go-pagetoken/internal/bufpool"
+	"github.com/pixlcrashr/go-pagetoken/internal/frozen"
 	"github.com/pixlcrashr/go-pagetoken/order"
 )
 
@@ -14,29 +18,140 @@ type KeysetValue struct {
 	Path  string
 	Order order.Order
 	Value string
+	// Sensitive marks values that must never be shown, such as e-mail
+	// addresses used as sort keys. DumpToken and LogValue replace them by a
+	// hash, unconditionally; accessors and database adapters use them as
+	// any other value. The mark is serialized along with the value.
+	Sensitive bool
 }
 
+// KeysetToken is the decoded state of a page token. Methods reading a token,
+// including String, are safe for concurrent use; methods modifying it, such
+// as SetTotalCount, are not, so a token must not be modified while it is
+// shared. Next returns a new token and leaves the receiver unchanged.
 type KeysetToken struct {
-	checksum uint32
-	e        encryption.Crypter
-	payload  *KeysetPayload
+	checksum      uint32
+	e             encryption.Crypter
+	payload       *KeysetPayload
+	totalCount    int64
+	hasTotalCount bool
+	snapshot      time.Time
+	upstream      string
+	hasUpstream   bool
+	composite     *CompositePayload
+	groups        map[string]*KeysetPayload
+	upperBound    *KeysetPayload
+	pageIndex     int
+	issuedAt      time.Time
+	scope         string
+	id            string
+	ids           bool
+	legacy        bool
+	prefix        string
+	now           func() time.Time
+	pageSize      int
+
+	checksumMismatched bool
+	recovered          error
+
+	// serialized caches the result of the first successful String call
+	// until a setter modifies the token.
+	serialized atomic.Pointer[string]
 }
 
 func (b *KeysetToken) Checksum() uint32 {
 	return b.checksum
 }
 
-func (t *KeysetToken) tokenize(d []string) (string, error) {
-	bs := bytes.NewBuffer(nil)
-	if err := json.NewEncoder(bs).Encode(d); err != nil {
-		return "", err
-	}
+// TotalCount returns the total number of items of the listing, if one was
+// recorded on this token or on any token it was derived from via Next.
+func (c *KeysetToken) TotalCount() (int64, bool) {
+	return c.totalCount, c.hasTotalCount
+}
+
+// SetTotalCount records the total number of items of the listing. The value
+// is serialized into the token and carried over by Next, so it only needs to
+// be computed once per pagination session.
+func (c *KeysetToken) SetTotalCount(n int64) {
+	c.totalCount = n
+	c.hasTotalCount = true
+	c.serialized.Store(nil)
+}
+
+// SnapshotTime returns the point in time all pages of the listing should be
+// read at, if one was recorded on this token or on any token it was derived
+// from via Next.
+func (c *KeysetToken) SnapshotTime() (time.Time, bool) {
+	return c.snapshot, !c.snapshot.IsZero()
+}
+
+// SetSnapshotTime records the point in time all pages of the listing should
+// be read at. It is usually set on the first page and carried over by Next.
+func (c *KeysetToken) SetSnapshotTime(t time.Time) {
+	c.snapshot = t
+	c.serialized.Store(nil)
+}
+
+// PageIndex returns the zero-based index of the page the token points to:
+// zero for the token of the first page, incremented by every Next.
+func (c *KeysetToken) PageIndex() int {
+	return c.pageIndex
+}
+
+// PageSize returns the page size to serve the page with, as resolved by
+// RequestReader.PageSize for the request the token was read for. It is zero
+// for tokens not returned by a RequestReader and is not serialized.
+func (c *KeysetToken) PageSize() int {
+	return c.pageSize
+}
+
+// ChecksumMismatched reports whether a RequestReader created with
+// WithSoftChecksum, or with WithFallbackToFirstPage for
+// ErrorKindChecksumMismatch, returned the token of the first page because
+// the page token of the request was issued for other checksum fields.
+func (c *KeysetToken) ChecksumMismatched() bool {
+	return c.checksumMismatched
+}
+
+// IssuedAt returns the time the token was serialized at, if it was parsed
+// from a token string that records it. Tokens are stamped by String with
+// second precision.
+func (c *KeysetToken) IssuedAt() (time.Time, bool) {
+	return c.issuedAt, !c.issuedAt.IsZero()
+}
+
+// UpstreamToken returns the continuation token of an upstream API, if one
+// was recorded on this token or on any token it was derived from via Next.
+func (c *KeysetToken) UpstreamToken() (string, bool) {
+	return c.upstream, c.hasUpstream
+}
 
-	return t.e.Encrypt(bs.Bytes())
+// SetUpstreamToken records the continuation token of an upstream API the
+// listing is proxied to, e.g. the NextContinuationToken of S3
+// ListObjectsV2. It is encrypted along with the keyset, so clients can
+// neither read nor alter it, and is not part of the keyset payload, so
+// database adapters ignore it. It is carried over by Next and must be
+// replaced for every page.
+//
+// Upstream tokens longer than MaxUpstreamTokenLength make String fail with
+// ErrUpstreamTokenTooLong. Long upstream tokens also lengthen the page
+// token, which may need a higher WithMaxTokenLength in Middleware.
+func (c *KeysetToken) SetUpstreamToken(token string) {
+	c.upstream = token
+	c.hasUpstream = true
+	c.serialized.Store(nil)
 }
 
 var ErrFieldNotFound = errors.New("field not found")
 
+// MaxUpstreamTokenLength is the longest upstream token a KeysetToken can
+// carry, in bytes.
+const MaxUpstreamTokenLength = 8192
+
+// ErrUpstreamTokenTooLong is returned by String if the upstream token is
+// longer than MaxUpstreamTokenLength.
+var ErrUpstreamTokenTooLong = errors.New("upstream token too long")
+
 func (c *KeysetToken) Payload() *KeysetPayload {
 	return c.payload
 }
@@ -48,6 +163,19 @@ func (c *KeysetToken) Next(opts ...KeysetTokenOpt) *KeysetToken {
 
 	newC.e = c.e
 	newC.checksum = c.checksum
+	newC.totalCount = c.totalCount
+	newC.hasTotalCount = c.hasTotalCount
+	newC.snapshot = c.snapshot
+	newC.upstream = c.upstream
+	newC.hasUpstream = c.hasUpstream
+	newC.composite = c.composite
+	newC.groups = c.groups
+	newC.upperBound = c.upperBound
+	newC.pageIndex = c.pageIndex + 1
+	newC.prefix = c.prefix
+	newC.now = c.now
+	newC.scope = c.scope
+	newC.ids = c.ids
 
 	for _, opt := range opts {
 		opt(newC)
@@ -56,28 +184,160 @@ func (c *KeysetToken) Next(opts ...KeysetTokenOpt) *KeysetToken {
 	return newC
 }
 
+// String serializes and encrypts the token. The result of the first
+// successful call is cached on the token: later calls, including concurrent
+// ones, return the same string without encrypting again, so a token emitted
+// in a response body and in headers reads the same everywhere. The issue
+// time recorded in the string is that of the first call.
+//
+// SetTotalCount, SetSnapshotTime and SetUpstreamToken discard the cached
+// string. Options only take effect on new tokens via Next and
+// NewKeysetToken, which start without one. A CompositePayload, and the
+// payloads passed to NextWithPayloads, must not be modified once a token
+// carrying them has been serialized.
 func (c *KeysetToken) String() (string, error) {
-	d := make([]string, len(c.payload.vs)*3)
+	if s := c.serialized.Load(); s != nil {
+		return *s, nil
+	}
 
-	for i, field := range c.payload.vs {
-		d[i*3] = field.Path
-		d[i*3+1] = field.Value
-		d[i*3+2] = field.Order.String()
+	s, err := c.encode()
+	if err != nil {
+		return "", err
+	}
+
+	// a concurrent call may have been first, return its string instead
+	if !c.serialized.CompareAndSwap(nil, &s) {
+		return *c.serialized.Load(), nil
+	}
+
+	return s, nil
+}
+
+// MarshalText implements encoding.TextMarshaler, emitting the string
+// returned by String, so that response structs can carry tokens directly:
+//
+//	type ListBooksResponse struct {
+//		Books         []Book                 `json:"books"`
+//		NextPageToken *pagetoken.KeysetToken `json:"next_page_token,omitempty"`
+//	}
+//
+// A nil token marshals to an empty string. Note that encoding/json emits
+// null for nil pointer fields without calling MarshalText, hence the
+// omitempty above.
+func (c *KeysetToken) MarshalText() ([]byte, error) {
+	if c == nil {
+		return []byte{}, nil
+	}
+
+	s, err := c.String()
+	if err != nil {
+		return nil, err
 	}
 
-	return c.tokenize(append(d, strconv.FormatUint(uint64(c.checksum), 10)))
+	return []byte(s), nil
+}
+
+// MarshalJSON implements json.Marshaler, emitting the string returned by
+// String as a JSON string, see MarshalText.
+func (c *KeysetToken) MarshalJSON() ([]byte, error) {
+	s, err := c.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(string(s))
+}
+
+// encode serializes and encrypts the token without caching.
+func (c *KeysetToken) encode() (string, error) {
+	buf := bufpool.Get()
+	defer bufpool.Put(buf)
+
+	d, err := c.marshal(*buf)
+	if err != nil {
+		return "", err
+	}
+	*buf = d
+
+	s, err := c.e.Encrypt(d)
+	if err != nil {
+		return "", err
+	}
+
+	return c.prefix + s, nil
 }
 
 type KeysetTokenOpt func(*KeysetToken)
 
+// NewKeysetToken returns a token that is encrypted with e and carries no
+// request checksum, e.g. for per-item cursors that are not bound to the
+// parameters of a request. Tokens for paginating requests are obtained from
+// RequestReader.Read instead.
+func NewKeysetToken(e encryption.Crypter, opts ...KeysetTokenOpt) *KeysetToken {
+	t := &KeysetToken{
+		e:       e,
+		payload: &KeysetPayload{},
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	return t
+}
+
 func WithKeysetPayload(payload *KeysetPayload) KeysetTokenOpt {
 	return func(c *KeysetToken) {
 		c.payload = payload
 	}
 }
 
+func WithTotalCount(n int64) KeysetTokenOpt {
+	return func(c *KeysetToken) {
+		c.SetTotalCount(n)
+	}
+}
+
+func WithSnapshotTime(t time.Time) KeysetTokenOpt {
+	return func(c *KeysetToken) {
+		c.SetSnapshotTime(t)
+	}
+}
+
+// WithStringPrefix makes String prepend prefix to the encrypted token, see
+// WithTokenPrefix.
+func WithStringPrefix(prefix string) KeysetTokenOpt {
+	return func(c *KeysetToken) {
+		c.prefix = prefix
+	}
+}
+
+// WithIssueClock replaces time.Now as the source of the issue time String
+// stamps into the token, e.g. to serialize tokens reproducibly. The clock is
+// carried over by Next.
+func WithIssueClock(now func() time.Time) KeysetTokenOpt {
+	return func(c *KeysetToken) {
+		c.now = now
+	}
+}
+
+func WithUpstreamToken(token string) KeysetTokenOpt {
+	return func(c *KeysetToken) {
+		c.SetUpstreamToken(token)
+	}
+}
+
+// KeysetTokenParser parses page token strings. It is safe for concurrent
+// use by multiple goroutines, provided its crypter is. Its configuration
+// must not be changed once it is in use; builds with the pagetokendebug tag
+// panic if it is.
 type KeysetTokenParser struct {
-	e encryption.Crypter
+	e            encryption.Crypter
+	prefix       string
+	strictValues bool
+	maxValueLen  int
+	maxAge       time.Duration
+	now          func() time.Time
+	fingerprint  string
 }
 
 type KeysetTokenParserOpt func(*KeysetTokenParser)
@@ -88,41 +348,55 @@ func WithKeysetTokenEncryptor(e encryption.Crypter) KeysetTokenParserOpt {
 	}
 }
 
+// WithKeysetTokenPrefix makes the parser strip prefix from tokens carrying
+// it and the parsed tokens prepend it again when serialized, see
+// WithTokenPrefix.
+func WithKeysetTokenPrefix(prefix string) KeysetTokenParserOpt {
+	return func(p *KeysetTokenParser) {
+		p.prefix = prefix
+	}
+}
+
+// Parse decrypts and decodes token. It accepts the token formats of all
+// versions of this package, including the legacy path/value/order triples
+// issued before versioned tokens; tokens derived from a parsed token via
+// Next are serialized in the latest format.
 func (p *KeysetTokenParser) Parse(token string) (*KeysetToken, error) {
-	d, err := p.e.Decrypt(token)
+	d, err := p.decrypt(token)
 	if err != nil {
 		return nil, err
 	}
 
-	var ps []string
-	if err := json.Unmarshal(d, &ps); err != nil {
-		return nil, err
+	t, err := unmarshalKeysetToken(d)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
 	}
 
-	crc, err := strconv.ParseUint(ps[len(ps)-1], 10, 64)
-	if err != nil {
+	if err := p.checkValues(t, d); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	if err := checkAge(t, p.maxAge, p.now); err != nil {
 		return nil, err
 	}
 
-	vs := []KeysetValue{}
-	for i := 0; i < len(ps)-1; i += 3 {
-		var o order.Order
-		if err := o.UnmarshalString(ps[i+2]); err != nil {
-			return nil, err
-		}
+	t.e = p.e
+	t.prefix = p.prefix
+	return t, nil
+}
 
-		vs = append(vs, KeysetValue{
-			Path:  ps[i],
-			Value: ps[i+1],
-			Order: o,
-		})
+// decrypt returns the plaintext of token.
+func (p *KeysetTokenParser) decrypt(token string) ([]byte, error) {
+	if frozen.Enabled {
+		frozen.Check("KeysetTokenParser", p.fingerprint, p.state())
 	}
 
-	return &KeysetToken{
-		checksum: uint32(crc),
-		e:        p.e,
-		payload:  &KeysetPayload{vs: vs},
-	}, nil
+	d, err := p.e.Decrypt(strings.TrimPrefix(token, p.prefix))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	return d, nil
 }
 
 func NewKeysetTokenParser(opts ...KeysetTokenParserOpt) *KeysetTokenParser {
@@ -130,6 +404,14 @@ func NewKeysetTokenParser(opts ...KeysetTokenParserOpt) *KeysetTokenParser {
 	for _, opt := range opts {
 		opt(p)
 	}
+	if frozen.Enabled {
+		p.fingerprint = p.state()
+	}
 
 	return p
 }
+
+// state returns a fingerprint of the configuration of p for frozen.Check.
+func (p *KeysetTokenParser) state() string {
+	return fmt.Sprintf("%T(%p) %q %t %d %d %p", p.e, p.e, p.prefix, p.strictValues, p.maxValueLen, p.maxAge, p.now)
+}
@@ -0,0 +1,142 @@
+package pagetoken
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"net/netip"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/pixlcrashr/go-pagetoken/order"
+)
+
+// ErrUnsupportedKeysetValue is returned by AddAuto for values it cannot
+// encode.
+var ErrUnsupportedKeysetValue = errors.New("unsupported keyset value type")
+
+// keysetEncoders maps a reflect.Type to the func(any) string registered for
+// it via RegisterKeysetEncoder.
+var keysetEncoders sync.Map
+
+// RegisterKeysetEncoder registers encodeFn as the encoding AddAuto uses for
+// values of type T, e.g. uuid.UUID.String. Registered encoders take
+// precedence over the built-in encodings; registering an encoder for the
+// same type again replaces it. Encoders are usually registered from init
+// functions; registering is safe for concurrent use.
+func RegisterKeysetEncoder[T any](encodeFn KeysetValueEncodeFn[T]) {
+	keysetEncoders.Store(reflect.TypeFor[T](), func(v any) string {
+		return encodeFn(v.(T))
+	})
+}
+
+// AddAuto appends value with the same encoding as the typed adder matching
+// its dynamic type, for building payloads generically, e.g. from the
+// map[string]any of a query layer. It supports, in order of precedence:
+//
+//   - types registered via RegisterKeysetEncoder
+//   - string, bool, all int, uint, float and complex types, time.Time,
+//     Date, []byte, [16]byte, netip.Addr and non-nil *big.Int, encoded like
+//     AddString, AddBool, AddInt, ..., AddTime, AddDate, AddBytes, AddUUID,
+//     AddNetIP and AddBigInt
+//   - fmt.Stringer, encoded by its String method
+//   - other types whose underlying type is a string, bool, int, uint, float
+//     or complex type, such as type Status string, encoded like that type
+//
+// Note that a named integer type with a String method, such as an enum, is
+// encoded by its name, not by its number; register an encoder to store the
+// number instead. Other values, including nil, are not appended and
+// reported with an error wrapping ErrUnsupportedKeysetValue.
+func (b *KeysetPayloadBuilder) AddAuto(key string, value any, order order.Order) error {
+	if value == nil {
+		return fmt.Errorf("%w: %q: nil", ErrUnsupportedKeysetValue, key)
+	}
+
+	if enc, ok := keysetEncoders.Load(reflect.TypeOf(value)); ok {
+		b.append(key, enc.(func(any) string)(value), order)
+		return nil
+	}
+
+	switch v := value.(type) {
+	case string:
+		b.AddString(key, v, order)
+	case bool:
+		b.AddBool(key, v, order)
+	case int:
+		b.AddInt(key, v, order)
+	case int8:
+		b.AddInt8(key, v, order)
+	case int16:
+		b.AddInt16(key, v, order)
+	case int32:
+		b.AddInt32(key, v, order)
+	case int64:
+		b.AddInt64(key, v, order)
+	case uint:
+		b.AddUint(key, v, order)
+	case uint8:
+		b.AddUint8(key, v, order)
+	case uint16:
+		b.AddUint16(key, v, order)
+	case uint32:
+		b.AddUint32(key, v, order)
+	case uint64:
+		b.AddUint64(key, v, order)
+	case float32:
+		b.AddFloat32(key, v, order)
+	case float64:
+		b.AddFloat64(key, v, order)
+	case complex64:
+		b.AddComplex64(key, v, order)
+	case complex128:
+		b.AddComplex128(key, v, order)
+	case time.Time:
+		b.AddTime(key, v, order)
+	case Date:
+		b.AddDate(key, v.Year, v.Month, v.Day, order)
+	case []byte:
+		b.AddBytes(key, v, order)
+	case [16]byte:
+		b.AddUUID(key, v, order)
+	case netip.Addr:
+		b.AddNetIP(key, v, order)
+	case *big.Int:
+		if v == nil {
+			return fmt.Errorf("%w: %q: nil %T", ErrUnsupportedKeysetValue, key, v)
+		}
+		b.AddBigInt(key, v, order)
+	case fmt.Stringer:
+		b.append(key, v.String(), order)
+	default:
+		return b.addKind(key, reflect.ValueOf(value), order)
+	}
+
+	return nil
+}
+
+// addKind appends a value of a named type by its underlying type.
+func (b *KeysetPayloadBuilder) addKind(key string, rv reflect.Value, order order.Order) error {
+	switch rv.Kind() {
+	case reflect.String:
+		b.AddString(key, rv.String(), order)
+	case reflect.Bool:
+		b.AddBool(key, rv.Bool(), order)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		b.AddInt64(key, rv.Int(), order)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		b.AddUint64(key, rv.Uint(), order)
+	case reflect.Float32:
+		b.AddFloat32(key, float32(rv.Float()), order)
+	case reflect.Float64:
+		b.AddFloat64(key, rv.Float(), order)
+	case reflect.Complex64:
+		b.AddComplex64(key, complex64(rv.Complex()), order)
+	case reflect.Complex128:
+		b.AddComplex128(key, rv.Complex(), order)
+	default:
+		return fmt.Errorf("%w: %q: %s", ErrUnsupportedKeysetValue, key, rv.Type())
+	}
+
+	return nil
+}
@@ -0,0 +1,158 @@
+package pagetoken_test
+
+import (
+	"math/big"
+	"net/netip"
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/order"
+)
+
+type autoStatus string
+
+type autoID int64
+
+type autoVersion uint16
+
+type autoColor int
+
+func (c autoColor) String() string {
+	return [...]string{"red", "green"}[c]
+}
+
+// autoPoint is registered with RegisterKeysetEncoder.
+type autoPoint struct {
+	X, Y int
+}
+
+// autoRegistered has a String method that its registered encoder overrides.
+type autoRegistered int
+
+func (autoRegistered) String() string {
+	return "stringer"
+}
+
+func init() {
+	pagetoken.RegisterKeysetEncoder(func(p autoPoint) string {
+		return strconv.Itoa(p.X) + "," + strconv.Itoa(p.Y)
+	})
+	pagetoken.RegisterKeysetEncoder(func(r autoRegistered) string {
+		return "registered-" + strconv.Itoa(int(r))
+	})
+}
+
+var _ = Describe("AddAuto", func() {
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)
+
+	DescribeTable("encodes values like the typed adders",
+		func(value any, typed func(b *pagetoken.KeysetPayloadBuilder) *pagetoken.KeysetPayloadBuilder) {
+			b := pagetoken.NewKeysetPayloadBuilder()
+			Expect(b.AddAuto("k", value, order.Desc)).To(Succeed())
+
+			Expect(b.Build().Values()).To(Equal(typed(pagetoken.NewKeysetPayloadBuilder()).Build().Values()))
+		},
+		Entry("string", "hello", func(b *pagetoken.KeysetPayloadBuilder) *pagetoken.KeysetPayloadBuilder {
+			return b.AddString("k", "hello", order.Desc)
+		}),
+		Entry("bool", true, func(b *pagetoken.KeysetPayloadBuilder) *pagetoken.KeysetPayloadBuilder {
+			return b.AddBool("k", true, order.Desc)
+		}),
+		Entry("int", -42, func(b *pagetoken.KeysetPayloadBuilder) *pagetoken.KeysetPayloadBuilder {
+			return b.AddInt("k", -42, order.Desc)
+		}),
+		Entry("int8", int8(-8), func(b *pagetoken.KeysetPayloadBuilder) *pagetoken.KeysetPayloadBuilder {
+			return b.AddInt8("k", -8, order.Desc)
+		}),
+		Entry("int16", int16(-16), func(b *pagetoken.KeysetPayloadBuilder) *pagetoken.KeysetPayloadBuilder {
+			return b.AddInt16("k", -16, order.Desc)
+		}),
+		Entry("int32", int32(-32), func(b *pagetoken.KeysetPayloadBuilder) *pagetoken.KeysetPayloadBuilder {
+			return b.AddInt32("k", -32, order.Desc)
+		}),
+		Entry("int64", int64(-64), func(b *pagetoken.KeysetPayloadBuilder) *pagetoken.KeysetPayloadBuilder {
+			return b.AddInt64("k", -64, order.Desc)
+		}),
+		Entry("uint", uint(1), func(b *pagetoken.KeysetPayloadBuilder) *pagetoken.KeysetPayloadBuilder {
+			return b.AddUint("k", 1, order.Desc)
+		}),
+		Entry("uint8", uint8(8), func(b *pagetoken.KeysetPayloadBuilder) *pagetoken.KeysetPayloadBuilder {
+			return b.AddUint8("k", 8, order.Desc)
+		}),
+		Entry("uint16", uint16(16), func(b *pagetoken.KeysetPayloadBuilder) *pagetoken.KeysetPayloadBuilder {
+			return b.AddUint16("k", 16, order.Desc)
+		}),
+		Entry("uint32", uint32(32), func(b *pagetoken.KeysetPayloadBuilder) *pagetoken.KeysetPayloadBuilder {
+			return b.AddUint32("k", 32, order.Desc)
+		}),
+		Entry("uint64", uint64(18446744073709551615), func(b *pagetoken.KeysetPayloadBuilder) *pagetoken.KeysetPayloadBuilder {
+			return b.AddUint64("k", 18446744073709551615, order.Desc)
+		}),
+		Entry("float32", float32(0.1), func(b *pagetoken.KeysetPayloadBuilder) *pagetoken.KeysetPayloadBuilder {
+			return b.AddFloat32("k", 0.1, order.Desc)
+		}),
+		Entry("float64", 0.1, func(b *pagetoken.KeysetPayloadBuilder) *pagetoken.KeysetPayloadBuilder {
+			return b.AddFloat64("k", 0.1, order.Desc)
+		}),
+		Entry("complex64", complex64(1+2i), func(b *pagetoken.KeysetPayloadBuilder) *pagetoken.KeysetPayloadBuilder {
+			return b.AddComplex64("k", 1+2i, order.Desc)
+		}),
+		Entry("complex128", 1+2i, func(b *pagetoken.KeysetPayloadBuilder) *pagetoken.KeysetPayloadBuilder {
+			return b.AddComplex128("k", 1+2i, order.Desc)
+		}),
+		Entry("time.Time", ts, func(b *pagetoken.KeysetPayloadBuilder) *pagetoken.KeysetPayloadBuilder {
+			return b.AddTime("k", ts, order.Desc)
+		}),
+		Entry("[]byte", []byte{0x00, 0xff}, func(b *pagetoken.KeysetPayloadBuilder) *pagetoken.KeysetPayloadBuilder {
+			return b.AddBytes("k", []byte{0x00, 0xff}, order.Desc)
+		}),
+		Entry("[16]byte", [16]byte{15: 1}, func(b *pagetoken.KeysetPayloadBuilder) *pagetoken.KeysetPayloadBuilder {
+			return b.AddUUID("k", [16]byte{15: 1}, order.Desc)
+		}),
+		Entry("netip.Addr", netip.MustParseAddr("10.0.0.2"), func(b *pagetoken.KeysetPayloadBuilder) *pagetoken.KeysetPayloadBuilder {
+			return b.AddNetIP("k", netip.MustParseAddr("10.0.0.2"), order.Desc)
+		}),
+		Entry("*big.Int", new(big.Int).Lsh(big.NewInt(1), 64), func(b *pagetoken.KeysetPayloadBuilder) *pagetoken.KeysetPayloadBuilder {
+			return b.AddBigInt("k", new(big.Int).Lsh(big.NewInt(1), 64), order.Desc)
+		}),
+		Entry("fmt.Stringer", autoColor(1), func(b *pagetoken.KeysetPayloadBuilder) *pagetoken.KeysetPayloadBuilder {
+			return b.AddString("k", "green", order.Desc)
+		}),
+		Entry("a named string", autoStatus("active"), func(b *pagetoken.KeysetPayloadBuilder) *pagetoken.KeysetPayloadBuilder {
+			return b.AddString("k", "active", order.Desc)
+		}),
+		Entry("a named int", autoID(-7), func(b *pagetoken.KeysetPayloadBuilder) *pagetoken.KeysetPayloadBuilder {
+			return b.AddInt64("k", -7, order.Desc)
+		}),
+		Entry("a named uint", autoVersion(3), func(b *pagetoken.KeysetPayloadBuilder) *pagetoken.KeysetPayloadBuilder {
+			return b.AddUint16("k", 3, order.Desc)
+		}),
+		Entry("a registered type", autoPoint{X: 1, Y: 2}, func(b *pagetoken.KeysetPayloadBuilder) *pagetoken.KeysetPayloadBuilder {
+			return pagetoken.AddKeysetValue(b, "k", "1,2", order.Desc, func(s string) string { return s })
+		}),
+		Entry("a registered fmt.Stringer", autoRegistered(5), func(b *pagetoken.KeysetPayloadBuilder) *pagetoken.KeysetPayloadBuilder {
+			return b.AddString("k", "registered-5", order.Desc)
+		}),
+	)
+
+	DescribeTable("rejects unsupported values without appending them",
+		func(value any) {
+			b := pagetoken.NewKeysetPayloadBuilder()
+
+			err := b.AddAuto("k", value, order.Asc)
+			Expect(err).To(MatchError(pagetoken.ErrUnsupportedKeysetValue))
+			Expect(err).To(MatchError(ContainSubstring(`"k"`)))
+			Expect(b.Build().Values()).To(BeEmpty())
+		},
+		Entry("nil", nil),
+		Entry("a struct", struct{ A int }{A: 1}),
+		Entry("a pointer", new(int)),
+		Entry("a nil *big.Int", (*big.Int)(nil)),
+		Entry("a map", map[string]any{}),
+		Entry("a slice", []int{1}),
+	)
+})
@@ -0,0 +1,94 @@
+package pagetoken
+
+import (
+	"fmt"
+
+	"github.com/pixlcrashr/go-pagetoken/encryption"
+)
+
+// ItemCursors returns a cursor for every payload, e.g. the keyset of every
+// row of a page, for clients that resume from a specific item rather than
+// the page boundary. Each cursor is token with its payload replaced, as
+// returned by token.Next(WithKeysetPayload(payload)).String(), and carries
+// the checksum, total count and snapshot time of token.
+//
+// Cursors of a page share everything but the keyset, so the plaintext
+// metadata and the serialization buffer are prepared once and reused. The
+// encryption itself cannot be shared: every cursor still costs one call to
+// the token's crypter, which for the AEAD encryptor is one AES-GCM seal
+// with a fresh nonce. Use LazyItemCursors to only pay for the cursors
+// actually returned to the client.
+func ItemCursors(token *KeysetToken, payloads []*KeysetPayload) ([]string, error) {
+	cursor := LazyItemCursors(token, payloads)
+
+	cs := make([]string, len(payloads))
+	for i := range payloads {
+		c, err := cursor(i)
+		if err != nil {
+			return nil, err
+		}
+		cs[i] = c
+	}
+
+	return cs, nil
+}
+
+// LazyItemCursors is like ItemCursors but returns a function computing the
+// cursor of payloads[i] on demand. The function reuses a serialization
+// buffer across calls, so it must not be called concurrently, and the
+// token's crypter must not retain the plaintext passed to Encrypt.
+func LazyItemCursors(token *KeysetToken, payloads []*KeysetPayload) func(i int) (string, error) {
+	w := &itemCursorWriter{
+		e:      token.e,
+		prefix: token.prefix,
+		body:   token.v1Body(),
+		ids:    token.ids,
+	}
+	// match the page index of token.Next
+	w.body.PageIndex++
+
+	return func(i int) (string, error) {
+		if i < 0 || i >= len(payloads) {
+			return "", fmt.Errorf("item cursor index %d out of range [0, %d)", i, len(payloads))
+		}
+
+		return w.cursor(payloads[i])
+	}
+}
+
+// itemCursorWriter serializes cursors that differ only in their keyset and,
+// if ids is set, their token ID.
+type itemCursorWriter struct {
+	e      encryption.Encrypter
+	prefix string
+	body   keysetTokenV1Body
+	ids    bool
+	buf    []byte
+}
+
+func (w *itemCursorWriter) cursor(payload *KeysetPayload) (string, error) {
+	if w.body.Upstream != nil && len(*w.body.Upstream) > MaxUpstreamTokenLength {
+		return "", fmt.Errorf("%w: %d bytes", ErrUpstreamTokenTooLong, len(*w.body.Upstream))
+	}
+
+	var vs []KeysetValue
+	if payload != nil {
+		vs = payload.vs
+	}
+	if w.ids {
+		w.body.ID = newTokenID()
+	}
+
+	buf, err := appendV1(w.buf[:0], &w.body, vs)
+	if err != nil {
+		return "", err
+	}
+	w.buf = buf
+
+	s, err := w.e.Encrypt(buf)
+	if err != nil {
+		return "", err
+	}
+
+	return w.prefix + s, nil
+}
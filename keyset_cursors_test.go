@@ -0,0 +1,133 @@
+package pagetoken_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/encryption"
+	"github.com/pixlcrashr/go-pagetoken/order"
+	"github.com/pixlcrashr/go-pagetoken/pagetokentest"
+)
+
+func itemPayloads(n int) []*pagetoken.KeysetPayload {
+	ps := make([]*pagetoken.KeysetPayload, n)
+	for i := range ps {
+		ps[i] = pagetoken.NewKeysetPayloadBuilder().
+			AddString("name", "user <"+string(rune('a'+i%26))+">", order.Desc).
+			AddInt("id", i, order.Asc).
+			Build()
+	}
+	return ps
+}
+
+var _ = Describe("ItemCursors", func() {
+	It("returns the same plaintexts as serializing every item on its own", func() {
+		issuedAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+		t := pagetoken.NewKeysetToken(pagetokentest.StaticCrypter{},
+			pagetoken.WithChecksum(42),
+			pagetoken.WithTotalCount(3),
+			pagetoken.WithIssueClock(func() time.Time { return issuedAt }),
+		)
+
+		ps := itemPayloads(3)
+		cs, err := pagetoken.ItemCursors(t, ps)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cs).To(HaveLen(3))
+
+		for i, p := range ps {
+			want, err := t.Next(pagetoken.WithKeysetPayload(p)).String()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cs[i]).To(Equal(want))
+		}
+	})
+
+	It("returns cursors the reader accepts for the same request", func() {
+		key, err := encryption.Rand32ByteKey()
+		Expect(err).NotTo(HaveOccurred())
+		e, err := encryption.NewAEADEncryptor(key)
+		Expect(err).NotTo(HaveOccurred())
+		rr := pagetoken.NewRequestReader(pagetoken.WithEncryptor(e))
+
+		t, err := rr.Read(filterRequest{status: "active"})
+		Expect(err).NotTo(HaveOccurred())
+
+		ps := itemPayloads(2)
+		cs, err := pagetoken.ItemCursors(t, ps)
+		Expect(err).NotTo(HaveOccurred())
+
+		resumed, err := rr.Read(filterRequest{status: "active", token: cs[1]})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resumed.Payload().Values()).To(Equal(ps[1].Values()))
+
+		_, err = rr.Read(filterRequest{status: "inactive", token: cs[1]})
+		Expect(err).To(MatchError(pagetoken.ErrChecksumMismatch))
+	})
+
+	Describe("LazyItemCursors", func() {
+		It("computes only the requested cursors", func() {
+			calls := 0
+			t := pagetoken.NewKeysetToken(countingCrypter{token: "c", calls: &calls})
+
+			cursor := pagetoken.LazyItemCursors(t, itemPayloads(10))
+			Expect(calls).To(BeZero())
+
+			c, err := cursor(9)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(c).To(Equal("c"))
+			Expect(calls).To(Equal(1))
+		})
+
+		It("rejects out of range indices", func() {
+			cursor := pagetoken.LazyItemCursors(pagetoken.NewKeysetToken(pagetokentest.StaticCrypter{}), itemPayloads(1))
+			_, err := cursor(1)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
+
+func benchmarkToken(b *testing.B) *pagetoken.KeysetToken {
+	key, err := encryption.Rand32ByteKey()
+	if err != nil {
+		b.Fatal(err)
+	}
+	e, err := encryption.NewAEADEncryptor(key)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	t, err := pagetoken.NewRequestReader(pagetoken.WithEncryptor(e)).Read(filterRequest{status: "active"})
+	if err != nil {
+		b.Fatal(err)
+	}
+	return t
+}
+
+func BenchmarkItemCursorsNaive(b *testing.B) {
+	t := benchmarkToken(b)
+	ps := itemPayloads(100)
+
+	b.ReportAllocs()
+	for b.Loop() {
+		for _, p := range ps {
+			if _, err := t.Next(pagetoken.WithKeysetPayload(p)).String(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkItemCursors(b *testing.B) {
+	t := benchmarkToken(b)
+	ps := itemPayloads(100)
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := pagetoken.ItemCursors(t, ps); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package pagetoken
+
+import (
+	"maps"
+	"slices"
+)
+
+// PayloadFor returns the keyset of the named list of an endpoint that pages
+// several independent lists with one token, e.g. "recent" and "recommended"
+// books. Each keyset is used like the payload of a single-list token, e.g.
+// with the gorm builder.
+//
+// A token without named keysets, such as the token of the first page,
+// starts every list at its first row and returns an empty payload. Once a
+// token carries named keysets via NextWithPayloads, a missing name means
+// that list is exhausted: PayloadFor returns nil and false, and the list
+// must not be queried again.
+func (c *KeysetToken) PayloadFor(name string) (*KeysetPayload, bool) {
+	if c.groups == nil {
+		return &KeysetPayload{}, true
+	}
+
+	p, ok := c.groups[name]
+	return p, ok
+}
+
+// PayloadNames returns the names of the lists that have rows left, in sorted
+// order, or nil if the token carries no named keysets.
+func (c *KeysetToken) PayloadNames() []string {
+	if c.groups == nil {
+		return nil
+	}
+
+	names := slices.AppendSeq(make([]string, 0, len(c.groups)), maps.Keys(c.groups))
+	slices.Sort(names)
+	return names
+}
+
+// NextWithPayloads returns the token of the next page of an endpoint paging
+// several lists, see PayloadFor. payloads holds the keyset of the last row
+// of every list that has rows left; lists without an entry, or with a nil
+// one, are exhausted. An empty payload starts its list at the first row.
+func (c *KeysetToken) NextWithPayloads(payloads map[string]*KeysetPayload, opts ...KeysetTokenOpt) *KeysetToken {
+	return c.Next(append([]KeysetTokenOpt{WithKeysetPayloads(payloads)}, opts...)...)
+}
+
+// WithKeysetPayloads stores the keysets of the named lists on the token, see
+// NextWithPayloads. They are carried over by Next like the keyset payload.
+// Every list lengthens the token, which may need a higher
+// WithMaxTokenLength in Middleware, see DefaultMaxTokenLength.
+func WithKeysetPayloads(payloads map[string]*KeysetPayload) KeysetTokenOpt {
+	return func(c *KeysetToken) {
+		c.groups = make(map[string]*KeysetPayload, len(payloads))
+		for name, p := range payloads {
+			if p != nil {
+				c.groups[name] = p
+			}
+		}
+	}
+}
+
+// groupsWire is the plaintext encoding of the named keysets of a token.
+// Exhausted lists are left out, so the object is present but may be empty.
+type groupsWire struct {
+	Payloads map[string][]string `json:"k"`
+	// Sensitive holds the positions of the sensitive values of the keysets
+	// that have any.
+	Sensitive map[string][]int `json:"r,omitempty"`
+}
+
+func encodeGroups(groups map[string]*KeysetPayload) *groupsWire {
+	if groups == nil {
+		return nil
+	}
+
+	w := &groupsWire{Payloads: make(map[string][]string, len(groups))}
+	for name, p := range groups {
+		w.Payloads[name] = encodeKeysetValues(p.Values())
+		if is := sensitivePositions(p.Values()); is != nil {
+			if w.Sensitive == nil {
+				w.Sensitive = map[string][]int{}
+			}
+			w.Sensitive[name] = is
+		}
+	}
+
+	return w
+}
+
+func decodeGroups(w *groupsWire) (map[string]*KeysetPayload, error) {
+	if w == nil {
+		return nil, nil
+	}
+
+	groups := make(map[string]*KeysetPayload, len(w.Payloads))
+	for name, ps := range w.Payloads {
+		vs, err := decodeKeysetValues(ps)
+		if err != nil {
+			return nil, err
+		}
+		if err := markSensitive(vs, w.Sensitive[name]); err != nil {
+			return nil, err
+		}
+		groups[name] = &KeysetPayload{vs: vs}
+	}
+
+	return groups, nil
+}
@@ -0,0 +1,106 @@
+package pagetoken_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/encryption"
+	"github.com/pixlcrashr/go-pagetoken/order"
+	"github.com/pixlcrashr/go-pagetoken/pagetokentest"
+)
+
+var _ = Describe("Named keysets", func() {
+	var (
+		e      *encryption.AEADEncryptor
+		parser *pagetoken.KeysetTokenParser
+	)
+
+	BeforeEach(func() {
+		key, err := encryption.Rand32ByteKey()
+		Expect(err).NotTo(HaveOccurred())
+		e, err = encryption.NewAEADEncryptor(key)
+		Expect(err).NotTo(HaveOccurred())
+		parser = pagetoken.NewKeysetTokenParser(pagetoken.WithKeysetTokenEncryptor(e))
+	})
+
+	It("start every list on a token without named keysets", func() {
+		t := pagetoken.NewKeysetToken(e)
+
+		p, ok := t.PayloadFor("recent")
+		Expect(ok).To(BeTrue())
+		Expect(p.Values()).To(BeEmpty())
+		Expect(t.PayloadNames()).To(BeNil())
+	})
+
+	It("round-trip and treat missing lists as exhausted", func() {
+		recent := pagetoken.NewKeysetPayloadBuilder().
+			AddInt("id", 7, order.Desc).
+			Build()
+		recommended := pagetoken.NewKeysetPayloadBuilder().
+			AddStringSensitive("email", "jane@example.com", order.Asc).
+			Build()
+
+		next := pagetoken.NewKeysetToken(e).NextWithPayloads(map[string]*pagetoken.KeysetPayload{
+			"recent":      recent,
+			"recommended": recommended,
+			"popular":     nil,
+		})
+		s, err := next.String()
+		Expect(err).NotTo(HaveOccurred())
+
+		t, err := parser.Parse(s)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pagetokentest.Equivalent(t, next)).To(BeTrue())
+		Expect(t.PayloadNames()).To(Equal([]string{"recent", "recommended"}))
+
+		p, ok := t.PayloadFor("recent")
+		Expect(ok).To(BeTrue())
+		Expect(p.Values()).To(Equal(recent.Values()))
+
+		p, ok = t.PayloadFor("recommended")
+		Expect(ok).To(BeTrue())
+		Expect(p.Values()).To(Equal(recommended.Values()))
+
+		p, ok = t.PayloadFor("popular")
+		Expect(ok).To(BeFalse())
+		Expect(p).To(BeNil())
+	})
+
+	It("keep all lists exhausted once none is left", func() {
+		s, err := pagetoken.NewKeysetToken(e).NextWithPayloads(nil).String()
+		Expect(err).NotTo(HaveOccurred())
+
+		t, err := parser.Parse(s)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(t.PayloadNames()).To(BeEmpty())
+		Expect(t.PayloadNames()).NotTo(BeNil())
+
+		_, ok := t.PayloadFor("recent")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("are carried over by Next", func() {
+		t := pagetoken.NewKeysetToken(e).NextWithPayloads(map[string]*pagetoken.KeysetPayload{
+			"recent": pagetoken.NewKeysetPayloadBuilder().AddInt("id", 7, order.Asc).Build(),
+		}).Next()
+
+		Expect(t.PayloadNames()).To(Equal([]string{"recent"}))
+	})
+
+	It("change the cache key", func() {
+		t := pagetoken.NewKeysetToken(e)
+
+		a, err := pagetoken.CacheKey(request{}, t.NextWithPayloads(map[string]*pagetoken.KeysetPayload{
+			"recent": pagetoken.NewKeysetPayloadBuilder().AddInt("id", 7, order.Asc).Build(),
+		}))
+		Expect(err).NotTo(HaveOccurred())
+
+		b, err := pagetoken.CacheKey(request{}, t.NextWithPayloads(map[string]*pagetoken.KeysetPayload{
+			"recent": pagetoken.NewKeysetPayloadBuilder().AddInt("id", 8, order.Asc).Build(),
+		}))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(a).NotTo(Equal(b))
+	})
+})
@@ -0,0 +1,75 @@
+package pagetoken_test
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/order"
+	"github.com/pixlcrashr/go-pagetoken/pagetokentest"
+)
+
+type listResponse struct {
+	Items         []string               `json:"items"`
+	NextPageToken *pagetoken.KeysetToken `json:"next_page_token,omitempty"`
+}
+
+var _ = Describe("Token marshaling", func() {
+	var t *pagetoken.KeysetToken
+
+	BeforeEach(func() {
+		t = pagetoken.NewKeysetToken(pagetokentest.StaticCrypter{},
+			pagetoken.WithKeysetPayload(pagetoken.NewKeysetPayloadBuilder().
+				AddInt("id", 42, order.Asc).
+				Build()),
+		)
+	})
+
+	It("marshals a present token to its string", func() {
+		s, err := t.String()
+		Expect(err).NotTo(HaveOccurred())
+
+		d, err := json.Marshal(listResponse{Items: []string{"a"}, NextPageToken: t})
+		Expect(err).NotTo(HaveOccurred())
+
+		var got map[string]any
+		Expect(json.Unmarshal(d, &got)).To(Succeed())
+		Expect(got).To(HaveKeyWithValue("next_page_token", s))
+	})
+
+	It("omits an absent token", func() {
+		d, err := json.Marshal(listResponse{Items: []string{}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(d)).To(Equal(`{"items":[]}`))
+	})
+
+	It("marshals a nil token to an empty string", func() {
+		var nilToken *pagetoken.KeysetToken
+
+		d, err := nilToken.MarshalText()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(d).To(BeEmpty())
+
+		d, err = nilToken.MarshalJSON()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(d)).To(Equal(`""`))
+	})
+
+	It("marshals as text", func() {
+		s, err := t.String()
+		Expect(err).NotTo(HaveOccurred())
+
+		d, err := t.MarshalText()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(d)).To(Equal(s))
+	})
+
+	It("surfaces serialization errors from json.Marshal", func() {
+		failing := pagetoken.NewKeysetToken(failingCrypter{})
+
+		_, err := json.Marshal(listResponse{NextPageToken: failing})
+		Expect(err).To(MatchError(errWrongKeySize))
+	})
+})
@@ -1,28 +1,75 @@
 package pagetoken
 
 import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"net/netip"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/pixlcrashr/go-pagetoken/order"
 )
 
+// KeysetPayload holds the keyset values of a page boundary. It is immutable
+// once built and safe for concurrent use by multiple goroutines.
+//
+// Paths are not required to be unique. Lookups by path return the first
+// value with that path; Values returns all of them.
 type KeysetPayload struct {
 	vs []KeysetValue
+
+	// index maps each path to the position of its first value. It is built
+	// on the first lookup if there are more than indexMinValues values.
+	indexOnce sync.Once
+	index     map[string]int
 }
 
+// indexMinValues is the number of values up to which lookups scan the
+// values, which is faster than hashing the path for short payloads.
+const indexMinValues = 8
+
 func (kf *KeysetPayload) Values() []KeysetValue {
 	return kf.vs
 }
 
-// value looks up a single value by path name.
-func (kf *KeysetPayload) value(key string) (KeysetValue, error) {
-	for _, f := range kf.vs {
-		if f.Path == key {
-			return f, nil
+// SortSpec returns the paths and orders of the payload's values, in insertion
+// order.
+func (kf *KeysetPayload) SortSpec() SortSpec {
+	spec := make(SortSpec, len(kf.vs))
+	for i, v := range kf.vs {
+		spec[i] = order.Field{Path: v.Path, Order: v.Order}
+	}
+	return spec
+}
+
+// find returns the position of the first value with path key, or -1.
+func (kf *KeysetPayload) find(key string) int {
+	if len(kf.vs) <= indexMinValues {
+		for i := range kf.vs {
+			if kf.vs[i].Path == key {
+				return i
+			}
+		}
+		return -1
+	}
+
+	kf.indexOnce.Do(kf.buildIndex)
+	if i, ok := kf.index[key]; ok {
+		return i
+	}
+	return -1
+}
+
+func (kf *KeysetPayload) buildIndex() {
+	kf.index = make(map[string]int, len(kf.vs))
+	for i, f := range kf.vs {
+		if _, ok := kf.index[f.Path]; !ok {
+			kf.index[f.Path] = i
 		}
 	}
-	return KeysetValue{}, ErrFieldNotFound
 }
 
 func identity[T any](v T) (T, error) {
@@ -159,6 +206,19 @@ func (kf *KeysetPayload) Complex128(key string) (complex128, order.Order, error)
 	})
 }
 
+// --- arbitrary precision ---
+
+// BigInt decodes a value stored via AddBigInt.
+func (kf *KeysetPayload) BigInt(key string) (*big.Int, order.Order, error) {
+	return GetKeysetValue(kf, key, func(s string) (*big.Int, error) {
+		v, ok := new(big.Int).SetString(s, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid integer %q", s)
+		}
+		return v, nil
+	})
+}
+
 // --- time ---
 
 func (kf *KeysetPayload) Time(key string) (time.Time, order.Order, error) {
@@ -167,6 +227,89 @@ func (kf *KeysetPayload) Time(key string) (time.Time, order.Order, error) {
 	})
 }
 
+// Date decodes a value stored via AddDate.
+func (kf *KeysetPayload) Date(key string) (Date, order.Order, error) {
+	return GetKeysetValue(kf, key, ParseDate)
+}
+
+// --- bytes ---
+
+// Bytes decodes a value stored via AddBytes.
+func (kf *KeysetPayload) Bytes(key string) ([]byte, order.Order, error) {
+	return GetKeysetValue(kf, key, hex.DecodeString)
+}
+
+// UUID decodes a value stored via AddUUID.
+func (kf *KeysetPayload) UUID(key string) ([16]byte, order.Order, error) {
+	return GetKeysetValue(kf, key, func(s string) ([16]byte, error) {
+		var v [16]byte
+		if hex.DecodedLen(len(s)) != len(v) {
+			return v, fmt.Errorf("invalid 128-bit identifier length %d", len(s))
+		}
+		_, err := hex.Decode(v[:], []byte(s))
+		return v, err
+	})
+}
+
+// NetIP decodes a value stored via AddNetIP.
+func (kf *KeysetPayload) NetIP(key string) (netip.Addr, order.Order, error) {
+	return GetKeysetValue(kf, key, func(s string) (netip.Addr, error) {
+		var v [16]byte
+		if len(s) != 1+hex.EncodedLen(len(v)) {
+			return netip.Addr{}, fmt.Errorf("invalid IP address length %d", len(s))
+		}
+		if _, err := hex.Decode(v[:], []byte(s[1:])); err != nil {
+			return netip.Addr{}, err
+		}
+
+		switch s[0] {
+		case '0':
+			return netip.Addr{}, nil
+		case '4':
+			a := netip.AddrFrom16(v)
+			if !a.Is4In6() {
+				return netip.Addr{}, fmt.Errorf("invalid IPv4 address %s", a)
+			}
+			return a.Unmap(), nil
+		case '6':
+			return netip.AddrFrom16(v), nil
+		default:
+			return netip.Addr{}, fmt.Errorf("invalid IP address family %q", s[0])
+		}
+	})
+}
+
+// --- collated string ---
+
+func splitCollated(s string) (string, string, error) {
+	sortKey, value, ok := strings.Cut(s, ":")
+	if !ok {
+		return "", "", fmt.Errorf("invalid collated value %q", s)
+	}
+	return sortKey, value, nil
+}
+
+// CollatedString returns the original string of a value stored via
+// AddCollated.
+func (kf *KeysetPayload) CollatedString(key string) (string, order.Order, error) {
+	return GetKeysetValue(kf, key, func(s string) (string, error) {
+		_, value, err := splitCollated(s)
+		return value, err
+	})
+}
+
+// CollationKey returns the collation sort key of a value stored via
+// AddCollated. Comparing sort keys byte-wise yields the locale's order.
+func (kf *KeysetPayload) CollationKey(key string) ([]byte, order.Order, error) {
+	return GetKeysetValue(kf, key, func(s string) ([]byte, error) {
+		sortKey, _, err := splitCollated(s)
+		if err != nil {
+			return nil, err
+		}
+		return hex.DecodeString(sortKey)
+	})
+}
+
 // --- generic accessor ---
 
 type KeysetValueDecodeFn[T any] func(string) (T, error)
@@ -181,11 +324,12 @@ type KeysetValueDecodeFn[T any] func(string) (T, error)
 //
 //	id, order, err := pagetoken.GetKeysetValue(payload, "id", uuid.Parse)
 func GetKeysetValue[T any](kf *KeysetPayload, key string, decodeFn KeysetValueDecodeFn[T]) (T, order.Order, error) {
-	f, err := kf.value(key)
-	if err != nil {
+	i := kf.find(key)
+	if i < 0 {
 		var zero T
-		return zero, order.Desc, err
+		return zero, order.Desc, ErrFieldNotFound
 	}
+	f := &kf.vs[i]
 
 	v, err := decodeFn(f.Value)
 	if err != nil {
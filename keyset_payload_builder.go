@@ -1,23 +1,49 @@
 package pagetoken
 
 import (
+	"encoding/hex"
+	"math/big"
+	"net/netip"
 	"strconv"
 	"time"
 
 	"github.com/pixlcrashr/go-pagetoken/order"
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
 )
 
 // KeysetPayloadBuilder accumulates KeysetValues in insertion order.
 // Values may only be appended; the builder provides no way to delete,
 // edit, or reorder existing entries.
 type KeysetPayloadBuilder struct {
-	vs []KeysetValue
+	vs           []KeysetValue
+	defaultOrder order.Order
 }
 
 func NewKeysetPayloadBuilder() *KeysetPayloadBuilder {
 	return &KeysetPayloadBuilder{}
 }
 
+// NewKeysetPayloadBuilderWithOrder returns a builder whose adders without an
+// order parameter, such as AddStringD, use o, see DefaultOrder.
+func NewKeysetPayloadBuilderWithOrder(o order.Order) *KeysetPayloadBuilder {
+	return &KeysetPayloadBuilder{defaultOrder: o}
+}
+
+// DefaultOrder sets the order used by the adders without an order
+// parameter, such as AddStringD, for the values added after it. Adders
+// taking an order use theirs, so single fields can still deviate:
+//
+//	b := pagetoken.NewKeysetPayloadBuilderWithOrder(order.Desc).
+//		AddTimeD("created_at", t).
+//		AddInt64("id", id, order.Asc)
+//
+// The default order of a builder is order.Asc.
+func (b *KeysetPayloadBuilder) DefaultOrder(o order.Order) *KeysetPayloadBuilder {
+	b.defaultOrder = o
+	return b
+}
+
 // append is the single internal write path; all typed adders funnel through here.
 func (b *KeysetPayloadBuilder) append(key, value string, order order.Order) *KeysetPayloadBuilder {
 	b.vs = append(b.vs, KeysetValue{
@@ -28,6 +54,19 @@ func (b *KeysetPayloadBuilder) append(key, value string, order order.Order) *Key
 	return b
 }
 
+// Sensitive marks the value added last as sensitive, see
+// KeysetValue.Sensitive:
+//
+//	b.AddString("email", u.Email, order.Asc).Sensitive()
+//
+// It does nothing if no value was added yet.
+func (b *KeysetPayloadBuilder) Sensitive() *KeysetPayloadBuilder {
+	if len(b.vs) > 0 {
+		b.vs[len(b.vs)-1].Sensitive = true
+	}
+	return b
+}
+
 // Build produces an immutable KeysetPayload from the values accumulated
 // so far. The returned payload is independent of the builder: subsequent
 // adder calls do not affect it.
@@ -43,6 +82,11 @@ func (b *KeysetPayloadBuilder) AddString(key string, value string, order order.O
 	return b.append(key, value, order)
 }
 
+// AddStringSensitive is like AddString followed by Sensitive.
+func (b *KeysetPayloadBuilder) AddStringSensitive(key string, value string, order order.Order) *KeysetPayloadBuilder {
+	return b.AddString(key, value, order).Sensitive()
+}
+
 // --- bool ---
 
 func (b *KeysetPayloadBuilder) AddBool(key string, value bool, order order.Order) *KeysetPayloadBuilder {
@@ -125,12 +169,103 @@ func (b *KeysetPayloadBuilder) AddComplex128(key string, value complex128, order
 	return b.append(key, strconv.FormatComplex(value, 'g', -1, 128), order)
 }
 
+// --- arbitrary precision ---
+
+// AddBigInt stores an integer of any size, such as a uint256 identifier kept
+// in a NUMERIC or DECIMAL column, as its decimal string, e.g. "-42". value
+// must not be nil. Decimal strings do not sort like the numbers, so the
+// database must compare the column as a number: pass the string through to
+// the query, which binds it to the column's numeric type, rather than
+// comparing it as text or converting it to an int64 or float64:
+//
+//	q, err := ptgorm.KeysetWhereOrderLimit(db, keyset, func(column string, p *pagetoken.KeysetPayload) (any, error) {
+//		v, _, err := p.String(column)
+//		return v, err
+//	})
+//
+// Note that SQLite stores integers beyond 64 bits in NUMERIC columns as
+// floating point numbers, which are not exact.
+func (b *KeysetPayloadBuilder) AddBigInt(key string, value *big.Int, order order.Order) *KeysetPayloadBuilder {
+	return b.append(key, value.String(), order)
+}
+
 // --- time ---
 
 func (b *KeysetPayloadBuilder) AddTime(key string, value time.Time, order order.Order) *KeysetPayloadBuilder {
 	return b.append(key, value.Format(time.RFC3339Nano), order)
 }
 
+// AddDate stores a calendar date in ISO 8601 form, e.g. "2024-03-01", which
+// sorts like the date and which databases compare to DATE columns as a date.
+// Dates out of range, such as February 30, are normalized like time.Date
+// does. See DateOf for taking the date of a time.Time.
+func (b *KeysetPayloadBuilder) AddDate(key string, year int, month time.Month, day int, order order.Order) *KeysetPayloadBuilder {
+	return b.append(key, Date{Year: year, Month: month, Day: day}.String(), order)
+}
+
+// --- bytes ---
+
+// AddBytes stores value as lowercase hex. Fixed-length byte strings encoded
+// this way compare as strings exactly like the raw bytes compare in a
+// BINARY/BLOB/bytea column.
+func (b *KeysetPayloadBuilder) AddBytes(key string, value []byte, order order.Order) *KeysetPayloadBuilder {
+	return b.append(key, hex.EncodeToString(value), order)
+}
+
+// AddUUID stores a 128-bit identifier such as a UUIDv7 or a ULID in its
+// canonical 32-char lowercase hex form. uuid.UUID and ulid.ULID are both
+// [16]byte, so they can be passed after a plain conversion:
+//
+//	b.AddUUID("id", [16]byte(u), order.Asc)
+//
+// Unlike the dashed UUID string, the hex form sorts exactly like the
+// identifier's byte order, which is what BINARY(16) columns compare by.
+func (b *KeysetPayloadBuilder) AddUUID(key string, value [16]byte, order order.Order) *KeysetPayloadBuilder {
+	return b.AddBytes(key, value[:], order)
+}
+
+// AddNetIP stores an IP address in a fixed-width form that sorts like
+// Postgres inet columns do: a family digit, '4' for IPv4 and '6' for IPv6
+// (including IPv4-mapped IPv6 addresses), followed by the 16-byte form of
+// the address as lowercase hex, e.g. "4" + "00000000000000000000ffff0a000002"
+// for 10.0.0.2. Addresses of one family therefore compare numerically, so
+// 10.0.0.2 sorts before 10.0.0.10, and IPv4 addresses sort before IPv6
+// addresses.
+//
+// The family digit keeps 10.0.0.2 and ::ffff:10.0.0.2 apart, which inet
+// treats as different values. Zones are not stored. The zero Addr is stored
+// with the family digit '0'.
+func (b *KeysetPayloadBuilder) AddNetIP(key string, value netip.Addr, order order.Order) *KeysetPayloadBuilder {
+	family := byte('0')
+	switch {
+	case value.Is4():
+		family = '4'
+	case value.Is6():
+		family = '6'
+	}
+
+	v := value.As16()
+	buf := make([]byte, 1+hex.EncodedLen(len(v)))
+	buf[0] = family
+	hex.Encode(buf[1:], v[:])
+	return b.append(key, string(buf), order)
+}
+
+// --- collated string ---
+
+// AddCollated stores value together with its collation sort key for lang, so
+// that comparing the stored values byte-wise yields the locale's order, e.g.
+// "Äpfel" before "Zebra" in German but "åska" after "zebra" in Swedish.
+//
+// The stored form is the hex encoded sort key, which preserves the key's byte
+// order, followed by ':' and the original value. Byte-comparing backends use
+// CollationKey; SQL backends, which collate themselves, use CollatedString to
+// receive the original value.
+func (b *KeysetPayloadBuilder) AddCollated(key string, value string, lang language.Tag, order order.Order) *KeysetPayloadBuilder {
+	sortKey := collate.New(lang).KeyFromString(&collate.Buffer{}, value)
+	return b.append(key, hex.EncodeToString(sortKey)+":"+value, order)
+}
+
 type KeysetValueEncodeFn[T any] func(T) string
 
 // AddKeysetValue is the inverse of GetKeysetValue: it serialises value to a
@@ -138,7 +273,7 @@ type KeysetValueEncodeFn[T any] func(T) string
 //
 // Example:
 //
-//	pagetoken.AddKeysetValue(b, "id", someUUID, pagetoken.OrderAsc, uuid.UUID.String)
+//	pagetoken.AddKeysetValue(b, "id", someUUID, order.Asc, uuid.UUID.String)
 func AddKeysetValue[T any](b *KeysetPayloadBuilder, key string, value T, order order.Order, encodeFn KeysetValueEncodeFn[T]) *KeysetPayloadBuilder {
 	return b.append(key, encodeFn(value), order)
 }
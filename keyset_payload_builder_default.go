@@ -0,0 +1,152 @@
+package pagetoken
+
+import (
+	"math/big"
+	"net/netip"
+	"time"
+
+	"golang.org/x/text/language"
+)
+
+// The adders below take no order parameter and use the default order of the
+// builder instead, see DefaultOrder. They store their values exactly like
+// the adders of the same name without the D suffix.
+
+// --- string ---
+
+func (b *KeysetPayloadBuilder) AddStringD(key string, value string) *KeysetPayloadBuilder {
+	return b.AddString(key, value, b.defaultOrder)
+}
+
+// AddStringSensitiveD is like AddStringD followed by Sensitive.
+func (b *KeysetPayloadBuilder) AddStringSensitiveD(key string, value string) *KeysetPayloadBuilder {
+	return b.AddStringSensitive(key, value, b.defaultOrder)
+}
+
+// --- bool ---
+
+func (b *KeysetPayloadBuilder) AddBoolD(key string, value bool) *KeysetPayloadBuilder {
+	return b.AddBool(key, value, b.defaultOrder)
+}
+
+// --- signed integers ---
+
+func (b *KeysetPayloadBuilder) AddIntD(key string, value int) *KeysetPayloadBuilder {
+	return b.AddInt(key, value, b.defaultOrder)
+}
+
+func (b *KeysetPayloadBuilder) AddInt8D(key string, value int8) *KeysetPayloadBuilder {
+	return b.AddInt8(key, value, b.defaultOrder)
+}
+
+func (b *KeysetPayloadBuilder) AddInt16D(key string, value int16) *KeysetPayloadBuilder {
+	return b.AddInt16(key, value, b.defaultOrder)
+}
+
+func (b *KeysetPayloadBuilder) AddInt32D(key string, value int32) *KeysetPayloadBuilder {
+	return b.AddInt32(key, value, b.defaultOrder)
+}
+
+func (b *KeysetPayloadBuilder) AddInt64D(key string, value int64) *KeysetPayloadBuilder {
+	return b.AddInt64(key, value, b.defaultOrder)
+}
+
+// --- unsigned integers ---
+
+func (b *KeysetPayloadBuilder) AddUintD(key string, value uint) *KeysetPayloadBuilder {
+	return b.AddUint(key, value, b.defaultOrder)
+}
+
+func (b *KeysetPayloadBuilder) AddUint8D(key string, value uint8) *KeysetPayloadBuilder {
+	return b.AddUint8(key, value, b.defaultOrder)
+}
+
+func (b *KeysetPayloadBuilder) AddUint16D(key string, value uint16) *KeysetPayloadBuilder {
+	return b.AddUint16(key, value, b.defaultOrder)
+}
+
+func (b *KeysetPayloadBuilder) AddUint32D(key string, value uint32) *KeysetPayloadBuilder {
+	return b.AddUint32(key, value, b.defaultOrder)
+}
+
+func (b *KeysetPayloadBuilder) AddUint64D(key string, value uint64) *KeysetPayloadBuilder {
+	return b.AddUint64(key, value, b.defaultOrder)
+}
+
+// --- type aliases (byte = uint8, rune = int32) ---
+
+func (b *KeysetPayloadBuilder) AddByteD(key string, value byte) *KeysetPayloadBuilder {
+	return b.AddByte(key, value, b.defaultOrder)
+}
+
+func (b *KeysetPayloadBuilder) AddRuneD(key string, value rune) *KeysetPayloadBuilder {
+	return b.AddRune(key, value, b.defaultOrder)
+}
+
+// --- floating point ---
+
+func (b *KeysetPayloadBuilder) AddFloat32D(key string, value float32) *KeysetPayloadBuilder {
+	return b.AddFloat32(key, value, b.defaultOrder)
+}
+
+func (b *KeysetPayloadBuilder) AddFloat64D(key string, value float64) *KeysetPayloadBuilder {
+	return b.AddFloat64(key, value, b.defaultOrder)
+}
+
+// --- complex ---
+
+func (b *KeysetPayloadBuilder) AddComplex64D(key string, value complex64) *KeysetPayloadBuilder {
+	return b.AddComplex64(key, value, b.defaultOrder)
+}
+
+func (b *KeysetPayloadBuilder) AddComplex128D(key string, value complex128) *KeysetPayloadBuilder {
+	return b.AddComplex128(key, value, b.defaultOrder)
+}
+
+// --- arbitrary precision ---
+
+func (b *KeysetPayloadBuilder) AddBigIntD(key string, value *big.Int) *KeysetPayloadBuilder {
+	return b.AddBigInt(key, value, b.defaultOrder)
+}
+
+// --- time ---
+
+func (b *KeysetPayloadBuilder) AddTimeD(key string, value time.Time) *KeysetPayloadBuilder {
+	return b.AddTime(key, value, b.defaultOrder)
+}
+
+func (b *KeysetPayloadBuilder) AddDateD(key string, year int, month time.Month, day int) *KeysetPayloadBuilder {
+	return b.AddDate(key, year, month, day, b.defaultOrder)
+}
+
+// --- bytes ---
+
+func (b *KeysetPayloadBuilder) AddBytesD(key string, value []byte) *KeysetPayloadBuilder {
+	return b.AddBytes(key, value, b.defaultOrder)
+}
+
+func (b *KeysetPayloadBuilder) AddUUIDD(key string, value [16]byte) *KeysetPayloadBuilder {
+	return b.AddUUID(key, value, b.defaultOrder)
+}
+
+func (b *KeysetPayloadBuilder) AddNetIPD(key string, value netip.Addr) *KeysetPayloadBuilder {
+	return b.AddNetIP(key, value, b.defaultOrder)
+}
+
+// --- collated string ---
+
+func (b *KeysetPayloadBuilder) AddCollatedD(key string, value string, lang language.Tag) *KeysetPayloadBuilder {
+	return b.AddCollated(key, value, lang, b.defaultOrder)
+}
+
+// --- any ---
+
+// AddAutoD is like AddAuto with the default order of the builder.
+func (b *KeysetPayloadBuilder) AddAutoD(key string, value any) error {
+	return b.AddAuto(key, value, b.defaultOrder)
+}
+
+// AddKeysetValueD is like AddKeysetValue with the default order of b.
+func AddKeysetValueD[T any](b *KeysetPayloadBuilder, key string, value T, encodeFn KeysetValueEncodeFn[T]) *KeysetPayloadBuilder {
+	return AddKeysetValue(b, key, value, b.defaultOrder, encodeFn)
+}
@@ -0,0 +1,114 @@
+package pagetoken_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/order"
+	"golang.org/x/text/language"
+)
+
+var _ = Describe("KeysetPayloadBuilder default order", func() {
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	It("defaults to ascending", func() {
+		p := pagetoken.NewKeysetPayloadBuilder().AddIntD("id", 1).Build()
+		Expect(p.Values()[0].Order).To(Equal(order.Asc))
+	})
+
+	It("lets explicit orders win over the default", func() {
+		p := pagetoken.NewKeysetPayloadBuilderWithOrder(order.Desc).
+			AddTimeD("created_at", ts).
+			AddStringD("name", "b").
+			AddInt64("id", 42, order.Asc).
+			Build()
+
+		Expect(p.SortSpec()).To(Equal(pagetoken.SortSpec{
+			{Path: "created_at", Order: order.Desc},
+			{Path: "name", Order: order.Desc},
+			{Path: "id", Order: order.Asc},
+		}))
+	})
+
+	It("applies a changed default to the values added after it", func() {
+		p := pagetoken.NewKeysetPayloadBuilder().
+			AddIntD("a", 1).
+			DefaultOrder(order.Desc).
+			AddIntD("b", 2).
+			Build()
+
+		Expect(p.SortSpec()).To(Equal(pagetoken.SortSpec{
+			{Path: "a", Order: order.Asc},
+			{Path: "b", Order: order.Desc},
+		}))
+	})
+
+	It("serializes identically to the fully explicit form", func() {
+		defaulted := pagetoken.NewKeysetPayloadBuilderWithOrder(order.Desc).
+			AddStringD("string", "s").
+			AddStringSensitiveD("email", "jane@example.com").
+			AddBoolD("bool", true).
+			AddIntD("int", -1).
+			AddInt8D("int8", -8).
+			AddInt16D("int16", -16).
+			AddInt32D("int32", -32).
+			AddInt64D("int64", -64).
+			AddUintD("uint", 1).
+			AddUint8D("uint8", 8).
+			AddUint16D("uint16", 16).
+			AddUint32D("uint32", 32).
+			AddUint64D("uint64", 64).
+			AddByteD("byte", 'b').
+			AddRuneD("rune", 'r').
+			AddFloat32D("float32", 0.5).
+			AddFloat64D("float64", 0.25).
+			AddComplex64D("complex64", 1+2i).
+			AddComplex128D("complex128", 3+4i).
+			AddTimeD("time", ts).
+			AddBytesD("bytes", []byte{0xab}).
+			AddUUIDD("uuid", [16]byte{15: 1}).
+			AddCollatedD("collated", "Äpfel", language.German).
+			AddInt64("id", 42, order.Asc)
+		pagetoken.AddKeysetValueD(defaulted, "custom", 7, func(v int) string { return "v7" })
+		Expect(defaulted.AddAutoD("auto", uint16(3))).To(Succeed())
+
+		explicit := pagetoken.NewKeysetPayloadBuilder().
+			AddString("string", "s", order.Desc).
+			AddStringSensitive("email", "jane@example.com", order.Desc).
+			AddBool("bool", true, order.Desc).
+			AddInt("int", -1, order.Desc).
+			AddInt8("int8", -8, order.Desc).
+			AddInt16("int16", -16, order.Desc).
+			AddInt32("int32", -32, order.Desc).
+			AddInt64("int64", -64, order.Desc).
+			AddUint("uint", 1, order.Desc).
+			AddUint8("uint8", 8, order.Desc).
+			AddUint16("uint16", 16, order.Desc).
+			AddUint32("uint32", 32, order.Desc).
+			AddUint64("uint64", 64, order.Desc).
+			AddByte("byte", 'b', order.Desc).
+			AddRune("rune", 'r', order.Desc).
+			AddFloat32("float32", 0.5, order.Desc).
+			AddFloat64("float64", 0.25, order.Desc).
+			AddComplex64("complex64", 1+2i, order.Desc).
+			AddComplex128("complex128", 3+4i, order.Desc).
+			AddTime("time", ts, order.Desc).
+			AddBytes("bytes", []byte{0xab}, order.Desc).
+			AddUUID("uuid", [16]byte{15: 1}, order.Desc).
+			AddCollated("collated", "Äpfel", language.German, order.Desc).
+			AddInt64("id", 42, order.Asc)
+		pagetoken.AddKeysetValue(explicit, "custom", 7, order.Desc, func(v int) string { return "v7" })
+		Expect(explicit.AddAuto("auto", uint16(3), order.Desc)).To(Succeed())
+
+		a, err := pagetoken.EncodePayload(defaulted.Build())
+		Expect(err).NotTo(HaveOccurred())
+		b, err := pagetoken.EncodePayload(explicit.Build())
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(a).To(Equal(b))
+		Expect(defaulted.Build().Values()).To(Equal(explicit.Build().Values()))
+	})
+})
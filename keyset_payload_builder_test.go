@@ -1,7 +1,10 @@
 package pagetoken_test
 
 import (
+	"net/netip"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -9,6 +12,7 @@ import (
 
 	"github.com/pixlcrashr/go-pagetoken"
 	"github.com/pixlcrashr/go-pagetoken/order"
+	"golang.org/x/text/language"
 )
 
 var _ = Describe("KeysetPayloadBuilder", func() {
@@ -234,6 +238,104 @@ var _ = Describe("KeysetPayloadBuilder", func() {
 		})
 	})
 
+	// --- bytes ---
+
+	Describe("AddUUID", func() {
+		It("stores the canonical lowercase hex form", func() {
+			id := [16]byte{0x01, 0x8f, 0x3a, 0x7c, 0xde, 0xad, 0x7b, 0xee, 0xaf, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xff}
+			p := (&pagetoken.KeysetPayloadBuilder{}).AddUUID("id", id, order.Asc).Build()
+			Expect(p.Values()[0].Value).To(Equal("018f3a7cdead7beeaf000000000000ff"))
+		})
+
+		It("sorts like the raw bytes", func() {
+			lo := [16]byte{0x01, 0x0a}
+			hi := [16]byte{0x01, 0xa0}
+			p := (&pagetoken.KeysetPayloadBuilder{}).
+				AddUUID("lo", lo, order.Asc).
+				AddUUID("hi", hi, order.Asc).
+				Build()
+			Expect(p.Values()[0].Value < p.Values()[1].Value).To(BeTrue())
+		})
+	})
+
+	Describe("AddNetIP", func() {
+		It("stores the family digit and the 16-byte hex form", func() {
+			p := (&pagetoken.KeysetPayloadBuilder{}).
+				AddNetIP("v4", netip.MustParseAddr("10.0.0.2"), order.Asc).
+				AddNetIP("v6", netip.MustParseAddr("2001:db8::1"), order.Asc).
+				Build()
+			Expect(p.Values()[0].Value).To(Equal("400000000000000000000ffff0a000002"))
+			Expect(p.Values()[1].Value).To(Equal("620010db8000000000000000000000001"))
+		})
+
+		It("sorts like postgres inet", func() {
+			// inet orders by family first, then by address
+			inetOrder := []string{
+				"10.0.0.2",
+				"10.0.0.10",
+				"192.168.0.1",
+				"::1",
+				"::ffff:10.0.0.2",
+				"2001:db8::1",
+				"2001:db8::10",
+			}
+
+			values := []string{}
+			for _, s := range inetOrder {
+				p := (&pagetoken.KeysetPayloadBuilder{}).AddNetIP("ip", netip.MustParseAddr(s), order.Asc).Build()
+				values = append(values, p.Values()[0].Value)
+			}
+
+			Expect(slices.IsSorted(values)).To(BeTrue())
+		})
+	})
+
+	// --- collated string ---
+
+	Describe("AddCollated", func() {
+		// sortByValue orders words by the byte-wise comparison of their
+		// stored values, as non-SQL backends do.
+		sortByValue := func(lang language.Tag, words []string) []string {
+			b := &pagetoken.KeysetPayloadBuilder{}
+			for _, w := range words {
+				b.AddCollated(w, w, lang, order.Asc)
+			}
+			vs := b.Build().Values()
+			slices.SortFunc(vs, func(a, b pagetoken.KeysetValue) int {
+				return strings.Compare(a.Value, b.Value)
+			})
+
+			sorted := []string{}
+			for _, v := range vs {
+				sorted = append(sorted, v.Path)
+			}
+			return sorted
+		}
+
+		It("orders German umlauts next to their base letters", func() {
+			Expect(sortByValue(language.German, []string{"Zebra", "Öl", "Apfel", "Ofen", "Äpfel", "Ober"})).
+				To(Equal([]string{"Apfel", "Äpfel", "Ober", "Ofen", "Öl", "Zebra"}))
+		})
+
+		It("orders Swedish å, ä and ö after z", func() {
+			Expect(sortByValue(language.Swedish, []string{"öl", "åska", "zebra", "ärta", "apa"})).
+				To(Equal([]string{"apa", "zebra", "åska", "ärta", "öl"}))
+		})
+
+		It("differs from the byte order of the raw strings", func() {
+			Expect("Äpfel" > "Zebra").To(BeTrue())
+			Expect(sortByValue(language.German, []string{"Zebra", "Äpfel"})).To(Equal([]string{"Äpfel", "Zebra"}))
+		})
+
+		It("round-trips the original value", func() {
+			p := (&pagetoken.KeysetPayloadBuilder{}).AddCollated("name", "Müller: Öl", language.German, order.Desc).Build()
+			v, o, err := p.CollatedString("name")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(v).To(Equal("Müller: Öl"))
+			Expect(o).To(Equal(order.Desc))
+		})
+	})
+
 	// --- complex ---
 
 	Describe("AddComplex64", func() {
@@ -1,8 +1,12 @@
 package pagetoken_test
 
 import (
+	"bytes"
 	"errors"
+	"math/big"
+	"net/netip"
 	"strconv"
+	"testing"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -10,6 +14,7 @@ import (
 
 	"github.com/pixlcrashr/go-pagetoken"
 	"github.com/pixlcrashr/go-pagetoken/order"
+	"golang.org/x/text/language"
 )
 
 // build is a test helper that constructs a KeysetPayload via the builder.
@@ -38,6 +43,32 @@ var _ = Describe("KeysetPayload", func() {
 		})
 	})
 
+	DescribeTable("looks up the first value of a duplicated path",
+		func(n int) {
+			p := build(func(b *pagetoken.KeysetPayloadBuilder) {
+				for i := range n {
+					b.AddString("f"+strconv.Itoa(i), strconv.Itoa(i), order.Asc)
+				}
+				b.AddString("dup", "first", order.Desc).
+					AddString("dup", "second", order.Asc)
+			})
+			Expect(p.Values()).To(HaveLen(n + 2))
+
+			for range 2 {
+				v, o, err := p.String("dup")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(v).To(Equal("first"))
+				Expect(o).To(Equal(order.Desc))
+
+				v, _, err = p.String("f" + strconv.Itoa(n-1))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(v).To(Equal(strconv.Itoa(n - 1)))
+			}
+		},
+		Entry("in a short payload", 1),
+		Entry("in a payload looked up via its index", 30),
+	)
+
 	// --- string ---
 
 	Describe("String", func() {
@@ -563,6 +594,48 @@ var _ = Describe("KeysetPayload", func() {
 		})
 	})
 
+	// --- arbitrary precision ---
+
+	Describe("BigInt", func() {
+		DescribeTable("round-trips via AddBigInt",
+			func(s string) {
+				n, ok := new(big.Int).SetString(s, 10)
+				Expect(ok).To(BeTrue())
+				p := build(func(b *pagetoken.KeysetPayloadBuilder) {
+					b.AddBigInt("n", n, order.Desc)
+				})
+				Expect(p.Values()[0].Value).To(Equal(s))
+
+				v, o, err := p.BigInt("n")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(v.Cmp(n)).To(BeZero())
+				Expect(o).To(Equal(order.Desc))
+			},
+			Entry("zero", "0"),
+			Entry("2^64", "18446744073709551616"),
+			Entry("2^256-1", "115792089237316195423570985008687907853269984665640564039457584007913129639935"),
+			Entry("a negative value beyond -2^63", "-9223372036854775809"),
+		)
+
+		It("returns ErrFieldNotFound for a missing key", func() {
+			_, _, err := (&pagetoken.KeysetPayloadBuilder{}).Build().BigInt("missing")
+			Expect(err).To(MatchError(pagetoken.ErrFieldNotFound))
+		})
+
+		DescribeTable("returns an error for an invalid raw value",
+			func(raw string) {
+				p := build(func(b *pagetoken.KeysetPayloadBuilder) {
+					b.AddString("n", raw, order.Asc)
+				})
+				_, _, err := p.BigInt("n")
+				Expect(err).To(HaveOccurred())
+			},
+			Entry("a word", "not-a-number"),
+			Entry("a decimal fraction", "1.5"),
+			Entry("hex", "0x10"),
+		)
+	})
+
 	// --- time ---
 
 	Describe("Time", func() {
@@ -591,6 +664,125 @@ var _ = Describe("KeysetPayload", func() {
 		})
 	})
 
+	// --- bytes ---
+
+	Describe("Bytes", func() {
+		It("round-trips via AddBytes", func() {
+			p := build(func(b *pagetoken.KeysetPayloadBuilder) {
+				b.AddBytes("b", []byte{0x00, 0xab, 0xff}, order.Asc)
+			})
+			v, o, err := p.Bytes("b")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(v).To(Equal([]byte{0x00, 0xab, 0xff}))
+			Expect(o).To(Equal(order.Asc))
+		})
+
+		It("returns an error for an invalid raw value", func() {
+			p := build(func(b *pagetoken.KeysetPayloadBuilder) {
+				b.AddString("b", "not-hex", order.Asc)
+			})
+			_, _, err := p.Bytes("b")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("UUID", func() {
+		It("round-trips via AddUUID", func() {
+			id := [16]byte{0x01, 0x8f, 0x3a, 0x7c, 0x00, 0x00, 0x70, 0x00, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01}
+			p := build(func(b *pagetoken.KeysetPayloadBuilder) {
+				b.AddUUID("id", id, order.Desc)
+			})
+			v, o, err := p.UUID("id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(v).To(Equal(id))
+			Expect(o).To(Equal(order.Desc))
+		})
+
+		It("returns an error for a value of the wrong length", func() {
+			p := build(func(b *pagetoken.KeysetPayloadBuilder) {
+				b.AddBytes("id", []byte{0x01, 0x02}, order.Asc)
+			})
+			_, _, err := p.UUID("id")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("NetIP", func() {
+		DescribeTable("round-trips via AddNetIP",
+			func(s string) {
+				ip := netip.MustParseAddr(s)
+				p := build(func(b *pagetoken.KeysetPayloadBuilder) {
+					b.AddNetIP("ip", ip, order.Desc)
+				})
+				v, o, err := p.NetIP("ip")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(v).To(Equal(ip))
+				Expect(o).To(Equal(order.Desc))
+			},
+			Entry("IPv4", "10.0.0.2"),
+			Entry("IPv6", "2001:db8::1"),
+			Entry("IPv4-mapped IPv6", "::ffff:10.0.0.2"),
+		)
+
+		It("round-trips the zero address", func() {
+			p := build(func(b *pagetoken.KeysetPayloadBuilder) {
+				b.AddNetIP("ip", netip.Addr{}, order.Asc)
+			})
+			v, _, err := p.NetIP("ip")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(v.IsValid()).To(BeFalse())
+		})
+
+		It("drops zones", func() {
+			p := build(func(b *pagetoken.KeysetPayloadBuilder) {
+				b.AddNetIP("ip", netip.MustParseAddr("fe80::1%eth0"), order.Asc)
+			})
+			v, _, err := p.NetIP("ip")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(v).To(Equal(netip.MustParseAddr("fe80::1")))
+		})
+
+		DescribeTable("returns an error for malformed values",
+			func(value string) {
+				p := build(func(b *pagetoken.KeysetPayloadBuilder) {
+					b.AddString("ip", value, order.Asc)
+				})
+				_, _, err := p.NetIP("ip")
+				Expect(err).To(HaveOccurred())
+			},
+			Entry("a dotted quad", "10.0.0.2"),
+			Entry("an unknown family", "500000000000000000000ffff0a000002"),
+			Entry("an IPv4 family without the mapped prefix", "420010db8000000000000000000000001"),
+			Entry("invalid hex", "4zz000000000000000000ffff0a000002"),
+		)
+	})
+
+	// --- collated string ---
+
+	Describe("CollationKey", func() {
+		It("returns byte-comparable sort keys", func() {
+			p := build(func(b *pagetoken.KeysetPayloadBuilder) {
+				b.AddCollated("a", "Äpfel", language.German, order.Asc).
+					AddCollated("z", "Zebra", language.German, order.Asc)
+			})
+			a, _, err := p.CollationKey("a")
+			Expect(err).NotTo(HaveOccurred())
+			z, _, err := p.CollationKey("z")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(bytes.Compare(a, z)).To(Equal(-1))
+		})
+
+		It("returns an error for a value not stored via AddCollated", func() {
+			p := build(func(b *pagetoken.KeysetPayloadBuilder) {
+				b.AddString("s", "plain", order.Asc)
+			})
+			_, _, err := p.CollationKey("s")
+			Expect(err).To(HaveOccurred())
+			_, _, err = p.CollatedString("s")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
 	// --- generic accessor ---
 
 	Describe("GetKeysetValue", func() {
@@ -622,3 +814,26 @@ var _ = Describe("KeysetPayload", func() {
 		})
 	})
 })
+
+// BenchmarkKeysetPayloadLookup looks up every field of the payload once per
+// iteration, like a query builder binding each keyset column.
+func BenchmarkKeysetPayloadLookup(b *testing.B) {
+	for _, n := range []int{2, 8, 32} {
+		b.Run("fields="+strconv.Itoa(n), func(b *testing.B) {
+			p := benchmarkPayload(n)
+			paths := make([]string, n)
+			for i, v := range p.Values() {
+				paths[i] = v.Path
+			}
+
+			b.ReportAllocs()
+			for b.Loop() {
+				for _, path := range paths {
+					if _, _, err := p.String(path); err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+		})
+	}
+}
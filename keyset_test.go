@@ -1,9 +1,421 @@
 package pagetoken_test
 
 import (
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
 	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/checksum"
+	"github.com/pixlcrashr/go-pagetoken/encryption"
+	"github.com/pixlcrashr/go-pagetoken/order"
 )
 
+type request struct {
+	token string
+}
+
+func (r request) GetChecksumFields() []checksum.BuilderOpt {
+	return nil
+}
+
+func (r request) GetPageToken() string {
+	return r.token
+}
+
 var _ = Describe("Token", func() {
+	var e *encryption.AEADEncryptor
+
+	BeforeEach(func() {
+		key, err := encryption.Rand32ByteKey()
+		Expect(err).NotTo(HaveOccurred())
+		e, err = encryption.NewAEADEncryptor(key)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("round-trips the keyset", func() {
+		rr := pagetoken.NewRequestReader(pagetoken.WithEncryptor(e))
+		t, err := rr.Read(request{})
+		Expect(err).NotTo(HaveOccurred())
+
+		s, err := t.Next(pagetoken.WithKeysetPayload(
+			pagetoken.NewKeysetPayloadBuilder().
+				AddString("name", "b", order.Desc).
+				AddInt("id", 42, order.Asc).
+				Build(),
+		)).String()
+		Expect(err).NotTo(HaveOccurred())
+
+		t, err = rr.Read(request{token: s})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(t.Payload().Values()).To(Equal([]pagetoken.KeysetValue{
+			{Path: "name", Order: order.Desc, Value: "b"},
+			{Path: "id", Order: order.Asc, Value: "42"},
+		}))
+	})
+
+	It("round-trips the keyset through the parser", func() {
+		at := time.Date(2026, 3, 1, 12, 30, 0, 0, time.UTC)
+		rr := pagetoken.NewRequestReader(pagetoken.WithEncryptor(e))
+		t, err := rr.Read(request{})
+		Expect(err).NotTo(HaveOccurred())
+
+		s, err := t.Next(pagetoken.WithKeysetPayload(
+			pagetoken.NewKeysetPayloadBuilder().
+				AddTime("created_at", at, order.Desc).
+				AddString("email", "jane@example.com", order.Asc).Sensitive().
+				AddInt64("id", 7, order.Asc).
+				Build(),
+		)).String()
+		Expect(err).NotTo(HaveOccurred())
+
+		t, err = pagetoken.NewKeysetTokenParser(pagetoken.WithKeysetTokenEncryptor(e)).Parse(s)
+		Expect(err).NotTo(HaveOccurred())
+		p := t.Payload()
+		Expect(p.SortSpec()).To(Equal(pagetoken.SortSpec{
+			{Path: "created_at", Order: order.Desc},
+			{Path: "email", Order: order.Asc},
+			{Path: "id", Order: order.Asc},
+		}))
+
+		createdAt, _, err := p.Time("created_at")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(createdAt).To(BeTemporally("==", at))
+		email, _, err := p.String("email")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(email).To(Equal("jane@example.com"))
+		Expect(p.Values()[1].Sensitive).To(BeTrue())
+		id, _, err := p.Int64("id")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(id).To(BeEquivalentTo(7))
+	})
+
+	Describe("TotalCount", func() {
+		It("is unset on a fresh token", func() {
+			t, err := pagetoken.NewRequestReader(pagetoken.WithEncryptor(e)).Read(request{})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, ok := t.TotalCount()
+			Expect(ok).To(BeFalse())
+		})
+
+		It("is carried over by Next and survives serialization", func() {
+			rr := pagetoken.NewRequestReader(pagetoken.WithEncryptor(e))
+			t, err := rr.Read(request{})
+			Expect(err).NotTo(HaveOccurred())
+
+			t.SetTotalCount(250)
+			s, err := t.Next().String()
+			Expect(err).NotTo(HaveOccurred())
+
+			t, err = rr.Read(request{token: s})
+			Expect(err).NotTo(HaveOccurred())
+
+			n, ok := t.TotalCount()
+			Expect(ok).To(BeTrue())
+			Expect(n).To(Equal(int64(250)))
+		})
+
+		It("can be overridden via WithTotalCount", func() {
+			t, err := pagetoken.NewRequestReader(pagetoken.WithEncryptor(e)).Read(request{})
+			Expect(err).NotTo(HaveOccurred())
+
+			n, ok := t.Next(pagetoken.WithTotalCount(0)).TotalCount()
+			Expect(ok).To(BeTrue())
+			Expect(n).To(BeZero())
+		})
+	})
+
+	Describe("SnapshotTime", func() {
+		It("is carried over by Next and survives serialization", func() {
+			rr := pagetoken.NewRequestReader(pagetoken.WithEncryptor(e))
+			t, err := rr.Read(request{})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, ok := t.SnapshotTime()
+			Expect(ok).To(BeFalse())
+
+			ts := time.Date(2024, 1, 1, 12, 0, 0, 123456789, time.UTC)
+			s, err := t.Next(pagetoken.WithSnapshotTime(ts)).String()
+			Expect(err).NotTo(HaveOccurred())
+
+			t, err = rr.Read(request{token: s})
+			Expect(err).NotTo(HaveOccurred())
+
+			got, ok := t.Next().SnapshotTime()
+			Expect(ok).To(BeTrue())
+			Expect(got).To(Equal(ts))
+		})
+	})
+
+	Describe("UpstreamToken", func() {
+		It("round-trips a multi-KB upstream token next to the keyset", func() {
+			rr := pagetoken.NewRequestReader(pagetoken.WithEncryptor(e))
+			t, err := rr.Read(request{})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, ok := t.UpstreamToken()
+			Expect(ok).To(BeFalse())
+
+			upstream := strings.Repeat("1/aWNvbnRpbnVhdGlvbi10b2tlbg==+", 200)
+			Expect(len(upstream)).To(BeNumerically(">", 4096))
+
+			s, err := t.Next(
+				pagetoken.WithKeysetPayload(pagetoken.NewKeysetPayloadBuilder().AddInt("id", 42, order.Asc).Build()),
+				pagetoken.WithUpstreamToken(upstream),
+			).String()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(s).NotTo(ContainSubstring("aWNvbnRpbnVhdGlvbi10b2tlbg"))
+
+			t, err = rr.Read(request{token: s})
+			Expect(err).NotTo(HaveOccurred())
+
+			got, ok := t.Next().UpstreamToken()
+			Expect(ok).To(BeTrue())
+			Expect(got).To(Equal(upstream))
+			Expect(t.Payload().Values()).To(Equal([]pagetoken.KeysetValue{
+				{Path: "id", Order: order.Asc, Value: "42"},
+			}))
+		})
 
+		It("refuses to serialize upstream tokens over the limit", func() {
+			t := pagetoken.NewKeysetToken(e, pagetoken.WithUpstreamToken(strings.Repeat("x", pagetoken.MaxUpstreamTokenLength+1)))
+			_, err := t.String()
+			Expect(err).To(MatchError(pagetoken.ErrUpstreamTokenTooLong))
+
+			_, err = pagetoken.ItemCursors(t, []*pagetoken.KeysetPayload{pagetoken.NewKeysetPayloadBuilder().Build()})
+			Expect(err).To(MatchError(pagetoken.ErrUpstreamTokenTooLong))
+		})
+	})
+
+	Describe("String", func() {
+		It("returns the same string on repeated and concurrent calls", func() {
+			t := pagetoken.NewKeysetToken(e, pagetoken.WithKeysetPayload(benchmarkPayload(3)))
+
+			s, err := t.String()
+			Expect(err).NotTo(HaveOccurred())
+
+			var wg sync.WaitGroup
+			for range 8 {
+				wg.Go(func() {
+					defer GinkgoRecover()
+
+					got, err := t.String()
+					Expect(err).NotTo(HaveOccurred())
+					Expect(got).To(Equal(s))
+				})
+			}
+			wg.Wait()
+		})
+
+		It("encrypts only once", func() {
+			calls := 0
+			t := pagetoken.NewKeysetToken(countingCrypter{token: "t", calls: &calls})
+
+			for range 3 {
+				s, err := t.String()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(s).To(Equal("t"))
+			}
+			Expect(calls).To(Equal(1))
+
+			_, err := t.Next().String()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(calls).To(Equal(2), "tokens returned by Next are serialized on their own")
+		})
+
+		DescribeTable("serializes again after a setter modified the token",
+			func(set func(t *pagetoken.KeysetToken), check func(t *pagetoken.KeysetToken)) {
+				calls := 0
+				t := pagetoken.NewKeysetToken(countingCrypter{token: "t", calls: &calls})
+				_, err := t.String()
+				Expect(err).NotTo(HaveOccurred())
+
+				set(t)
+				_, err = t.String()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(calls).To(Equal(2))
+
+				aead := pagetoken.NewKeysetToken(e)
+				before, err := aead.String()
+				Expect(err).NotTo(HaveOccurred())
+
+				set(aead)
+				after, err := aead.String()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(after).NotTo(Equal(before))
+
+				parsed, err := pagetoken.NewKeysetTokenParser(pagetoken.WithKeysetTokenEncryptor(e)).Parse(after)
+				Expect(err).NotTo(HaveOccurred())
+				check(parsed)
+			},
+			Entry("SetTotalCount",
+				func(t *pagetoken.KeysetToken) { t.SetTotalCount(7) },
+				func(t *pagetoken.KeysetToken) {
+					n, _ := t.TotalCount()
+					Expect(n).To(Equal(int64(7)))
+				},
+			),
+			Entry("SetSnapshotTime",
+				func(t *pagetoken.KeysetToken) { t.SetSnapshotTime(time.Unix(1704067200, 0)) },
+				func(t *pagetoken.KeysetToken) {
+					ts, _ := t.SnapshotTime()
+					Expect(ts.Unix()).To(Equal(int64(1704067200)))
+				},
+			),
+			Entry("SetUpstreamToken",
+				func(t *pagetoken.KeysetToken) { t.SetUpstreamToken("upstream") },
+				func(t *pagetoken.KeysetToken) {
+					u, _ := t.UpstreamToken()
+					Expect(u).To(Equal("upstream"))
+				},
+			),
+		)
+	})
+
+	It("serializes tokens concurrently without sharing buffers", func() {
+		const n = 64
+
+		ss := make([]string, n)
+		errs := make([]error, n)
+		var wg sync.WaitGroup
+		for i := range n {
+			wg.Go(func() {
+				p := pagetoken.NewKeysetPayloadBuilder().
+					AddString("name", strings.Repeat(strconv.Itoa(i), i+1), order.Asc).
+					AddInt("id", i, order.Asc).
+					Build()
+				for range 20 {
+					ss[i], errs[i] = pagetoken.NewKeysetToken(e, pagetoken.WithKeysetPayload(p)).String()
+				}
+			})
+		}
+		wg.Wait()
+
+		parser := pagetoken.NewKeysetTokenParser(pagetoken.WithKeysetTokenEncryptor(e))
+		for i := range n {
+			Expect(errs[i]).NotTo(HaveOccurred())
+
+			t, err := parser.Parse(ss[i])
+			Expect(err).NotTo(HaveOccurred())
+			id, _, err := t.Payload().Int("id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(id).To(Equal(i))
+			name, _, err := t.Payload().String("name")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(name).To(Equal(strings.Repeat(strconv.Itoa(i), i+1)))
+		}
+	})
+
+	It("parses legacy tokens", func() {
+		s, err := e.Encrypt([]byte(`["id","42","asc","0"]` + "\n"))
+		Expect(err).NotTo(HaveOccurred())
+
+		t, err := pagetoken.NewKeysetTokenParser(pagetoken.WithKeysetTokenEncryptor(e)).Parse(s)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(t.Checksum()).To(BeZero())
+
+		id, o, err := t.Payload().Int("id")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(id).To(Equal(42))
+		Expect(o).To(Equal(order.Asc))
+
+		_, ok := t.TotalCount()
+		Expect(ok).To(BeFalse())
+	})
 })
+
+// benchmarkPayload returns a payload of n fields of mixed types.
+func benchmarkPayload(n int) *pagetoken.KeysetPayload {
+	at := time.Date(2024, 1, 1, 12, 30, 0, 0, time.UTC)
+
+	b := pagetoken.NewKeysetPayloadBuilder()
+	for i := range n {
+		switch i % 3 {
+		case 0:
+			b.AddTime("created_at_"+strconv.Itoa(i), at, order.Desc)
+		case 1:
+			b.AddString("name_"+strconv.Itoa(i), "user "+strconv.Itoa(i), order.Asc)
+		default:
+			b.AddInt64("id_"+strconv.Itoa(i), int64(i)*7919, order.Asc)
+		}
+	}
+	return b.Build()
+}
+
+func BenchmarkKeysetTokenString(b *testing.B) {
+	for _, n := range []int{1, 3, 8} {
+		b.Run("fields="+strconv.Itoa(n), func(b *testing.B) {
+			t := benchmarkToken(b).Next(pagetoken.WithKeysetPayload(benchmarkPayload(n)))
+
+			b.ReportAllocs()
+			for b.Loop() {
+				if _, err := t.String(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkKeysetTokenParse(b *testing.B) {
+	for _, n := range []int{1, 3, 8} {
+		b.Run("fields="+strconv.Itoa(n), func(b *testing.B) {
+			key, err := encryption.Rand32ByteKey()
+			if err != nil {
+				b.Fatal(err)
+			}
+			e, err := encryption.NewAEADEncryptor(key)
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			s, err := pagetoken.NewKeysetToken(e, pagetoken.WithKeysetPayload(benchmarkPayload(n))).String()
+			if err != nil {
+				b.Fatal(err)
+			}
+			parser := pagetoken.NewKeysetTokenParser(pagetoken.WithKeysetTokenEncryptor(e))
+
+			b.ReportAllocs()
+			for b.Loop() {
+				if _, err := parser.Parse(s); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkKeysetTokenStringParallel(b *testing.B) {
+	t := benchmarkToken(b).Next(pagetoken.WithKeysetPayload(benchmarkPayload(3)))
+
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := t.String(); err != nil {
+				b.Error(err)
+				return
+			}
+		}
+	})
+}
+
+func BenchmarkItemCursorsParallel(b *testing.B) {
+	t := benchmarkToken(b)
+	ps := itemPayloads(50)
+
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := pagetoken.ItemCursors(t, ps); err != nil {
+				b.Error(err)
+				return
+			}
+		}
+	})
+}
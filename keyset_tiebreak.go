@@ -0,0 +1,50 @@
+package pagetoken
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/pixlcrashr/go-pagetoken/order"
+)
+
+// ErrTiebreakConflict is returned by EnsurePayloadTiebreakStrict if the
+// payload already carries the tiebreak path with another order.
+var ErrTiebreakConflict = errors.New("tiebreak conflicts with payload")
+
+// EnsurePayloadTiebreak returns p with a value for path appended if p does
+// not carry path yet, the payload counterpart of EnsureTiebreak for payloads
+// built elsewhere, e.g. by AddAuto or for a CompositePayload. value is the
+// encoded value as stored by the adders of KeysetPayloadBuilder, e.g.
+// strconv.FormatInt(id, 10) for AddInt64.
+//
+// A value already present for path is kept as is, including its order, so
+// are all other values. A nil p yields a payload of the tiebreak alone. p
+// itself is never modified.
+func EnsurePayloadTiebreak(p *KeysetPayload, path string, o order.Order, value string) *KeysetPayload {
+	if p != nil && p.find(path) >= 0 {
+		return p
+	}
+
+	var vs []KeysetValue
+	if p != nil {
+		vs = p.vs
+	}
+
+	out := make([]KeysetValue, len(vs), len(vs)+1)
+	copy(out, vs)
+	return &KeysetPayload{vs: append(out, KeysetValue{Path: path, Value: value, Order: o})}
+}
+
+// EnsurePayloadTiebreakStrict is like EnsurePayloadTiebreak, but fails with
+// ErrTiebreakConflict if p carries path with an order other than o, which
+// would page through the rows tied on the other paths in the wrong
+// direction.
+func EnsurePayloadTiebreakStrict(p *KeysetPayload, path string, o order.Order, value string) (*KeysetPayload, error) {
+	if p != nil {
+		if i := p.find(path); i >= 0 && p.vs[i].Order != o {
+			return nil, fmt.Errorf("%w: %s is ordered %s, not %s", ErrTiebreakConflict, path, p.vs[i].Order, o)
+		}
+	}
+
+	return EnsurePayloadTiebreak(p, path, o, value), nil
+}
@@ -0,0 +1,64 @@
+package pagetoken_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/order"
+)
+
+var _ = Describe("EnsurePayloadTiebreak", func() {
+	p := pagetoken.NewKeysetPayloadBuilder().
+		AddString("name", "ada", order.Desc).
+		AddInt("id", 7, order.Asc).
+		Build()
+
+	It("appends a missing tiebreak", func() {
+		p := pagetoken.NewKeysetPayloadBuilder().AddString("name", "ada", order.Desc).Build()
+
+		got := pagetoken.EnsurePayloadTiebreak(p, "id", order.Desc, "7")
+		Expect(got.Values()).To(Equal([]pagetoken.KeysetValue{
+			{Path: "name", Value: "ada", Order: order.Desc},
+			{Path: "id", Value: "7", Order: order.Desc},
+		}))
+		Expect(p.Values()).To(HaveLen(1), "the input payload must not be modified")
+	})
+
+	It("keeps a present tiebreak with a matching order", func() {
+		Expect(pagetoken.EnsurePayloadTiebreak(p, "id", order.Asc, "8")).To(BeIdenticalTo(p))
+	})
+
+	It("keeps a present tiebreak with a conflicting order", func() {
+		Expect(pagetoken.EnsurePayloadTiebreak(p, "id", order.Desc, "8")).To(BeIdenticalTo(p))
+	})
+
+	It("builds a payload of the tiebreak alone for a nil payload", func() {
+		got := pagetoken.EnsurePayloadTiebreak(nil, "id", order.Asc, "1")
+		Expect(got.Values()).To(Equal([]pagetoken.KeysetValue{{Path: "id", Value: "1", Order: order.Asc}}))
+	})
+
+	Describe("EnsurePayloadTiebreakStrict", func() {
+		It("appends a missing tiebreak", func() {
+			p := pagetoken.NewKeysetPayloadBuilder().AddString("name", "ada", order.Desc).Build()
+
+			got, err := pagetoken.EnsurePayloadTiebreakStrict(p, "id", order.Desc, "7")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(got.SortSpec()).To(Equal(pagetoken.SortSpec{
+				{Path: "name", Order: order.Desc},
+				{Path: "id", Order: order.Desc},
+			}))
+		})
+
+		It("keeps a present tiebreak with a matching order", func() {
+			got, err := pagetoken.EnsurePayloadTiebreakStrict(p, "id", order.Asc, "8")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(got).To(BeIdenticalTo(p))
+		})
+
+		It("rejects a present tiebreak with a conflicting order", func() {
+			_, err := pagetoken.EnsurePayloadTiebreakStrict(p, "id", order.Desc, "8")
+			Expect(err).To(MatchError(pagetoken.ErrTiebreakConflict))
+		})
+	})
+})
@@ -0,0 +1,516 @@
+package pagetoken
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pixlcrashr/go-pagetoken/order"
+)
+
+// The golden fixtures in testdata/golden pin the token formats; a fixture for
+// a newly added version is minted with:
+//
+//go:generate go run ./tools/gen-golden
+
+// Token plaintext formats
+//
+// Legacy tokens are a JSON array of path/value/order triples followed by the
+// checksum:
+//
+//	["created_at","2024-01-01T00:00:00Z","desc","id","42","asc","1234567890"]
+//
+// Versioned tokens start with a single version byte followed by a JSON
+// object, which leaves room for token metadata next to the keyset:
+//
+//	0x01 {"k":["created_at","2024-01-01T00:00:00Z","desc","id","42","asc"],"c":1234567890,"n":250,"s":1704067200000000000,"u":"...","p":{"p":{"shard-1":["id","7","asc"]},"d":["shard-2"]},"i":3,"t":1704067200,"r":[1]}
+//
+// The "r" member lists the positions of the sensitive values of the keyset,
+// see KeysetValue.Sensitive; it is left out if there are none, like the
+// other optional members. Readers that predate it ignore it.
+//
+// Tokens paging several lists carry their named keysets in the "g" member,
+// see NextWithPayloads; exhausted lists are left out:
+//
+//	"g":{"k":{"recent":["id","7","asc"]},"r":{"recent":[0]}}
+//
+// Tokens of a shard of a split listing carry the inclusive upper bound of
+// their key range in the "b" member, see SplitToken:
+//
+//	"b":["id","500","asc"]
+//
+// Tokens of scoped requests carry their scope in the "o" member, see
+// Scoper, and tokens of readers created with WithTokenIDs a random ID in the
+// "j" member:
+//
+//	"o":"/books.v1.BookService/ListBooks","j":"MFRGGZDFMZTWQ2LKNNWG23TPOA"
+//
+// A legacy plaintext always starts with '[', so the first byte tells both
+// formats apart. String always emits the latest version; Parse accepts all
+// of them.
+//
+// EncodePayload follows the same scheme for a bare keyset: the version byte
+// followed by the keyset as stored in the "k" member of that version.
+//
+// Outside of the encryption, token strings may be tagged with a prefix, see
+// WithTokenPrefix. Tagged and untagged token strings parse alike.
+const keysetTokenV1 byte = 0x01
+
+type keysetTokenV1Body struct {
+	Keyset     json.RawMessage `json:"k"`
+	Checksum   uint32          `json:"c"`
+	TotalCount *int64          `json:"n,omitempty"`
+	Snapshot   *int64          `json:"s,omitempty"`
+	Upstream   *string         `json:"u,omitempty"`
+	Composite  *compositeWire  `json:"p,omitempty"`
+	PageIndex  int             `json:"i,omitempty"`
+	IssuedAt   int64           `json:"t,omitempty"`
+	Sensitive  []int           `json:"r,omitempty"`
+	Groups     *groupsWire     `json:"g,omitempty"`
+	UpperBound []string        `json:"b,omitempty"`
+	Scope      string          `json:"o,omitempty"`
+	ID         string          `json:"j,omitempty"`
+}
+
+func encodeKeysetValues(vs []KeysetValue) []string {
+	d := make([]string, len(vs)*3)
+
+	for i, field := range vs {
+		d[i*3] = field.Path
+		d[i*3+1] = field.Value
+		d[i*3+2] = field.Order.String()
+	}
+
+	return d
+}
+
+func decodeKeysetValues(ps []string) ([]KeysetValue, error) {
+	if len(ps)%3 != 0 {
+		return nil, fmt.Errorf("%w: invalid keyset length %d", ErrMalformedToken, len(ps))
+	}
+
+	vs := make([]KeysetValue, 0, len(ps)/3)
+	for i := 0; i < len(ps); i += 3 {
+		var o order.Order
+		if err := o.UnmarshalString(ps[i+2]); err != nil {
+			return nil, err
+		}
+
+		vs = append(vs, KeysetValue{
+			Path:  ps[i],
+			Value: ps[i+1],
+			Order: o,
+		})
+	}
+
+	return vs, nil
+}
+
+// sensitivePositions returns the positions of the sensitive values of vs, or
+// nil if there are none.
+func sensitivePositions(vs []KeysetValue) []int {
+	var is []int
+	for i, v := range vs {
+		if v.Sensitive {
+			is = append(is, i)
+		}
+	}
+
+	return is
+}
+
+// markSensitive marks the values of vs at the positions is as sensitive.
+func markSensitive(vs []KeysetValue, is []int) error {
+	for _, i := range is {
+		if i < 0 || i >= len(vs) {
+			return fmt.Errorf("sensitive position %d out of range", i)
+		}
+		vs[i].Sensitive = true
+	}
+
+	return nil
+}
+
+// decodeKeysetStrings decodes the JSON string array of an encoded keyset.
+// Arrays as written by appendV1 for printable ASCII values are split
+// without decoding every string on its own; all others are decoded by
+// encoding/json.
+func decodeKeysetStrings(raw json.RawMessage) ([]string, error) {
+	if raw == nil {
+		return nil, nil
+	}
+
+	if ps, ok := splitASCIIStrings(raw); ok {
+		return ps, nil
+	}
+
+	var ps []string
+	if err := json.Unmarshal(raw, &ps); err != nil {
+		return nil, err
+	}
+
+	return ps, nil
+}
+
+// splitASCIIStrings splits a compact JSON array of strings consisting of
+// printable ASCII characters other than backslashes. The strings share the
+// memory of a single copy of raw. It reports false for any other input.
+func splitASCIIStrings(raw []byte) ([]string, bool) {
+	if len(raw) < 2 || raw[0] != '[' || raw[len(raw)-1] != ']' {
+		return nil, false
+	}
+
+	s := string(raw[1 : len(raw)-1])
+	ps := make([]string, 0, strings.Count(s, `"`)/2)
+	for i := 0; i < len(s); {
+		if len(ps) > 0 {
+			if s[i] != ',' {
+				return nil, false
+			}
+			i++
+		}
+
+		if i >= len(s) || s[i] != '"' {
+			return nil, false
+		}
+
+		j := strings.IndexByte(s[i+1:], '"')
+		if j < 0 {
+			return nil, false
+		}
+
+		p := s[i+1 : i+1+j]
+		for k := 0; k < len(p); k++ {
+			if p[k] < 0x20 || p[k] >= 0x7f || p[k] == '\\' {
+				return nil, false
+			}
+		}
+
+		ps = append(ps, p)
+		i += j + 2
+	}
+
+	return ps, true
+}
+
+// marshal appends the plaintext of c to dst, growing it once up front if its
+// capacity is short of the expected length.
+func (c *KeysetToken) marshal(dst []byte) ([]byte, error) {
+	if len(c.upstream) > MaxUpstreamTokenLength {
+		return nil, fmt.Errorf("%w: %d bytes", ErrUpstreamTokenTooLong, len(c.upstream))
+	}
+
+	body := c.v1Body()
+	return appendV1(slices.Grow(dst, v1SizeHint(&body, c.payload.vs)), &body, c.payload.vs)
+}
+
+// v1SizeHint estimates the length of the plaintext of body with the keyset
+// vs, so that appendV1 rarely needs to grow its buffer.
+func v1SizeHint(body *keysetTokenV1Body, vs []KeysetValue) int {
+	// version byte, braces, field names and numbers
+	n := 128
+	for _, v := range vs {
+		// quotes, commas and order
+		n += len(v.Path) + len(v.Value) + 16
+	}
+	if body.Upstream != nil {
+		n += len(*body.Upstream) + 8
+	}
+
+	return n
+}
+
+// appendV1 appends the versioned plaintext of body with the keyset vs to
+// dst. The keyset and the sensitive positions of body are ignored and taken
+// from vs. The output is identical to the JSON encoding of body by
+// encoding/json, but it is written without reflection and without an
+// intermediate string slice.
+func appendV1(dst []byte, body *keysetTokenV1Body, vs []KeysetValue) ([]byte, error) {
+	dst = append(dst, keysetTokenV1)
+
+	dst = append(dst, `{"k":`...)
+	dst = appendKeyset(dst, vs)
+	dst = append(dst, `,"c":`...)
+	dst = strconv.AppendUint(dst, uint64(body.Checksum), 10)
+
+	if body.TotalCount != nil {
+		dst = append(dst, `,"n":`...)
+		dst = strconv.AppendInt(dst, *body.TotalCount, 10)
+	}
+
+	if body.Snapshot != nil {
+		dst = append(dst, `,"s":`...)
+		dst = strconv.AppendInt(dst, *body.Snapshot, 10)
+	}
+
+	if body.Upstream != nil {
+		dst = append(dst, `,"u":`...)
+		dst = appendJSONString(dst, *body.Upstream)
+	}
+
+	if body.Composite != nil {
+		bs, err := json.Marshal(body.Composite)
+		if err != nil {
+			return nil, err
+		}
+		dst = append(dst, `,"p":`...)
+		dst = append(dst, bs...)
+	}
+
+	if body.PageIndex != 0 {
+		dst = append(dst, `,"i":`...)
+		dst = strconv.AppendInt(dst, int64(body.PageIndex), 10)
+	}
+
+	if body.IssuedAt != 0 {
+		dst = append(dst, `,"t":`...)
+		dst = strconv.AppendInt(dst, body.IssuedAt, 10)
+	}
+
+	dst = appendSensitive(dst, vs)
+
+	if body.Groups != nil {
+		bs, err := json.Marshal(body.Groups)
+		if err != nil {
+			return nil, err
+		}
+		dst = append(dst, `,"g":`...)
+		dst = append(dst, bs...)
+	}
+
+	if body.UpperBound != nil {
+		dst = append(dst, `,"b":`...)
+		bs, err := json.Marshal(body.UpperBound)
+		if err != nil {
+			return nil, err
+		}
+		dst = append(dst, bs...)
+	}
+
+	if body.Scope != "" {
+		dst = append(dst, `,"o":`...)
+		dst = appendJSONString(dst, body.Scope)
+	}
+
+	if body.ID != "" {
+		dst = append(dst, `,"j":`...)
+		dst = appendJSONString(dst, body.ID)
+	}
+
+	return append(dst, '}'), nil
+}
+
+// appendSensitive appends the "r" member listing the positions of the
+// sensitive values of vs to dst, if there are any.
+func appendSensitive(dst []byte, vs []KeysetValue) []byte {
+	n := 0
+	for i, v := range vs {
+		if !v.Sensitive {
+			continue
+		}
+
+		if n == 0 {
+			dst = append(dst, `,"r":[`...)
+		} else {
+			dst = append(dst, ',')
+		}
+		dst = strconv.AppendInt(dst, int64(i), 10)
+		n++
+	}
+	if n > 0 {
+		dst = append(dst, ']')
+	}
+
+	return dst
+}
+
+// appendKeyset appends vs as JSON array of path/value/order triples to dst,
+// identical to the JSON encoding of encodeKeysetValues(vs).
+func appendKeyset(dst []byte, vs []KeysetValue) []byte {
+	dst = append(dst, '[')
+	for i, v := range vs {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		dst = appendJSONString(dst, v.Path)
+		dst = append(dst, ',')
+		dst = appendJSONString(dst, v.Value)
+		dst = append(dst, ',')
+		dst = appendJSONString(dst, v.Order.String())
+	}
+	return append(dst, ']')
+}
+
+// appendJSONString appends s as JSON string to dst. Strings of printable
+// ASCII that encoding/json leaves unescaped are copied as they are; all
+// others are encoded by encoding/json to keep its escaping rules.
+func appendJSONString(dst []byte, s string) []byte {
+	for i := 0; i < len(s); i++ {
+		if b := s[i]; b < 0x20 || b >= 0x80 || b == '"' || b == '\\' || b == '<' || b == '>' || b == '&' {
+			// cannot fail for strings
+			bs, _ := json.Marshal(s)
+			return append(dst, bs...)
+		}
+	}
+
+	dst = append(dst, '"')
+	dst = append(dst, s...)
+	return append(dst, '"')
+}
+
+func (c *KeysetToken) issueTime() time.Time {
+	if c.now != nil {
+		return c.now()
+	}
+
+	return time.Now()
+}
+
+// v1Body returns the versioned plaintext body of c without its keyset.
+func (c *KeysetToken) v1Body() keysetTokenV1Body {
+	body := keysetTokenV1Body{
+		Checksum:  c.checksum,
+		PageIndex: c.pageIndex,
+		IssuedAt:  c.issueTime().Unix(),
+		Scope:     c.scope,
+	}
+
+	if c.ids {
+		body.ID = newTokenID()
+	}
+
+	if c.hasTotalCount {
+		n := c.totalCount
+		body.TotalCount = &n
+	}
+
+	if !c.snapshot.IsZero() {
+		s := c.snapshot.UnixNano()
+		body.Snapshot = &s
+	}
+
+	if c.hasUpstream {
+		u := c.upstream
+		body.Upstream = &u
+	}
+
+	body.Composite = encodeComposite(c.composite)
+	body.Groups = encodeGroups(c.groups)
+
+	if c.upperBound != nil {
+		body.UpperBound = encodeKeysetValues(c.upperBound.vs)
+	}
+
+	return body
+}
+
+// unmarshalKeysetToken decodes a token plaintext of any supported version.
+// The returned token has no crypter set.
+func unmarshalKeysetToken(d []byte) (*KeysetToken, error) {
+	if len(d) > 0 && d[0] == keysetTokenV1 {
+		return unmarshalKeysetTokenV1(d[1:])
+	}
+
+	return unmarshalKeysetTokenLegacy(d)
+}
+
+func unmarshalKeysetTokenV1(d []byte) (*KeysetToken, error) {
+	var body keysetTokenV1Body
+	if err := json.Unmarshal(d, &body); err != nil {
+		// values of the wrong type, such as a checksum overflowing uint32,
+		// are malformed rather than undecodable
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return nil, fmt.Errorf("%w: %w", ErrMalformedToken, err)
+		}
+		return nil, err
+	}
+
+	ps, err := decodeKeysetStrings(body.Keyset)
+	if err != nil {
+		return nil, err
+	}
+
+	vs, err := decodeKeysetValues(ps)
+	if err != nil {
+		return nil, err
+	}
+	if err := markSensitive(vs, body.Sensitive); err != nil {
+		return nil, err
+	}
+
+	t := &KeysetToken{
+		checksum: body.Checksum,
+		payload:  &KeysetPayload{vs: vs},
+	}
+
+	if body.TotalCount != nil {
+		t.totalCount = *body.TotalCount
+		t.hasTotalCount = true
+	}
+
+	if body.Snapshot != nil {
+		t.snapshot = time.Unix(0, *body.Snapshot).UTC()
+	}
+	t.scope = body.Scope
+	t.id = body.ID
+
+	if body.Upstream != nil {
+		t.upstream = *body.Upstream
+		t.hasUpstream = true
+	}
+
+	if t.composite, err = decodeComposite(body.Composite); err != nil {
+		return nil, err
+	}
+
+	if t.groups, err = decodeGroups(body.Groups); err != nil {
+		return nil, err
+	}
+
+	if body.UpperBound != nil {
+		bvs, err := decodeKeysetValues(body.UpperBound)
+		if err != nil {
+			return nil, err
+		}
+		t.upperBound = &KeysetPayload{vs: bvs}
+	}
+
+	t.pageIndex = body.PageIndex
+	if body.IssuedAt != 0 {
+		t.issuedAt = time.Unix(body.IssuedAt, 0).UTC()
+	}
+
+	return t, nil
+}
+
+func unmarshalKeysetTokenLegacy(d []byte) (*KeysetToken, error) {
+	var ps []string
+	if err := json.Unmarshal(d, &ps); err != nil {
+		return nil, err
+	}
+
+	if len(ps) == 0 {
+		return nil, fmt.Errorf("%w: missing checksum", ErrMalformedToken)
+	}
+
+	crc, err := strconv.ParseUint(ps[len(ps)-1], 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("%w: checksum: %w", ErrMalformedToken, err)
+	}
+
+	vs, err := decodeKeysetValues(ps[:len(ps)-1])
+	if err != nil {
+		return nil, err
+	}
+
+	return &KeysetToken{
+		checksum: uint32(crc),
+		payload:  &KeysetPayload{vs: vs},
+		legacy:   true,
+	}, nil
+}
@@ -0,0 +1,104 @@
+package pagetoken
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// LinkHeader returns the value of an RFC 8288 (formerly RFC 5988) Link
+// header pointing to the next and previous page:
+//
+//	<https://api.example.com/users?page_token=...&status=active>; rel="next", <...>; rel="prev"
+//
+// The page URLs are copies of baseURL with the serialized token set as the
+// tokenParam query parameter, or DefaultPageTokenParam if tokenParam is
+// empty. All other query parameters of baseURL are kept. A nil token omits
+// its link; if both are nil, the value is empty.
+func LinkHeader(baseURL *url.URL, nextToken, prevToken *KeysetToken, tokenParam string) (string, error) {
+	next, err := tokenString(nextToken)
+	if err != nil {
+		return "", err
+	}
+	prev, err := tokenString(prevToken)
+	if err != nil {
+		return "", err
+	}
+
+	return linkHeader(baseURL, next, prev, tokenParam), nil
+}
+
+// WriteLinkHeader adds the Link header returned by LinkHeader to w. Nothing
+// is added if both tokens are nil. It must be called before the response
+// header is written.
+func WriteLinkHeader(w http.ResponseWriter, baseURL *url.URL, nextToken, prevToken *KeysetToken, tokenParam string) error {
+	v, err := LinkHeader(baseURL, nextToken, prevToken, tokenParam)
+	if err != nil {
+		return err
+	}
+
+	if v != "" {
+		w.Header().Add("Link", v)
+	}
+	return nil
+}
+
+// linkHeader returns the Link header value of LinkHeader for tokens that
+// are already serialized. An empty token omits its link.
+func linkHeader(baseURL *url.URL, next, prev, tokenParam string) string {
+	links := []string{}
+	for _, l := range []struct {
+		rel   string
+		token string
+	}{
+		{"next", next},
+		{"prev", prev},
+	} {
+		if l.token == "" {
+			continue
+		}
+
+		links = append(links, "<"+withPageToken(baseURL, l.token, tokenParam).String()+`>; rel="`+l.rel+`"`)
+	}
+
+	return strings.Join(links, ", ")
+}
+
+// pageURL returns a copy of baseURL with the serialized token set as the
+// tokenParam query parameter. A nil token removes the parameter.
+func pageURL(baseURL *url.URL, token *KeysetToken, tokenParam string) (*url.URL, error) {
+	s, err := tokenString(token)
+	if err != nil {
+		return nil, err
+	}
+
+	return withPageToken(baseURL, s, tokenParam), nil
+}
+
+// withPageToken returns a copy of baseURL with token set as the tokenParam
+// query parameter, or DefaultPageTokenParam if tokenParam is empty. An
+// empty token removes the parameter.
+func withPageToken(baseURL *url.URL, token, tokenParam string) *url.URL {
+	if tokenParam == "" {
+		tokenParam = DefaultPageTokenParam
+	}
+
+	q := baseURL.Query()
+	q.Del(tokenParam)
+	if token != "" {
+		q.Set(tokenParam, token)
+	}
+
+	u := *baseURL
+	u.RawQuery = q.Encode()
+	return &u
+}
+
+// tokenString serializes t, or returns an empty string if t is nil.
+func tokenString(t *KeysetToken) (string, error) {
+	if t == nil {
+		return "", nil
+	}
+
+	return t.String()
+}
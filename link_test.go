@@ -0,0 +1,81 @@
+package pagetoken_test
+
+import (
+	"net/http/httptest"
+	"net/url"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/encryption"
+)
+
+// fixedCrypter encrypts every plaintext to the same token.
+type fixedCrypter string
+
+func (c fixedCrypter) Encrypt([]byte) (string, error) { return string(c), nil }
+func (c fixedCrypter) Decrypt(string) ([]byte, error) { return nil, nil }
+
+var _ = Describe("LinkHeader", func() {
+	var base *url.URL
+
+	BeforeEach(func() {
+		var err error
+		base, err = url.Parse("https://api.example.com/users?status=active&sort=name&page_token=old")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("links next and prev pages with escaped tokens and the other parameters preserved", func() {
+		next := pagetoken.NewKeysetToken(fixedCrypter("bmV4dA=="))
+		prev := pagetoken.NewKeysetToken(fixedCrypter("cHJldg="))
+
+		v, err := pagetoken.LinkHeader(base, next, prev, "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(v).To(Equal(
+			`<https://api.example.com/users?page_token=bmV4dA%3D%3D&sort=name&status=active>; rel="next", ` +
+				`<https://api.example.com/users?page_token=cHJldg%3D&sort=name&status=active>; rel="prev"`,
+		))
+		Expect(base.RawQuery).To(Equal("status=active&sort=name&page_token=old"))
+	})
+
+	It("omits absent links and uses the given parameter", func() {
+		v, err := pagetoken.LinkHeader(base, nil, pagetoken.NewKeysetToken(fixedCrypter("cHJldg=")), "cursor")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(v).To(Equal(`<https://api.example.com/users?cursor=cHJldg%3D&page_token=old&sort=name&status=active>; rel="prev"`))
+
+		v, err = pagetoken.LinkHeader(base, nil, nil, "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(v).To(BeEmpty())
+	})
+
+	It("round-trips tokens through the link URL", func() {
+		key, err := encryption.Rand32ByteKey()
+		Expect(err).NotTo(HaveOccurred())
+		e, err := encryption.NewAEADEncryptor(key)
+		Expect(err).NotTo(HaveOccurred())
+		rr := pagetoken.NewRequestReader(pagetoken.WithEncryptor(e))
+
+		t, err := rr.Read(filterRequest{status: "active"})
+		Expect(err).NotTo(HaveOccurred())
+
+		v, err := pagetoken.LinkHeader(base, t.Next(), nil, "")
+		Expect(err).NotTo(HaveOccurred())
+
+		u, err := url.Parse(v[1 : len(v)-len(`>; rel="next"`)])
+		Expect(err).NotTo(HaveOccurred())
+		_, err = rr.Read(filterRequest{status: "active", token: u.Query().Get("page_token")})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	Describe("WriteLinkHeader", func() {
+		It("adds the header only if there are links", func() {
+			w := httptest.NewRecorder()
+			Expect(pagetoken.WriteLinkHeader(w, base, nil, nil, "")).To(Succeed())
+			Expect(w.Header()).NotTo(HaveKey("Link"))
+
+			Expect(pagetoken.WriteLinkHeader(w, base, pagetoken.NewKeysetToken(fixedCrypter("abc")), nil, "")).To(Succeed())
+			Expect(w.Header().Get("Link")).To(Equal(`<https://api.example.com/users?page_token=abc&sort=name&status=active>; rel="next"`))
+		})
+	})
+})
@@ -0,0 +1,41 @@
+package pagetoken
+
+import (
+	"fmt"
+	"log/slog"
+)
+
+// LogValue implements slog.LogValuer. It logs the values of p as a group of
+// their paths, with sensitive values replaced by RedactSensitive.
+func (p *KeysetPayload) LogValue() slog.Value {
+	if p == nil {
+		return slog.GroupValue()
+	}
+
+	attrs := make([]slog.Attr, len(p.vs))
+	for i, v := range p.vs {
+		value := v.Value
+		if v.Sensitive {
+			value = RedactSensitive(value)
+		}
+		attrs[i] = slog.String(v.Path, value)
+	}
+
+	return slog.GroupValue(attrs...)
+}
+
+// LogValue implements slog.LogValuer. It logs the checksum, the page index,
+// the total count if one was recorded and the keyset of c as logged by
+// KeysetPayload.LogValue. The upstream token is never logged.
+func (c *KeysetToken) LogValue() slog.Value {
+	attrs := []slog.Attr{
+		slog.String("checksum", fmt.Sprintf("0x%08x", c.checksum)),
+		slog.Int("page_index", c.pageIndex),
+	}
+	if c.hasTotalCount {
+		attrs = append(attrs, slog.Int64("total_count", c.totalCount))
+	}
+	attrs = append(attrs, slog.Any("keyset", c.payload))
+
+	return slog.GroupValue(attrs...)
+}
@@ -0,0 +1,159 @@
+package pagetoken
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// DefaultMaxTokenLength is the longest page token Middleware accepts unless
+// configured otherwise. It fits tokens holding one keyset of a few short
+// columns, but not every token the package can issue: upstream tokens, see
+// SetUpstreamToken, composite payloads, see WithCompositePayload, and named
+// keysets, see WithKeysetPayloads, grow a token by about 4/3 of their size,
+// and a single upstream token may already exceed the default. Services
+// issuing such tokens must raise the limit with WithMaxTokenLength, and
+// likewise in WithIntrospectionMaxTokenLength, OpenAPIConfig.MaxTokenLength
+// and WithSetupMaxTokenLength; ValidateSetup reports keysets that do not
+// fit.
+const DefaultMaxTokenLength = 512
+
+type middleware struct {
+	reader       *RequestReader
+	tokenParam   string
+	maxLength    int
+	errorHandler func(http.ResponseWriter, *http.Request, error)
+}
+
+type MiddlewareOpt func(*middleware)
+
+// WithTokenParam sets the query parameter Middleware reads the page token
+// from. The default is DefaultPageTokenParam.
+func WithTokenParam(name string) MiddlewareOpt {
+	return func(m *middleware) {
+		m.tokenParam = name
+	}
+}
+
+// WithMaxTokenLength sets the longest page token Middleware accepts. The
+// default is DefaultMaxTokenLength, which is too low for tokens carrying
+// upstream tokens, composite payloads or named keysets.
+func WithMaxTokenLength(n int) MiddlewareOpt {
+	return func(m *middleware) {
+		m.maxLength = n
+	}
+}
+
+// WithErrorHandler sets the function Middleware responds to rejected page
+// tokens with. The default responds with 400 Bad Request and a plain text
+// body.
+func WithErrorHandler(fn func(w http.ResponseWriter, r *http.Request, err error)) MiddlewareOpt {
+	return func(m *middleware) {
+		m.errorHandler = fn
+	}
+}
+
+// Middleware returns a net/http middleware, e.g. for chi's Router.Use, that
+// rejects obviously bogus page tokens before the handler runs: tokens longer
+// than the maximum length, tokens with characters outside of the base64
+// alphabets and the dot of signed tokens, and tokens that cannot be
+// decrypted or decoded. All of them are reported as ErrInvalidToken.
+//
+// Accepted tokens are stored in the request context. The checksum is not
+// verified, as that needs the parsed request; handlers do so with
+// RequestReader.ReadContext, which reuses the stored token:
+//
+//	r.Use(pagetoken.Middleware(reader))
+//	r.Get("/users", func(w http.ResponseWriter, r *http.Request) {
+//	    t, err := reader.ReadContext(r.Context(), pagetoken.HTTPRequest(r, "status"))
+//	    ...
+//	})
+//
+// Requests without a page token are passed through unchanged.
+func Middleware(reader *RequestReader, opts ...MiddlewareOpt) func(http.Handler) http.Handler {
+	m := &middleware{
+		reader:       reader,
+		tokenParam:   DefaultPageTokenParam,
+		maxLength:    DefaultMaxTokenLength,
+		errorHandler: rejectToken,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			s := r.URL.Query().Get(m.tokenParam)
+			if s == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			t, err := m.check(s)
+			if err != nil {
+				m.errorHandler(w, r, err)
+				return
+			}
+			if t == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), t)))
+		})
+	}
+}
+
+func (m *middleware) check(s string) (*KeysetToken, error) {
+	if len(s) > m.maxLength {
+		return nil, fmt.Errorf("%w: longer than %d bytes", ErrInvalidToken, m.maxLength)
+	}
+
+	// the tag of WithTokenPrefix is chosen freely, e.g. "pt:", so only the
+	// encrypted part after it is checked
+	start := 0
+	if p := m.reader.prefix; p != "" && strings.HasPrefix(s, p) {
+		start = len(p)
+	}
+	for i := start; i < len(s); i++ {
+		if !isBase64(s[i]) {
+			return nil, fmt.Errorf("%w: invalid character %q at offset %d", ErrInvalidToken, s[i], i)
+		}
+	}
+
+	// tokens bound to a subject cannot be decrypted without the request
+	if m.reader.subject != nil {
+		return nil, nil
+	}
+
+	return m.reader.parse(s, nil)
+}
+
+// isBase64 reports whether c belongs to the URL-safe or the standard base64
+// alphabet, as the AEAD encryptor accepts both, or is the dot separating
+// the payload and signature of encryption.SignerEncryptor tokens.
+func isBase64(c byte) bool {
+	return 'A' <= c && c <= 'Z' ||
+		'a' <= c && c <= 'z' ||
+		'0' <= c && c <= '9' ||
+		c == '-' || c == '_' || c == '+' || c == '/' || c == '=' || c == '.'
+}
+
+func rejectToken(w http.ResponseWriter, _ *http.Request, err error) {
+	http.Error(w, ErrInvalidToken.Error(), HTTPStatus(err))
+}
+
+type tokenContextKey struct{}
+
+// NewContext returns a copy of ctx carrying the page token t.
+func NewContext(ctx context.Context, t *KeysetToken) context.Context {
+	return context.WithValue(ctx, tokenContextKey{}, t)
+}
+
+// FromContext returns the page token stored in ctx by Middleware or
+// NewContext.
+func FromContext(ctx context.Context) (*KeysetToken, bool) {
+	t, ok := ctx.Value(tokenContextKey{}).(*KeysetToken)
+	return t, ok
+}
@@ -0,0 +1,134 @@
+package pagetoken_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/encryption"
+)
+
+var _ = Describe("Middleware", func() {
+	var (
+		rr      *pagetoken.RequestReader
+		handler http.Handler
+		called  int
+		stored  *pagetoken.KeysetToken
+	)
+
+	BeforeEach(func() {
+		key, err := encryption.Rand32ByteKey()
+		Expect(err).NotTo(HaveOccurred())
+		e, err := encryption.NewAEADEncryptor(key)
+		Expect(err).NotTo(HaveOccurred())
+		rr = pagetoken.NewRequestReader(pagetoken.WithEncryptor(e))
+
+		called = 0
+		stored = nil
+		handler = pagetoken.Middleware(rr)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			called++
+			stored, _ = pagetoken.FromContext(r.Context())
+
+			t, err := rr.ReadContext(r.Context(), pagetoken.HTTPRequest(r, "status"))
+			if err != nil {
+				http.Error(w, err.Error(), pagetoken.HTTPStatus(err))
+				return
+			}
+			Expect(t).NotTo(BeNil())
+		}))
+	})
+
+	serve := func(query url.Values) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?"+query.Encode(), nil))
+		return rec
+	}
+
+	issue := func(status string) string {
+		t, err := rr.Read(filterRequest{status: status})
+		Expect(err).NotTo(HaveOccurred())
+		s, err := t.Next().String()
+		Expect(err).NotTo(HaveOccurred())
+		return s
+	}
+
+	It("passes requests without a page token through", func() {
+		Expect(serve(url.Values{"status": {"active"}}).Code).To(Equal(http.StatusOK))
+		Expect(called).To(Equal(1))
+		Expect(stored).To(BeNil())
+	})
+
+	It("stores accepted tokens in the request context", func() {
+		rec := serve(url.Values{"status": {"active"}, "page_token": {issue("active")}})
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(called).To(Equal(1))
+		Expect(stored).NotTo(BeNil())
+	})
+
+	It("leaves checksum validation to the handler", func() {
+		rec := serve(url.Values{"status": {"inactive"}, "page_token": {issue("active")}})
+		Expect(rec.Code).To(Equal(http.StatusBadRequest))
+		Expect(rec.Body.String()).To(ContainSubstring(pagetoken.ErrChecksumMismatch.Error()))
+		Expect(called).To(Equal(1))
+	})
+
+	It("accepts signed tokens", func() {
+		signer, err := encryption.NewSignerEncryptor(encryption.MustRandKey(32))
+		Expect(err).NotTo(HaveOccurred())
+		rr = pagetoken.NewRequestReader(pagetoken.WithEncryptor(signer))
+		s := issue("active")
+		Expect(s).To(ContainSubstring("."))
+
+		var stored bool
+		h := pagetoken.Middleware(rr)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, stored = pagetoken.FromContext(r.Context())
+		}))
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?"+url.Values{"page_token": {s}}.Encode(), nil))
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(stored).To(BeTrue())
+	})
+
+	DescribeTable("rejects bogus tokens without invoking the handler",
+		func(token func() string) {
+			rec := serve(url.Values{"status": {"active"}, "page_token": {token()}})
+			Expect(rec.Code).To(Equal(http.StatusBadRequest))
+			Expect(rec.Body.String()).To(ContainSubstring(pagetoken.ErrInvalidToken.Error()))
+			Expect(called).To(BeZero())
+		},
+		Entry("too long", func() string {
+			return strings.Repeat("A", pagetoken.DefaultMaxTokenLength+1)
+		}),
+		Entry("not base64", func() string {
+			return issue("active") + "*"
+		}),
+		Entry("not decryptable", func() string {
+			s := []byte(issue("active"))
+			s[len(s)/2] ^= 0x01
+			return string(s)
+		}),
+	)
+
+	It("honours the configured parameter, length and error handler", func() {
+		handler = pagetoken.Middleware(rr,
+			pagetoken.WithTokenParam("cursor"),
+			pagetoken.WithMaxTokenLength(8),
+			pagetoken.WithErrorHandler(func(w http.ResponseWriter, _ *http.Request, err error) {
+				w.WriteHeader(http.StatusTeapot)
+			}),
+		)(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+			called++
+		}))
+
+		Expect(serve(url.Values{"page_token": {"not-a-token!"}}).Code).To(Equal(http.StatusOK))
+		Expect(called).To(Equal(1))
+
+		Expect(serve(url.Values{"cursor": {issue("active")}}).Code).To(Equal(http.StatusTeapot))
+		Expect(called).To(Equal(1))
+	})
+})
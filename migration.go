@@ -0,0 +1,88 @@
+package pagetoken
+
+import "github.com/pixlcrashr/go-pagetoken/checksum"
+
+// WithChecksum replaces the request checksum of a token, e.g. of the token
+// returned by Next, with crc. The token then validates for the requests
+// whose checksum fields yield crc rather than for those of the token it was
+// derived from: only stamp a checksum after validating the token against
+// the request it was issued for. See WithRecomputedChecksum for migrating a
+// token to a request whose checksum fields changed.
+func WithChecksum(crc uint32) KeysetTokenOpt {
+	return func(c *KeysetToken) {
+		c.checksum = crc
+	}
+}
+
+// WithRecomputedChecksum returns a copy of c whose checksum is built from
+// the checksum fields of req, followed by opts, e.g. after a filter was
+// renamed:
+//
+//	t, err := oldReader.Read(oldRequest)
+//	...
+//	t, err = t.WithRecomputedChecksum(newRequest)
+//
+// The copy then validates for requests with the checksum fields of req only.
+// As with WithChecksum, c must have been validated against the request it
+// was issued for first. The checksum options of a RequestReader, such as
+// the page size handling of WithAIP158, are not applied; pass them as opts
+// where they matter.
+func (c *KeysetToken) WithRecomputedChecksum(req Request, opts ...checksum.BuilderOpt) (*KeysetToken, error) {
+	cb := checksum.NewBuilder(req.GetChecksumFields()...)
+	for _, opt := range opts {
+		opt(cb)
+	}
+
+	crc, err := cb.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	t := c.Next(WithChecksum(crc))
+	t.pageIndex = c.pageIndex
+	t.pageSize = c.pageSize
+	return t, nil
+}
+
+// LegacyChecksumFn is called for a page token accepted by the legacy
+// checksum of WithLegacyDefaultChecksums.
+type LegacyChecksumFn func(info TokenInfo)
+
+// WithLegacyDefaultChecksums makes the reader also accept page tokens whose
+// checksum includes the fields added by checksum.FieldWithDefault with their
+// default value, as computed by checksum.Builder.BuildWithDefaults. These are
+// the tokens issued while such a field was still added by checksum.Field,
+// before it was changed to checksum.FieldWithDefault.
+//
+// fn, if not nil, is called for every token accepted this way, e.g. to count
+// the legacy tokens still in use and remove the option once there are none
+// left:
+//
+//	pagetoken.WithLegacyDefaultChecksums(func(info pagetoken.TokenInfo) {
+//	    legacyTokens.Inc()
+//	})
+func WithLegacyDefaultChecksums(fn LegacyChecksumFn) RequestReaderOpt {
+	return func(rr *RequestReader) {
+		rr.legacyDefaults = true
+		rr.onLegacyChecksum = fn
+	}
+}
+
+// verifyLegacyDefaults reports whether t matches the checksum of cb with the
+// fields of their default value included, if enabled.
+func (r *RequestReader) verifyLegacyDefaults(t *KeysetToken, cb *checksum.Builder) (bool, error) {
+	if !r.legacyDefaults {
+		return false, nil
+	}
+
+	crc, err := cb.BuildWithDefaults()
+	if err != nil || crc != t.checksum {
+		return false, err
+	}
+
+	if r.onLegacyChecksum != nil {
+		r.onLegacyChecksum(t.Info())
+	}
+
+	return true, nil
+}
@@ -0,0 +1,211 @@
+package pagetoken_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/checksum"
+	"github.com/pixlcrashr/go-pagetoken/encryption"
+)
+
+// regionRequest is a request of a listing that gained an optional region
+// filter defaulting to "eu". fields returns its checksum fields in the
+// deployed version.
+type regionRequest struct {
+	status string
+	region string
+	token  string
+	fields func(r regionRequest) []checksum.BuilderOpt
+}
+
+func (r regionRequest) GetChecksumFields() []checksum.BuilderOpt { return r.fields(r) }
+func (r regionRequest) GetPageToken() string                     { return r.token }
+
+// Deployed versions of the checksum fields of regionRequest.
+var (
+	// beforeRegion did not have the region filter.
+	beforeRegion = func(r regionRequest) []checksum.BuilderOpt {
+		return []checksum.BuilderOpt{checksum.Field("status", r.status)}
+	}
+	// plainRegion added the filter as a plain field.
+	plainRegion = func(r regionRequest) []checksum.BuilderOpt {
+		return []checksum.BuilderOpt{checksum.Field("status", r.status), checksum.Field("region", r.region)}
+	}
+	// defaultRegion added the filter with its default.
+	defaultRegion = func(r regionRequest) []checksum.BuilderOpt {
+		return []checksum.BuilderOpt{checksum.Field("status", r.status), checksum.FieldWithDefault("region", r.region, "eu")}
+	}
+)
+
+var _ = Describe("Checksum fields with defaults", func() {
+	var e encryption.Crypter
+
+	BeforeEach(func() {
+		key, err := encryption.Rand32ByteKey()
+		Expect(err).NotTo(HaveOccurred())
+		e, err = encryption.NewAEADEncryptor(key)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	issue := func(rr *pagetoken.RequestReader, r regionRequest) string {
+		t, err := rr.Read(r)
+		Expect(err).NotTo(HaveOccurred())
+		s, err := t.Next().String()
+		Expect(err).NotTo(HaveOccurred())
+		return s
+	}
+
+	Context("added after tokens were issued", func() {
+		var (
+			rr  *pagetoken.RequestReader
+			old string
+		)
+
+		BeforeEach(func() {
+			rr = pagetoken.NewRequestReader(pagetoken.WithEncryptor(e))
+			old = issue(rr, regionRequest{status: "active", fields: beforeRegion})
+		})
+
+		It("accept old tokens with the default value", func() {
+			_, err := rr.Read(regionRequest{status: "active", region: "eu", token: old, fields: defaultRegion})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("reject old tokens with another value", func() {
+			_, err := rr.Read(regionRequest{status: "active", region: "us", token: old, fields: defaultRegion})
+			Expect(err).To(MatchError(pagetoken.ErrChecksumMismatch))
+		})
+
+		It("bind new tokens to another value", func() {
+			s := issue(rr, regionRequest{status: "active", region: "us", fields: defaultRegion})
+
+			_, err := rr.Read(regionRequest{status: "active", region: "us", token: s, fields: defaultRegion})
+			Expect(err).NotTo(HaveOccurred())
+			_, err = rr.Read(regionRequest{status: "active", region: "eu", token: s, fields: defaultRegion})
+			Expect(err).To(MatchError(pagetoken.ErrChecksumMismatch))
+		})
+	})
+
+	Context("replacing a plain field", func() {
+		var (
+			legacy []pagetoken.TokenInfo
+			rr     *pagetoken.RequestReader
+		)
+
+		BeforeEach(func() {
+			legacy = nil
+			rr = pagetoken.NewRequestReader(
+				pagetoken.WithEncryptor(e),
+				pagetoken.WithLegacyDefaultChecksums(func(info pagetoken.TokenInfo) {
+					legacy = append(legacy, info)
+				}),
+			)
+		})
+
+		It("rejects tokens with the default value without the legacy checksum", func() {
+			s := issue(rr, regionRequest{status: "active", region: "eu", fields: plainRegion})
+
+			_, err := pagetoken.NewRequestReader(pagetoken.WithEncryptor(e)).
+				Read(regionRequest{status: "active", region: "eu", token: s, fields: defaultRegion})
+			Expect(err).To(MatchError(pagetoken.ErrChecksumMismatch))
+		})
+
+		It("accepts tokens with the default value by the legacy checksum", func() {
+			s := issue(rr, regionRequest{status: "active", region: "eu", fields: plainRegion})
+
+			t, err := rr.Read(regionRequest{status: "active", region: "eu", token: s, fields: defaultRegion})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(legacy).To(ConsistOf(t.Info()))
+		})
+
+		It("accepts tokens with another value without the legacy checksum", func() {
+			s := issue(rr, regionRequest{status: "active", region: "us", fields: plainRegion})
+
+			_, err := rr.Read(regionRequest{status: "active", region: "us", token: s, fields: defaultRegion})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(legacy).To(BeEmpty())
+		})
+
+		It("accepts new tokens without the legacy checksum", func() {
+			s := issue(rr, regionRequest{status: "active", region: "eu", fields: defaultRegion})
+
+			_, err := rr.Read(regionRequest{status: "active", region: "eu", token: s, fields: defaultRegion})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(legacy).To(BeEmpty())
+		})
+
+		It("still rejects tokens of other parameters", func() {
+			s := issue(rr, regionRequest{status: "active", region: "eu", fields: plainRegion})
+
+			_, err := rr.Read(regionRequest{status: "inactive", region: "eu", token: s, fields: defaultRegion})
+			Expect(err).To(MatchError(pagetoken.ErrChecksumMismatch))
+			Expect(legacy).To(BeEmpty())
+		})
+	})
+})
+
+var _ = Describe("Re-stamped checksums", func() {
+	var rr *pagetoken.RequestReader
+
+	BeforeEach(func() {
+		key, err := encryption.Rand32ByteKey()
+		Expect(err).NotTo(HaveOccurred())
+		e, err := encryption.NewAEADEncryptor(key)
+		Expect(err).NotTo(HaveOccurred())
+		rr = pagetoken.NewRequestReader(pagetoken.WithEncryptor(e))
+	})
+
+	// renamed is the request of the listing after its status filter was
+	// renamed to state.
+	renamed := func(state, token string) fieldsRequest {
+		return fieldsRequest{fields: []checksum.BuilderOpt{checksum.Field("state", state)}, token: token}
+	}
+
+	str := func(t *pagetoken.KeysetToken) string {
+		s, err := t.String()
+		Expect(err).NotTo(HaveOccurred())
+		return s
+	}
+
+	// old returns a token of the second page, validated against the request
+	// shape it was issued for.
+	old := func() *pagetoken.KeysetToken {
+		t, err := rr.Read(filterRequest{status: "active"})
+		Expect(err).NotTo(HaveOccurred())
+		t, err = rr.Read(filterRequest{status: "active", token: str(t.Next())})
+		Expect(err).NotTo(HaveOccurred())
+		return t
+	}
+
+	It("migrates a token to the new request shape only", func() {
+		_, err := rr.Read(renamed("active", str(old())))
+		Expect(err).To(MatchError(pagetoken.ErrChecksumMismatch))
+
+		migrated, err := old().WithRecomputedChecksum(renamed("active", ""))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(migrated.PageIndex()).To(Equal(1))
+
+		t, err := rr.Read(renamed("active", str(migrated)))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(t.PageIndex()).To(Equal(1))
+
+		_, err = rr.Read(renamed("inactive", str(migrated)))
+		Expect(err).To(MatchError(pagetoken.ErrChecksumMismatch))
+		_, err = rr.Read(filterRequest{status: "active", token: str(migrated)})
+		Expect(err).To(MatchError(pagetoken.ErrChecksumMismatch))
+	})
+
+	It("stamps the checksum given to Next", func() {
+		first, err := rr.Read(renamed("active", ""))
+		Expect(err).NotTo(HaveOccurred())
+
+		t := old().Next(pagetoken.WithChecksum(first.Checksum()))
+		Expect(t.PageIndex()).To(Equal(2))
+
+		_, err = rr.Read(renamed("active", str(t)))
+		Expect(err).NotTo(HaveOccurred())
+		_, err = rr.Read(filterRequest{status: "active", token: str(t)})
+		Expect(err).To(MatchError(pagetoken.ErrChecksumMismatch))
+	})
+})
@@ -0,0 +1,88 @@
+package pagetoken
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pixlcrashr/go-pagetoken/encryption"
+)
+
+// NextToken returns the token string of the page after current, i.e. the
+// token returned by Read, positioned after the keyset payload. It returns
+// an empty string if payload is nil, i.e. if current is the last page, so
+// handlers can pass on the next keyset of their query as is:
+//
+//	t, err := reader.Read(req)
+//	...
+//	items, next, err := list(ctx, t.Payload())
+//	...
+//	resp.NextPageToken, err = reader.NextToken(t, next)
+//
+// The token is issued under the policy of the reader rather than of
+// current: it is encrypted with the reader's crypter, tagged with its
+// prefix, see WithTokenPrefix, and payload must match its schema, see
+// WithPayloadSchema. The page index is incremented and all other state of
+// current, such as its total count, is carried over as by
+// KeysetToken.Next. For readers with a subject, see WithSubject, the token
+// stays bound to the subject of the request current was read for.
+func (r *RequestReader) NextToken(current *KeysetToken, payload *KeysetPayload) (string, error) {
+	return r.NextTokenContext(context.Background(), current, payload)
+}
+
+// NextTokenContext is like NextToken, but passes ctx to the audit sink, see
+// WithAuditSink.
+func (r *RequestReader) NextTokenContext(ctx context.Context, current *KeysetToken, payload *KeysetPayload) (string, error) {
+	r.assertFrozen()
+
+	// tokens bound to a subject keep the crypter of the request they
+	// were read for
+	e := r.e
+	if r.subject != nil {
+		e = current.e
+	}
+
+	return r.nextToken(ctx, current, payload, current.checksum, current.scope, e)
+}
+
+// NextTokenFor is like NextToken, but computes the checksum and scope of the
+// token from req instead of carrying over those of current, e.g. for tokens not
+// returned by Read for req, such as those restored from a cache.
+func (r *RequestReader) NextTokenFor(req Request, current *KeysetToken, payload *KeysetPayload) (string, error) {
+	r.assertFrozen()
+
+	crc, err := r.checksum(req)
+	if err != nil {
+		return "", err
+	}
+
+	e, err := r.crypter(req)
+	if err != nil {
+		return "", err
+	}
+
+	return r.nextToken(context.Background(), current, payload, crc, scopeOf(req), e)
+}
+
+func (r *RequestReader) nextToken(ctx context.Context, current *KeysetToken, payload *KeysetPayload, crc uint32, scope string, e encryption.Crypter) (string, error) {
+	if payload == nil {
+		return "", nil
+	}
+
+	if r.payloadSchema != nil {
+		if err := r.payloadSchema.Validate(payload); err != nil {
+			return "", fmt.Errorf("next page token: %w", err)
+		}
+	}
+
+	t := current.Next(WithKeysetPayload(payload))
+	t.checksum = crc
+	t.scope = scope
+	t.ids = r.tokenIDs
+	if r.now != nil {
+		t.now = r.now
+	}
+	t.e = e
+	t.prefix = r.prefix
+
+	return r.issue(ctx, t)
+}
@@ -0,0 +1,131 @@
+package pagetoken_test
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/encryption"
+	"github.com/pixlcrashr/go-pagetoken/order"
+	"github.com/pixlcrashr/go-pagetoken/pagetokentest"
+)
+
+var _ = Describe("NextToken", func() {
+	var e encryption.Crypter
+
+	BeforeEach(func() {
+		key, err := encryption.Rand32ByteKey()
+		Expect(err).NotTo(HaveOccurred())
+		e, err = encryption.NewAEADEncryptor(key)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	payload := func(id int) *pagetoken.KeysetPayload {
+		return pagetoken.NewKeysetPayloadBuilder().AddInt("id", id, order.Asc).Build()
+	}
+
+	It("issues the token of the next page", func() {
+		rr := pagetoken.NewRequestReader(pagetoken.WithEncryptor(e))
+		t, err := rr.Read(filterRequest{status: "active"})
+		Expect(err).NotTo(HaveOccurred())
+		t.SetTotalCount(10)
+
+		s, err := rr.NextToken(t, payload(7))
+		Expect(err).NotTo(HaveOccurred())
+
+		next, err := rr.Read(filterRequest{status: "active", token: s})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(next.PageIndex()).To(Equal(1))
+		Expect(next.Payload().Values()).To(Equal(payload(7).Values()))
+		n, ok := next.TotalCount()
+		Expect(ok).To(BeTrue())
+		Expect(n).To(BeNumerically("==", 10))
+
+		s, err = rr.NextToken(next, payload(14))
+		Expect(err).NotTo(HaveOccurred())
+		next, err = rr.Read(filterRequest{status: "active", token: s})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(next.PageIndex()).To(Equal(2))
+	})
+
+	It("returns no token after the last page", func() {
+		rr := pagetoken.NewRequestReader(pagetoken.WithEncryptor(e))
+		t, err := rr.Read(filterRequest{status: "active"})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(rr.NextToken(t, nil)).To(BeEmpty())
+	})
+
+	It("issues tokens with the crypter and prefix of the reader", func() {
+		rr := pagetoken.NewRequestReader(pagetoken.WithEncryptor(e), pagetoken.WithTokenPrefix(pagetoken.DefaultTokenPrefix))
+		t := pagetoken.NewKeysetToken(pagetokentest.StaticCrypter{})
+
+		s, err := rr.NextToken(t, payload(7))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s).To(HavePrefix(pagetoken.DefaultTokenPrefix))
+
+		_, err = e.Decrypt(strings.TrimPrefix(s, pagetoken.DefaultTokenPrefix))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("rejects payloads not matching the schema of the reader", func() {
+		rr := pagetoken.NewRequestReader(
+			pagetoken.WithEncryptor(e),
+			pagetoken.WithPayloadSchema(pagetoken.NewPayloadSchema().Int("id", order.Asc)),
+		)
+		t, err := rr.Read(filterRequest{status: "active"})
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = rr.NextToken(t, payload(7))
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = rr.NextToken(t, pagetoken.NewKeysetPayloadBuilder().AddInt("id", 7, order.Desc).Build())
+		Expect(err).To(MatchError(pagetoken.ErrKeysetFieldOrder))
+	})
+
+	It("keeps the checksum of a token re-issued for a changed request", func() {
+		rr := pagetoken.NewRequestReader(pagetoken.WithEncryptor(e), pagetoken.WithSoftChecksum())
+		first, err := rr.Read(filterRequest{status: "active"})
+		Expect(err).NotTo(HaveOccurred())
+		s, err := rr.NextToken(first, payload(7))
+		Expect(err).NotTo(HaveOccurred())
+
+		restarted, err := rr.Read(filterRequest{status: "inactive", token: s})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(restarted.ChecksumMismatched()).To(BeTrue())
+
+		s, err = rr.NextToken(restarted, payload(3))
+		Expect(err).NotTo(HaveOccurred())
+		next, err := rr.Read(filterRequest{status: "inactive", token: s})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(next.ChecksumMismatched()).To(BeFalse())
+		Expect(next.PageIndex()).To(Equal(1))
+	})
+
+	Describe("NextTokenFor", func() {
+		It("computes the checksum from the request", func() {
+			rr := pagetoken.NewRequestReader(pagetoken.WithEncryptor(e))
+			t, err := rr.Read(filterRequest{status: "active"})
+			Expect(err).NotTo(HaveOccurred())
+
+			s, err := rr.NextTokenFor(filterRequest{status: "inactive"}, t, payload(7))
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = rr.Read(filterRequest{status: "inactive", token: s})
+			Expect(err).NotTo(HaveOccurred())
+			_, err = rr.Read(filterRequest{status: "active", token: s})
+			Expect(err).To(MatchError(pagetoken.ErrChecksumMismatch))
+		})
+
+		It("applies the required checksum fields of the reader", func() {
+			rr := pagetoken.NewRequestReader(pagetoken.WithEncryptor(e), pagetoken.WithRequireChecksumFields())
+			t, err := rr.Read(filterRequest{status: "active"})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = rr.NextTokenFor(fieldsRequest{}, t, payload(7))
+			Expect(err).To(MatchError(pagetoken.ErrNoChecksumFields))
+		})
+	})
+})
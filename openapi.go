@@ -0,0 +1,134 @@
+package pagetoken
+
+// Defaults of OpenAPIConfig.
+const (
+	DefaultPageSizeParam     = "page_size"
+	DefaultNextPageTokenName = "next_page_token"
+	DefaultMinPageSize       = 1
+	DefaultMaxPageSize       = 100
+	DefaultPageSize          = 20
+)
+
+// OpenAPIConfig configures the OpenAPI definitions returned by
+// OpenAPIParams and OpenAPIResponseProperties. Zero values are replaced by
+// the package defaults.
+type OpenAPIConfig struct {
+	// TokenParam is the name of the page token query parameter. The default
+	// is DefaultPageTokenParam.
+	TokenParam string
+	// SizeParam is the name of the page size query parameter. The default is
+	// DefaultPageSizeParam.
+	SizeParam string
+	// NextTokenName is the name of the response property holding the next
+	// page token. The default is DefaultNextPageTokenName.
+	NextTokenName string
+	// MaxTokenLength is the longest page token accepted. The default is
+	// DefaultMaxTokenLength, the limit of Middleware.
+	MaxTokenLength int
+	// MinPageSize, MaxPageSize and DefaultPageSize bound the page size. The
+	// defaults are DefaultMinPageSize, DefaultMaxPageSize and
+	// DefaultPageSize.
+	MinPageSize     int
+	MaxPageSize     int
+	DefaultPageSize int
+}
+
+func (c OpenAPIConfig) withDefaults() OpenAPIConfig {
+	if c.TokenParam == "" {
+		c.TokenParam = DefaultPageTokenParam
+	}
+	if c.SizeParam == "" {
+		c.SizeParam = DefaultPageSizeParam
+	}
+	if c.NextTokenName == "" {
+		c.NextTokenName = DefaultNextPageTokenName
+	}
+	if c.MaxTokenLength == 0 {
+		c.MaxTokenLength = DefaultMaxTokenLength
+	}
+	if c.MinPageSize == 0 {
+		c.MinPageSize = DefaultMinPageSize
+	}
+	if c.MaxPageSize == 0 {
+		c.MaxPageSize = DefaultMaxPageSize
+	}
+	if c.DefaultPageSize == 0 {
+		c.DefaultPageSize = DefaultPageSize
+	}
+	return c
+}
+
+// OpenAPISchema is an OpenAPI 3 schema object, limited to the keywords used
+// by this package. It marshals to JSON accepted by kin-openapi's
+// openapi3.Schema and Huma's huma.Schema.
+type OpenAPISchema struct {
+	Type        string `json:"type"`
+	Format      string `json:"format,omitempty"`
+	Description string `json:"description,omitempty"`
+	MaxLength   *int   `json:"maxLength,omitempty"`
+	Minimum     *int   `json:"minimum,omitempty"`
+	Maximum     *int   `json:"maximum,omitempty"`
+	Default     any    `json:"default,omitempty"`
+}
+
+// OpenAPIParameter is an OpenAPI 3 parameter object. It marshals to JSON
+// accepted by kin-openapi's openapi3.Parameter and Huma's huma.Param.
+type OpenAPIParameter struct {
+	Name        string         `json:"name"`
+	In          string         `json:"in"`
+	Description string         `json:"description,omitempty"`
+	Required    bool           `json:"required,omitempty"`
+	Schema      *OpenAPISchema `json:"schema"`
+}
+
+func intPtr(n int) *int {
+	return &n
+}
+
+// OpenAPIParams returns the query parameters of a paginated listing: the
+// page token, limited to the maximum token length, and the page size,
+// bounded by the configured minimum and maximum.
+func OpenAPIParams(cfg OpenAPIConfig) []OpenAPIParameter {
+	cfg = cfg.withDefaults()
+
+	return []OpenAPIParameter{
+		{
+			Name:        cfg.TokenParam,
+			In:          "query",
+			Description: "Opaque token of the page to return, as returned in " + cfg.NextTokenName + " of the previous page. Omit it for the first page.",
+			Schema:      openAPITokenSchema(cfg),
+		},
+		{
+			Name:        cfg.SizeParam,
+			In:          "query",
+			Description: "Maximum number of items to return.",
+			Schema: &OpenAPISchema{
+				Type:    "integer",
+				Format:  "int32",
+				Minimum: intPtr(cfg.MinPageSize),
+				Maximum: intPtr(cfg.MaxPageSize),
+				Default: cfg.DefaultPageSize,
+			},
+		},
+	}
+}
+
+// OpenAPIResponseProperties returns the properties to add to the schema of
+// a paginated response: the next page token.
+func OpenAPIResponseProperties(cfg OpenAPIConfig) map[string]*OpenAPISchema {
+	cfg = cfg.withDefaults()
+
+	s := openAPITokenSchema(cfg)
+	s.Description = "Token of the next page, to be passed as " + cfg.TokenParam + ". Empty on the last page."
+	return map[string]*OpenAPISchema{
+		cfg.NextTokenName: s,
+	}
+}
+
+func openAPITokenSchema(cfg OpenAPIConfig) *OpenAPISchema {
+	return &OpenAPISchema{
+		Type:      "string",
+		Format:    "pagetoken",
+		MaxLength: intPtr(cfg.MaxTokenLength),
+	}
+}
@@ -1,6 +1,9 @@
 package order
 
-import "fmt"
+import (
+	"database/sql/driver"
+	"fmt"
+)
 
 type Order bool
 
@@ -29,3 +32,34 @@ func (o *Order) UnmarshalString(s string) error {
 
 	return nil
 }
+
+// MarshalText implements encoding.TextMarshaler, emitting "asc" or "desc",
+// so that orders marshal to JSON and similar formats by name instead of as
+// booleans.
+func (o Order) MarshalText() ([]byte, error) {
+	return []byte(o.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, accepting "asc" and
+// "desc".
+func (o *Order) UnmarshalText(text []byte) error {
+	return o.UnmarshalString(string(text))
+}
+
+// Value implements driver.Valuer, storing the order as "asc" or "desc" in a
+// text column.
+func (o Order) Value() (driver.Value, error) {
+	return o.String(), nil
+}
+
+// Scan implements sql.Scanner for text columns holding "asc" or "desc".
+func (o *Order) Scan(src any) error {
+	switch v := src.(type) {
+	case string:
+		return o.UnmarshalString(v)
+	case []byte:
+		return o.UnmarshalString(string(v))
+	default:
+		return fmt.Errorf("cannot scan %T into order", src)
+	}
+}
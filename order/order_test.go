@@ -0,0 +1,74 @@
+package order_test
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/pixlcrashr/go-pagetoken/order"
+)
+
+var _ = Describe("Order encodings", func() {
+	It("round-trips through JSON by name", func() {
+		type preference struct {
+			Order order.Order `json:"order"`
+		}
+
+		for _, o := range []order.Order{order.Asc, order.Desc} {
+			bs, err := json.Marshal(preference{Order: o})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(bs)).To(Equal(`{"order":"` + o.String() + `"}`))
+
+			var p preference
+			Expect(json.Unmarshal(bs, &p)).To(Succeed())
+			Expect(p.Order).To(Equal(o))
+		}
+	})
+
+	It("round-trips through JSON as map values and keys", func() {
+		bs, err := json.Marshal(map[order.Order]order.Order{order.Desc: order.Asc})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(bs)).To(Equal(`{"desc":"asc"}`))
+	})
+
+	It("rejects unknown names", func() {
+		var o order.Order
+		Expect(json.Unmarshal([]byte(`"up"`), &o)).NotTo(Succeed())
+		Expect(json.Unmarshal([]byte(`true`), &o)).NotTo(Succeed())
+	})
+
+	It("round-trips through a sqlite text column", func() {
+		db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard})
+		Expect(err).NotTo(HaveOccurred())
+		sqlDB, err := db.DB()
+		Expect(err).NotTo(HaveOccurred())
+		sqlDB.SetMaxOpenConns(1)
+
+		_, err = sqlDB.Exec("CREATE TABLE preferences (id INTEGER PRIMARY KEY, sort_order TEXT NOT NULL)")
+		Expect(err).NotTo(HaveOccurred())
+
+		for i, o := range []order.Order{order.Asc, order.Desc} {
+			_, err = sqlDB.Exec("INSERT INTO preferences (id, sort_order) VALUES (?, ?)", i, o)
+			Expect(err).NotTo(HaveOccurred())
+
+			var stored string
+			Expect(sqlDB.QueryRow("SELECT sort_order FROM preferences WHERE id = ?", i).Scan(&stored)).To(Succeed())
+			Expect(stored).To(Equal(o.String()))
+
+			var scanned order.Order
+			Expect(sqlDB.QueryRow("SELECT sort_order FROM preferences WHERE id = ?", i).Scan(&scanned)).To(Succeed())
+			Expect(scanned).To(Equal(o))
+		}
+	})
+
+	It("fails to scan other values", func() {
+		var o order.Order
+		Expect(o.Scan("sideways")).NotTo(Succeed())
+		Expect(o.Scan(int64(1))).NotTo(Succeed())
+		Expect(o.Scan(nil)).NotTo(Succeed())
+	})
+})
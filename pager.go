@@ -0,0 +1,139 @@
+package pagetoken
+
+import (
+	"context"
+	"iter"
+)
+
+// PagerFetchFn loads up to limit items of a source sorted by its keyset,
+// starting after the item with the keyset after, or with the first item if
+// after is nil.
+type PagerFetchFn[T any] func(ctx context.Context, after *KeysetPayload, limit int) ([]T, error)
+
+// PagerCursorFn returns the keyset of item, i.e. its values of the columns
+// the source is sorted by.
+type PagerCursorFn[T any] func(item T) *KeysetPayload
+
+// Pager pages through sources other than SQL databases, e.g. in-memory
+// slices, key-value stores or external APIs, by keyset. Fetch loads one
+// more item than requested, so the Pager knows whether there is a next page
+// without loading it.
+//
+// Example:
+//
+//	p := pagetoken.Pager[Book]{
+//		Fetch: func(ctx context.Context, after *pagetoken.KeysetPayload, limit int) ([]Book, error) {
+//			id := int64(0)
+//			if after != nil {
+//				id, _, _ = after.Int64("id")
+//			}
+//			return store.BooksAfter(ctx, id, limit)
+//		},
+//		Cursor: func(b Book) *pagetoken.KeysetPayload {
+//			return pagetoken.NewKeysetPayloadBuilder().AddInt64("id", b.ID, order.Asc).Build()
+//		},
+//	}
+//
+//	t, err := reader.Read(req)
+//	...
+//	books, next, err := p.Page(ctx, t, 0)
+type Pager[T any] struct {
+	// Fetch loads the items of a page.
+	Fetch PagerFetchFn[T]
+	// Cursor builds the keyset of the last item of a page.
+	Cursor PagerCursorFn[T]
+}
+
+// Page loads the page token points to, usually a token returned by
+// RequestReader.Read, and returns its items together with the token of the
+// next page, which is nil once the last page has been loaded. If pageSize is
+// not positive, the page size of token is used, or DefaultPageSize if it has
+// none.
+//
+// Unlike in All, token must not be nil: the next token is derived from it,
+// inheriting its crypter and request checksum, which a nil token lacks. For
+// the first page, pass the fresh token RequestReader.Read returns for
+// requests without a page token.
+func (p Pager[T]) Page(ctx context.Context, token *KeysetToken, pageSize int) ([]T, *KeysetToken, error) {
+	if pageSize <= 0 {
+		pageSize = token.PageSize()
+	}
+
+	items, next, err := p.page(ctx, token.Payload(), pageSize)
+	if err != nil || next == nil {
+		return items, nil, err
+	}
+
+	return items, token.Next(WithKeysetPayload(next)), nil
+}
+
+// All returns an iterator over the items of the page token points to and of
+// all pages after it, loaded lazily in pages of pageSize items as the
+// iteration proceeds. A nil token starts with the first item; pageSize
+// defaults as in Page.
+//
+// If Fetch fails or ctx is done before a page is loaded, the error is
+// yielded with the zero value of T as the last element.
+func (p Pager[T]) All(ctx context.Context, token *KeysetToken, pageSize int) iter.Seq2[T, error] {
+	var after *KeysetPayload
+	if token != nil {
+		after = token.Payload()
+		if pageSize <= 0 {
+			pageSize = token.PageSize()
+		}
+	}
+
+	return func(yield func(T, error) bool) {
+		after := after
+		for {
+			if err := ctx.Err(); err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+
+			items, next, err := p.page(ctx, after, pageSize)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+
+			if next == nil {
+				return
+			}
+			after = next
+		}
+	}
+}
+
+// page loads up to limit items after the keyset after, or DefaultPageSize
+// items if limit is not positive, and returns them together with the
+// keyset of the next page, which is nil on the last page. The empty keyset
+// of the first page's token is passed to Fetch as nil.
+func (p Pager[T]) page(ctx context.Context, after *KeysetPayload, limit int) ([]T, *KeysetPayload, error) {
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+	if after != nil && len(after.Values()) == 0 {
+		after = nil
+	}
+
+	items, err := p.Fetch(ctx, after, limit+1)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(items) <= limit {
+		return items, nil, nil
+	}
+	items = items[:limit]
+
+	return items, p.Cursor(items[limit-1]), nil
+}
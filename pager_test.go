@@ -0,0 +1,172 @@
+package pagetoken_test
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/encryption"
+	"github.com/pixlcrashr/go-pagetoken/order"
+)
+
+type pagerItem struct {
+	ID int
+}
+
+var _ = Describe("Pager", func() {
+	var (
+		rr      *pagetoken.RequestReader
+		source  []pagerItem
+		fetches int
+		pager   pagetoken.Pager[pagerItem]
+	)
+
+	BeforeEach(func() {
+		key, err := encryption.Rand32ByteKey()
+		Expect(err).NotTo(HaveOccurred())
+		e, err := encryption.NewAEADEncryptor(key)
+		Expect(err).NotTo(HaveOccurred())
+		rr = pagetoken.NewRequestReader(pagetoken.WithEncryptor(e))
+
+		source = []pagerItem{}
+		for id := 1; id <= 7; id++ {
+			source = append(source, pagerItem{ID: id})
+		}
+
+		fetches = 0
+		pager = pagetoken.Pager[pagerItem]{
+			Fetch: func(ctx context.Context, after *pagetoken.KeysetPayload, limit int) ([]pagerItem, error) {
+				fetches++
+				i := 0
+				if after != nil {
+					id, _, err := after.Int("id")
+					if err != nil {
+						return nil, err
+					}
+					i = sort.Search(len(source), func(i int) bool { return source[i].ID > id })
+				}
+				return source[i:min(i+limit, len(source))], nil
+			},
+			Cursor: func(item pagerItem) *pagetoken.KeysetPayload {
+				return pagetoken.NewKeysetPayloadBuilder().AddInt("id", item.ID, order.Asc).Build()
+			},
+		}
+	})
+
+	ids := func(items []pagerItem) []int {
+		out := []int{}
+		for _, item := range items {
+			out = append(out, item.ID)
+		}
+		return out
+	}
+
+	It("pages through the tokens of a RequestReader", func() {
+		seen := [][]int{}
+		token := ""
+		for {
+			t, err := rr.Read(filterRequest{status: "active", token: token})
+			Expect(err).NotTo(HaveOccurred())
+
+			items, next, err := pager.Page(context.Background(), t, 3)
+			Expect(err).NotTo(HaveOccurred())
+			seen = append(seen, ids(items))
+
+			if next == nil {
+				break
+			}
+			Expect(next.PageIndex()).To(Equal(t.PageIndex() + 1))
+			token, err = next.String()
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		Expect(seen).To(Equal([][]int{{1, 2, 3}, {4, 5, 6}, {7}}))
+	})
+
+	It("returns no next token if the last page is full", func() {
+		t, err := rr.Read(filterRequest{status: "active"})
+		Expect(err).NotTo(HaveOccurred())
+
+		items, next, err := pager.Page(context.Background(), t, 7)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ids(items)).To(Equal([]int{1, 2, 3, 4, 5, 6, 7}))
+		Expect(next).To(BeNil())
+	})
+
+	It("uses the page size of the token", func() {
+		t, err := rr.Read(sizedRequest{size: 2})
+		Expect(err).NotTo(HaveOccurred())
+
+		items, next, err := pager.Page(context.Background(), t, 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ids(items)).To(Equal([]int{1, 2}))
+		Expect(next.Payload().Values()).To(Equal(pager.Cursor(source[1]).Values()))
+	})
+
+	It("returns the error of Fetch", func() {
+		pager.Fetch = func(context.Context, *pagetoken.KeysetPayload, int) ([]pagerItem, error) {
+			return nil, errors.New("unavailable")
+		}
+		t, err := rr.Read(filterRequest{status: "active"})
+		Expect(err).NotTo(HaveOccurred())
+
+		_, next, err := pager.Page(context.Background(), t, 3)
+		Expect(err).To(MatchError("unavailable"))
+		Expect(next).To(BeNil())
+	})
+
+	Describe("All", func() {
+		It("iterates over all items from the start", func() {
+			items := []pagerItem{}
+			for item, err := range pager.All(context.Background(), nil, 3) {
+				Expect(err).NotTo(HaveOccurred())
+				items = append(items, item)
+			}
+
+			Expect(ids(items)).To(Equal([]int{1, 2, 3, 4, 5, 6, 7}))
+			Expect(fetches).To(Equal(3))
+		})
+
+		It("iterates from the page of a token", func() {
+			t, err := rr.Read(filterRequest{status: "active"})
+			Expect(err).NotTo(HaveOccurred())
+			_, next, err := pager.Page(context.Background(), t, 3)
+			Expect(err).NotTo(HaveOccurred())
+
+			items := []pagerItem{}
+			for item, err := range pager.All(context.Background(), next, 3) {
+				Expect(err).NotTo(HaveOccurred())
+				items = append(items, item)
+			}
+
+			Expect(ids(items)).To(Equal([]int{4, 5, 6, 7}))
+		})
+
+		It("stops loading pages once the iteration stops", func() {
+			for item := range pager.All(context.Background(), nil, 3) {
+				if item.ID == 2 {
+					break
+				}
+			}
+
+			Expect(fetches).To(Equal(1))
+		})
+
+		It("yields the error of a canceled context", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			errs := []error{}
+			for _, err := range pager.All(ctx, nil, 3) {
+				errs = append(errs, err)
+			}
+
+			Expect(errs).To(ConsistOf(MatchError(context.Canceled)))
+			Expect(fetches).To(BeZero())
+		})
+	})
+})
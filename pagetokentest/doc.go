@@ -0,0 +1,31 @@
+// Package pagetokentest provides helpers for testing code that reads and
+// writes page tokens: a deterministic crypter, functions minting and parsing
+// tokens without error handling, and functions asserting on tokens. It
+// depends on the standard library and the pagetoken packages only.
+//
+// All helpers use StaticCrypter, so a RequestReader under test must be
+// created with it:
+//
+//	reader := pagetoken.NewRequestReader(pagetoken.WithEncryptor(pagetokentest.StaticCrypter{}))
+//
+//	token := pagetokentest.MustTokenFor(t, &ListUsersRequest{Status: "active"},
+//	    pagetoken.KeysetValue{Path: "id", Value: "42", Order: order.Asc},
+//	)
+//	resp := listUsers(reader, &ListUsersRequest{Status: "active", PageToken: token})
+//
+//	next := pagetokentest.ParseForTest(t, resp.NextPageToken)
+//	if !pagetokentest.HasKeysetField(next, "id", "52", order.Asc) {
+//	    t.Errorf("next page starts at %v", next.Payload().Values())
+//	}
+//
+// Equivalent compares tokens regardless of their issue time. Gomega matchers
+// built on both functions are provided by the separate pagetokengomega
+// module.
+//
+// RoundTrip checks that a reader preserves a keyset for a request type, and
+// ArbitraryValue and ArbitraryPayload implement quick.Generator, so that
+// testing/quick can drive it over arbitrary keysets and filters.
+//
+// WalkAllPages follows the tokens of a listing to its end and reports rows
+// repeated, skipped or reordered at page boundaries.
+package pagetokentest
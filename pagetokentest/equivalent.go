@@ -0,0 +1,95 @@
+package pagetokentest
+
+import (
+	"slices"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/order"
+)
+
+// HasKeysetField reports whether the keyset of t carries the field path with
+// the given value and order, sensitive or not.
+func HasKeysetField(t *pagetoken.KeysetToken, path, value string, o order.Order) bool {
+	return slices.ContainsFunc(t.Payload().Values(), func(v pagetoken.KeysetValue) bool {
+		return v.Path == path && v.Value == value && v.Order == o
+	})
+}
+
+// Equivalent reports whether a and b are logically equal: they carry the
+// same checksum, keyset, total count, snapshot time, upstream token,
+// composite payload, named keysets and page index. The issue time is
+// ignored, as is the crypter, so a token and its parsed counterpart are
+// equivalent.
+func Equivalent(a, b *pagetoken.KeysetToken) bool {
+	if a.Checksum() != b.Checksum() || a.PageIndex() != b.PageIndex() {
+		return false
+	}
+
+	if !slices.Equal(a.Payload().Values(), b.Payload().Values()) {
+		return false
+	}
+
+	an, aOk := a.TotalCount()
+	bn, bOk := b.TotalCount()
+	if an != bn || aOk != bOk {
+		return false
+	}
+
+	as, _ := a.SnapshotTime()
+	bs, _ := b.SnapshotTime()
+	if !as.Equal(bs) {
+		return false
+	}
+
+	au, aOk := a.UpstreamToken()
+	bu, bOk := b.UpstreamToken()
+	if au != bu || aOk != bOk {
+		return false
+	}
+
+	return equivalentGroups(a, b) && equivalentComposite(a.CompositePayload(), b.CompositePayload())
+}
+
+func equivalentGroups(a, b *pagetoken.KeysetToken) bool {
+	names := a.PayloadNames()
+	if !slices.Equal(names, b.PayloadNames()) || (names == nil) != (b.PayloadNames() == nil) {
+		return false
+	}
+
+	for _, name := range names {
+		ap, _ := a.PayloadFor(name)
+		bp, _ := b.PayloadFor(name)
+		if !slices.Equal(ap.Values(), bp.Values()) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func equivalentComposite(a, b *pagetoken.CompositePayload) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	names := a.Names()
+	if !slices.Equal(names, b.Names()) {
+		return false
+	}
+
+	for _, name := range names {
+		if a.Done(name) != b.Done(name) {
+			return false
+		}
+
+		ap, bp := a.Get(name), b.Get(name)
+		if (ap == nil) != (bp == nil) {
+			return false
+		}
+		if ap != nil && !slices.Equal(ap.Values(), bp.Values()) {
+			return false
+		}
+	}
+
+	return true
+}
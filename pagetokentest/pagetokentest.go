@@ -0,0 +1,149 @@
+package pagetokentest
+
+import (
+	"encoding/base64"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/checksum"
+)
+
+// StaticCrypter is a deterministic encryption.Crypter for tests. It encodes
+// plaintexts as unpadded URL-safe base64 without encrypting them, so equal
+// tokens always yield equal strings and a token can be read with any base64
+// decoder. Its tokens pass the character check of pagetoken.Middleware.
+//
+// StaticCrypter provides no confidentiality or integrity whatsoever and must
+// never be used outside of tests.
+type StaticCrypter struct{}
+
+func (StaticCrypter) Encrypt(d []byte) (string, error) {
+	return base64.RawURLEncoding.EncodeToString(d), nil
+}
+
+func (StaticCrypter) Decrypt(token string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(token)
+}
+
+// TB is the subset of testing.TB used by the helpers. It is implemented by
+// *testing.T, *testing.B and ginkgo.GinkgoT().
+type TB interface {
+	Helper()
+	Fatalf(format string, args ...any)
+}
+
+type request struct {
+	fields []checksum.BuilderOpt
+}
+
+func (r request) GetChecksumFields() []checksum.BuilderOpt { return r.fields }
+func (request) GetPageToken() string                       { return "" }
+
+// MustToken returns a token string carrying the keyset fields, for a request
+// without checksum fields. It fails t if the token cannot be serialized.
+func MustToken(t TB, fields ...pagetoken.KeysetValue) string {
+	t.Helper()
+
+	return MustTokenFor(t, request{}, fields...)
+}
+
+// MustTokenFor returns a token string carrying the keyset fields, bound to
+// the checksum fields of req as if it had been issued in response to req.
+// The page token of req is ignored. It fails t if the token cannot be
+// serialized.
+//
+// The checksum is that of a RequestReader without options, so the token does
+// not match readers created with pagetoken.WithAIP158 or
+// pagetoken.WithChecksumOpts.
+func MustTokenFor(t TB, req pagetoken.Request, fields ...pagetoken.KeysetValue) string {
+	t.Helper()
+
+	first, err := pagetoken.NewRequestReader(
+		pagetoken.WithEncryptor(StaticCrypter{}),
+	).Read(request{fields: req.GetChecksumFields()})
+	if err != nil {
+		t.Fatalf("pagetokentest: failed to create token: %v", err)
+	}
+
+	s, err := first.Next(pagetoken.WithKeysetPayload(Payload(fields...))).String()
+	if err != nil {
+		t.Fatalf("pagetokentest: failed to serialize token: %v", err)
+	}
+
+	return s
+}
+
+// ParseForTest parses a token string created with StaticCrypter. It fails t
+// if the token cannot be parsed.
+func ParseForTest(t TB, token string) *pagetoken.KeysetToken {
+	t.Helper()
+
+	kt, err := parse(token)
+	if err != nil {
+		t.Fatalf("pagetokentest: failed to parse token: %v", err)
+	}
+
+	return kt
+}
+
+// Payload returns a keyset payload carrying the fields in the given order.
+func Payload(fields ...pagetoken.KeysetValue) *pagetoken.KeysetPayload {
+	b := pagetoken.NewKeysetPayloadBuilder()
+	for _, f := range fields {
+		b.AddString(f.Path, f.Value, f.Order)
+		if f.Sensitive {
+			b.Sensitive()
+		}
+	}
+
+	return b.Build()
+}
+
+// RoundTrip asserts that reader preserves payload for requests built by
+// makeReq: a token issued for the first page of makeReq("") and carrying
+// payload must be accepted by reader for makeReq(token), checksum included,
+// and read back equivalent to the issued token. It fails t otherwise.
+//
+// Unlike the other helpers, RoundTrip works with readers of any crypter and
+// checksum options. Together with ArbitraryPayload it turns into a property
+// of the request type:
+//
+//	quick.Check(func(p pagetokentest.ArbitraryPayload, status string) bool {
+//	    pagetokentest.RoundTrip(t, reader, func(token string) pagetoken.Request {
+//	        return &ListUsersRequest{Status: status, PageToken: token}
+//	    }, p.KeysetPayload)
+//	    return true
+//	}, nil)
+func RoundTrip(t TB, reader *pagetoken.RequestReader, makeReq func(token string) pagetoken.Request, payload *pagetoken.KeysetPayload) {
+	t.Helper()
+
+	first, err := reader.Read(makeReq(""))
+	if err != nil {
+		t.Fatalf("pagetokentest: failed to read first page request: %v", err)
+		return
+	}
+
+	want := first.Next(pagetoken.WithKeysetPayload(payload))
+	s, err := want.String()
+	if err != nil {
+		t.Fatalf("pagetokentest: failed to serialize token: %v", err)
+		return
+	}
+
+	got, err := reader.Read(makeReq(s))
+	if err != nil {
+		t.Fatalf("pagetokentest: failed to read token back: %v", err)
+		return
+	}
+
+	if !Equivalent(got, want) {
+		t.Fatalf("pagetokentest: token not preserved\n\tissued: checksum %d, page %d, keyset %v\n\tread:   checksum %d, page %d, keyset %v",
+			want.Checksum(), want.PageIndex(), want.Payload().Values(),
+			got.Checksum(), got.PageIndex(), got.Payload().Values())
+	}
+}
+
+func parse(token string) (*pagetoken.KeysetToken, error) {
+	return pagetoken.NewKeysetTokenParser(
+		pagetoken.WithKeysetTokenEncryptor(StaticCrypter{}),
+	).Parse(token)
+}
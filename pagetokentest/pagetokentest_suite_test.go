@@ -0,0 +1,13 @@
+package pagetokentest_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestPagetokentest(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Pagetokentest Suite")
+}
@@ -0,0 +1,102 @@
+package pagetokentest_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/checksum"
+	"github.com/pixlcrashr/go-pagetoken/order"
+	"github.com/pixlcrashr/go-pagetoken/pagetokentest"
+)
+
+type filterRequest struct {
+	status string
+	token  string
+}
+
+func (r filterRequest) GetChecksumFields() []checksum.BuilderOpt {
+	return []checksum.BuilderOpt{checksum.Field("status", r.status)}
+}
+
+func (r filterRequest) GetPageToken() string { return r.token }
+
+var id42 = pagetoken.KeysetValue{Path: "id", Value: "42", Order: order.Asc}
+
+var _ = Describe("StaticCrypter", func() {
+	It("encrypts equal plaintexts to equal, URL-safe strings", func() {
+		c := pagetokentest.StaticCrypter{}
+		a, err := c.Encrypt([]byte("\x01{\"k\":[]}"))
+		Expect(err).NotTo(HaveOccurred())
+		b, err := c.Encrypt([]byte("\x01{\"k\":[]}"))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(a).To(Equal(b))
+		Expect(a).To(MatchRegexp(`^[A-Za-z0-9_-]+$`))
+
+		d, err := c.Decrypt(a)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(d)).To(Equal("\x01{\"k\":[]}"))
+	})
+})
+
+var _ = Describe("MustTokenFor", func() {
+	reader := func() *pagetoken.RequestReader {
+		return pagetoken.NewRequestReader(pagetoken.WithEncryptor(pagetokentest.StaticCrypter{}))
+	}
+
+	It("mints tokens a reader accepts for the request", func() {
+		s := pagetokentest.MustTokenFor(GinkgoT(), filterRequest{status: "active"}, id42)
+
+		t, err := reader().Read(filterRequest{status: "active", token: s})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pagetokentest.HasKeysetField(t, "id", "42", order.Asc)).To(BeTrue())
+		Expect(t.PageIndex()).To(Equal(1))
+
+		_, err = reader().Read(filterRequest{status: "inactive", token: s})
+		Expect(err).To(MatchError(pagetoken.ErrChecksumMismatch))
+	})
+
+	It("mints tokens for requests without checksum fields with MustToken", func() {
+		s := pagetokentest.MustToken(GinkgoT(), id42)
+
+		t, err := reader().Read(filterRequest{token: s})
+		Expect(err).To(MatchError(pagetoken.ErrChecksumMismatch))
+		Expect(t).To(BeNil())
+
+		Expect(pagetokentest.ParseForTest(GinkgoT(), s).Payload().Values()).To(ConsistOf(id42))
+	})
+})
+
+var _ = Describe("HasKeysetField", func() {
+	It("matches keyset fields by path, value and order", func() {
+		t := pagetokentest.ParseForTest(GinkgoT(), pagetokentest.MustToken(GinkgoT(), id42))
+
+		Expect(pagetokentest.HasKeysetField(t, "id", "42", order.Asc)).To(BeTrue())
+		Expect(pagetokentest.HasKeysetField(t, "id", "42", order.Desc)).To(BeFalse())
+		Expect(pagetokentest.HasKeysetField(t, "name", "42", order.Asc)).To(BeFalse())
+	})
+})
+
+var _ = Describe("Equivalent", func() {
+	It("considers a token equivalent to its parsed string", func() {
+		t := pagetokentest.ParseForTest(GinkgoT(), pagetokentest.MustToken(GinkgoT(), id42))
+		t.SetTotalCount(7)
+		t.SetUpstreamToken("upstream")
+
+		s, err := t.String()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pagetokentest.Equivalent(t, pagetokentest.ParseForTest(GinkgoT(), s))).To(BeTrue())
+	})
+
+	DescribeTable("tells tokens apart",
+		func(opts ...pagetoken.KeysetTokenOpt) {
+			t := pagetokentest.ParseForTest(GinkgoT(), pagetokentest.MustToken(GinkgoT(), id42))
+			Expect(pagetokentest.Equivalent(t.Next(), t)).To(BeFalse())
+			Expect(pagetokentest.Equivalent(t.Next(opts...), t.Next())).To(BeFalse())
+		},
+		Entry("by total count", pagetoken.WithTotalCount(1)),
+		Entry("by upstream token", pagetoken.WithUpstreamToken("")),
+		Entry("by keyset", pagetoken.WithKeysetPayload(pagetokentest.Payload())),
+	)
+})
@@ -0,0 +1,106 @@
+package pagetokentest
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/order"
+)
+
+// maxArbitraryFields is the most fields ArbitraryPayload generates.
+const maxArbitraryFields = 8
+
+// ArbitraryValue is a KeysetValue implementing quick.Generator, for
+// properties driven by testing/quick:
+//
+//	quick.Check(func(v pagetokentest.ArbitraryValue) bool {
+//	    kv := pagetoken.KeysetValue(v)
+//	    ...
+//	}, nil)
+//
+// Generated paths are column names of lower case letters, digits and
+// underscores starting with a letter. Values are arbitrary valid UTF-8
+// strings, as produced by the typed adders of KeysetPayloadBuilder; one in
+// four is sensitive.
+type ArbitraryValue pagetoken.KeysetValue
+
+// Generate implements quick.Generator.
+func (ArbitraryValue) Generate(r *rand.Rand, size int) reflect.Value {
+	return reflect.ValueOf(ArbitraryValue{
+		Path:      arbitraryPath(r, size),
+		Order:     arbitraryOrder(r),
+		Value:     arbitraryString(r, size),
+		Sensitive: r.Intn(4) == 0,
+	})
+}
+
+// ArbitraryPayload wraps a KeysetPayload implementing quick.Generator. It
+// holds up to eight fields with distinct paths, see ArbitraryValue.
+type ArbitraryPayload struct {
+	*pagetoken.KeysetPayload
+}
+
+// Generate implements quick.Generator.
+func (ArbitraryPayload) Generate(r *rand.Rand, size int) reflect.Value {
+	n := r.Intn(min(size, maxArbitraryFields) + 1)
+
+	b := pagetoken.NewKeysetPayloadBuilder()
+	seen := map[string]bool{}
+	for len(seen) < n {
+		path := arbitraryPath(r, size)
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+
+		b.AddString(path, arbitraryString(r, size), arbitraryOrder(r))
+		if r.Intn(4) == 0 {
+			b.Sensitive()
+		}
+	}
+
+	return reflect.ValueOf(ArbitraryPayload{b.Build()})
+}
+
+const (
+	pathStart = "abcdefghijklmnopqrstuvwxyz"
+	pathRest  = pathStart + "0123456789_"
+)
+
+func arbitraryPath(r *rand.Rand, size int) string {
+	var sb strings.Builder
+	sb.WriteByte(pathStart[r.Intn(len(pathStart))])
+	for range r.Intn(min(size, 32) + 1) {
+		sb.WriteByte(pathRest[r.Intn(len(pathRest))])
+	}
+
+	return sb.String()
+}
+
+func arbitraryOrder(r *rand.Rand) order.Order {
+	if r.Intn(2) == 0 {
+		return order.Asc
+	}
+
+	return order.Desc
+}
+
+// arbitraryString returns a valid UTF-8 string mixing ASCII, characters
+// JSON escapes and multi-byte runes.
+func arbitraryString(r *rand.Rand, size int) string {
+	var sb strings.Builder
+	for range r.Intn(size + 1) {
+		switch r.Intn(4) {
+		case 0:
+			sb.WriteRune(rune(r.Intn(0x20)))
+		case 1:
+			sb.WriteRune(rune(0x80 + r.Intn(0xd800-0x80)))
+		default:
+			sb.WriteRune(rune(0x20 + r.Intn(0x5f)))
+		}
+	}
+
+	return sb.String()
+}
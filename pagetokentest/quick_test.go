@@ -0,0 +1,95 @@
+package pagetokentest_test
+
+import (
+	"fmt"
+	"regexp"
+	"testing/quick"
+	"unicode/utf8"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/encryption"
+	"github.com/pixlcrashr/go-pagetoken/order"
+	"github.com/pixlcrashr/go-pagetoken/pagetokentest"
+)
+
+// recordingTB records failures instead of stopping the test.
+type recordingTB struct {
+	failures []string
+}
+
+func (*recordingTB) Helper() {}
+
+func (r *recordingTB) Fatalf(format string, args ...any) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+var pathPattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+func validValue(v pagetoken.KeysetValue) bool {
+	return pathPattern.MatchString(v.Path) &&
+		utf8.ValidString(v.Value) &&
+		(v.Order == order.Asc || v.Order == order.Desc)
+}
+
+var _ = Describe("Generators", func() {
+	It("generate valid keyset values", func() {
+		Expect(quick.Check(func(v pagetokentest.ArbitraryValue) bool {
+			return validValue(pagetoken.KeysetValue(v))
+		}, nil)).To(Succeed())
+	})
+
+	It("generate payloads of valid values with distinct paths", func() {
+		Expect(quick.Check(func(p pagetokentest.ArbitraryPayload) bool {
+			seen := map[string]bool{}
+			for _, v := range p.Values() {
+				if !validValue(v) || seen[v.Path] {
+					return false
+				}
+				seen[v.Path] = true
+			}
+			return len(seen) <= 8
+		}, nil)).To(Succeed())
+	})
+})
+
+var _ = Describe("RoundTrip", func() {
+	It("holds for any filter and keyset with StaticCrypter", func() {
+		reader := pagetoken.NewRequestReader(pagetoken.WithEncryptor(pagetokentest.StaticCrypter{}))
+
+		Expect(quick.Check(func(p pagetokentest.ArbitraryPayload, status string) bool {
+			pagetokentest.RoundTrip(GinkgoT(), reader, func(token string) pagetoken.Request {
+				return filterRequest{status: status, token: token}
+			}, p.KeysetPayload)
+			return true
+		}, nil)).To(Succeed())
+	})
+
+	It("holds for any filter and keyset with AES-GCM and AIP-158 checksums", func() {
+		key, err := encryption.Rand32ByteKey()
+		Expect(err).NotTo(HaveOccurred())
+		e, err := encryption.NewAEADEncryptor(key)
+		Expect(err).NotTo(HaveOccurred())
+		reader := pagetoken.NewRequestReader(pagetoken.WithEncryptor(e), pagetoken.WithAIP158())
+
+		Expect(quick.Check(func(p pagetokentest.ArbitraryPayload, status string) bool {
+			pagetokentest.RoundTrip(GinkgoT(), reader, func(token string) pagetoken.Request {
+				return filterRequest{status: status, token: token}
+			}, p.KeysetPayload)
+			return true
+		}, nil)).To(Succeed())
+	})
+
+	It("fails when the checksum fields change between requests", func() {
+		reader := pagetoken.NewRequestReader(pagetoken.WithEncryptor(pagetokentest.StaticCrypter{}))
+
+		t := &recordingTB{}
+		pagetokentest.RoundTrip(t, reader, func(token string) pagetoken.Request {
+			return filterRequest{status: "status-" + token, token: token}
+		}, pagetokentest.Payload(id42))
+
+		Expect(t.failures).To(ConsistOf(ContainSubstring(pagetoken.ErrChecksumMismatch.Error())))
+	})
+})
@@ -0,0 +1,130 @@
+package pagetokentest
+
+import (
+	"fmt"
+	"strings"
+)
+
+// WalkAllPages follows page tokens from the first page until fetch returns
+// an empty next token, and asserts that the keys of the returned items are
+// exactly want, in order: every key appears once, none is skipped and none
+// is unexpected. It fails t with a report naming the pages on which rows
+// were repeated, skipped or reordered, followed by the keys of every page.
+//
+// fetch is called with an empty token for the first page. The walk fails
+// after more than len(want)+1 pages, so a listing that never ends is
+// reported instead of hanging the test.
+func WalkAllPages[T any](t TB, fetch func(token string) (items []T, next string, err error), key func(T) string, want []string) {
+	t.Helper()
+
+	var pages [][]string
+	token := ""
+	for {
+		if len(pages) > len(want) {
+			t.Fatalf("pagetokentest: listing did not end after %d pages for %d keys\n%s",
+				len(pages), len(want), formatPages(pages))
+			return
+		}
+
+		items, next, err := fetch(token)
+		if err != nil {
+			t.Fatalf("pagetokentest: failed to fetch page %d: %v\n%s",
+				len(pages)+1, err, formatPages(pages))
+			return
+		}
+
+		keys := make([]string, len(items))
+		for i, it := range items {
+			keys[i] = key(it)
+		}
+		pages = append(pages, keys)
+
+		if next == "" {
+			break
+		}
+		token = next
+	}
+
+	if problems := comparePages(pages, want); len(problems) > 0 {
+		t.Fatalf("pagetokentest: pages do not match the expected keys\n\t%s\n%s",
+			strings.Join(problems, "\n\t"), formatPages(pages))
+	}
+}
+
+// comparePages describes how the keys of pages differ from want, or returns
+// nil if they match.
+func comparePages(pages [][]string, want []string) []string {
+	wantIdx := make(map[string]int, len(want))
+	for i, k := range want {
+		wantIdx[k] = i
+	}
+
+	var problems []string
+	var got []string
+	page := map[string]int{}
+	for p, keys := range pages {
+		for _, k := range keys {
+			if first, ok := page[k]; ok {
+				problems = append(problems, fmt.Sprintf("page %d: duplicate %q, first returned on page %d", p+1, k, first))
+				continue
+			}
+			page[k] = p + 1
+
+			if _, ok := wantIdx[k]; !ok {
+				problems = append(problems, fmt.Sprintf("page %d: unexpected %q", p+1, k))
+				continue
+			}
+			got = append(got, k)
+		}
+	}
+
+	for i, k := range want {
+		if _, ok := page[k]; ok {
+			continue
+		}
+		problems = append(problems, fmt.Sprintf("missing %q %s", k, missingContext(want, page, i)))
+	}
+
+	if len(problems) > 0 {
+		return problems
+	}
+
+	// every key appears exactly once, so got and want only differ in order
+	for i := range got {
+		if got[i] != want[i] {
+			return []string{fmt.Sprintf("page %d: %q returned where %q was expected", page[got[i]], got[i], want[i])}
+		}
+	}
+
+	return nil
+}
+
+// missingContext locates the missing key want[i] between its closest
+// neighbours that were returned.
+func missingContext(want []string, page map[string]int, i int) string {
+	before, after := "the start", "the end"
+	for j := i - 1; j >= 0; j-- {
+		if p, ok := page[want[j]]; ok {
+			before = fmt.Sprintf("%q (page %d)", want[j], p)
+			break
+		}
+	}
+	for j := i + 1; j < len(want); j++ {
+		if p, ok := page[want[j]]; ok {
+			after = fmt.Sprintf("%q (page %d)", want[j], p)
+			break
+		}
+	}
+
+	return "between " + before + " and " + after
+}
+
+func formatPages(pages [][]string) string {
+	var sb strings.Builder
+	sb.WriteString("pages:")
+	for p, keys := range pages {
+		fmt.Fprintf(&sb, "\n\t%d: %s", p+1, strings.Join(keys, ", "))
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,96 @@
+package pagetokentest_test
+
+import (
+	"errors"
+	"strconv"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken/pagetokentest"
+)
+
+// pager serves keys in pages of size, with the page index as token.
+type pager struct {
+	pages [][]string
+}
+
+func pagesOf(size int, keys ...string) pager {
+	p := pager{}
+	for len(keys) > size {
+		p.pages = append(p.pages, keys[:size])
+		keys = keys[size:]
+	}
+	p.pages = append(p.pages, keys)
+	return p
+}
+
+func (p pager) fetch(token string) ([]string, string, error) {
+	i := 0
+	if token != "" {
+		var err error
+		if i, err = strconv.Atoi(token); err != nil {
+			return nil, "", err
+		}
+	}
+
+	if i+1 == len(p.pages) {
+		return p.pages[i], "", nil
+	}
+	return p.pages[i], strconv.Itoa(i + 1), nil
+}
+
+func identity(s string) string { return s }
+
+var _ = Describe("WalkAllPages", func() {
+	want := []string{"a", "b", "c", "d", "e", "f", "g"}
+
+	walk := func(fetch func(string) ([]string, string, error)) []string {
+		t := &recordingTB{}
+		pagetokentest.WalkAllPages(t, fetch, identity, want)
+		return t.failures
+	}
+
+	It("passes when every key is returned once and in order", func() {
+		Expect(walk(pagesOf(3, want...).fetch)).To(BeEmpty())
+		Expect(walk(pager{pages: [][]string{want[:3], want[3:], {}}}.fetch)).To(BeEmpty())
+	})
+
+	It("reports rows repeated across a page boundary", func() {
+		p := pager{pages: [][]string{{"a", "b", "c"}, {"c", "d", "e"}, {"f", "g"}}}
+		Expect(walk(p.fetch)).To(ConsistOf(And(
+			ContainSubstring(`page 2: duplicate "c", first returned on page 1`),
+			ContainSubstring("\t2: c, d, e"),
+		)))
+	})
+
+	It("reports rows skipped at a page boundary", func() {
+		p := pager{pages: [][]string{{"a", "b", "c"}, {"e", "f", "g"}}}
+		Expect(walk(p.fetch)).To(ConsistOf(
+			ContainSubstring(`missing "d" between "c" (page 1) and "e" (page 2)`),
+		))
+	})
+
+	It("reports unexpected and reordered rows", func() {
+		p := pager{pages: [][]string{{"a", "b", "c"}, {"d", "e", "f", "g", "h"}}}
+		Expect(walk(p.fetch)).To(ConsistOf(ContainSubstring(`page 2: unexpected "h"`)))
+
+		p = pager{pages: [][]string{{"a", "b", "c"}, {"e", "d", "f", "g"}}}
+		Expect(walk(p.fetch)).To(ConsistOf(ContainSubstring(`page 2: "e" returned where "d" was expected`)))
+	})
+
+	It("stops listings that do not end", func() {
+		Expect(walk(func(string) ([]string, string, error) {
+			return []string{"a"}, "again", nil
+		})).To(ConsistOf(ContainSubstring("listing did not end after 8 pages")))
+	})
+
+	It("reports fetch errors with the page", func() {
+		Expect(walk(func(token string) ([]string, string, error) {
+			if token != "" {
+				return nil, "", errors.New("boom")
+			}
+			return want[:3], "next", nil
+		})).To(ConsistOf(ContainSubstring("failed to fetch page 2: boom")))
+	})
+})
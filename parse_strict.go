@@ -0,0 +1,90 @@
+package pagetoken
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// WithStrictValues makes the parser reject tokens whose keyset paths or
+// values contain NUL bytes or whose plaintext is not valid UTF-8 with an
+// error wrapping ErrInvalidToken and ErrMalformedToken, which names the
+// offending path. Such values cannot be issued by AddString and friends on
+// valid input but may reach query building through crafted tokens.
+//
+// The keysets of composite and named payloads are checked as well. Orders
+// need no option: the parser only ever accepts "asc" and "desc".
+func WithStrictValues() KeysetTokenParserOpt {
+	return func(p *KeysetTokenParser) {
+		p.strictValues = true
+	}
+}
+
+// WithMaxValueLen makes the parser reject tokens with a keyset value longer
+// than n bytes with an error wrapping ErrInvalidToken and
+// ErrMalformedToken, which names the offending path. A non-positive n
+// disables the check.
+func WithMaxValueLen(n int) KeysetTokenParserOpt {
+	return func(p *KeysetTokenParser) {
+		p.maxValueLen = n
+	}
+}
+
+// checkValues applies the value checks of p to the keysets of t, decoded
+// from the plaintext d.
+func (p *KeysetTokenParser) checkValues(t *KeysetToken, d []byte) error {
+	if !p.strictValues && p.maxValueLen <= 0 {
+		return nil
+	}
+
+	invalidUTF8 := p.strictValues && !utf8.Valid(d)
+
+	payloads := []*KeysetPayload{t.payload}
+	if t.composite != nil {
+		for _, name := range t.composite.Names() {
+			payloads = append(payloads, t.composite.Get(name))
+		}
+	}
+	for _, name := range t.PayloadNames() {
+		payloads = append(payloads, t.groups[name])
+	}
+
+	for _, payload := range payloads {
+		if payload == nil {
+			continue
+		}
+
+		for _, v := range payload.vs {
+			if err := p.checkValue(v, invalidUTF8); err != nil {
+				return err
+			}
+		}
+	}
+
+	// the invalid bytes are outside of the keysets, e.g. in the upstream
+	// token
+	if invalidUTF8 {
+		return fmt.Errorf("%w: invalid UTF-8", ErrMalformedToken)
+	}
+
+	return nil
+}
+
+func (p *KeysetTokenParser) checkValue(v KeysetValue, invalidUTF8 bool) error {
+	if p.strictValues {
+		if strings.IndexByte(v.Path, 0) >= 0 || strings.IndexByte(v.Value, 0) >= 0 {
+			return fmt.Errorf("%w: %q: NUL byte", ErrMalformedToken, v.Path)
+		}
+
+		// the JSON decoder replaced the invalid bytes by utf8.RuneError
+		if invalidUTF8 && (strings.ContainsRune(v.Path, utf8.RuneError) || strings.ContainsRune(v.Value, utf8.RuneError)) {
+			return fmt.Errorf("%w: %q: invalid UTF-8", ErrMalformedToken, v.Path)
+		}
+	}
+
+	if p.maxValueLen > 0 && len(v.Value) > p.maxValueLen {
+		return fmt.Errorf("%w: %q: value of %d bytes exceeds %d", ErrMalformedToken, v.Path, len(v.Value), p.maxValueLen)
+	}
+
+	return nil
+}
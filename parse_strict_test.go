@@ -0,0 +1,100 @@
+package pagetoken_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/pagetokentest"
+)
+
+var _ = Describe("Strict value checks", func() {
+	encrypt := func(plaintext string) string {
+		s, err := pagetokentest.StaticCrypter{}.Encrypt([]byte(plaintext))
+		Expect(err).NotTo(HaveOccurred())
+		return s
+	}
+
+	parse := func(plaintext string, opts ...pagetoken.KeysetTokenParserOpt) error {
+		opts = append(opts, pagetoken.WithKeysetTokenEncryptor(pagetokentest.StaticCrypter{}))
+		_, err := pagetoken.NewKeysetTokenParser(opts...).Parse(encrypt(plaintext))
+		return err
+	}
+
+	strict := []pagetoken.KeysetTokenParserOpt{pagetoken.WithStrictValues(), pagetoken.WithMaxValueLen(8)}
+
+	DescribeTable("rejects malformed values naming the path",
+		func(plaintext string, path string) {
+			err := parse(plaintext, strict...)
+			Expect(err).To(MatchError(pagetoken.ErrInvalidToken))
+			Expect(err).To(MatchError(pagetoken.ErrMalformedToken))
+			Expect(err).To(MatchError(ContainSubstring(path)))
+		},
+		Entry("a NUL byte in a value", `["id","4\u00002","asc","1"]`, `"id"`),
+		Entry("a NUL byte in a path", `["i\u0000d","42","asc","1"]`, `"i\x00d"`),
+		Entry("invalid UTF-8 in a value", "[\"name\",\"a\xffb\",\"asc\",\"1\"]", `"name"`),
+		Entry("a value longer than the cap", `["name","abcdefghi","asc","1"]`, `"name"`),
+		Entry("a NUL byte in a composite part",
+			"\x01"+`{"k":[],"c":1,"p":{"p":{"shard-1":["id","\u0000","asc"]}}}`, `"id"`),
+		Entry("a NUL byte in a named keyset",
+			"\x01"+`{"k":[],"c":1,"g":{"k":{"recent":["id","\u0000","asc"]}}}`, `"id"`),
+	)
+
+	DescribeTable("rejects keysets that are not triples",
+		func(plaintext string) {
+			err := parse(plaintext)
+			Expect(err).To(MatchError(pagetoken.ErrInvalidToken))
+			Expect(err).To(MatchError(pagetoken.ErrMalformedToken))
+		},
+		Entry("a legacy token without checksum", `[]`),
+		Entry("a legacy token with a partial triple", `["id","42","1"]`),
+		Entry("a legacy token with a trailing value", `["id","42","asc","x","1"]`),
+		Entry("a partial triple", "\x01"+`{"k":["id","42"],"c":1}`),
+		Entry("a partial upper bound", "\x01"+`{"k":[],"c":1,"b":["id"]}`),
+		Entry("a partial composite part", "\x01"+`{"k":[],"c":1,"p":{"p":{"shard-1":["id","7"]}}}`),
+	)
+
+	DescribeTable("rejects checksums that are not uint32",
+		func(plaintext string) {
+			err := parse(plaintext)
+			Expect(err).To(MatchError(pagetoken.ErrInvalidToken))
+			Expect(err).To(MatchError(pagetoken.ErrMalformedToken))
+		},
+		Entry("a legacy checksum overflowing uint32", `["id","42","asc","4294967297"]`),
+		Entry("a non-numeric legacy checksum", `["id","42","asc","abc"]`),
+		Entry("a negative legacy checksum", `["id","42","asc","-1"]`),
+		Entry("a checksum overflowing uint32", "\x01"+`{"k":["id","42","asc"],"c":4294967297}`),
+		Entry("a non-numeric checksum", "\x01"+`{"k":["id","42","asc"],"c":"1"}`),
+	)
+
+	It("accepts the largest checksum", func() {
+		t, err := pagetoken.NewKeysetTokenParser(pagetoken.WithKeysetTokenEncryptor(pagetokentest.StaticCrypter{})).
+			Parse(encrypt(`["id","42","asc","4294967295"]`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(t.Checksum()).To(Equal(uint32(4294967295)))
+	})
+
+	It("rejects invalid UTF-8 outside of the keysets", func() {
+		err := parse("\x01"+"{\"k\":[],\"c\":1,\"u\":\"a\xffb\"}", strict...)
+		Expect(err).To(MatchError(pagetoken.ErrMalformedToken))
+	})
+
+	It("accepts values at the cap", func() {
+		Expect(parse(`["name","abcdefgh","asc","1"]`, strict...)).To(Succeed())
+	})
+
+	It("rejects numeric orders without any option", func() {
+		err := parse(`["id","42","1","1"]`)
+		Expect(err).To(MatchError(pagetoken.ErrInvalidToken))
+		Expect(err).NotTo(MatchError(pagetoken.ErrMalformedToken))
+	})
+
+	DescribeTable("does not check values by default",
+		func(plaintext string) {
+			Expect(parse(plaintext)).To(Succeed())
+		},
+		Entry("a NUL byte", `["id","4\u00002","asc","1"]`),
+		Entry("invalid UTF-8", "[\"name\",\"a\xffb\",\"asc\",\"1\"]"),
+		Entry("a long value", `["name","abcdefghi","asc","1"]`),
+	)
+})
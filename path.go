@@ -0,0 +1,58 @@
+package pagetoken
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidPath is returned for keyset paths that are not valid
+// identifiers, see ValidatePath.
+var ErrInvalidPath = errors.New("invalid keyset path")
+
+// PathSeparator separates the segments of a nested keyset path such as
+// "author.name".
+const PathSeparator = "."
+
+// ValidatePath checks that path is an identifier or a nested path of
+// identifiers separated by PathSeparator, e.g. "created_at" or
+// "author.name". An identifier starts with an ASCII letter or underscore,
+// followed by ASCII letters, digits and underscores. Invalid paths are
+// reported with an error wrapping ErrInvalidPath.
+//
+// Paths are not validated when they are added to a payload; database
+// adapters that render paths into SQL validate them.
+func ValidatePath(path string) error {
+	for i, s := range SplitPath(path) {
+		if !isIdentifier(s) {
+			return fmt.Errorf("%w: %q: segment %d is not an identifier", ErrInvalidPath, path, i+1)
+		}
+	}
+
+	return nil
+}
+
+// SplitPath returns the segments of a nested keyset path, e.g. "author" and
+// "name" for "author.name". A path without PathSeparator has a single
+// segment.
+func SplitPath(path string) []string {
+	return strings.Split(path, PathSeparator)
+}
+
+func isIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '_', 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z':
+		case '0' <= c && c <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,42 @@
+package pagetoken_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken"
+)
+
+var _ = Describe("Paths", func() {
+	DescribeTable("ValidatePath accepts identifiers and nested paths",
+		func(path string) {
+			Expect(pagetoken.ValidatePath(path)).To(Succeed())
+		},
+		Entry("a column", "created_at"),
+		Entry("a leading underscore", "_id"),
+		Entry("digits", "col2"),
+		Entry("a nested path", "author.name"),
+		Entry("a deeply nested path", "meta.author.address.city"),
+	)
+
+	DescribeTable("ValidatePath rejects other paths",
+		func(path string) {
+			Expect(pagetoken.ValidatePath(path)).To(MatchError(pagetoken.ErrInvalidPath))
+		},
+		Entry("empty", ""),
+		Entry("a leading digit", "2col"),
+		Entry("a leading dot", ".name"),
+		Entry("a trailing dot", "author."),
+		Entry("an empty segment", "author..name"),
+		Entry("spaces", "author name"),
+		Entry("quotes", "author'name"),
+		Entry("SQL", "id; DROP TABLE users"),
+		Entry("expressions", "COALESCE(score, -1)"),
+		Entry("non-ASCII letters", "größe"),
+	)
+
+	It("SplitPath returns the segments", func() {
+		Expect(pagetoken.SplitPath("author.name")).To(Equal([]string{"author", "name"}))
+		Expect(pagetoken.SplitPath("id")).To(Equal([]string{"id"}))
+	})
+})
@@ -0,0 +1,96 @@
+package pagetoken
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// EncodePayload serializes p without checksum, metadata or encryption, for
+// storage that is protected otherwise, e.g. a server-side session, a signed
+// JWT claim or a job queue message. The bytes provide no confidentiality or
+// integrity of their own.
+//
+// The encoding follows the version byte scheme of token plaintexts: a
+// version byte followed by the keyset exactly as it is stored in a token of
+// that version, so EncodePayload(token.Payload()) is stable for equal
+// keysets no matter whether the payload was built, parsed from a token or
+// decoded by DecodePayload:
+//
+//	0x01 ["created_at","2024-01-01T00:00:00Z","desc","id","42","asc"]
+//
+// A payload with sensitive values is encoded as an object of the keyset and
+// the positions of the sensitive values, as stored in the "k" and "r"
+// members of a token:
+//
+//	0x01 {"k":["email","a@example.com","asc","id","42","asc"],"r":[0]}
+//
+// A nil payload encodes like an empty one.
+func EncodePayload(p *KeysetPayload) ([]byte, error) {
+	var vs []KeysetValue
+	if p != nil {
+		vs = p.vs
+	}
+
+	d := append(make([]byte, 0, payloadSizeHint(vs)), keysetTokenV1)
+	if sensitivePositions(vs) == nil {
+		return appendKeyset(d, vs), nil
+	}
+
+	d = append(d, `{"k":`...)
+	d = appendKeyset(d, vs)
+	d = appendSensitive(d, vs)
+	return append(d, '}'), nil
+}
+
+// sensitivePayloadWire is the encoding of a payload with sensitive values.
+type sensitivePayloadWire struct {
+	Keyset    json.RawMessage `json:"k"`
+	Sensitive []int           `json:"r"`
+}
+
+// DecodePayload decodes a payload serialized by EncodePayload of this or an
+// earlier version of the package.
+func DecodePayload(d []byte) (*KeysetPayload, error) {
+	if len(d) == 0 {
+		return nil, errors.New("decode payload: empty input")
+	}
+
+	if d[0] != keysetTokenV1 {
+		return nil, fmt.Errorf("decode payload: unsupported version %#02x", d[0])
+	}
+
+	w := sensitivePayloadWire{Keyset: d[1:]}
+	if len(d) > 1 && d[1] == '{' {
+		w = sensitivePayloadWire{}
+		if err := json.Unmarshal(d[1:], &w); err != nil {
+			return nil, fmt.Errorf("decode payload: %w", err)
+		}
+	}
+
+	ps, err := decodeKeysetStrings(w.Keyset)
+	if err != nil {
+		return nil, fmt.Errorf("decode payload: %w", err)
+	}
+
+	vs, err := decodeKeysetValues(ps)
+	if err != nil {
+		return nil, fmt.Errorf("decode payload: %w", err)
+	}
+	if err := markSensitive(vs, w.Sensitive); err != nil {
+		return nil, fmt.Errorf("decode payload: %w", err)
+	}
+
+	return &KeysetPayload{vs: vs}, nil
+}
+
+func payloadSizeHint(vs []KeysetValue) int {
+	// version byte and brackets
+	n := 3
+	for _, v := range vs {
+		// quotes, commas and order
+		n += len(v.Path) + len(v.Value) + 16
+	}
+
+	return n
+}
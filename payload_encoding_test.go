@@ -0,0 +1,94 @@
+package pagetoken_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing/quick"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/order"
+	"github.com/pixlcrashr/go-pagetoken/pagetokentest"
+)
+
+var _ = Describe("EncodePayload", func() {
+	It("round-trips payloads via DecodePayload", func() {
+		Expect(quick.Check(func(p pagetokentest.ArbitraryPayload) bool {
+			d, err := pagetoken.EncodePayload(p.KeysetPayload)
+			Expect(err).NotTo(HaveOccurred())
+
+			got, err := pagetoken.DecodePayload(d)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(got.Values()).To(Equal(p.Values()))
+
+			again, err := pagetoken.EncodePayload(got)
+			Expect(err).NotTo(HaveOccurred())
+			return string(again) == string(d)
+		}, nil)).To(Succeed())
+	})
+
+	It("encodes a payload extracted from a token and the original alike", func() {
+		p := benchmarkPayload(5)
+		s, err := pagetoken.NewKeysetToken(pagetokentest.StaticCrypter{}, pagetoken.WithKeysetPayload(p)).String()
+		Expect(err).NotTo(HaveOccurred())
+
+		want, err := pagetoken.EncodePayload(p)
+		Expect(err).NotTo(HaveOccurred())
+		got, err := pagetoken.EncodePayload(pagetokentest.ParseForTest(GinkgoT(), s).Payload())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(got).To(Equal(want))
+	})
+
+	It("follows the version byte and keyset encoding of token plaintexts", func() {
+		p := pagetoken.NewKeysetPayloadBuilder().
+			AddString("name", "Zoë \"Q\"", order.Desc).
+			AddInt("id", 42, order.Asc).
+			Build()
+
+		d, err := pagetoken.EncodePayload(p)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(d)).To(Equal("\x01" + `["name","Zoë \"Q\"","desc","id","42","asc"]`))
+
+		s, err := pagetoken.NewKeysetToken(pagetokentest.StaticCrypter{}, pagetoken.WithKeysetPayload(p)).String()
+		Expect(err).NotTo(HaveOccurred())
+		plaintext, err := base64.RawURLEncoding.DecodeString(s)
+		Expect(err).NotTo(HaveOccurred())
+
+		var body struct {
+			Keyset json.RawMessage `json:"k"`
+		}
+		Expect(plaintext[0]).To(Equal(d[0]))
+		Expect(json.Unmarshal(plaintext[1:], &body)).To(Succeed())
+		Expect(string(body.Keyset)).To(Equal(string(d[1:])))
+	})
+
+	It("encodes nil and empty payloads alike", func() {
+		nilP, err := pagetoken.EncodePayload(nil)
+		Expect(err).NotTo(HaveOccurred())
+		empty, err := pagetoken.EncodePayload(pagetoken.NewKeysetPayloadBuilder().Build())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(nilP).To(Equal([]byte("\x01[]")))
+		Expect(empty).To(Equal(nilP))
+
+		p, err := pagetoken.DecodePayload(empty)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(p.Values()).To(BeEmpty())
+	})
+})
+
+var _ = Describe("DecodePayload", func() {
+	DescribeTable("rejects malformed input",
+		func(d string, msg string) {
+			_, err := pagetoken.DecodePayload([]byte(d))
+			Expect(err).To(MatchError(ContainSubstring(msg)))
+		},
+		Entry("empty", "", "empty input"),
+		Entry("unknown version", "\x02[]", "unsupported version 0x02"),
+		Entry("unversioned", `["id","42","asc"]`, "unsupported version 0x5b"),
+		Entry("truncated", "\x01[\"id\",\"42\"", "decode payload"),
+		Entry("incomplete triple", "\x01[\"id\",\"42\"]", "invalid keyset length 2"),
+		Entry("invalid order", "\x01[\"id\",\"42\",\"up\"]", "decode payload"),
+	)
+})
@@ -0,0 +1,212 @@
+package pagetoken
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/pixlcrashr/go-pagetoken/order"
+)
+
+var (
+	// ErrUnexpectedKeysetField is returned by PayloadSchema.Validate for a
+	// keyset value whose path the schema does not declare at its position.
+	ErrUnexpectedKeysetField = errors.New("unexpected keyset field")
+	// ErrMissingKeysetField is returned by PayloadSchema.Validate for a
+	// keyset lacking values of fields the schema declares.
+	ErrMissingKeysetField = errors.New("missing keyset field")
+	// ErrKeysetFieldType is returned by PayloadSchema.Validate for a keyset
+	// value that does not decode as the type the schema declares.
+	ErrKeysetFieldType = errors.New("invalid keyset field type")
+	// ErrKeysetFieldOrder is returned by PayloadSchema.Validate for a keyset
+	// value whose order differs from the one the schema declares.
+	ErrKeysetFieldOrder = errors.New("invalid keyset field order")
+)
+
+// PayloadSchemaCheckFn reports whether value is a valid encoding of a field
+// type, e.g. by decoding it.
+type PayloadSchemaCheckFn func(value string) error
+
+type payloadSchemaField struct {
+	path  string
+	typ   string
+	order order.Order
+	check func(p *KeysetPayload, path string) error
+}
+
+// PayloadSchema declares the exact keyset an endpoint issues: the paths of
+// its values in order, their types and their orders. It lets a RequestReader
+// reject tokens carrying other keysets, see WithPayloadSchema, so that query
+// building downstream only ever sees the columns and types it expects.
+//
+// Types are checked by decoding the values with the accessor of the same
+// name, e.g. Int64 with KeysetPayload.Int64.
+//
+// Example:
+//
+//	schema := pagetoken.NewPayloadSchema().
+//		Time("created_at", order.Desc).
+//		Int64("id", order.Asc)
+type PayloadSchema struct {
+	fields []payloadSchemaField
+}
+
+func NewPayloadSchema() *PayloadSchema {
+	return &PayloadSchema{}
+}
+
+// Field declares a field of a custom type, named typ in errors, whose
+// values check accepts.
+func (s *PayloadSchema) Field(path string, typ string, o order.Order, check PayloadSchemaCheckFn) *PayloadSchema {
+	return s.add(path, typ, o, func(p *KeysetPayload, path string) error {
+		_, _, err := GetKeysetValue(p, path, func(v string) (struct{}, error) {
+			return struct{}{}, check(v)
+		})
+		return err
+	})
+}
+
+func (s *PayloadSchema) String(path string, o order.Order) *PayloadSchema {
+	return s.add(path, "string", o, func(p *KeysetPayload, path string) error {
+		_, _, err := p.String(path)
+		return err
+	})
+}
+
+func (s *PayloadSchema) Bool(path string, o order.Order) *PayloadSchema {
+	return s.add(path, "bool", o, func(p *KeysetPayload, path string) error {
+		_, _, err := p.Bool(path)
+		return err
+	})
+}
+
+func (s *PayloadSchema) Int(path string, o order.Order) *PayloadSchema {
+	return s.add(path, "int", o, func(p *KeysetPayload, path string) error {
+		_, _, err := p.Int(path)
+		return err
+	})
+}
+
+func (s *PayloadSchema) Int32(path string, o order.Order) *PayloadSchema {
+	return s.add(path, "int32", o, func(p *KeysetPayload, path string) error {
+		_, _, err := p.Int32(path)
+		return err
+	})
+}
+
+func (s *PayloadSchema) Int64(path string, o order.Order) *PayloadSchema {
+	return s.add(path, "int64", o, func(p *KeysetPayload, path string) error {
+		_, _, err := p.Int64(path)
+		return err
+	})
+}
+
+func (s *PayloadSchema) Uint64(path string, o order.Order) *PayloadSchema {
+	return s.add(path, "uint64", o, func(p *KeysetPayload, path string) error {
+		_, _, err := p.Uint64(path)
+		return err
+	})
+}
+
+func (s *PayloadSchema) Float64(path string, o order.Order) *PayloadSchema {
+	return s.add(path, "float64", o, func(p *KeysetPayload, path string) error {
+		_, _, err := p.Float64(path)
+		return err
+	})
+}
+
+func (s *PayloadSchema) BigInt(path string, o order.Order) *PayloadSchema {
+	return s.add(path, "big.Int", o, func(p *KeysetPayload, path string) error {
+		_, _, err := p.BigInt(path)
+		return err
+	})
+}
+
+func (s *PayloadSchema) Time(path string, o order.Order) *PayloadSchema {
+	return s.add(path, "time.Time", o, func(p *KeysetPayload, path string) error {
+		_, _, err := p.Time(path)
+		return err
+	})
+}
+
+func (s *PayloadSchema) Date(path string, o order.Order) *PayloadSchema {
+	return s.add(path, "Date", o, func(p *KeysetPayload, path string) error {
+		_, _, err := p.Date(path)
+		return err
+	})
+}
+
+func (s *PayloadSchema) Bytes(path string, o order.Order) *PayloadSchema {
+	return s.add(path, "[]byte", o, func(p *KeysetPayload, path string) error {
+		_, _, err := p.Bytes(path)
+		return err
+	})
+}
+
+func (s *PayloadSchema) UUID(path string, o order.Order) *PayloadSchema {
+	return s.add(path, "[16]byte", o, func(p *KeysetPayload, path string) error {
+		_, _, err := p.UUID(path)
+		return err
+	})
+}
+
+func (s *PayloadSchema) NetIP(path string, o order.Order) *PayloadSchema {
+	return s.add(path, "netip.Addr", o, func(p *KeysetPayload, path string) error {
+		_, _, err := p.NetIP(path)
+		return err
+	})
+}
+
+func (s *PayloadSchema) add(path string, typ string, o order.Order, check func(p *KeysetPayload, path string) error) *PayloadSchema {
+	s.fields = append(s.fields, payloadSchemaField{path: path, typ: typ, order: o, check: check})
+	return s
+}
+
+// Validate checks that p holds exactly the fields of s, in the same order
+// and with the declared types and orders. It returns an error wrapping
+// ErrUnexpectedKeysetField, ErrMissingKeysetField, ErrKeysetFieldType or
+// ErrKeysetFieldOrder for the first field that does not match.
+func (s *PayloadSchema) Validate(p *KeysetPayload) error {
+	vs := p.Values()
+	for i, v := range vs {
+		if i >= len(s.fields) || v.Path != s.fields[i].path {
+			return fmt.Errorf("%w: %q at position %d", ErrUnexpectedKeysetField, v.Path, i)
+		}
+
+		f := s.fields[i]
+		if err := f.check(p, f.path); err != nil {
+			return fmt.Errorf("%w: %q is not a %s: %w", ErrKeysetFieldType, f.path, f.typ, err)
+		}
+		if v.Order != f.order {
+			return fmt.Errorf("%w: %q is %s, expected %s", ErrKeysetFieldOrder, f.path, v.Order, f.order)
+		}
+	}
+
+	if len(vs) < len(s.fields) {
+		return fmt.Errorf("%w: %q", ErrMissingKeysetField, s.fields[len(vs)].path)
+	}
+
+	return nil
+}
+
+// WithPayloadSchema makes the reader reject page tokens whose keyset does
+// not match schema with an error wrapping ErrInvalidToken and the error of
+// PayloadSchema.Validate. Like WithExpectedKeysetColumns, tokens are checked
+// after their checksum and tokens without keyset values, such as those of
+// the first page, are not checked.
+func WithPayloadSchema(schema *PayloadSchema) RequestReaderOpt {
+	return func(rr *RequestReader) {
+		rr.payloadSchema = schema
+	}
+}
+
+func (r *RequestReader) checkPayloadSchema(t *KeysetToken) error {
+	if r.payloadSchema == nil || t.payload == nil || len(t.payload.vs) == 0 {
+		return nil
+	}
+
+	if err := r.payloadSchema.Validate(t.payload); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	return nil
+}
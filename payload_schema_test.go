@@ -0,0 +1,135 @@
+package pagetoken_test
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/encryption"
+	"github.com/pixlcrashr/go-pagetoken/order"
+)
+
+var _ = Describe("PayloadSchema", func() {
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	schema := func() *pagetoken.PayloadSchema {
+		return pagetoken.NewPayloadSchema().
+			Time("created_at", order.Desc).
+			Int64("id", order.Asc)
+	}
+
+	It("accepts a keyset matching the schema", func() {
+		p := pagetoken.NewKeysetPayloadBuilder().
+			AddTime("created_at", ts, order.Desc).
+			AddInt64("id", 42, order.Asc).
+			Build()
+
+		Expect(schema().Validate(p)).To(Succeed())
+	})
+
+	DescribeTable("rejects keysets not matching the schema with distinct errors",
+		func(p *pagetoken.KeysetPayload, want error, others ...error) {
+			err := schema().Validate(p)
+			Expect(err).To(MatchError(want))
+			for _, other := range others {
+				Expect(err).NotTo(MatchError(other))
+			}
+		},
+		Entry("an extra field",
+			pagetoken.NewKeysetPayloadBuilder().
+				AddTime("created_at", ts, order.Desc).
+				AddInt64("id", 42, order.Asc).
+				AddString("tenant", "acme", order.Asc).
+				Build(),
+			pagetoken.ErrUnexpectedKeysetField,
+			pagetoken.ErrMissingKeysetField, pagetoken.ErrKeysetFieldType, pagetoken.ErrKeysetFieldOrder),
+		Entry("another field in place of a declared one",
+			pagetoken.NewKeysetPayloadBuilder().
+				AddTime("created_at", ts, order.Desc).
+				AddString("name", "a", order.Asc).
+				Build(),
+			pagetoken.ErrUnexpectedKeysetField),
+		Entry("a missing field",
+			pagetoken.NewKeysetPayloadBuilder().
+				AddTime("created_at", ts, order.Desc).
+				Build(),
+			pagetoken.ErrMissingKeysetField,
+			pagetoken.ErrUnexpectedKeysetField, pagetoken.ErrKeysetFieldType, pagetoken.ErrKeysetFieldOrder),
+		Entry("a wrong type",
+			pagetoken.NewKeysetPayloadBuilder().
+				AddTime("created_at", ts, order.Desc).
+				AddString("id", "abc", order.Asc).
+				Build(),
+			pagetoken.ErrKeysetFieldType,
+			pagetoken.ErrUnexpectedKeysetField, pagetoken.ErrMissingKeysetField, pagetoken.ErrKeysetFieldOrder),
+		Entry("a wrong order",
+			pagetoken.NewKeysetPayloadBuilder().
+				AddTime("created_at", ts, order.Asc).
+				AddInt64("id", 42, order.Asc).
+				Build(),
+			pagetoken.ErrKeysetFieldOrder,
+			pagetoken.ErrUnexpectedKeysetField, pagetoken.ErrMissingKeysetField, pagetoken.ErrKeysetFieldType),
+	)
+
+	It("checks custom types", func() {
+		s := pagetoken.NewPayloadSchema().Field("sku", "sku", order.Asc, func(v string) error {
+			if !strings.HasPrefix(v, "SKU-") {
+				return errors.New("missing prefix")
+			}
+			return nil
+		})
+
+		valid := pagetoken.NewKeysetPayloadBuilder().AddString("sku", "SKU-1", order.Asc).Build()
+		Expect(s.Validate(valid)).To(Succeed())
+
+		invalid := pagetoken.NewKeysetPayloadBuilder().AddString("sku", "1", order.Asc).Build()
+		Expect(s.Validate(invalid)).To(MatchError(pagetoken.ErrKeysetFieldType))
+	})
+
+	Describe("WithPayloadSchema", func() {
+		var rr *pagetoken.RequestReader
+
+		BeforeEach(func() {
+			key, err := encryption.Rand32ByteKey()
+			Expect(err).NotTo(HaveOccurred())
+			e, err := encryption.NewAEADEncryptor(key)
+			Expect(err).NotTo(HaveOccurred())
+			rr = pagetoken.NewRequestReader(pagetoken.WithEncryptor(e), pagetoken.WithPayloadSchema(schema()))
+		})
+
+		issue := func(b *pagetoken.KeysetPayloadBuilder) string {
+			t, err := rr.Read(filterRequest{status: "active"})
+			Expect(err).NotTo(HaveOccurred())
+			s, err := t.Next(pagetoken.WithKeysetPayload(b.Build())).String()
+			Expect(err).NotTo(HaveOccurred())
+			return s
+		}
+
+		It("accepts the first page and tokens matching the schema", func() {
+			_, err := rr.Read(filterRequest{status: "active"})
+			Expect(err).NotTo(HaveOccurred())
+
+			token := issue(pagetoken.NewKeysetPayloadBuilder().
+				AddTime("created_at", ts, order.Desc).
+				AddInt64("id", 42, order.Asc))
+			_, err = rr.Read(filterRequest{status: "active", token: token})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("rejects tokens not matching the schema as invalid", func() {
+			token := issue(pagetoken.NewKeysetPayloadBuilder().
+				AddTime("created_at", ts, order.Desc).
+				AddString("id", "1 OR 1=1", order.Asc))
+
+			_, err := rr.Read(filterRequest{status: "active", token: token})
+			Expect(err).To(MatchError(pagetoken.ErrInvalidToken))
+			Expect(err).To(MatchError(pagetoken.ErrKeysetFieldType))
+			Expect(pagetoken.HTTPStatus(err)).To(Equal(http.StatusBadRequest))
+		})
+	})
+})
@@ -0,0 +1,84 @@
+package pagetoken
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/pixlcrashr/go-pagetoken/order"
+)
+
+// ErrInvalidPayloadValues is returned by PayloadFromValues for parameters
+// not following the value:order syntax.
+var ErrInvalidPayloadValues = errors.New("invalid keyset payload values")
+
+// PayloadFromValues builds a payload from the parameters of v whose names
+// start with prefix, e.g. for debugging and admin endpoints or for testing
+// repositories with curl. The rest of the name is the path of the field and
+// the value is the value of the field followed by a colon and its order:
+//
+//	?k.created_at=2024-01-01T00:00:00Z:desc&k.id=abc:asc
+//
+// yields the fields created_at and id for the prefix "k.". The value is
+// split at its last colon, so values may contain colons without escaping.
+// Paths must pass ValidatePath and orders must be "asc" or "desc"; a
+// parameter given more than once is rejected, as is a missing order.
+// Errors wrap ErrInvalidPayloadValues or ErrInvalidPath.
+//
+// url.Values does not preserve the order of the parameters, so the fields
+// are added in lexical order of their paths. Since database adapters
+// compare the fields in payload order, this only matches the sort order of
+// a listing if its paths sort the same way.
+func PayloadFromValues(v url.Values, prefix string) (*KeysetPayload, error) {
+	paths := []string{}
+	for name := range v {
+		if path, ok := strings.CutPrefix(name, prefix); ok {
+			paths = append(paths, path)
+		}
+	}
+	slices.Sort(paths)
+
+	b := NewKeysetPayloadBuilder()
+	for _, path := range paths {
+		if err := ValidatePath(path); err != nil {
+			return nil, err
+		}
+
+		vs := v[prefix+path]
+		if len(vs) != 1 {
+			return nil, fmt.Errorf("%w: %q: %d values", ErrInvalidPayloadValues, path, len(vs))
+		}
+
+		i := strings.LastIndexByte(vs[0], ':')
+		if i < 0 {
+			return nil, fmt.Errorf("%w: %q: missing order", ErrInvalidPayloadValues, path)
+		}
+
+		var o order.Order
+		if err := o.UnmarshalString(vs[0][i+1:]); err != nil {
+			return nil, fmt.Errorf("%w: %q: %w", ErrInvalidPayloadValues, path, err)
+		}
+
+		b.append(path, vs[0][:i], o)
+	}
+
+	return b.Build(), nil
+}
+
+// PayloadToValues is the inverse of PayloadFromValues: it returns the
+// fields of p as parameters named prefix and their path. Sensitive values
+// are included as is and their mark is lost.
+func PayloadToValues(p *KeysetPayload, prefix string) url.Values {
+	v := url.Values{}
+	if p == nil {
+		return v
+	}
+
+	for _, kv := range p.vs {
+		v.Add(prefix+kv.Path, kv.Value+":"+kv.Order.String())
+	}
+
+	return v
+}
@@ -0,0 +1,81 @@
+package pagetoken_test
+
+import (
+	"net/url"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/order"
+)
+
+var _ = Describe("Payloads from url.Values", func() {
+	parse := func(query string) (*pagetoken.KeysetPayload, error) {
+		v, err := url.ParseQuery(query)
+		Expect(err).NotTo(HaveOccurred())
+		return pagetoken.PayloadFromValues(v, "k.")
+	}
+
+	It("builds the fields of the prefixed parameters", func() {
+		p, err := parse("k.created_at=2024-01-01T00:00:00Z:desc&k.id=abc:asc&page_size=10")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(p.Values()).To(Equal([]pagetoken.KeysetValue{
+			{Path: "created_at", Value: "2024-01-01T00:00:00Z", Order: order.Desc},
+			{Path: "id", Value: "abc", Order: order.Asc},
+		}))
+
+		t, o, err := p.Time("created_at")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(t).To(Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)))
+		Expect(o).To(Equal(order.Desc))
+	})
+
+	DescribeTable("splits values at their last colon",
+		func(query string, value string) {
+			p, err := parse(query)
+			Expect(err).NotTo(HaveOccurred())
+
+			v, _, err := p.String("name")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(v).To(Equal(value))
+		},
+		Entry("colons inside the value", "k.name=a:b:c:asc", "a:b:c"),
+		Entry("a trailing colon", "k.name=a::asc", "a:"),
+		Entry("an escaped colon", "k.name=a%3Ab%3Adesc", "a:b"),
+		Entry("an empty value", "k.name=:asc", ""),
+	)
+
+	DescribeTable("rejects malformed entries",
+		func(query string, want error) {
+			_, err := parse(query)
+			Expect(err).To(MatchError(want))
+		},
+		Entry("a missing order", "k.id=42", pagetoken.ErrInvalidPayloadValues),
+		Entry("an unknown order", "k.id=42:up", pagetoken.ErrInvalidPayloadValues),
+		Entry("a numeric order", "k.id=42:1", pagetoken.ErrInvalidPayloadValues),
+		Entry("a repeated parameter", "k.id=1:asc&k.id=2:asc", pagetoken.ErrInvalidPayloadValues),
+		Entry("an invalid path", "k.id%20OR%201=42:asc", pagetoken.ErrInvalidPath),
+		Entry("an empty path", "k.=42:asc", pagetoken.ErrInvalidPath),
+	)
+
+	It("round-trips payloads through PayloadToValues", func() {
+		p := pagetoken.NewKeysetPayloadBuilder().
+			AddString("author.name", "a:b", order.Desc).
+			AddInt("id", 42, order.Asc).
+			Build()
+
+		v := pagetoken.PayloadToValues(p, "k.")
+		Expect(v.Encode()).To(Equal("k.author.name=a%3Ab%3Adesc&k.id=42%3Aasc"))
+
+		got, err := pagetoken.PayloadFromValues(v, "k.")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(got.Values()).To(Equal(p.Values()))
+	})
+
+	It("returns no parameters for a nil payload", func() {
+		Expect(pagetoken.PayloadToValues(nil, "k.")).To(BeEmpty())
+	})
+})
@@ -0,0 +1,55 @@
+package pagetoken
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrNoTokenMetadata is returned by Peek for legacy tokens, which carry no
+// metadata but their checksum.
+var ErrNoTokenMetadata = errors.New("page token has no metadata")
+
+// keysetTokenV1Header is the metadata of a versioned token plaintext, see
+// keysetTokenV1Body.
+type keysetTokenV1Header struct {
+	Checksum  uint32 `json:"c"`
+	PageIndex int    `json:"i,omitempty"`
+	IssuedAt  int64  `json:"t,omitempty"`
+}
+
+// Peek decrypts token and returns its metadata without decoding its keyset
+// or verifying it against a request, e.g. for routers and rate limiters
+// running before the request is built. The keyset values are never
+// exposed. A token accepted by Peek may still be rejected by Parse or by a
+// RequestReader.
+//
+// Peek fails with ErrInvalidToken for tokens that cannot be decrypted and
+// with ErrNoTokenMetadata for legacy tokens.
+func (p *KeysetTokenParser) Peek(token string) (TokenInfo, error) {
+	d, err := p.decrypt(token)
+	if err != nil {
+		return TokenInfo{}, err
+	}
+
+	if len(d) == 0 || d[0] != keysetTokenV1 {
+		return TokenInfo{}, ErrNoTokenMetadata
+	}
+
+	var h keysetTokenV1Header
+	if err := json.Unmarshal(d[1:], &h); err != nil {
+		return TokenInfo{}, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	info := TokenInfo{
+		Version:   int(keysetTokenV1),
+		PageIndex: h.PageIndex,
+		Checksum:  h.Checksum,
+	}
+	if h.IssuedAt != 0 {
+		info.IssuedAt = time.Unix(h.IssuedAt, 0).UTC()
+	}
+
+	return info, nil
+}
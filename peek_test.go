@@ -0,0 +1,93 @@
+package pagetoken_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/encryption"
+	"github.com/pixlcrashr/go-pagetoken/internal/golden"
+	"github.com/pixlcrashr/go-pagetoken/order"
+	"github.com/pixlcrashr/go-pagetoken/pagetokentest"
+)
+
+var _ = Describe("Peek", func() {
+	var (
+		e      encryption.Crypter
+		parser *pagetoken.KeysetTokenParser
+	)
+
+	BeforeEach(func() {
+		key, err := encryption.Rand32ByteKey()
+		Expect(err).NotTo(HaveOccurred())
+		e, err = encryption.NewAEADEncryptor(key)
+		Expect(err).NotTo(HaveOccurred())
+		parser = pagetoken.NewKeysetTokenParser(pagetoken.WithKeysetTokenEncryptor(e))
+	})
+
+	It("agrees with a full Parse", func() {
+		at := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+		payload := pagetoken.NewKeysetPayloadBuilder().AddInt("id", 42, order.Asc).Build()
+		t := pagetoken.NewKeysetToken(e, pagetoken.WithKeysetPayload(payload), pagetoken.WithIssueClock(func() time.Time { return at })).
+			Next(pagetoken.WithChecksum(1234)).
+			Next()
+		s, err := t.String()
+		Expect(err).NotTo(HaveOccurred())
+
+		info, err := parser.Peek(s)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info).To(Equal(pagetoken.TokenInfo{Version: 1, IssuedAt: at, PageIndex: 2, Checksum: 1234}))
+
+		parsed, err := parser.Parse(s)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(parsed.Info()).To(Equal(info))
+	})
+
+	It("agrees with a full Parse of the golden fixtures", func() {
+		fixtures, err := golden.Load("testdata/golden")
+		Expect(err).NotTo(HaveOccurred())
+
+		parser := pagetoken.NewKeysetTokenParser(pagetoken.WithKeysetTokenEncryptor(pagetokentest.StaticCrypter{}))
+		for _, f := range fixtures {
+			info, err := parser.Peek(f.Tokens.Static)
+			if f.Version == 0 {
+				Expect(err).To(MatchError(pagetoken.ErrNoTokenMetadata))
+				continue
+			}
+			Expect(err).NotTo(HaveOccurred())
+
+			t, err := parser.Parse(f.Tokens.Static)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info).To(Equal(t.Info()))
+		}
+	})
+
+	It("fails on legacy tokens lacking metadata", func() {
+		s, err := e.Encrypt([]byte(`["id","42","asc","0"]`))
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = parser.Peek(s)
+		Expect(err).To(MatchError(pagetoken.ErrNoTokenMetadata))
+		Expect(err).NotTo(MatchError(pagetoken.ErrInvalidToken))
+
+		t, err := parser.Parse(s)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(t.Info().Version).To(BeZero())
+	})
+
+	It("fails on tokens that cannot be decrypted", func() {
+		_, err := parser.Peek("not-a-token")
+		Expect(err).To(MatchError(pagetoken.ErrInvalidToken))
+	})
+
+	It("strips the token prefix", func() {
+		s, err := pagetoken.NewKeysetToken(e, pagetoken.WithStringPrefix("pt_")).String()
+		Expect(err).NotTo(HaveOccurred())
+
+		info, err := pagetoken.NewKeysetTokenParser(pagetoken.WithKeysetTokenEncryptor(e), pagetoken.WithKeysetTokenPrefix("pt_")).Peek(s)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Version).To(Equal(1))
+	})
+})
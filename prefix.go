@@ -0,0 +1,58 @@
+package pagetoken
+
+import (
+	"regexp"
+	"strings"
+)
+
+// DefaultTokenPrefix is the tag WithTokenPrefix is meant to be used with
+// and ScrubString recognizes. The digit is the version of the token string
+// format, so that later formats can be told apart by their tag.
+const DefaultTokenPrefix = "pt1_"
+
+// WithTokenPrefix makes the reader tag the tokens it issues with prefix,
+// e.g. DefaultTokenPrefix, so that secret scanners and log scrubbers can
+// recognize them:
+//
+//	pt1_AbCdEf...
+//
+// The prefix is stripped before a token is decrypted. Untagged tokens,
+// e.g. those issued before the option was enabled, are still accepted, so
+// enabling it needs no migration. The prefix counts towards the maximum
+// token length of Middleware, but is exempt from its character check, so it
+// may contain characters outside the base64 alphabet, e.g. "pt:".
+func WithTokenPrefix(prefix string) RequestReaderOpt {
+	return func(rr *RequestReader) {
+		rr.prefix = prefix
+	}
+}
+
+var defaultTokenPattern = tokenPattern(DefaultTokenPrefix)
+
+func tokenPattern(prefix string) *regexp.Regexp {
+	return regexp.MustCompile(regexp.QuoteMeta(prefix) + `[A-Za-z0-9_-]+=*`)
+}
+
+// ScrubString replaces all page tokens tagged with DefaultTokenPrefix in s
+// by the prefix followed by RedactedValue, e.g. before s is logged:
+//
+//	GET /users?page_token=pt1_AbCdEf... -> GET /users?page_token=pt1_[redacted]
+//
+// Untagged tokens cannot be told apart from other base64 data and are left
+// as is.
+func ScrubString(s string) string {
+	if !strings.Contains(s, DefaultTokenPrefix) {
+		return s
+	}
+
+	return defaultTokenPattern.ReplaceAllLiteralString(s, DefaultTokenPrefix+RedactedValue)
+}
+
+// ScrubStringWithPrefix is like ScrubString for tokens tagged with prefix.
+func ScrubStringWithPrefix(s, prefix string) string {
+	if prefix == "" || !strings.Contains(s, prefix) {
+		return s
+	}
+
+	return tokenPattern(prefix).ReplaceAllLiteralString(s, prefix+RedactedValue)
+}
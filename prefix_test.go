@@ -0,0 +1,124 @@
+package pagetoken_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/encryption"
+	"github.com/pixlcrashr/go-pagetoken/order"
+)
+
+var _ = Describe("WithTokenPrefix", func() {
+	var (
+		e      *encryption.AEADEncryptor
+		tagged *pagetoken.RequestReader
+	)
+
+	BeforeEach(func() {
+		key, err := encryption.Rand32ByteKey()
+		Expect(err).NotTo(HaveOccurred())
+		e, err = encryption.NewAEADEncryptor(key)
+		Expect(err).NotTo(HaveOccurred())
+		tagged = pagetoken.NewRequestReader(pagetoken.WithEncryptor(e), pagetoken.WithTokenPrefix(pagetoken.DefaultTokenPrefix))
+	})
+
+	next := func(rr *pagetoken.RequestReader, token string) string {
+		t, err := rr.Read(filterRequest{status: "active", token: token})
+		Expect(err).NotTo(HaveOccurred())
+		s, err := t.Next().String()
+		Expect(err).NotTo(HaveOccurred())
+		return s
+	}
+
+	It("tags issued tokens and strips the tag when reading them", func() {
+		s := next(tagged, "")
+		Expect(s).To(HavePrefix("pt1_"))
+
+		s = next(tagged, s)
+		Expect(s).To(HavePrefix("pt1_"))
+		Expect(strings.Count(s, "pt1_")).To(Equal(1))
+	})
+
+	It("still reads untagged tokens issued before the prefix was enabled", func() {
+		untagged := next(pagetoken.NewRequestReader(pagetoken.WithEncryptor(e)), "")
+		Expect(untagged).NotTo(HavePrefix("pt1_"))
+
+		Expect(next(tagged, untagged)).To(HavePrefix("pt1_"))
+	})
+
+	It("rejects tokens with a tampered tag", func() {
+		s := next(tagged, "")
+		_, err := tagged.Read(filterRequest{status: "active", token: "pt2_" + strings.TrimPrefix(s, "pt1_")})
+		Expect(err).To(MatchError(pagetoken.ErrInvalidToken))
+	})
+
+	It("tags item cursors and passes Middleware", func() {
+		t, err := tagged.Read(filterRequest{status: "active"})
+		Expect(err).NotTo(HaveOccurred())
+		cs, err := pagetoken.ItemCursors(t, []*pagetoken.KeysetPayload{
+			pagetoken.NewKeysetPayloadBuilder().AddInt("id", 1, order.Asc).Build(),
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cs[0]).To(HavePrefix("pt1_"))
+
+		w := httptest.NewRecorder()
+		pagetoken.Middleware(tagged)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, err := tagged.ReadContext(r.Context(), filterRequest{status: "active", token: cs[0]})
+			Expect(err).NotTo(HaveOccurred())
+		})).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users?page_token="+cs[0], nil))
+		Expect(w.Code).To(Equal(http.StatusOK))
+	})
+
+	It("passes Middleware with a prefix outside the base64 alphabet", func() {
+		rr := pagetoken.NewRequestReader(pagetoken.WithEncryptor(e), pagetoken.WithTokenPrefix("pt:"))
+		s := next(rr, "")
+		Expect(s).To(HavePrefix("pt:"))
+
+		serve := func(token string) int {
+			w := httptest.NewRecorder()
+			pagetoken.Middleware(rr)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, err := rr.ReadContext(r.Context(), filterRequest{status: "active", token: token})
+				Expect(err).NotTo(HaveOccurred())
+			})).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users?page_token="+token, nil))
+			return w.Code
+		}
+		Expect(serve(s)).To(Equal(http.StatusOK))
+		Expect(serve(s + ":")).To(Equal(http.StatusBadRequest))
+	})
+})
+
+var _ = Describe("ScrubString", func() {
+	DescribeTable("redacts tagged tokens in mixed content",
+		func(in, out string) {
+			Expect(pagetoken.ScrubString(in)).To(Equal(out))
+		},
+		Entry("query string",
+			`GET /users?status=active&page_token=pt1_AbC-d_E09 200 12ms`,
+			`GET /users?status=active&page_token=pt1_[redacted] 200 12ms`,
+		),
+		Entry("several tokens and padding in JSON",
+			`{"next":"pt1_bmV4dA==","prev":"pt1_cHJldg=","msg":"ok"}`,
+			`{"next":"pt1_[redacted]","prev":"pt1_[redacted]","msg":"ok"}`,
+		),
+		Entry("untagged base64 and text",
+			`token=bmV4dA user=pt1 path=/pt1_`,
+			`token=bmV4dA user=pt1 path=/pt1_`,
+		),
+		Entry("already scrubbed lines",
+			`page_token=pt1_[redacted]`,
+			`page_token=pt1_[redacted]`,
+		),
+	)
+
+	It("scrubs custom prefixes", func() {
+		Expect(pagetoken.ScrubStringWithPrefix("cursor=acme.v1.AbC_d-9 done", "acme.v1.")).
+			To(Equal("cursor=acme.v1.[redacted] done"))
+		Expect(pagetoken.ScrubStringWithPrefix("cursor=acmexv1xAbC", "acme.v1.")).
+			To(Equal("cursor=acmexv1xAbC"))
+	})
+})
@@ -0,0 +1,107 @@
+package pagetoken
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// ProblemContentType is the media type of RFC 7807 problem details.
+const ProblemContentType = "application/problem+json"
+
+// Problem type URIs of the error classes of this package. They are stable
+// identifiers for clients to match on, not locations to dereference.
+const (
+	ProblemTypeInvalidToken     = "urn:pagetoken:problem:invalid-token"
+	ProblemTypeChecksumMismatch = "urn:pagetoken:problem:checksum-mismatch"
+	ProblemTypeExpired          = "urn:pagetoken:problem:expired"
+	ProblemTypeScopeMismatch    = "urn:pagetoken:problem:scope-mismatch"
+	ProblemTypeInvalidPageSize  = "urn:pagetoken:problem:invalid-page-size"
+	ProblemTypeRevoked          = "urn:pagetoken:problem:revoked"
+	ProblemTypeStale            = "urn:pagetoken:problem:stale"
+)
+
+// ProblemDetails is an RFC 7807 problem details document.
+type ProblemDetails struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+}
+
+// Problem maps an error returned by this package to problem details with
+// the type URI of its error class and the status of HTTPStatus. The detail
+// tells the client how to recover; it never includes the token or the
+// underlying error message. Errors of no class map to a generic
+// "about:blank" problem with status 500.
+func Problem(err error) ProblemDetails {
+	switch {
+	case errors.Is(err, ErrTokenExpired):
+		return ProblemDetails{
+			Type:   ProblemTypeExpired,
+			Title:  "Page token expired",
+			Status: HTTPStatus(err),
+			Detail: "The page token is too old to be continued. Restart pagination without a page token.",
+		}
+	case errors.Is(err, ErrTokenRevoked):
+		return ProblemDetails{
+			Type:   ProblemTypeRevoked,
+			Title:  "Page token revoked",
+			Status: HTTPStatus(err),
+			Detail: "The page token is no longer valid. Restart pagination without a page token.",
+		}
+	case errors.Is(err, ErrStaleToken):
+		return ProblemDetails{
+			Type:   ProblemTypeStale,
+			Title:  "Page token stale",
+			Status: HTTPStatus(err),
+			Detail: "The sort order changed since the page token was issued. Restart pagination without a page token.",
+		}
+	case errors.Is(err, ErrScopeMismatch):
+		return ProblemDetails{
+			Type:   ProblemTypeScopeMismatch,
+			Title:  "Page token scope mismatch",
+			Status: HTTPStatus(err),
+			Detail: "The page token was issued for another resource. Use page tokens only with the resource that issued them.",
+		}
+	case errors.Is(err, ErrChecksumMismatch):
+		return ProblemDetails{
+			Type:   ProblemTypeChecksumMismatch,
+			Title:  "Page token does not match the request",
+			Status: HTTPStatus(err),
+			Detail: "The request parameters changed since the page token was issued. Repeat the parameters of the first page or restart pagination without a page token.",
+		}
+	case errors.Is(err, ErrInvalidToken):
+		return ProblemDetails{
+			Type:   ProblemTypeInvalidToken,
+			Title:  "Invalid page token",
+			Status: HTTPStatus(err),
+			Detail: "The page token is malformed or was not issued by this service. Pass page tokens back unmodified.",
+		}
+	case errors.Is(err, ErrInvalidPageSize):
+		return ProblemDetails{
+			Type:   ProblemTypeInvalidPageSize,
+			Title:  "Invalid page size",
+			Status: HTTPStatus(err),
+			Detail: "The page size must not be negative. Omit it or pass zero for the default page size.",
+		}
+	default:
+		return ProblemDetails{
+			Type:   "about:blank",
+			Title:  http.StatusText(http.StatusInternalServerError),
+			Status: http.StatusInternalServerError,
+		}
+	}
+}
+
+// WriteProblem writes the problem details of err, as returned by Problem, as
+// an application/problem+json response.
+func WriteProblem(w http.ResponseWriter, err error) {
+	p := Problem(err)
+
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(p.Status)
+	_ = json.NewEncoder(w).Encode(p)
+}
@@ -0,0 +1,73 @@
+package pagetoken_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken"
+)
+
+var _ = Describe("Problem", func() {
+	DescribeTable("writes the problem details of each error class",
+		func(err error, status int, body string) {
+			w := httptest.NewRecorder()
+			pagetoken.WriteProblem(w, err)
+
+			Expect(w.Code).To(Equal(status))
+			Expect(w.Header().Get("Content-Type")).To(Equal(pagetoken.ProblemContentType))
+			Expect(w.Body.String()).To(MatchJSON(body))
+		},
+		Entry("invalid token",
+			fmt.Errorf("%w: illegal base64 data at input byte 4", pagetoken.ErrInvalidToken),
+			http.StatusBadRequest,
+			`{"type":"urn:pagetoken:problem:invalid-token","title":"Invalid page token","status":400,"detail":"The page token is malformed or was not issued by this service. Pass page tokens back unmodified."}`,
+		),
+		Entry("checksum mismatch",
+			fmt.Errorf("%w (got 0x1 but expected 0x2)", pagetoken.ErrChecksumMismatch),
+			http.StatusBadRequest,
+			`{"type":"urn:pagetoken:problem:checksum-mismatch","title":"Page token does not match the request","status":400,"detail":"The request parameters changed since the page token was issued. Repeat the parameters of the first page or restart pagination without a page token."}`,
+		),
+		Entry("expired",
+			pagetoken.ErrTokenExpired,
+			http.StatusBadRequest,
+			`{"type":"urn:pagetoken:problem:expired","title":"Page token expired","status":400,"detail":"The page token is too old to be continued. Restart pagination without a page token."}`,
+		),
+		Entry("scope mismatch",
+			pagetoken.ErrScopeMismatch,
+			http.StatusBadRequest,
+			`{"type":"urn:pagetoken:problem:scope-mismatch","title":"Page token scope mismatch","status":400,"detail":"The page token was issued for another resource. Use page tokens only with the resource that issued them."}`,
+		),
+		Entry("invalid page size",
+			fmt.Errorf("%w: -1 is negative", pagetoken.ErrInvalidPageSize),
+			http.StatusBadRequest,
+			`{"type":"urn:pagetoken:problem:invalid-page-size","title":"Invalid page size","status":400,"detail":"The page size must not be negative. Omit it or pass zero for the default page size."}`,
+		),
+		Entry("revoked",
+			fmt.Errorf("%w: issued during incident window", pagetoken.ErrTokenRevoked),
+			http.StatusBadRequest,
+			`{"type":"urn:pagetoken:problem:revoked","title":"Page token revoked","status":400,"detail":"The page token is no longer valid. Restart pagination without a page token."}`,
+		),
+		Entry("stale",
+			fmt.Errorf("%w: keyset columns [created_at id], expected [updated_at id]", pagetoken.ErrStaleToken),
+			http.StatusBadRequest,
+			`{"type":"urn:pagetoken:problem:stale","title":"Page token stale","status":400,"detail":"The sort order changed since the page token was issued. Restart pagination without a page token."}`,
+		),
+		Entry("unknown errors",
+			errors.New("connection refused"),
+			http.StatusInternalServerError,
+			`{"type":"about:blank","title":"Internal Server Error","status":500}`,
+		),
+	)
+
+	It("never echoes the underlying error", func() {
+		const token = "c2VjcmV0LXRva2Vu"
+		p := pagetoken.Problem(fmt.Errorf("%w: cannot decode %q", pagetoken.ErrInvalidToken, token))
+		Expect(p.Detail).NotTo(ContainSubstring(token))
+		Expect(p.Title).NotTo(ContainSubstring(token))
+	})
+})
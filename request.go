@@ -1,10 +1,14 @@
 package pagetoken
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/pixlcrashr/go-pagetoken/checksum"
 	"github.com/pixlcrashr/go-pagetoken/encryption"
+	"github.com/pixlcrashr/go-pagetoken/internal/frozen"
 )
 
 // Request is an interface that must be implemented by all request types that
@@ -16,19 +20,72 @@ type Request interface {
 	GetPageToken() string
 }
 
+// RequestReader reads the page tokens of requests. It is safe for concurrent
+// use by multiple goroutines, so a single reader is meant to be shared by all
+// handlers. Its configuration must not be changed once it is in use, e.g. by
+// applying a RequestReaderOpt to it; builds with the pagetokendebug tag
+// panic if it is.
 type RequestReader struct {
-	e            encryption.Crypter
-	checksumOpts []checksum.BuilderOpt
+	e                encryption.Crypter
+	checksumOpts     []checksum.BuilderOpt
+	aip158           bool
+	defaultPageSize  int
+	minPageSize      int
+	maxPageSize      int
+	pageSizeChecksum pageSizeChecksum
+	softChecksum     bool
+	requireChecksum  bool
+	revocationCheck  RevocationCheckFn
+	keysetColumns    KeysetColumnsFn
+	payloadSchema    *PayloadSchema
+	fallbackKinds    uint
+	auditSink        AuditSink
+	auditIdentity    AuditIdentityFn
+	legacyDefaults   bool
+	onLegacyChecksum LegacyChecksumFn
+	prefix           string
+	decodeCache      *decodeCache
+	maxAge           time.Duration
+	now              func() time.Time
+	subject          SubjectFn
+	tokenIDs         bool
+	fingerprint      string
 }
 
 type RequestReaderOpt func(*RequestReader)
 
+// WithChecksumOpts adds opts to the checksum of every request, before the
+// checksum fields of the request itself, e.g. a field naming the endpoint
+// or tenant the reader serves.
 func WithChecksumOpts(opts ...checksum.BuilderOpt) RequestReaderOpt {
 	return func(rr *RequestReader) {
 		rr.checksumOpts = opts
 	}
 }
 
+// WithSoftChecksum makes Read and ReadContext restart the listing instead of
+// failing with ErrChecksumMismatch when a page token was issued for other
+// checksum fields, e.g. because the client changed a filter between pages:
+// they return the token of the first page of the request, whose
+// ChecksumMismatched reports the mismatch for handlers to log. Verify still
+// fails.
+func WithSoftChecksum() RequestReaderOpt {
+	return func(rr *RequestReader) {
+		rr.softChecksum = true
+	}
+}
+
+// WithRequireChecksumFields makes Read, ReadContext and Verify fail with
+// ErrNoChecksumFields for requests whose GetChecksumFields returns no
+// fields while the reader has no checksum fields configured via
+// WithChecksumOpts either. Without checksum fields, a token validates for
+// every request it is presented to, which is rarely intended.
+func WithRequireChecksumFields() RequestReaderOpt {
+	return func(rr *RequestReader) {
+		rr.requireChecksum = true
+	}
+}
+
 func WithEncryptor(e encryption.Crypter) RequestReaderOpt {
 	return func(rr *RequestReader) {
 		rr.e = e
@@ -38,63 +95,276 @@ func WithEncryptor(e encryption.Crypter) RequestReaderOpt {
 func NewRequestReader(
 	opts ...RequestReaderOpt,
 ) *RequestReader {
-	rr := &RequestReader{}
+	rr := &RequestReader{
+		defaultPageSize: DefaultPageSize,
+		minPageSize:     DefaultMinPageSize,
+		maxPageSize:     DefaultMaxPageSize,
+	}
 	for _, opt := range opts {
 		opt(rr)
 	}
+	if frozen.Enabled {
+		rr.fingerprint = rr.state()
+	}
 
 	// TODO: add defaults
 	return rr
 }
 
+// state returns a fingerprint of the configuration of r for frozen.Check.
+func (r *RequestReader) state() string {
+	return fmt.Sprintf("%T(%p) %p/%d %t %d %d %d %d %t %t %p %p %p %b %T(%p) %p %t %p %q %p %d %p %p %t",
+		r.e, r.e, r.checksumOpts, len(r.checksumOpts), r.aip158,
+		r.defaultPageSize, r.minPageSize, r.maxPageSize, r.pageSizeChecksum,
+		r.softChecksum, r.requireChecksum, r.revocationCheck, r.keysetColumns,
+		r.payloadSchema, r.fallbackKinds, r.auditSink, r.auditSink,
+		r.auditIdentity, r.legacyDefaults, r.onLegacyChecksum,
+		r.prefix, r.decodeCache, r.maxAge, r.now, r.subject, r.tokenIDs)
+}
+
+func (r *RequestReader) assertFrozen() {
+	if frozen.Enabled {
+		frozen.Check("RequestReader", r.fingerprint, r.state())
+	}
+}
+
 func (r *RequestReader) createChecksumBuilder(opts ...checksum.BuilderOpt) *checksum.Builder {
 	cb := checksum.NewBuilder(opts...)
 	return cb
 }
 
 func (r *RequestReader) Read(req Request) (*KeysetToken, error) {
+	return r.read(context.Background(), req)
+}
+
+// FromRequest reads the page token of req with a reader created for this
+// call from e and opts, see RequestReader.Read: it returns the token of the
+// first page for a request without a page token and verifies the checksum
+// of all others. Handlers reading many requests should share a
+// RequestReader instead.
+func FromRequest(e encryption.Crypter, req Request, opts ...RequestReaderOpt) (*KeysetToken, error) {
+	return NewRequestReader(append([]RequestReaderOpt{WithEncryptor(e)}, opts...)...).Read(req)
+}
+
+func (r *RequestReader) read(ctx context.Context, req Request) (*KeysetToken, error) {
+	r.assertFrozen()
+
+	n, err := r.PageSize(req)
+	if err != nil {
+		return nil, err
+	}
+
 	t := req.GetPageToken()
-	var c *KeysetToken
 
 	if t == "" {
-		// create a newly initialized cursor
-		c = &KeysetToken{}
-		cb := r.createChecksumBuilder()
+		return r.first(req, n)
+	}
 
-		for _, field := range req.GetChecksumFields() {
-			field(cb)
-		}
-		crc, err := cb.Build()
+	c, err := r.parse(t, req)
+	if err != nil {
+		return r.fallback(req, n, err)
+	}
+
+	if err := checkScope(c, req); err != nil {
+		return nil, err
+	}
+
+	if err := checkAge(c, r.maxAge, r.now); err != nil {
+		return r.fallback(req, n, err)
+	}
+
+	if err := r.checkRevoked(ctx, c); err != nil {
+		return nil, err
+	}
+
+	c, err = r.verify(c, req, n)
+	if err != nil {
+		return nil, err
+	}
+	r.consumed(ctx, t, c)
+
+	return c, nil
+}
+
+// first returns a newly initialized token for the first page of req.
+func (r *RequestReader) first(req Request, pageSize int) (*KeysetToken, error) {
+	crc, err := r.checksum(req)
+	if err != nil {
+		return nil, err
+	}
+
+	e, err := r.crypter(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &KeysetToken{
+		checksum: crc,
+		e:        e,
+		payload:  &KeysetPayload{},
+		prefix:   r.prefix,
+		now:      r.now,
+		pageSize: pageSize,
+		scope:    scopeOf(req),
+		ids:      r.tokenIDs,
+	}, nil
+}
+
+// verify returns c if it was issued for req, and the token of the first
+// page of req if it was not and the checksum is soft. The keyset columns of
+// c are checked once its checksum matches. Failed checks fall back to the
+// first page as configured by WithFallbackToFirstPage.
+func (r *RequestReader) verify(c *KeysetToken, req Request, pageSize int) (*KeysetToken, error) {
+	err := r.Verify(c, req)
+	if r.softChecksum && errors.Is(err, ErrChecksumMismatch) {
+		t, err := r.first(req, pageSize)
 		if err != nil {
 			return nil, err
 		}
-		c.checksum = crc
-		c.e = r.e
-		c.payload = &KeysetPayload{}
-		return c, nil
+		t.checksumMismatched = true
+		return t, nil
+	}
+	if err != nil {
+		return r.fallback(req, pageSize, err)
+	}
+
+	if err := r.checkKeysetColumns(c, req); err != nil {
+		return r.fallback(req, pageSize, err)
+	}
+
+	if err := r.checkPayloadSchema(c); err != nil {
+		return r.fallback(req, pageSize, err)
 	}
 
-	p := NewKeysetTokenParser(WithKeysetTokenEncryptor(r.e))
-	c, err := p.Parse(t)
+	c.pageSize = pageSize
+	c.ids = r.tokenIDs
+	c.now = r.now
+	return c, nil
+}
+
+// ReadContext is like Read, but uses the page token stored in ctx by
+// Middleware instead of decrypting the token of req again. Without a stored
+// token it falls back to Read. ctx is passed to the revocation check.
+func (r *RequestReader) ReadContext(ctx context.Context, req Request) (*KeysetToken, error) {
+	r.assertFrozen()
+
+	c, ok := FromContext(ctx)
+	if !ok {
+		return r.read(ctx, req)
+	}
+
+	n, err := r.PageSize(req)
 	if err != nil {
 		return nil, err
 	}
 
-	// verify request checksum with page token checksum
-	cb := r.createChecksumBuilder()
-	for _, field := range req.GetChecksumFields() {
-		field(cb)
+	if err := checkScope(c, req); err != nil {
+		return nil, err
+	}
+
+	if err := checkAge(c, r.maxAge, r.now); err != nil {
+		return r.fallback(req, n, err)
+	}
+
+	if err := r.checkRevoked(ctx, c); err != nil {
+		return nil, err
+	}
+
+	c, err = r.verify(c, req, n)
+	if err != nil {
+		return nil, err
 	}
+	r.consumed(ctx, req.GetPageToken(), c)
+
+	return c, nil
+}
+
+// Verify checks that the page token t was issued for a request with the
+// checksum fields of req. It returns a *ChecksumMismatchError, which wraps
+// ErrChecksumMismatch, otherwise.
+func (r *RequestReader) Verify(t *KeysetToken, req Request) error {
+	r.assertFrozen()
+
+	cb, err := r.checksumBuilder(req)
+	if err != nil {
+		return err
+	}
+
 	crc, err := cb.Build()
+	if err != nil {
+		return err
+	}
+
+	if crc != t.checksum {
+		if ok, err := r.verifyLegacyDefaults(t, cb); ok || err != nil {
+			return err
+		}
+
+		return &ChecksumMismatchError{Got: crc, Want: t.checksum}
+	}
+
+	return nil
+}
+
+func (r *RequestReader) parse(token string, req Request) (*KeysetToken, error) {
+	e, key := r.e, token
+	if r.subject != nil {
+		subject := r.subject(req)
+
+		var err error
+		if e, err = r.bind(subject); err != nil {
+			return nil, err
+		}
+		key = subjectCacheKey(subject, token)
+	}
+
+	if r.decodeCache == nil {
+		return r.decode(token, e)
+	}
+
+	// the generation is taken before decoding, so that a token decoded
+	// with keys replaced in the meantime is not cached
+	gen := generation(r.e)
+	if t, ok := r.decodeCache.get(key, gen); ok {
+		return t, nil
+	}
+
+	t, err := r.decode(token, e)
 	if err != nil {
 		return nil, err
 	}
+	r.decodeCache.add(key, gen, t)
 
-	if crc != c.checksum {
-		return nil, fmt.Errorf(
-			"checksum mismatch (got 0x%x but expected 0x%x)", c.checksum, crc,
-		)
+	return t, nil
+}
+
+func (r *RequestReader) decode(token string, e encryption.Crypter) (*KeysetToken, error) {
+	return NewKeysetTokenParser(
+		WithKeysetTokenEncryptor(e),
+		WithKeysetTokenPrefix(r.prefix),
+	).Parse(token)
+}
+
+func (r *RequestReader) checksum(req Request) (uint32, error) {
+	cb, err := r.checksumBuilder(req)
+	if err != nil {
+		return 0, err
 	}
 
-	return c, nil
+	return cb.Build()
+}
+
+func (r *RequestReader) checksumBuilder(req Request) (*checksum.Builder, error) {
+	cb := r.createChecksumBuilder(r.checksumOpts...)
+	for _, field := range req.GetChecksumFields() {
+		field(cb)
+	}
+	if r.requireChecksum && cb.Len() == 0 {
+		return nil, fmt.Errorf("%w: %T", ErrNoChecksumFields, req)
+	}
+	if err := r.pageSizeChecksumField(cb, req); err != nil {
+		return nil, err
+	}
+
+	return cb, nil
 }
@@ -1,9 +1,364 @@
 package pagetoken_test
 
 import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
 	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/checksum"
+	"github.com/pixlcrashr/go-pagetoken/encryption"
+	"github.com/pixlcrashr/go-pagetoken/order"
 )
 
+type filterRequest struct {
+	status string
+	token  string
+}
+
+func (r filterRequest) GetChecksumFields() []checksum.BuilderOpt {
+	return []checksum.BuilderOpt{checksum.Field("status", r.status)}
+}
+
+func (r filterRequest) GetPageToken() string {
+	return r.token
+}
+
+var _ = Describe("FromRequest", func() {
+	var e encryption.Crypter
+
+	BeforeEach(func() {
+		key, err := encryption.Rand32ByteKey()
+		Expect(err).NotTo(HaveOccurred())
+		e, err = encryption.NewAEADEncryptor(key)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("returns the token of the first page for a request without a token", func() {
+		t, err := pagetoken.FromRequest(e, filterRequest{status: "active"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(t.Payload().Values()).To(BeEmpty())
+		Expect(t.PageIndex()).To(BeZero())
+	})
+
+	It("continues from a token issued for the same parameters", func() {
+		first, err := pagetoken.FromRequest(e, filterRequest{status: "active"})
+		Expect(err).NotTo(HaveOccurred())
+		s, err := first.Next(pagetoken.WithKeysetPayload(
+			pagetoken.NewKeysetPayloadBuilder().AddInt("id", 42, order.Asc).Build(),
+		)).String()
+		Expect(err).NotTo(HaveOccurred())
+
+		t, err := pagetoken.FromRequest(e, filterRequest{status: "active", token: s})
+		Expect(err).NotTo(HaveOccurred())
+		id, _, err := t.Payload().Int("id")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(id).To(Equal(42))
+		Expect(t.PageIndex()).To(Equal(1))
+	})
+
+	It("rejects a token issued for other parameters", func() {
+		first, err := pagetoken.FromRequest(e, filterRequest{status: "active"})
+		Expect(err).NotTo(HaveOccurred())
+		s, err := first.Next().String()
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = pagetoken.FromRequest(e, filterRequest{status: "archived", token: s})
+		Expect(err).To(MatchError(pagetoken.ErrChecksumMismatch))
+
+		t, err := pagetoken.FromRequest(e, filterRequest{status: "archived", token: s}, pagetoken.WithSoftChecksum())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(t.ChecksumMismatched()).To(BeTrue())
+	})
+
+	It("continues from a token issued before a key rotation", func() {
+		ring := encryption.NewKeyRing()
+		Expect(ring.AddKey("a", encryption.MustRandKey(32))).To(Succeed())
+
+		first, err := pagetoken.FromRequest(ring, filterRequest{status: "active"})
+		Expect(err).NotTo(HaveOccurred())
+		s, err := first.Next().String()
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(ring.AddKey("b", encryption.MustRandKey(32))).To(Succeed())
+		Expect(ring.SetPrimary("b")).To(Succeed())
+
+		t, err := pagetoken.FromRequest(ring, filterRequest{status: "active", token: s})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(t.PageIndex()).To(Equal(1))
+	})
+
+	It("continues from a signed token and rejects tampered ones", func() {
+		signer, err := encryption.NewSignerEncryptor(encryption.MustRandKey(32))
+		Expect(err).NotTo(HaveOccurred())
+
+		first, err := pagetoken.FromRequest(signer, filterRequest{status: "active"})
+		Expect(err).NotTo(HaveOccurred())
+		s, err := first.Next().String()
+		Expect(err).NotTo(HaveOccurred())
+
+		t, err := pagetoken.FromRequest(signer, filterRequest{status: "active", token: s})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(t.PageIndex()).To(Equal(1))
+
+		payload, sig, _ := strings.Cut(s, ".")
+		d, err := base64.RawURLEncoding.DecodeString(payload)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(d).To(ContainSubstring(`"i":1`))
+		d = bytes.Replace(d, []byte(`"i":1`), []byte(`"i":9`), 1)
+		forged := base64.RawURLEncoding.EncodeToString(d) + "." + sig
+
+		_, err = pagetoken.FromRequest(signer, filterRequest{status: "active", token: forged})
+		Expect(err).To(MatchError(pagetoken.ErrInvalidToken))
+		Expect(err).To(MatchError(encryption.ErrInvalidSignature))
+	})
+})
+
 var _ = Describe("Request", func() {
+	var rr *pagetoken.RequestReader
+
+	BeforeEach(func() {
+		key, err := encryption.Rand32ByteKey()
+		Expect(err).NotTo(HaveOccurred())
+		e, err := encryption.NewAEADEncryptor(key)
+		Expect(err).NotTo(HaveOccurred())
+		rr = pagetoken.NewRequestReader(pagetoken.WithEncryptor(e))
+	})
+
+	issue := func(status string) string {
+		t, err := rr.Read(filterRequest{status: status})
+		Expect(err).NotTo(HaveOccurred())
+		s, err := t.Next().String()
+		Expect(err).NotTo(HaveOccurred())
+		return s
+	}
+
+	It("accepts a token issued for the same parameters", func() {
+		_, err := rr.Read(filterRequest{status: "active", token: issue("active")})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("rejects a token issued for other parameters with ErrChecksumMismatch", func() {
+		_, err := rr.Read(filterRequest{status: "inactive", token: issue("active")})
+		Expect(err).To(MatchError(pagetoken.ErrChecksumMismatch))
+		Expect(pagetoken.HTTPStatus(err)).To(Equal(http.StatusBadRequest))
+	})
+
+	It("reports the checksums of a mismatch", func() {
+		_, err := rr.Read(filterRequest{status: "inactive", token: issue("active")})
+
+		var mismatch *pagetoken.ChecksumMismatchError
+		Expect(errors.As(err, &mismatch)).To(BeTrue())
+		Expect(errors.Is(err, pagetoken.ErrChecksumMismatch)).To(BeTrue())
+
+		active, err := rr.Read(filterRequest{status: "active"})
+		Expect(err).NotTo(HaveOccurred())
+		inactive, err := rr.Read(filterRequest{status: "inactive"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(mismatch.Got).To(Equal(inactive.Checksum()))
+		Expect(mismatch.Want).To(Equal(active.Checksum()))
+		Expect(mismatch.Error()).To(Equal(fmt.Sprintf(
+			"page token checksum mismatch (got 0x%x but expected 0x%x)", inactive.Checksum(), active.Checksum(),
+		)))
+	})
+
+	It("rejects a tampered token with ErrInvalidToken", func() {
+		s := []byte(issue("active"))
+		s[len(s)/2] ^= 0x01
+
+		_, err := rr.Read(filterRequest{status: "active", token: string(s)})
+		Expect(err).To(MatchError(pagetoken.ErrInvalidToken))
+		Expect(pagetoken.HTTPStatus(err)).To(Equal(http.StatusBadRequest))
+	})
+
+	It("does not report a mismatch by default", func() {
+		t, err := rr.Read(filterRequest{status: "active", token: issue("active")})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(t.ChecksumMismatched()).To(BeFalse())
+	})
+})
+
+var _ = Describe("Soft checksum", func() {
+	var (
+		e  encryption.Crypter
+		rr *pagetoken.RequestReader
+	)
+
+	BeforeEach(func() {
+		key, err := encryption.Rand32ByteKey()
+		Expect(err).NotTo(HaveOccurred())
+		e, err = encryption.NewAEADEncryptor(key)
+		Expect(err).NotTo(HaveOccurred())
+		rr = pagetoken.NewRequestReader(pagetoken.WithEncryptor(e), pagetoken.WithSoftChecksum())
+	})
+
+	// issue returns the token of the second page of a listing by status.
+	issue := func(status string) *pagetoken.KeysetToken {
+		t, err := rr.Read(filterRequest{status: status})
+		Expect(err).NotTo(HaveOccurred())
+		return t.Next(pagetoken.WithKeysetPayload(pagetoken.NewKeysetPayloadBuilder().AddInt("id", 7, order.Asc).Build()))
+	}
+
+	str := func(t *pagetoken.KeysetToken) string {
+		s, err := t.String()
+		Expect(err).NotTo(HaveOccurred())
+		return s
+	}
+
+	It("accepts a token issued for the same parameters", func() {
+		t, err := rr.Read(filterRequest{status: "active", token: str(issue("active"))})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(t.ChecksumMismatched()).To(BeFalse())
+		Expect(t.PageIndex()).To(Equal(1))
+		Expect(t.Payload().Values()).To(HaveLen(1))
+	})
+
+	It("restarts from the first page on a mismatch", func() {
+		t, err := rr.Read(filterRequest{status: "inactive", token: str(issue("active"))})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(t.ChecksumMismatched()).To(BeTrue())
+		Expect(t.PageIndex()).To(BeZero())
+		Expect(t.Payload().Values()).To(BeEmpty())
+
+		first, err := rr.Read(filterRequest{status: "inactive"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(t.Checksum()).To(Equal(first.Checksum()))
+	})
+
+	It("issues tokens from a restart that validate on the next request", func() {
+		t, err := rr.Read(filterRequest{status: "inactive", token: str(issue("active"))})
+		Expect(err).NotTo(HaveOccurred())
+
+		next, err := rr.Read(filterRequest{status: "inactive", token: str(t.Next())})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(next.ChecksumMismatched()).To(BeFalse())
+		Expect(next.PageIndex()).To(Equal(1))
+
+		_, err = pagetoken.NewRequestReader(pagetoken.WithEncryptor(e)).Read(filterRequest{status: "inactive", token: str(t.Next())})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("restarts tokens read from the context", func() {
+		ctx := pagetoken.NewContext(context.Background(), issue("active"))
+		t, err := rr.ReadContext(ctx, filterRequest{status: "inactive"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(t.ChecksumMismatched()).To(BeTrue())
+		Expect(t.Payload().Values()).To(BeEmpty())
+	})
+
+	It("still rejects invalid tokens", func() {
+		_, err := rr.Read(filterRequest{status: "active", token: "invalid"})
+		Expect(err).To(MatchError(pagetoken.ErrInvalidToken))
+	})
+
+	It("keeps Verify strict", func() {
+		err := rr.Verify(issue("active"), filterRequest{status: "inactive"})
+		Expect(err).To(MatchError(pagetoken.ErrChecksumMismatch))
+	})
+})
+
+var _ = Describe("HTTPStatus", func() {
+	It("maps nil to 200", func() {
+		Expect(pagetoken.HTTPStatus(nil)).To(Equal(http.StatusOK))
+	})
+
+	It("maps other errors to 500", func() {
+		Expect(pagetoken.HTTPStatus(errors.New("db down"))).To(Equal(http.StatusInternalServerError))
+	})
+})
+
+type fieldsRequest struct {
+	fields []checksum.BuilderOpt
+	token  string
+}
+
+func (r fieldsRequest) GetChecksumFields() []checksum.BuilderOpt {
+	return r.fields
+}
+
+func (r fieldsRequest) GetPageToken() string {
+	return r.token
+}
+
+var _ = Describe("Required checksum fields", func() {
+	var e encryption.Crypter
+
+	BeforeEach(func() {
+		key, err := encryption.Rand32ByteKey()
+		Expect(err).NotTo(HaveOccurred())
+		e, err = encryption.NewAEADEncryptor(key)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	DescribeTable("rejects requests without checksum fields",
+		func(fields []checksum.BuilderOpt) {
+			rr := pagetoken.NewRequestReader(pagetoken.WithEncryptor(e), pagetoken.WithRequireChecksumFields())
+
+			_, err := rr.Read(fieldsRequest{fields: fields})
+			Expect(err).To(MatchError(pagetoken.ErrNoChecksumFields))
+			Expect(pagetoken.HTTPStatus(err)).To(Equal(http.StatusInternalServerError))
+		},
+		Entry("nil", nil),
+		Entry("an empty slice", []checksum.BuilderOpt{}),
+	)
+
+	It("rejects tokens presented with a request without checksum fields", func() {
+		t, err := pagetoken.NewRequestReader(pagetoken.WithEncryptor(e)).Read(fieldsRequest{})
+		Expect(err).NotTo(HaveOccurred())
+
+		rr := pagetoken.NewRequestReader(pagetoken.WithEncryptor(e), pagetoken.WithRequireChecksumFields())
+		Expect(rr.Verify(t, fieldsRequest{})).To(MatchError(pagetoken.ErrNoChecksumFields))
+	})
+
+	It("accepts requests with checksum fields", func() {
+		rr := pagetoken.NewRequestReader(pagetoken.WithEncryptor(e), pagetoken.WithRequireChecksumFields())
+
+		_, err := rr.Read(fieldsRequest{fields: []checksum.BuilderOpt{checksum.Field("status", "active")}})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("accepts requests without checksum fields if the reader has static ones", func() {
+		rr := pagetoken.NewRequestReader(
+			pagetoken.WithEncryptor(e),
+			pagetoken.WithRequireChecksumFields(),
+			pagetoken.WithChecksumOpts(checksum.Field("endpoint", "books")),
+		)
+
+		_, err := rr.Read(fieldsRequest{})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("rejects tokens of readers with other static checksum fields", func() {
+		tenant := func(name string) *pagetoken.RequestReader {
+			return pagetoken.NewRequestReader(
+				pagetoken.WithEncryptor(e),
+				pagetoken.WithRequireChecksumFields(),
+				pagetoken.WithChecksumOpts(checksum.Field("tenant", name)),
+			)
+		}
+
+		t, err := tenant("a").Read(fieldsRequest{})
+		Expect(err).NotTo(HaveOccurred())
+		s, err := t.Next().String()
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = tenant("a").Read(fieldsRequest{token: s})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = tenant("b").Read(fieldsRequest{token: s})
+		Expect(err).To(MatchError(pagetoken.ErrChecksumMismatch))
+	})
+
+	It("accepts requests without checksum fields unless required", func() {
+		rr := pagetoken.NewRequestReader(pagetoken.WithEncryptor(e))
 
+		_, err := rr.Read(fieldsRequest{})
+		Expect(err).NotTo(HaveOccurred())
+	})
 })
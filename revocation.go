@@ -0,0 +1,98 @@
+package pagetoken
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"time"
+)
+
+// TokenInfo is the metadata of a page token that can be checked without
+// looking at its keyset.
+type TokenInfo struct {
+	// Version is the format version of the token: 0 for legacy tokens,
+	// which carry no metadata but the checksum, and 1 for versioned ones.
+	Version int
+	// IssuedAt is the time the token was serialized at, with second
+	// precision. It is zero for tokens issued before tokens were stamped.
+	IssuedAt time.Time
+	// PageIndex is the zero-based index of the page the token points to.
+	PageIndex int
+	// Checksum is the request checksum the token was issued for.
+	Checksum uint32
+	// Scope is the scope the token was issued for, see Scoper. It is empty
+	// for tokens of unscoped requests.
+	Scope string
+	// ID is the random ID of the token string, the equivalent of the jti
+	// claim of a JWT. It is empty for tokens issued without WithTokenIDs.
+	ID string
+}
+
+// Info returns the metadata of the token.
+func (c *KeysetToken) Info() TokenInfo {
+	version := int(keysetTokenV1)
+	if c.legacy {
+		version = 0
+	}
+
+	return TokenInfo{
+		Version:   version,
+		IssuedAt:  c.issuedAt,
+		PageIndex: c.pageIndex,
+		Checksum:  c.checksum,
+		Scope:     c.scope,
+		ID:        c.id,
+	}
+}
+
+// RevocationCheckFn decides whether a page token may still be used. A
+// non-nil error rejects it.
+type RevocationCheckFn func(ctx context.Context, info TokenInfo) error
+
+// WithRevocationCheck makes the reader call fn for every page token it
+// reads, after decrypting it and before verifying its checksum, e.g. to
+// reject all tokens issued during an incident window without rotating the
+// key:
+//
+//	pagetoken.WithRevocationCheck(func(ctx context.Context, info pagetoken.TokenInfo) error {
+//	    if info.IssuedAt.Before(incidentEnd) {
+//	        return errors.New("issued before incident end")
+//	    }
+//	    return nil
+//	})
+//
+// An error returned by fn is wrapped together with ErrTokenRevoked, so both
+// can be matched with errors.Is. Requests without a page token are not
+// checked.
+func WithRevocationCheck(fn RevocationCheckFn) RequestReaderOpt {
+	return func(rr *RequestReader) {
+		rr.revocationCheck = fn
+	}
+}
+
+// WithTokenIDs makes the reader issue page tokens carrying a random ID,
+// which TokenInfo exposes to the revocation check, e.g. to deny individual
+// tokens by ID. Every token string gets its own ID, including the strings of
+// tokens derived via Next.
+func WithTokenIDs() RequestReaderOpt {
+	return func(rr *RequestReader) {
+		rr.tokenIDs = true
+	}
+}
+
+// newTokenID returns a random token ID.
+func newTokenID() string {
+	return rand.Text()
+}
+
+func (r *RequestReader) checkRevoked(ctx context.Context, t *KeysetToken) error {
+	if r.revocationCheck == nil {
+		return nil
+	}
+
+	if err := r.revocationCheck(ctx, t.Info()); err != nil {
+		return fmt.Errorf("%w: %w", ErrTokenRevoked, err)
+	}
+
+	return nil
+}
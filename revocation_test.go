@@ -0,0 +1,210 @@
+package pagetoken_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/encryption"
+)
+
+type ctxKey struct{}
+
+var _ = Describe("WithRevocationCheck", func() {
+	var (
+		e     *encryption.AEADEncryptor
+		infos []pagetoken.TokenInfo
+	)
+
+	BeforeEach(func() {
+		key, err := encryption.Rand32ByteKey()
+		Expect(err).NotTo(HaveOccurred())
+		e, err = encryption.NewAEADEncryptor(key)
+		Expect(err).NotTo(HaveOccurred())
+		infos = nil
+	})
+
+	reader := func(result error) *pagetoken.RequestReader {
+		return pagetoken.NewRequestReader(
+			pagetoken.WithEncryptor(e),
+			pagetoken.WithRevocationCheck(func(ctx context.Context, info pagetoken.TokenInfo) error {
+				infos = append(infos, info)
+				return result
+			}),
+		)
+	}
+
+	// secondPage returns the token of the second page of filterRequest.
+	secondPage := func() string {
+		t, err := pagetoken.NewRequestReader(pagetoken.WithEncryptor(e)).Read(filterRequest{status: "active"})
+		Expect(err).NotTo(HaveOccurred())
+		s, err := t.Next().String()
+		Expect(err).NotTo(HaveOccurred())
+		return s
+	}
+
+	It("accepts tokens the check allows and passes their metadata", func() {
+		s := secondPage()
+
+		t, err := reader(nil).Read(filterRequest{status: "active", token: s})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(t.PageIndex()).To(Equal(1))
+
+		Expect(infos).To(HaveLen(1))
+		Expect(infos[0].PageIndex).To(Equal(1))
+		Expect(infos[0].Checksum).To(Equal(t.Checksum()))
+		Expect(infos[0].IssuedAt).To(BeTemporally("~", time.Now(), 2*time.Second))
+	})
+
+	It("passes the scope and ID of tokens", func() {
+		issuer := pagetoken.NewRequestReader(pagetoken.WithEncryptor(e), pagetoken.WithTokenIDs())
+		req := scopedRequest{filterRequest: filterRequest{status: "active"}, scope: "ListBooks"}
+		t, err := issuer.Read(req)
+		Expect(err).NotTo(HaveOccurred())
+		s, err := t.Next().String()
+		Expect(err).NotTo(HaveOccurred())
+
+		req.token = s
+		_, err = reader(nil).Read(req)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(infos).To(HaveLen(1))
+		Expect(infos[0].Scope).To(Equal("ListBooks"))
+		Expect(infos[0].ID).NotTo(BeEmpty())
+	})
+
+	It("gives every token string its own ID", func() {
+		issuer := pagetoken.NewRequestReader(pagetoken.WithEncryptor(e), pagetoken.WithTokenIDs())
+		t, err := issuer.Read(filterRequest{status: "active"})
+		Expect(err).NotTo(HaveOccurred())
+		s, err := t.Next().String()
+		Expect(err).NotTo(HaveOccurred())
+
+		t, err = issuer.Read(filterRequest{status: "active", token: s})
+		Expect(err).NotTo(HaveOccurred())
+		next, err := t.Next().String()
+		Expect(err).NotTo(HaveOccurred())
+
+		for _, s := range []string{s, s, next} {
+			_, err := reader(nil).Read(filterRequest{status: "active", token: s})
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(infos).To(HaveLen(3))
+		Expect(infos[0].ID).To(Equal(infos[1].ID))
+		Expect(infos[2].ID).NotTo(BeEmpty())
+		Expect(infos[2].ID).NotTo(Equal(infos[0].ID))
+	})
+
+	It("gives every item cursor its own ID", func() {
+		issuer := pagetoken.NewRequestReader(pagetoken.WithEncryptor(e), pagetoken.WithTokenIDs())
+		t, err := issuer.Read(filterRequest{status: "active"})
+		Expect(err).NotTo(HaveOccurred())
+		cs, err := pagetoken.ItemCursors(t, itemPayloads(2))
+		Expect(err).NotTo(HaveOccurred())
+
+		for _, s := range cs {
+			_, err := reader(nil).Read(filterRequest{status: "active", token: s})
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(infos).To(HaveLen(2))
+		Expect(infos[0].ID).NotTo(BeEmpty())
+		Expect(infos[0].ID).NotTo(Equal(infos[1].ID))
+	})
+
+	It("denies tokens by ID", func() {
+		issuer := pagetoken.NewRequestReader(pagetoken.WithEncryptor(e), pagetoken.WithTokenIDs())
+		t, err := issuer.Read(filterRequest{status: "active"})
+		Expect(err).NotTo(HaveOccurred())
+		s, err := t.Next().String()
+		Expect(err).NotTo(HaveOccurred())
+		info, err := pagetoken.NewKeysetTokenParser(pagetoken.WithKeysetTokenEncryptor(e)).Parse(s)
+		Expect(err).NotTo(HaveOccurred())
+
+		denied := map[string]bool{info.Info().ID: true}
+		rr := pagetoken.NewRequestReader(
+			pagetoken.WithEncryptor(e),
+			pagetoken.WithRevocationCheck(func(_ context.Context, info pagetoken.TokenInfo) error {
+				if denied[info.ID] {
+					return errors.New("denied by ID")
+				}
+				return nil
+			}),
+		)
+
+		_, err = rr.Read(filterRequest{status: "active", token: s})
+		Expect(err).To(MatchError(pagetoken.ErrTokenRevoked))
+
+		other, err := t.Next().String()
+		Expect(err).NotTo(HaveOccurred())
+		_, err = rr.Read(filterRequest{status: "active", token: other})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("passes no scope or ID for unscoped tokens issued without IDs", func() {
+		_, err := reader(nil).Read(filterRequest{status: "active", token: secondPage()})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(infos).To(HaveLen(1))
+		Expect(infos[0].Scope).To(BeEmpty())
+		Expect(infos[0].ID).To(BeEmpty())
+	})
+
+	It("rejects tokens the check denies with ErrTokenRevoked", func() {
+		denied := errors.New("issued during incident window")
+
+		_, err := reader(denied).Read(filterRequest{status: "active", token: secondPage()})
+		Expect(err).To(MatchError(pagetoken.ErrTokenRevoked))
+		Expect(err).To(MatchError(denied))
+		Expect(pagetoken.HTTPStatus(err)).To(Equal(http.StatusBadRequest))
+	})
+
+	It("passes the request context to the check", func() {
+		var got any
+		rr := pagetoken.NewRequestReader(
+			pagetoken.WithEncryptor(e),
+			pagetoken.WithRevocationCheck(func(ctx context.Context, _ pagetoken.TokenInfo) error {
+				got = ctx.Value(ctxKey{})
+				return ctx.Err()
+			}),
+		)
+		ctx := context.WithValue(context.Background(), ctxKey{}, "request")
+
+		_, err := rr.ReadContext(ctx, filterRequest{status: "active", token: secondPage()})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(got).To(Equal("request"))
+
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err = rr.ReadContext(canceled, filterRequest{status: "active", token: secondPage()})
+		Expect(err).To(MatchError(pagetoken.ErrTokenRevoked))
+		Expect(err).To(MatchError(context.Canceled))
+	})
+
+	It("checks tokens stored by Middleware", func() {
+		s := secondPage()
+		t, err := pagetoken.NewKeysetTokenParser(pagetoken.WithKeysetTokenEncryptor(e)).Parse(s)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = reader(errors.New("denied")).ReadContext(
+			pagetoken.NewContext(context.Background(), t),
+			filterRequest{status: "active", token: s},
+		)
+		Expect(err).To(MatchError(pagetoken.ErrTokenRevoked))
+	})
+
+	It("does not check first pages or undecryptable tokens", func() {
+		rr := reader(errors.New("denied"))
+
+		_, err := rr.Read(filterRequest{status: "active"})
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = rr.Read(filterRequest{status: "active", token: "bm90LWEtdG9rZW4"})
+		Expect(err).To(MatchError(pagetoken.ErrInvalidToken))
+		Expect(infos).To(BeEmpty())
+	})
+})
@@ -0,0 +1,41 @@
+package pagetoken
+
+import "fmt"
+
+// Scoper is implemented by requests whose page tokens are scoped, e.g. to
+// the RPC method or endpoint serving them. The reader stamps the scope into
+// the tokens it issues for such requests and rejects tokens issued for
+// another scope with ErrScopeMismatch, so that a token of one list method
+// cannot be replayed against another even if their checksum fields
+// coincide. Unlike the checksum, the scope is exposed to the revocation
+// check via TokenInfo.
+type Scoper interface {
+	// GetTokenScope returns the scope of the page tokens of the request.
+	GetTokenScope() string
+}
+
+// scopeOf returns the scope of the page tokens of req, which is empty for
+// requests not implementing Scoper.
+func scopeOf(req Request) string {
+	if s, ok := req.(Scoper); ok {
+		return s.GetTokenScope()
+	}
+
+	return ""
+}
+
+// Scope returns the scope the token was issued for, see Scoper. It is empty
+// for tokens of requests not implementing Scoper.
+func (c *KeysetToken) Scope() string {
+	return c.scope
+}
+
+// checkScope returns an error wrapping ErrScopeMismatch unless t was issued
+// for the scope of req.
+func checkScope(t *KeysetToken, req Request) error {
+	if want := scopeOf(req); t.scope != want {
+		return fmt.Errorf("%w: issued for scope %q, not %q", ErrScopeMismatch, t.scope, want)
+	}
+
+	return nil
+}
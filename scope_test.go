@@ -0,0 +1,104 @@
+package pagetoken_test
+
+import (
+	"net/http"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/encryption"
+	"github.com/pixlcrashr/go-pagetoken/order"
+)
+
+// scopedRequest is a filterRequest of the list method scope.
+type scopedRequest struct {
+	filterRequest
+	scope string
+}
+
+func (r scopedRequest) GetTokenScope() string {
+	return r.scope
+}
+
+var _ = Describe("Scoper", func() {
+	var rr *pagetoken.RequestReader
+
+	BeforeEach(func() {
+		key, err := encryption.Rand32ByteKey()
+		Expect(err).NotTo(HaveOccurred())
+		e, err := encryption.NewAEADEncryptor(key)
+		Expect(err).NotTo(HaveOccurred())
+		rr = pagetoken.NewRequestReader(pagetoken.WithEncryptor(e))
+	})
+
+	// issue returns the token of the second page of req.
+	issue := func(req pagetoken.Request) string {
+		t, err := rr.Read(req)
+		Expect(err).NotTo(HaveOccurred())
+		s, err := t.Next(pagetoken.WithKeysetPayload(pagetoken.NewKeysetPayloadBuilder().AddInt("id", 7, order.Asc).Build())).String()
+		Expect(err).NotTo(HaveOccurred())
+		return s
+	}
+
+	scoped := func(scope, token string) scopedRequest {
+		return scopedRequest{filterRequest: filterRequest{status: "active", token: token}, scope: scope}
+	}
+
+	It("stamps the scope of the request into its tokens", func() {
+		t, err := rr.Read(scoped("ListBooks", ""))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(t.Scope()).To(Equal("ListBooks"))
+
+		t, err = rr.Read(scoped("ListBooks", issue(scoped("ListBooks", ""))))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(t.Scope()).To(Equal("ListBooks"))
+
+		s, err := t.Next().String()
+		Expect(err).NotTo(HaveOccurred())
+		t, err = rr.Read(scoped("ListBooks", s))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(t.Scope()).To(Equal("ListBooks"))
+	})
+
+	It("rejects tokens of another scope with ErrScopeMismatch", func() {
+		s := issue(scoped("ListBooks", ""))
+
+		_, err := rr.Read(scoped("ListArchivedBooks", s))
+		Expect(err).To(MatchError(pagetoken.ErrScopeMismatch))
+		Expect(err).NotTo(MatchError(pagetoken.ErrChecksumMismatch))
+		Expect(pagetoken.HTTPStatus(err)).To(Equal(http.StatusBadRequest))
+	})
+
+	It("rejects scoped tokens for unscoped requests and vice versa", func() {
+		_, err := rr.Read(filterRequest{status: "active", token: issue(scoped("ListBooks", ""))})
+		Expect(err).To(MatchError(pagetoken.ErrScopeMismatch))
+
+		_, err = rr.Read(scoped("ListBooks", issue(filterRequest{status: "active"})))
+		Expect(err).To(MatchError(pagetoken.ErrScopeMismatch))
+	})
+
+	It("takes the scope of NextTokenFor from the request", func() {
+		t, err := rr.Read(scoped("ListBooks", ""))
+		Expect(err).NotTo(HaveOccurred())
+
+		s, err := rr.NextTokenFor(scoped("ListArchivedBooks", ""), t, pagetoken.NewKeysetPayloadBuilder().AddInt("id", 7, order.Asc).Build())
+		Expect(err).NotTo(HaveOccurred())
+
+		t, err = rr.Read(scoped("ListArchivedBooks", s))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(t.Scope()).To(Equal("ListArchivedBooks"))
+	})
+
+	It("scopes boundary tokens", func() {
+		ts, err := pagetoken.BoundaryTokens(rr, scoped("ListBooks", ""), []*pagetoken.KeysetPayload{
+			pagetoken.NewKeysetPayloadBuilder().AddInt("id", 7, order.Asc).Build(),
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = rr.Read(scoped("ListBooks", ts[0]))
+		Expect(err).NotTo(HaveOccurred())
+		_, err = rr.Read(scoped("ListArchivedBooks", ts[0]))
+		Expect(err).To(MatchError(pagetoken.ErrScopeMismatch))
+	})
+})
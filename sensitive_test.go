@@ -0,0 +1,148 @@
+package pagetoken_test
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/encryption"
+	"github.com/pixlcrashr/go-pagetoken/order"
+	"github.com/pixlcrashr/go-pagetoken/pagetokentest"
+)
+
+var _ = Describe("Sensitive values", func() {
+	const email = "jane@example.com"
+
+	var (
+		e       *encryption.AEADEncryptor
+		payload *pagetoken.KeysetPayload
+		s       string
+	)
+
+	BeforeEach(func() {
+		key, err := encryption.Rand32ByteKey()
+		Expect(err).NotTo(HaveOccurred())
+		e, err = encryption.NewAEADEncryptor(key)
+		Expect(err).NotTo(HaveOccurred())
+
+		payload = pagetoken.NewKeysetPayloadBuilder().
+			AddStringSensitive("email", email, order.Asc).
+			AddInt64("id", 42, order.Asc).
+			AddInt64("account_id", 7, order.Desc).Sensitive().
+			Build()
+
+		s, err = pagetoken.NewKeysetToken(e, pagetoken.WithKeysetPayload(payload)).String()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("are marked by the builder", func() {
+		Expect(payload.Values()).To(Equal([]pagetoken.KeysetValue{
+			{Path: "email", Value: email, Order: order.Asc, Sensitive: true},
+			{Path: "id", Value: "42", Order: order.Asc},
+			{Path: "account_id", Value: "7", Order: order.Desc, Sensitive: true},
+		}))
+	})
+
+	It("keep their mark through a serialize and parse round trip", func() {
+		t, err := pagetoken.NewKeysetTokenParser(pagetoken.WithKeysetTokenEncryptor(e)).Parse(s)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(t.Payload().Values()).To(Equal(payload.Values()))
+
+		next, err := t.Next().String()
+		Expect(err).NotTo(HaveOccurred())
+		t, err = pagetoken.NewKeysetTokenParser(pagetoken.WithKeysetTokenEncryptor(e)).Parse(next)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(t.Payload().Values()).To(Equal(payload.Values()))
+	})
+
+	It("keep their mark in composite parts", func() {
+		c := pagetoken.NewCompositePayload()
+		c.Set("users", payload)
+
+		s, err := pagetoken.NewKeysetToken(e, pagetoken.WithCompositePayload(c)).String()
+		Expect(err).NotTo(HaveOccurred())
+		t, err := pagetoken.NewKeysetTokenParser(pagetoken.WithKeysetTokenEncryptor(e)).Parse(s)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(t.CompositePayload().Get("users").Values()).To(Equal(payload.Values()))
+	})
+
+	It("keep their mark through EncodePayload", func() {
+		d, err := pagetoken.EncodePayload(payload)
+		Expect(err).NotTo(HaveOccurred())
+
+		p, err := pagetoken.DecodePayload(d)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(p.Values()).To(Equal(payload.Values()))
+	})
+
+	It("reject positions out of range", func() {
+		_, err := pagetoken.DecodePayload(append([]byte{0x01}, `{"k":["id","42","asc"],"r":[1]}`...))
+		Expect(err).To(MatchError(ContainSubstring("out of range")))
+	})
+
+	It("leave tokens without them unchanged", func() {
+		s := pagetokentest.MustToken(GinkgoT(), pagetoken.KeysetValue{Path: "id", Value: "42", Order: order.Asc})
+		plaintext, err := pagetokentest.StaticCrypter{}.Decrypt(s)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(plaintext)).NotTo(ContainSubstring(`"r"`))
+	})
+
+	It("remain available to typed accessors", func() {
+		t, err := pagetoken.NewKeysetTokenParser(pagetoken.WithKeysetTokenEncryptor(e)).Parse(s)
+		Expect(err).NotTo(HaveOccurred())
+
+		v, o, err := t.Payload().String("email")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(v).To(Equal(email))
+		Expect(o).To(Equal(order.Asc))
+		Expect(pagetokentest.HasKeysetField(t, "email", email, order.Asc)).To(BeTrue())
+	})
+
+	It("are dumped as a hash only", func() {
+		for _, opts := range [][]pagetoken.DumpOpt{nil, {pagetoken.WithUnredactedValues()}} {
+			d, err := pagetoken.DumpToken(e, s, opts...)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(d.Keyset[0]).To(Equal(pagetoken.DumpValue{
+				Path: "email", Order: "asc", Value: pagetoken.RedactSensitive(email), Sensitive: true,
+			}))
+			Expect(d.Keyset[2].Value).To(Equal(pagetoken.RedactSensitive("7")))
+		}
+
+		d, err := pagetoken.DumpToken(e, s, pagetoken.WithUnredactedValues())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(d.Keyset[1].Value).To(Equal("42"))
+	})
+
+	It("are hashed in a stable, short form", func() {
+		Expect(pagetoken.RedactSensitive(email)).To(MatchRegexp(`^sha256:[0-9a-f]{16}$`))
+		Expect(pagetoken.RedactSensitive(email)).To(Equal(pagetoken.RedactSensitive(email)))
+		Expect(pagetoken.RedactSensitive(email)).NotTo(Equal(pagetoken.RedactSensitive("john@example.com")))
+	})
+
+	It("are redacted by the introspection handler", func() {
+		h := pagetoken.IntrospectionHandler(e, pagetoken.WithIntrospectionUnredactedValues())
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/pagetoken", strings.NewReader(`{"token":"`+s+`"}`)))
+
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Body.String()).NotTo(ContainSubstring(email))
+		Expect(w.Body.String()).To(ContainSubstring(pagetoken.RedactSensitive(email)))
+	})
+
+	It("are redacted in logs", func() {
+		t, err := pagetoken.NewKeysetTokenParser(pagetoken.WithKeysetTokenEncryptor(e)).Parse(s)
+		Expect(err).NotTo(HaveOccurred())
+
+		var buf bytes.Buffer
+		slog.New(slog.NewJSONHandler(&buf, nil)).Info("page", "token", t)
+
+		Expect(buf.String()).NotTo(ContainSubstring(email))
+		Expect(buf.String()).To(ContainSubstring(`"keyset":{"email":"` + pagetoken.RedactSensitive(email) + `","id":"42","account_id":"` + pagetoken.RedactSensitive("7") + `"}`))
+	})
+})
@@ -0,0 +1,108 @@
+package pagetoken
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/pixlcrashr/go-pagetoken/checksum"
+	"github.com/pixlcrashr/go-pagetoken/encryption"
+	"github.com/pixlcrashr/go-pagetoken/order"
+)
+
+type setupConfig struct {
+	maxLength int
+	keyset    *KeysetPayload
+}
+
+type ValidateSetupOpt func(*setupConfig)
+
+// WithSetupMaxTokenLength sets the longest token ValidateSetup accepts. It
+// should match the limit of Middleware. The default is
+// DefaultMaxTokenLength.
+func WithSetupMaxTokenLength(n int) ValidateSetupOpt {
+	return func(c *setupConfig) {
+		c.maxLength = n
+	}
+}
+
+// WithSetupKeyset sets the keyset ValidateSetup measures the token size
+// with. It should be the largest keyset the service issues. The default is
+// a timestamp and a UUID.
+func WithSetupKeyset(p *KeysetPayload) ValidateSetupOpt {
+	return func(c *setupConfig) {
+		c.keyset = p
+	}
+}
+
+// setupProbe is the request ValidateSetup reads tokens for.
+type setupProbe struct {
+	token string
+}
+
+func (p setupProbe) GetChecksumFields() []checksum.BuilderOpt {
+	return []checksum.BuilderOpt{checksum.Field("probe", "pagetoken")}
+}
+
+func (p setupProbe) GetPageToken() string {
+	return p.token
+}
+
+// ValidateSetup checks the configuration of reader, e.g. from main() or in a
+// readiness probe, so that a misconfiguration fails the deployment instead
+// of every paginated request. It checks that
+//
+//   - the crypter encrypts and decrypts, see encryption.SelfTest,
+//   - the checksum of a request builds with the configured checksum
+//     options, as Read builds it,
+//   - a token with a representative keyset, see WithSetupKeyset, fits into
+//     the maximum token length, and
+//   - that token is read back by reader.
+//
+// All failures are returned, joined with errors.Join.
+func ValidateSetup(reader *RequestReader, opts ...ValidateSetupOpt) error {
+	cfg := &setupConfig{
+		maxLength: DefaultMaxTokenLength,
+		keyset: NewKeysetPayloadBuilder().
+			AddTime("created_at", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), order.Desc).
+			AddString("id", "0b5a1c9e-3f2d-4e8a-9c7b-6d5e4f3a2b1c", order.Asc).
+			Build(),
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	if reader == nil {
+		return errors.New("request reader is nil")
+	}
+
+	errs := []error{}
+	if _, err := reader.checksum(setupProbe{}); err != nil {
+		errs = append(errs, fmt.Errorf("failed to build checksum: %w", err))
+	}
+
+	if err := encryption.SelfTest(reader.e); err != nil {
+		// without a working crypter, no token can be measured or read
+		return errors.Join(append(errs, fmt.Errorf("crypter self test failed: %w", err))...)
+	}
+
+	t, err := reader.Read(setupProbe{})
+	if err != nil {
+		return errors.Join(append(errs, fmt.Errorf("failed to read first page: %w", err))...)
+	}
+
+	s, err := t.Next(WithKeysetPayload(cfg.keyset)).String()
+	if err != nil {
+		return errors.Join(append(errs, fmt.Errorf("failed to serialize token: %w", err))...)
+	}
+
+	if len(s) > cfg.maxLength {
+		errs = append(errs, fmt.Errorf("token with a representative keyset is %d bytes long, more than the maximum of %d", len(s), cfg.maxLength))
+	}
+
+	if _, err := reader.Read(setupProbe{token: s}); err != nil {
+		errs = append(errs, fmt.Errorf("failed to read back token: %w", err))
+	}
+
+	return errors.Join(errs...)
+}
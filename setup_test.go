@@ -0,0 +1,62 @@
+package pagetoken_test
+
+import (
+	"errors"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/encryption"
+	"github.com/pixlcrashr/go-pagetoken/order"
+)
+
+// failingCrypter fails to encrypt, like a custom crypter configured with a
+// key of the wrong size.
+type failingCrypter struct{}
+
+var errWrongKeySize = errors.New("key must be 32 bytes")
+
+func (failingCrypter) Encrypt([]byte) (string, error) { return "", errWrongKeySize }
+func (failingCrypter) Decrypt(string) ([]byte, error) { return nil, errWrongKeySize }
+
+var _ = Describe("ValidateSetup", func() {
+	var e *encryption.AEADEncryptor
+
+	BeforeEach(func() {
+		key, err := encryption.Rand32ByteKey()
+		Expect(err).NotTo(HaveOccurred())
+		e, err = encryption.NewAEADEncryptor(key)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("passes for a working setup", func() {
+		Expect(pagetoken.ValidateSetup(pagetoken.NewRequestReader(pagetoken.WithEncryptor(e)))).To(Succeed())
+	})
+
+	It("fails without a crypter", func() {
+		err := pagetoken.ValidateSetup(pagetoken.NewRequestReader())
+		Expect(err).To(MatchError(ContainSubstring("crypter is nil")))
+	})
+
+	It("fails for a faulty crypter", func() {
+		err := pagetoken.ValidateSetup(pagetoken.NewRequestReader(pagetoken.WithEncryptor(failingCrypter{})))
+		Expect(err).To(MatchError(errWrongKeySize))
+	})
+
+	It("fails if a representative token exceeds the maximum length", func() {
+		rr := pagetoken.NewRequestReader(pagetoken.WithEncryptor(e))
+		err := pagetoken.ValidateSetup(rr, pagetoken.WithSetupKeyset(
+			pagetoken.NewKeysetPayloadBuilder().AddString("title", strings.Repeat("x", 600), order.Asc).Build(),
+		))
+		Expect(err).To(MatchError(ContainSubstring("more than the maximum of 512")))
+
+		Expect(pagetoken.ValidateSetup(rr, pagetoken.WithSetupMaxTokenLength(100))).
+			To(MatchError(ContainSubstring("more than the maximum of 100")))
+	})
+
+	It("fails for a nil reader", func() {
+		Expect(pagetoken.ValidateSetup(nil)).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,41 @@
+package pagetoken
+
+import "github.com/pixlcrashr/go-pagetoken/order"
+
+// SortSpec is the ordered list of columns (and their directions) a listing is
+// sorted by. It is the server-side counterpart of a KeysetPayload: the spec
+// names the columns, the payload carries the boundary values for them.
+type SortSpec = order.Fields
+
+// EnsureTiebreak returns spec with column appended if spec does not already
+// sort by it. Keyset pagination needs the sort columns to be unique as a
+// whole, otherwise rows tied on all of them are duplicated or skipped at page
+// boundaries; a unique column such as the primary key makes them so.
+//
+// The appended column takes the direction of the last column of spec, which
+// keeps the order compatible with a composite index on the same columns. An
+// empty spec is sorted ascending by column. spec itself is never modified.
+func EnsureTiebreak(spec SortSpec, column string) SortSpec {
+	if HasSortColumn(spec, column) {
+		return spec
+	}
+
+	o := order.Asc
+	if len(spec) > 0 {
+		o = spec[len(spec)-1].Order
+	}
+
+	out := make(SortSpec, len(spec), len(spec)+1)
+	copy(out, spec)
+	return append(out, order.Field{Path: column, Order: o})
+}
+
+// HasSortColumn reports whether spec sorts by column.
+func HasSortColumn(spec SortSpec, column string) bool {
+	for _, f := range spec {
+		if f.Path == column {
+			return true
+		}
+	}
+	return false
+}
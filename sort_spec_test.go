@@ -0,0 +1,36 @@
+package pagetoken_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/order"
+)
+
+var _ = Describe("EnsureTiebreak", func() {
+	It("appends a missing tiebreak in the direction of the last column", func() {
+		spec := pagetoken.SortSpec{{Path: "created_at", Order: order.Desc}}
+
+		Expect(pagetoken.EnsureTiebreak(spec, "id")).To(Equal(pagetoken.SortSpec{
+			{Path: "created_at", Order: order.Desc},
+			{Path: "id", Order: order.Desc},
+		}))
+		Expect(spec).To(HaveLen(1), "the input spec must not be modified")
+	})
+
+	It("keeps a spec that already contains the tiebreak", func() {
+		spec := pagetoken.SortSpec{
+			{Path: "id", Order: order.Asc},
+			{Path: "created_at", Order: order.Desc},
+		}
+
+		Expect(pagetoken.EnsureTiebreak(spec, "id")).To(Equal(spec))
+	})
+
+	It("sorts an empty spec ascending by the tiebreak", func() {
+		Expect(pagetoken.EnsureTiebreak(nil, "id")).To(Equal(pagetoken.SortSpec{
+			{Path: "id", Order: order.Asc},
+		}))
+	})
+})
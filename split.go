@@ -0,0 +1,190 @@
+package pagetoken
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/pixlcrashr/go-pagetoken/order"
+)
+
+// ErrUnsplittable is returned by SplitToken for tokens whose remaining range
+// cannot be partitioned, e.g. because they are not ordered by the key of
+// the RangeBounds alone or their key is not of its type.
+var ErrUnsplittable = errors.New("page token cannot be split")
+
+type keySpace int
+
+const (
+	keySpaceInt keySpace = iota + 1
+	keySpaceUUID
+)
+
+// RangeBounds is the key space of a listing SplitToken partitions, see
+// IntRange and UUIDRange.
+type RangeBounds struct {
+	path     string
+	space    keySpace
+	min, max *big.Int
+}
+
+// IntRange returns the bounds of the integer keys at path, stored via
+// AddInt64 or another integer adder. from and to are the smallest and the
+// largest key, inclusive, e.g. the minimum and the maximum primary key of a
+// table.
+func IntRange(path string, from, to int64) RangeBounds {
+	return RangeBounds{path: path, space: keySpaceInt, min: big.NewInt(from), max: big.NewInt(to)}
+}
+
+// UUIDRange returns the bounds of the 128-bit identifiers at path, stored
+// via AddUUID, from and to inclusive, see IntRange. The identifiers are
+// divided by their byte order, so the shards of random UUIDs hold about the
+// same number of rows, while those of time-ordered UUIDv7s cover equal time
+// spans.
+func UUIDRange(path string, from, to [16]byte) RangeBounds {
+	return RangeBounds{
+		path:  path,
+		space: keySpaceUUID,
+		min:   new(big.Int).SetBytes(from[:]),
+		max:   new(big.Int).SetBytes(to[:]),
+	}
+}
+
+// key decodes the keyset value v.
+func (b RangeBounds) key(v string) (*big.Int, error) {
+	switch b.space {
+	case keySpaceInt:
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s is not an integer key", ErrUnsplittable, b.path)
+		}
+		return big.NewInt(n), nil
+	case keySpaceUUID:
+		bs, err := hex.DecodeString(v)
+		if err != nil || len(bs) != 16 {
+			return nil, fmt.Errorf("%w: %s is not a 128-bit identifier", ErrUnsplittable, b.path)
+		}
+		return new(big.Int).SetBytes(bs), nil
+	default:
+		return nil, fmt.Errorf("%w: unsupported key space", ErrUnsplittable)
+	}
+}
+
+// encode encodes the key k as a keyset value.
+func (b RangeBounds) encode(k *big.Int) string {
+	if b.space == keySpaceUUID {
+		var bs [16]byte
+		return hex.EncodeToString(k.FillBytes(bs[:]))
+	}
+
+	return k.String()
+}
+
+// UpperBound returns the inclusive upper bound of the key range of a shard
+// token returned by SplitToken, as a payload of the key alone, or nil for
+// tokens that are not bounded. Queries must exclude the keys after it, e.g.
+// via the WithUpperBound option of the gorm adapter.
+func (c *KeysetToken) UpperBound() *KeysetPayload {
+	return c.upperBound
+}
+
+// SplitToken partitions the keys remaining after t, up to the maximum of
+// bounds, into n ranges of about the same size and returns a token for
+// each, so that n workers can page through a large listing in parallel.
+// The tokens keep the checksum of t and thus validate for the same
+// requests.
+//
+// t must be ordered ascending by the key of bounds alone, or be a token of
+// the first page, whose remaining range starts at the minimum of bounds.
+// The first token continues from the keyset of t; every other one from the
+// end of the previous range. All but the last token are bounded by the end
+// of their range, see UpperBound; the last one runs to the end of the
+// listing, including keys added beyond the maximum of bounds, unless t was
+// bounded itself, in which case its bound is kept and takes the place of
+// the maximum. Tokens derived from the shard tokens via Next keep their
+// bound.
+//
+// Fewer than n tokens are returned if fewer than n keys remain, and t alone
+// if none do. Tokens that cannot be split fail with ErrUnsplittable.
+func SplitToken(t *KeysetToken, n int, bounds RangeBounds) ([]*KeysetToken, error) {
+	if n < 1 {
+		return nil, fmt.Errorf("%w: invalid shard count %d", ErrUnsplittable, n)
+	}
+	if bounds.space == 0 {
+		return nil, fmt.Errorf("%w: unsupported key space", ErrUnsplittable)
+	}
+	if t.composite != nil || t.groups != nil {
+		return nil, fmt.Errorf("%w: token pages several keysets", ErrUnsplittable)
+	}
+
+	lo := new(big.Int).Sub(bounds.min, big.NewInt(1))
+	if t.payload != nil && len(t.payload.vs) > 0 {
+		k, err := splitKey(t.payload, bounds)
+		if err != nil {
+			return nil, err
+		}
+		lo = k
+	}
+
+	hi := bounds.max
+	if t.upperBound != nil {
+		k, err := splitKey(t.upperBound, bounds)
+		if err != nil {
+			return nil, err
+		}
+		hi = k
+	}
+
+	count := new(big.Int).Sub(hi, lo)
+	if count.Sign() <= 0 {
+		return []*KeysetToken{t}, nil
+	}
+	if count.IsInt64() && count.Int64() < int64(n) {
+		n = int(count.Int64())
+	}
+
+	// the range of shard i ends at lo + count*(i+1)/n
+	end := func(i int) *big.Int {
+		e := new(big.Int).Mul(count, big.NewInt(int64(i+1)))
+		e.Quo(e, big.NewInt(int64(n)))
+		return e.Add(e, lo)
+	}
+
+	ts := make([]*KeysetToken, n)
+	for i := range n {
+		payload := t.payload
+		if i > 0 {
+			payload = splitPayload(bounds, end(i-1))
+		}
+
+		s := t.Next(WithKeysetPayload(payload))
+		s.pageIndex = t.pageIndex
+		s.upperBound = nil
+		if i < n-1 || t.upperBound != nil {
+			s.upperBound = splitPayload(bounds, end(i))
+		}
+
+		ts[i] = s
+	}
+
+	return ts, nil
+}
+
+// splitKey decodes the key of p, which must hold the key of bounds
+// ascending and nothing else.
+func splitKey(p *KeysetPayload, bounds RangeBounds) (*big.Int, error) {
+	if len(p.vs) != 1 || p.vs[0].Path != bounds.path {
+		return nil, fmt.Errorf("%w: keyset must consist of %s only", ErrUnsplittable, bounds.path)
+	}
+	if p.vs[0].Order != order.Asc {
+		return nil, fmt.Errorf("%w: %s must be ascending", ErrUnsplittable, bounds.path)
+	}
+
+	return bounds.key(p.vs[0].Value)
+}
+
+func splitPayload(bounds RangeBounds, k *big.Int) *KeysetPayload {
+	return &KeysetPayload{vs: []KeysetValue{{Path: bounds.path, Value: bounds.encode(k), Order: order.Asc}}}
+}
@@ -0,0 +1,167 @@
+package pagetoken_test
+
+import (
+	"encoding/hex"
+	"strconv"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/encryption"
+	"github.com/pixlcrashr/go-pagetoken/order"
+)
+
+var _ = Describe("SplitToken", func() {
+	var e encryption.Crypter
+
+	BeforeEach(func() {
+		key, err := encryption.Rand32ByteKey()
+		Expect(err).NotTo(HaveOccurred())
+		e, err = encryption.NewAEADEncryptor(key)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	token := func(payload *pagetoken.KeysetPayload) *pagetoken.KeysetToken {
+		return pagetoken.NewKeysetToken(e, pagetoken.WithKeysetPayload(payload), pagetoken.WithChecksum(42))
+	}
+
+	at := func(id int64) *pagetoken.KeysetToken {
+		return token(pagetoken.NewKeysetPayloadBuilder().AddInt64("id", id, order.Asc).Build())
+	}
+
+	// ranges returns the start keys and the upper bounds of ts, "" for
+	// none
+	ranges := func(ts []*pagetoken.KeysetToken) [][2]string {
+		out := [][2]string{}
+		for _, t := range ts {
+			r := [2]string{}
+			if p := t.Payload(); p != nil && len(p.Values()) > 0 {
+				r[0] = p.Values()[0].Value
+			}
+			if b := t.UpperBound(); b != nil {
+				r[1] = b.Values()[0].Value
+			}
+			out = append(out, r)
+		}
+		return out
+	}
+
+	It("divides the remaining integer keys evenly", func() {
+		ts, err := pagetoken.SplitToken(at(40), 3, pagetoken.IntRange("id", 1, 100))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(ranges(ts)).To(Equal([][2]string{{"40", "60"}, {"60", "80"}, {"80", ""}}))
+		for _, t := range ts {
+			Expect(t.Checksum()).To(Equal(uint32(42)))
+			Expect(t.PageIndex()).To(BeZero())
+		}
+	})
+
+	It("divides the keys of the first page from the minimum", func() {
+		ts, err := pagetoken.SplitToken(token(nil), 2, pagetoken.IntRange("id", 1, 100))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(ranges(ts)).To(Equal([][2]string{{"", "50"}, {"50", ""}}))
+	})
+
+	It("keeps the bound of a split shard", func() {
+		ts, err := pagetoken.SplitToken(at(0), 2, pagetoken.IntRange("id", 1, 100))
+		Expect(err).NotTo(HaveOccurred())
+
+		ts, err = pagetoken.SplitToken(ts[0], 2, pagetoken.IntRange("id", 1, 100))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ranges(ts)).To(Equal([][2]string{{"0", "25"}, {"25", "50"}}))
+	})
+
+	It("returns fewer tokens than requested for few remaining keys", func() {
+		ts, err := pagetoken.SplitToken(at(98), 5, pagetoken.IntRange("id", 1, 100))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ranges(ts)).To(Equal([][2]string{{"98", "99"}, {"99", ""}}))
+
+		t := at(100)
+		ts, err = pagetoken.SplitToken(t, 5, pagetoken.IntRange("id", 1, 100))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ts).To(Equal([]*pagetoken.KeysetToken{t}))
+	})
+
+	It("divides the remaining 128-bit identifiers evenly", func() {
+		var from, to [16]byte
+		for i := range to {
+			to[i] = 0xff
+		}
+
+		ts, err := pagetoken.SplitToken(token(nil), 4, pagetoken.UUIDRange("id", from, to))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(ranges(ts)).To(Equal([][2]string{
+			{"", "3fffffffffffffffffffffffffffffff"},
+			{"3fffffffffffffffffffffffffffffff", "7fffffffffffffffffffffffffffffff"},
+			{"7fffffffffffffffffffffffffffffff", "bfffffffffffffffffffffffffffffff"},
+			{"bfffffffffffffffffffffffffffffff", ""},
+		}))
+
+		u, _, err := ts[1].Payload().UUID("id")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(hex.EncodeToString(u[:])).To(Equal("3fffffffffffffffffffffffffffffff"))
+	})
+
+	It("serializes the upper bound", func() {
+		ts, err := pagetoken.SplitToken(at(0), 2, pagetoken.IntRange("id", 1, 100))
+		Expect(err).NotTo(HaveOccurred())
+
+		s, err := ts[0].Next().String()
+		Expect(err).NotTo(HaveOccurred())
+
+		t, err := pagetoken.NewKeysetTokenParser(pagetoken.WithKeysetTokenEncryptor(e)).Parse(s)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(t.UpperBound().Values()).To(Equal([]pagetoken.KeysetValue{{Path: "id", Value: "50", Order: order.Asc}}))
+	})
+
+	DescribeTable("rejects tokens that cannot be split",
+		func(t func() *pagetoken.KeysetToken, n int, bounds pagetoken.RangeBounds) {
+			_, err := pagetoken.SplitToken(t(), n, bounds)
+			Expect(err).To(MatchError(pagetoken.ErrUnsplittable))
+		},
+		Entry("keysets of other keys", func() *pagetoken.KeysetToken {
+			return token(pagetoken.NewKeysetPayloadBuilder().AddString("name", "ada", order.Asc).Build())
+		}, 2, pagetoken.IntRange("id", 1, 100)),
+		Entry("keysets of several keys", func() *pagetoken.KeysetToken {
+			return token(pagetoken.NewKeysetPayloadBuilder().AddInt("sort", 1, order.Asc).AddInt("id", 1, order.Asc).Build())
+		}, 2, pagetoken.IntRange("id", 1, 100)),
+		Entry("descending keys", func() *pagetoken.KeysetToken {
+			return token(pagetoken.NewKeysetPayloadBuilder().AddInt("id", 1, order.Desc).Build())
+		}, 2, pagetoken.IntRange("id", 1, 100)),
+		Entry("keys of another type", func() *pagetoken.KeysetToken {
+			return token(pagetoken.NewKeysetPayloadBuilder().AddString("id", "abc", order.Asc).Build())
+		}, 2, pagetoken.IntRange("id", 1, 100)),
+		Entry("integer keys of a UUID range", func() *pagetoken.KeysetToken {
+			return token(pagetoken.NewKeysetPayloadBuilder().AddInt("id", 1, order.Asc).Build())
+		}, 2, pagetoken.UUIDRange("id", [16]byte{}, [16]byte{0xff})),
+		Entry("no key space", func() *pagetoken.KeysetToken { return token(nil) }, 2, pagetoken.RangeBounds{}),
+		Entry("no shards", func() *pagetoken.KeysetToken { return token(nil) }, 0, pagetoken.IntRange("id", 1, 100)),
+	)
+
+	It("partitions the remaining keys without gaps or overlaps", func() {
+		for n := 1; n <= 7; n++ {
+			ts, err := pagetoken.SplitToken(at(3), n, pagetoken.IntRange("id", 1, 50))
+			Expect(err).NotTo(HaveOccurred())
+
+			next := int64(4)
+			for i, t := range ts {
+				start, _, err := t.Payload().Int64("id")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(start + 1).To(Equal(next))
+
+				if i == len(ts)-1 {
+					Expect(t.UpperBound()).To(BeNil())
+					break
+				}
+				end, err := strconv.ParseInt(t.UpperBound().Values()[0].Value, 10, 64)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(end).To(BeNumerically(">=", start+1))
+				next = end + 1
+			}
+		}
+	})
+})
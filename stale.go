@@ -0,0 +1,51 @@
+package pagetoken
+
+import (
+	"fmt"
+	"slices"
+)
+
+// KeysetColumnsFn returns the keyset columns the listing of req is currently
+// sorted by, in order, or nil if its page tokens are not to be checked.
+type KeysetColumnsFn func(req Request) []string
+
+// WithExpectedKeysetColumns makes the reader reject page tokens whose keyset
+// paths are not exactly the columns fn returns for the request, in the same
+// order, with an error wrapping ErrStaleToken. It catches tokens issued
+// before the sort order of an endpoint changed, which would otherwise
+// continue on columns the listing is no longer sorted by:
+//
+//	pagetoken.WithExpectedKeysetColumns(func(req pagetoken.Request) []string {
+//	    return []string{"updated_at", "id"}
+//	})
+//
+// Tokens are checked after their checksum, so ErrChecksumMismatch takes
+// precedence. Tokens without keyset values, such as those of composite
+// listings, are not checked.
+func WithExpectedKeysetColumns(fn KeysetColumnsFn) RequestReaderOpt {
+	return func(rr *RequestReader) {
+		rr.keysetColumns = fn
+	}
+}
+
+func (r *RequestReader) checkKeysetColumns(t *KeysetToken, req Request) error {
+	if r.keysetColumns == nil || t.payload == nil || len(t.payload.vs) == 0 {
+		return nil
+	}
+
+	want := r.keysetColumns(req)
+	if want == nil {
+		return nil
+	}
+
+	got := make([]string, len(t.payload.vs))
+	for i, v := range t.payload.vs {
+		got[i] = v.Path
+	}
+
+	if !slices.Equal(got, want) {
+		return fmt.Errorf("%w: keyset columns %q, expected %q", ErrStaleToken, got, want)
+	}
+
+	return nil
+}
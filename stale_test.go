@@ -0,0 +1,105 @@
+package pagetoken_test
+
+import (
+	"net/http"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/encryption"
+	"github.com/pixlcrashr/go-pagetoken/order"
+)
+
+var _ = Describe("Stale tokens", func() {
+	var (
+		e      encryption.Crypter
+		issuer *pagetoken.RequestReader
+	)
+
+	BeforeEach(func() {
+		key, err := encryption.Rand32ByteKey()
+		Expect(err).NotTo(HaveOccurred())
+		e, err = encryption.NewAEADEncryptor(key)
+		Expect(err).NotTo(HaveOccurred())
+		issuer = pagetoken.NewRequestReader(pagetoken.WithEncryptor(e))
+	})
+
+	// issue returns a token of the second page whose keyset has columns.
+	issue := func(req filterRequest, columns ...string) string {
+		t, err := issuer.Read(req)
+		Expect(err).NotTo(HaveOccurred())
+
+		b := pagetoken.NewKeysetPayloadBuilder()
+		for _, c := range columns {
+			b.AddString(c, "v", order.Asc)
+		}
+		s, err := t.Next(pagetoken.WithKeysetPayload(b.Build())).String()
+		Expect(err).NotTo(HaveOccurred())
+		return s
+	}
+
+	reader := func(columns ...string) *pagetoken.RequestReader {
+		return pagetoken.NewRequestReader(
+			pagetoken.WithEncryptor(e),
+			pagetoken.WithExpectedKeysetColumns(func(pagetoken.Request) []string { return columns }),
+		)
+	}
+
+	DescribeTable("compares the keyset columns",
+		func(columns []string, stale bool) {
+			_, err := reader("updated_at", "id").Read(filterRequest{status: "active", token: issue(filterRequest{status: "active"}, columns...)})
+			if !stale {
+				Expect(err).NotTo(HaveOccurred())
+				return
+			}
+
+			Expect(err).To(MatchError(pagetoken.ErrStaleToken))
+			Expect(err).NotTo(MatchError(pagetoken.ErrChecksumMismatch))
+			Expect(pagetoken.HTTPStatus(err)).To(Equal(http.StatusBadRequest))
+		},
+		Entry("accepts the same columns", []string{"updated_at", "id"}, false),
+		Entry("rejects other columns", []string{"created_at", "id"}, true),
+		Entry("rejects a subset", []string{"id"}, true),
+		Entry("rejects a superset", []string{"updated_at", "id", "name"}, true),
+		Entry("rejects reordered columns", []string{"id", "updated_at"}, true),
+	)
+
+	It("reports a checksum mismatch first", func() {
+		_, err := reader("updated_at", "id").Read(filterRequest{status: "inactive", token: issue(filterRequest{status: "active"}, "created_at", "id")})
+		Expect(err).To(MatchError(pagetoken.ErrChecksumMismatch))
+		Expect(err).NotTo(MatchError(pagetoken.ErrStaleToken))
+	})
+
+	It("does not check the first page", func() {
+		_, err := reader("updated_at", "id").Read(filterRequest{status: "active"})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("does not check tokens without keyset values", func() {
+		_, err := reader("updated_at", "id").Read(filterRequest{status: "active", token: issue(filterRequest{status: "active"})})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("does not check requests without expected columns", func() {
+		_, err := reader().Read(filterRequest{status: "active", token: issue(filterRequest{status: "active"}, "created_at")})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("passes the request to the columns function", func() {
+		rr := pagetoken.NewRequestReader(
+			pagetoken.WithEncryptor(e),
+			pagetoken.WithExpectedKeysetColumns(func(req pagetoken.Request) []string {
+				if req.(filterRequest).status == "archived" {
+					return []string{"archived_at", "id"}
+				}
+				return []string{"updated_at", "id"}
+			}),
+		)
+
+		_, err := rr.Read(filterRequest{status: "archived", token: issue(filterRequest{status: "archived"}, "archived_at", "id")})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = rr.Read(filterRequest{status: "active", token: issue(filterRequest{status: "active"}, "archived_at", "id")})
+		Expect(err).To(MatchError(pagetoken.ErrStaleToken))
+	})
+})
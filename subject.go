@@ -0,0 +1,63 @@
+package pagetoken
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/pixlcrashr/go-pagetoken/encryption"
+)
+
+// SubjectFn returns the subject a page token of req is bound to, e.g. the
+// tenant or user the listing belongs to.
+type SubjectFn func(req Request) []byte
+
+// WithSubject binds the page tokens of the reader to the subject fn returns
+// for the request, so that a token issued for one subject fails to decrypt
+// for another, even under the same key. The subject is authenticated as the
+// associated data of the token's AEAD and is not part of the token:
+//
+//	pagetoken.WithSubject(func(req pagetoken.Request) []byte {
+//	    return []byte(req.(*ListUsersRequest).TenantID)
+//	})
+//
+// Tokens presented with another subject are rejected with ErrInvalidToken,
+// like tampered ones. An empty subject is the same as none, so tokens issued
+// without a subject stay valid for requests without one. The encryptor of
+// the reader must implement encryption.AADCrypter, as all encryptors of
+// this module do; reads fail otherwise.
+//
+// Middleware cannot bind tokens, as it runs before the request is parsed,
+// so it only checks their length and alphabet for readers with a subject,
+// and ReadContext decrypts them.
+func WithSubject(fn SubjectFn) RequestReaderOpt {
+	return func(rr *RequestReader) {
+		rr.subject = fn
+	}
+}
+
+// crypter returns the crypter for the page tokens of req.
+func (r *RequestReader) crypter(req Request) (encryption.Crypter, error) {
+	if r.subject == nil {
+		return r.e, nil
+	}
+
+	return r.bind(r.subject(req))
+}
+
+// bind returns the crypter for page tokens of subject.
+func (r *RequestReader) bind(subject []byte) (encryption.Crypter, error) {
+	c, ok := r.e.(encryption.AADCrypter)
+	if !ok {
+		return nil, fmt.Errorf("pagetoken: subject binding requires an encryption.AADCrypter, got %T", r.e)
+	}
+
+	return encryption.BindAAD(c, subject), nil
+}
+
+// subjectCacheKey returns the decode cache key of token for subject, which
+// is prefixed by its length so that no two pairs share a key.
+func subjectCacheKey(subject []byte, token string) string {
+	b := binary.AppendUvarint(nil, uint64(len(subject)))
+	b = append(b, subject...)
+	return string(append(b, token...))
+}
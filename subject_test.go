@@ -0,0 +1,152 @@
+package pagetoken_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/checksum"
+	"github.com/pixlcrashr/go-pagetoken/encryption"
+	"github.com/pixlcrashr/go-pagetoken/order"
+	"github.com/pixlcrashr/go-pagetoken/pagetokentest"
+)
+
+type tenantRequest struct {
+	tenant string
+	token  string
+}
+
+func (r tenantRequest) GetChecksumFields() []checksum.BuilderOpt {
+	return nil
+}
+
+func (r tenantRequest) GetPageToken() string {
+	return r.token
+}
+
+func tenantSubject(req pagetoken.Request) []byte {
+	return []byte(req.(tenantRequest).tenant)
+}
+
+var _ = Describe("WithSubject", func() {
+	var (
+		e      *encryption.AEADEncryptor
+		reader *pagetoken.RequestReader
+	)
+
+	BeforeEach(func() {
+		key, err := encryption.Rand32ByteKey()
+		Expect(err).NotTo(HaveOccurred())
+		e, err = encryption.NewAEADEncryptor(key)
+		Expect(err).NotTo(HaveOccurred())
+
+		reader = pagetoken.NewRequestReader(
+			pagetoken.WithEncryptor(e),
+			pagetoken.WithSubject(tenantSubject),
+			pagetoken.WithDecodeCache(8),
+		)
+	})
+
+	payload := pagetoken.NewKeysetPayloadBuilder().AddInt("id", 42, order.Asc).Build()
+
+	// secondPage returns the token of the second page of tenant.
+	secondPage := func(tenant string) string {
+		t, err := reader.Read(tenantRequest{tenant: tenant})
+		Expect(err).NotTo(HaveOccurred())
+		s, err := reader.NextToken(t, payload)
+		Expect(err).NotTo(HaveOccurred())
+		return s
+	}
+
+	It("accepts tokens of the same subject", func() {
+		t, err := reader.Read(tenantRequest{tenant: "a", token: secondPage("a")})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(t.PageIndex()).To(Equal(1))
+
+		s, err := t.Next().String()
+		Expect(err).NotTo(HaveOccurred())
+		_, err = reader.Read(tenantRequest{tenant: "a", token: s})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("rejects tokens of another subject", func() {
+		s := secondPage("a")
+
+		_, err := reader.Read(tenantRequest{tenant: "b", token: s})
+		Expect(err).To(MatchError(pagetoken.ErrInvalidToken))
+	})
+
+	It("does not serve tokens of another subject from the decode cache", func() {
+		s := secondPage("a")
+		_, err := reader.Read(tenantRequest{tenant: "a", token: s})
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = reader.Read(tenantRequest{tenant: "b", token: s})
+		Expect(err).To(MatchError(pagetoken.ErrInvalidToken))
+	})
+
+	It("keeps the subject out of the token", func() {
+		s := secondPage("tenant-with-a-long-identifier")
+
+		d, err := e.DecryptWithAAD(s, []byte("tenant-with-a-long-identifier"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(d)).NotTo(ContainSubstring("tenant-with-a-long-identifier"))
+	})
+
+	It("binds tokens computed for a request", func() {
+		t, err := pagetoken.NewRequestReader(pagetoken.WithEncryptor(e)).Read(tenantRequest{})
+		Expect(err).NotTo(HaveOccurred())
+		s, err := reader.NextTokenFor(tenantRequest{tenant: "a"}, t, payload)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = reader.Read(tenantRequest{tenant: "a", token: s})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = reader.Read(tenantRequest{tenant: "b", token: s})
+		Expect(err).To(MatchError(pagetoken.ErrInvalidToken))
+	})
+
+	Describe("an empty subject", func() {
+		It("accepts tokens issued without a subject", func() {
+			unbound := pagetoken.NewRequestReader(pagetoken.WithEncryptor(e))
+			t, err := unbound.Read(tenantRequest{})
+			Expect(err).NotTo(HaveOccurred())
+			s, err := unbound.NextToken(t, payload)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = reader.Read(tenantRequest{token: s})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("rejects tokens of a non-empty subject", func() {
+			_, err := reader.Read(tenantRequest{token: secondPage("a")})
+			Expect(err).To(MatchError(pagetoken.ErrInvalidToken))
+		})
+	})
+
+	It("defers decryption from Middleware to ReadContext", func() {
+		s := secondPage("a")
+
+		var err error
+		h := pagetoken.Middleware(reader)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, ok := pagetoken.FromContext(r.Context())
+			Expect(ok).To(BeFalse())
+			_, err = reader.ReadContext(r.Context(), tenantRequest{tenant: "b", token: s})
+		}))
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?page_token="+s, nil))
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(err).To(MatchError(pagetoken.ErrInvalidToken))
+	})
+
+	It("fails for encryptors without associated data", func() {
+		_, err := pagetoken.NewRequestReader(
+			pagetoken.WithEncryptor(pagetokentest.StaticCrypter{}),
+			pagetoken.WithSubject(tenantSubject),
+		).Read(tenantRequest{tenant: "a"})
+		Expect(err).To(MatchError(ContainSubstring("encryption.AADCrypter")))
+	})
+})
@@ -0,0 +1,5 @@
+// Package booksv1 contains the messages and service of books.proto, the
+// AIP-158 list service of the gRPC example.
+//
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative books.proto
+package booksv1
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/encryption"
+)
+
+func main() {
+	addr := flag.String("addr", "127.0.0.1:9090", "address to listen on")
+	flag.Parse()
+
+	db, err := openDB()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to open database: %v\n", err)
+		os.Exit(1)
+	}
+
+	reader, err := newReader()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to create page token reader: %v\n", err)
+		os.Exit(1)
+	}
+
+	lis, err := net.Listen("tcp", *addr)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to listen: %v\n", err)
+		os.Exit(1)
+	}
+
+	srv := newServer(db, reader)
+
+	fmt.Printf("Listening on %s\n", *addr)
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+
+	go func() {
+		if err := srv.Serve(lis); err != nil {
+			fmt.Fprintf(os.Stderr, "server error: %v\n", err)
+		}
+	}()
+
+	sig := <-quit
+	fmt.Printf("\nReceived signal %s, shutting down gracefully...\n", sig)
+
+	srv.GracefulStop()
+
+	fmt.Println("Server stopped.")
+}
+
+// newReader returns the page token reader of the server, following AIP-158
+// with a default page size of 20 and a maximum of 100.
+func newReader() (*pagetoken.RequestReader, error) {
+	k, err := encryption.Rand32ByteKey()
+	if err != nil {
+		return nil, err
+	}
+
+	e, err := encryption.NewAEADEncryptor(k)
+	if err != nil {
+		return nil, err
+	}
+
+	return pagetoken.NewRequestReader(
+		pagetoken.WithEncryptor(e),
+		pagetoken.WithAIP158(),
+		pagetoken.WithPageSizeLimits(20, 100),
+	), nil
+}
+
+// openDB returns a seeded in-memory SQLite database.
+func openDB() (*gorm.DB, error) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{
+		Logger: logger.Discard,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// every connection to an in-memory SQLite database would open a
+	// database of its own
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetMaxOpenConns(1)
+
+	if err := db.AutoMigrate(&book{}); err != nil {
+		return nil, fmt.Errorf("failed to migrate database: %v", err)
+	}
+
+	if err := seed(db); err != nil {
+		return nil, fmt.Errorf("failed to seed database: %v", err)
+	}
+
+	return db, nil
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// book is the database row of a book.
+type book struct {
+	ID    int64 `gorm:"primaryKey"`
+	Title string
+}
+
+func seed(db *gorm.DB) error {
+	books := make([]book, 100)
+	for i := range books {
+		books[i] = book{
+			ID:    int64(i + 1),
+			Title: fmt.Sprintf("Book %03d", i+1),
+		}
+	}
+	return db.Create(&books).Error
+}
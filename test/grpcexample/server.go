@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"unicode/utf8"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	ptgorm "github.com/pixlcrashr/go-pagetoken/database/gorm"
+	"github.com/pixlcrashr/go-pagetoken/integration/pagetokengrpc"
+	"github.com/pixlcrashr/go-pagetoken/order"
+	"github.com/pixlcrashr/go-pagetoken/test/grpcexample/booksv1"
+)
+
+// orderByFields are the fields order_by may sort by.
+var orderByFields = []string{"id", "title"}
+
+// defaultSpec is the order of the books without order_by.
+var defaultSpec = pagetoken.SortSpec{{Path: "id", Order: order.Asc}}
+
+// bookServer implements booksv1.BookService on top of the books table.
+type bookServer struct {
+	booksv1.UnimplementedBookServiceServer
+
+	db     *gorm.DB
+	reader *pagetoken.RequestReader
+}
+
+// newServer returns a gRPC server serving the books of db. The page tokens
+// of ListBooks are read by the interceptor and bound to filter and
+// order_by, so clients may change page_size between pages as AIP-158
+// allows.
+func newServer(db *gorm.DB, reader *pagetoken.RequestReader) *grpc.Server {
+	srv := grpc.NewServer(grpc.UnaryInterceptor(pagetokengrpc.UnaryServerInterceptor(reader,
+		pagetokengrpc.WithMethod(booksv1.BookService_ListBooks_FullMethodName, pagetokengrpc.FieldMask("filter", "order_by")),
+	)))
+	booksv1.RegisterBookServiceServer(srv, &bookServer{db: db, reader: reader})
+
+	return srv
+}
+
+func (s *bookServer) ListBooks(ctx context.Context, req *booksv1.ListBooksRequest) (*booksv1.ListBooksResponse, error) {
+	t, ok := pagetoken.FromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Internal, "no page token in context")
+	}
+
+	spec, err := parseOrderBy(req.GetOrderBy())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid order_by: %v", err)
+	}
+
+	q := s.db.WithContext(ctx).Model(&book{})
+	if f := req.GetFilter(); f != "" {
+		q = q.Where("substr(title, 1, ?) = ?", utf8.RuneCountInString(f), f)
+	}
+
+	// t.PageSize is the page size of the request with the default and the
+	// maximum of the reader applied
+	books, next, err := ptgorm.ListKeyset(q, t.Payload(), t.PageSize(), ptgorm.ListConfig[book]{
+		Spec:     spec,
+		ValueFn:  bookValue,
+		KeysetFn: bookKeyset,
+	})
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to list books")
+	}
+
+	resp := &booksv1.ListBooksResponse{Books: make([]*booksv1.Book, len(books))}
+	for i, b := range books {
+		resp.Books[i] = &booksv1.Book{Id: b.ID, Title: b.Title}
+	}
+
+	resp.NextPageToken, err = s.reader.NextTokenContext(ctx, t, next)
+	if err != nil {
+		return nil, pagetokengrpc.Status(err).Err()
+	}
+
+	return resp, nil
+}
+
+// parseOrderBy parses an order_by such as "title desc".
+func parseOrderBy(s string) (pagetoken.SortSpec, error) {
+	if s == "" {
+		return defaultSpec, nil
+	}
+
+	fs := order.Fields{}
+	if err := fs.UnmarshalString(s); err != nil {
+		return nil, err
+	}
+
+	for _, f := range fs {
+		if !slices.Contains(orderByFields, f.Path) {
+			return nil, fmt.Errorf("unknown field %q", f.Path)
+		}
+	}
+
+	return fs, nil
+}
+
+// bookValue decodes the keyset value of column.
+func bookValue(column string, payload *pagetoken.KeysetPayload) (any, error) {
+	switch column {
+	case "id":
+		v, _, err := payload.Int64(column)
+		return v, err
+	case "title":
+		v, _, err := payload.String(column)
+		return v, err
+	default:
+		return nil, errors.New("unknown column: " + column)
+	}
+}
+
+// bookKeyset builds the keyset of b for spec.
+func bookKeyset(b book, spec pagetoken.SortSpec) (*pagetoken.KeysetPayload, error) {
+	kb := pagetoken.NewKeysetPayloadBuilder()
+	for _, f := range spec {
+		switch f.Path {
+		case "id":
+			kb.AddInt64(f.Path, b.ID, f.Order)
+		case "title":
+			kb.AddString(f.Path, b.Title, f.Order)
+		default:
+			return nil, errors.New("unknown column: " + f.Path)
+		}
+	}
+
+	return kb.Build(), nil
+}
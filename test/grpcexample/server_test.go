@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"net"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/pixlcrashr/go-pagetoken/integration/pagetokengrpc"
+	"github.com/pixlcrashr/go-pagetoken/test/grpcexample/booksv1"
+)
+
+var _ = Describe("BookService", func() {
+	var (
+		client booksv1.BookServiceClient
+		ctx    context.Context
+	)
+
+	BeforeEach(func() {
+		db, err := openDB()
+		Expect(err).NotTo(HaveOccurred())
+		sqlDB, err := db.DB()
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(sqlDB.Close)
+
+		reader, err := newReader()
+		Expect(err).NotTo(HaveOccurred())
+
+		lis := bufconn.Listen(1 << 20)
+		srv := newServer(db, reader)
+		go func() {
+			defer GinkgoRecover()
+			Expect(srv.Serve(lis)).To(Succeed())
+		}()
+		DeferCleanup(srv.Stop)
+
+		conn, err := grpc.NewClient("passthrough:///bufnet",
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+				return lis.DialContext(ctx)
+			}),
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(conn.Close)
+
+		client = booksv1.NewBookServiceClient(conn)
+		ctx = context.Background()
+	})
+
+	titles := func(resp *booksv1.ListBooksResponse) []string {
+		out := []string{}
+		for _, b := range resp.GetBooks() {
+			out = append(out, b.GetTitle())
+		}
+		return out
+	}
+
+	// walk lists all pages of req, changing nothing but the page token, and
+	// returns the titles per page
+	walk := func(req *booksv1.ListBooksRequest) [][]string {
+		pages := [][]string{}
+		for {
+			resp, err := client.ListBooks(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			pages = append(pages, titles(resp))
+
+			if resp.GetNextPageToken() == "" {
+				return pages
+			}
+			req.PageToken = resp.GetNextPageToken()
+		}
+	}
+
+	reasonOf := func(err error) string {
+		st, ok := status.FromError(err)
+		Expect(ok).To(BeTrue())
+		Expect(st.Code()).To(Equal(codes.InvalidArgument))
+
+		for _, d := range st.Details() {
+			if info, ok := d.(*errdetails.ErrorInfo); ok {
+				Expect(info.GetDomain()).To(Equal(pagetokengrpc.ErrorDomain))
+				return info.GetReason()
+			}
+		}
+		return ""
+	}
+
+	It("walks all pages and returns no next page token on the last one", func() {
+		pages := walk(&booksv1.ListBooksRequest{PageSize: 30})
+		Expect(pages).To(HaveLen(4))
+		Expect(pages[0]).To(HaveLen(30))
+		Expect(pages[0][0]).To(Equal("Book 001"))
+		Expect(pages[3]).To(HaveLen(10))
+		Expect(pages[3][9]).To(Equal("Book 100"))
+	})
+
+	It("applies the default and the maximum page size", func() {
+		resp, err := client.ListBooks(ctx, &booksv1.ListBooksRequest{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.GetBooks()).To(HaveLen(20))
+
+		resp, err = client.ListBooks(ctx, &booksv1.ListBooksRequest{PageSize: 1000})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.GetBooks()).To(HaveLen(100))
+		Expect(resp.GetNextPageToken()).To(BeEmpty())
+	})
+
+	It("allows the page size to change between pages", func() {
+		resp, err := client.ListBooks(ctx, &booksv1.ListBooksRequest{PageSize: 10, OrderBy: "title desc"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(titles(resp)[0]).To(Equal("Book 100"))
+
+		resp, err = client.ListBooks(ctx, &booksv1.ListBooksRequest{PageSize: 5, OrderBy: "title desc", PageToken: resp.GetNextPageToken()})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(titles(resp)).To(Equal([]string{"Book 090", "Book 089", "Book 088", "Book 087", "Book 086"}))
+	})
+
+	It("filters and orders the books", func() {
+		pages := walk(&booksv1.ListBooksRequest{PageSize: 4, Filter: "Book 05", OrderBy: "title desc"})
+		Expect(pages).To(Equal([][]string{
+			{"Book 059", "Book 058", "Book 057", "Book 056"},
+			{"Book 055", "Book 054", "Book 053", "Book 052"},
+			{"Book 051", "Book 050"},
+		}))
+	})
+
+	It("rejects a token presented with another filter", func() {
+		resp, err := client.ListBooks(ctx, &booksv1.ListBooksRequest{PageSize: 4, Filter: "Book 05"})
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = client.ListBooks(ctx, &booksv1.ListBooksRequest{PageSize: 4, Filter: "Book 06", PageToken: resp.GetNextPageToken()})
+		Expect(reasonOf(err)).To(Equal(pagetokengrpc.ReasonChecksumMismatch))
+	})
+
+	It("rejects a tampered token with INVALID_ARGUMENT", func() {
+		resp, err := client.ListBooks(ctx, &booksv1.ListBooksRequest{PageSize: 10})
+		Expect(err).NotTo(HaveOccurred())
+
+		tampered := []byte(resp.GetNextPageToken())
+		tampered[len(tampered)/2] ^= 'A' ^ 'B'
+
+		_, err = client.ListBooks(ctx, &booksv1.ListBooksRequest{PageSize: 10, PageToken: string(tampered)})
+		Expect(reasonOf(err)).To(Equal(pagetokengrpc.ReasonInvalidPageToken))
+	})
+
+	It("rejects a negative page size", func() {
+		_, err := client.ListBooks(ctx, &booksv1.ListBooksRequest{PageSize: -1})
+		Expect(reasonOf(err)).To(Equal(pagetokengrpc.ReasonInvalidPageSize))
+	})
+
+	It("rejects an unknown order_by field", func() {
+		_, err := client.ListBooks(ctx, &booksv1.ListBooksRequest{OrderBy: "price"})
+		Expect(status.Code(err)).To(Equal(codes.InvalidArgument))
+	})
+})
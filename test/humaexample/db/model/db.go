@@ -6,9 +6,17 @@ import (
 	"github.com/google/uuid"
 )
 
+type Author struct {
+	ID    uuid.UUID `gorm:"id;primaryKey"`
+	Name  string    `gorm:"name"`
+	Books []Book    `gorm:"foreignKey:AuthorID"`
+}
+
 type Book struct {
 	ID          uuid.UUID `gorm:"id;primaryKey"`
+	AuthorID    uuid.UUID `gorm:"author_id;index"`
 	DisplayName string    `gorm:"display_name"`
+	Subtitle    *string   `gorm:"subtitle"`
 	CreatedAt   time.Time `gorm:"created_at"`
 	UpdatedAt   time.Time `gorm:"updated_at"`
 }
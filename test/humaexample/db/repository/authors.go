@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/pixlcrashr/go-pagetoken"
+	ptGorm "github.com/pixlcrashr/go-pagetoken/database/gorm"
+	"github.com/pixlcrashr/go-pagetoken/database/sqlraw"
+	"github.com/pixlcrashr/go-pagetoken/order"
+	"github.com/pixlcrashr/go-pagetoken/test/humaexample/db/model"
+	"gorm.io/gorm"
+)
+
+type AuthorsRepository struct {
+	DB *gorm.DB
+}
+
+// AuthorBook is a book joined with its author.
+type AuthorBook struct {
+	model.Book
+	AuthorName string
+}
+
+// authorBooksSpec orders the books of an author by the name of the author
+// and their creation time, with the book id as tiebreak. The name is the
+// same for all books of an author, but keeps the sort column of the joined
+// table in the keyset.
+var authorBooksSpec = pagetoken.SortSpec{
+	{Path: "author_name", Order: order.Asc},
+	{Path: "created_at", Order: order.Asc},
+	{Path: "id", Order: order.Asc},
+}
+
+// authorBookColumns maps the keyset paths of the books of an author to the
+// qualified columns of the join, as books and authors both have an id.
+var authorBookColumns = map[string]string{
+	"author_name": "authors.name",
+	"created_at":  "books.created_at",
+	"id":          "books.id",
+}
+
+func authorBookColumn(path string) string {
+	if c, ok := authorBookColumns[path]; ok {
+		return c
+	}
+
+	return path
+}
+
+// ListBooksByKeyset lists one page of the books of the author authorID,
+// joined with the author, using gorm's query builder.
+func (r *AuthorsRepository) ListBooksByKeyset(
+	ctx context.Context,
+	authorID uuid.UUID,
+	pageSize int,
+	keyset *pagetoken.KeysetPayload,
+) (ms []*AuthorBook, next *pagetoken.KeysetPayload, err error) {
+	q := r.DB.WithContext(ctx).
+		Model(&model.Book{}).
+		Select("books.*, authors.name AS author_name").
+		Joins("JOIN authors ON authors.id = books.author_id").
+		Where("books.author_id = ?", authorID)
+
+	q, err = ptGorm.KeysetWhereOrderLimit(
+		q,
+		keyset,
+		authorBookKeysetValue,
+		ptGorm.WithSortSpec(authorBooksSpec),
+		ptGorm.WithColumnMapping(authorBookColumn),
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to apply keyset: %w", err)
+	}
+
+	q = q.Limit(pageSize + 1)
+
+	ms = []*AuthorBook{}
+	if err := q.Find(&ms).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to query books: %w", err)
+	}
+
+	if len(ms) > pageSize {
+		next = nextAuthorBookKeyset(ms[pageSize-1])
+	}
+
+	return ms[:minPageSize(len(ms), pageSize)], next, nil
+}
+
+// ListBooksByKeysetSQL is like ListBooksByKeyset, but runs a hand-written
+// SQL query with the keyset condition rendered by sqlraw. Both return
+// identical pages for identical inputs.
+func (r *AuthorsRepository) ListBooksByKeysetSQL(
+	ctx context.Context,
+	authorID uuid.UUID,
+	pageSize int,
+	keyset *pagetoken.KeysetPayload,
+) (ms []*AuthorBook, next *pagetoken.KeysetPayload, err error) {
+	conds := []string{"books.author_id = ?"}
+	args := []any{authorID}
+
+	b := sqlraw.NewBuilder(sqlraw.WithColumnFn(authorBookColumn))
+
+	// KeysetWhere validates the paths of keyset, so its sort spec is safe
+	// to render below
+	where, kArgs, err := b.KeysetWhere(keyset, authorBookKeysetValue)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to apply keyset: %w", err)
+	}
+	if where != "" {
+		conds = append(conds, where)
+		args = append(args, kArgs...)
+	}
+
+	spec := authorBooksSpec
+	if keyset != nil && len(keyset.Values()) > 0 {
+		spec = keyset.SortSpec()
+	}
+
+	query := "SELECT books.id, books.author_id, books.display_name, books.subtitle, books.created_at, books.updated_at, authors.name AS author_name" +
+		" FROM books JOIN authors ON authors.id = books.author_id" +
+		" WHERE " + strings.Join(conds, " AND ") +
+		" ORDER BY " + b.OrderBy(spec) + " LIMIT ?"
+	args = append(args, pageSize+1)
+
+	ms = []*AuthorBook{}
+	if err := r.DB.WithContext(ctx).Raw(query, args...).Scan(&ms).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to query books: %w", err)
+	}
+
+	if len(ms) > pageSize {
+		next = nextAuthorBookKeyset(ms[pageSize-1])
+	}
+
+	return ms[:minPageSize(len(ms), pageSize)], next, nil
+}
+
+// authorBookKeysetValue decodes the keyset value of column, which is a
+// keyset path rather than a qualified column, see bookKeysetValue.
+func authorBookKeysetValue(column string, payload *pagetoken.KeysetPayload) (any, error) {
+	if column == "author_name" {
+		v, _, err := payload.String(column)
+		if err != nil {
+			return nil, err
+		}
+		return v, nil
+	}
+
+	return bookKeysetValue(column, payload)
+}
+
+// nextAuthorBookKeyset builds the keyset of the page after m, the last book
+// of the current page.
+func nextAuthorBookKeyset(m *AuthorBook) *pagetoken.KeysetPayload {
+	return pagetoken.NewKeysetPayloadBuilder().
+		AddString("author_name", m.AuthorName, authorBooksSpec[0].Order).
+		AddTime("created_at", m.CreatedAt, authorBooksSpec[1].Order).
+		AddString("id", m.ID.String(), authorBooksSpec[2].Order).
+		Build()
+}
@@ -4,11 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/pixlcrashr/go-pagetoken"
 	ptGorm "github.com/pixlcrashr/go-pagetoken/database/gorm"
+	"github.com/pixlcrashr/go-pagetoken/database/sqlraw"
 	"github.com/pixlcrashr/go-pagetoken/order"
 	"github.com/pixlcrashr/go-pagetoken/test/humaexample/db/model"
 	"gorm.io/gorm"
@@ -53,36 +55,7 @@ func (r *BooksRepository) ListByKeyset(
 		q = q.Where("id = ?", *filter.IDEq)
 	}
 
-	q, err = ptGorm.KeysetWhereOrderLimit(q, keyset, func(column string, payload *pagetoken.KeysetPayload) (any, error) {
-		switch column {
-		case "id":
-			v, _, err := payload.String(column)
-			if err != nil {
-				return nil, err
-			}
-
-			id, err := uuid.Parse(v)
-			if err != nil {
-				return nil, err
-			}
-
-			return id, nil
-		case "display_name":
-			v, _, err := payload.String(column)
-			if err != nil {
-				return nil, err
-			}
-			return v, nil
-		case "created_at":
-			v, _, err := payload.Time(column)
-			if err != nil {
-				return nil, err
-			}
-			return v, nil
-		default:
-			return nil, errors.New("unknown column: " + column)
-		}
-	})
+	q, err = ptGorm.KeysetWhereOrderLimit(q, keyset, bookKeysetValue)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to apply keyset: %w", err)
 	}
@@ -106,51 +79,153 @@ func (r *BooksRepository) ListByKeyset(
 
 	// TODO: handle this part more DX friendly
 	if len(ms) > pageSize {
-		nextItem := ms[pageSize-1]
-
-		if keyset == nil || len(keyset.Values()) == 0 {
-			if len(o) == 0 {
-				keyset = pagetoken.NewKeysetPayloadBuilder().
-					AddTime("created_at", time.Time{}, order.Desc).
-					Build()
-			} else {
-				b := pagetoken.NewKeysetPayloadBuilder()
-				// empty default setter
-				func(m *model.Book, keysetBuilder *pagetoken.KeysetPayloadBuilder, values order.Fields) {
-					for _, v := range values {
-						switch v.Path {
-						case "id":
-							keysetBuilder.AddString(v.Path, "", v.Order)
-						case "display_name":
-							keysetBuilder.AddString(v.Path, "", v.Order)
-						case "created_at":
-							keysetBuilder.AddTime(v.Path, time.Time{}, v.Order)
-						}
-					}
-				}(nextItem, b, o)
-				keyset = b.Build()
-			}
+		next = nextBookKeyset(ms[pageSize-1], o, keyset)
+	}
+
+	return ms[:minPageSize(len(ms), pageSize)], next, nil
+}
+
+// ListByKeysetSQL is like ListByKeyset, but runs a hand-written SQL query
+// with the keyset condition rendered by sqlraw instead of building it with
+// gorm's query builder. Both return identical pages for identical inputs.
+func (r *BooksRepository) ListByKeysetSQL(
+	ctx context.Context,
+	filter ListFilter,
+	pageSize int,
+	o order.Fields,
+	keyset *pagetoken.KeysetPayload,
+) (ms []*model.Book, next *pagetoken.KeysetPayload, err error) {
+	conds := []string{}
+	args := []any{}
+
+	if filter.DisplayNameEq != nil {
+		conds = append(conds, "display_name = ?")
+		args = append(args, *filter.DisplayNameEq)
+	}
+
+	if filter.DisplayNameLike != nil {
+		conds = append(conds, "display_name LIKE ?")
+		args = append(args, *filter.DisplayNameLike)
+	}
+
+	if filter.IDEq != nil {
+		conds = append(conds, "id = ?")
+		args = append(args, *filter.IDEq)
+	}
+
+	// gorm rebinds the ? placeholders for the dialect of r.DB
+	b := sqlraw.NewBuilder()
+
+	where, kArgs, err := b.KeysetWhere(keyset, bookKeysetValue)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to apply keyset: %w", err)
+	}
+	if where != "" {
+		conds = append(conds, where)
+		args = append(args, kArgs...)
+	}
+
+	var orderBy string
+	switch {
+	case keyset != nil && len(keyset.Values()) > 0:
+		orderBy = b.OrderBy(keyset.SortSpec())
+	case len(o) == 0:
+		orderBy = "created_at DESC"
+	default:
+		orderBy = b.OrderBy(o)
+	}
+
+	query := "SELECT id, display_name, subtitle, created_at, updated_at FROM books"
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+	query += " ORDER BY " + orderBy + " LIMIT ?"
+	args = append(args, pageSize+1)
+
+	ms = []*model.Book{}
+	if err := r.DB.WithContext(ctx).Raw(query, args...).Scan(&ms).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to query books: %w", err)
+	}
+
+	if len(ms) > pageSize {
+		next = nextBookKeyset(ms[pageSize-1], o, keyset)
+	}
+
+	return ms[:minPageSize(len(ms), pageSize)], next, nil
+}
+
+// bookKeysetValue decodes the keyset value of column into the type of the
+// corresponding model.Book field.
+func bookKeysetValue(column string, payload *pagetoken.KeysetPayload) (any, error) {
+	switch column {
+	case "id":
+		v, _, err := payload.String(column)
+		if err != nil {
+			return nil, err
 		}
 
-		keysetBuilder := pagetoken.NewKeysetPayloadBuilder()
+		id, err := uuid.Parse(v)
+		if err != nil {
+			return nil, err
+		}
 
-		func(m *model.Book, keysetBuilder *pagetoken.KeysetPayloadBuilder, values []pagetoken.KeysetValue) {
-			for _, v := range values {
+		return id, nil
+	case "display_name":
+		v, _, err := payload.String(column)
+		if err != nil {
+			return nil, err
+		}
+		return v, nil
+	case "created_at":
+		v, _, err := payload.Time(column)
+		if err != nil {
+			return nil, err
+		}
+		return v, nil
+	default:
+		return nil, errors.New("unknown column: " + column)
+	}
+}
+
+// nextBookKeyset builds the keyset of the page after m, the last book of the
+// current page. On the first page keyset is empty and the columns are taken
+// from o, or default to created_at.
+func nextBookKeyset(m *model.Book, o order.Fields, keyset *pagetoken.KeysetPayload) *pagetoken.KeysetPayload {
+	if keyset == nil || len(keyset.Values()) == 0 {
+		if len(o) == 0 {
+			keyset = pagetoken.NewKeysetPayloadBuilder().
+				AddTime("created_at", time.Time{}, order.Desc).
+				Build()
+		} else {
+			b := pagetoken.NewKeysetPayloadBuilder()
+			// empty default setter
+			for _, v := range o {
 				switch v.Path {
 				case "id":
-					keysetBuilder.AddString(v.Path, m.ID.String(), v.Order)
+					b.AddString(v.Path, "", v.Order)
 				case "display_name":
-					keysetBuilder.AddString(v.Path, m.DisplayName, v.Order)
+					b.AddString(v.Path, "", v.Order)
 				case "created_at":
-					keysetBuilder.AddTime(v.Path, m.CreatedAt, v.Order)
+					b.AddTime(v.Path, time.Time{}, v.Order)
 				}
 			}
-		}(nextItem, keysetBuilder, keyset.Values())
+			keyset = b.Build()
+		}
+	}
 
-		next = keysetBuilder.Build()
+	b := pagetoken.NewKeysetPayloadBuilder()
+	for _, v := range keyset.Values() {
+		switch v.Path {
+		case "id":
+			b.AddString(v.Path, m.ID.String(), v.Order)
+		case "display_name":
+			b.AddString(v.Path, m.DisplayName, v.Order)
+		case "created_at":
+			b.AddTime(v.Path, m.CreatedAt, v.Order)
+		}
 	}
 
-	return ms[:minPageSize(len(ms), pageSize)], next, nil
+	return b.Build()
 }
 
 func minPageSize(a, b int) int {
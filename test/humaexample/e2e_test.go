@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openTestDB returns a seeded in-memory SQLite database, closed when the
+// spec ends.
+func openTestDB() *gorm.DB {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{
+		Logger: logger.Discard,
+	})
+	Expect(err).NotTo(HaveOccurred())
+	Expect(useSingleConn(db)).To(Succeed())
+
+	sqlDB, err := db.DB()
+	Expect(err).NotTo(HaveOccurred())
+	DeferCleanup(sqlDB.Close)
+
+	Expect(resetDB(db, defaultSeedConfig)).To(Succeed())
+
+	return db
+}
+
+var _ = Describe("Server", func() {
+	var srv *Server
+
+	BeforeEach(func() {
+		srv = newServer(openTestDB())
+	})
+
+	get := func(query url.Values) (int, []byte) {
+		resp, err := srv.app.Test(httptest.NewRequest(http.MethodGet, "/api/v1/books/dao?"+query.Encode(), nil))
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		Expect(err).NotTo(HaveOccurred())
+
+		return resp.StatusCode, body
+	}
+
+	page := func(query url.Values) ListBooksResponse {
+		code, body := get(query)
+		Expect(code).To(Equal(http.StatusOK), string(body))
+
+		var resp ListBooksResponse
+		Expect(json.Unmarshal(body, &resp.Body)).To(Succeed())
+		return resp
+	}
+
+	It("walks pages and rejects changed filters and tampered tokens", func() {
+		query := url.Values{"order_by": {"display_name"}, "page_size": {"10"}}
+
+		var token string
+		for i, first := range []string{"Book 001", "Book 011", "Book 021"} {
+			resp := page(query)
+			Expect(resp.Body.Books).To(HaveLen(10), "page %d", i+1)
+			Expect(resp.Body.Books[0].DisplayName).To(Equal(first))
+			Expect(resp.Body.NextPageToken).NotTo(BeEmpty())
+
+			token = resp.Body.NextPageToken
+			query.Set("page_token", token)
+		}
+
+		By("changing a filter")
+		changed := url.Values{"order_by": {"display_name"}, "page_size": {"10"}, "display_name": {"Book 1"}, "page_token": {token}}
+		code, body := get(changed)
+		Expect(code).To(Equal(http.StatusBadRequest))
+		Expect(string(body)).To(ContainSubstring("page_token does not match the request parameters"))
+
+		By("tampering with the token")
+		tampered := []byte(token)
+		tampered[len(tampered)/2] ^= 'A' ^ 'B'
+		query.Set("page_token", string(tampered))
+		code, body = get(query)
+		Expect(code).To(Equal(http.StatusBadRequest))
+		Expect(string(body)).To(ContainSubstring("invalid page_token"))
+
+		By("resuming with the untouched token")
+		query.Set("page_token", token)
+		Expect(page(query).Body.Books[0].DisplayName).To(Equal("Book 031"))
+	})
+})
@@ -4,7 +4,9 @@ import (
 	"context"
 
 	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
 	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/integration/pagetokenhuma"
 	"github.com/pixlcrashr/go-pagetoken/order"
 	"github.com/pixlcrashr/go-pagetoken/test/humaexample/db/model"
 	"github.com/pixlcrashr/go-pagetoken/test/humaexample/db/repository"
@@ -12,28 +14,39 @@ import (
 )
 
 var (
-	ErrInvalidPageToken  = huma.Error400BadRequest("invalid page_token")
 	ErrInvalidOrderBy    = huma.Error400BadRequest("invalid order_by")
 	ErrFailedToListBooks = huma.Error500InternalServerError("failed to list books")
+	ErrInvalidAuthorID   = huma.Error400BadRequest("invalid author id")
 )
 
 // Handler holds the dependencies for the books API.
 type Handler struct {
-	r  *repository.BooksRepository
-	rr *pagetoken.RequestReader
+	r      *repository.BooksRepository
+	a      *repository.AuthorsRepository
+	reader *pagetoken.RequestReader
 }
 
-// ListBooks handles GET /api/v1/books.
-// TODO: implement keyset-pagination using pagetoken.KeysetTokenFromRequest.
-func (h *Handler) ListBooksSQL(_ context.Context, _ *ListBooksRequest) (*ListBooksResponse, error) {
-	return nil, ErrFailedToListBooks
+// listFn lists one page of books, see repository.BooksRepository.ListByKeyset.
+type listFn func(
+	ctx context.Context,
+	filter repository.ListFilter,
+	pageSize int,
+	o order.Fields,
+	keyset *pagetoken.KeysetPayload,
+) ([]*model.Book, *pagetoken.KeysetPayload, error)
+
+// ListBooksSQL handles GET /api/v1/books/sql.
+func (h *Handler) ListBooksSQL(ctx context.Context, req *ListBooksRequest) (*ListBooksResponse, error) {
+	return h.listBooks(ctx, req, h.r.ListByKeysetSQL)
 }
 
+// ListBooksDAO handles GET /api/v1/books/dao.
 func (h *Handler) ListBooksDAO(ctx context.Context, req *ListBooksRequest) (*ListBooksResponse, error) {
-	t, err := h.rr.Read(req)
-	if err != nil {
-		return nil, ErrInvalidPageToken
-	}
+	return h.listBooks(ctx, req, h.r.ListByKeyset)
+}
+
+func (h *Handler) listBooks(ctx context.Context, req *ListBooksRequest, list listFn) (*ListBooksResponse, error) {
+	t := req.Token()
 
 	oFs := order.Fields{}
 	if req.OrderBy != "" {
@@ -42,7 +55,7 @@ func (h *Handler) ListBooksDAO(ctx context.Context, req *ListBooksRequest) (*Lis
 		}
 	}
 
-	ms, nextPayload, err := h.r.ListByKeyset(
+	ms, nextPayload, err := list(
 		ctx,
 		repository.ListFilter{},
 		req.PageSize,
@@ -54,15 +67,9 @@ func (h *Handler) ListBooksDAO(ctx context.Context, req *ListBooksRequest) (*Lis
 	}
 
 	resp := &ListBooksResponse{}
-	if nextPayload != nil {
-		t = t.Next(pagetoken.WithKeysetPayload(nextPayload))
-
-		tS, err := t.String()
-		if err != nil {
-			return nil, ErrInvalidPageToken
-		}
-
-		resp.Body.NextPageToken = tS
+	resp.Body.NextPageToken, err = h.reader.NextToken(t, nextPayload)
+	if err != nil {
+		return nil, pagetokenhuma.Error(err)
 	}
 
 	resp.Body.Books = lo.Map(ms, func(m *model.Book, _ int) Book {
@@ -73,3 +80,50 @@ func (h *Handler) ListBooksDAO(ctx context.Context, req *ListBooksRequest) (*Lis
 
 	return resp, nil
 }
+
+// listAuthorBooksFn lists one page of the books of an author, see
+// repository.AuthorsRepository.ListBooksByKeyset.
+type listAuthorBooksFn func(
+	ctx context.Context,
+	authorID uuid.UUID,
+	pageSize int,
+	keyset *pagetoken.KeysetPayload,
+) ([]*repository.AuthorBook, *pagetoken.KeysetPayload, error)
+
+// ListAuthorBooksSQL handles GET /api/v1/authors/{id}/books/sql.
+func (h *Handler) ListAuthorBooksSQL(ctx context.Context, req *ListAuthorBooksRequest) (*ListAuthorBooksResponse, error) {
+	return h.listAuthorBooks(ctx, req, h.a.ListBooksByKeysetSQL)
+}
+
+// ListAuthorBooksDAO handles GET /api/v1/authors/{id}/books/dao.
+func (h *Handler) ListAuthorBooksDAO(ctx context.Context, req *ListAuthorBooksRequest) (*ListAuthorBooksResponse, error) {
+	return h.listAuthorBooks(ctx, req, h.a.ListBooksByKeyset)
+}
+
+func (h *Handler) listAuthorBooks(ctx context.Context, req *ListAuthorBooksRequest, list listAuthorBooksFn) (*ListAuthorBooksResponse, error) {
+	t := req.Token()
+
+	authorID, err := uuid.Parse(req.AuthorID)
+	if err != nil {
+		return nil, ErrInvalidAuthorID
+	}
+
+	ms, nextPayload, err := list(ctx, authorID, req.PageSize, t.Payload())
+	if err != nil {
+		return nil, ErrFailedToListBooks
+	}
+
+	resp := &ListAuthorBooksResponse{}
+	resp.Body.NextPageToken, err = h.reader.NextToken(t, nextPayload)
+	if err != nil {
+		return nil, pagetokenhuma.Error(err)
+	}
+
+	resp.Body.Books = lo.Map(ms, func(m *repository.AuthorBook, _ int) AuthorBook {
+		b := AuthorBook{}
+		b.fromModel(m)
+		return b
+	})
+
+	return resp, nil
+}
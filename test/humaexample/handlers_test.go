@@ -0,0 +1,136 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"github.com/danielgtaylor/huma/v2/humatest"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/pixlcrashr/go-pagetoken/test/humaexample/db/model"
+	"gorm.io/gorm"
+)
+
+var _ = Describe("Handler", func() {
+	var (
+		api humatest.TestAPI
+		db  *gorm.DB
+	)
+
+	BeforeEach(func() {
+		db = openTestDB()
+
+		_, api = humatest.New(GinkgoT())
+		registerRoutes(api, db)
+	})
+
+	// list pages through path and returns the names of the books per page
+	list := func(path string, query url.Values) [][]string {
+		pages := [][]string{}
+		for {
+			resp := api.Get(path + "?" + query.Encode())
+			Expect(resp.Code).To(Equal(http.StatusOK), resp.Body.String())
+
+			var body ListBooksResponse
+			Expect(json.Unmarshal(resp.Body.Bytes(), &body.Body)).To(Succeed())
+
+			names := []string{}
+			for _, b := range body.Body.Books {
+				names = append(names, b.DisplayName)
+			}
+			pages = append(pages, names)
+
+			if body.Body.NextPageToken == "" {
+				return pages
+			}
+			query.Set("page_token", body.Body.NextPageToken)
+		}
+	}
+
+	DescribeTable("returns identical pages from the SQL and the DAO endpoint",
+		func(orderBy string, first, last string) {
+			query := func() url.Values {
+				return url.Values{"order_by": {orderBy}, "page_size": {"30"}}
+			}
+
+			sqlPages := list("/api/v1/books/sql", query())
+			Expect(sqlPages).To(HaveLen(4))
+			Expect(sqlPages[0][0]).To(Equal(first))
+			Expect(sqlPages[3]).To(HaveLen(10))
+			Expect(sqlPages[3][9]).To(Equal(last))
+
+			Expect(list("/api/v1/books/dao", query())).To(Equal(sqlPages))
+		},
+		Entry("by default", "", "Book 100", "Book 001"),
+		Entry("by display name", "display_name", "Book 001", "Book 100"),
+		Entry("by display name descending", "display_name desc", "Book 100", "Book 001"),
+		Entry("by creation time", "created_at", "Book 001", "Book 100"),
+	)
+
+	Describe("books of an author", func() {
+		// listAuthorBooks pages through path and returns the names of the
+		// books per page
+		listAuthorBooks := func(path string) [][]string {
+			query := url.Values{"page_size": {"10"}}
+			pages := [][]string{}
+			for {
+				resp := api.Get(path + "?" + query.Encode())
+				Expect(resp.Code).To(Equal(http.StatusOK), resp.Body.String())
+
+				var body ListAuthorBooksResponse
+				Expect(json.Unmarshal(resp.Body.Bytes(), &body.Body)).To(Succeed())
+
+				names := []string{}
+				for _, b := range body.Body.Books {
+					Expect(b.AuthorName).To(Equal("Alan Turing"))
+					names = append(names, b.DisplayName)
+				}
+				pages = append(pages, names)
+
+				if body.Body.NextPageToken == "" {
+					return pages
+				}
+				query.Set("page_token", body.Body.NextPageToken)
+			}
+		}
+
+		var authorID string
+
+		BeforeEach(func() {
+			var author model.Author
+			Expect(db.Where("name = ?", "Alan Turing").First(&author).Error).To(Succeed())
+			authorID = author.ID.String()
+		})
+
+		It("returns identical pages of the joined query from the SQL and the DAO endpoint", func() {
+			sqlPages := listAuthorBooks("/api/v1/authors/" + authorID + "/books/sql")
+			Expect(sqlPages).To(HaveLen(3))
+			Expect(sqlPages[0]).To(HaveLen(10))
+			Expect(sqlPages[0][:3]).To(Equal([]string{"Book 002", "Book 006", "Book 010"}))
+			Expect(sqlPages[2]).To(HaveLen(5))
+			Expect(sqlPages[2][4]).To(Equal("Book 098"))
+
+			Expect(listAuthorBooks("/api/v1/authors/" + authorID + "/books/dao")).To(Equal(sqlPages))
+		})
+
+		It("rejects a token of another author", func() {
+			resp := api.Get("/api/v1/authors/" + authorID + "/books/dao?page_size=10")
+			Expect(resp.Code).To(Equal(http.StatusOK))
+
+			var body ListAuthorBooksResponse
+			Expect(json.Unmarshal(resp.Body.Bytes(), &body.Body)).To(Succeed())
+
+			var other model.Author
+			Expect(db.Where("name = ?", "Grace Hopper").First(&other).Error).To(Succeed())
+
+			resp = api.Get("/api/v1/authors/" + other.ID.String() + "/books/dao?page_size=10&page_token=" + url.QueryEscape(body.Body.NextPageToken))
+			Expect(resp.Code).To(Equal(http.StatusBadRequest))
+		})
+
+		It("rejects an invalid author id", func() {
+			resp := api.Get("/api/v1/authors/not-a-uuid/books/sql")
+			Expect(resp.Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+})
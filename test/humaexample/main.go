@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 	"os/signal"
@@ -8,6 +9,7 @@ import (
 
 	"github.com/pixlcrashr/go-pagetoken/test/humaexample/db/model"
 	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
@@ -15,7 +17,16 @@ import (
 const defaultDSN = "host=127.0.0.1 port=5473 user=books password=books dbname=books sslmode=disable"
 
 func main() {
-	db, err := connectToDB()
+	driver := flag.String("db", "postgres", "database to serve from: postgres (see compose.yaml) or sqlite (in-memory)")
+	cfg := defaultSeedConfig
+	flag.IntVar(&cfg.Books, "books", cfg.Books, "number of books to seed")
+	flag.Float64Var(&cfg.DuplicateTimestamps, "dup-timestamps", cfg.DuplicateTimestamps, "ratio of books created at the same time as the book before them")
+	flag.Float64Var(&cfg.NullSubtitles, "null-ratio", cfg.NullSubtitles, "ratio of books with a NULL subtitle")
+	flag.BoolVar(&cfg.LocaleNames, "locale-names", cfg.LocaleNames, "seed display names in many scripts and with duplicates")
+	flag.Uint64Var(&cfg.RandSeed, "seed", cfg.RandSeed, "seed of the random choices of the seeding")
+	flag.Parse()
+
+	db, err := connectToDB(*driver, cfg)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "failed to connect to database: %v\n", err)
 		os.Exit(1)
@@ -51,30 +62,68 @@ func main() {
 	fmt.Println("Server stopped.")
 }
 
-func connectToDB() (*gorm.DB, error) {
-	dsn := os.Getenv("DATABASE_URL")
-	if dsn == "" {
-		dsn = defaultDSN
+// connectToDB opens the database of the given driver and resets it to the
+// seed data described by cfg.
+func connectToDB(driver string, cfg seedConfig) (*gorm.DB, error) {
+	var dialector gorm.Dialector
+	switch driver {
+	case "postgres":
+		dsn := os.Getenv("DATABASE_URL")
+		if dsn == "" {
+			dsn = defaultDSN
+		}
+		dialector = postgres.Open(dsn)
+	case "sqlite":
+		dialector = sqlite.Open("file::memory:")
+	default:
+		return nil, fmt.Errorf("unknown database %q", driver)
 	}
 
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+	db, err := gorm.Open(dialector, &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Info),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %v", err)
 	}
 
-	if err := db.Migrator().DropTable(&model.Book{}); err != nil {
-		return nil, fmt.Errorf("failed to drop tables: %v", err)
+	if driver == "sqlite" {
+		if err := useSingleConn(db); err != nil {
+			return nil, err
+		}
 	}
 
-	if err := db.AutoMigrate(&model.Book{}); err != nil {
-		return nil, fmt.Errorf("failed to migrate database: %v", err)
+	if err := resetDB(db, cfg); err != nil {
+		return nil, err
 	}
 
-	if err := seed(db); err != nil {
-		return nil, fmt.Errorf("failed to seed database: %v", err)
+	return db, nil
+}
+
+// useSingleConn limits db to one connection, as every connection to an
+// in-memory SQLite database would open a database of its own.
+func useSingleConn(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get underlying sql.DB: %v", err)
 	}
+	sqlDB.SetMaxOpenConns(1)
 
-	return db, nil
+	return nil
+}
+
+// resetDB recreates the tables of db and seeds them as described by cfg.
+func resetDB(db *gorm.DB, cfg seedConfig) error {
+	if err := db.Migrator().DropTable(&model.Author{}, &model.Book{}); err != nil {
+		return fmt.Errorf("failed to drop tables: %v", err)
+	}
+
+	if err := db.AutoMigrate(&model.Author{}, &model.Book{}); err != nil {
+		return fmt.Errorf("failed to migrate database: %v", err)
+	}
+
+	if err := seed(db, cfg); err != nil {
+		return fmt.Errorf("failed to seed database: %v", err)
+	}
+
+	return nil
 }
@@ -3,15 +3,19 @@ package main
 import (
 	"time"
 
+	"github.com/danielgtaylor/huma/v2"
 	"github.com/google/uuid"
 	"github.com/pixlcrashr/go-pagetoken/checksum"
+	"github.com/pixlcrashr/go-pagetoken/integration/pagetokenhuma"
 	"github.com/pixlcrashr/go-pagetoken/test/humaexample/db/model"
+	"github.com/pixlcrashr/go-pagetoken/test/humaexample/db/repository"
 )
 
 // Book is the API representation of a Book.
 type Book struct {
 	ID          uuid.UUID `json:"id" doc:"Book UUID"`
 	DisplayName string    `json:"display_name" doc:"Human-readable book name"`
+	Subtitle    *string   `json:"subtitle,omitempty" doc:"Subtitle, absent if the book has none"`
 	CreatedAt   time.Time `json:"created_at" doc:"Creation timestamp"`
 	UpdatedAt   time.Time `json:"updated_at" doc:"Last modification timestamp"`
 }
@@ -19,20 +23,23 @@ type Book struct {
 func (b *Book) fromModel(m *model.Book) {
 	b.ID = m.ID
 	b.DisplayName = m.DisplayName
+	b.Subtitle = m.Subtitle
 	b.CreatedAt = m.CreatedAt
 	b.UpdatedAt = m.UpdatedAt
 }
 
 // ListBooksRequest holds query parameters for the list-books endpoint.
 type ListBooksRequest struct {
-	DisplayName string `query:"display_name" doc:"Case-sensitive prefix filter on display_name" maxLength:"200"`
-	ID          string `query:"id" doc:"Filter by exact book UUID" maxLength:"36"`
-	OrderBy     string `query:"order_by" doc:"Sort expression, e.g. 'display_name desc'. Available fields: id, display_name, created_at, updated_at"`
-	PageSize    int    `query:"page_size" doc:"Books per page (max 100)" minimum:"1" maximum:"100" default:"20"`
-	PageToken   string `query:"page_token" doc:"Opaque continuation token from the previous response; omit for the first page" maxLength:"512"`
+	pagetokenhuma.Pagination
+
+	DisplayName string                  `query:"display_name" doc:"Case-sensitive prefix filter on display_name" maxLength:"200"`
+	ID          string                  `query:"id" doc:"Filter by exact book UUID" maxLength:"36"`
+	OrderBy     string                  `query:"order_by" doc:"Sort expression, e.g. 'display_name desc'. Available fields: id, display_name, created_at, updated_at"`
+	PageSize    int                     `query:"page_size" doc:"Books per page (max 100)" minimum:"1" maximum:"100" default:"20"`
+	PageToken   pagetokenhuma.PageToken `query:"page_token"`
 }
 
-func (r *ListBooksRequest) GetPageToken() string { return r.PageToken }
+func (r *ListBooksRequest) GetPageToken() string { return string(r.PageToken) }
 func (r *ListBooksRequest) GetChecksumFields() []checksum.BuilderOpt {
 	return []checksum.BuilderOpt{
 		checksum.Field("display_name", r.DisplayName),
@@ -40,6 +47,9 @@ func (r *ListBooksRequest) GetChecksumFields() []checksum.BuilderOpt {
 		checksum.Field("order_by", r.OrderBy),
 	}
 }
+func (r *ListBooksRequest) Resolve(ctx huma.Context) []error {
+	return pagetokenhuma.Resolve(ctx, r, &r.Pagination)
+}
 
 // ListBooksResponse is the API response for the list-books endpoint.
 type ListBooksResponse struct {
@@ -48,3 +58,43 @@ type ListBooksResponse struct {
 		NextPageToken string `json:"next_page_token" doc:"Pass as page_token on the next request; empty on the last page" maxLength:"512"`
 	}
 }
+
+// AuthorBook is the API representation of a Book joined with its Author.
+type AuthorBook struct {
+	Book
+	AuthorName string `json:"author_name" doc:"Name of the book's author"`
+}
+
+func (b *AuthorBook) fromModel(m *repository.AuthorBook) {
+	b.Book.fromModel(&m.Book)
+	b.AuthorName = m.AuthorName
+}
+
+// ListAuthorBooksRequest holds the parameters for the list-author-books
+// endpoint.
+type ListAuthorBooksRequest struct {
+	pagetokenhuma.Pagination
+
+	AuthorID  string                  `path:"id" doc:"Author UUID" maxLength:"36"`
+	PageSize  int                     `query:"page_size" doc:"Books per page (max 100)" minimum:"1" maximum:"100" default:"20"`
+	PageToken pagetokenhuma.PageToken `query:"page_token"`
+}
+
+func (r *ListAuthorBooksRequest) GetPageToken() string { return string(r.PageToken) }
+func (r *ListAuthorBooksRequest) GetChecksumFields() []checksum.BuilderOpt {
+	return []checksum.BuilderOpt{
+		checksum.Field("author_id", r.AuthorID),
+	}
+}
+func (r *ListAuthorBooksRequest) Resolve(ctx huma.Context) []error {
+	return pagetokenhuma.Resolve(ctx, r, &r.Pagination)
+}
+
+// ListAuthorBooksResponse is the API response for the list-author-books
+// endpoint.
+type ListAuthorBooksResponse struct {
+	Body struct {
+		Books         []AuthorBook `json:"books"`
+		NextPageToken string       `json:"next_page_token" doc:"Pass as page_token on the next request; empty on the last page" maxLength:"512"`
+	}
+}
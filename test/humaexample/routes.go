@@ -6,6 +6,7 @@ import (
 	"github.com/danielgtaylor/huma/v2"
 	"github.com/pixlcrashr/go-pagetoken"
 	"github.com/pixlcrashr/go-pagetoken/encryption"
+	"github.com/pixlcrashr/go-pagetoken/integration/pagetokenhuma"
 	"github.com/pixlcrashr/go-pagetoken/test/humaexample/db/repository"
 	"gorm.io/gorm"
 )
@@ -21,11 +22,15 @@ func registerRoutes(api huma.API, db *gorm.DB) {
 		panic(err)
 	}
 
+	reader := pagetoken.NewRequestReader(
+		pagetoken.WithEncryptor(e),
+	)
+	api.UseMiddleware(pagetokenhuma.Middleware(reader))
+
 	h := &Handler{
-		r: &repository.BooksRepository{DB: db},
-		rr: pagetoken.NewRequestReader(
-			pagetoken.WithEncryptor(e),
-		),
+		r:      &repository.BooksRepository{DB: db},
+		a:      &repository.AuthorsRepository{DB: db},
+		reader: reader,
 	}
 
 	huma.Register(api, huma.Operation{
@@ -42,4 +47,18 @@ func registerRoutes(api huma.API, db *gorm.DB) {
 		Summary:     "List books using DAO with keyset page-token pagination",
 		Tags:        []string{"Books"},
 	}, h.ListBooksDAO)
+	huma.Register(api, huma.Operation{
+		OperationID: "list-author-books-sql",
+		Method:      http.MethodGet,
+		Path:        "/api/v1/authors/{id}/books/sql",
+		Summary:     "List the books of an author, joined with the author, using raw SQL with keyset page-token pagination",
+		Tags:        []string{"Authors"},
+	}, h.ListAuthorBooksSQL)
+	huma.Register(api, huma.Operation{
+		OperationID: "list-author-books-dao",
+		Method:      http.MethodGet,
+		Path:        "/api/v1/authors/{id}/books/dao",
+		Summary:     "List the books of an author, joined with the author, using DAO with keyset page-token pagination",
+		Tags:        []string{"Authors"},
+	}, h.ListAuthorBooksDAO)
 }
@@ -2,6 +2,8 @@ package main
 
 import (
 	"fmt"
+	"math/rand/v2"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -9,16 +11,106 @@ import (
 	"gorm.io/gorm"
 )
 
-func seed(db *gorm.DB) error {
-	now := time.Now()
-	books := make([]model.Book, 100)
+// authorNames are the names of the seeded authors. The books are assigned
+// to them in turn, so every author has a quarter of the books.
+var authorNames = []string{"Ada Lovelace", "Alan Turing", "Grace Hopper", "Katherine Johnson"}
+
+// localeWords make up the display names of books seeded with
+// seedConfig.LocaleNames. They mix scripts, diacritics and case so that
+// collations disagree about their order.
+var localeWords = []string{
+	"Äpfel", "apfel", "Ørsted", "Straße", "STRASSE", "élan", "Élan", "naïve",
+	"İstanbul", "istanbul", "Ωμέγα", "ñandú", "Zoë", "Привет", "Ёлка", "日本語",
+	"中文", "한국어", "العربية", "עברית", "Łódź", "Ærø", "café", "cafe",
+}
+
+// seedConfig describes the books to seed.
+type seedConfig struct {
+	// Books is the number of books.
+	Books int
+	// DuplicateTimestamps is the ratio of books created at the same time
+	// as the book before them.
+	DuplicateTimestamps float64
+	// NullSubtitles is the ratio of books without a subtitle.
+	NullSubtitles float64
+	// LocaleNames gives the books display names made of localeWords
+	// instead of "Book 001" and so on. Display names may repeat.
+	LocaleNames bool
+	// RandSeed seeds the random choices, so that a configuration always
+	// seeds the same books.
+	RandSeed uint64
+}
+
+// defaultSeedConfig seeds 100 books named "Book 001" to "Book 100" with
+// strictly increasing creation times and subtitles.
+var defaultSeedConfig = seedConfig{Books: 100, RandSeed: 1}
+
+func (c seedConfig) validate() error {
+	if c.Books < 0 {
+		return fmt.Errorf("book count must not be negative, got %d", c.Books)
+	}
+	if c.DuplicateTimestamps < 0 || c.DuplicateTimestamps > 1 {
+		return fmt.Errorf("duplicate timestamp ratio must be between 0 and 1, got %v", c.DuplicateTimestamps)
+	}
+	if c.NullSubtitles < 0 || c.NullSubtitles > 1 {
+		return fmt.Errorf("NULL ratio must be between 0 and 1, got %v", c.NullSubtitles)
+	}
+
+	return nil
+}
+
+func seed(db *gorm.DB, cfg seedConfig) error {
+	if err := cfg.validate(); err != nil {
+		return err
+	}
+	rnd := rand.New(rand.NewPCG(cfg.RandSeed, cfg.RandSeed))
+
+	authors := make([]model.Author, len(authorNames))
+	for i, name := range authorNames {
+		authors[i] = model.Author{
+			ID:   uuid.New(),
+			Name: name,
+		}
+	}
+	if err := db.Create(&authors).Error; err != nil {
+		return err
+	}
+
+	createdAt := time.Now().Truncate(time.Second)
+	books := make([]model.Book, cfg.Books)
 	for i := range books {
+		if i > 0 && rnd.Float64() >= cfg.DuplicateTimestamps {
+			createdAt = createdAt.Add(time.Second)
+		}
+
 		books[i] = model.Book{
 			ID:          uuid.New(),
+			AuthorID:    authors[i%len(authors)].ID,
 			DisplayName: fmt.Sprintf("Book %03d", i+1),
-			CreatedAt:   now.Add(time.Duration(i) * time.Second),
-			UpdatedAt:   now.Add(time.Duration(i) * time.Second),
+			CreatedAt:   createdAt,
+			UpdatedAt:   createdAt,
+		}
+		if cfg.LocaleNames {
+			books[i].DisplayName = localeName(rnd)
 		}
+		if rnd.Float64() >= cfg.NullSubtitles {
+			subtitle := fmt.Sprintf("Volume %d", i%7+1)
+			books[i].Subtitle = &subtitle
+		}
+	}
+	if len(books) == 0 {
+		return nil
 	}
-	return db.Create(&books).Error
+
+	return db.CreateInBatches(&books, 500).Error
+}
+
+// localeName returns a display name of one to three localeWords.
+func localeName(rnd *rand.Rand) string {
+	words := make([]string, 1+rnd.IntN(3))
+	for i := range words {
+		words[i] = localeWords[rnd.IntN(len(localeWords))]
+	}
+
+	return strings.Join(words, " ")
 }
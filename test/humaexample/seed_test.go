@@ -0,0 +1,61 @@
+package main
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/pixlcrashr/go-pagetoken/test/humaexample/db/model"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var _ = Describe("seed", func() {
+	seeded := func(cfg seedConfig) *gorm.DB {
+		db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{
+			Logger: logger.Discard,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(useSingleConn(db)).To(Succeed())
+
+		sqlDB, err := db.DB()
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(sqlDB.Close)
+
+		Expect(resetDB(db, cfg)).To(Succeed())
+
+		return db
+	}
+
+	count := func(q *gorm.DB) int64 {
+		var n int64
+		Expect(q.Count(&n).Error).To(Succeed())
+		return n
+	}
+
+	It("seeds distinct timestamps and names by default", func() {
+		db := seeded(defaultSeedConfig)
+
+		Expect(count(db.Model(&model.Book{}))).To(BeEquivalentTo(100))
+		Expect(count(db.Model(&model.Book{}).Distinct("created_at"))).To(BeEquivalentTo(100))
+		Expect(count(db.Model(&model.Book{}).Where("subtitle IS NULL"))).To(BeZero())
+	})
+
+	It("seeds ties, NULLs and locale names in the configured ratios", func() {
+		db := seeded(seedConfig{
+			Books:               2000,
+			DuplicateTimestamps: 0.3,
+			NullSubtitles:       0.2,
+			LocaleNames:         true,
+			RandSeed:            1,
+		})
+
+		Expect(count(db.Model(&model.Book{}))).To(BeEquivalentTo(2000))
+		Expect(count(db.Model(&model.Book{}).Distinct("created_at"))).To(BeNumerically("~", 1400, 100))
+		Expect(count(db.Model(&model.Book{}).Where("subtitle IS NULL"))).To(BeNumerically("~", 400, 100))
+		Expect(count(db.Model(&model.Book{}).Distinct("display_name"))).To(BeNumerically("<", 2000))
+	})
+
+	It("rejects ratios out of range", func() {
+		Expect(seed(nil, seedConfig{Books: 1, NullSubtitles: 1.5})).To(MatchError(ContainSubstring("NULL ratio")))
+	})
+})
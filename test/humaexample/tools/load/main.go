@@ -0,0 +1,156 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"time"
+)
+
+// load walks every page of a list endpoint of the example server and
+// reports duplicated and missing books and the latency of the requests.
+//
+// Usage:
+//
+//	go run ./tools/load/ [-url <endpoint>] [-order-by <expr>] [-page-size <n>] [-expect <books>]
+//
+// Start the server first, e.g. with many ties and NULLs:
+//
+//	go run . -db sqlite -books 100000 -dup-timestamps 0.3 -null-ratio 0.2 -locale-names
+//	go run ./tools/load/ -order-by "created_at desc" -expect 100000
+//
+// The exit status is 1 if the walk returned a book twice or, with -expect,
+// fewer books than expected.
+func main() {
+	endpoint := flag.String("url", "http://127.0.0.1:8080/api/v1/books/dao", "list endpoint to walk")
+	orderBy := flag.String("order-by", "", "order_by of the walk, e.g. \"display_name desc\"")
+	pageSize := flag.Int("page-size", 100, "books per page")
+	expect := flag.Int("expect", 0, "number of books the walk must return, to report gaps; 0 to skip")
+	maxPages := flag.Int("max-pages", 0, "stop after this many pages; 0 walks all pages")
+	flag.Parse()
+
+	r, err := walk(http.DefaultClient, *endpoint, *orderBy, *pageSize, *maxPages)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "walk failed after %d pages: %v\n", len(r.latencies), err)
+		os.Exit(1)
+	}
+
+	ok := r.print(os.Stdout, *expect)
+	if !ok {
+		os.Exit(1)
+	}
+}
+
+// listResponse is the part of a list response the walk needs.
+type listResponse struct {
+	Books []struct {
+		ID string `json:"id"`
+	} `json:"books"`
+	NextPageToken string `json:"next_page_token"`
+}
+
+// report is the result of a walk.
+type report struct {
+	// seen counts the occurrences of every book id.
+	seen      map[string]int
+	books     int
+	latencies []time.Duration
+}
+
+// walk requests the pages of endpoint until there is no next page token, or
+// maxPages pages if positive.
+func walk(c *http.Client, endpoint, orderBy string, pageSize, maxPages int) (*report, error) {
+	r := &report{seen: map[string]int{}}
+
+	query := url.Values{"page_size": {fmt.Sprint(pageSize)}}
+	if orderBy != "" {
+		query.Set("order_by", orderBy)
+	}
+
+	for maxPages <= 0 || len(r.latencies) < maxPages {
+		start := time.Now()
+		page, err := get(c, endpoint+"?"+query.Encode())
+		if err != nil {
+			return r, err
+		}
+		r.latencies = append(r.latencies, time.Since(start))
+
+		for _, b := range page.Books {
+			r.seen[b.ID]++
+			r.books++
+		}
+
+		if page.NextPageToken == "" {
+			break
+		}
+		query.Set("page_token", page.NextPageToken)
+	}
+
+	return r, nil
+}
+
+func get(c *http.Client, u string) (*listResponse, error) {
+	resp, err := c.Get(u)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status %d: %s", resp.StatusCode, body)
+	}
+
+	var page listResponse
+	if err := json.Unmarshal(body, &page); err != nil {
+		return nil, fmt.Errorf("failed to decode page: %w", err)
+	}
+
+	return &page, nil
+}
+
+// print writes r to w and reports whether the walk was free of duplicates
+// and, if expect is positive, of gaps.
+func (r *report) print(w io.Writer, expect int) bool {
+	duplicates := 0
+	for _, n := range r.seen {
+		duplicates += n - 1
+	}
+
+	fmt.Fprintf(w, "pages:      %d\n", len(r.latencies))
+	fmt.Fprintf(w, "books:      %d (%d unique)\n", r.books, len(r.seen))
+	fmt.Fprintf(w, "duplicates: %d\n", duplicates)
+	ok := duplicates == 0
+
+	if expect > 0 {
+		missing := max(expect-len(r.seen), 0)
+		fmt.Fprintf(w, "missing:    %d of %d\n", missing, expect)
+		ok = ok && missing == 0
+	}
+
+	ls := slices.Clone(r.latencies)
+	slices.Sort(ls)
+	fmt.Fprintf(w, "latency:    p50 %v, p90 %v, p99 %v, max %v\n",
+		percentile(ls, 50), percentile(ls, 90), percentile(ls, 99), percentile(ls, 100))
+
+	return ok
+}
+
+// percentile returns the p-th percentile of the sorted durations ds, using
+// the nearest-rank method.
+func percentile(ds []time.Duration, p int) time.Duration {
+	if len(ds) == 0 {
+		return 0
+	}
+
+	rank := (p*len(ds) + 99) / 100
+	return ds[max(rank, 1)-1]
+}
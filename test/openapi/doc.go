@@ -0,0 +1,5 @@
+// Package openapi checks the OpenAPI fragments returned by
+// pagetoken.OpenAPIParams and pagetoken.OpenAPIResponseProperties against
+// kin-openapi. It lives in its own Go module so that the core pagetoken
+// module does not depend on kin-openapi.
+package openapi
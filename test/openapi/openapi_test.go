@@ -0,0 +1,78 @@
+package openapi_test
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken"
+)
+
+var _ = Describe("OpenAPI", func() {
+	// convert marshals v and unmarshals it into a kin-openapi type.
+	convert := func(v, into any) {
+		bs, err := json.Marshal(v)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(json.Unmarshal(bs, into)).To(Succeed())
+	}
+
+	It("marshals the parameters into kin-openapi parameters with the package defaults", func() {
+		var params []*openapi3.Parameter
+		convert(pagetoken.OpenAPIParams(pagetoken.OpenAPIConfig{}), &params)
+		Expect(params).To(HaveLen(2))
+
+		token := params[0]
+		Expect(token.Name).To(Equal(pagetoken.DefaultPageTokenParam))
+		Expect(token.In).To(Equal(openapi3.ParameterInQuery))
+		Expect(token.Schema.Value.Type.Is(openapi3.TypeString)).To(BeTrue())
+		Expect(token.Schema.Value.MaxLength).To(HaveValue(Equal(uint64(pagetoken.DefaultMaxTokenLength))))
+
+		size := params[1]
+		Expect(size.Name).To(Equal(pagetoken.DefaultPageSizeParam))
+		Expect(size.Schema.Value.Type.Is(openapi3.TypeInteger)).To(BeTrue())
+		Expect(size.Schema.Value.Min).To(HaveValue(Equal(float64(pagetoken.DefaultMinPageSize))))
+		Expect(size.Schema.Value.Max).To(HaveValue(Equal(float64(pagetoken.DefaultMaxPageSize))))
+		Expect(size.Schema.Value.Default).To(BeNumerically("==", pagetoken.DefaultPageSize))
+	})
+
+	It("produces a valid kin-openapi document", func() {
+		cfg := pagetoken.OpenAPIConfig{
+			TokenParam:     "cursor",
+			MaxTokenLength: 1024,
+			MaxPageSize:    500,
+		}
+
+		var params openapi3.Parameters
+		convert(pagetoken.OpenAPIParams(cfg), &params)
+
+		var props openapi3.Schemas
+		convert(pagetoken.OpenAPIResponseProperties(cfg), &props)
+		Expect(props).To(HaveKey(pagetoken.DefaultNextPageTokenName))
+		Expect(props[pagetoken.DefaultNextPageTokenName].Value.MaxLength).To(HaveValue(Equal(uint64(1024))))
+
+		body := openapi3.NewObjectSchema()
+		body.Properties = props
+
+		desc := "A page of users"
+		op := openapi3.NewOperation()
+		op.Parameters = params
+		op.Responses = openapi3.NewResponses(openapi3.WithStatus(200, &openapi3.ResponseRef{
+			Value: openapi3.NewResponse().WithDescription(desc).WithJSONSchema(body),
+		}))
+
+		doc := &openapi3.T{
+			OpenAPI: "3.0.3",
+			Info:    &openapi3.Info{Title: "users", Version: "1.0.0"},
+			Paths:   openapi3.NewPaths(),
+		}
+		doc.AddOperation("/users", "GET", op)
+
+		Expect(doc.Validate(context.Background())).To(Succeed())
+		Expect(params[0].Value.Name).To(Equal("cursor"))
+		Expect(params[0].Value.Schema.Value.MaxLength).To(HaveValue(Equal(uint64(1024))))
+		Expect(params[1].Value.Schema.Value.Max).To(HaveValue(Equal(float64(500))))
+	})
+})
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/database/sqlraw"
+	"github.com/pixlcrashr/go-pagetoken/order"
+)
+
+// book is the API representation of a book.
+type book struct {
+	ID     int64  `json:"id"`
+	Title  string `json:"title"`
+	Author string `json:"author"`
+}
+
+// listBooksResponse is the envelope of a page of books. The next page token
+// serializes itself and is omitted on the last page.
+type listBooksResponse struct {
+	Books         []book                 `json:"books"`
+	NextPageToken *pagetoken.KeysetToken `json:"next_page_token,omitempty"`
+}
+
+// bookSpec orders the books by title, with the id as tiebreak.
+var bookSpec = pagetoken.SortSpec{
+	{Path: "title", Order: order.Asc},
+	{Path: "id", Order: order.Asc},
+}
+
+// sizedRequest adds the page size of the page_size query parameter to a
+// request, so that the reader applies its page size limits to it.
+type sizedRequest struct {
+	pagetoken.Request
+	size int
+}
+
+func (r sizedRequest) GetPageSize() int { return r.size }
+
+type server struct {
+	db     *sql.DB
+	reader *pagetoken.RequestReader
+}
+
+// newHandler returns the handler of the books API.
+func newHandler(db *sql.DB, reader *pagetoken.RequestReader) http.Handler {
+	s := &server{db: db, reader: reader}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /books", s.listBooks)
+	return mux
+}
+
+// listBooks handles GET /books?author=…&page_size=…&page_token=…. The page
+// token is bound to the author filter, whereas the page size may change
+// between pages.
+func (s *server) listBooks(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	size := 0
+	if v := query.Get("page_size"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "invalid page_size", http.StatusBadRequest)
+			return
+		}
+		size = n
+	}
+
+	t, err := s.reader.Read(sizedRequest{Request: pagetoken.HTTPRequest(r, "author"), size: size})
+	if err != nil {
+		pagetoken.WriteProblem(w, err)
+		return
+	}
+
+	books, next, err := s.queryBooks(r.Context(), query.Get("author"), t.Payload(), t.PageSize())
+	if err != nil {
+		http.Error(w, "failed to list books", http.StatusInternalServerError)
+		return
+	}
+
+	resp := listBooksResponse{Books: books}
+	if next != nil {
+		resp.NextPageToken = t.Next(pagetoken.WithKeysetPayload(next))
+	}
+
+	body, err := json.Marshal(resp)
+	if err != nil {
+		pagetoken.WriteProblem(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(body)
+}
+
+// queryBooks loads up to limit books of author, or of all authors if author
+// is empty, after keyset and returns them together with the keyset of the
+// next page, which is nil on the last page.
+func (s *server) queryBooks(ctx context.Context, author string, keyset *pagetoken.KeysetPayload, limit int) ([]book, *pagetoken.KeysetPayload, error) {
+	conds := []string{}
+	args := []any{}
+
+	if author != "" {
+		conds = append(conds, "author = ?")
+		args = append(args, author)
+	}
+
+	// bookValue rejects all paths but title and id, so the keyset cannot
+	// smuggle other expressions into the query
+	b := sqlraw.NewBuilder()
+	where, kArgs, err := b.KeysetWhere(keyset, bookValue)
+	if err != nil {
+		return nil, nil, err
+	}
+	if where != "" {
+		conds = append(conds, where)
+		args = append(args, kArgs...)
+	}
+
+	q := "SELECT id, title, author FROM books"
+	if len(conds) > 0 {
+		q += " WHERE " + strings.Join(conds, " AND ")
+	}
+	q += " ORDER BY " + b.OrderBy(bookSpec) + " LIMIT ?"
+	args = append(args, limit+1)
+
+	rows, err := s.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	books := []book{}
+	for rows.Next() {
+		var bk book
+		if err := rows.Scan(&bk.ID, &bk.Title, &bk.Author); err != nil {
+			return nil, nil, err
+		}
+		books = append(books, bk)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	if len(books) <= limit {
+		return books, nil, nil
+	}
+	books = books[:limit]
+
+	last := books[limit-1]
+	next := pagetoken.NewKeysetPayloadBuilder().
+		AddString("title", last.Title, bookSpec[0].Order).
+		AddInt64("id", last.ID, bookSpec[1].Order).
+		Build()
+
+	return books, next, nil
+}
+
+// bookValue decodes the keyset value of column.
+func bookValue(column string, payload *pagetoken.KeysetPayload) (any, error) {
+	switch column {
+	case "title":
+		v, _, err := payload.String(column)
+		return v, err
+	case "id":
+		v, _, err := payload.Int64(column)
+		return v, err
+	default:
+		return nil, errors.New("unknown column: " + column)
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pixlcrashr/go-pagetoken"
+)
+
+type pageResponse struct {
+	Books         []book `json:"books"`
+	NextPageToken string `json:"next_page_token"`
+}
+
+var _ = Describe("GET /books", func() {
+	var srv *httptest.Server
+
+	BeforeEach(func() {
+		db, err := openDB()
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(db.Close)
+
+		reader, err := newReader()
+		Expect(err).NotTo(HaveOccurred())
+
+		srv = httptest.NewServer(newHandler(db, reader))
+		DeferCleanup(srv.Close)
+	})
+
+	get := func(query url.Values) *http.Response {
+		resp, err := http.Get(srv.URL + "/books?" + query.Encode())
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(resp.Body.Close)
+		return resp
+	}
+
+	list := func(query url.Values) pageResponse {
+		resp := get(query)
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(resp.Header.Get("Content-Type")).To(Equal("application/json"))
+
+		var page pageResponse
+		Expect(json.NewDecoder(resp.Body).Decode(&page)).To(Succeed())
+		return page
+	}
+
+	problem := func(query url.Values) pagetoken.ProblemDetails {
+		resp := get(query)
+		Expect(resp.Header.Get("Content-Type")).To(Equal("application/problem+json"))
+
+		var p pagetoken.ProblemDetails
+		Expect(json.NewDecoder(resp.Body).Decode(&p)).To(Succeed())
+		Expect(p.Status).To(Equal(resp.StatusCode))
+		return p
+	}
+
+	// walk pages through the books of query and returns their titles and
+	// the number of pages
+	walk := func(query url.Values) ([]string, int) {
+		titles := []string{}
+		pages := 0
+		for {
+			page := list(query)
+			pages++
+			for _, b := range page.Books {
+				titles = append(titles, b.Title)
+			}
+			if page.NextPageToken == "" {
+				return titles, pages
+			}
+			query.Set("page_token", page.NextPageToken)
+		}
+	}
+
+	It("pages through all books", func() {
+		titles, pages := walk(url.Values{"page_size": {"30"}})
+
+		Expect(pages).To(Equal(4))
+		Expect(titles).To(HaveLen(100))
+		Expect(titles[0]).To(Equal("Book 001"))
+		Expect(titles[99]).To(Equal("Book 100"))
+	})
+
+	It("pages through the books of an author", func() {
+		titles, pages := walk(url.Values{"author": {"Grace Hopper"}, "page_size": {"10"}})
+
+		Expect(pages).To(Equal(3))
+		Expect(titles).To(HaveLen(25))
+		Expect(titles[:2]).To(Equal([]string{"Book 003", "Book 007"}))
+	})
+
+	It("applies the default page size", func() {
+		Expect(list(url.Values{}).Books).To(HaveLen(20))
+	})
+
+	It("allows changing the page size between pages", func() {
+		first := list(url.Values{"page_size": {"10"}})
+
+		next := list(url.Values{"page_size": {"5"}, "page_token": {first.NextPageToken}})
+		Expect(next.Books).To(HaveLen(5))
+		Expect(next.Books[0].Title).To(Equal("Book 011"))
+	})
+
+	It("rejects tokens of another author filter", func() {
+		first := list(url.Values{"author": {"Alan Turing"}, "page_size": {"10"}})
+
+		p := problem(url.Values{"author": {"Ada Lovelace"}, "page_token": {first.NextPageToken}})
+		Expect(p.Type).To(Equal(pagetoken.ProblemTypeChecksumMismatch))
+		Expect(p.Status).To(Equal(http.StatusBadRequest))
+	})
+
+	It("rejects tampered tokens", func() {
+		first := list(url.Values{"page_size": {"10"}})
+
+		p := problem(url.Values{"page_token": {first.NextPageToken + "x"}})
+		Expect(p.Type).To(Equal(pagetoken.ProblemTypeInvalidToken))
+		Expect(p.Status).To(Equal(http.StatusBadRequest))
+	})
+
+	It("rejects negative page sizes", func() {
+		p := problem(url.Values{"page_size": {"-1"}})
+		Expect(p.Type).To(Equal(pagetoken.ProblemTypeInvalidPageSize))
+	})
+
+	It("rejects malformed page sizes", func() {
+		Expect(get(url.Values{"page_size": {"ten"}}).StatusCode).To(Equal(http.StatusBadRequest))
+	})
+})
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"os"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/pixlcrashr/go-pagetoken"
+	"github.com/pixlcrashr/go-pagetoken/encryption"
+)
+
+// authors are the authors of the seeded books, which are assigned to them
+// in turn.
+var authors = []string{"Ada Lovelace", "Alan Turing", "Grace Hopper", "Katherine Johnson"}
+
+func main() {
+	db, err := openDB()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to open database: %v\n", err)
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	reader, err := newReader()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to create page token reader: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Println("Listening on 127.0.0.1:8080")
+
+	if err := http.ListenAndServe("127.0.0.1:8080", newHandler(db, reader)); err != nil {
+		fmt.Fprintf(os.Stderr, "server error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// newReader returns the page token reader of the server, which follows
+// AIP-158 with a default page size of 20 and a maximum of 100.
+func newReader() (*pagetoken.RequestReader, error) {
+	k, err := encryption.Rand32ByteKey()
+	if err != nil {
+		return nil, err
+	}
+
+	e, err := encryption.NewAEADEncryptor(k)
+	if err != nil {
+		return nil, err
+	}
+
+	return pagetoken.NewRequestReader(
+		pagetoken.WithEncryptor(e),
+		pagetoken.WithAIP158(),
+		pagetoken.WithPageSizeLimits(20, 100),
+	), nil
+}
+
+// openDB returns an in-memory SQLite database seeded with 100 books.
+func openDB() (*sql.DB, error) {
+	db, err := sql.Open("sqlite3", "file::memory:")
+	if err != nil {
+		return nil, err
+	}
+
+	// every connection to an in-memory SQLite database would open a
+	// database of its own
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec("CREATE TABLE books (id INTEGER PRIMARY KEY, title TEXT NOT NULL, author TEXT NOT NULL)"); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create table: %w", err)
+	}
+
+	for i := range 100 {
+		_, err := db.Exec("INSERT INTO books (id, title, author) VALUES (?, ?, ?)",
+			i+1, fmt.Sprintf("Book %03d", i+1), authors[i%len(authors)])
+		if err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to seed database: %w", err)
+		}
+	}
+
+	return db, nil
+}
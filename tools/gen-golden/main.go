@@ -0,0 +1,104 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/pixlcrashr/go-pagetoken/internal/golden"
+	"github.com/pixlcrashr/go-pagetoken/pagetokentest"
+)
+
+// gen-golden mints the golden token fixture of the current wire format
+// version into testdata/golden.
+//
+// Usage:
+//
+//	go run ./tools/gen-golden/ [-o <output-dir>] [-force]
+//
+// It is run by go generate in the repository root. Fixtures are never
+// overwritten unless -force is given: once a version has been released, its
+// fixture pins the format, so a new fixture is only minted after a new
+// format version has been added deliberately.
+func main() {
+	outPath := flag.String("o", "testdata/golden", "Output directory for golden fixtures")
+	force := flag.Bool("force", false, "Overwrite the fixture of the current version")
+	flag.Parse()
+
+	if err := run(*outPath, *force); err != nil {
+		fmt.Fprintf(os.Stderr, "gen-golden: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(dir string, force bool) error {
+	f := canonical()
+
+	t, err := f.Token(pagetokentest.StaticCrypter{})
+	if err != nil {
+		return err
+	}
+	f.Checksum = t.Checksum()
+
+	if f.Tokens.Static, err = t.String(); err != nil {
+		return err
+	}
+	if f.Version, err = golden.PlaintextVersion(f.Tokens.Static); err != nil {
+		return err
+	}
+
+	p := golden.Path(dir, f.Version)
+	if _, err := os.Stat(p); err == nil && !force {
+		fmt.Printf("%s exists, nothing to do\n", p)
+		return nil
+	} else if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	at, err := f.Token(golden.Crypter())
+	if err != nil {
+		return err
+	}
+	if f.Tokens.AEAD, err = at.String(); err != nil {
+		return err
+	}
+
+	if err := golden.Write(dir, f); err != nil {
+		return err
+	}
+
+	fmt.Printf("wrote %s\n", p)
+	return nil
+}
+
+// canonical returns the contents of the fixtures, using every feature of the
+// wire format.
+func canonical() golden.Fixture {
+	total := int64(250)
+	snapshot := time.Date(2024, 1, 1, 0, 0, 0, 123456789, time.UTC)
+	upstream := "upstream-continuation"
+	issuedAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	return golden.Fixture{
+		ChecksumFields: [][2]string{{"status", "active"}, {"owner", "42"}},
+		Keyset: []golden.Value{
+			{Path: "created_at", Value: "2024-01-01T00:00:00Z", Order: "desc"},
+			{Path: "name", Value: "user \"ä\" <a>", Order: "asc"},
+			{Path: "id", Value: "42", Order: "asc"},
+		},
+		TotalCount: &total,
+		Snapshot:   &snapshot,
+		Upstream:   &upstream,
+		Composite: &golden.Composite{
+			Parts: map[string][]golden.Value{
+				"shard-1": {{Path: "id", Value: "7", Order: "asc"}},
+			},
+			Done: []string{"shard-2"},
+		},
+		PageIndex: 3,
+		IssuedAt:  &issuedAt,
+	}
+}